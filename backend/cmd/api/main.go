@@ -6,17 +6,23 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
 	"github.com/timmy/emomo/internal/api"
+	"github.com/timmy/emomo/internal/api/handler"
+	"github.com/timmy/emomo/internal/bootstrap"
+	"github.com/timmy/emomo/internal/cache"
 	"github.com/timmy/emomo/internal/config"
 	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/prompts"
 	"github.com/timmy/emomo/internal/repository"
 	"github.com/timmy/emomo/internal/service"
 	"github.com/timmy/emomo/internal/source"
 	"github.com/timmy/emomo/internal/source/localdir"
 	"github.com/timmy/emomo/internal/storage"
+	"gorm.io/gorm"
 )
 
 func buildSources(cfg *config.Config) map[string]source.Source {
@@ -27,11 +33,156 @@ func buildSources(cfg *config.Config) map[string]source.Source {
 			SourceID:     cfg.Sources.LocalDir.SourceID,
 			ManifestPath: cfg.Sources.LocalDir.ManifestPath,
 			QueuePath:    cfg.Sources.LocalDir.QueuePath,
+			Priorities:   bootstrap.Priorities(cfg.Sources.LocalDir.Priorities),
 		})
 	}
 	return sources
 }
 
+// interruptedJobAge is how long an ingest job may go without progress updates
+// before startup marks it as interrupted. Running jobs update every few seconds.
+const interruptedJobAge = time.Minute
+
+// buildHealthChecker returns the dependency checks of /health/ready: the
+// database, the default Qdrant collection and the storage bucket, plus an
+// embedding of a short query when server.health.check_embedding is on. A
+// failing embedding provider only degrades readiness, since every replica
+// shares it and pulling them all would not help.
+func buildHealthChecker(
+	cfg *config.Config,
+	db *gorm.DB,
+	qdrantRepo *repository.QdrantRepository,
+	objectStorage storage.ObjectStorage,
+	provider service.EmbeddingProvider,
+) *service.HealthChecker {
+	checks := []service.HealthCheck{
+		{Name: "database", Critical: true, Check: func(ctx context.Context) (string, error) {
+			return cfg.Database.Driver, repository.PingDB(ctx, db)
+		}},
+		{Name: "qdrant", Critical: true, Check: func(ctx context.Context) (string, error) {
+			health, err := qdrantRepo.CheckCollection(ctx)
+			if health == nil {
+				return qdrantRepo.GetCollectionName(), err
+			}
+			return fmt.Sprintf("%s: status=%s, points=%d", qdrantRepo.GetCollectionName(), health.Status, health.Points), err
+		}},
+		{Name: "storage", Critical: true, Check: func(ctx context.Context) (string, error) {
+			return "", objectStorage.CheckBucket(ctx)
+		}},
+	}
+	if cfg.Server.Health.CheckEmbedding {
+		checks = append(checks, service.HealthCheck{Name: "embedding", Check: func(ctx context.Context) (string, error) {
+			_, err := provider.EmbedQuery(ctx, "health")
+			return provider.GetModel(), err
+		}})
+	}
+	return service.NewHealthChecker(service.HealthCheckerConfig{
+		Timeout:  cfg.Server.Health.Timeout,
+		CacheTTL: cfg.Server.Health.CacheTTL,
+	}, checks...)
+}
+
+// logStartupReport logs the resolved configuration with secrets redacted, the
+// searchable collections with their point counts, the enabled sources and the
+// first dependency check, so that "why is search empty" can be answered from
+// the startup log alone. Problems are logged as warnings; none stop startup.
+func logStartupReport(
+	ctx context.Context,
+	cfg *config.Config,
+	searchService *service.SearchService,
+	registry *service.EmbeddingRegistry,
+	sources map[string]source.Source,
+	health *service.HealthChecker,
+	log *logger.Logger,
+) {
+	log.WithFields(logger.Fields{"config": cfg.Summary()}).Info("Startup configuration")
+
+	timeout := cfg.Server.Health.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	for _, collection := range searchService.ListCollections() {
+		collectionLog := log.WithFields(logger.Fields{
+			"collection":        collection.Name,
+			"qdrant_collection": collection.Qdrant,
+			"model":             collection.Model,
+			"dimensions":        collection.Dimensions,
+			"default":           collection.Default,
+		})
+		_, qdrantRepo, ok := registry.Get(collection.Name)
+		if !ok {
+			collectionLog.Info("Search collection")
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		info, err := qdrantRepo.CheckCollection(checkCtx)
+		cancel()
+		switch {
+		case err != nil:
+			collectionLog.WithError(err).Warn("Search collection unavailable")
+		case info.Points == 0:
+			collectionLog.WithField("points", 0).Warn("Search collection is empty; searches return nothing until memes are ingested into it")
+		default:
+			collectionLog.WithFields(logger.Fields{"points": info.Points, "status": info.Status}).Info("Search collection")
+		}
+	}
+
+	ids := make([]string, 0, len(sources))
+	for _, src := range sources {
+		ids = append(ids, src.GetSourceID())
+	}
+	sort.Strings(ids)
+	if len(ids) == 0 {
+		log.Warn("No ingest sources enabled; memes can only be added by upload")
+	} else {
+		log.WithFields(logger.Fields{"sources": ids}).Info("Ingest sources enabled")
+	}
+
+	report := health.Check(ctx)
+	for _, dep := range report.Dependencies {
+		depLog := log.WithFields(logger.Fields{
+			"dependency": dep.Name,
+			"status":     dep.Status,
+			"critical":   dep.Critical,
+			"latency_ms": dep.LatencyMs,
+			"detail":     dep.Detail,
+		})
+		if dep.Error != "" {
+			depLog.WithField("error", dep.Error).Warn("Dependency check failed")
+		} else {
+			depLog.Info("Dependency check passed")
+		}
+	}
+	if report.Status != service.HealthStatusReady {
+		log.WithField("status", report.Status).Warn("Startup dependency checks failed; /health/ready reports the current state")
+	}
+}
+
+// buildReranker returns the search reranker (nil when disabled); the llm
+// provider falls back to the VLM credentials when it has none of its own.
+func buildReranker(cfg *config.Config, promptSet prompts.Provider, transport http.RoundTripper) (service.Reranker, error) {
+	rerank := cfg.Search.Rerank
+	if rerank.Provider == service.RerankProviderLLM {
+		if rerank.APIKey == "" {
+			rerank.APIKey = cfg.VLM.APIKey
+		}
+		if rerank.BaseURL == "" {
+			rerank.BaseURL = cfg.VLM.BaseURL
+		}
+	}
+	return service.NewReranker(&service.RerankConfig{
+		Enabled:   rerank.Enabled,
+		Provider:  rerank.Provider,
+		Model:     rerank.Model,
+		APIKey:    rerank.APIKey,
+		BaseURL:   rerank.BaseURL,
+		TopN:      rerank.TopN,
+		Timeout:   rerank.Timeout,
+		Prompts:   promptSet,
+		Transport: transport,
+	})
+}
+
 func serviceRetrievalConfig(cfg config.RetrievalConfig) service.RetrievalConfig {
 	return service.RetrievalConfig{
 		ImageTopK:   cfg.ImageTopK,
@@ -45,6 +196,15 @@ func serviceRetrievalConfig(cfg config.RetrievalConfig) service.RetrievalConfig
 	}
 }
 
+// categoryThresholds maps each configured category to its score threshold.
+func categoryThresholds(cfg []config.CategoryThreshold) map[string]float32 {
+	thresholds := make(map[string]float32, len(cfg))
+	for _, entry := range cfg {
+		thresholds[entry.Category] = entry.Threshold
+	}
+	return thresholds
+}
+
 func registerSearchProfiles(searchService *service.SearchService, registry *service.EmbeddingRegistry, profiles []config.SearchProfileConfig) {
 	for _, profile := range profiles {
 		imageProvider, imageRepo, hasImage := registry.Get(profile.ImageEmbedding)
@@ -58,6 +218,27 @@ func registerSearchProfiles(searchService *service.SearchService, registry *serv
 	}
 }
 
+// warmUp pre-loads the search caches and then marks the service ready, also
+// when the warm-up fails or times out so an instance never stays unready.
+func warmUp(searchService *service.SearchService, readiness *handler.Readiness, cfg config.WarmupConfig, log *logger.Logger) {
+	start := time.Now()
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	if err := searchService.Warm(ctx, cfg.HotQueries); err != nil {
+		log.WithError(err).Warn("Warm-up incomplete, serving with cold caches")
+	}
+	readiness.MarkReady()
+	log.WithFields(logger.Fields{
+		"hot_queries": len(cfg.HotQueries),
+		"duration_ms": time.Since(start).Milliseconds(),
+	}).Info("Warm-up finished, ready for traffic")
+}
+
 func main() {
 	// Initialize logger first (with defaults)
 	appLogger := logger.New(&logger.Config{
@@ -75,6 +256,15 @@ func main() {
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to load config")
 	}
+	if err := logger.SetQueryLogging(logger.QueryLogConfig{
+		Mode:     cfg.Search.QueryLogging.Mode,
+		MaxRunes: cfg.Search.QueryLogging.MaxRunes,
+		Salt:     cfg.Search.QueryLogging.Salt,
+	}); err != nil {
+		appLogger.WithError(err).Fatal("Invalid search.query_logging config")
+	}
+	defer bootstrap.Metrics(cfg, "api", appLogger)()
+	providerTransport := bootstrap.ProviderTransport(cfg, appLogger)
 
 	// Initialize database
 	db, err := repository.InitDB(&cfg.Database)
@@ -84,22 +274,43 @@ func main() {
 
 	// Initialize repositories
 	memeRepo := repository.NewMemeRepository(db)
+	memeRepo.SetOperationTimeout(cfg.Database.OperationTimeout)
 	vectorRepo := repository.NewMemeVectorRepository(db)
 	descRepo := repository.NewMemeDescriptionRepository(db)
 
 	ctx := context.Background()
 
+	// Shared caches and ingest locks of the API replicas; without redis.url
+	// each process keeps its own caches and the job limits alone bound ingests
+	redis := bootstrap.Redis(ctx, cfg, appLogger)
+	var sharedCache cache.Store
+	var sourceLocker cache.Locker
+	if redis != nil {
+		defer redis.Close()
+		sharedCache, sourceLocker = redis, redis
+		appLogger.WithFields(logger.Fields{
+			"key_prefix": cfg.Redis.KeyPrefix,
+		}).Info("Redis enabled for shared caches and ingest locks")
+	}
+
+	// Periodically log DB pool stats and warn on saturation
+	repository.StartPoolMonitor(ctx, db, repository.PoolMonitorConfig{
+		Interval:        cfg.Database.PoolMonitorInterval,
+		SaturationRatio: cfg.Database.PoolSaturationRatio,
+	})
+
 	// Initialize S3-compatible storage
 	storageCfg := cfg.GetStorageConfig()
 	objectStorage, err := storage.NewStorage(&storage.S3Config{
-		Type:      storage.StorageType(storageCfg.Type),
-		Endpoint:  storageCfg.Endpoint,
-		AccessKey: storageCfg.AccessKey,
-		SecretKey: storageCfg.SecretKey,
-		UseSSL:    storageCfg.UseSSL,
-		Bucket:    storageCfg.Bucket,
-		Region:    storageCfg.Region,
-		PublicURL: storageCfg.PublicURL,
+		Type:             storage.StorageType(storageCfg.Type),
+		Endpoint:         storageCfg.Endpoint,
+		AccessKey:        storageCfg.AccessKey,
+		SecretKey:        storageCfg.SecretKey,
+		UseSSL:           storageCfg.UseSSL,
+		Bucket:           storageCfg.Bucket,
+		Region:           storageCfg.Region,
+		PublicURL:        storageCfg.PublicURL,
+		OperationTimeout: storageCfg.OperationTimeout,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize storage")
@@ -111,13 +322,18 @@ func main() {
 
 	// Initialize embedding registry (replaces ~70 lines of manual initialization)
 	embeddingRegistry, err := service.NewEmbeddingRegistry(&service.EmbeddingRegistryConfig{
-		Embeddings:        cfg.Embeddings,
-		QdrantHost:        cfg.Qdrant.Host,
-		QdrantPort:        cfg.Qdrant.Port,
-		QdrantAPIKey:      cfg.Qdrant.APIKey,
-		QdrantUseTLS:      cfg.Qdrant.UseTLS,
-		DefaultCollection: cfg.Qdrant.Collection,
-		Logger:            appLogger,
+		Embeddings:             cfg.Embeddings,
+		QdrantHost:             cfg.Qdrant.Host,
+		QdrantPort:             cfg.Qdrant.Port,
+		QdrantAPIKey:           cfg.Qdrant.APIKey,
+		QdrantUseTLS:           cfg.Qdrant.UseTLS,
+		QdrantPoolSize:         cfg.Qdrant.PoolSize,
+		QdrantKeepaliveTime:    cfg.Qdrant.KeepaliveTime,
+		QdrantKeepaliveTimeout: cfg.Qdrant.KeepaliveTimeout,
+		QdrantOperationTimeout: cfg.Qdrant.OperationTimeout,
+		DefaultCollection:      cfg.Qdrant.Collection,
+		Logger:                 appLogger,
+		Transport:              providerTransport,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize embedding registry")
@@ -138,6 +354,8 @@ func main() {
 		defaultVectorType = service.IngestVectorTypeForDocumentMode(defaultEmbeddingCfg.GetDocumentMode())
 	}
 
+	promptSet := bootstrap.Prompts(cfg, appLogger)
+
 	// Initialize query expansion service
 	// Use Query Expansion's own APIKey/BaseURL if configured, otherwise fall back to VLM's
 	qeAPIKey := cfg.Search.QueryExpansion.APIKey
@@ -153,7 +371,21 @@ func main() {
 		Model:   cfg.Search.QueryExpansion.Model,
 		APIKey:  qeAPIKey,
 		BaseURL: qeBaseURL,
+		Prompts: promptSet,
+
+		MaxConcurrent: cfg.Search.QueryExpansion.MaxConcurrent,
+		QueueTimeout:  cfg.Search.QueryExpansion.QueueTimeout,
+		CacheSize:     cfg.Search.QueryExpansion.CacheSize,
+		DefaultLang:   cfg.Search.QueryExpansion.DefaultLang,
+		Shared:        sharedCache,
+		Transport:     providerTransport,
 	})
+	if queryExpansionService.IsEnabled() && cfg.Search.QueryExpansion.PersistCache {
+		queryExpansionService.SetCacheStore(repository.NewQueryExpansionCacheRepository(db))
+		if _, err := queryExpansionService.LoadCache(ctx); err != nil {
+			appLogger.WithError(err).Warn("Failed to load query expansion cache")
+		}
+	}
 
 	if queryExpansionService.IsEnabled() {
 		appLogger.WithFields(logger.Fields{
@@ -161,6 +393,8 @@ func main() {
 		}).Info("Query expansion enabled")
 	}
 
+	mediaConverter := bootstrap.MediaConverter(cfg.Ingest.Media)
+
 	// Create search service
 	searchService := service.NewSearchService(
 		memeRepo,
@@ -171,12 +405,41 @@ func main() {
 		objectStorage,
 		appLogger,
 		&service.SearchConfig{
-			ScoreThreshold:    cfg.Search.ScoreThreshold,
-			DefaultCollection: defaultEmbeddingName,
-			DefaultProfile:    cfg.Search.DefaultProfile,
-			Retrieval:         serviceRetrievalConfig(cfg.Search.Retrieval),
+			ScoreThreshold:     cfg.Search.ScoreThreshold,
+			CategoryThresholds: categoryThresholds(cfg.Search.CategoryThresholds),
+			DefaultCollection:  defaultEmbeddingName,
+			DefaultProfile:     cfg.Search.DefaultProfile,
+			Retrieval:          serviceRetrievalConfig(cfg.Search.Retrieval),
 		},
 	)
+	searchService.SetVectorRepository(vectorRepo)
+	searchService.SetDescriptionChunking(service.DescriptionChunking{
+		ChunkRunes: cfg.Ingest.Chunking.ChunkRunes,
+		MaxChunks:  cfg.Ingest.Chunking.MaxChunks,
+	})
+	searchService.SetMediaConverter(mediaConverter, cfg.Ingest.Media.PosterOffset)
+	searchService.SetCache(service.SearchCacheConfig{
+		TTL:             cfg.Search.Cache.TTL,
+		QueryEmbeddings: cfg.Search.Cache.QueryEmbeddings,
+		Results:         cfg.Search.Cache.Results,
+		ResultTTL:       cfg.Search.Cache.ResultTTL,
+		Shared:          sharedCache,
+	})
+
+	if reranker, err := buildReranker(cfg, promptSet, providerTransport); err != nil {
+		appLogger.WithError(err).Warn("Search rerank disabled")
+	} else if reranker != nil {
+		searchService.SetReranker(reranker, cfg.Search.Rerank.TopN)
+		appLogger.WithFields(logger.Fields{
+			"provider": cfg.Search.Rerank.Provider,
+			"model":    cfg.Search.Rerank.Model,
+			"top_n":    cfg.Search.Rerank.TopN,
+		}).Info("Search rerank enabled")
+	}
+
+	// Query scene detection only helps once ingest writes scene tags.
+	sceneTagger := bootstrap.SceneTagger(cfg, promptSet, providerTransport)
+	searchService.SetQuerySceneDetection(sceneTagger.IsEnabled())
 
 	// Register all embedding collections with search service
 	for _, name := range embeddingRegistry.Names() {
@@ -193,13 +456,38 @@ func main() {
 		"default_qdrant":        defaultQdrantCollection,
 	}).Info("Embedding collections registered")
 
+	if err := searchService.SetShadowSearch(service.ShadowSearchConfig{
+		Target:      cfg.Search.Shadow.Target,
+		Percent:     cfg.Search.Shadow.Percent,
+		Timeout:     cfg.Search.Shadow.Timeout,
+		MaxInFlight: cfg.Search.Shadow.MaxInFlight,
+	}); err != nil {
+		appLogger.WithError(err).Warn("Shadow search disabled")
+	} else if cfg.Search.Shadow.Target != "" && cfg.Search.Shadow.Percent > 0 {
+		appLogger.WithFields(logger.Fields{
+			"target":  cfg.Search.Shadow.Target,
+			"percent": cfg.Search.Shadow.Percent,
+		}).Info("Shadow search enabled")
+	}
+
 	// Initialize VLM service
 	vlmService := service.NewVLMService(&service.VLMConfig{
-		Provider: cfg.VLM.Provider,
-		Model:    cfg.VLM.Model,
-		APIKey:   cfg.VLM.APIKey,
-		BaseURL:  cfg.VLM.BaseURL,
+		Provider:  cfg.VLM.Provider,
+		Model:     cfg.VLM.Model,
+		APIKey:    cfg.VLM.APIKey,
+		BaseURL:   cfg.VLM.BaseURL,
+		Prompts:   promptSet,
+		Transport: providerTransport,
 	})
+	if cfg.Search.ImageSearch.Enabled {
+		searchService.SetImageSearch(vlmService, service.ImageSearchConfig{
+			MaxBytes: cfg.Search.ImageSearch.MaxBytes,
+			Validation: service.ImageValidationConfig{
+				MaxWidth:  cfg.Search.ImageSearch.MaxWidth,
+				MaxHeight: cfg.Search.ImageSearch.MaxHeight,
+			},
+		})
+	}
 
 	var ingestIndexes []service.IngestVectorIndex
 	if defaultProfile := cfg.GetDefaultSearchProfile(); defaultProfile != nil {
@@ -222,17 +510,106 @@ func main() {
 		&service.IngestConfig{
 			Workers:       cfg.Ingest.Workers,
 			BatchSize:     cfg.Ingest.BatchSize,
+			QueueSize:     cfg.Ingest.QueueSize,
 			Collection:    defaultQdrantCollection,
 			VectorType:    defaultVectorType,
 			VectorIndexes: ingestIndexes,
+			UnitOfWork: repository.NewUnitOfWork(db, repository.TxRepositories{
+				Memes:        memeRepo,
+				Vectors:      vectorRepo,
+				Descriptions: descRepo,
+			}),
+			Validation: service.ImageValidationConfig{
+				MinWidth:  cfg.Ingest.Validation.MinWidth,
+				MinHeight: cfg.Ingest.Validation.MinHeight,
+				MaxWidth:  cfg.Ingest.Validation.MaxWidth,
+				MaxHeight: cfg.Ingest.Validation.MaxHeight,
+			},
+			Converter:     mediaConverter,
+			Origins:       bootstrap.OriginChecker(cfg, appLogger),
+			SparseEncoder: bootstrap.SparseEncoder(cfg.Ingest.Sparse),
+			PosterOffset:  cfg.Ingest.Media.PosterOffset,
+			SceneTagger:   sceneTagger,
+			Cleaner:       bootstrap.DescriptionCleaner(cfg.Ingest.Cleanup),
+			TextWeights:   bootstrap.EmbeddingTextWeights(cfg.Ingest.EmbeddingText),
+			Chunking: service.DescriptionChunking{
+				ChunkRunes: cfg.Ingest.Chunking.ChunkRunes,
+				MaxChunks:  cfg.Ingest.Chunking.MaxChunks,
+			},
+			NearDuplicates: bootstrap.NearDuplicatePolicy(cfg.Ingest.NearDuplicates),
+			Quality: service.DescriptionQualityConfig{
+				MinScore:     cfg.Ingest.Quality.MinScore,
+				Retry:        cfg.Ingest.Quality.Retry,
+				Disabled:     !cfg.Ingest.Quality.Enabled,
+				TagsFallback: cfg.Ingest.Quality.TagsFallback,
+			},
 		},
 	)
+	ingestService.SetSourceRepository(repository.NewDataSourceRepository(db))
+	ingestService.SetQuarantineRepository(repository.NewQuarantineRepository(db))
+	ingestService.SetJobRepository(repository.NewIngestJobRepository(db))
+	if failed, err := ingestService.FailInterruptedJobs(ctx, interruptedJobAge); err != nil {
+		appLogger.WithError(err).Warn("Failed to close interrupted ingest jobs")
+	} else if failed > 0 {
+		appLogger.WithFields(logger.Fields{"jobs": failed}).Warn("Marked interrupted ingest jobs failed")
+	}
+	ingestService.SetCategorySuggestionRepository(repository.NewCategorySuggestionRepository(db))
+	coverRepo := repository.NewCategoryCoverRepository(db)
+	ingestService.SetCategoryCoverRepository(coverRepo)
+	searchService.SetCategoryCoverRepository(coverRepo)
+	if cfg.Search.StatsHistory.Enabled {
+		searchService.SetStatsHistoryRepository(repository.NewStatsSnapshotRepository(db))
+		searchService.StartStatsSnapshots(ctx, cfg.Search.StatsHistory.SnapshotInterval)
+	}
+	if cfg.Search.Popularity.Enabled {
+		searchService.SetPopularity(repository.NewMemeActivityRepository(db), service.PopularityConfig{
+			Weight: cfg.Search.Popularity.Weight,
+			Days:   cfg.Search.Popularity.Days,
+			TopN:   cfg.Search.Popularity.TopN,
+		})
+		searchService.StartActivityFlush(ctx, cfg.Search.Popularity.FlushInterval)
+		if pruned, err := searchService.PruneActivity(ctx); err != nil {
+			appLogger.WithError(err).Warn("Failed to prune meme activity")
+		} else {
+			appLogger.WithFields(logger.Fields{
+				"weight": cfg.Search.Popularity.Weight,
+				"days":   cfg.Search.Popularity.Days,
+				"pruned": pruned,
+			}).Info("Search popularity enabled")
+		}
+	}
+	ingestService.SetCachePurger(bootstrap.CachePurger(cfg, appLogger))
+	ingestService.SetSourceLocker(sourceLocker)
+	ingestService.StartOriginVerifier(ctx, service.OriginVerifierConfig{
+		Interval:  cfg.Ingest.Origins.ReverifyInterval,
+		BatchSize: cfg.Ingest.Origins.ReverifyBatch,
+		MaxAge:    cfg.Ingest.Origins.ReverifyMaxAge,
+	})
 
 	// Initialize data sources
 	sources := buildSources(cfg)
+	if src, ok := sources["localdir"]; ok {
+		ingestService.SetSourceSkipRules(src.GetSourceID(), bootstrap.SkipRules(cfg.Sources.LocalDir.Skip))
+	}
+
+	// Initialize API key usage tracking
+	usageService := service.NewUsageService(&cfg.APIKeys, repository.NewAPIKeyUsageRepository(db))
+	usageService.SetKeyRepository(repository.NewAPIKeyRepository(db))
+	appLogger.WithFields(logger.Fields{
+		"keys":    len(cfg.APIKeys.Keys),
+		"require": cfg.APIKeys.Require,
+	}).Info("API keys configured")
+	// Release builds never serve ingest and admin routes to anonymous clients;
+	// api_keys.admin_auth can also be turned on with API_KEYS_ADMIN_AUTH=true
+	if cfg.Server.Mode == "release" && !usageService.AdminAuthRequired() {
+		appLogger.Fatal("Admin routes would be unauthenticated in release mode; set api_keys.admin_auth (API_KEYS_ADMIN_AUTH=true) or configure api_keys.keys with a role")
+	}
 
 	// Setup router
-	router := api.SetupRouter(searchService, ingestService, sources, cfg, appLogger)
+	readiness := handler.NewReadiness(!cfg.Server.Warmup.Enabled)
+	health := buildHealthChecker(cfg, db, defaultQdrantRepo, objectStorage, defaultProvider)
+	router := api.SetupRouter(searchService, ingestService, usageService, sources, readiness, health, cfg, appLogger)
+	logStartupReport(ctx, cfg, searchService, embeddingRegistry, sources, health, appLogger)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -256,6 +633,11 @@ func main() {
 		}
 	}()
 
+	// Serve /health/live right away but keep /health/ready at 503 until caches are warm
+	if cfg.Server.Warmup.Enabled {
+		go warmUp(searchService, readiness, cfg.Server.Warmup, appLogger)
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -270,6 +652,9 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown: %v", err)
 	}
+	if err := searchService.FlushActivity(shutdownCtx); err != nil {
+		logger.Error("Failed to flush meme activity: %v", err)
+	}
 
 	logger.Info("Server exited")
 }
@@ -0,0 +1,232 @@
+// discover proposes new categories for memes by clustering their embeddings.
+//
+// Use case: most memes of directory-less sources end up in 未分类. This tool
+// reads the dense vectors of one category from a Qdrant collection, groups
+// them with k-means, asks the LLM to name every cluster from the descriptions
+// of the memes closest to its center, and writes the names to the
+// category_suggestions review queue (GET /api/v1/admin/categories/suggestions).
+//
+// With --outliers it instead checks an existing category: memes far from the
+// category's centroid and closer to another category's centroid are queued
+// as suggestions to move them, one per target category. No LLM is called.
+//
+// Nothing is recategorized automatically; accepting a suggestion (POST
+// /api/v1/admin/categories/suggestions/:id/accept) moves its memes.
+//
+// Example:
+//
+//	go run ./cmd/discover --dry-run                  # cluster 未分类 and print cluster sizes
+//	go run ./cmd/discover --clusters 40 --min-size 10
+//	go run ./cmd/discover --embedding jina --category ""   # cluster every meme
+//	go run ./cmd/discover --outliers --category 猫猫        # memes of 猫猫 that fit another category better
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/timmy/emomo/internal/bootstrap"
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// scrollPageSize is the number of points read from Qdrant per request.
+const scrollPageSize = 256
+
+func main() {
+	appLogger := logger.New(&logger.Config{
+		Level:       "info",
+		Format:      "text",
+		ServiceName: "emomo-discover",
+	})
+	logger.SetDefaultLogger(appLogger)
+	defer logger.Sync()
+
+	configPath := flag.String("config", "", "Path to config file (defaults to ./configs/config.yaml)")
+	embeddingName := flag.String("embedding", "", "Embedding config name whose collection is clustered. Defaults to the config's default embedding")
+	category := flag.String("category", "未分类", "Category of the memes to cluster; empty clusters every meme")
+	clusters := flag.Int("clusters", 0, "Number of k-means clusters; 0 = sqrt(points/2)")
+	minSize := flag.Int("min-size", 5, "Minimum cluster size that gets a suggestion")
+	exemplars := flag.Int("exemplars", 8, "Memes per cluster shown to the LLM")
+	seed := flag.Int64("seed", 1, "Seed of the k-means initialization")
+	limit := flag.Int("limit", 0, "Maximum points to cluster; 0 = no limit")
+	model := flag.String("model", "", "Chat model for cluster labels. Defaults to the VLM model")
+	dryRun := flag.Bool("dry-run", false, "Cluster only: print cluster sizes without calling the LLM or storing suggestions")
+	outliers := flag.Bool("outliers", false, "Find memes of --category that are closer to another category's centroid instead of clustering")
+	deviations := flag.Float64("deviations", 2, "Outliers: standard deviations below the mean similarity to the category centroid")
+	margin := flag.Float64("margin", 0.02, "Outliers: similarity another category's centroid must add to be suggested")
+	flag.Parse()
+	if *outliers && (*category == "" || *category == "未分类") {
+		appLogger.Fatal("--outliers needs a --category other than 未分类")
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to load config")
+	}
+	providerTransport := bootstrap.ProviderTransport(cfg, appLogger)
+
+	db, err := repository.InitDB(&cfg.Database)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to initialize database")
+	}
+	memeRepo := repository.NewMemeRepository(db)
+	memeRepo.SetOperationTimeout(cfg.Database.OperationTimeout)
+
+	embeddingRegistry, err := service.NewEmbeddingRegistry(&service.EmbeddingRegistryConfig{
+		Embeddings:             cfg.Embeddings,
+		QdrantHost:             cfg.Qdrant.Host,
+		QdrantPort:             cfg.Qdrant.Port,
+		QdrantAPIKey:           cfg.Qdrant.APIKey,
+		QdrantUseTLS:           cfg.Qdrant.UseTLS,
+		QdrantPoolSize:         cfg.Qdrant.PoolSize,
+		QdrantKeepaliveTime:    cfg.Qdrant.KeepaliveTime,
+		QdrantKeepaliveTimeout: cfg.Qdrant.KeepaliveTimeout,
+		QdrantOperationTimeout: cfg.Qdrant.OperationTimeout,
+		DefaultCollection:      cfg.Qdrant.Collection,
+		Logger:                 appLogger,
+		Transport:              providerTransport,
+	})
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to initialize embedding registry")
+	}
+	defer embeddingRegistry.Close()
+
+	name := *embeddingName
+	if name == "" {
+		name = embeddingRegistry.DefaultName()
+	}
+	qdrantRepo, ok := embeddingRegistry.GetQdrantRepo(name)
+	if !ok {
+		appLogger.WithField("embedding", name).Fatal("Unknown embedding")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		appLogger.Warn("Received shutdown signal, canceling...")
+		cancel()
+	}()
+
+	// Outliers are compared with the centroids of every other category
+	readCategory := *category
+	if *outliers {
+		readCategory = ""
+	}
+	points, err := readPoints(ctx, qdrantRepo, readCategory, *limit)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to read points")
+	}
+	appLogger.WithFields(logger.Fields{
+		"collection": qdrantRepo.GetCollectionName(),
+		"category":   readCategory,
+		"points":     len(points),
+	}).Info("Read points for clustering")
+
+	existing, err := memeRepo.GetCategories(ctx)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to load categories")
+	}
+	known := existing[:0]
+	for _, c := range existing {
+		if c != "" && c != *category {
+			known = append(known, c)
+		}
+	}
+
+	labelModel := *model
+	if labelModel == "" {
+		labelModel = cfg.VLM.Model
+	}
+	discovery := service.NewCategoryDiscovery(&service.CategoryDiscoveryConfig{
+		Model:          labelModel,
+		APIKey:         cfg.VLM.APIKey,
+		BaseURL:        cfg.VLM.BaseURL,
+		Prompts:        bootstrap.Prompts(cfg, appLogger),
+		Clusters:       *clusters,
+		MinClusterSize: *minSize,
+		Exemplars:      *exemplars,
+		Seed:           *seed,
+		Deviations:     *deviations,
+		Margin:         *margin,
+		DryRun:         *dryRun,
+		Transport:      providerTransport,
+	}, repository.NewCategorySuggestionRepository(db))
+
+	if *outliers {
+		report, err := discovery.FindOutliers(ctx, qdrantRepo.GetCollectionName(), *category, points)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Outlier search failed")
+		}
+		for _, target := range report.Targets {
+			appLogger.WithFields(logger.Fields{
+				"label":     target.Label,
+				"size":      target.Size,
+				"exemplars": target.ExemplarIDs,
+			}).Info("Outlier target")
+		}
+		appLogger.WithFields(logger.Fields{
+			"run_id":      report.RunID,
+			"category":    report.Category,
+			"memes":       report.Memes,
+			"threshold":   report.Threshold,
+			"outliers":    report.Outliers,
+			"suggestions": report.Suggestions,
+			"dry_run":     *dryRun,
+		}).Info("Outlier search completed")
+		return
+	}
+
+	report, err := discovery.Discover(ctx, qdrantRepo.GetCollectionName(), *category, points, known)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Category discovery failed")
+	}
+	for _, cluster := range report.Clusters {
+		appLogger.WithFields(logger.Fields{
+			"size":      cluster.Size,
+			"label":     cluster.Label,
+			"exemplars": cluster.ExemplarIDs,
+		}).Info("Cluster")
+	}
+	appLogger.WithFields(logger.Fields{
+		"run_id":      report.RunID,
+		"points":      report.Points,
+		"clusters":    len(report.Clusters),
+		"suggestions": report.Suggestions,
+		"failed":      report.Failed,
+		"dry_run":     *dryRun,
+	}).Info("Category discovery completed")
+}
+
+// readPoints scrolls the collection for points of category with their vectors.
+func readPoints(ctx context.Context, repo *repository.QdrantRepository, category string, limit int) ([]repository.Point, error) {
+	var points []repository.Point
+	offset := ""
+	for {
+		page, next, err := repo.ScrollPoints(ctx, offset, scrollPageSize, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range page {
+			if point.Payload == nil || (category != "" && point.Payload.Category != category) {
+				continue
+			}
+			points = append(points, point)
+			if limit > 0 && len(points) >= limit {
+				return points, nil
+			}
+		}
+		if next == "" || len(page) == 0 {
+			return points, nil
+		}
+		offset = next
+	}
+}
@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
+	"github.com/timmy/emomo/internal/bootstrap"
 	"github.com/timmy/emomo/internal/config"
 	"github.com/timmy/emomo/internal/logger"
 	"github.com/timmy/emomo/internal/repository"
@@ -34,9 +39,65 @@ func selectSource(cfg *config.Config, sourceType string, pathOverride string) (s
 		SourceID:     cfg.Sources.LocalDir.SourceID,
 		ManifestPath: cfg.Sources.LocalDir.ManifestPath,
 		QueuePath:    cfg.Sources.LocalDir.QueuePath,
+		Priorities:   bootstrap.Priorities(cfg.Sources.LocalDir.Priorities),
 	}), nil
 }
 
+// splitList splits a comma-separated flag value, returning nil when it is empty.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePriorities parses a comma-separated list of pattern=priority pairs.
+func parsePriorities(value string) ([]source.CategoryPriority, error) {
+	var priorities []source.CategoryPriority
+	for _, pair := range splitList(value) {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid priority %q, want pattern=priority", pair)
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(pair[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority %q: %w", pair, err)
+		}
+		priorities = append(priorities, source.CategoryPriority{Pattern: strings.TrimSpace(pair[:i]), Priority: priority})
+	}
+	return priorities, nil
+}
+
+// writeTaxonomy writes a taxonomy as indented JSON to path, or stdout for "-".
+func writeTaxonomy(path string, taxonomy *service.Taxonomy) error {
+	data, err := json.MarshalIndent(taxonomy, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode taxonomy: %w", err)
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readTaxonomy reads a curated taxonomy JSON file.
+func readTaxonomy(path string) (*service.Taxonomy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var taxonomy service.Taxonomy
+	if err := json.Unmarshal(data, &taxonomy); err != nil {
+		return nil, fmt.Errorf("failed to parse taxonomy %s: %w", path, err)
+	}
+	return &taxonomy, nil
+}
+
 func main() {
 	// Initialize logger first (with defaults)
 	appLogger := logger.New(&logger.Config{
@@ -52,7 +113,20 @@ func main() {
 	sourcePath := flag.String("path", "", "Local static image directory path; overrides sources.localdir.root_path")
 	limit := flag.Int("limit", 100, "Maximum number of items to ingest")
 	retryPending := flag.Bool("retry", false, "Retry pending items instead of ingesting new ones")
+	promptReport := flag.Bool("prompt-report", false, "Report description counts per VLM prompt version and exit")
+	verifyOrigins := flag.Bool("verify-origins", false, "Re-check up to --limit stored source URLs and mark dead or blocked origins for review")
+	redescribe := flag.Bool("redescribe", false, "Regenerate up to --limit descriptions produced by outdated prompt versions")
+	exportTaxonomy := flag.String("export-taxonomy", "", "Write the category and tag taxonomy as JSON to this file (- for stdout) and exit")
+	importTaxonomy := flag.String("import-taxonomy", "", "Apply the renames and merges of a curated taxonomy JSON file and exit")
+	dryRun := flag.Bool("dry-run", false, "With --import-taxonomy, report what would change without applying it")
 	force := flag.Bool("force", false, "Force re-process items, skip duplicate checks")
+	review := flag.Bool("review", false, "Hold new memes in the moderation queue instead of publishing them")
+	minSize := flag.Int64("min-size", 0, "Skip files smaller than this many bytes for this run; overrides the source setting (-1 disables)")
+	skipFormats := flag.String("skip-formats", "", "Comma-separated formats to skip for this run, e.g. webm,mp4")
+	includeCategories := flag.String("include-categories", "", "Comma-separated category globs to ingest for this run")
+	excludeCategories := flag.String("exclude-categories", "", "Comma-separated category globs to skip for this run")
+	nearDuplicates := flag.String("near-duplicates", "", "Policy for images matching a stored meme's perceptual hash for this run: skip, link or force; overrides ingest.near_duplicates")
+	priorityFlag := flag.String("priority", "", "Comma-separated category priorities for this run, e.g. '热门*=10,新梗*=5'; higher runs first")
 	autoMigrate := flag.Bool("auto-migrate", false, "Run database auto-migrations before ingest")
 	configPath := flag.String("config", "", "Path to config file")
 	embeddingName := flag.String("embedding", "", "Embedding config name (e.g., 'jina', 'qwen3'). If empty, uses default")
@@ -64,6 +138,8 @@ func main() {
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to load config")
 	}
+	defer bootstrap.Metrics(cfg, "ingest", appLogger)()
+	providerTransport := bootstrap.ProviderTransport(cfg, appLogger)
 
 	if *autoMigrate {
 		cfg.Database.AutoMigrate = true
@@ -79,17 +155,23 @@ func main() {
 
 	// Initialize repositories
 	memeRepo := repository.NewMemeRepository(db)
+	memeRepo.SetOperationTimeout(cfg.Database.OperationTimeout)
 	vectorRepo := repository.NewMemeVectorRepository(db)
 	descRepo := repository.NewMemeDescriptionRepository(db)
 
 	embeddingRegistry, err := service.NewEmbeddingRegistry(&service.EmbeddingRegistryConfig{
-		Embeddings:        cfg.Embeddings,
-		QdrantHost:        cfg.Qdrant.Host,
-		QdrantPort:        cfg.Qdrant.Port,
-		QdrantAPIKey:      cfg.Qdrant.APIKey,
-		QdrantUseTLS:      cfg.Qdrant.UseTLS,
-		DefaultCollection: cfg.Qdrant.Collection,
-		Logger:            appLogger,
+		Embeddings:             cfg.Embeddings,
+		QdrantHost:             cfg.Qdrant.Host,
+		QdrantPort:             cfg.Qdrant.Port,
+		QdrantAPIKey:           cfg.Qdrant.APIKey,
+		QdrantUseTLS:           cfg.Qdrant.UseTLS,
+		QdrantPoolSize:         cfg.Qdrant.PoolSize,
+		QdrantKeepaliveTime:    cfg.Qdrant.KeepaliveTime,
+		QdrantKeepaliveTimeout: cfg.Qdrant.KeepaliveTimeout,
+		QdrantOperationTimeout: cfg.Qdrant.OperationTimeout,
+		DefaultCollection:      cfg.Qdrant.Collection,
+		Logger:                 appLogger,
+		Transport:              providerTransport,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize embedding registry")
@@ -100,6 +182,12 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Watch the DB pool for saturation/leaks during long ingest runs
+	repository.StartPoolMonitor(ctx, db, repository.PoolMonitorConfig{
+		Interval:        cfg.Database.PoolMonitorInterval,
+		SaturationRatio: cfg.Database.PoolSaturationRatio,
+	})
+
 	if err := embeddingRegistry.EnsureCollections(ctx); err != nil {
 		appLogger.WithError(err).Fatal("Failed to ensure Qdrant collections")
 	}
@@ -167,14 +255,15 @@ func main() {
 	// Initialize S3-compatible storage
 	storageCfg := cfg.GetStorageConfig()
 	objectStorage, err := storage.NewStorage(&storage.S3Config{
-		Type:      storage.StorageType(storageCfg.Type),
-		Endpoint:  storageCfg.Endpoint,
-		AccessKey: storageCfg.AccessKey,
-		SecretKey: storageCfg.SecretKey,
-		UseSSL:    storageCfg.UseSSL,
-		Bucket:    storageCfg.Bucket,
-		Region:    storageCfg.Region,
-		PublicURL: storageCfg.PublicURL,
+		Type:             storage.StorageType(storageCfg.Type),
+		Endpoint:         storageCfg.Endpoint,
+		AccessKey:        storageCfg.AccessKey,
+		SecretKey:        storageCfg.SecretKey,
+		UseSSL:           storageCfg.UseSSL,
+		Bucket:           storageCfg.Bucket,
+		Region:           storageCfg.Region,
+		PublicURL:        storageCfg.PublicURL,
+		OperationTimeout: storageCfg.OperationTimeout,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize storage")
@@ -184,14 +273,25 @@ func main() {
 		appLogger.WithError(err).Fatal("Failed to ensure storage bucket")
 	}
 
+	promptSet := bootstrap.Prompts(cfg, appLogger)
+
 	// Initialize VLM service
 	vlmService := service.NewVLMService(&service.VLMConfig{
-		Provider: cfg.VLM.Provider,
-		Model:    cfg.VLM.Model,
-		APIKey:   cfg.VLM.APIKey,
-		BaseURL:  cfg.VLM.BaseURL,
+		Provider:  cfg.VLM.Provider,
+		Model:     cfg.VLM.Model,
+		APIKey:    cfg.VLM.APIKey,
+		BaseURL:   cfg.VLM.BaseURL,
+		Prompts:   promptSet,
+		Transport: providerTransport,
 	})
 
+	sceneTagger := bootstrap.SceneTagger(cfg, promptSet, providerTransport)
+	if sceneTagger.IsEnabled() {
+		appLogger.WithFields(logger.Fields{
+			"model": cfg.Ingest.SceneTags.Model,
+		}).Info("Scene tagging enabled")
+	}
+
 	// Initialize ingest service
 	ingestService := service.NewIngestService(
 		memeRepo,
@@ -205,11 +305,52 @@ func main() {
 		&service.IngestConfig{
 			Workers:       cfg.Ingest.Workers,
 			BatchSize:     cfg.Ingest.BatchSize,
+			QueueSize:     cfg.Ingest.QueueSize,
 			Collection:    collectionName,
 			VectorType:    fallbackVectorType,
 			VectorIndexes: ingestIndexes,
+			UnitOfWork: repository.NewUnitOfWork(db, repository.TxRepositories{
+				Memes:        memeRepo,
+				Vectors:      vectorRepo,
+				Descriptions: descRepo,
+			}),
+			Validation: service.ImageValidationConfig{
+				MinWidth:  cfg.Ingest.Validation.MinWidth,
+				MinHeight: cfg.Ingest.Validation.MinHeight,
+				MaxWidth:  cfg.Ingest.Validation.MaxWidth,
+				MaxHeight: cfg.Ingest.Validation.MaxHeight,
+			},
+			Converter:     bootstrap.MediaConverter(cfg.Ingest.Media),
+			Origins:       bootstrap.OriginChecker(cfg, appLogger),
+			SparseEncoder: bootstrap.SparseEncoder(cfg.Ingest.Sparse),
+			PosterOffset:  cfg.Ingest.Media.PosterOffset,
+			SceneTagger:   sceneTagger,
+			Cleaner:       bootstrap.DescriptionCleaner(cfg.Ingest.Cleanup),
+			TextWeights:   bootstrap.EmbeddingTextWeights(cfg.Ingest.EmbeddingText),
+			Chunking: service.DescriptionChunking{
+				ChunkRunes: cfg.Ingest.Chunking.ChunkRunes,
+				MaxChunks:  cfg.Ingest.Chunking.MaxChunks,
+			},
+			NearDuplicates: bootstrap.NearDuplicatePolicy(cfg.Ingest.NearDuplicates),
+			Quality: service.DescriptionQualityConfig{
+				MinScore:     cfg.Ingest.Quality.MinScore,
+				Retry:        cfg.Ingest.Quality.Retry,
+				Disabled:     !cfg.Ingest.Quality.Enabled,
+				TagsFallback: cfg.Ingest.Quality.TagsFallback,
+			},
 		},
 	)
+	ingestService.SetSourceRepository(repository.NewDataSourceRepository(db))
+	ingestService.SetQuarantineRepository(repository.NewQuarantineRepository(db))
+	ingestService.SetJobRepository(repository.NewIngestJobRepository(db))
+	ingestService.SetCategoryCoverRepository(repository.NewCategoryCoverRepository(db))
+	ingestService.SetCachePurger(bootstrap.CachePurger(cfg, appLogger))
+	// Lock sources like the API replicas sharing redis.url, so a run never
+	// overlaps an API-triggered ingest of the same source
+	if redis := bootstrap.Redis(ctx, cfg, appLogger); redis != nil {
+		defer redis.Close()
+		ingestService.SetSourceLocker(redis)
+	}
 
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -221,7 +362,76 @@ func main() {
 	}()
 
 	// Run ingestion
-	if *retryPending {
+	if *promptReport {
+		report, err := ingestService.PromptVersionReport(ctx)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to build prompt version report")
+		}
+		for _, version := range report.Versions {
+			appLogger.WithFields(logger.Fields{
+				"prompt_version": version.PromptVersion,
+				"descriptions":   version.Count,
+				"current":        version.PromptVersion == report.CurrentVersion,
+			}).Info("Prompt version")
+		}
+		appLogger.WithFields(logger.Fields{
+			"vlm_model":       report.VLMModel,
+			"current_version": report.CurrentVersion,
+			"outdated":        report.Outdated,
+		}).Info("Prompt version report completed")
+	} else if *exportTaxonomy != "" {
+		taxonomy, err := ingestService.ExportTaxonomy(ctx)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to export taxonomy")
+		}
+		if err := writeTaxonomy(*exportTaxonomy, taxonomy); err != nil {
+			appLogger.WithError(err).Fatal("Failed to write taxonomy")
+		}
+		appLogger.WithFields(logger.Fields{
+			"categories": len(taxonomy.Categories),
+			"tags":       len(taxonomy.Tags),
+			"file":       *exportTaxonomy,
+		}).Info("Taxonomy exported")
+	} else if *importTaxonomy != "" {
+		taxonomy, err := readTaxonomy(*importTaxonomy)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to read taxonomy")
+		}
+		report, err := ingestService.ImportTaxonomy(ctx, taxonomy, *dryRun)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to import taxonomy")
+		}
+		appLogger.WithFields(logger.Fields{
+			"job_id":       report.JobID,
+			"memes":        report.Memes,
+			"updated":      report.UpdatedMemes,
+			"failed":       report.FailedMemes,
+			"moved_covers": report.MovedCovers,
+			"dry_run":      *dryRun,
+		}).Info("Taxonomy import completed")
+	} else if *verifyOrigins {
+		stats, err := ingestService.VerifyOrigins(ctx, *limit, cfg.Ingest.Origins.ReverifyMaxAge)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to verify origins")
+		}
+		appLogger.WithFields(logger.Fields{
+			"checked": stats.Checked,
+			"ok":      stats.OK,
+			"dead":    stats.Dead,
+			"blocked": stats.Blocked,
+			"errors":  stats.Errors,
+		}).Info("Origin verification completed")
+	} else if *redescribe {
+		stats, err := ingestService.RegenerateOutdatedDescriptions(ctx, *limit)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to regenerate descriptions")
+		}
+		appLogger.WithFields(logger.Fields{
+			"total":     stats.TotalItems,
+			"processed": stats.ProcessedItems,
+			"failed":    stats.FailedItems,
+		}).Info("Redescribe completed; run reembed --stale to refresh vectors")
+	} else if *retryPending {
 		stats, err := ingestService.RetryPending(ctx, *limit)
 		if err != nil {
 			appLogger.WithError(err).Fatal("Failed to retry pending items")
@@ -236,14 +446,37 @@ func main() {
 		if err != nil {
 			appLogger.WithError(err).WithField("source", *sourceType).Fatal("Failed to select source")
 		}
+		ingestService.SetSourceSkipRules(src.GetSourceID(), bootstrap.SkipRules(cfg.Sources.LocalDir.Skip))
+		priorities, err := parsePriorities(*priorityFlag)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Invalid --priority flag")
+		}
+		var runNearDuplicates service.NearDuplicatePolicy
+		if *nearDuplicates != "" {
+			if runNearDuplicates, err = service.ParseNearDuplicatePolicy(*nearDuplicates); err != nil {
+				appLogger.WithError(err).Fatal("Invalid --near-duplicates flag")
+			}
+		}
 
 		stats, err := ingestService.IngestFromSource(ctx, src, *limit, &service.IngestOptions{
 			Force: *force,
+			SkipRules: &service.SkipRules{
+				MinFileSize:       *minSize,
+				SkipFormats:       splitList(*skipFormats),
+				IncludeCategories: splitList(*includeCategories),
+				ExcludeCategories: splitList(*excludeCategories),
+			},
+			Priorities:     priorities,
+			NearDuplicates: runNearDuplicates,
+			Review:         *review,
 		})
-		if err != nil {
+		if errors.Is(err, context.Canceled) {
+			appLogger.WithError(err).Warn("Ingestion canceled; partial stats follow")
+		} else if err != nil {
 			appLogger.WithError(err).Fatal("Failed to ingest from source")
 		}
 		appLogger.WithFields(logger.Fields{
+			"job_id":     stats.JobID,
 			"total":      stats.TotalItems,
 			"processed":  stats.ProcessedItems,
 			"skipped":    stats.SkippedItems,
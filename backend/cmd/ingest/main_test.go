@@ -81,3 +81,17 @@ func TestSelectSourceRejectsChineseBQB(t *testing.T) {
 		t.Fatalf("expected unsupported source error, got %v", err)
 	}
 }
+
+func TestParsePriorities(t *testing.T) {
+	priorities, err := parsePriorities("热门*=10, new = 5")
+	if err != nil {
+		t.Fatalf("parsePriorities() error = %v", err)
+	}
+	if len(priorities) != 2 || priorities[0].Pattern != "热门*" || priorities[0].Priority != 10 || priorities[1].Pattern != "new" || priorities[1].Priority != 5 {
+		t.Fatalf("parsePriorities() = %+v", priorities)
+	}
+
+	if _, err := parsePriorities("hot"); err == nil {
+		t.Fatal("expected a pair without priority to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reembedCursor records how far a run got, so an interrupted migration can
+// resume instead of scanning every meme again. Every meme with an ID up to
+// AfterID has been processed.
+type reembedCursor struct {
+	Mode       string    `json:"mode"`        // Profile or embedding the cursor belongs to
+	VectorType string    `json:"vector_type"` // --vector-type of the run
+	AfterID    string    `json:"after_id"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// loadCursor reads the cursor at path. A missing file starts at the beginning.
+// A cursor of another mode or vector type is rejected, so it is never applied
+// to the wrong target collection.
+func loadCursor(path, mode, vectorType string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read cursor: %w", err)
+	}
+	var cursor reembedCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return "", fmt.Errorf("failed to parse cursor %s: %w", path, err)
+	}
+	if cursor.Mode != mode || cursor.VectorType != vectorType {
+		return "", fmt.Errorf("cursor %s belongs to mode=%s vector_type=%s, not mode=%s vector_type=%s",
+			path, cursor.Mode, cursor.VectorType, mode, vectorType)
+	}
+	return cursor.AfterID, nil
+}
+
+// saveCursor writes the cursor through a temporary file, so an interrupt never
+// leaves a truncated cursor behind.
+func saveCursor(path, mode, vectorType, afterID string) error {
+	data, err := json.MarshalIndent(reembedCursor{
+		Mode:       mode,
+		VectorType: vectorType,
+		AfterID:    afterID,
+		UpdatedAt:  time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cursor: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save cursor: %w", err)
+	}
+	return nil
+}
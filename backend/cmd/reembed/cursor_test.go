@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCursorRoundTripAndModeCheck(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "reembed.cursor")
+	if afterID, err := loadCursor(path, "qwen3vl", "all"); err != nil || afterID != "" {
+		t.Fatalf("loadCursor() of a missing file = %q, %v, want a fresh start", afterID, err)
+	}
+	if err := saveCursor(path, "qwen3vl", "all", "meme-0042"); err != nil {
+		t.Fatalf("saveCursor() error = %v", err)
+	}
+	if afterID, err := loadCursor(path, "qwen3vl", "all"); err != nil || afterID != "meme-0042" {
+		t.Fatalf("loadCursor() = %q, %v, want meme-0042", afterID, err)
+	}
+	if _, err := loadCursor(path, "jina", "all"); err == nil {
+		t.Fatalf("loadCursor() for another mode error = nil, want an error")
+	}
+	if _, err := loadCursor(path, "qwen3vl", "image"); err == nil {
+		t.Fatalf("loadCursor() for another vector type error = nil, want an error")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// dedupeScrollPageSize is the number of point IDs fetched per Qdrant scroll call.
+const dedupeScrollPageSize = 512
+
+type dedupeStats struct {
+	Scanned    int
+	Referenced int
+	Deleted    int
+}
+
+// dedupeVectorPoints deletes Qdrant points that no meme_vectors row references.
+// Before point IDs were derived from (md5, collection, vector type), forced
+// re-runs wrote a fresh random point each time and left the previous one
+// behind, so the same meme could show up several times in search results.
+//
+// Run it while no ingest is in flight: points written by an ingest that has not
+// committed its meme_vectors rows yet look unreferenced.
+func dedupeVectorPoints(
+	ctx context.Context,
+	vectorRepo *repository.MemeVectorRepository,
+	indexes []service.IngestVectorIndex,
+	dryRun bool,
+	log *logger.Logger,
+) (dedupeStats, error) {
+	var stats dedupeStats
+	seen := make(map[string]bool, len(indexes))
+
+	for _, index := range indexes {
+		if index.QdrantRepo == nil || seen[index.Collection] {
+			continue
+		}
+		seen[index.Collection] = true
+
+		pointIDs, err := vectorRepo.ListPointIDsByCollection(ctx, index.Collection)
+		if err != nil {
+			return stats, fmt.Errorf("failed to list point IDs for %s: %w", index.Collection, err)
+		}
+		referenced := make(map[string]struct{}, len(pointIDs))
+		for _, id := range pointIDs {
+			referenced[id] = struct{}{}
+		}
+
+		var orphans []string
+		offset := ""
+		for {
+			ids, next, err := index.QdrantRepo.ScrollPointIDs(ctx, offset, dedupeScrollPageSize)
+			if err != nil {
+				return stats, fmt.Errorf("failed to scroll %s: %w", index.Collection, err)
+			}
+			for _, id := range ids {
+				stats.Scanned++
+				if _, ok := referenced[id]; ok {
+					stats.Referenced++
+					continue
+				}
+				orphans = append(orphans, id)
+			}
+			if next == "" {
+				break
+			}
+			offset = next
+		}
+
+		log.WithFields(logger.Fields{
+			"collection": index.Collection,
+			"orphans":    len(orphans),
+			"dry_run":    dryRun,
+		}).Info("Scanned collection for unreferenced points")
+
+		if dryRun {
+			continue
+		}
+		for start := 0; start < len(orphans); start += dedupeScrollPageSize {
+			end := min(start+dedupeScrollPageSize, len(orphans))
+			if err := index.QdrantRepo.DeleteBatch(ctx, orphans[start:end]); err != nil {
+				return stats, fmt.Errorf("failed to delete points from %s: %w", index.Collection, err)
+			}
+			stats.Deleted += end - start
+		}
+	}
+
+	return stats, nil
+}
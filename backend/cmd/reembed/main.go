@@ -13,6 +13,8 @@
 //
 //	go run ./cmd/reembed --embedding jina --limit 5 --workers 4
 //	go run ./cmd/reembed --embedding jina --workers 8        # full backfill
+//	go run ./cmd/reembed --profile qwen3vl --dedupe-points   # drop orphaned duplicate points
+//	go run ./cmd/reembed --profile qwen3vl --stale           # migrate points built from outdated text
 package main
 
 import (
@@ -33,6 +35,7 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/timmy/emomo/internal/bootstrap"
 	"github.com/timmy/emomo/internal/config"
 	"github.com/timmy/emomo/internal/domain"
 	"github.com/timmy/emomo/internal/logger"
@@ -58,12 +61,17 @@ func main() {
 	workers := flag.Int("workers", 4, "Number of concurrent workers")
 	dryRun := flag.Bool("dry-run", false, "Plan only: count memes that would be embedded but do not call any APIs")
 	force := flag.Bool("force", false, "Re-embed even if a meme_vectors row already exists for the target collection")
+	stale := flag.Bool("stale", false, "Refresh existing points whose text input is outdated: re-embed changed caption vectors and rewrite changed BM25 sparse vectors")
+	cursorPath := flag.String("cursor", "", "Cursor file: resume after the last meme it records and update it after every page; removed once the run completes")
+	rate := flag.Float64("rate", 0, "Maximum memes embedded per second across all workers; 0 = no limit")
+	dedupePoints := flag.Bool("dedupe-points", false, "Delete Qdrant points not referenced by meme_vectors (duplicates from earlier runs) and exit")
 	flag.Parse()
 
 	cfg, err := config.Load(*configPath)
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to load config")
 	}
+	providerTransport := bootstrap.ProviderTransport(cfg, appLogger)
 	cfg.Database.AutoMigrate = false
 
 	db, err := repository.InitDB(&cfg.Database)
@@ -72,17 +80,23 @@ func main() {
 	}
 
 	memeRepo := repository.NewMemeRepository(db)
+	memeRepo.SetOperationTimeout(cfg.Database.OperationTimeout)
 	vectorRepo := repository.NewMemeVectorRepository(db)
 	descRepo := repository.NewMemeDescriptionRepository(db)
 
 	embeddingRegistry, err := service.NewEmbeddingRegistry(&service.EmbeddingRegistryConfig{
-		Embeddings:        cfg.Embeddings,
-		QdrantHost:        cfg.Qdrant.Host,
-		QdrantPort:        cfg.Qdrant.Port,
-		QdrantAPIKey:      cfg.Qdrant.APIKey,
-		QdrantUseTLS:      cfg.Qdrant.UseTLS,
-		DefaultCollection: cfg.Qdrant.Collection,
-		Logger:            appLogger,
+		Embeddings:             cfg.Embeddings,
+		QdrantHost:             cfg.Qdrant.Host,
+		QdrantPort:             cfg.Qdrant.Port,
+		QdrantAPIKey:           cfg.Qdrant.APIKey,
+		QdrantUseTLS:           cfg.Qdrant.UseTLS,
+		QdrantPoolSize:         cfg.Qdrant.PoolSize,
+		QdrantKeepaliveTime:    cfg.Qdrant.KeepaliveTime,
+		QdrantKeepaliveTimeout: cfg.Qdrant.KeepaliveTimeout,
+		QdrantOperationTimeout: cfg.Qdrant.OperationTimeout,
+		DefaultCollection:      cfg.Qdrant.Collection,
+		Logger:                 appLogger,
+		Transport:              providerTransport,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize embedding registry")
@@ -98,14 +112,15 @@ func main() {
 
 	storageCfg := cfg.GetStorageConfig()
 	objectStorage, err := storage.NewStorage(&storage.S3Config{
-		Type:      storage.StorageType(storageCfg.Type),
-		Endpoint:  storageCfg.Endpoint,
-		AccessKey: storageCfg.AccessKey,
-		SecretKey: storageCfg.SecretKey,
-		UseSSL:    storageCfg.UseSSL,
-		Bucket:    storageCfg.Bucket,
-		Region:    storageCfg.Region,
-		PublicURL: storageCfg.PublicURL,
+		Type:             storage.StorageType(storageCfg.Type),
+		Endpoint:         storageCfg.Endpoint,
+		AccessKey:        storageCfg.AccessKey,
+		SecretKey:        storageCfg.SecretKey,
+		UseSSL:           storageCfg.UseSSL,
+		Bucket:           storageCfg.Bucket,
+		Region:           storageCfg.Region,
+		PublicURL:        storageCfg.PublicURL,
+		OperationTimeout: storageCfg.OperationTimeout,
 	})
 	if err != nil {
 		appLogger.WithError(err).Fatal("Failed to initialize storage")
@@ -136,8 +151,25 @@ func main() {
 		"workers":        *workers,
 		"dry_run":        *dryRun,
 		"force":          *force,
+		"stale":          *stale,
+		"cursor":         *cursorPath,
+		"rate":           *rate,
 	}).Info("Starting reembed")
 
+	if *dedupePoints {
+		stats, err := dedupeVectorPoints(ctx, vectorRepo, vectorIndexes, *dryRun, appLogger)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Point dedupe failed")
+		}
+		appLogger.WithFields(logger.Fields{
+			"scanned":    stats.Scanned,
+			"referenced": stats.Referenced,
+			"deleted":    stats.Deleted,
+			"dry_run":    *dryRun,
+		}).Info("Point dedupe completed")
+		return
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
@@ -153,20 +185,52 @@ func main() {
 		descRepo:      descRepo,
 		objectStorage: objectStorage,
 		vectorIndexes: vectorIndexes,
+		cleaner:       bootstrap.DescriptionCleaner(cfg.Ingest.Cleanup),
+		textWeights: service.EmbeddingTextWeights{
+			OCR:         cfg.Ingest.EmbeddingText.OCRWeight,
+			Description: cfg.Ingest.EmbeddingText.DescriptionWeight,
+			Tags:        cfg.Ingest.EmbeddingText.TagsWeight,
+		},
+		sparseEncoder: bootstrap.SparseEncoder(cfg.Ingest.Sparse),
 		dryRun:        *dryRun,
 		force:         *force,
+		stale:         *stale,
+		mode:          modeName,
+		vectorType:    *vectorType,
+		cursorPath:    *cursorPath,
+	}
+	if *cursorPath != "" {
+		w.afterID, err = loadCursor(*cursorPath, modeName, *vectorType)
+		if err != nil {
+			appLogger.WithError(err).Fatal("Failed to load cursor")
+		}
+		if w.afterID != "" {
+			appLogger.WithField("after_id", w.afterID).Info("Resuming reembed from cursor")
+		}
+	}
+	if *rate > 0 && !*dryRun {
+		ticker := time.NewTicker(max(time.Nanosecond, time.Duration(float64(time.Second) / *rate)))
+		defer ticker.Stop()
+		w.throttle = ticker.C
 	}
 
 	stats, err := w.run(ctx, *limit, *workers)
 	if err != nil && !errors.Is(err, context.Canceled) {
 		appLogger.WithError(err).Fatal("reembed failed")
 	}
+	// A run that reached the end needs no cursor; a limited or interrupted run keeps it.
+	if err == nil && *limit == 0 && *cursorPath != "" && !*dryRun {
+		if err := os.Remove(*cursorPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			appLogger.WithError(err).Warn("Failed to remove cursor")
+		}
+	}
 
 	appLogger.WithFields(logger.Fields{
 		"scanned":         stats.Scanned,
 		"skipped_existed": stats.SkippedExisted,
 		"skipped_no_url":  stats.SkippedNoURL,
 		"reembedded":      stats.Reembedded,
+		"sparse_updated":  stats.SparseUpdated,
 		"failed":          stats.Failed,
 		"mode":            modeName,
 	}).Info("Reembed completed")
@@ -258,8 +322,18 @@ type worker struct {
 	descRepo      *repository.MemeDescriptionRepository
 	objectStorage storage.ObjectStorage
 	vectorIndexes []service.IngestVectorIndex
+	cleaner       *service.DescriptionCleaner
+	textWeights   service.EmbeddingTextWeights
+	sparseEncoder service.SparseEncoder
 	dryRun        bool
 	force         bool
+	stale         bool
+	throttle      <-chan time.Time // Ticks once per allowed embed when --rate is set (nil = unlimited)
+
+	mode       string // Profile or embedding name, recorded in the cursor
+	vectorType string
+	cursorPath string // --cursor file updated after every page (empty disables it)
+	afterID    string // Resume after this meme ID
 }
 
 type runStats struct {
@@ -267,15 +341,18 @@ type runStats struct {
 	SkippedExisted int64
 	SkippedNoURL   int64
 	Reembedded     int64
+	SparseUpdated  int64
 	Failed         int64
 }
 
 const pageSize = 200
 
-// run streams memes (status=active) page-by-page and feeds them through a
-// fixed pool of workers. Each worker may concurrently call the embedding API
-// and upsert into Qdrant — both backends tolerate parallelism, but the user
-// can throttle via --workers if they want to respect Jina rate limits.
+// run streams memes (status=active) page-by-page in ID order and feeds them
+// through a fixed pool of workers. Each worker may concurrently call the
+// embedding API and upsert into Qdrant — both backends tolerate parallelism,
+// but the user can throttle via --workers or --rate if they want to respect
+// Jina rate limits. Every page is finished before the next one starts, so
+// the cursor saved after it never skips a meme.
 func (w *worker) run(ctx context.Context, limit, workers int) (runStats, error) {
 	if workers <= 0 {
 		workers = 1
@@ -284,16 +361,18 @@ func (w *worker) run(ctx context.Context, limit, workers int) (runStats, error)
 	jobs := make(chan domain.Meme, workers*2)
 	stats := runStats{}
 
+	var listErr error
+	var pending sync.WaitGroup
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
 			for meme := range jobs {
-				if ctx.Err() != nil {
-					return
+				if ctx.Err() == nil {
+					w.processOne(ctx, meme, &stats)
 				}
-				w.processOne(ctx, meme, &stats)
+				pending.Done()
 			}
 		}(i)
 	}
@@ -301,45 +380,67 @@ func (w *worker) run(ctx context.Context, limit, workers int) (runStats, error)
 	go func() {
 		defer close(jobs)
 
-		offset := 0
+		afterID := w.afterID
 		emitted := 0
 		for {
 			if ctx.Err() != nil {
 				return
 			}
 
-			memes, err := w.memeRepo.ListByStatus(ctx, domain.MemeStatusActive, pageSize, offset)
+			memes, err := w.memeRepo.ListByStatusAfter(ctx, domain.MemeStatusActive, afterID, pageSize)
 			if err != nil {
-				w.log.WithError(err).WithField("offset", offset).Error("Failed to list memes; aborting page")
+				listErr = fmt.Errorf("failed to list memes after %q: %w", afterID, err)
 				return
 			}
 			if len(memes) == 0 {
 				return
 			}
 
+		page:
 			for _, meme := range memes {
 				if limit > 0 && emitted >= limit {
-					return
+					break
 				}
+				pending.Add(1)
 				select {
 				case <-ctx.Done():
-					return
+					pending.Done()
+					break page
 				case jobs <- meme:
 					emitted++
+					afterID = meme.ID
 				}
 			}
 
-			if len(memes) < pageSize {
+			pending.Wait()
+			if ctx.Err() != nil {
+				return
+			}
+			w.saveCursor(afterID)
+			if len(memes) < pageSize || (limit > 0 && emitted >= limit) {
 				return
 			}
-			offset += pageSize
 		}
 	}()
 
 	wg.Wait()
+	if listErr != nil {
+		return stats, listErr
+	}
 	return stats, ctx.Err()
 }
 
+// saveCursor records that every meme up to afterID has been processed.
+// Dry runs never move the cursor.
+func (w *worker) saveCursor(afterID string) {
+	if w.cursorPath == "" || w.dryRun {
+		return
+	}
+	if err := saveCursor(w.cursorPath, w.mode, w.vectorType, afterID); err != nil {
+		w.log.WithError(err).WithField("cursor", w.cursorPath).Warn("Failed to save cursor")
+	}
+}
+
 // processOne handles a single meme. It is called from a worker goroutine, so
 // it talks to its own copy of `meme` and only mutates `stats` via atomics.
 func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runStats) {
@@ -361,33 +462,44 @@ func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runSta
 		w.log.WithField("meme_id", meme.ID).Warn("Storage object is missing")
 		return
 	}
-	imageURL := w.objectStorage.GetURL(meme.StorageKey)
-	if imageURL == "" {
+	storageURL := w.objectStorage.GetURL(meme.StorageKey)
+	if storageURL == "" {
 		atomic.AddInt64(&stats.SkippedNoURL, 1)
 		w.log.WithField("meme_id", meme.ID).Warn("Storage backend returned empty URL")
 		return
 	}
 
+	// Animated memes are embedded through their static poster frame.
+	imageURL := storageURL
+	posterURL := ""
+	if meme.PosterKey != "" {
+		posterURL = w.objectStorage.GetURL(meme.PosterKey)
+		imageURL = posterURL
+	}
+
 	// Look up an existing VLM description (any model) so we can populate the
 	// Qdrant payload + BM25 sparse vector. Reembed never invokes the VLM.
 	desc := w.lookupDescription(ctx, meme.ID)
 	vlmDescription := ""
 	ocrText := ""
 	descriptionID := ""
+	var sceneTags []string
 	if desc != nil {
 		vlmDescription = desc.Description
 		ocrText = service.NormalizeOCRText(desc.OCRText)
 		descriptionID = desc.ID
+		sceneTags = desc.SceneTags
 	}
 
-	captionText := service.BuildCaptionEmbeddingText(
+	compactDesc := service.CompactDescription(w.cleaner.Clean(vlmDescription))
+	captionText := w.textWeights.CaptionText(
 		ocrText,
-		service.CompactDescription(vlmDescription),
+		compactDesc,
 		meme.Category,
 		meme.Tags,
 		service.ExtractEmotionWords(vlmDescription),
 	)
-	bm25Text := service.BuildBM25Text(ocrText, service.CompactDescription(vlmDescription), meme.Tags)
+	bm25Text := service.BuildBM25Text(ocrText, compactDesc, meme.Tags)
 	payload := &repository.MemePayload{
 		MemeID:         meme.ID,
 		SourceType:     meme.SourceType,
@@ -395,7 +507,27 @@ func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runSta
 		Tags:           meme.Tags,
 		VLMDescription: vlmDescription,
 		OCRText:        ocrText,
-		StorageURL:     imageURL,
+		StorageURL:     storageURL,
+		PosterURL:      posterURL,
+		Colors:         service.PaletteColorNames(meme.DominantColors),
+		Saturation:     meme.Saturation,
+		TextLang:       service.DetectTextLanguage(ocrText),
+		SceneTags:      sceneTags,
+	}
+
+	if w.stale {
+		storedBM25Text := ""
+		if desc != nil {
+			storedBM25Text = desc.BM25Text
+		}
+		w.refreshStale(ctx, meme, vectorPayloadInput{
+			ImageURL:      imageURL,
+			CaptionText:   captionText,
+			BM25Text:      bm25Text,
+			DescriptionID: descriptionID,
+			Payload:       payload,
+		}, storedBM25Text, stats)
+		return
 	}
 
 	if w.dryRun {
@@ -416,6 +548,14 @@ func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runSta
 		return
 	}
 
+	if w.throttle != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.throttle:
+		}
+	}
+
 	embedStart := time.Now()
 	wrote := 0
 	for _, index := range w.vectorIndexes {
@@ -428,7 +568,7 @@ func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runSta
 			BM25Text:      bm25Text,
 			DescriptionID: descriptionID,
 			Payload:       payload,
-		}); err != nil {
+		}, w.force); err != nil {
 			atomic.AddInt64(&stats.Failed, 1)
 			w.log.WithError(err).WithFields(logger.Fields{
 				"meme_id":     meme.ID,
@@ -445,6 +585,11 @@ func (w *worker) processOne(ctx context.Context, meme domain.Meme, stats *runSta
 		atomic.AddInt64(&stats.SkippedExisted, 1)
 		return
 	}
+	if desc != nil && desc.BM25Text != bm25Text {
+		if err := w.descRepo.UpdateBM25Text(ctx, desc.ID, bm25Text); err != nil {
+			w.log.WithError(err).WithField("meme_id", meme.ID).Warn("Failed to record BM25 text")
+		}
+	}
 	w.log.WithFields(logger.Fields{
 		"meme_id":     meme.ID,
 		"vectors":     wrote,
@@ -460,6 +605,107 @@ type vectorPayloadInput struct {
 	Payload       *repository.MemePayload
 }
 
+// refreshStale migrates the existing points of a meme to the current embedding
+// text. Caption vectors whose input hash no longer matches the structured
+// caption text are re-embedded; sparse vectors whose BM25 text differs from the
+// one recorded on the description only get their sparse vector rewritten.
+// Memes without points are left to a regular run.
+func (w *worker) refreshStale(ctx context.Context, meme domain.Meme, input vectorPayloadInput, storedBM25Text string, stats *runStats) {
+	bm25Changed := input.BM25Text != storedBM25Text
+	captionHash := calculateTextSHA256(input.CaptionText)
+
+	refreshed := 0
+	failed := false
+	for _, index := range w.vectorIndexes {
+		existing, err := w.vectorRepo.GetByMD5CollectionAndVectorType(ctx, meme.MD5Hash, index.Collection, index.VectorType)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			failed = true
+			atomic.AddInt64(&stats.Failed, 1)
+			w.log.WithError(err).WithFields(logger.Fields{
+				"meme_id":    meme.ID,
+				"collection": index.Collection,
+			}).Error("Failed to load vector record")
+			continue
+		}
+
+		fields := logger.Fields{
+			"meme_id":     meme.ID,
+			"collection":  index.Collection,
+			"vector_type": index.VectorType,
+		}
+		switch {
+		case index.VectorType == domain.MemeVectorTypeCaption && input.CaptionText != "" && existing.InputHash != captionHash:
+			if w.dryRun {
+				w.log.WithFields(fields).Info("[dry-run] would re-embed stale caption vector")
+			} else if err := w.processVectorIndex(ctx, meme, index, input, true); err != nil {
+				failed = true
+				atomic.AddInt64(&stats.Failed, 1)
+				w.log.WithError(err).WithFields(fields).Error("Failed to re-embed stale caption vector")
+				continue
+			}
+			atomic.AddInt64(&stats.Reembedded, 1)
+		case index.UseSparse && bm25Changed:
+			if w.dryRun {
+				w.log.WithFields(fields).Info("[dry-run] would rewrite stale sparse vector")
+			} else if err := w.rewriteSparseVectors(ctx, index, existing.QdrantPointID, input.BM25Text); err != nil {
+				failed = true
+				atomic.AddInt64(&stats.Failed, 1)
+				w.log.WithError(err).WithFields(fields).Error("Failed to rewrite stale sparse vector")
+				continue
+			}
+			atomic.AddInt64(&stats.SparseUpdated, 1)
+		default:
+			continue
+		}
+		refreshed++
+	}
+
+	if refreshed == 0 && !failed {
+		atomic.AddInt64(&stats.SkippedExisted, 1)
+	}
+	// Record the BM25 text only once every point carries it, so a failed
+	// point is retried by the next --stale run.
+	if w.dryRun || failed || !bm25Changed || input.DescriptionID == "" {
+		return
+	}
+	if err := w.descRepo.UpdateBM25Text(ctx, input.DescriptionID, input.BM25Text); err != nil {
+		atomic.AddInt64(&stats.Failed, 1)
+		w.log.WithError(err).WithField("meme_id", meme.ID).Error("Failed to record BM25 text")
+	}
+}
+
+// rewriteSparseVectors replaces the BM25 vector of a point and, with an
+// encoder configured, its learned sparse vector.
+func (w *worker) rewriteSparseVectors(ctx context.Context, index service.IngestVectorIndex, pointID, bm25Text string) error {
+	if err := index.QdrantRepo.UpdateSparseVector(ctx, pointID, bm25Text); err != nil {
+		return err
+	}
+	learned, err := w.encodeLearnedSparse(ctx, bm25Text)
+	if err != nil || learned == nil {
+		return err
+	}
+	return index.QdrantRepo.UpdateLearnedSparseVector(ctx, pointID, *learned)
+}
+
+// encodeLearnedSparse encodes BM25 text like ingest does, returning nil when
+// no encoder is configured or the text is empty.
+func (w *worker) encodeLearnedSparse(ctx context.Context, text string) (*repository.SparseVector, error) {
+	if w.sparseEncoder == nil || strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	vectors, err := w.sparseEncoder.EncodeSparse(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode learned sparse vector: %w", err)
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("sparse encoder returned no vector")
+	}
+	return &vectors[0], nil
+}
+
 func (w *worker) shouldProcessIndex(ctx context.Context, meme domain.Meme, index service.IngestVectorIndex, captionText string, stats *runStats) bool {
 	if index.VectorType == domain.MemeVectorTypeCaption && captionText == "" {
 		atomic.AddInt64(&stats.SkippedNoURL, 1)
@@ -488,8 +734,10 @@ func (w *worker) shouldProcessIndex(ctx context.Context, meme domain.Meme, index
 	return true
 }
 
-func (w *worker) processVectorIndex(ctx context.Context, meme domain.Meme, index service.IngestVectorIndex, input vectorPayloadInput) error {
-	if w.force {
+// processVectorIndex embeds one vector route of a meme and records it. With
+// replace set, the existing point and meme_vectors row are dropped first.
+func (w *worker) processVectorIndex(ctx context.Context, meme domain.Meme, index service.IngestVectorIndex, input vectorPayloadInput, replace bool) error {
+	if replace {
 		existing, err := w.vectorRepo.GetByMD5CollectionAndVectorType(ctx, meme.MD5Hash, index.Collection, index.VectorType)
 		if err == nil && existing != nil {
 			if delErr := index.QdrantRepo.Delete(ctx, existing.QdrantPointID); delErr != nil {
@@ -520,9 +768,13 @@ func (w *worker) processVectorIndex(ctx context.Context, meme domain.Meme, index
 		return fmt.Errorf("EmbedDocument failed after retries: %w", err)
 	}
 
-	pointID := uuid.New().String()
+	pointID := index.PointID(meme.MD5Hash)
 	if index.UseSparse {
-		if err := index.QdrantRepo.UpsertHybrid(ctx, pointID, embedding, input.BM25Text, input.Payload); err != nil {
+		learned, err := w.encodeLearnedSparse(ctx, input.BM25Text)
+		if err != nil {
+			return err
+		}
+		if err := index.QdrantRepo.UpsertHybridLearned(ctx, pointID, embedding, input.BM25Text, learned, input.Payload); err != nil {
 			return fmt.Errorf("UpsertHybrid failed: %w", err)
 		}
 	} else {
@@ -2,11 +2,19 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/api/stream"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/i18n"
 	"github.com/timmy/emomo/internal/logger"
 	"github.com/timmy/emomo/internal/service"
 	"github.com/timmy/emomo/internal/source"
@@ -19,11 +27,14 @@ type AdminHandler struct {
 	logger        *logger.Logger
 
 	// Ingest job state
+	jobs          *jobLimiter
+	webhook       WebhookConfig // Staging manifest webhook (see SetWebhook)
+	staging       StagingConfig // Staging uploads (see SetStaging)
 	mu            sync.RWMutex
-	isRunning     bool
 	currentStats  *service.IngestStats
 	lastRunTime   time.Time
 	lastRunStatus string
+	sourceRuns    map[string]SourceJobStatus // Last run per source ID
 }
 
 // NewAdminHandler creates a new admin handler.
@@ -38,9 +49,20 @@ func NewAdminHandler(ingestService *service.IngestService, sources map[string]so
 		ingestService: ingestService,
 		sources:       sources,
 		logger:        log,
+		jobs:          newJobLimiter(JobLimits{}),
+		sourceRuns:    make(map[string]SourceJobStatus),
 	}
 }
 
+// SetJobLimits sets how many admin jobs may run at once, overall and per source.
+// Call it before serving requests.
+// Parameters:
+//   - limits: concurrency and queue limits.
+// Returns: none.
+func (h *AdminHandler) SetJobLimits(limits JobLimits) {
+	h.jobs = newJobLimiter(limits)
+}
+
 // log returns a logger from Gin context if available, otherwise returns the default logger
 func (h *AdminHandler) log(c *gin.Context) *logger.Logger {
 	if l := logger.FromContext(c.Request.Context()); l != nil {
@@ -54,11 +76,29 @@ type IngestRequest struct {
 	Source string `json:"source" binding:"required"`
 	Limit  int    `json:"limit" binding:"required,min=1,max=10000"`
 	Force  bool   `json:"force"`
+
+	// Optional skip rules for this run; set fields override the source's rules.
+	MinFileSize       int64    `json:"min_file_size"` // -1 disables the source's size check
+	SkipFormats       []string `json:"skip_formats"`
+	IncludeCategories []string `json:"include_categories"`
+	ExcludeCategories []string `json:"exclude_categories"`
+
+	// Optional category priorities for this run; matching items are ingested first.
+	Priorities []source.CategoryPriority `json:"priorities"`
+
+	// Review holds new memes of this run in the moderation queue.
+	Review bool `json:"review"`
+
+	// rescan drops the source's cached item list when the job starts, so a
+	// manifest written since the last scan is read.
+	rescan bool
 }
 
 // IngestResponse represents the ingest API response.
 type IngestResponse struct {
 	Message string               `json:"message"`
+	JobID   string               `json:"job_id,omitempty"` // Poll GET /api/v1/ingest/jobs/:id for progress
+	Status  domain.JobStatus     `json:"status,omitempty"`
 	Stats   *service.IngestStats `json:"stats,omitempty"`
 }
 
@@ -68,274 +108,13 @@ type IngestStatusResponse struct {
 	LastRunTime   string               `json:"last_run_time,omitempty"`
 	LastRunStatus string               `json:"last_run_status,omitempty"`
 	CurrentStats  *service.IngestStats `json:"current_stats,omitempty"`
+
+	Sources map[string]SourceJobStatus `json:"sources,omitempty"` // Jobs and last run per source ID
 }
 
-// AdminPage serves the admin dashboard HTML page.
-// Parameters:
-//   - c: Gin request context.
-// Returns: none (writes HTML response).
-func (h *AdminHandler) AdminPage(c *gin.Context) {
-	html := `<!DOCTYPE html>
-<html lang="zh-CN">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Emomo Admin</title>
-    <style>
-        * { box-sizing: border-box; margin: 0; padding: 0; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            min-height: 100vh;
-            padding: 2rem;
-        }
-        .container {
-            max-width: 600px;
-            margin: 0 auto;
-        }
-        .card {
-            background: white;
-            border-radius: 16px;
-            padding: 2rem;
-            box-shadow: 0 10px 40px rgba(0,0,0,0.2);
-            margin-bottom: 1.5rem;
-        }
-        h1 {
-            color: #333;
-            margin-bottom: 0.5rem;
-            font-size: 1.8rem;
-        }
-        .subtitle {
-            color: #666;
-            margin-bottom: 1.5rem;
-        }
-        .form-group {
-            margin-bottom: 1rem;
-        }
-        label {
-            display: block;
-            margin-bottom: 0.5rem;
-            color: #444;
-            font-weight: 500;
-        }
-        select, input[type="number"] {
-            width: 100%;
-            padding: 0.75rem;
-            border: 2px solid #e0e0e0;
-            border-radius: 8px;
-            font-size: 1rem;
-            transition: border-color 0.2s;
-        }
-        select:focus, input:focus {
-            outline: none;
-            border-color: #667eea;
-        }
-        .checkbox-group {
-            display: flex;
-            align-items: center;
-            gap: 0.5rem;
-        }
-        .checkbox-group input {
-            width: 18px;
-            height: 18px;
-        }
-        button {
-            width: 100%;
-            padding: 1rem;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            color: white;
-            border: none;
-            border-radius: 8px;
-            font-size: 1.1rem;
-            font-weight: 600;
-            cursor: pointer;
-            transition: transform 0.2s, box-shadow 0.2s;
-        }
-        button:hover:not(:disabled) {
-            transform: translateY(-2px);
-            box-shadow: 0 5px 20px rgba(102, 126, 234, 0.4);
-        }
-        button:disabled {
-            opacity: 0.6;
-            cursor: not-allowed;
-        }
-        .status {
-            padding: 1rem;
-            border-radius: 8px;
-            margin-top: 1rem;
-            display: none;
-        }
-        .status.success {
-            background: #d4edda;
-            color: #155724;
-            display: block;
-        }
-        .status.error {
-            background: #f8d7da;
-            color: #721c24;
-            display: block;
-        }
-        .status.running {
-            background: #fff3cd;
-            color: #856404;
-            display: block;
-        }
-        .stats {
-            margin-top: 1rem;
-            padding: 1rem;
-            background: #f8f9fa;
-            border-radius: 8px;
-        }
-        .stats-row {
-            display: flex;
-            justify-content: space-between;
-            padding: 0.5rem 0;
-            border-bottom: 1px solid #e0e0e0;
-        }
-        .stats-row:last-child {
-            border-bottom: none;
-        }
-        .quick-links {
-            display: flex;
-            gap: 1rem;
-            flex-wrap: wrap;
-        }
-        .quick-links a {
-            flex: 1;
-            min-width: 120px;
-            padding: 0.75rem;
-            background: #f8f9fa;
-            color: #333;
-            text-decoration: none;
-            border-radius: 8px;
-            text-align: center;
-            transition: background 0.2s;
-        }
-        .quick-links a:hover {
-            background: #e9ecef;
-        }
-        .spinner {
-            display: inline-block;
-            width: 16px;
-            height: 16px;
-            border: 2px solid #ffffff;
-            border-radius: 50%;
-            border-top-color: transparent;
-            animation: spin 1s linear infinite;
-            margin-right: 8px;
-        }
-        @keyframes spin {
-            to { transform: rotate(360deg); }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="card">
-            <h1>🎭 Emomo Admin</h1>
-            <p class="subtitle">表情包语义搜索系统管理面板</p>
-
-            <form id="ingestForm">
-	                <div class="form-group">
-	                    <label for="source">数据源</label>
-	                    <select id="source" name="source">
-	                        <option value="localdir">本地静态图片目录</option>
-	                    </select>
-	                </div>
-
-                <div class="form-group">
-                    <label for="limit">导入数量</label>
-                    <input type="number" id="limit" name="limit" value="100" min="1" max="10000">
-                </div>
-
-                <div class="form-group">
-                    <div class="checkbox-group">
-                        <input type="checkbox" id="force" name="force">
-                        <label for="force" style="margin: 0;">强制重新处理（跳过重复检查）</label>
-                    </div>
-                </div>
-
-                <button type="submit" id="submitBtn">
-                    开始导入
-                </button>
-            </form>
-
-            <div id="status" class="status"></div>
-            <div id="stats" class="stats" style="display: none;"></div>
-        </div>
-
-        <div class="card">
-            <h2 style="margin-bottom: 1rem;">快速链接</h2>
-            <div class="quick-links">
-                <a href="/api/v1/stats">📊 系统统计</a>
-                <a href="/api/v1/categories">📁 分类列表</a>
-                <a href="/api/v1/memes?limit=10">🖼️ 表情包</a>
-                <a href="/health">💚 健康检查</a>
-            </div>
-        </div>
-    </div>
-
-    <script>
-        const form = document.getElementById('ingestForm');
-        const submitBtn = document.getElementById('submitBtn');
-        const statusDiv = document.getElementById('status');
-        const statsDiv = document.getElementById('stats');
-
-        form.addEventListener('submit', async (e) => {
-            e.preventDefault();
-
-            const source = document.getElementById('source').value;
-            const limit = parseInt(document.getElementById('limit').value);
-            const force = document.getElementById('force').checked;
-
-            submitBtn.disabled = true;
-            submitBtn.innerHTML = '<span class="spinner"></span>导入中...';
-            statusDiv.className = 'status running';
-            statusDiv.textContent = '正在导入数据，请稍候...';
-            statsDiv.style.display = 'none';
-
-            try {
-                const response = await fetch('/api/v1/ingest', {
-                    method: 'POST',
-                    headers: { 'Content-Type': 'application/json' },
-                    body: JSON.stringify({ source, limit, force })
-                });
-
-                const data = await response.json();
-
-                if (response.ok) {
-                    statusDiv.className = 'status success';
-                    statusDiv.textContent = '✓ ' + data.message;
-
-                    if (data.stats) {
-                        statsDiv.style.display = 'block';
-                        statsDiv.innerHTML = ` + "`" + `
-                            <div class="stats-row"><span>总计</span><span>${data.stats.TotalItems}</span></div>
-                            <div class="stats-row"><span>已处理</span><span>${data.stats.ProcessedItems}</span></div>
-                            <div class="stats-row"><span>跳过</span><span>${data.stats.SkippedItems}</span></div>
-                            <div class="stats-row"><span>失败</span><span>${data.stats.FailedItems}</span></div>
-                        ` + "`" + `;
-                    }
-                } else {
-                    statusDiv.className = 'status error';
-                    statusDiv.textContent = '✗ ' + (data.error || '导入失败');
-                }
-            } catch (err) {
-                statusDiv.className = 'status error';
-                statusDiv.textContent = '✗ 网络错误: ' + err.message;
-            } finally {
-                submitBtn.disabled = false;
-                submitBtn.textContent = '开始导入';
-            }
-        });
-    </script>
-</body>
-</html>`
-	c.Header("Content-Type", "text/html; charset=utf-8")
-	c.String(http.StatusOK, html)
-}
-
-// TriggerIngest handles the ingest API endpoint.
+// TriggerIngest handles the ingest API endpoint. It validates the request,
+// queues an ingest job and returns 202 with its ID; the job runs in the
+// background once a slot of the source is free.
 // Parameters:
 //   - c: Gin request context.
 // Returns: none (writes JSON response).
@@ -345,67 +124,116 @@ func (h *AdminHandler) TriggerIngest(c *gin.Context) {
 	var req IngestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.CtxWarn(ctx, "Invalid ingest request: client_ip=%s, error=%v", c.ClientIP(), err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
 		return
 	}
 
 	logger.CtxInfo(ctx, "Received ingest request: source=%s, limit=%d, force=%v, client_ip=%s",
 		req.Source, req.Limit, req.Force, c.ClientIP())
 
-	// Check if ingest is already running
-	h.mu.RLock()
-	if h.isRunning {
-		h.mu.RUnlock()
-		logger.CtxWarn(ctx, "Ingest request rejected: already running, source=%s, client_ip=%s",
-			req.Source, c.ClientIP())
-		c.JSON(http.StatusConflict, gin.H{"error": "Ingest is already running"})
+	skipRules := &service.SkipRules{
+		MinFileSize:       req.MinFileSize,
+		SkipFormats:       req.SkipFormats,
+		IncludeCategories: req.IncludeCategories,
+		ExcludeCategories: req.ExcludeCategories,
+	}
+	if err := skipRules.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	if err := source.ValidatePriorities(req.Priorities); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
 		return
 	}
-	h.mu.RUnlock()
 
 	// Get source
 	src, ok := h.sources[req.Source]
 	if !ok {
 		logger.CtxWarn(ctx, "Unknown source requested: source=%s, client_ip=%s", req.Source, c.ClientIP())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown source: " + req.Source})
+		respondError(c, http.StatusBadRequest, i18n.MsgUnknownSource, req.Source)
 		return
 	}
 
-	// Set running state
-	h.mu.Lock()
-	h.isRunning = true
-	h.currentStats = nil
-	h.mu.Unlock()
+	h.queueIngest(c, src, &req, &service.IngestOptions{
+		Force:      req.Force,
+		SkipRules:  skipRules,
+		Priorities: req.Priorities,
+		Review:     req.Review,
+	})
+}
+
+// queueIngest takes a job slot or queue place of src, records the job and
+// runs it in the background, answering 202 with the job ID.
+func (h *AdminHandler) queueIngest(c *gin.Context, src source.Source, req *IngestRequest, opts *service.IngestOptions) {
+	ctx := c.Request.Context()
 
-	logger.CtxInfo(ctx, "Starting ingest process: source=%s, limit=%d, force=%v",
-		req.Source, req.Limit, req.Force)
+	// Take a job slot or queue place of the source now, so a full queue is
+	// rejected before the job is accepted; other sources run independently
+	ticket, err := h.jobs.enqueue(src.GetSourceID())
+	if err != nil {
+		logger.CtxWarn(ctx, "Ingest request rejected: source=%s, client_ip=%s, error=%v",
+			req.Source, c.ClientIP(), err)
+		respondError(c, http.StatusConflict, i18n.MsgIngestRunning, req.Source)
+		return
+	}
 
-	// Run ingest (use background context to avoid cancellation on HTTP timeout)
-	ingestCtx := context.Background()
-	startTime := time.Now()
-	stats, err := h.ingestService.IngestFromSource(ingestCtx, src, req.Limit, &service.IngestOptions{
-		Force: req.Force,
+	job, err := h.ingestService.QueueIngestJob(ctx, src.GetSourceID())
+	if err != nil {
+		ticket.cancel()
+		logger.CtxError(ctx, "Failed to queue ingest job: source=%s, error=%v", req.Source, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgIngestFailed, err.Error())
+		return
+	}
+	opts.JobID = job.ID
+
+	logger.CtxInfo(ctx, "Ingest job queued: source=%s, job_id=%s, limit=%d, force=%v",
+		req.Source, job.ID, req.Limit, req.Force)
+
+	// Run ingest detached from the request, keeping the request's log fields
+	// for correlation.
+	go h.runIngest(logger.DetachContext(ctx), ticket, src, req, opts)
+
+	c.JSON(http.StatusAccepted, IngestResponse{
+		Message: i18n.Message(ctx, i18n.MsgIngestQueued),
+		JobID:   job.ID,
+		Status:  job.Status,
 	})
+}
+
+// runIngest waits for the job slot of ticket and runs an ingest job.
+func (h *AdminHandler) runIngest(ctx context.Context, ticket *jobTicket, src source.Source, req *IngestRequest, opts *service.IngestOptions) {
+	release, err := ticket.wait(ctx)
+	if err != nil {
+		logger.CtxError(ctx, "Ingest job did not start: source=%s, job_id=%s, error=%v", req.Source, opts.JobID, err)
+		return
+	}
+	defer release()
+
+	// Rescan once the job holds a slot of the source: with one job per source
+	// (the default) no other job is paging through the old items
+	if reloader, ok := src.(source.Reloader); ok && req.rescan {
+		reloader.Reload()
+	}
+
+	logger.CtxInfo(ctx, "Starting ingest process: source=%s, job_id=%s, limit=%d, force=%v",
+		req.Source, opts.JobID, req.Limit, req.Force)
+
+	startTime := time.Now()
+	stats, err := h.ingestService.IngestFromSource(ctx, src, req.Limit, opts)
 	duration := time.Since(startTime)
 
 	// Update state
-	h.mu.Lock()
-	h.isRunning = false
-	h.currentStats = stats
-	h.lastRunTime = time.Now()
+	runStatus := "success"
 	if err != nil {
-		h.lastRunStatus = "failed: " + err.Error()
-	} else {
-		h.lastRunStatus = "success"
+		runStatus = "failed: " + err.Error()
 	}
-	h.mu.Unlock()
+	h.recordRun(src.GetSourceID(), stats, runStatus)
 
 	if err != nil {
 		logger.With(logger.Fields{
 			logger.FieldDurationMs: duration.Milliseconds(),
 		}).Error(ctx, "Ingest process failed: source=%s, limit=%d, force=%v, error=%v",
 			req.Source, req.Limit, req.Force, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -414,11 +242,29 @@ func (h *AdminHandler) TriggerIngest(c *gin.Context) {
 		logger.FieldCount:      stats.ProcessedItems,
 	}).Info(ctx, "Ingest process completed: source=%s, total=%d, processed=%d, skipped=%d, failed=%d",
 		req.Source, stats.TotalItems, stats.ProcessedItems, stats.SkippedItems, stats.FailedItems)
+}
 
-	c.JSON(http.StatusOK, IngestResponse{
-		Message: "Ingest completed successfully",
-		Stats:   stats,
-	})
+// GetIngestJob returns an ingest job with its live counts, for polling a job
+// started by TriggerIngest.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) GetIngestJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	job, err := h.ingestService.GetIngestJob(ctx, id)
+	if errors.Is(err, service.ErrIngestJobNotFound) {
+		respondError(c, http.StatusNotFound, i18n.MsgJobNotFound, id)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to get ingest job: job_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetIngestJob)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
 }
 
 // GetIngestStatus returns the current ingest status.
@@ -426,21 +272,740 @@ func (h *AdminHandler) TriggerIngest(c *gin.Context) {
 //   - c: Gin request context.
 // Returns: none (writes JSON response).
 func (h *AdminHandler) GetIngestStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	resp := h.ingestStatus()
+	logger.CtxDebug(ctx, "Ingest status requested: client_ip=%s, is_running=%v", c.ClientIP(), resp.IsRunning)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// StreamIngestStatus handles GET /api/v1/ingest/status/stream, sending a status
+// event now and whenever the ingest status changes.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes SSE events).
+func (h *AdminHandler) StreamIngestStatus(c *gin.Context) {
+	sse := stream.New(c, stream.DefaultHeartbeat)
+	defer sse.Close()
+
+	ticker := time.NewTicker(ingestStatusPollInterval)
+	defer ticker.Stop()
+
+	var last *IngestStatusResponse
+	for {
+		status := h.ingestStatus()
+		if last == nil || !reflect.DeepEqual(status, *last) {
+			if err := sse.Event("status", status); err != nil {
+				return
+			}
+			last = &status
+		}
+		select {
+		case <-sse.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ingestStatusPollInterval is how often StreamIngestStatus checks for changes.
+const ingestStatusPollInterval = time.Second
+
+// ingestStatus returns a snapshot of the ingest state.
+func (h *AdminHandler) ingestStatus() IngestStatusResponse {
+	sources := h.jobs.snapshot()
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	ctx := c.Request.Context()
-	logger.CtxDebug(ctx, "Ingest status requested: client_ip=%s, is_running=%v", c.ClientIP(), h.isRunning)
-
 	resp := IngestStatusResponse{
-		IsRunning:     h.isRunning,
 		LastRunStatus: h.lastRunStatus,
 		CurrentStats:  h.currentStats,
 	}
-
 	if !h.lastRunTime.IsZero() {
 		resp.LastRunTime = h.lastRunTime.Format(time.RFC3339)
 	}
 
-	c.JSON(http.StatusOK, resp)
+	for sourceID, run := range h.sourceRuns {
+		status := sources[sourceID]
+		status.LastRunTime = run.LastRunTime
+		status.LastRunStatus = run.LastRunStatus
+		sources[sourceID] = status
+	}
+	for _, status := range sources {
+		if status.Running > 0 {
+			resp.IsRunning = true
+		}
+	}
+	if len(sources) > 0 {
+		resp.Sources = sources
+	}
+	return resp
+}
+
+// recordRun stores the outcome of a finished ingest run.
+func (h *AdminHandler) recordRun(sourceID string, stats *service.IngestStats, runStatus string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.currentStats = stats
+	h.lastRunTime = time.Now()
+	h.lastRunStatus = runStatus
+	h.sourceRuns[sourceID] = SourceJobStatus{
+		LastRunTime:   h.lastRunTime.Format(time.RFC3339),
+		LastRunStatus: runStatus,
+	}
+}
+
+// GetSourceStats returns ingest progress for a single source.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) GetSourceStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	src, ok := h.lookupSource(id)
+	if !ok {
+		logger.CtxWarn(ctx, "Unknown source requested for stats: source=%s, client_ip=%s", id, c.ClientIP())
+		respondError(c, http.StatusNotFound, i18n.MsgUnknownSource, id)
+		return
+	}
+
+	stats, err := h.ingestService.GetSourceStats(ctx, src)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to get source stats: source=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetSourceStats)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// DeleteSource removes all memes of a source with their vectors, Qdrant points
+// and unshared storage objects. dry_run=true only reports what would be removed.
+// Sources no longer configured can be deleted by their source ID.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) DeleteSource(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	sourceType := id
+	if src, ok := h.lookupSource(id); ok {
+		sourceType = src.GetSourceID()
+	}
+
+	// Deleting while the source ingests could leave half-written memes behind,
+	// so a deletion needs the source to itself. Dry runs only read.
+	if !dryRun {
+		release, ok := h.jobs.tryAcquireExclusive(sourceType)
+		if !ok {
+			logger.CtxWarn(ctx, "Source delete rejected: source job running, source=%s, client_ip=%s", sourceType, c.ClientIP())
+			respondError(c, http.StatusConflict, i18n.MsgSourceJobRunning, sourceType)
+			return
+		}
+		defer release()
+	}
+
+	logger.CtxInfo(ctx, "Received source delete request: source=%s, dry_run=%v, client_ip=%s", sourceType, dryRun, c.ClientIP())
+
+	// Use a detached context so a dropped connection does not stop the deletion halfway
+	report, err := h.ingestService.DeleteSource(logger.DetachContext(ctx), sourceType, dryRun)
+
+	if err != nil {
+		logger.CtxError(ctx, "Failed to delete source: source=%s, dry_run=%v, error=%v", sourceType, dryRun, err)
+		if report == nil {
+			respondError(c, http.StatusInternalServerError, i18n.MsgDeleteSource, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportTaxonomy returns every category and tag of active memes with their
+// counts, in the format ImportTaxonomy accepts.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ExportTaxonomy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	taxonomy, err := h.ingestService.ExportTaxonomy(ctx)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to export taxonomy: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgExportTaxonomy)
+		return
+	}
+
+	c.JSON(http.StatusOK, taxonomy)
+}
+
+// taxonomyJobKey is the job limiter key of taxonomy imports, so only one runs at a time.
+const taxonomyJobKey = "taxonomy_import"
+
+// ImportTaxonomy applies the renames and merges of a curated taxonomy (the
+// JSON of ExportTaxonomy with "from" lists added) to every meme. dry_run=true
+// only reports what would change.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ImportTaxonomy(c *gin.Context) {
+	ctx := c.Request.Context()
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	var taxonomy service.Taxonomy
+	if err := c.ShouldBindJSON(&taxonomy); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	// Dry runs only read.
+	if !dryRun {
+		release, ok := h.jobs.tryAcquireExclusive(taxonomyJobKey)
+		if !ok {
+			logger.CtxWarn(ctx, "Taxonomy import rejected: import already running, client_ip=%s", c.ClientIP())
+			respondError(c, http.StatusConflict, i18n.MsgTaxonomyRunning)
+			return
+		}
+		defer release()
+	}
+
+	logger.CtxInfo(ctx, "Received taxonomy import request: categories=%d, tags=%d, dry_run=%v, client_ip=%s",
+		len(taxonomy.Categories), len(taxonomy.Tags), dryRun, c.ClientIP())
+
+	// Use a detached context so a dropped connection does not stop the import halfway
+	report, err := h.ingestService.ImportTaxonomy(logger.DetachContext(ctx), &taxonomy, dryRun)
+	switch {
+	case errors.Is(err, service.ErrInvalidTaxonomy):
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidTaxonomy, err.Error())
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to import taxonomy: dry_run=%v, error=%v", dryRun, err)
+		if report == nil {
+			respondError(c, http.StatusInternalServerError, i18n.MsgImportTaxonomy, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// exportJobKey is the job limiter key of vector exports, so only one runs at a time.
+const exportJobKey = "vector_export"
+
+// ExportVectors writes the points of the vector collections to Parquet files in
+// object storage for offline analysis. collection limits the export to one
+// collection and shard_size sets the points per file.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ExportVectors(c *gin.Context) {
+	ctx := c.Request.Context()
+	collection := c.Query("collection")
+	shardSize, _ := strconv.Atoi(c.DefaultQuery("shard_size", "0"))
+
+	release, ok := h.jobs.tryAcquireExclusive(exportJobKey)
+	if !ok {
+		logger.CtxWarn(ctx, "Vector export rejected: export already running, client_ip=%s", c.ClientIP())
+		respondError(c, http.StatusConflict, i18n.MsgExportRunning)
+		return
+	}
+	defer release()
+
+	logger.CtxInfo(ctx, "Received vector export request: collection=%s, shard_size=%d, client_ip=%s", collection, shardSize, c.ClientIP())
+
+	// Use a detached context so a dropped connection does not leave a partial export
+	report, err := h.ingestService.ExportVectors(logger.DetachContext(ctx), collection, shardSize)
+
+	if err != nil {
+		logger.CtxError(ctx, "Failed to export vectors: collection=%s, error=%v", collection, err)
+		if report == nil {
+			respondError(c, http.StatusBadRequest, i18n.MsgExportVectors, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// lookupSource finds a source by its config key, falling back to its source ID.
+func (h *AdminHandler) lookupSource(id string) (source.Source, bool) {
+	if src, ok := h.sources[id]; ok {
+		return src, true
+	}
+	for _, src := range h.sources {
+		if src.GetSourceID() == id {
+			return src, true
+		}
+	}
+	return nil, false
+}
+
+// QuarantineListResponse represents a page of quarantined source items.
+type QuarantineListResponse struct {
+	Items []domain.QuarantinedItem `json:"items"`
+	service.PageInfo
+}
+
+// ListQuarantined returns source items that failed image validation.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListQuarantined(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := h.ingestService.ListQuarantined(ctx, limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list quarantined items: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListQuarantined)
+		return
+	}
+
+	c.JSON(http.StatusOK, QuarantineListResponse{
+		Items:    items,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// DescriptionReviewResponse represents a page of descriptions flagged for review.
+type DescriptionReviewResponse struct {
+	Items []domain.MemeDescription `json:"items"`
+	service.PageInfo
+}
+
+// ListDescriptionsForReview returns VLM descriptions that failed the quality check.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListDescriptionsForReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := h.ingestService.ListDescriptionsForReview(ctx, limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list descriptions for review: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListReview)
+		return
+	}
+
+	c.JSON(http.StatusOK, DescriptionReviewResponse{
+		Items:    items,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// CategorySuggestionResponse represents a page of discovered category suggestions.
+type CategorySuggestionResponse struct {
+	Items []domain.CategorySuggestion `json:"items"`
+	service.PageInfo
+}
+
+// ListCategorySuggestions returns categories proposed by the category discovery
+// job. status filters by review status (default pending; "all" lists every one).
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListCategorySuggestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	status := domain.SuggestionStatus(c.DefaultQuery("status", string(domain.SuggestionStatusPending)))
+	if status == "all" {
+		status = ""
+	}
+
+	items, total, err := h.ingestService.ListCategorySuggestions(ctx, status, limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list category suggestions: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListSuggestions)
+		return
+	}
+
+	c.JSON(http.StatusOK, CategorySuggestionResponse{
+		Items:    items,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// AcceptSuggestionRequest is the optional body of POST
+// /api/v1/admin/categories/suggestions/:id/accept.
+type AcceptSuggestionRequest struct {
+	MemeIDs []string `json:"meme_ids"` // Move only these memes of the suggestion (empty moves all)
+}
+
+// AcceptCategorySuggestion moves the memes of a pending category suggestion
+// to its label. Memes whose move fails are listed under failed and the
+// suggestion stays pending, so accepting again retries them.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) AcceptCategorySuggestion(c *gin.Context) {
+	var req AcceptSuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	h.decideSuggestion(c, func(ctx context.Context, id string) (*service.CategorySuggestionDecision, error) {
+		return h.ingestService.AcceptCategorySuggestion(ctx, id, req.MemeIDs)
+	}, "accept", i18n.MsgAcceptSuggestion)
+}
+
+// RejectCategorySuggestion turns down a pending category suggestion without
+// changing any meme.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) RejectCategorySuggestion(c *gin.Context) {
+	h.decideSuggestion(c, h.ingestService.RejectCategorySuggestion, "reject", i18n.MsgRejectSuggestion)
+}
+
+// decideSuggestion runs a review decision on the suggestion of the request
+// path and writes the outcome.
+func (h *AdminHandler) decideSuggestion(c *gin.Context, decide func(context.Context, string) (*service.CategorySuggestionDecision, error), action, failureKey string) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	decision, err := decide(ctx, id)
+	switch {
+	case errors.Is(err, service.ErrSuggestionNotFound):
+		respondError(c, http.StatusNotFound, i18n.MsgNoSuggestion)
+		return
+	case errors.Is(err, service.ErrSuggestionNotPending):
+		respondError(c, http.StatusConflict, i18n.MsgSuggestionDone, id)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to %s category suggestion: suggestion_id=%s, error=%v", action, id, err)
+		respondError(c, http.StatusInternalServerError, failureKey, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// CategoryCoverRequest is the body of PUT /api/v1/admin/categories/:name/cover.
+type CategoryCoverRequest struct {
+	MemeID string `json:"meme_id" binding:"required"`
+}
+
+// SetCategoryCover pins a meme of the category as its cover in GET
+// /api/v1/categories, replacing the previous cover.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) SetCategoryCover(c *gin.Context) {
+	ctx := c.Request.Context()
+	category := c.Param("name")
+
+	var req CategoryCoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	cover, err := h.ingestService.SetCategoryCover(ctx, category, req.MemeID)
+	switch {
+	case errors.Is(err, service.ErrMemeNotFound):
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	case errors.Is(err, service.ErrCoverCategoryMismatch):
+		respondError(c, http.StatusBadRequest, i18n.MsgCoverMismatch, req.MemeID, category)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to set category cover: category=%s, meme_id=%s, error=%v", category, req.MemeID, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgUpdateCover)
+		return
+	}
+
+	c.JSON(http.StatusOK, cover)
+}
+
+// ClearCategoryCover removes the pinned cover of a category.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes 204 or a JSON error).
+func (h *AdminHandler) ClearCategoryCover(c *gin.Context) {
+	ctx := c.Request.Context()
+	category := c.Param("name")
+
+	err := h.ingestService.ClearCategoryCover(ctx, category)
+	if errors.Is(err, service.ErrCoverNotFound) {
+		respondError(c, http.StatusNotFound, i18n.MsgCoverNotFound, category)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to clear category cover: category=%s, error=%v", category, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgUpdateCover)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// IngestJobListResponse represents a page of recorded ingest runs.
+type IngestJobListResponse struct {
+	Items []domain.IngestJob `json:"items"`
+	service.PageInfo
+}
+
+// ListIngestJobs returns recorded ingest runs, most recent first.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListIngestJobs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := h.ingestService.ListIngestJobs(ctx, limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list ingest jobs: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListIngestJobs)
+		return
+	}
+
+	c.JSON(http.StatusOK, IngestJobListResponse{
+		Items:    items,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// GetIngestReport returns the report of an ingest run as JSON, or as a CSV
+// download when format=csv.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON or CSV response).
+func (h *AdminHandler) GetIngestReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	report, err := h.ingestService.GetIngestReport(ctx, id)
+	if errors.Is(err, service.ErrIngestJobNotFound) {
+		respondError(c, http.StatusNotFound, i18n.MsgReportNotFound, id)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to get ingest report: job_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetReport)
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ingest-%s.json"`, id))
+		c.JSON(http.StatusOK, report)
+	case "csv":
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ingest-%s.csv"`, id))
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := report.WriteCSV(c.Writer); err != nil {
+			logger.CtxError(ctx, "Failed to write ingest report CSV: job_id=%s, error=%v", id, err)
+		}
+	default:
+		respondError(c, http.StatusBadRequest, i18n.MsgReportFormat)
+	}
+}
+
+// OriginReviewResponse represents a page of memes whose origin is dead or blocked.
+type OriginReviewResponse struct {
+	Items []domain.Meme `json:"items"`
+	service.PageInfo
+}
+
+// ListDeadOrigins returns memes whose source URL was found dead or blocked.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListDeadOrigins(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, total, err := h.ingestService.ListDeadOrigins(ctx, limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list dead origins: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListDeadOrigins)
+		return
+	}
+
+	c.JSON(http.StatusOK, OriginReviewResponse{
+		Items:    items,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// DeleteMeme removes a meme with its Qdrant points in every ingest collection,
+// its unshared storage objects and its database rows. The run is recorded as
+// an ingest job of kind meme_delete.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) DeleteMeme(c *gin.Context) {
+	ctx := c.Request.Context()
+	memeID := c.Param("id")
+	logger.CtxInfo(ctx, "Received meme delete request: meme_id=%s, client_ip=%s", memeID, c.ClientIP())
+
+	// Use a detached context so a dropped connection does not stop the deletion halfway
+	report, err := h.ingestService.DeleteMeme(logger.DetachContext(ctx), memeID)
+	switch {
+	case errors.Is(err, service.ErrMemeNotFound):
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to delete meme: meme_id=%s, error=%v", memeID, err)
+		if report == nil {
+			respondError(c, http.StatusInternalServerError, i18n.MsgDeleteMeme, err.Error())
+			return
+		}
+		c.JSON(http.StatusInternalServerError, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ModerationQueueResponse represents a page of memes awaiting review.
+type ModerationQueueResponse struct {
+	Memes []domain.Meme `json:"memes"`
+	service.PageInfo
+}
+
+// ListModerationQueue returns memes awaiting review, oldest first. The
+// optional source query parameter narrows the queue to one source type, such
+// as upload.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ListModerationQueue(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	memes, total, err := h.ingestService.ListModerationQueue(ctx, c.Query("source"), limit, offset)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list moderation queue: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListModeration)
+		return
+	}
+
+	c.JSON(http.StatusOK, ModerationQueueResponse{
+		Memes:    memes,
+		PageInfo: service.NewPageInfo(total, limit, offset),
+	})
+}
+
+// ApproveMeme publishes a meme awaiting review, so searches and lists return it.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) ApproveMeme(c *gin.Context) {
+	h.moderate(c, h.ingestService.ApproveMeme, "approve", i18n.MsgApproveMeme)
+}
+
+// RejectMeme removes a meme awaiting review from the index and marks it
+// rejected, so the image is not ingested again.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) RejectMeme(c *gin.Context) {
+	h.moderate(c, h.ingestService.RejectMeme, "reject", i18n.MsgRejectMeme)
+}
+
+// moderate runs a moderation decision on the meme of the request path and
+// writes the updated meme.
+func (h *AdminHandler) moderate(c *gin.Context, decide func(context.Context, string) (*domain.Meme, error), action, failureKey string) {
+	ctx := c.Request.Context()
+	memeID := c.Param("id")
+
+	meme, err := decide(ctx, memeID)
+	switch {
+	case errors.Is(err, service.ErrMemeNotFound):
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	case errors.Is(err, service.ErrMemeNotInReview):
+		respondError(c, http.StatusConflict, i18n.MsgMemeNotInReview, memeID)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to %s meme: meme_id=%s, error=%v", action, memeID, err)
+		respondError(c, http.StatusInternalServerError, failureKey, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, meme)
+}
+
+// RedescribeMeme re-runs the VLM on a stored meme and rewrites its description
+// and vectors, for use after upgrading the VLM model or prompt.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *AdminHandler) RedescribeMeme(c *gin.Context) {
+	ctx := c.Request.Context()
+	memeID := c.Param("id")
+
+	result, err := h.ingestService.RedescribeMeme(ctx, memeID)
+	switch {
+	case errors.Is(err, service.ErrMemeNotFound), errors.Is(err, service.ErrMemeNotStored):
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to re-describe meme: meme_id=%s, error=%v", memeID, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgRedescribe)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+//go:embed templates/admin.html
+var adminPageHTML string
+
+var adminPageTemplate = template.Must(template.New("admin").Parse(adminPageHTML))
+
+// adminPageData is the data of the admin page template.
+type adminPageData struct {
+	Lang     string
+	Messages map[string]string // admin.* messages used by the page script
+}
+
+// T returns the admin UI message of key in the page language.
+func (d adminPageData) T(key string) string {
+	return i18n.T(d.Lang, key)
+}
+
+// AdminPage serves the admin dashboard HTML page in the request language.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes HTML response).
+func (h *AdminHandler) AdminPage(c *gin.Context) {
+	lang := i18n.FromContext(c.Request.Context())
+
+	var buf bytes.Buffer
+	err := adminPageTemplate.Execute(&buf, adminPageData{
+		Lang:     lang,
+		Messages: i18n.Catalog(lang, "admin."),
+	})
+	if err != nil {
+		logger.CtxError(c.Request.Context(), "Failed to render admin page: error=%v", err)
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Vary", "Accept-Language")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
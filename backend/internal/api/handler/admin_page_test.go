@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+)
+
+func TestAdminPageFollowsRequestLanguage(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	h := &AdminHandler{}
+	for lang, want := range map[string]string{
+		i18n.ZhCN: "开始导入",
+		i18n.En:   "Start ingest",
+	} {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request = req.WithContext(i18n.NewContext(req.Context(), lang))
+
+		h.AdminPage(c)
+
+		body := rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, want) || !strings.Contains(body, `lang="`+lang+`"`) {
+			t.Fatalf("AdminPage(%s) = %d, want a %s page containing %q", lang, rec.Code, lang, want)
+		}
+		if !strings.Contains(body, `"admin.running":`) {
+			t.Fatalf("AdminPage(%s) does not embed the script messages", lang)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/buildinfo"
+)
+
+// DebugHandler serves build and runtime diagnostics.
+type DebugHandler struct {
+	features map[string]bool
+}
+
+// MemoryStats is the part of runtime.MemStats reported by the debug endpoint.
+type MemoryStats struct {
+	AllocBytes      uint64     `json:"alloc_bytes"`       // Live heap objects
+	TotalAllocBytes uint64     `json:"total_alloc_bytes"` // Allocated since start
+	SysBytes        uint64     `json:"sys_bytes"`         // Obtained from the OS
+	HeapInuseBytes  uint64     `json:"heap_inuse_bytes"`
+	HeapObjects     uint64     `json:"heap_objects"`
+	NumGC           uint32     `json:"num_gc"`
+	PauseTotalMs    int64      `json:"pause_total_ms"`
+	LastGC          *time.Time `json:"last_gc,omitempty"`
+}
+
+// DebugInfo is the response of GET /api/v1/admin/debug/info.
+type DebugInfo struct {
+	buildinfo.Info
+	UptimeSeconds int64           `json:"uptime_seconds"`
+	Goroutines    int             `json:"goroutines"`
+	CPUs          int             `json:"cpus"`
+	Memory        MemoryStats     `json:"memory"`
+	Features      map[string]bool `json:"features"`
+}
+
+// NewDebugHandler creates a new debug handler.
+// Parameters:
+//   - features: optional features by name and whether they are enabled.
+//
+// Returns:
+//   - *DebugHandler: initialized handler.
+func NewDebugHandler(features map[string]bool) *DebugHandler {
+	return &DebugHandler{features: features}
+}
+
+// Info handles GET /api/v1/admin/debug/info.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *DebugHandler) Info(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	memory := MemoryStats{
+		AllocBytes:      mem.Alloc,
+		TotalAllocBytes: mem.TotalAlloc,
+		SysBytes:        mem.Sys,
+		HeapInuseBytes:  mem.HeapInuse,
+		HeapObjects:     mem.HeapObjects,
+		NumGC:           mem.NumGC,
+		PauseTotalMs:    time.Duration(mem.PauseTotalNs).Milliseconds(),
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		memory.LastGC = &lastGC
+	}
+
+	c.JSON(http.StatusOK, DebugInfo{
+		Info:          buildinfo.Get(),
+		UptimeSeconds: int64(buildinfo.Uptime().Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		CPUs:          runtime.NumCPU(),
+		Memory:        memory,
+		Features:      h.features,
+	})
+}
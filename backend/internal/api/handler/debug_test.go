@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDebugInfoReportsBuildAndRuntime(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	h := NewDebugHandler(map[string]bool{"rerank": true, "pprof": false})
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/debug/info", nil)
+	h.Info(c)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Info() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var info DebugInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Version == "" || info.GoVersion != runtime.Version() {
+		t.Fatalf("build info = %+v, want a version and go %s", info.Info, runtime.Version())
+	}
+	if info.Goroutines < 1 || info.Memory.SysBytes == 0 || info.StartedAt.IsZero() {
+		t.Fatalf("runtime info = %+v, want goroutines, memory and start time", info)
+	}
+	if !info.Features["rerank"] || info.Features["pprof"] {
+		t.Fatalf("features = %v, want rerank on and pprof off", info.Features)
+	}
+}
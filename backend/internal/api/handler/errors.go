@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+)
+
+// respondError writes an error response with the message of key translated to
+// the request language, and key itself as a stable code.
+// Parameters:
+//   - c: Gin request context.
+//   - status: HTTP status code.
+//   - key: i18n message key.
+//   - args: fmt arguments of the message.
+//
+// Returns: none (writes JSON response).
+func respondError(c *gin.Context, status int, key string, args ...any) {
+	c.JSON(status, gin.H{
+		"error": i18n.Message(c.Request.Context(), key, args...),
+		"code":  key,
+	})
+}
@@ -2,22 +2,62 @@ package handler
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/service"
 )
 
 // HealthHandler handles health check endpoints.
-type HealthHandler struct{}
+type HealthHandler struct {
+	readiness *Readiness
+	checker   *service.HealthChecker
+}
 
-// NewHealthHandler creates a new health handler.
+// Readiness records whether the service has finished warming up. It is safe
+// for concurrent use.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+// NewReadiness creates a readiness flag.
+// Parameters:
+//   - ready: initial state; false until MarkReady when warming up.
+// Returns:
+//   - *Readiness: readiness flag.
+func NewReadiness(ready bool) *Readiness {
+	r := &Readiness{}
+	r.ready.Store(ready)
+	return r
+}
+
+// MarkReady flags the service as ready to take traffic.
+// Parameters: none.
+// Returns: none.
+func (r *Readiness) MarkReady() {
+	r.ready.Store(true)
+}
+
+// IsReady reports whether the service is ready to take traffic.
 // Parameters: none.
 // Returns:
+//   - bool: true once ready.
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+// NewHealthHandler creates a new health handler.
+// Parameters:
+//   - readiness: readiness flag served by /health/ready (nil is always ready).
+//   - checker: dependency checks run by /health/ready (nil checks none).
+// Returns:
 //   - *HealthHandler: initialized handler.
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(readiness *Readiness, checker *service.HealthChecker) *HealthHandler {
+	return &HealthHandler{readiness: readiness, checker: checker}
 }
 
-// Health returns the health status of the service.
+// Health reports that the process is up, for liveness probes. It checks no
+// dependencies, so an outage of one does not get healthy replicas restarted.
 // Parameters:
 //   - c: Gin request context.
 // Returns: none (writes JSON response).
@@ -26,3 +66,32 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		"status": "ok",
 	})
 }
+
+// Ready reports whether the service can take traffic: it answers 503 while
+// warming up or when a critical dependency check fails, so load balancers
+// hold traffic back. Failing non-critical checks answer 200 with status
+// degraded. The body lists each dependency with its status and latency.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.readiness != nil && !h.readiness.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": service.HealthStatusWarming,
+		})
+		return
+	}
+	if h.checker == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status": service.HealthStatusReady,
+		})
+		return
+	}
+
+	report := h.checker.Check(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == service.HealthStatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/service"
+)
+
+func TestReadyWaitsForWarmUp(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	readiness := NewReadiness(false)
+	h := NewHealthHandler(readiness, nil)
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		h.Ready(c)
+		return rec.Code
+	}
+
+	if got := ready(); got != http.StatusServiceUnavailable {
+		t.Fatalf("Ready() while warming = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	readiness.MarkReady()
+	if got := ready(); got != http.StatusOK {
+		t.Fatalf("Ready() after MarkReady = %d, want %d", got, http.StatusOK)
+	}
+}
+
+func TestReadyReportsDependencies(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		check  service.HealthCheck
+		want   int
+		status string
+	}{
+		{
+			name:   "critical failure",
+			check:  service.HealthCheck{Name: "database", Critical: true, Check: failingCheck},
+			want:   http.StatusServiceUnavailable,
+			status: service.HealthStatusUnavailable,
+		},
+		{
+			name:   "optional failure",
+			check:  service.HealthCheck{Name: "embedding", Check: failingCheck},
+			want:   http.StatusOK,
+			status: service.HealthStatusDegraded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := NewHealthHandler(NewReadiness(true), service.NewHealthChecker(service.HealthCheckerConfig{}, tt.check))
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			h.Ready(c)
+
+			var report service.HealthReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.want || report.Status != tt.status {
+				t.Fatalf("Ready() = %d %q, want %d %q", rec.Code, report.Status, tt.want, tt.status)
+			}
+			if len(report.Dependencies) != 1 || report.Dependencies[0].Error == "" {
+				t.Fatalf("Ready() dependencies = %+v, want the failed check", report.Dependencies)
+			}
+		})
+	}
+}
+
+func failingCheck(context.Context) (string, error) {
+	return "", errors.New("connection refused")
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// imageFormOverhead is the room left in upload bodies for the other fields.
+const imageFormOverhead = 64 << 10
+
+// errImageRequired is returned when a request carries no image.
+var errImageRequired = errors.New("image is required")
+
+// ImageSearch handles POST /api/v1/search/image. The image is either the
+// multipart file field "image" or base64 in the "image" field of a JSON body;
+// the other search options are form fields or JSON fields respectively.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *SearchHandler) ImageSearch(c *gin.Context) {
+	ctx := c.Request.Context()
+	maxBytes := h.searchService.ImageSearchMaxBytes()
+	// Base64 grows the image by a third.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes/3*4+imageFormOverhead)
+
+	var req service.ImageSearchRequest
+	imageData, err := readSearchImage(c, &req, maxBytes)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge), errors.Is(err, service.ErrImageTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.MsgImageTooLarge, maxBytes)
+		return
+	case errors.Is(err, errImageRequired):
+		respondError(c, http.StatusBadRequest, i18n.MsgImageRequired)
+		return
+	case err != nil:
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	if collection := c.Query("collection"); collection != "" && req.Collection == "" {
+		req.Collection = collection
+	}
+	if profile := c.Query("profile"); profile != "" && req.Profile == "" {
+		req.Profile = profile
+	}
+
+	result, err := h.searchService.ImageSearch(ctx, imageData, &req)
+	if h.respondUnknownTarget(c, req.Collection, req.Profile, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrImageSearchDisabled):
+		respondError(c, http.StatusServiceUnavailable, i18n.MsgImageSearchOff)
+		return
+	case errors.Is(err, service.ErrImageTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.MsgImageTooLarge, maxBytes)
+		return
+	case errors.Is(err, service.ErrInvalidImage):
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidImage, err.Error())
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Image search failed: size=%d, error=%v", len(imageData), err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgSearchFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// readSearchImage binds the search options of c into req and returns the
+// uploaded image, reading at most one byte past maxBytes.
+func readSearchImage(c *gin.Context, req *service.ImageSearchRequest, maxBytes int64) ([]byte, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if err := c.ShouldBind(req); err != nil {
+			return nil, err
+		}
+		header, err := c.FormFile("image")
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, errImageRequired
+		}
+		if err != nil {
+			return nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open upload: %w", err)
+		}
+		defer file.Close()
+		return io.ReadAll(io.LimitReader(file, maxBytes+1))
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, err
+	}
+	if req.Image == "" {
+		return nil, errImageRequired
+	}
+	return decodeBase64Image(req.Image)
+}
+
+// decodeBase64Image decodes a base64 image, with or without a data URL prefix
+// such as "data:image/png;base64,".
+func decodeBase64Image(encoded string) ([]byte, error) {
+	if strings.HasPrefix(encoded, "data:") {
+		_, payload, ok := strings.Cut(encoded, ",")
+		if !ok {
+			return nil, errors.New("malformed data URL")
+		}
+		encoded = payload
+	}
+	encoded = strings.TrimSpace(encoded)
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		// Tolerate unpadded input.
+		if data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "=")); err != nil {
+			return nil, fmt.Errorf("image is not valid base64: %w", err)
+		}
+	}
+	return data, nil
+}
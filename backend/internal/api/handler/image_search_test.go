@@ -0,0 +1,25 @@
+package handler
+
+import "testing"
+
+func TestDecodeBase64Image(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"aGVsbG8=", "hello", false},
+		{"aGVsbG8", "hello", false},
+		{"data:image/png;base64,aGVsbG8=", "hello", false},
+		{"data:image/png;base64", "", true},
+		{"not base64!", "", true},
+	}
+	for _, tt := range tests {
+		got, err := decodeBase64Image(tt.input)
+		if (err != nil) != tt.wantErr || string(got) != tt.want {
+			t.Errorf("decodeBase64Image(%q) = %q, %v, want %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	defaultJobsPerSource = 1
+	defaultJobQueueSize  = 10
+)
+
+// errJobQueueFull is returned when a source already has the maximum number of
+// jobs waiting for a slot.
+var errJobQueueFull = errors.New("too many jobs queued for source")
+
+// JobLimits bounds the admin jobs (ingest runs and source deletions) running at once.
+type JobLimits struct {
+	MaxConcurrent int            // Jobs running at once across all sources (0 = unlimited)
+	PerSource     int            // Jobs running at once per source (0 uses 1)
+	SourceLimits  map[string]int // Per-source overrides of PerSource, keyed by source ID
+	QueueSize     int            // Jobs waiting per source before new ones are rejected (0 uses 10)
+}
+
+// SourceJobStatus reports the jobs of one source.
+type SourceJobStatus struct {
+	Running       int    `json:"running"`
+	Queued        int    `json:"queued"`
+	LastRunTime   string `json:"last_run_time,omitempty"`
+	LastRunStatus string `json:"last_run_status,omitempty"`
+}
+
+// jobLimiter hands out job slots per source. Waiting jobs are woken whenever a
+// slot is released and re-check the limits.
+type jobLimiter struct {
+	mu        sync.Mutex
+	limits    JobLimits
+	running   map[string]int
+	exclusive map[string]bool
+	queued    map[string]int
+	total     int
+	released  chan struct{} // Closed and replaced on every release
+}
+
+func newJobLimiter(limits JobLimits) *jobLimiter {
+	if limits.PerSource <= 0 {
+		limits.PerSource = defaultJobsPerSource
+	}
+	if limits.QueueSize <= 0 {
+		limits.QueueSize = defaultJobQueueSize
+	}
+	return &jobLimiter{
+		limits:    limits,
+		running:   make(map[string]int),
+		exclusive: make(map[string]bool),
+		queued:    make(map[string]int),
+		released:  make(chan struct{}),
+	}
+}
+
+// acquire waits for a job slot of sourceID, queueing behind other jobs of the
+// source. The returned release must be called when the job ends.
+func (l *jobLimiter) acquire(ctx context.Context, sourceID string) (func(), error) {
+	ticket, err := l.enqueue(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	return ticket.wait(ctx)
+}
+
+// jobTicket is a place in the queue of a source, taken by enqueue.
+type jobTicket struct {
+	l        *jobLimiter
+	sourceID string
+	release  func() // Set when a slot was free at enqueue time
+}
+
+// enqueue takes a slot of sourceID if one is free and otherwise a place in
+// the source's queue, so callers can reject a job before running it in the
+// background. The ticket must be waited on or canceled.
+func (l *jobLimiter) enqueue(sourceID string) (*jobTicket, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.canRun(sourceID, false) {
+		return &jobTicket{l: l, sourceID: sourceID, release: l.take(sourceID, false)}, nil
+	}
+	if l.queued[sourceID] >= l.limits.QueueSize {
+		return nil, errJobQueueFull
+	}
+	l.queued[sourceID]++
+	return &jobTicket{l: l, sourceID: sourceID}, nil
+}
+
+// wait blocks until the job may start and returns its release func.
+func (t *jobTicket) wait(ctx context.Context) (func(), error) {
+	if t.release != nil {
+		return t.release, nil
+	}
+	l := t.l
+	defer t.leaveQueue()
+
+	// A slot may have been freed since enqueue, so check before waiting.
+	l.mu.Lock()
+	for {
+		if l.canRun(t.sourceID, false) {
+			release := l.take(t.sourceID, false)
+			l.mu.Unlock()
+			return release, nil
+		}
+		released := l.released
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+		l.mu.Lock()
+	}
+}
+
+// cancel gives up the slot or queue place of a job that will not run.
+func (t *jobTicket) cancel() {
+	if t.release != nil {
+		t.release()
+		return
+	}
+	t.leaveQueue()
+}
+
+func (t *jobTicket) leaveQueue() {
+	l := t.l
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queued[t.sourceID]--
+	if l.queued[t.sourceID] == 0 {
+		delete(l.queued, t.sourceID)
+	}
+}
+
+// tryAcquireExclusive takes every slot of sourceID if no job of the source is
+// running, for jobs that must not overlap with others, like deleting a source.
+func (l *jobLimiter) tryAcquireExclusive(sourceID string) (func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.canRun(sourceID, true) {
+		return nil, false
+	}
+	return l.take(sourceID, true), true
+}
+
+// canRun reports whether a job of sourceID may start. Callers hold l.mu.
+func (l *jobLimiter) canRun(sourceID string, exclusive bool) bool {
+	if l.exclusive[sourceID] {
+		return false
+	}
+	if l.limits.MaxConcurrent > 0 && l.total >= l.limits.MaxConcurrent {
+		return false
+	}
+	if exclusive {
+		return l.running[sourceID] == 0
+	}
+	return l.running[sourceID] < l.sourceLimit(sourceID)
+}
+
+// take records a started job and returns its release func. Callers hold l.mu.
+func (l *jobLimiter) take(sourceID string, exclusive bool) func() {
+	l.running[sourceID]++
+	l.total++
+	if exclusive {
+		l.exclusive[sourceID] = true
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.running[sourceID]--
+			if l.running[sourceID] == 0 {
+				delete(l.running, sourceID)
+			}
+			l.total--
+			if exclusive {
+				delete(l.exclusive, sourceID)
+			}
+			close(l.released)
+			l.released = make(chan struct{})
+		})
+	}
+}
+
+func (l *jobLimiter) sourceLimit(sourceID string) int {
+	if limit, ok := l.limits.SourceLimits[sourceID]; ok && limit > 0 {
+		return limit
+	}
+	return l.limits.PerSource
+}
+
+// snapshot returns the running and queued jobs per source.
+func (l *jobLimiter) snapshot() map[string]SourceJobStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	statuses := make(map[string]SourceJobStatus)
+	for sourceID, n := range l.running {
+		status := statuses[sourceID]
+		status.Running = n
+		statuses[sourceID] = status
+	}
+	for sourceID, n := range l.queued {
+		status := statuses[sourceID]
+		status.Queued = n
+		statuses[sourceID] = status
+	}
+	return statuses
+}
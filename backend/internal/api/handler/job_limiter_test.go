@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobLimiterQueuesPerSource(t *testing.T) {
+	t.Parallel()
+
+	limiter := newJobLimiter(JobLimits{QueueSize: 1})
+	ctx := context.Background()
+
+	releaseA, err := limiter.acquire(ctx, "a")
+	if err != nil {
+		t.Fatalf("acquire(a) error = %v", err)
+	}
+	releaseB, err := limiter.acquire(ctx, "b")
+	if err != nil {
+		t.Fatalf("acquire(b) error = %v, want other sources to run concurrently", err)
+	}
+	defer releaseB()
+
+	acquired := make(chan func())
+	go func() {
+		release, err := limiter.acquire(ctx, "a")
+		if err != nil {
+			t.Errorf("queued acquire(a) error = %v", err)
+		}
+		acquired <- release
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.snapshot()["a"].Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("second job of source a was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := limiter.acquire(ctx, "a"); !errors.Is(err, errJobQueueFull) {
+		t.Fatalf("acquire(a) with full queue error = %v, want errJobQueueFull", err)
+	}
+	if _, ok := limiter.tryAcquireExclusive("a"); ok {
+		t.Fatal("tryAcquireExclusive(a) succeeded while a job of a runs")
+	}
+
+	releaseA()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("queued job did not start after release")
+	}
+
+	release, ok := limiter.tryAcquireExclusive("a")
+	if !ok {
+		t.Fatal("tryAcquireExclusive(a) failed with no job of a running")
+	}
+	defer release()
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(waitCtx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire(a) during exclusive job error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestJobLimiterEnqueueRejectsFullQueueUpFront(t *testing.T) {
+	t.Parallel()
+
+	limiter := newJobLimiter(JobLimits{QueueSize: 1})
+	ctx := context.Background()
+
+	running, err := limiter.enqueue("a")
+	if err != nil {
+		t.Fatalf("enqueue(a) error = %v", err)
+	}
+	queued, err := limiter.enqueue("a")
+	if err != nil {
+		t.Fatalf("second enqueue(a) error = %v, want a queue place", err)
+	}
+	if _, err := limiter.enqueue("a"); !errors.Is(err, errJobQueueFull) {
+		t.Fatalf("enqueue(a) with full queue error = %v, want errJobQueueFull", err)
+	}
+	if got := limiter.snapshot()["a"]; got.Running != 1 || got.Queued != 1 {
+		t.Fatalf("snapshot(a) = %+v, want 1 running and 1 queued", got)
+	}
+
+	// A canceled queue place frees room for another job.
+	queued.cancel()
+	queued, err = limiter.enqueue("a")
+	if err != nil {
+		t.Fatalf("enqueue(a) after cancel error = %v", err)
+	}
+
+	release, err := running.wait(ctx)
+	if err != nil {
+		t.Fatalf("wait() of a free slot error = %v", err)
+	}
+	release()
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	release, err = queued.wait(waitCtx)
+	if err != nil {
+		t.Fatalf("wait() of a queued job error = %v, want it to start after release", err)
+	}
+	release()
+	if got := limiter.snapshot(); len(got) != 0 {
+		t.Fatalf("snapshot() = %+v, want no jobs", got)
+	}
+}
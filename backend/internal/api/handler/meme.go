@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
 	"github.com/timmy/emomo/internal/service"
 )
 
@@ -24,21 +30,143 @@ func NewMemeHandler(searchService *service.SearchService) *MemeHandler {
 	}
 }
 
-// ListMemes handles GET /api/v1/memes.
+// maxTagFilters bounds the tags combined in one meme list query.
+const maxTagFilters = 5
+
+// ListMemes handles GET /api/v1/memes. Repeated tag parameters list memes
+// carrying every given tag; sort picks newest (default), oldest, popular,
+// file_size or random.
 // Parameters:
 //   - c: Gin request context.
 // Returns: none (writes JSON response).
 func (h *MemeHandler) ListMemes(c *gin.Context) {
 	category := c.Query("category")
+	tags := c.QueryArray("tag")
+	if len(tags) > maxTagFilters {
+		respondError(c, http.StatusBadRequest, i18n.MsgTooManyTags, maxTagFilters)
+		return
+	}
+	sort, err := domain.ParseMemeSort(c.Query("sort"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidSort, c.Query("sort"))
+		return
+	}
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	result, err := h.searchService.ListMemes(c.Request.Context(), category, limit, offset)
+	filter := repository.MemeListFilter{Category: category, Tags: tags, Sort: sort}
+	result, err := h.searchService.ListMemes(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, i18n.MsgListMemes, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RandomMemes handles GET /api/v1/memes/random, drawing count (default 1,
+// at most service.MaxRandomMemes) active memes at random, optionally of a
+// category and an emotion word.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *MemeHandler) RandomMemes(c *gin.Context) {
+	ctx := c.Request.Context()
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "1"))
+	filter := repository.MemeListFilter{Category: c.Query("category"), Emotion: c.Query("emotion")}
+
+	result, err := h.searchService.RandomMemes(ctx, filter, count)
+	if errors.Is(err, service.ErrUnknownEmotion) {
+		respondError(c, http.StatusBadRequest, i18n.MsgUnknownEmotion, filter.Emotion)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list memes: " + err.Error(),
-		})
+		logger.CtxError(ctx, "Failed to draw random memes: category=%s, emotion=%s, error=%v", filter.Category, filter.Emotion, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgPickMeme)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DailyMeme handles GET /api/v1/memes/daily, returning the meme of today or
+// of an earlier date (YYYY-MM-DD, UTC), optionally of a category and an
+// emotion word. The same day and filters always return the same meme.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *MemeHandler) DailyMeme(c *gin.Context) {
+	ctx := c.Request.Context()
+	day, ok := parseDay(c.Query("date"), time.Now())
+	if !ok {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidDay, c.Query("date"))
+		return
+	}
+	filter := repository.MemeListFilter{Category: c.Query("category"), Emotion: c.Query("emotion")}
+
+	result, err := h.searchService.DailyMeme(ctx, filter, day)
+	if errors.Is(err, service.ErrUnknownEmotion) {
+		respondError(c, http.StatusBadRequest, i18n.MsgUnknownEmotion, filter.Emotion)
+		return
+	}
+	if errors.Is(err, service.ErrMemeNotFound) {
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to pick daily meme: category=%s, emotion=%s, error=%v", filter.Category, filter.Emotion, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgPickMeme)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseDay parses a YYYY-MM-DD date no later than the UTC day of now; empty
+// is today.
+func parseDay(value string, now time.Time) (time.Time, bool) {
+	today := now.UTC()
+	if value == "" {
+		return today, true
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil || day.After(today) {
+		return time.Time{}, false
+	}
+	return day, true
+}
+
+// MemeBatchRequest is the body of POST /api/v1/memes/batch-get.
+type MemeBatchRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchGetMemes handles POST /api/v1/memes/batch-get, returning the memes of
+// up to service.MaxBatchMemes IDs in request order.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes JSON response).
+func (h *MemeHandler) BatchGetMemes(c *gin.Context) {
+	var req MemeBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	if len(ids) == 0 {
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDsRequired)
+		return
+	}
+	if len(ids) > service.MaxBatchMemes {
+		respondError(c, http.StatusBadRequest, i18n.MsgTooManyMemeIDs, service.MaxBatchMemes)
+		return
+	}
+
+	result, err := h.searchService.GetMemesByIDs(c.Request.Context(), ids)
+	if err != nil {
+		logger.CtxError(c.Request.Context(), "Failed to batch get memes: count=%d, error=%v", len(ids), err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetMemes)
 		return
 	}
 
@@ -52,19 +180,41 @@ func (h *MemeHandler) ListMemes(c *gin.Context) {
 func (h *MemeHandler) GetMeme(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Meme ID is required",
-		})
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDRequired)
 		return
 	}
 
 	meme, err := h.searchService.GetMemeByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Meme not found",
-		})
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
 		return
 	}
 
 	c.JSON(http.StatusOK, meme)
 }
+
+// RecordClick handles POST /api/v1/memes/:id/click, counting a meme opened
+// from search results for the popularity prior of search ranking.
+// Parameters:
+//   - c: Gin request context.
+// Returns: none (writes 204 or a JSON error).
+func (h *MemeHandler) RecordClick(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDRequired)
+		return
+	}
+
+	err := h.searchService.RecordClick(ctx, id)
+	if errors.Is(err, service.ErrMemeNotFound) {
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to record meme click: meme_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgRecordClick)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
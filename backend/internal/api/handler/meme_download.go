@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// bundleFileName is the download name of ZIP bundles.
+const bundleFileName = "emomo-memes.zip"
+
+// BundleRequest is the body of POST /api/v1/memes/download.
+type BundleRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// DownloadMeme handles GET /api/v1/memes/:id/download, streaming the stored
+// file as an attachment instead of redirecting to the bucket URL.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes the file or a JSON error).
+func (h *MemeHandler) DownloadMeme(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDRequired)
+		return
+	}
+
+	file, err := h.searchService.GetMemeFile(ctx, id)
+	if errors.Is(err, service.ErrMemeNotFound) || errors.Is(err, service.ErrMemeNotStored) {
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to look up meme for download: meme_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgDownloadMeme)
+		return
+	}
+
+	h.serveFile(c, file, "attachment")
+	if c.Writer.Status() == http.StatusOK {
+		h.searchService.RecordDownloads(ctx, file.Meme.ID)
+	}
+}
+
+// GetStill handles GET /api/v1/memes/:id/still, returning a static image of
+// the meme: the poster frame of animated memes (generated on first request)
+// or the image itself.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes the image or a JSON error).
+func (h *MemeHandler) GetStill(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	if id == "" {
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDRequired)
+		return
+	}
+
+	file, err := h.searchService.GetStillFile(ctx, id)
+	switch {
+	case errors.Is(err, service.ErrMemeNotFound), errors.Is(err, service.ErrMemeNotStored):
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	case errors.Is(err, service.ErrStillUnavailable):
+		respondError(c, http.StatusNotFound, i18n.MsgStillUnavailable)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to prepare meme still: meme_id=%s, error=%v", id, err)
+		respondError(c, http.StatusBadGateway, i18n.MsgDownloadMeme)
+		return
+	}
+
+	h.serveFile(c, file, "inline")
+}
+
+// serveFile streams a meme file with a content-hash ETag. A request whose
+// If-None-Match lists that ETag gets 304 without reading storage.
+func (h *MemeHandler) serveFile(c *gin.Context, file *service.MemeFile, disposition string) {
+	ctx := c.Request.Context()
+	if file.ETag != "" {
+		c.Header("ETag", file.ETag)
+		if etagMatches(c.GetHeader("If-None-Match"), file.ETag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	body, err := h.searchService.OpenFile(ctx, file)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to open meme file: meme_id=%s, key=%s, error=%v", file.Meme.ID, file.Key, err)
+		respondError(c, http.StatusBadGateway, i18n.MsgDownloadMeme)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, -1, file.ContentType, body, map[string]string{
+		"Content-Disposition": contentDisposition(disposition, file.Name),
+	})
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadBundle handles POST /api/v1/memes/download, streaming the requested
+// memes as a ZIP archive.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes the archive or a JSON error).
+func (h *MemeHandler) DownloadBundle(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	if len(ids) == 0 {
+		respondError(c, http.StatusBadRequest, i18n.MsgMemeIDsRequired)
+		return
+	}
+	if len(ids) > service.MaxBundleMemes {
+		respondError(c, http.StatusBadRequest, i18n.MsgTooManyMemes, service.MaxBundleMemes)
+		return
+	}
+
+	memes, err := h.searchService.ResolveBundle(ctx, ids)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to resolve meme bundle: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgDownloadMeme)
+		return
+	}
+	if len(memes) == 0 {
+		respondError(c, http.StatusNotFound, i18n.MsgMemeNotFound)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", contentDisposition("attachment", bundleFileName))
+	c.Status(http.StatusOK)
+
+	written, err := h.searchService.WriteMemeBundle(ctx, memes, c.Writer)
+	if err != nil {
+		// Headers are sent; the client sees a truncated archive.
+		logger.CtxError(ctx, "Failed to stream meme bundle: written=%d, requested=%d, error=%v", written, len(ids), err)
+		return
+	}
+	logger.CtxInfo(ctx, "Streamed meme bundle: written=%d, requested=%d", written, len(ids))
+
+	downloaded := make([]string, len(memes))
+	for i, meme := range memes {
+		downloaded[i] = meme.ID
+	}
+	h.searchService.RecordDownloads(ctx, downloaded...)
+}
+
+// contentDisposition returns a Content-Disposition header of the given type
+// for name, encoding non-ASCII names as RFC 2231 filename*.
+func contentDisposition(disposition, name string) string {
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": name}); header != "" {
+		return header
+	}
+	return disposition
+}
+
+// uniqueIDs trims ids and drops empty and repeated ones, keeping order.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
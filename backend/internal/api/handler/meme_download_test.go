@@ -0,0 +1,25 @@
+package handler
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	t.Parallel()
+
+	etag := `"0123abcd"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"0123abcd"`, true},
+		{`W/"0123abcd"`, true},
+		{`"other", "0123abcd"`, true},
+		{`"other"`, false},
+		{"*", true},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// defaultUploadMaxBytes bounds uploads when no limit is configured.
+const defaultUploadMaxBytes = 10 << 20
+
+// UploadHandler handles memes contributed by users.
+type UploadHandler struct {
+	ingestService *service.IngestService
+	maxBytes      int64
+}
+
+// NewUploadHandler creates a new upload handler.
+// Parameters:
+//   - ingestService: ingest service that runs the pipeline for uploads.
+//   - maxBytes: largest accepted image (0 uses 10 MiB).
+//
+// Returns:
+//   - *UploadHandler: initialized handler.
+func NewUploadHandler(ingestService *service.IngestService, maxBytes int64) *UploadHandler {
+	if maxBytes <= 0 {
+		maxBytes = defaultUploadMaxBytes
+	}
+	return &UploadHandler{ingestService: ingestService, maxBytes: maxBytes}
+}
+
+// UploadMeme handles POST /api/v1/memes/upload. The image is the multipart
+// file field "file"; "category" and repeated "tag" fields are optional. New
+// memes are created with status review and stay out of search until an admin
+// approves them. Re-uploads of a stored image return the stored meme
+// with duplicate set and status 200 instead of 201; images a moderator
+// rejected return 409.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *UploadHandler) UploadMeme(c *gin.Context) {
+	ctx := c.Request.Context()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+imageFormOverhead)
+
+	data, err := readUploadFile(c, h.maxBytes)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge), errors.Is(err, service.ErrImageTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.MsgImageTooLarge, h.maxBytes)
+		return
+	case errors.Is(err, errImageRequired):
+		respondError(c, http.StatusBadRequest, i18n.MsgImageRequired)
+		return
+	case err != nil:
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	logger.CtxInfo(ctx, "Received meme upload: size=%d, client_ip=%s", len(data), c.ClientIP())
+
+	// Use a detached context so a dropped connection does not stop the pipeline halfway
+	result, err := h.ingestService.UploadMeme(logger.DetachContext(ctx), &service.UploadRequest{
+		Data:     data,
+		Category: c.PostForm("category"),
+		Tags:     c.PostFormArray("tag"),
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidImage):
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidImage, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidUpload):
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidUpload, err.Error())
+		return
+	case errors.Is(err, service.ErrUploadNearDuplicate):
+		respondError(c, http.StatusConflict, i18n.MsgUploadDuplicate)
+		return
+	case errors.Is(err, service.ErrUploadRejected):
+		respondError(c, http.StatusConflict, i18n.MsgUploadRejected)
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to upload meme: size=%d, error=%v", len(data), err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgUploadMeme)
+		return
+	}
+
+	status := http.StatusCreated
+	if result.Duplicate {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
+}
+
+// readUploadFile returns the multipart file "file", reading at most one byte
+// past maxBytes.
+func readUploadFile(c *gin.Context, maxBytes int64) ([]byte, error) {
+	header, err := c.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+		return nil, errImageRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, service.ErrImageTooLarge
+	}
+	return data, nil
+}
@@ -1,14 +1,22 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/api/stream"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
 	"github.com/timmy/emomo/internal/service"
 )
 
+// SearchCacheHeader is the request header that skips the search result cache
+// when set to "bypass".
+const SearchCacheHeader = "X-Search-Cache"
+
 // SearchHandler handles search-related endpoints.
 type SearchHandler struct {
 	searchService *service.SearchService
@@ -34,9 +42,7 @@ func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
 func (h *SearchHandler) TextSearch(c *gin.Context) {
 	var req service.SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
 		return
 	}
 
@@ -47,19 +53,73 @@ func (h *SearchHandler) TextSearch(c *gin.Context) {
 	if profile := c.Query("profile"); profile != "" && req.Profile == "" {
 		req.Profile = profile
 	}
+	req.NoCache = bypassSearchCache(c)
 
 	result, err := h.searchService.TextSearch(c.Request.Context(), &req)
+	if h.respondUnknownTarget(c, req.Collection, req.Profile, err) {
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, i18n.MsgSearchFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DebugSearch handles POST /api/v1/admin/search/debug. It takes the body of
+// POST /api/v1/search and returns the artifacts of every search stage.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *SearchHandler) DebugSearch(c *gin.Context) {
+	var req service.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.searchService.DebugSearch(c.Request.Context(), &req)
+	if h.respondUnknownTarget(c, req.Collection, req.Profile, err) {
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, i18n.MsgSearchFailed, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CompareSearch handles GET /api/v1/admin/search/compare?q=... It runs the
+// query against every registered collection and returns the ranked results
+// side by side. Besides q (or query), it takes the query parameters of
+// GET /api/v1/search/stream; collection and profile are ignored.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *SearchHandler) CompareSearch(c *gin.Context) {
+	req := service.SearchRequest{Query: c.Query("q")}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.searchService.CompareCollections(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Search failed: " + err.Error(),
-		})
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
-// GetCategories handles GET /api/v1/categories.
+// GetCategories handles GET /api/v1/categories. Categories with a pinned
+// cover are listed under "covers", keyed by category. With ?stats=true the
+// response also carries per-category counts and growth over the last
+// ?days=N days (default 30) under "stats".
 // Parameters:
 //   - c: Gin request context.
 //
@@ -67,16 +127,33 @@ func (h *SearchHandler) TextSearch(c *gin.Context) {
 func (h *SearchHandler) GetCategories(c *gin.Context) {
 	categories, err := h.searchService.GetCategories(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get categories: " + err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetCategories, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	covers, err := h.searchService.GetCategoryCovers(c.Request.Context())
+	if err != nil {
+		// Covers are decoration; the list is still useful without them.
+		logger.CtxWarn(c.Request.Context(), "Failed to load category covers: error=%v", err)
+	}
+
+	resp := gin.H{
 		"categories": categories,
 		"total":      len(categories),
-	})
+	}
+	if len(covers) > 0 {
+		resp["covers"] = covers
+	}
+	if withStats, _ := strconv.ParseBool(c.DefaultQuery("stats", "false")); withStats {
+		days, _ := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(service.DefaultCategoryTrendDays)))
+		stats, err := h.searchService.GetCategoryStats(c.Request.Context(), days)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, i18n.MsgGetCategories, err.Error())
+			return
+		}
+		resp["stats"] = stats
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetStats handles GET /api/v1/stats.
@@ -87,26 +164,45 @@ func (h *SearchHandler) GetCategories(c *gin.Context) {
 func (h *SearchHandler) GetStats(c *gin.Context) {
 	stats, err := h.searchService.GetStats(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get stats: " + err.Error(),
-		})
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetStats, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, stats)
 }
 
-// TextSearchStream handles POST /api/v1/search/stream with SSE.
+// GetStatsHistory handles GET /api/v1/stats/history. The optional days query
+// parameter sets the window (default 30, at most 365).
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *SearchHandler) GetStatsHistory(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(service.DefaultStatsHistoryDays)))
+	history, err := h.searchService.GetStatsHistory(c.Request.Context(), days)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetStatsHistory, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// TextSearchStream handles POST /api/v1/search/stream with SSE. GET takes the
+// same fields as query parameters (?query=...&top_k=...), so browsers can
+// consume the stream with EventSource.
 // Parameters:
 //   - c: Gin request context.
 //
 // Returns: none (writes SSE events).
 func (h *SearchHandler) TextSearchStream(c *gin.Context) {
 	var req service.SearchRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+	bind := c.ShouldBindJSON
+	if c.Request.Method == http.MethodGet {
+		bind = c.ShouldBindQuery
+	}
+	if err := bind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
 		return
 	}
 
@@ -117,12 +213,14 @@ func (h *SearchHandler) TextSearchStream(c *gin.Context) {
 	if profile := c.Query("profile"); profile != "" && req.Profile == "" {
 		req.Profile = profile
 	}
+	req.NoCache = bypassSearchCache(c)
+	// Reject unknown names with a status code before the stream starts.
+	if h.respondUnknownTarget(c, req.Collection, req.Profile, h.searchService.ValidateSearchTarget(&req)) {
+		return
+	}
 
-	// Set SSE headers
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
+	sse := stream.New(c, stream.DefaultHeartbeat)
+	defer sse.Close()
 
 	ctx := c.Request.Context()
 
@@ -139,13 +237,10 @@ func (h *SearchHandler) TextSearchStream(c *gin.Context) {
 		searchResult, searchErr = h.searchService.TextSearchWithProgress(ctx, &req, progressCh)
 	}()
 
-	// Get the response writer for flushing
-	w := c.Writer
-
 	// Stream progress events
 	for {
 		select {
-		case <-ctx.Done():
+		case <-sse.Done():
 			// Client disconnected
 			return
 		case progress, ok := <-progressCh:
@@ -154,13 +249,13 @@ func (h *SearchHandler) TextSearchStream(c *gin.Context) {
 				<-done
 				// Send final result
 				if searchErr != nil {
-					errData, _ := json.Marshal(gin.H{
+					_ = sse.Event("error", gin.H{
 						"stage": "error",
-						"error": searchErr.Error(),
+						"error": i18n.Message(ctx, i18n.MsgSearchFailed, searchErr.Error()),
+						"code":  i18n.MsgSearchFailed,
 					})
-					fmt.Fprintf(w, "event: error\ndata: %s\n\n", errData)
 				} else if searchResult != nil {
-					resultData, _ := json.Marshal(gin.H{
+					_ = sse.Event("complete", gin.H{
 						"stage":          "complete",
 						"results":        searchResult.Results,
 						"total":          searchResult.Total,
@@ -168,20 +263,76 @@ func (h *SearchHandler) TextSearchStream(c *gin.Context) {
 						"expanded_query": searchResult.ExpandedQuery,
 						"collection":     searchResult.Collection,
 						"profile":        searchResult.Profile,
+						"cached":         searchResult.Cached,
 					})
-					fmt.Fprintf(w, "event: complete\ndata: %s\n\n", resultData)
 				}
-				w.Flush()
 				return
 			}
-			// Write SSE event
 			eventType := "progress"
 			if progress.Stage == "thinking" {
 				eventType = "thinking"
 			}
-			data, _ := json.Marshal(progress)
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
-			w.Flush()
+			if err := sse.Event(eventType, progress); err != nil {
+				return
+			}
 		}
 	}
 }
+
+// GetCollections handles GET /api/v1/collections. It lists the collections
+// and search profiles a search can name with "collection" and "profile",
+// with the embedding model of each.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *SearchHandler) GetCollections(c *gin.Context) {
+	collections := h.searchService.ListCollections()
+	profiles := h.searchService.ListProfiles()
+	resp := gin.H{
+		"collections": collections,
+		"profiles":    profiles,
+	}
+	for _, collection := range collections {
+		if collection.Default {
+			resp["default_collection"] = collection.Name
+		}
+	}
+	for _, profile := range profiles {
+		if profile.Default {
+			resp["default_profile"] = profile.Name
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondUnknownTarget answers 400 with the registered names when err is an
+// unknown collection or profile.
+// Parameters:
+//   - c: Gin request context.
+//   - collection: collection named by the request.
+//   - profile: profile named by the request.
+//   - err: error of a search, or nil.
+//
+// Returns:
+//   - bool: true if a response was written.
+func (h *SearchHandler) respondUnknownTarget(c *gin.Context, collection, profile string, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrUnknownProfile):
+		respondError(c, http.StatusBadRequest, i18n.MsgBadProfile,
+			profile, strings.Join(h.searchService.GetAvailableProfiles(), ", "))
+		return true
+	case errors.Is(err, service.ErrUnknownCollection):
+		respondError(c, http.StatusBadRequest, i18n.MsgBadCollection,
+			collection, strings.Join(h.searchService.GetAvailableCollections(), ", "))
+		return true
+	default:
+		return false
+	}
+}
+
+// bypassSearchCache reports whether the request asks to skip the search
+// result cache with X-Search-Cache: bypass.
+func bypassSearchCache(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader(SearchCacheHeader), "bypass")
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/service"
+)
+
+func TestSearchRequestBindsQueryParameters(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/search/stream?query=%E5%BC%80%E5%BF%83&top_k=5&category=%E7%8C%AB&profile=qwen3vl", nil)
+
+	var req service.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		t.Fatalf("ShouldBindQuery() error = %v", err)
+	}
+	if req.Query != "开心" || req.TopK != 5 || req.Profile != "qwen3vl" {
+		t.Fatalf("bound request = %+v, want query 开心, top_k 5, profile qwen3vl", req)
+	}
+	if req.Category == nil || *req.Category != "猫" {
+		t.Fatalf("bound category = %v, want 猫", req.Category)
+	}
+}
+
+func TestTextSearchStreamGetRequiresQuery(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/search/stream?top_k=5", nil)
+	NewSearchHandler(nil).TextSearchStream(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TextSearchStream() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got := rec.Header().Get("Content-Type"); got == "text/event-stream" {
+		t.Fatalf("TextSearchStream() started a stream for an invalid request")
+	}
+}
+
+func TestTextSearchStreamRejectsUnknownCollection(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	searchService := service.NewSearchService(nil, nil, nil, nil, nil, nil, nil, &service.SearchConfig{
+		DefaultCollection: "qwen3",
+	})
+	searchService.RegisterCollection("qwen3", nil, nil)
+	searchService.RegisterCollection("jina", nil, nil)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/search/stream?query=hi&collection=clip", nil)
+	NewSearchHandler(searchService).TextSearchStream(c)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("TextSearchStream() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "error.unknown_collection") || !strings.Contains(body, "qwen3, jina") {
+		t.Fatalf("TextSearchStream() body = %s, want the unknown collection code and available names", body)
+	}
+}
+
+func TestGetCollectionsListsDefaultFirst(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	searchService := service.NewSearchService(nil, nil, nil, nil, nil, nil, nil, &service.SearchConfig{
+		DefaultCollection: "qwen3",
+	})
+	searchService.RegisterCollection("jina", nil, nil)
+	searchService.RegisterCollection("qwen3", nil, nil)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/collections", nil)
+	NewSearchHandler(searchService).GetCollections(c)
+
+	var resp struct {
+		Collections       []service.CollectionInfo `json:"collections"`
+		DefaultCollection string                   `json:"default_collection"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.DefaultCollection != "qwen3" || len(resp.Collections) != 2 || resp.Collections[1].Name != "jina" {
+		t.Fatalf("GetCollections() = %+v, want qwen3 (default) then jina", resp)
+	}
+}
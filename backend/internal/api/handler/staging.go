@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/source"
+)
+
+const (
+	defaultStagingMaxBytes = 100 << 20
+	defaultStagingSource   = "localdir"
+)
+
+// errStagingTooLarge is returned when the files of a staging upload exceed
+// the limit once unpacked.
+var errStagingTooLarge = errors.New("staging upload too large")
+
+// StagingConfig configures UploadStaging.
+type StagingConfig struct {
+	MaxBytes int64 // Largest accepted upload, and total size of the unpacked files (default 100 MiB)
+}
+
+// StagingUploadResponse reports a staging upload.
+type StagingUploadResponse struct {
+	Source string `json:"source"`
+	source.StageResult
+	TotalItems *int `json:"total_items,omitempty"` // Items of the source after the upload; omitted if it cannot be counted
+}
+
+// SetStaging configures UploadStaging. Call it before serving requests.
+// Parameters:
+//   - cfg: size limit of staging uploads.
+//
+// Returns: none.
+func (h *AdminHandler) SetStaging(cfg StagingConfig) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultStagingMaxBytes
+	}
+	h.staging = cfg
+}
+
+// UploadStaging handles POST /api/v1/admin/staging/upload, which writes a
+// crawler batch into the staging layout of a source: images plus stage2
+// manifest entries. The multipart body carries either a zip "archive" with
+// one .jsonl manifest and the images, or a "manifest" file and repeated
+// "file" images; "source" defaults to localdir. The source is rescanned, so
+// its next ingest picks up the batch. Uploads are refused while a job of the
+// source runs.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes 201 with the written files or a JSON error).
+func (h *AdminHandler) UploadStaging(c *gin.Context) {
+	ctx := c.Request.Context()
+	maxBytes := h.staging.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultStagingMaxBytes
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+imageFormOverhead)
+
+	form, err := c.MultipartForm()
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.MsgStagingTooLarge, maxBytes)
+		return
+	case err != nil:
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidStaging, err.Error())
+		return
+	}
+
+	name := defaultStagingSource
+	if values := form.Value["source"]; len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		name = strings.TrimSpace(values[0])
+	}
+	src, ok := h.sources[name]
+	if !ok {
+		respondError(c, http.StatusNotFound, i18n.MsgUnknownSource, name)
+		return
+	}
+	stager, ok := src.(source.Stager)
+	if !ok {
+		respondError(c, http.StatusBadRequest, i18n.MsgNoStaging, name)
+		return
+	}
+
+	manifest, files, err := readStagingForm(form, maxBytes)
+	switch {
+	case errors.Is(err, errStagingTooLarge):
+		respondError(c, http.StatusRequestEntityTooLarge, i18n.MsgStagingTooLarge, maxBytes)
+		return
+	case err != nil:
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidStaging, err.Error())
+		return
+	}
+
+	// Rescanning while an ingest pages through the source would shift its
+	// items, so staging needs the source to itself
+	release, ok := h.jobs.tryAcquireExclusive(src.GetSourceID())
+	if !ok {
+		logger.CtxWarn(ctx, "Staging upload rejected: source job running, source=%s, client_ip=%s", name, c.ClientIP())
+		respondError(c, http.StatusConflict, i18n.MsgSourceJobRunning, name)
+		return
+	}
+	defer release()
+
+	logger.CtxInfo(ctx, "Received staging upload: source=%s, files=%d, client_ip=%s", name, len(files), c.ClientIP())
+
+	result, err := stager.Stage(manifest, files)
+	switch {
+	case errors.Is(err, source.ErrStagingUnsupported):
+		respondError(c, http.StatusBadRequest, i18n.MsgNoStaging, name)
+		return
+	case errors.Is(err, source.ErrInvalidStaging):
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidStaging, err.Error())
+		return
+	case errors.Is(err, source.ErrStagingConflict):
+		respondError(c, http.StatusConflict, i18n.MsgStagingConflict, err.Error())
+		return
+	case err != nil:
+		logger.CtxError(ctx, "Failed to write staging upload: source=%s, error=%v", name, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgStageUpload)
+		return
+	}
+
+	resp := StagingUploadResponse{Source: name, StageResult: *result}
+	if counter, ok := src.(source.Counter); ok {
+		if total, err := counter.GetTotalCount(ctx); err != nil {
+			logger.CtxWarn(ctx, "Failed to count source after staging upload: source=%s, error=%v", name, err)
+		} else {
+			resp.TotalItems = &total
+		}
+	}
+
+	logger.CtxInfo(ctx, "Staging upload written: source=%s, written=%d, unchanged=%d, skipped=%d, entries=%d",
+		name, result.Written, result.Unchanged, result.Skipped, result.Entries)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// readStagingForm returns the manifest and images of a staging upload form,
+// reading at most maxBytes of unpacked files.
+func readStagingForm(form *multipart.Form, maxBytes int64) ([]byte, []source.StagedFile, error) {
+	budget := &stagingBudget{remaining: maxBytes}
+
+	if archives := form.File["archive"]; len(archives) > 0 {
+		if len(archives) > 1 || len(form.File["manifest"]) > 0 || len(form.File["file"]) > 0 {
+			return nil, nil, errors.New(`send either one "archive" or a "manifest" with "file" fields`)
+		}
+		return readStagingArchive(archives[0], budget)
+	}
+
+	manifests := form.File["manifest"]
+	if len(manifests) != 1 {
+		return nil, nil, errors.New(`an "archive" zip or one "manifest" file is required`)
+	}
+	manifest, err := budget.readFormFile(manifests[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]source.StagedFile, 0, len(form.File["file"]))
+	for _, header := range form.File["file"] {
+		data, err := budget.readFormFile(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, source.StagedFile{Name: header.Filename, Data: data})
+	}
+	return manifest, files, nil
+}
+
+// readStagingArchive returns the manifest and images of a zip: the single
+// .jsonl entry is the manifest and every other file an image, named by its
+// base name. Directories and hidden entries such as __MACOSX are skipped.
+func readStagingArchive(header *multipart.FileHeader, budget *stagingBudget) ([]byte, []source.StagedFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var manifest []byte
+	files := make([]source.StagedFile, 0, len(archive.File))
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || hiddenArchivePath(entry.Name) {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s in archive: %w", entry.Name, err)
+		}
+		data, err := budget.read(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name := path.Base(entry.Name)
+		if strings.EqualFold(path.Ext(name), ".jsonl") {
+			if manifest != nil {
+				return nil, nil, errors.New("archive holds more than one .jsonl manifest")
+			}
+			manifest = data
+			continue
+		}
+		files = append(files, source.StagedFile{Name: name, Data: data})
+	}
+	if manifest == nil {
+		return nil, nil, errors.New("archive holds no .jsonl manifest")
+	}
+	return manifest, files, nil
+}
+
+// hiddenArchivePath reports whether a zip entry or one of its directories
+// starts with a dot or is the __MACOSX metadata of macOS archives.
+func hiddenArchivePath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// stagingBudget bounds the bytes read from the files of one upload, so a
+// small zip cannot unpack into more than the upload limit.
+type stagingBudget struct {
+	remaining int64
+}
+
+// read reads r, failing with errStagingTooLarge past the remaining budget.
+func (b *stagingBudget) read(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, b.remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > b.remaining {
+		return nil, errStagingTooLarge
+	}
+	b.remaining -= int64(len(data))
+	return data, nil
+}
+
+// readFormFile reads a multipart file within the budget.
+func (b *stagingBudget) readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer file.Close()
+	return b.read(file)
+}
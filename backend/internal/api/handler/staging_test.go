@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/source"
+	"github.com/timmy/emomo/internal/source/localdir"
+)
+
+func TestUploadStagingArchive(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "stage2_results.jsonl")
+	h := NewAdminHandler(nil, map[string]source.Source{
+		"localdir": localdir.NewAdapter(localdir.Options{RootPath: root, ManifestPath: manifestPath}),
+	}, nil)
+	h.SetStaging(StagingConfig{MaxBytes: 1 << 10})
+
+	upload := func(entries map[string]string) *httptest.ResponseRecorder {
+		t.Helper()
+		var archive bytes.Buffer
+		zw := zip.NewWriter(&archive)
+		for name, data := range entries {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatalf("zip Create(%s) error = %v", name, err)
+			}
+			w.Write([]byte(data))
+		}
+		zw.Close()
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, _ := mw.CreateFormFile("archive", "batch.zip")
+		part.Write(archive.Bytes())
+		mw.Close()
+
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/staging/upload", &body)
+		c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+		h.UploadStaging(c)
+		return rec
+	}
+
+	rec := upload(map[string]string{
+		"batch/stage2_results.jsonl": `{"note_id":"n1","filename":"cat.jpg","keyword":"猫猫","keep":true}` + "\n",
+		"batch/images/cat.jpg":       "cat",
+		"__MACOSX/batch/._cat.jpg":   "resource fork",
+	})
+	var resp StagingUploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("UploadStaging() = %d %s, want 201", rec.Code, rec.Body)
+	}
+	if resp.Source != "localdir" || resp.Written != 1 || resp.TotalItems == nil || *resp.TotalItems != 1 {
+		t.Fatalf("UploadStaging() = %+v, want 1 file written and 1 item in localdir", resp)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "cat.jpg")); err != nil || string(data) != "cat" {
+		t.Fatalf("staged cat.jpg = %q, %v, want cat", data, err)
+	}
+
+	// The limit also bounds the unpacked files of a well-compressed archive
+	rec = upload(map[string]string{
+		"stage2_results.jsonl": `{"filename":"big.png","keep":true}`,
+		"big.png":              string(bytes.Repeat([]byte{0}, 4<<10)),
+	})
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("UploadStaging(unpacks past the limit) = %d %s, want 413", rec.Code, rec.Body)
+	}
+
+	rec = upload(map[string]string{"cat.jpg": "cat"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("UploadStaging(no manifest) = %d %s, want 400", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+)
+
+// UsageHandler handles API key management and usage endpoints.
+type UsageHandler struct {
+	usageService *service.UsageService
+}
+
+// NewUsageHandler creates a new usage handler.
+// Parameters:
+//   - usageService: usage service instance.
+//
+// Returns:
+//   - *UsageHandler: initialized handler.
+func NewUsageHandler(usageService *service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetKeyUsage handles GET /api/v1/admin/keys/:id/usage.
+// Query parameter months (default 12) limits the earlier months returned.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *UsageHandler) GetKeyUsage(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	months, _ := strconv.Atoi(c.DefaultQuery("months", "12"))
+	if months < 0 || months > 120 {
+		months = 12
+	}
+
+	result, err := h.usageService.GetUsage(ctx, id, months)
+	if errors.Is(err, service.ErrUnknownAPIKey) {
+		respondError(c, http.StatusNotFound, i18n.MsgUnknownAPIKey, id)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to get API key usage: key_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgGetKeyUsage)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateKey handles POST /api/v1/admin/keys.
+// The response carries the secret in "key"; it is not stored and cannot be
+// shown again.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *UsageHandler) CreateKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req service.CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+
+	key, err := h.usageService.CreateKey(ctx, req)
+	if errors.Is(err, service.ErrInvalidKeyRequest) {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to create API key: name=%s, error=%v", req.Name, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgCreateAPIKey)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListKeys handles GET /api/v1/admin/keys.
+// Query parameter include_revoked=true lists revoked keys too. Keys from
+// config.yaml are not listed.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *UsageHandler) ListKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	includeRevoked := c.Query("include_revoked") == "true"
+
+	keys, err := h.usageService.ListKeys(ctx, includeRevoked)
+	if err != nil {
+		logger.CtxError(ctx, "Failed to list API keys: error=%v", err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgListAPIKeys)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys":  keys,
+		"total": len(keys),
+	})
+}
+
+// RevokeKey handles DELETE /api/v1/admin/keys/:id.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes JSON response).
+func (h *UsageHandler) RevokeKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	key, err := h.usageService.RevokeKey(ctx, id)
+	if errors.Is(err, service.ErrUnknownAPIKey) {
+		respondError(c, http.StatusNotFound, i18n.MsgUnknownAPIKey, id)
+		return
+	}
+	if err != nil {
+		logger.CtxError(ctx, "Failed to revoke API key: key_id=%s, error=%v", id, err)
+		respondError(c, http.StatusInternalServerError, i18n.MsgRevokeAPIKey)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of
+	// "<timestamp>.<body>", keyed by the webhook secret.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader carries the Unix time the request was signed at.
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+
+	defaultWebhookLimit   = 10000
+	defaultWebhookMaxSkew = 5 * time.Minute
+	maxWebhookBodyBytes   = 64 << 10
+)
+
+// WebhookConfig configures the staging manifest webhook.
+type WebhookConfig struct {
+	Secret  string        // HMAC-SHA256 key shared with the crawler
+	Limit   int           // Items per triggered ingest (default 10000)
+	MaxSkew time.Duration // Largest accepted distance of the signed time from now (default 5m)
+	Review  bool          // Hold new memes of triggered ingests in the moderation queue
+}
+
+// ManifestWebhookRequest is the optional body of the staging manifest
+// webhook. Every field is optional.
+type ManifestWebhookRequest struct {
+	Manifest string `json:"manifest"` // Manifest file the crawler wrote, for the logs
+	Items    int    `json:"items"`    // Entries the crawler wrote, for the logs
+	Limit    int    `json:"limit"`    // Overrides the configured items per ingest (at most 10000)
+}
+
+// SetWebhook configures ManifestWebhook. Call it before serving requests.
+// Parameters:
+//   - cfg: shared secret and ingest settings of the webhook.
+//
+// Returns: none.
+func (h *AdminHandler) SetWebhook(cfg WebhookConfig) {
+	if cfg.Limit <= 0 {
+		cfg.Limit = defaultWebhookLimit
+	}
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = defaultWebhookMaxSkew
+	}
+	h.webhook = cfg
+}
+
+// ManifestWebhook handles POST /api/v1/ingest/webhook/:source, which the
+// crawler calls once it finished writing a staging manifest. A correctly
+// signed request queues an ingest job of the source like POST
+// /api/v1/ingest; the job rescans the source when it starts, so it reads
+// the new manifest.
+// Parameters:
+//   - c: Gin request context.
+//
+// Returns: none (writes 202 with the job ID or a JSON error).
+func (h *AdminHandler) ManifestWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Param("source")
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+		return
+	}
+	if err := verifyWebhookSignature(h.webhook.Secret, c.GetHeader(WebhookTimestampHeader),
+		c.GetHeader(WebhookSignatureHeader), body, time.Now(), h.webhook.MaxSkew); err != nil {
+		logger.CtxWarn(ctx, "Manifest webhook rejected: source=%s, client_ip=%s, error=%v", name, c.ClientIP(), err)
+		respondError(c, http.StatusUnauthorized, i18n.MsgBadSignature)
+		return
+	}
+
+	var req ManifestWebhookRequest
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			respondError(c, http.StatusBadRequest, i18n.MsgInvalidRequest, err.Error())
+			return
+		}
+	}
+	limit := h.webhook.Limit
+	if req.Limit > 0 {
+		limit = min(req.Limit, defaultWebhookLimit)
+	}
+
+	src, ok := h.sources[name]
+	if !ok {
+		respondError(c, http.StatusNotFound, i18n.MsgUnknownSource, name)
+		return
+	}
+	logger.CtxInfo(ctx, "Manifest webhook received: source=%s, manifest=%s, items=%d, limit=%d, client_ip=%s",
+		name, req.Manifest, req.Items, limit, c.ClientIP())
+
+	h.queueIngest(c, src, &IngestRequest{Source: name, Limit: limit, Review: h.webhook.Review, rescan: true},
+		&service.IngestOptions{Review: h.webhook.Review})
+}
+
+// verifyWebhookSignature checks that signature is the HMAC-SHA256 of
+// "<timestamp>.<body>" keyed by secret and that timestamp is within maxSkew
+// of now, so a captured request cannot be replayed later.
+func verifyWebhookSignature(secret, timestamp, signature string, body []byte, now time.Time, maxSkew time.Duration) error {
+	if secret == "" {
+		return errors.New("webhook secret not configured")
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %q", WebhookTimestampHeader, timestamp)
+	}
+	if skew := now.Sub(time.Unix(unix, 0)).Abs(); skew > maxSkew {
+		return fmt.Errorf("timestamp is %s away from now", skew.Round(time.Second))
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return fmt.Errorf("malformed %s", WebhookSignatureHeader)
+	}
+	if !hmac.Equal(got, webhookMAC(secret, timestamp, body)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// webhookMAC returns the HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+func webhookMAC(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
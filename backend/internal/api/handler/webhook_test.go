@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"manifest":"stage2_results.jsonl","items":120}`)
+	sign := func(secret, timestamp string) string {
+		return "sha256=" + hex.EncodeToString(webhookMAC(secret, timestamp, body))
+	}
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		wantErr   bool
+	}{
+		{"valid", "s3cret", "1800000000", sign("s3cret", "1800000000"), false},
+		{"within skew", "s3cret", "1799999800", sign("s3cret", "1799999800"), false},
+		{"expired", "s3cret", "1799999000", sign("s3cret", "1799999000"), true},
+		{"other secret", "s3cret", "1800000000", sign("other", "1800000000"), true},
+		{"timestamp not signed", "s3cret", "1800000001", sign("s3cret", "1800000000"), true},
+		{"missing prefix", "s3cret", "1800000000", sign("s3cret", "1800000000")[len("sha256="):], true},
+		{"missing timestamp", "s3cret", "", sign("s3cret", ""), true},
+		{"no secret", "", "1800000000", sign("", "1800000000"), true},
+	}
+	for _, tt := range tests {
+		err := verifyWebhookSignature(tt.secret, tt.timestamp, tt.signature, body, now, 5*time.Minute)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("verifyWebhookSignature(%s) error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/service"
+	"github.com/timmy/emomo/internal/usage"
+)
+
+// APIKeyHeader is the header carrying the API key. "Authorization: Bearer <key>"
+// is accepted as well.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyIDContextKey is the Gin context key holding the authenticated key ID.
+const APIKeyIDContextKey = "api_key_id"
+
+// APIKey returns middleware that authenticates API keys, rejects requests of
+// keys over their monthly quota (429 for requests, 402 for cost) and records
+// the requests and model tokens of every key. Requests without a key pass
+// unmetered unless the service requires a key.
+// Parameters:
+//   - usageService: service holding the keys, quotas and usage counters.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func APIKey(usageService *service.UsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		key := requestAPIKey(c.Request)
+		if key == "" {
+			if usageService.RequireKey() {
+				abortWithError(c, http.StatusUnauthorized, i18n.MsgAPIKeyRequired)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		keyID, err := usageService.Authenticate(ctx, key)
+		switch {
+		case errors.Is(err, service.ErrAPIKeyExpired):
+			logger.CtxWarn(ctx, "Expired API key rejected: client_ip=%s", c.ClientIP())
+			abortWithError(c, http.StatusUnauthorized, i18n.MsgAPIKeyExpired)
+			return
+		case errors.Is(err, service.ErrUnknownAPIKey):
+			logger.CtxWarn(ctx, "Unknown API key rejected: client_ip=%s", c.ClientIP())
+			abortWithError(c, http.StatusUnauthorized, i18n.MsgInvalidAPIKey)
+			return
+		case err != nil:
+			logger.CtxError(ctx, "Failed to authenticate API key: error=%v", err)
+			abortWithError(c, http.StatusInternalServerError, i18n.MsgCheckAPIKey)
+			return
+		}
+
+		if err := usageService.CheckQuota(ctx, keyID); err != nil {
+			switch {
+			case errors.Is(err, service.ErrRequestQuotaExceeded):
+				logger.CtxWarn(ctx, "API key over request quota: key_id=%s", keyID)
+				abortWithError(c, http.StatusTooManyRequests, i18n.MsgRequestQuota)
+			case errors.Is(err, service.ErrCostQuotaExceeded):
+				logger.CtxWarn(ctx, "API key over cost quota: key_id=%s", keyID)
+				abortWithError(c, http.StatusPaymentRequired, i18n.MsgCostQuota)
+			default:
+				logger.CtxError(ctx, "Failed to check API key quota: key_id=%s, error=%v", keyID, err)
+				abortWithError(c, http.StatusInternalServerError, i18n.MsgCheckQuota)
+			}
+			return
+		}
+
+		meter := &usage.Meter{}
+		c.Request = c.Request.WithContext(usage.NewContext(ctx, meter))
+		c.Set(APIKeyIDContextKey, keyID)
+
+		c.Next()
+
+		// The request context may already be canceled once the client is gone.
+		recordCtx := logger.DetachContext(ctx)
+		if err := usageService.Record(recordCtx, keyID, meter.LLMTokens(), meter.EmbeddingTokens()); err != nil {
+			logger.CtxError(recordCtx, "Failed to record API key usage: key_id=%s, error=%v", keyID, err)
+		}
+	}
+}
+
+// APIKeyAuth returns middleware that restricts routes to API keys allowed
+// the scope of the route. It runs after APIKey, which authenticates the key.
+// Keys created through the admin API need the scope (or admin). Config keys
+// need a role on ingest and admin routes when api_keys.admin_auth is on: GET
+// and HEAD requests need the readonly or admin role, other methods need
+// admin. Requests without a key pass search routes; on other routes they get
+// 401 when api_keys.admin_auth is on. Keys not allowed get 403.
+// Parameters:
+//   - usageService: service holding the keys, their roles and scopes.
+//   - scope: scope of the routes.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func APIKeyAuth(usageService *service.UsageService, scope service.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		keyID := c.GetString(APIKeyIDContextKey)
+		if keyID == "" {
+			if scope != service.APIKeyScopeSearch && usageService.AdminAuthRequired() {
+				abortWithError(c, http.StatusUnauthorized, i18n.MsgAPIKeyRequired)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		err := usageService.Authorize(ctx, keyID, scope, readOnly)
+		switch {
+		case errors.Is(err, service.ErrMissingScope):
+			logger.CtxWarn(ctx, "API key lacks scope: key_id=%s, required=%s, method=%s, path=%s",
+				keyID, scope, c.Request.Method, c.FullPath())
+			abortWithError(c, http.StatusForbidden, i18n.MsgAPIKeyScope, scope)
+			return
+		case errors.Is(err, service.ErrMissingRole):
+			required := service.APIKeyRoleAdmin
+			if readOnly {
+				required = service.APIKeyRoleReadonly
+			}
+			logger.CtxWarn(ctx, "API key lacks role: key_id=%s, required=%s, method=%s, path=%s",
+				keyID, required, c.Request.Method, c.FullPath())
+			abortWithError(c, http.StatusForbidden, i18n.MsgAPIKeyForbidden, required)
+			return
+		case err != nil:
+			logger.CtxError(ctx, "Failed to authorize API key: key_id=%s, error=%v", keyID, err)
+			abortWithError(c, http.StatusInternalServerError, i18n.MsgCheckAPIKey)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestAPIKey returns the key of the X-API-Key header or the bearer token.
+func requestAPIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return ""
+}
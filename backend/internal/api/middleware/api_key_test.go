@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"github.com/timmy/emomo/internal/service"
+)
+
+func TestAPIKeyAuthChecksRoles(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	db := testutil.NewTestDB(t)
+	usageService := service.NewUsageService(&config.APIKeysConfig{
+		AdminAuth: true,
+		Keys: []config.APIKeyConfig{
+			{ID: "admin", Key: "secret-admin", Role: "admin"},
+			{ID: "viewer", Key: "secret-viewer", Role: "readonly"},
+			{ID: "client", Key: "secret-client"},
+		},
+	}, repository.NewAPIKeyUsageRepository(db))
+
+	r := gin.New()
+	admin := r.Group("/admin", APIKey(usageService), APIKeyAuth(usageService, service.APIKeyScopeAdmin))
+	admin.GET("/jobs", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.DELETE("/memes/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		key    string
+		want   int
+	}{
+		{http.MethodGet, "/admin/jobs", "", http.StatusUnauthorized},
+		{http.MethodGet, "/admin/jobs", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/admin/jobs", "secret-client", http.StatusForbidden},
+		{http.MethodGet, "/admin/jobs", "secret-viewer", http.StatusOK},
+		{http.MethodDelete, "/admin/memes/1", "secret-viewer", http.StatusForbidden},
+		{http.MethodDelete, "/admin/memes/1", "secret-admin", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.key != "" {
+			req.Header.Set(APIKeyHeader, tc.key)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s %s with key %q = %d, want %d", tc.method, tc.path, tc.key, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestAPIKeyAuthChecksScopes(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	db := testutil.NewTestDB(t)
+	usageService := service.NewUsageService(&config.APIKeysConfig{
+		Keys: []config.APIKeyConfig{{ID: "client", Key: "secret-client"}},
+	}, repository.NewAPIKeyUsageRepository(db))
+	usageService.SetKeyRepository(repository.NewAPIKeyRepository(db))
+	searcher, err := usageService.CreateKey(context.Background(), service.CreateKeyRequest{Name: "app", Scopes: []string{"search"}})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	ingester, err := usageService.CreateKey(context.Background(), service.CreateKeyRequest{Name: "bot", Scopes: []string{"ingest"}})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+
+	r := gin.New()
+	r.Use(APIKey(usageService))
+	r.POST("/search", APIKeyAuth(usageService, service.APIKeyScopeSearch), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/ingest", APIKeyAuth(usageService, service.APIKeyScopeIngest), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tc := range []struct {
+		path string
+		key  string
+		want int
+	}{
+		{"/search", "", http.StatusOK},
+		{"/search", "secret-client", http.StatusOK},
+		{"/search", searcher.Key, http.StatusOK},
+		{"/search", ingester.Key, http.StatusForbidden},
+		{"/ingest", "", http.StatusUnauthorized}, // configured keys imply admin_auth
+		{"/ingest", "secret-client", http.StatusForbidden},
+		{"/ingest", searcher.Key, http.StatusForbidden},
+		{"/ingest", ingester.Key, http.StatusOK},
+		{"/ingest", "emk_unknown", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+		if tc.key != "" {
+			req.Header.Set(APIKeyHeader, tc.key)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("POST %s with key %q = %d, want %d", tc.path, tc.key, rec.Code, tc.want)
+		}
+	}
+}
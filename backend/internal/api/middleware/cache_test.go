@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCacheControl(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	cache := CacheControl(CacheConfig{Enabled: true, MaxAge: time.Minute, SMaxAge: 10 * time.Minute},
+		func(c *gin.Context) []string { return []string{"memes", "category-" + c.Query("category")} })
+	r := gin.New()
+	r.GET("/memes", cache, func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		if c.GetHeader("If-None-Match") != "" {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"memes": []string{}})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memes?category=cats", nil))
+	h := rec.Header()
+	if got := h.Get("Cache-Control"); got != "public, max-age=60, s-maxage=600" {
+		t.Fatalf("Cache-Control = %q, want public with max-age and s-maxage", got)
+	}
+	if h.Get("Surrogate-Key") != "memes category-cats" || h.Get("Cache-Tag") != "memes,category-cats" {
+		t.Fatalf("Surrogate-Key = %q, Cache-Tag = %q, want memes and category-cats", h.Get("Surrogate-Key"), h.Get("Cache-Tag"))
+	}
+	if h.Get("Vary") == "" {
+		t.Fatal("Vary is empty, want the API key headers")
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/memes?fail=1", nil))
+	if got := rec.Header().Get("Cache-Control"); rec.Code != http.StatusInternalServerError || got != "no-store" {
+		t.Fatalf("error response = %d with Cache-Control %q, want 500 with no-store", rec.Code, got)
+	}
+	if got := rec.Header().Get("Surrogate-Key"); got != "" {
+		t.Fatalf("error response Surrogate-Key = %q, want none", got)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/memes", nil)
+	req.Header.Set("If-None-Match", `"etag"`)
+	r.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Cache-Control"); rec.Code != http.StatusNotModified || got != "public, max-age=60, s-maxage=600" {
+		t.Fatalf("not modified response = %d with Cache-Control %q, want 304 keeping the cache lifetimes", rec.Code, got)
+	}
+}
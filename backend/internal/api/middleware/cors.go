@@ -1,75 +1,169 @@
 package middleware
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultCORSHeaders are the request headers allowed when a policy lists none.
+var DefaultCORSHeaders = []string{
+	"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization",
+	"accept", "origin", "Cache-Control", "X-Requested-With", APIKeyHeader,
+	"X-Search-Cache",
+}
+
 // CORSConfig holds CORS configuration.
 type CORSConfig struct {
 	AllowedOrigins  []string
 	AllowAllOrigins bool
+	AllowedHeaders  []string      // Request headers allowed by preflights (empty uses DefaultCORSHeaders)
+	MaxAge          time.Duration // How long browsers cache preflight results (0 omits Access-Control-Max-Age)
+}
+
+// CORSGroup is a CORS policy for the routes under some path prefixes.
+type CORSGroup struct {
+	PathPrefixes []string // e.g. /api/v1/admin; matches the prefix and paths below it
+	Config       CORSConfig
 }
 
 // CORS returns middleware that handles Cross-Origin Resource Sharing.
 // Parameters:
 //   - config: CORS configuration values.
+//
 // Returns:
 //   - gin.HandlerFunc: middleware handler.
 func CORS(config CORSConfig) gin.HandlerFunc {
+	return CORSWithGroups(config, nil)
+}
+
+// CORSWithGroups returns middleware that handles Cross-Origin Resource Sharing
+// with the policy of the first group matching the request path, or config for
+// paths no group matches. It must be installed on the engine rather than on
+// route groups so preflights of any route reach it.
+// Parameters:
+//   - config: default CORS policy.
+//   - groups: per-path policies, checked in order.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func CORSWithGroups(config CORSConfig, groups []CORSGroup) gin.HandlerFunc {
+	policies := make([]corsPolicy, 0, len(groups)+1)
+	for _, group := range groups {
+		policies = append(policies, newCORSPolicy(group.PathPrefixes, group.Config))
+	}
+	defaultPolicy := newCORSPolicy(nil, config)
+
 	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-
-		// Determine allowed origin
-		var allowedOrigin string
-		if config.AllowAllOrigins {
-			allowedOrigin = "*"
-			// When using *, credentials must be false
-			c.Writer.Header().Set("Access-Control-Allow-Credentials", "false")
-		} else {
-			// Check if origin is in allowed list
-			allowed := false
-			for _, allowedOriginItem := range config.AllowedOrigins {
-				if origin == allowedOriginItem || allowedOriginItem == "*" {
-					allowed = true
-					allowedOrigin = origin
-					break
-				}
+		policy := defaultPolicy
+		for _, p := range policies {
+			if p.matches(c.Request.URL.Path) {
+				policy = p
+				break
 			}
+		}
+		policy.handle(c)
+	}
+}
 
-			if !allowed && len(config.AllowedOrigins) > 0 {
-				// Origin not allowed, don't set CORS headers
-				c.Next()
-				return
-			}
+// corsPolicy is a CORSConfig with its headers rendered once.
+type corsPolicy struct {
+	prefixes     []string
+	config       CORSConfig
+	allowHeaders string
+	maxAge       string
+}
 
-			// If no origins configured or origin matches, allow it
-			if allowedOrigin == "" {
+func newCORSPolicy(prefixes []string, config CORSConfig) corsPolicy {
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	policy := corsPolicy{
+		prefixes:     prefixes,
+		config:       config,
+		allowHeaders: strings.Join(headers, ", "),
+	}
+	if config.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	return policy
+}
+
+// matches reports whether path is one of the prefixes or below one.
+func (p corsPolicy) matches(path string) bool {
+	for _, prefix := range p.prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// handle sets the CORS headers of the policy and answers preflights.
+func (p corsPolicy) handle(c *gin.Context) {
+	config := p.config
+	origin := c.Request.Header.Get("Origin")
+
+	// Determine allowed origin
+	var allowedOrigin string
+	if config.AllowAllOrigins {
+		allowedOrigin = "*"
+		// When using *, credentials must be false
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "false")
+	} else {
+		// Check if origin is in allowed list
+		allowed := false
+		for _, allowedOriginItem := range config.AllowedOrigins {
+			if origin == allowedOriginItem || allowedOriginItem == "*" {
+				allowed = true
 				allowedOrigin = origin
+				break
 			}
-			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
-
-		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if !allowed && len(config.AllowedOrigins) > 0 {
+			// Origin not allowed, don't set CORS headers
+			c.Next()
 			return
 		}
 
-		c.Next()
+		// If no origins configured or origin matches, allow it
+		if allowedOrigin == "" {
+			allowedOrigin = origin
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	c.Writer.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+	if allowedOrigin != "*" {
+		// The response depends on the origin; keep shared caches from mixing them up
+		c.Writer.Header().Add("Vary", "Origin")
 	}
+	c.Writer.Header().Set("Access-Control-Allow-Headers", p.allowHeaders)
+	c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+	c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+
+	// Handle preflight requests
+	if c.Request.Method == "OPTIONS" {
+		if p.maxAge != "" {
+			c.Writer.Header().Set("Access-Control-Max-Age", p.maxAge)
+		}
+		c.AbortWithStatus(204)
+		return
+	}
+
+	c.Next()
 }
 
 // IsOriginAllowed checks if an origin is allowed based on the configuration.
 // Parameters:
 //   - origin: origin header value to check.
 //   - config: CORS configuration values.
+//
 // Returns:
 //   - bool: true if the origin is allowed.
 func IsOriginAllowed(origin string, config CORSConfig) bool {
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSWithGroups(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(CORSWithGroups(CORSConfig{AllowAllOrigins: true}, []CORSGroup{{
+		PathPrefixes: []string{"/api/v1/admin"},
+		Config: CORSConfig{
+			AllowedOrigins: []string{"https://dashboard.test"},
+			AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+			MaxAge:         10 * time.Minute,
+		},
+	}}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/v1/search", ok)
+	r.GET("/api/v1/admin/quarantine", ok)
+	r.GET("/api/v1/administrators", ok)
+
+	tests := []struct {
+		method, path, origin string
+		wantOrigin, maxAge   string
+		wantStatus           int
+	}{
+		{http.MethodGet, "/api/v1/search", "https://embed.test", "*", "", http.StatusOK},
+		{http.MethodOptions, "/api/v1/search", "https://embed.test", "*", "", http.StatusNoContent},
+		{http.MethodGet, "/api/v1/admin/quarantine", "https://dashboard.test", "https://dashboard.test", "", http.StatusOK},
+		{http.MethodOptions, "/api/v1/admin/quarantine", "https://dashboard.test", "https://dashboard.test", "600", http.StatusNoContent},
+		{http.MethodGet, "/api/v1/admin/quarantine", "https://embed.test", "", "", http.StatusOK},
+		// Only whole path segments match the group prefix
+		{http.MethodGet, "/api/v1/administrators", "https://embed.test", "*", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		h := rec.Header()
+		if rec.Code != tt.wantStatus || h.Get("Access-Control-Allow-Origin") != tt.wantOrigin || h.Get("Access-Control-Max-Age") != tt.maxAge {
+			t.Errorf("%s %s from %s = %d, origin %q, max-age %q; want %d, %q, %q", tt.method, tt.path, tt.origin,
+				rec.Code, h.Get("Access-Control-Allow-Origin"), h.Get("Access-Control-Max-Age"), tt.wantStatus, tt.wantOrigin, tt.maxAge)
+		}
+		if tt.wantOrigin == "https://dashboard.test" && h.Get("Access-Control-Allow-Headers") != "Content-Type, X-API-Key" {
+			t.Errorf("%s %s Access-Control-Allow-Headers = %q, want the group headers", tt.method, tt.path, h.Get("Access-Control-Allow-Headers"))
+		}
+	}
+}
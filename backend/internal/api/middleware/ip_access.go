@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// IPAccessConfig holds CIDR-based access rules.
+type IPAccessConfig struct {
+	Allow []string // CIDRs or single IPs allowed (empty allows every address not denied)
+	Deny  []string // CIDRs or single IPs denied, checked before Allow
+}
+
+// IPAccess returns middleware that rejects requests from client IPs outside
+// the allowlist or inside the denylist with 403. Rules without a prefix length
+// match a single address. With no rules every request passes.
+// Parameters:
+//   - config: allow and deny rules.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+//   - error: non-nil if a rule is neither an IP nor a CIDR.
+func IPAccess(config IPAccessConfig) (gin.HandlerFunc, error) {
+	allow, err := parseIPNets(config.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parseIPNets(config.Deny)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		if len(allow) == 0 && len(deny) == 0 {
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		if !ipAllowed(net.ParseIP(clientIP), allow, deny) {
+			logger.CtxWarn(c.Request.Context(), "Request rejected by IP rules: path=%s, client_ip=%s",
+				c.Request.URL.Path, clientIP)
+			abortWithError(c, http.StatusForbidden, i18n.MsgAccessDenied)
+			return
+		}
+		c.Next()
+	}, nil
+}
+
+// ipAllowed reports whether ip passes the rules; unparsable IPs never do.
+func ipAllowed(ip net.IP, allow, deny []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	for _, n := range allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPNets parses CIDRs, turning single IPs into /32 or /128 networks.
+func parseIPNets(rules []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if !strings.Contains(rule, "/") {
+			ip := net.ParseIP(rule)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP rule %q", rule)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP rule %q: %w", rule, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPAccess(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	handler, err := IPAccess(IPAccessConfig{
+		Allow: []string{"10.8.0.0/16", "127.0.0.1", "fd00::/8"},
+		Deny:  []string{"10.8.9.0/24"},
+	})
+	if err != nil {
+		t.Fatalf("IPAccess() error = %v", err)
+	}
+	r := gin.New()
+	r.GET("/admin", handler, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for addr, want := range map[string]int{
+		"10.8.1.2:5000":  http.StatusOK,
+		"127.0.0.1:5000": http.StatusOK,
+		"[fd00::1]:5000": http.StatusOK,
+		"10.8.9.1:5000":  http.StatusForbidden,
+		"127.0.0.2:5000": http.StatusForbidden,
+		"192.0.2.1:5000": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("GET /admin from %s = %d, want %d", addr, rec.Code, want)
+		}
+	}
+}
+
+func TestIPAccessRejectsInvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []string{"10.8.0.0/33", "vpn", "10.8.0"} {
+		if _, err := IPAccess(IPAccessConfig{Allow: []string{rule}}); err == nil {
+			t.Errorf("IPAccess(%q) succeeded, want error", rule)
+		}
+	}
+}
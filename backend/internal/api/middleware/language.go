@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+)
+
+// Language returns middleware that negotiates the response language from the
+// Accept-Language header and stores it in the request context for i18n.
+// Parameters:
+//   - fallback: language used when the client accepts no supported language.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func Language(fallback string) gin.HandlerFunc {
+	if lang, ok := i18n.Normalize(fallback); ok {
+		fallback = lang
+	} else {
+		fallback = i18n.DefaultLanguage
+	}
+
+	return func(c *gin.Context) {
+		lang := i18n.Match(c.GetHeader("Accept-Language"), fallback)
+		c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), lang))
+		c.Next()
+	}
+}
+
+// abortWithError aborts the request with a translated error message and its key.
+func abortWithError(c *gin.Context, status int, key string, args ...any) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": i18n.Message(c.Request.Context(), key, args...),
+		"code":  key,
+	})
+}
@@ -17,7 +17,7 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := logger.QueryParams(c.Request.URL.RawQuery, "query")
 
 		// Generate request ID
 		requestID := uuid.New().String()
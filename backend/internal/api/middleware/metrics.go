@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/metrics"
+)
+
+// Metrics returns middleware that counts requests and records their latency
+// as http.requests and http.request.duration, tagged with the method, the
+// route pattern (not the raw path, to keep tag values bounded) and the status.
+// Parameters: none.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		tags := []string{
+			metrics.Tag("method", c.Request.Method),
+			metrics.Tag("route", route),
+			metrics.Tag("status", strconv.Itoa(c.Writer.Status())),
+		}
+		metrics.Count("http.requests", 1, tags...)
+		metrics.Timing("http.request.duration", time.Since(start), tags...)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/metrics"
+)
+
+// recordingSink keeps the counters it receives.
+type recordingSink struct {
+	metrics.Nop
+	mu     sync.Mutex
+	counts []string
+}
+
+func (s *recordingSink) Count(name string, value int64, tags ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts = append(s.counts, name+" "+strings.Join(tags, ","))
+}
+
+func TestMetricsTagsRoutePattern(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &recordingSink{}
+	metrics.SetSink(sink)
+	t.Cleanup(func() { metrics.SetSink(nil) })
+
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/memes/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/memes/1", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	want := []string{
+		"http.requests method:GET,route:/memes/:id,status:404",
+		"http.requests method:GET,route:unmatched,status:404",
+	}
+	if strings.Join(sink.counts, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("counts = %q, want %q", sink.counts, want)
+	}
+}
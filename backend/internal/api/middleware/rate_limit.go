@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// rateLimitSweepSize is the number of tracked clients past which expired
+// windows are dropped.
+const rateLimitSweepSize = 1024
+
+// RateLimitConfig bounds the requests of each client IP.
+type RateLimitConfig struct {
+	Limit  int           // Requests allowed per window (0 disables the limit)
+	Window time.Duration // Length of a window; counts reset when it ends
+}
+
+// RateLimit returns middleware that rejects requests past the per-IP limit
+// of the current fixed window with 429 and a Retry-After header.
+// Parameters:
+//   - config: requests allowed per client IP and window.
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	limiter := newIPRateLimiter(config)
+	return func(c *gin.Context) {
+		if config.Limit <= 0 || config.Window <= 0 {
+			c.Next()
+			return
+		}
+
+		clientIP := c.ClientIP()
+		retryAfter, ok := limiter.allow(clientIP, time.Now())
+		if !ok {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			logger.CtxWarn(c.Request.Context(), "Request rate limited: path=%s, client_ip=%s, retry_after=%ds",
+				c.Request.URL.Path, clientIP, seconds)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			abortWithError(c, http.StatusTooManyRequests, i18n.MsgRateLimited, seconds)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipRateLimiter counts requests per client IP in fixed windows.
+type ipRateLimiter struct {
+	mu      sync.Mutex
+	config  RateLimitConfig
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newIPRateLimiter(config RateLimitConfig) *ipRateLimiter {
+	return &ipRateLimiter{config: config, windows: make(map[string]*rateWindow)}
+}
+
+// allow counts a request of ip at now. When the limit is reached it returns
+// false and the time until the window ends.
+func (l *ipRateLimiter) allow(ip string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.windows[ip]
+	if !ok || now.Sub(window.start) >= l.config.Window {
+		if !ok && len(l.windows) >= rateLimitSweepSize {
+			l.sweep(now)
+		}
+		window = &rateWindow{start: now}
+		l.windows[ip] = window
+	}
+	if window.count >= l.config.Limit {
+		return window.start.Add(l.config.Window).Sub(now), false
+	}
+	window.count++
+	return 0, true
+}
+
+// sweep drops the windows that ended before now. Callers hold l.mu.
+func (l *ipRateLimiter) sweep(now time.Time) {
+	for ip, window := range l.windows {
+		if now.Sub(window.start) >= l.config.Window {
+			delete(l.windows, ip)
+		}
+	}
+}
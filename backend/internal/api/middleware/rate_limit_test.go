@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitPerClientIP(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/upload", RateLimit(RateLimitConfig{Limit: 2, Window: time.Hour}),
+		func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	for i, tc := range []struct {
+		addr string
+		want int
+	}{
+		{"192.0.2.1:5000", http.StatusCreated},
+		{"192.0.2.1:5001", http.StatusCreated},
+		{"192.0.2.1:5002", http.StatusTooManyRequests},
+		{"192.0.2.2:5000", http.StatusCreated},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		req.RemoteAddr = tc.addr
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("request %d from %s = %d, want %d", i, tc.addr, rec.Code, tc.want)
+		}
+		if tc.want == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "3600" {
+			t.Fatalf("Retry-After = %q, want 3600", rec.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestIPRateLimiterResetsWindows(t *testing.T) {
+	t.Parallel()
+
+	limiter := newIPRateLimiter(RateLimitConfig{Limit: 1, Window: time.Minute})
+	start := time.Now()
+	if _, ok := limiter.allow("192.0.2.1", start); !ok {
+		t.Fatalf("allow() first request = false, want true")
+	}
+	if retryAfter, ok := limiter.allow("192.0.2.1", start.Add(20*time.Second)); ok || retryAfter != 40*time.Second {
+		t.Fatalf("allow() second request = %v, %v, want false after 40s", retryAfter, ok)
+	}
+	if _, ok := limiter.allow("192.0.2.1", start.Add(time.Minute)); !ok {
+		t.Fatalf("allow() in the next window = false, want true")
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/i18n"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const defaultPanicReportTimeout = 10 * time.Second
+
+// PanicReport describes a panic recovered while serving a request.
+type PanicReport struct {
+	IncidentID string    `json:"incident_id"` // Returned to the client so support can find the report
+	RequestID  string    `json:"request_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	Time       time.Time `json:"time"`
+}
+
+// PanicReporter sends recovered panics to an error tracker.
+type PanicReporter interface {
+	// ReportPanic reports a panic. It runs outside the request and must not block for long.
+	ReportPanic(ctx context.Context, report *PanicReport) error
+}
+
+// Recovery returns middleware that recovers panics, logs them with their stack
+// through the request logger, passes them to reporter and answers with a JSON
+// 500 carrying an incident ID. Install it after LoggerMiddleware so the log
+// entry has the request fields.
+// Parameters:
+//   - reporter: error tracker (nil only logs).
+//
+// Returns:
+//   - gin.HandlerFunc: middleware handler.
+func Recovery(reporter PanicReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			ctx := c.Request.Context()
+
+			if isConnectionLost(recovered) {
+				// The client is gone; there is nobody to answer
+				logger.CtxWarn(ctx, "Connection lost while writing response: path=%s, error=%v", c.Request.URL.Path, recovered)
+				c.Abort()
+				return
+			}
+
+			report := &PanicReport{
+				IncidentID: uuid.New().String(),
+				RequestID:  logger.GetRequestID(ctx),
+				Method:     c.Request.Method,
+				Path:       c.Request.URL.Path,
+				Panic:      fmt.Sprint(recovered),
+				Stack:      string(debug.Stack()),
+				Time:       time.Now(),
+			}
+			logger.With(logger.Fields{
+				"incident_id": report.IncidentID,
+				"stack":       report.Stack,
+			}).Error(ctx, "Panic recovered: method=%s, path=%s, panic=%s", report.Method, report.Path, report.Panic)
+
+			if reporter != nil {
+				reportCtx := logger.DetachContext(ctx)
+				go func() {
+					if err := reporter.ReportPanic(reportCtx, report); err != nil {
+						logger.CtxWarn(reportCtx, "Failed to report panic: incident_id=%s, error=%v", report.IncidentID, err)
+					}
+				}()
+			}
+
+			if c.Writer.Written() {
+				// Part of the response is out; the status cannot change anymore
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":       i18n.Message(ctx, i18n.MsgInternalError, report.IncidentID),
+				"code":        i18n.MsgInternalError,
+				"incident_id": report.IncidentID,
+				"request_id":  report.RequestID,
+			})
+		}()
+		c.Next()
+	}
+}
+
+// isConnectionLost reports whether a panic comes from writing to a closed
+// connection or from http.ErrAbortHandler.
+func isConnectionLost(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(err, http.ErrAbortHandler) || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		var sysErr *os.SyscallError
+		if errors.As(opErr, &sysErr) {
+			msg := strings.ToLower(sysErr.Error())
+			return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+		}
+	}
+	return false
+}
+
+// WebhookPanicReporterConfig configures a panic webhook.
+type WebhookPanicReporterConfig struct {
+	URL     string        // Endpoint receiving the PanicReport as JSON
+	Token   string        // Optional bearer token
+	Timeout time.Duration // Per-request timeout (0 uses 10s)
+}
+
+// WebhookPanicReporter posts panic reports as JSON, e.g. to an alerting
+// endpoint or an error tracker ingestion proxy.
+type WebhookPanicReporter struct {
+	client *resty.Client
+	url    string
+}
+
+// NewWebhookPanicReporter creates a webhook panic reporter.
+// Parameters:
+//   - cfg: webhook URL, token and timeout.
+//
+// Returns:
+//   - *WebhookPanicReporter: initialized reporter.
+//   - error: non-nil if no URL is configured.
+func NewWebhookPanicReporter(cfg *WebhookPanicReporterConfig) (*WebhookPanicReporter, error) {
+	if cfg == nil || strings.TrimSpace(cfg.URL) == "" {
+		return nil, fmt.Errorf("panic webhook url is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultPanicReportTimeout
+	}
+
+	client := resty.New()
+	client.SetTimeout(timeout)
+	client.SetHeader("Content-Type", "application/json")
+	if cfg.Token != "" {
+		client.SetHeader("Authorization", "Bearer "+cfg.Token)
+	}
+	return &WebhookPanicReporter{client: client, url: strings.TrimSpace(cfg.URL)}, nil
+}
+
+// ReportPanic posts report to the webhook.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - report: recovered panic.
+//
+// Returns:
+//   - error: non-nil if the request fails or the webhook answers with an error.
+func (r *WebhookPanicReporter) ReportPanic(ctx context.Context, report *PanicReport) error {
+	resp, err := r.client.R().SetContext(ctx).SetBody(report).Post(r.url)
+	if err != nil {
+		return fmt.Errorf("failed to call panic webhook: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("panic webhook error: status=%d, body=%s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type panicReporterFunc func(ctx context.Context, report *PanicReport) error
+
+func (f panicReporterFunc) ReportPanic(ctx context.Context, report *PanicReport) error {
+	return f(ctx, report)
+}
+
+func TestRecovery(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	reports := make(chan *PanicReport, 1)
+	r := gin.New()
+	r.Use(Recovery(panicReporterFunc(func(ctx context.Context, report *PanicReport) error {
+		reports <- report
+		return nil
+	})))
+	r.GET("/boom", func(c *gin.Context) { panic("nil map write") })
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	if rec.Code != http.StatusInternalServerError || body["incident_id"] == "" || body["code"] != "error.internal" {
+		t.Fatalf("response = %d %v, want 500 with an incident ID", rec.Code, body)
+	}
+	if !strings.Contains(body["error"], body["incident_id"]) {
+		t.Fatalf("error = %q, want it to mention incident %s", body["error"], body["incident_id"])
+	}
+
+	select {
+	case report := <-reports:
+		if report.IncidentID != body["incident_id"] || report.Panic != "nil map write" || report.Path != "/boom" {
+			t.Fatalf("report = %+v, want the panic of /boom with the returned incident ID", report)
+		}
+		if !strings.Contains(report.Stack, "recovery_test.go") {
+			t.Fatalf("report stack does not include the panicking handler:\n%s", report.Stack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic was not reported")
+	}
+}
+
+func TestRecoveryAfterPartialResponse(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Recovery(nil))
+	r.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("stream broke")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Fatalf("response = %d %q, want the partial response untouched", rec.Code, rec.Body.String())
+	}
+}
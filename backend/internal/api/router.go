@@ -1,6 +1,8 @@
 package api
 
 import (
+	"net/http/pprof"
+
 	"github.com/gin-gonic/gin"
 	"github.com/timmy/emomo/internal/api/handler"
 	"github.com/timmy/emomo/internal/api/middleware"
@@ -14,7 +16,10 @@ import (
 // Parameters:
 //   - searchService: search service used by API handlers.
 //   - ingestService: ingest service used by admin handlers.
+//   - usageService: API key usage service for quotas and usage endpoints.
 //   - sources: map of source adapters keyed by name.
+//   - readiness: readiness flag served by /health/ready (nil is always ready).
+//   - health: dependency checks run by /health/ready (nil checks none).
 //   - cfg: application configuration for server settings.
 //   - log: logger instance for middleware.
 //
@@ -23,7 +28,10 @@ import (
 func SetupRouter(
 	searchService *service.SearchService,
 	ingestService *service.IngestService,
+	usageService *service.UsageService,
 	sources map[string]source.Source,
+	readiness *handler.Readiness,
+	health *service.HealthChecker,
 	cfg *config.Config,
 	log *logger.Logger,
 ) *gin.Engine {
@@ -38,48 +46,262 @@ func SetupRouter(
 	}
 
 	r := gin.New()
+	if len(cfg.Server.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			log.WithError(err).Fatal("Invalid server.trusted_proxies")
+		}
+	}
 
 	// Add middleware
-	r.Use(gin.Recovery())
 	r.Use(middleware.LoggerMiddleware(log))
-	r.Use(middleware.CORS(middleware.CORSConfig{
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Language(cfg.Server.DefaultLanguage))
+	r.Use(middleware.Recovery(buildPanicReporter(cfg, log)))
+	r.Use(middleware.CORSWithGroups(middleware.CORSConfig{
 		AllowedOrigins:  cfg.Server.CORS.AllowedOrigins,
 		AllowAllOrigins: cfg.Server.CORS.AllowAllOrigins,
-	}))
+		AllowedHeaders:  cfg.Server.CORS.AllowedHeaders,
+		MaxAge:          cfg.Server.CORS.MaxAge,
+	}, corsGroups(cfg.Server.CORS.Groups)))
 
 	// Create handlers
-	healthHandler := handler.NewHealthHandler()
+	healthHandler := handler.NewHealthHandler(readiness, health)
 	searchHandler := handler.NewSearchHandler(searchService)
 	memeHandler := handler.NewMemeHandler(searchService)
+	usageHandler := handler.NewUsageHandler(usageService)
+	// Profiles expose memory contents, so pprof needs keys with a role
+	pprofEnabled := cfg.Server.Pprof && usageService.AdminAuthRequired()
+	if cfg.Server.Pprof && !pprofEnabled {
+		log.Warn("server.pprof needs api_keys.admin_auth; profiling endpoints not mounted")
+	}
+	// Without roles anyone could mint keys, including admin-scoped ones
+	keysEnabled := usageService.AdminAuthRequired()
+	if !keysEnabled {
+		log.Warn("API key management needs api_keys.admin_auth; /api/v1/admin/keys not mounted")
+	}
+	debugHandler := handler.NewDebugHandler(enabledFeatures(cfg, pprofEnabled))
 	adminHandler := handler.NewAdminHandler(ingestService, sources, log)
+	adminHandler.SetJobLimits(handler.JobLimits{
+		MaxConcurrent: cfg.Ingest.Jobs.MaxConcurrent,
+		PerSource:     cfg.Ingest.Jobs.PerSource,
+		SourceLimits:  cfg.Ingest.Jobs.SourceLimits,
+		QueueSize:     cfg.Ingest.Jobs.QueueSize,
+	})
+
+	adminHandler.SetWebhook(handler.WebhookConfig{
+		Secret:  cfg.Ingest.Webhook.Secret,
+		Limit:   cfg.Ingest.Webhook.Limit,
+		MaxSkew: cfg.Ingest.Webhook.MaxSkew,
+		Review:  cfg.Ingest.Webhook.Review,
+	})
+	adminHandler.SetStaging(handler.StagingConfig{MaxBytes: cfg.Ingest.Staging.MaxBytes})
+
+	adminAccess, err := middleware.IPAccess(middleware.IPAccessConfig{
+		Allow: cfg.Server.AdminAccess.Allow,
+		Deny:  cfg.Server.AdminAccess.Deny,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid server.admin_access rules")
+	}
+	apiKey := middleware.APIKey(usageService)
+	searchAuth := middleware.APIKeyAuth(usageService, service.APIKeyScopeSearch)
+	ingestAuth := middleware.APIKeyAuth(usageService, service.APIKeyScopeIngest)
+	adminAuth := middleware.APIKeyAuth(usageService, service.APIKeyScopeAdmin)
+	cacheConfig := middleware.CacheConfig{
+		Enabled: cfg.CDN.Enabled,
+		MaxAge:  cfg.CDN.MaxAge,
+		SMaxAge: cfg.CDN.SMaxAge,
+	}
 
 	// Admin page (root)
-	r.GET("/", adminHandler.AdminPage)
+	r.GET("/", adminAccess, adminHandler.AdminPage)
 
-	// Health check
+	// Health checks; /health and /readyz predate /health/live and /health/ready
 	r.GET("/health", healthHandler.Health)
+	r.GET("/health/live", healthHandler.Health)
+	r.GET("/health/ready", healthHandler.Ready)
+	r.GET("/readyz", healthHandler.Ready)
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
+	v1.Use(apiKey)
 	{
 		// Search - register stream route first to avoid matching /search first
-		v1.POST("/search/stream", searchHandler.TextSearchStream)
-		v1.POST("/search", searchHandler.TextSearch)
+		v1.GET("/search/stream", searchAuth, searchHandler.TextSearchStream)
+		v1.POST("/search/stream", searchAuth, searchHandler.TextSearchStream)
+		v1.POST("/search/image", searchAuth, searchHandler.ImageSearch)
+		v1.POST("/search", searchAuth, searchHandler.TextSearch)
+		v1.GET("/collections", searchAuth, searchHandler.GetCollections)
 
 		// Categories
-		v1.GET("/categories", searchHandler.GetCategories)
+		v1.GET("/categories", searchAuth, middleware.CacheControl(cacheConfig, categoriesSurrogateKeys), searchHandler.GetCategories)
 
 		// Memes
-		v1.GET("/memes", memeHandler.ListMemes)
-		v1.GET("/memes/:id", memeHandler.GetMeme)
+		v1.GET("/memes", searchAuth, middleware.CacheControl(cacheConfig, memeListSurrogateKeys), memeHandler.ListMemes)
+		v1.GET("/memes/random", searchAuth, memeHandler.RandomMemes)
+		v1.GET("/memes/daily", searchAuth, middleware.CacheControl(cacheConfig, memeListSurrogateKeys), memeHandler.DailyMeme)
+		v1.GET("/memes/:id", searchAuth, middleware.CacheControl(cacheConfig, memeSurrogateKeys), memeHandler.GetMeme)
+		v1.GET("/memes/:id/download", searchAuth, memeHandler.DownloadMeme)
+		v1.POST("/memes/:id/click", searchAuth, middleware.RateLimit(middleware.RateLimitConfig{
+			Limit:  cfg.Search.Popularity.ClickLimit,
+			Window: cfg.Search.Popularity.ClickWindow,
+		}), memeHandler.RecordClick)
+		v1.GET("/memes/:id/still", searchAuth, middleware.CacheControl(cacheConfig, memeSurrogateKeys), memeHandler.GetStill)
+		v1.POST("/memes/download", searchAuth, memeHandler.DownloadBundle)
+		v1.POST("/memes/batch-get", searchAuth, memeHandler.BatchGetMemes)
+		if upload := cfg.Ingest.Upload; upload.Enabled {
+			uploadHandler := handler.NewUploadHandler(ingestService, upload.MaxBytes)
+			uploadLimit := middleware.RateLimit(middleware.RateLimitConfig{
+				Limit:  upload.RateLimit,
+				Window: upload.RateWindow,
+			})
+			v1.POST("/memes/upload", searchAuth, uploadLimit, uploadHandler.UploadMeme)
+		}
 
 		// Stats
-		v1.GET("/stats", searchHandler.GetStats)
+		v1.GET("/stats", searchAuth, searchHandler.GetStats)
+		v1.GET("/stats/history", searchAuth, searchHandler.GetStatsHistory)
 
-		// Ingest (admin)
-		v1.POST("/ingest", adminHandler.TriggerIngest)
-		v1.GET("/ingest/status", adminHandler.GetIngestStatus)
+		// Ingest
+		v1.POST("/ingest", ingestAuth, adminHandler.TriggerIngest)
+		v1.GET("/ingest/status", ingestAuth, adminHandler.GetIngestStatus)
+		v1.GET("/ingest/status/stream", ingestAuth, adminHandler.StreamIngestStatus)
+		v1.GET("/ingest/jobs/:id", ingestAuth, adminHandler.GetIngestJob)
+	}
+
+	// The crawler signs its webhook calls instead of sending an API key
+	if cfg.Ingest.Webhook.Secret != "" {
+		r.POST("/api/v1/ingest/webhook/:source", adminHandler.ManifestWebhook)
+	}
+
+	// Admin routes check client IPs before API keys and their roles or scopes
+	admin := r.Group("/api/v1/admin", adminAccess, apiKey, adminAuth)
+	{
+		admin.GET("/sources/:id/stats", adminHandler.GetSourceStats)
+		admin.DELETE("/sources/:id", adminHandler.DeleteSource)
+		admin.POST("/staging/upload", adminHandler.UploadStaging)
+		admin.POST("/exports/vectors", adminHandler.ExportVectors)
+		admin.GET("/taxonomy", adminHandler.ExportTaxonomy)
+		admin.POST("/taxonomy/import", adminHandler.ImportTaxonomy)
+		admin.GET("/quarantine", adminHandler.ListQuarantined)
+		admin.GET("/descriptions/review", adminHandler.ListDescriptionsForReview)
+		admin.GET("/categories/suggestions", adminHandler.ListCategorySuggestions)
+		admin.POST("/categories/suggestions/:id/accept", adminHandler.AcceptCategorySuggestion)
+		admin.POST("/categories/suggestions/:id/reject", adminHandler.RejectCategorySuggestion)
+		admin.PUT("/categories/:name/cover", adminHandler.SetCategoryCover)
+		admin.DELETE("/categories/:name/cover", adminHandler.ClearCategoryCover)
+		admin.DELETE("/memes/:id", adminHandler.DeleteMeme)
+		admin.GET("/moderation/queue", adminHandler.ListModerationQueue)
+		admin.POST("/moderation/:id/approve", adminHandler.ApproveMeme)
+		admin.POST("/moderation/:id/reject", adminHandler.RejectMeme)
+		admin.POST("/memes/:id/redescribe", adminHandler.RedescribeMeme)
+		admin.GET("/ingest/jobs", adminHandler.ListIngestJobs)
+		admin.GET("/origins/review", adminHandler.ListDeadOrigins)
+		admin.GET("/ingest/jobs/:id/report", adminHandler.GetIngestReport)
+		if keysEnabled {
+			admin.GET("/keys", usageHandler.ListKeys)
+			admin.POST("/keys", usageHandler.CreateKey)
+			admin.DELETE("/keys/:id", usageHandler.RevokeKey)
+			admin.GET("/keys/:id/usage", usageHandler.GetKeyUsage)
+		}
+		admin.POST("/search/debug", searchHandler.DebugSearch)
+		admin.GET("/search/compare", searchHandler.CompareSearch)
+		admin.GET("/debug/info", debugHandler.Info)
+		if pprofEnabled {
+			registerPprof(admin.Group("/debug/pprof"))
+		}
 	}
 
 	return r
 }
+
+// registerPprof mounts the net/http/pprof handlers under group.
+func registerPprof(group *gin.RouterGroup) {
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	// heap, goroutine, allocs, block, mutex, threadcreate
+	group.GET("/:profile", func(c *gin.Context) {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// enabledFeatures reports which optional features cfg turns on, for
+// GET /api/v1/admin/debug/info.
+func enabledFeatures(cfg *config.Config, pprofEnabled bool) map[string]bool {
+	search := cfg.Search
+	return map[string]bool{
+		"query_expansion":     search.QueryExpansion.Enabled,
+		"rerank":              search.Rerank.Enabled,
+		"popularity_ranking":  search.Popularity.Enabled && search.Popularity.Weight > 0,
+		"image_search":        search.ImageSearch.Enabled,
+		"search_result_cache": search.Cache.ResultTTL > 0 && (search.Cache.Results > 0 || cfg.Redis.URL != ""),
+		"shadow_search":       search.Shadow.Target != "" && search.Shadow.Percent > 0,
+		"stats_history":       search.StatsHistory.Enabled,
+		"upload":              cfg.Ingest.Upload.Enabled,
+		"ingest_webhook":      cfg.Ingest.Webhook.Secret != "",
+		"cdn":                 cfg.CDN.Enabled,
+		"redis":               cfg.Redis.URL != "",
+		"metrics":             cfg.Metrics.Sink != "" && cfg.Metrics.Sink != "none",
+		"api_key_required":    cfg.APIKeys.Require,
+		"admin_auth":          cfg.APIKeys.AdminAuthEnabled(),
+		"warmup":              cfg.Server.Warmup.Enabled,
+		"pprof":               pprofEnabled,
+	}
+}
+
+// categoriesSurrogateKeys tags the category list.
+func categoriesSurrogateKeys(*gin.Context) []string {
+	return []string{service.SurrogateKeyCategories}
+}
+
+// memeListSurrogateKeys tags a meme list, and its category when filtered.
+func memeListSurrogateKeys(c *gin.Context) []string {
+	keys := []string{service.SurrogateKeyMemes}
+	if category := c.Query("category"); category != "" {
+		keys = append(keys, service.CategorySurrogateKey(category))
+	}
+	return keys
+}
+
+// memeSurrogateKeys tags a meme detail response.
+func memeSurrogateKeys(c *gin.Context) []string {
+	return []string{service.SurrogateKeyMemes, service.MemeSurrogateKey(c.Param("id"))}
+}
+
+// corsGroups converts the configured CORS route groups to middleware policies.
+func corsGroups(groups []config.CORSGroupConfig) []middleware.CORSGroup {
+	out := make([]middleware.CORSGroup, 0, len(groups))
+	for _, group := range groups {
+		out = append(out, middleware.CORSGroup{
+			PathPrefixes: group.PathPrefixes,
+			Config: middleware.CORSConfig{
+				AllowedOrigins:  group.AllowedOrigins,
+				AllowAllOrigins: group.AllowAllOrigins,
+				AllowedHeaders:  group.AllowedHeaders,
+				MaxAge:          group.MaxAge,
+			},
+		})
+	}
+	return out
+}
+
+// buildPanicReporter returns the panic webhook of server.error_reporting, or nil when unset.
+func buildPanicReporter(cfg *config.Config, log *logger.Logger) middleware.PanicReporter {
+	reporting := cfg.Server.ErrorReporting
+	if reporting.WebhookURL == "" {
+		return nil
+	}
+	reporter, err := middleware.NewWebhookPanicReporter(&middleware.WebhookPanicReporterConfig{
+		URL:     reporting.WebhookURL,
+		Token:   reporting.Token,
+		Timeout: reporting.Timeout,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid server.error_reporting")
+	}
+	return reporter
+}
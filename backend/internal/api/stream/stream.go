@@ -0,0 +1,137 @@
+// Package stream writes server-sent events (SSE) from Gin handlers.
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHeartbeat is the interval of keep-alive comments, short enough for
+// proxies that close idle connections after 30-60s.
+const DefaultHeartbeat = 15 * time.Second
+
+// ErrClientGone is returned when the client disconnected or the stream was closed.
+var ErrClientGone = errors.New("stream client disconnected")
+
+// Writer writes SSE events to a Gin response. It is safe for concurrent use,
+// so the heartbeat can run while a handler sends events.
+type Writer struct {
+	c      *gin.Context
+	mu     sync.Mutex
+	closed bool
+	stop   chan struct{}
+}
+
+// New sets the SSE headers, flushes them and starts the heartbeat.
+// Parameters:
+//   - c: Gin request context.
+//   - heartbeat: interval of keep-alive comments (0 uses DefaultHeartbeat, negative disables).
+//
+// Returns:
+//   - *Writer: event writer; callers must Close it when the handler returns.
+func New(c *gin.Context, heartbeat time.Duration) *Writer {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	w := &Writer{c: c, stop: make(chan struct{})}
+	if heartbeat == 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	if heartbeat > 0 {
+		go w.heartbeat(heartbeat)
+	}
+	return w
+}
+
+// Done returns a channel closed when the client disconnects.
+// Parameters: none.
+// Returns:
+//   - <-chan struct{}: closed with the request context.
+func (w *Writer) Done() <-chan struct{} {
+	return w.c.Request.Context().Done()
+}
+
+// Event writes one event with JSON-encoded data and flushes it.
+// Parameters:
+//   - name: event name (empty sends an unnamed "message" event).
+//   - data: value encoded as JSON.
+//
+// Returns:
+//   - error: ErrClientGone once the client disconnected, or the encoding error.
+func (w *Writer) Event(name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+
+	var b strings.Builder
+	if name != "" {
+		fmt.Fprintf(&b, "event: %s\n", name)
+	}
+	fmt.Fprintf(&b, "data: %s\n\n", payload)
+	return w.write(b.String())
+}
+
+// Comment writes an SSE comment line, which clients ignore.
+// Parameters:
+//   - text: comment text without newlines.
+//
+// Returns:
+//   - error: ErrClientGone once the client disconnected.
+func (w *Writer) Comment(text string) error {
+	return w.write(": " + text + "\n\n")
+}
+
+// Close stops the heartbeat. Later writes return ErrClientGone.
+// Parameters: none.
+// Returns: none.
+func (w *Writer) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+	}
+}
+
+func (w *Writer) write(frame string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || w.c.Request.Context().Err() != nil {
+		return ErrClientGone
+	}
+	if _, err := w.c.Writer.WriteString(frame); err != nil {
+		return ErrClientGone
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+func (w *Writer) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.Done():
+			return
+		case <-ticker.C:
+			if err := w.Comment("ping"); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriterEvent(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodGet, "/stream", nil).WithContext(ctx)
+
+	sse := New(c, -1)
+	defer sse.Close()
+	if err := sse.Event("progress", map[string]string{"stage": "searching"}); err != nil {
+		t.Fatalf("Event() error = %v", err)
+	}
+	if err := sse.Comment("ping"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+
+	want := "event: progress\ndata: {\"stage\":\"searching\"}\n\n: ping\n\n"
+	if got := recorder.Body.String(); got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	cancel()
+	if err := sse.Event("progress", nil); !errors.Is(err, ErrClientGone) {
+		t.Fatalf("Event() after disconnect error = %v, want ErrClientGone", err)
+	}
+}
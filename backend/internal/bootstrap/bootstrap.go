@@ -0,0 +1,328 @@
+// Package bootstrap builds the services the commands share from their
+// configuration, so the API server, ingest, discover and reembed wire them
+// up identically. Invalid configuration is fatal: a command that silently ran
+// without a configured component would behave differently from the others.
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/timmy/emomo/internal/cache"
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/httpfixture"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/metrics"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/service"
+	"github.com/timmy/emomo/internal/source"
+)
+
+// ProviderTransport returns the fixture transport of the LLM, VLM and
+// embedding clients, or nil when fixtures are off.
+// Parameters:
+//   - cfg: configuration with the fixtures settings.
+//   - log: logger for the fixtures notice and fatal errors.
+//
+// Returns:
+//   - http.RoundTripper: recording or replaying transport, or nil.
+func ProviderTransport(cfg *config.Config, log *logger.Logger) http.RoundTripper {
+	transport, err := httpfixture.New(httpfixture.Config{Mode: cfg.Fixtures.Mode, Dir: cfg.Fixtures.Dir})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid fixtures config")
+	}
+	if transport != nil {
+		log.WithFields(logger.Fields{
+			"mode": cfg.Fixtures.Mode,
+			"dir":  cfg.Fixtures.Dir,
+		}).Warn("Provider API fixtures enabled")
+	}
+	return transport
+}
+
+// SparseEncoder returns the learned sparse encoder, or nil when no endpoint
+// is configured. An invalid configuration is fatal rather than disabling the
+// encoder: ingest and reembed would otherwise write points without the
+// learned sparse vectors that the other commands produce.
+// Parameters:
+//   - cfg: sparse encoder settings.
+//
+// Returns:
+//   - service.SparseEncoder: SPLADE encoder, or nil.
+func SparseEncoder(cfg config.SparseEncoderConfig) service.SparseEncoder {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	encoder, err := service.NewSpladeEncoder(&service.SpladeEncoderConfig{
+		Endpoint: cfg.Endpoint,
+		APIKey:   cfg.APIKey,
+		Timeout:  cfg.Timeout,
+	})
+	if err != nil {
+		logger.Fatal("Invalid sparse encoder config: error=%v", err)
+	}
+	return encoder
+}
+
+// Prompts returns the built-in prompts with the overrides of prompts.dir
+// applied.
+// Parameters:
+//   - cfg: configuration with the prompts directory.
+//   - log: logger for the loaded overrides and fatal errors.
+//
+// Returns:
+//   - prompts.Provider: prompt set of the LLM and VLM clients.
+func Prompts(cfg *config.Config, log *logger.Logger) prompts.Provider {
+	set, overridden, err := prompts.LoadDir(prompts.Default(), cfg.Prompts.Dir)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load prompt overrides")
+	}
+	if len(overridden) > 0 {
+		log.WithFields(logger.Fields{
+			"dir":     cfg.Prompts.Dir,
+			"prompts": overridden,
+		}).Info("Prompt overrides loaded")
+	}
+	return set
+}
+
+// DescriptionCleaner returns the description cleaner, or nil when cleanup
+// is disabled. Ingest and reembed share it, so reembed produces the same
+// embedding text as ingest.
+// Parameters:
+//   - cfg: description cleanup settings.
+//
+// Returns:
+//   - *service.DescriptionCleaner: cleaner, or nil.
+func DescriptionCleaner(cfg config.DescriptionCleanup) *service.DescriptionCleaner {
+	if !cfg.Enabled {
+		return nil
+	}
+	cleaner, err := service.NewDescriptionCleaner(service.DescriptionCleanerConfig{
+		Phrases:  cfg.Phrases,
+		Patterns: cfg.Patterns,
+	})
+	if err != nil {
+		logger.Fatal("Invalid description cleanup config: error=%v", err)
+	}
+	return cleaner
+}
+
+// MediaConverter returns the ffmpeg converter, or nil when it is disabled.
+// An ffmpeg binary that cannot be run only disables conversion with a
+// warning: HEIC, AVIF and clips are then skipped, other images still ingest.
+// Parameters:
+//   - cfg: media conversion settings.
+//
+// Returns:
+//   - service.MediaConverter: ffmpeg converter, or nil.
+func MediaConverter(cfg config.MediaConfig) service.MediaConverter {
+	if cfg.FFmpegPath == "" {
+		return nil
+	}
+	converter, err := service.NewFFmpegConverter(service.FFmpegConfig{
+		Path:            cfg.FFmpegPath,
+		MaxClipDuration: cfg.MaxClipDuration,
+		Timeout:         cfg.Timeout,
+	})
+	if err != nil {
+		logger.Warn("Media conversion disabled, HEIC/AVIF and clips will be skipped: error=%v", err)
+		return nil
+	}
+	return converter
+}
+
+// SceneTagger returns the scene tagger, falling back to the VLM credentials
+// when scene tagging has none of its own.
+// Parameters:
+//   - cfg: configuration with the scene tagging and VLM settings.
+//   - promptSet: prompts of the tagging model.
+//   - transport: fixture transport of the model client, or nil.
+//
+// Returns:
+//   - *service.SceneTagger: scene tagger.
+func SceneTagger(cfg *config.Config, promptSet prompts.Provider, transport http.RoundTripper) *service.SceneTagger {
+	apiKey := cfg.Ingest.SceneTags.APIKey
+	if apiKey == "" {
+		apiKey = cfg.VLM.APIKey
+	}
+	baseURL := cfg.Ingest.SceneTags.BaseURL
+	if baseURL == "" {
+		baseURL = cfg.VLM.BaseURL
+	}
+	return service.NewSceneTagger(&service.SceneTaggerConfig{
+		Enabled:   cfg.Ingest.SceneTags.Enabled,
+		Model:     cfg.Ingest.SceneTags.Model,
+		APIKey:    apiKey,
+		BaseURL:   baseURL,
+		MaxTokens: cfg.Ingest.SceneTags.MaxTokens,
+		Prompts:   promptSet,
+		Transport: transport,
+	})
+}
+
+// SkipRules converts a source's skip rule config into service rules.
+// Parameters:
+//   - cfg: skip rules of a source.
+//
+// Returns:
+//   - service.SkipRules: rules evaluated before items are read.
+func SkipRules(cfg config.SkipRulesConfig) service.SkipRules {
+	return service.SkipRules{
+		MinFileSize:       cfg.MinFileSize,
+		SkipFormats:       cfg.SkipFormats,
+		IncludeCategories: cfg.IncludeCategories,
+		ExcludeCategories: cfg.ExcludeCategories,
+	}
+}
+
+// Priorities converts configured category priorities into source rules.
+// Parameters:
+//   - cfg: category priorities of a source.
+//
+// Returns:
+//   - []source.CategoryPriority: priority rules of the source adapter.
+func Priorities(cfg []config.CategoryPriority) []source.CategoryPriority {
+	priorities := make([]source.CategoryPriority, 0, len(cfg))
+	for _, p := range cfg {
+		priorities = append(priorities, source.CategoryPriority{Pattern: p.Pattern, Priority: p.Priority})
+	}
+	return priorities
+}
+
+// OriginChecker returns the checker used for URL-based source items,
+// sending its requests through the configured download proxy and headers.
+// Parameters:
+//   - cfg: configuration with the origin check and download settings.
+//   - log: logger for fatal errors.
+//
+// Returns:
+//   - *service.OriginChecker: origin checker.
+func OriginChecker(cfg *config.Config, log *logger.Logger) *service.OriginChecker {
+	transport, err := source.NewDownloadTransport(source.DownloadConfig{
+		Proxy:     cfg.Ingest.Download.Proxy,
+		UserAgent: cfg.Ingest.Download.UserAgent,
+		Referer:   cfg.Ingest.Download.Referer,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid ingest.download config")
+	}
+	return service.NewOriginChecker(&service.OriginCheckConfig{
+		Timeout:   cfg.Ingest.Origins.Timeout,
+		MaxBytes:  cfg.Ingest.Origins.MaxBytes,
+		UserAgent: cfg.Ingest.Origins.UserAgent,
+		Transport: transport,
+	})
+}
+
+// CachePurger returns the purge webhook of cdn.purge_url, or nil when unset.
+// Parameters:
+//   - cfg: configuration with the CDN settings.
+//   - log: logger for fatal errors.
+//
+// Returns:
+//   - service.CachePurger: webhook purger, or nil.
+func CachePurger(cfg *config.Config, log *logger.Logger) service.CachePurger {
+	if cfg.CDN.PurgeURL == "" {
+		return nil
+	}
+	purger, err := service.NewWebhookPurger(&service.WebhookPurgerConfig{
+		URL:     cfg.CDN.PurgeURL,
+		Token:   cfg.CDN.PurgeToken,
+		Timeout: cfg.CDN.PurgeTimeout,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize cache purger")
+	}
+	return purger
+}
+
+// Redis connects to redis.url, or returns nil when it is unset. A
+// configured but unreachable server is fatal, since replicas would otherwise
+// silently stop sharing caches and locks.
+// Parameters:
+//   - ctx: context of the connection check.
+//   - cfg: configuration with the Redis settings.
+//   - log: logger for fatal errors.
+//
+// Returns:
+//   - *cache.Redis: connected client, or nil.
+func Redis(ctx context.Context, cfg *config.Config, log *logger.Logger) *cache.Redis {
+	if cfg.Redis.URL == "" {
+		return nil
+	}
+	redis, err := cache.NewRedis(cache.RedisConfig{
+		URL:       cfg.Redis.URL,
+		KeyPrefix: cfg.Redis.KeyPrefix,
+		PoolSize:  cfg.Redis.PoolSize,
+		Timeout:   cfg.Redis.Timeout,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid redis config")
+	}
+	if err := redis.Ping(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to connect to redis")
+	}
+	return redis
+}
+
+// Metrics installs the metrics.sink exporter for the package-level metrics
+// functions, tagged with the component.
+// Parameters:
+//   - cfg: configuration with the metrics settings.
+//   - component: value of the component tag, e.g. api or ingest.
+//   - log: logger for the sink notice and fatal errors.
+//
+// Returns:
+//   - func(): sends buffered metrics; call it on shutdown.
+func Metrics(cfg *config.Config, component string, log *logger.Logger) func() {
+	sink, err := metrics.New(metrics.Config{
+		Sink:          cfg.Metrics.Sink,
+		Address:       cfg.Metrics.Address,
+		Prefix:        cfg.Metrics.Prefix,
+		Tags:          append(append([]string(nil), cfg.Metrics.Tags...), metrics.Tag("component", component)),
+		FlushInterval: cfg.Metrics.FlushInterval,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Invalid metrics config")
+	}
+	metrics.SetSink(sink)
+	statsd, ok := sink.(*metrics.StatsD)
+	if !ok {
+		return func() {}
+	}
+	log.WithFields(logger.Fields{
+		"sink":    cfg.Metrics.Sink,
+		"address": cfg.Metrics.Address,
+	}).Info("Metrics enabled")
+	return func() { statsd.Close() }
+}
+
+// EmbeddingTextWeights converts the configured caption segment weights.
+// Parameters:
+//   - cfg: caption segment weights.
+//
+// Returns:
+//   - service.EmbeddingTextWeights: weights of the caption embedding text.
+func EmbeddingTextWeights(cfg config.EmbeddingTextWeights) service.EmbeddingTextWeights {
+	return service.EmbeddingTextWeights{
+		OCR:         cfg.OCRWeight,
+		Description: cfg.DescriptionWeight,
+		Tags:        cfg.TagsWeight,
+	}
+}
+
+// NearDuplicatePolicy parses the configured near duplicate policy; an
+// unknown policy is fatal.
+// Parameters:
+//   - value: ingest.near_duplicates setting.
+//
+// Returns:
+//   - service.NearDuplicatePolicy: parsed policy.
+func NearDuplicatePolicy(value string) service.NearDuplicatePolicy {
+	policy, err := service.ParseNearDuplicatePolicy(value)
+	if err != nil {
+		logger.Fatal("Invalid ingest.near_duplicates config: error=%v", err)
+	}
+	return policy
+}
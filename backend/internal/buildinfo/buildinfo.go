@@ -0,0 +1,83 @@
+// Package buildinfo reports the version, commit and build date of the
+// running binary. Release builds set them with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/timmy/emomo/internal/buildinfo.Version=v1.2.0 \
+//	  -X github.com/timmy/emomo/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/timmy/emomo/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unset values fall back to the VCS stamp Go embeds in binaries built from a
+// git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Set with -ldflags -X; see the package comment.
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// startTime approximates the process start for the uptime.
+var startTime = time.Now()
+
+// Info describes the running binary.
+type Info struct {
+	Version   string    `json:"version"`          // Release version, or "dev"
+	Commit    string    `json:"commit,omitempty"` // Git commit the binary was built from
+	Modified  bool      `json:"modified"`         // Built from a checkout with uncommitted changes
+	BuildDate string    `json:"build_date,omitempty"`
+	GoVersion string    `json:"go_version"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Get returns the build information of the running binary.
+// Parameters: none.
+//
+// Returns:
+//   - Info: version, commit, build date, Go version and start time.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: Date,
+		GoVersion: runtime.Version(),
+		StartedAt: startTime,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// Uptime returns how long the process has been running.
+// Parameters: none.
+//
+// Returns:
+//   - time.Duration: time since the process started.
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
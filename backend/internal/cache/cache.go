@@ -0,0 +1,69 @@
+// Package cache holds state shared by API replicas: a Redis store for the
+// search result and query expansion caches and locks that keep two processes
+// from running the same job. Without Redis, callers keep their caches in
+// process and use LocalLocker.
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLocked is returned by Locker.Lock when another holder has the lock.
+var ErrLocked = errors.New("lock is held by another process")
+
+// Store is a key-value store with expiring entries.
+type Store interface {
+	// Get returns the value of key; ok is false when it is missing or expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl (0 keeps it until evicted).
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys; missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Locker hands out named locks.
+type Locker interface {
+	// Lock takes the lock of key, or returns ErrLocked when it is held.
+	// Holders that stop without calling release lose the lock after ttl;
+	// live holders keep it until release.
+	Lock(ctx context.Context, key string, ttl time.Duration) (release func(), err error)
+}
+
+// LocalLocker is a Locker for a single process.
+type LocalLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// NewLocalLocker creates a Locker whose locks only exclude holders in this
+// process.
+// Parameters: none.
+//
+// Returns:
+//   - *LocalLocker: locker with no locks held.
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{held: make(map[string]bool)}
+}
+
+// Lock takes the lock of key. The ttl is unused: local locks end with the
+// process.
+func (l *LocalLocker) Lock(_ context.Context, key string, _ time.Duration) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return nil, ErrLocked
+	}
+	l.held[key] = true
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(l.held, key)
+			l.mu.Unlock()
+		})
+	}, nil
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLocalLockerExcludesHolders(t *testing.T) {
+	t.Parallel()
+
+	locker := NewLocalLocker()
+	release, err := locker.Lock(context.Background(), "a", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock(a) error = %v", err)
+	}
+	if _, err := locker.Lock(context.Background(), "a", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Lock(a) error = %v, want ErrLocked", err)
+	}
+	if release, err := locker.Lock(context.Background(), "b", time.Minute); err != nil {
+		t.Fatalf("Lock(b) error = %v", err)
+	} else {
+		release()
+	}
+	release()
+	if _, err := locker.Lock(context.Background(), "a", time.Minute); err != nil {
+		t.Fatalf("Lock(a) after release error = %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	defaultRedisPoolSize = 10
+	defaultRedisTimeout  = 3 * time.Second
+
+	// unlockScript deletes a lock only while the caller's token holds it, so
+	// a holder whose lock expired cannot release the next holder's lock.
+	unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) end return 0`
+	// refreshScript extends a lock only while the caller's token holds it.
+	refreshScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) end return 0`
+)
+
+// RedisConfig holds the connection settings of a Redis store.
+type RedisConfig struct {
+	URL       string        // redis://[[user]:password@]host:port[/db], or rediss:// for TLS
+	KeyPrefix string        // Prepended to every key, e.g. "emomo:"
+	PoolSize  int           // Idle connections kept (default 10)
+	Timeout   time.Duration // Dial and per-command timeout (default 3s)
+}
+
+// Redis is a Store and Locker backed by a Redis server. It speaks the RESP
+// protocol over a small pool of connections and is safe for concurrent use.
+type Redis struct {
+	addr      string
+	username  string
+	password  string
+	db        int
+	tls       *tls.Config
+	keyPrefix string
+	timeout   time.Duration
+	pool      chan *redisConn
+}
+
+// redisConn is a pooled connection with its buffered reader and writer.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedis creates a Redis store. It does not connect; call Ping to check
+// the server.
+// Parameters:
+//   - cfg: connection settings.
+//
+// Returns:
+//   - *Redis: store that connects on first use.
+//   - error: non-nil if the URL is invalid.
+func NewRedis(cfg RedisConfig) (*Redis, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	r := &Redis{
+		addr:      u.Host,
+		keyPrefix: cfg.KeyPrefix,
+		timeout:   cfg.Timeout,
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		r.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("invalid redis url: scheme %q (want redis or rediss)", u.Scheme)
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+	}
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		if r.db, err = strconv.Atoi(path); err != nil {
+			return nil, fmt.Errorf("invalid redis url: database %q", path)
+		}
+	}
+	if r.timeout <= 0 {
+		r.timeout = defaultRedisTimeout
+	}
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultRedisPoolSize
+	}
+	r.pool = make(chan *redisConn, poolSize)
+	return r, nil
+}
+
+// Ping checks that the server is reachable and accepts the credentials.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - error: non-nil if the server cannot be reached.
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.do(ctx, "PING")
+	return err
+}
+
+// Get returns the value of key.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.do(ctx, "GET", r.keyPrefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	return value, ok, nil
+}
+
+// Set stores value under key for ttl (0 keeps it until evicted).
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", r.keyPrefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// Delete removes keys.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := []string{"DEL"}
+	for _, key := range keys {
+		args = append(args, r.keyPrefix+key)
+	}
+	_, err := r.do(ctx, args...)
+	return err
+}
+
+// Lock takes the lock of key for ttl and extends it every ttl/3 until
+// release, so a crashed holder loses it after at most ttl.
+func (r *Redis) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	if ttl < time.Millisecond {
+		return nil, fmt.Errorf("lock ttl %s is too short", ttl)
+	}
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(raw[:])
+	key = r.keyPrefix + key
+	ms := strconv.FormatInt(ttl.Milliseconds(), 10)
+
+	reply, err := r.do(ctx, "SET", key, token, "NX", "PX", ms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take lock %s: %w", key, err)
+	}
+	if reply == nil {
+		return nil, ErrLocked
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				reply, err := r.do(ctx, "EVAL", refreshScript, "1", key, token, ms)
+				if err != nil {
+					logger.CtxWarn(ctx, "Failed to extend lock: key=%s, error=%v", key, err)
+				} else if reply == int64(0) {
+					logger.CtxWarn(ctx, "Lock lost to another holder: key=%s", key)
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			if _, err := r.do(ctx, "EVAL", unlockScript, "1", key, token); err != nil {
+				logger.CtxWarn(ctx, "Failed to release lock, it expires on its own: key=%s, error=%v", key, err)
+			}
+		})
+	}, nil
+}
+
+// Close closes the idle connections.
+// Parameters: none.
+//
+// Returns:
+//   - error: always nil.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case conn := <-r.pool:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and reads its reply: a string for status replies, an
+// int64, a []byte or nil for bulk strings, or a []any. Error replies are
+// returned as errors.
+func (r *Redis) do(ctx context.Context, args ...string) (any, error) {
+	conn, pooled, err := r.conn(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := r.exec(ctx, conn, args)
+	if pooled && isClosedConn(err) {
+		// The server closed the idle connection (idle timeout, restart)
+		// before reading the command; send it once more on a new one.
+		if conn, _, err = r.conn(ctx, false); err != nil {
+			return nil, err
+		}
+		reply, err = r.exec(ctx, conn, args)
+	}
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	return reply, err
+}
+
+// exec runs a command on conn and returns conn to the pool, or closes it
+// after an I/O error.
+func (r *Redis) exec(ctx context.Context, conn *redisConn, args []string) (any, error) {
+	deadline := time.Now().Add(r.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.conn.SetDeadline(deadline); err != nil {
+		conn.conn.Close()
+		return nil, err
+	}
+
+	reply, err := conn.roundTrip(args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may hold half a reply; drop it.
+		conn.conn.Close()
+		return nil, err
+	}
+	select {
+	case r.pool <- conn:
+	default:
+		conn.conn.Close()
+	}
+	return reply, err
+}
+
+// isClosedConn reports whether err shows the peer closed the connection.
+// Timeouts do not count: the server may have run the command.
+func isClosedConn(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.Is(err, net.ErrClosed)
+}
+
+// conn returns an idle connection when idle is set and one is pooled, or
+// dials, authenticates and selects the database on a new one.
+func (r *Redis) conn(ctx context.Context, idle bool) (conn *redisConn, pooled bool, err error) {
+	if idle {
+		select {
+		case conn := <-r.pool:
+			return conn, true, nil
+		default:
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: r.timeout}
+	var netConn net.Conn
+	if r.tls != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: r.tls}).DialContext(ctx, "tcp", r.addr)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn = &redisConn{conn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+	if err := netConn.SetDeadline(time.Now().Add(r.timeout)); err != nil {
+		netConn.Close()
+		return nil, false, fmt.Errorf("redis: %w", err)
+	}
+
+	var setup [][]string
+	if r.password != "" {
+		if r.username != "" {
+			setup = append(setup, []string{"AUTH", r.username, r.password})
+		} else {
+			setup = append(setup, []string{"AUTH", r.password})
+		}
+	}
+	if r.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(r.db)})
+	}
+	for _, args := range setup {
+		if _, err := conn.roundTrip(args); err != nil {
+			netConn.Close()
+			return nil, false, fmt.Errorf("redis %s: %w", args[0], err)
+		}
+	}
+	return conn, false, nil
+}
+
+// roundTrip writes a command as an array of bulk strings and reads the reply.
+func (c *redisConn) roundTrip(args []string) (any, error) {
+	c.w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		c.w.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// readReply reads one RESP2 reply.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				var replyErr redisError
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = replyErr
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", kind)
+	}
+}
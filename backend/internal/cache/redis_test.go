@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the commands the Redis store sends from a map.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	auth   []string
+	conns  []net.Conn
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns = append(server.conns, conn)
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range reply.([]any) {
+			args = append(args, string(arg.([]byte)))
+		}
+		if _, err := conn.Write([]byte(f.exec(args))); err != nil {
+			return
+		}
+	}
+}
+
+// closeIdle closes every client connection, as a server does with idle ones.
+func (f *fakeRedis) closeIdle() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bulk := func(value string, ok bool) string {
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+	}
+	switch strings.ToUpper(args[0]) {
+	case "AUTH", "SELECT":
+		f.auth = append(f.auth, strings.Join(args, " "))
+		return "+OK\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.values[args[1]]
+		return bulk(value, ok)
+	case "SET":
+		if _, ok := f.values[args[1]]; ok && len(args) > 3 && args[3] == "NX" {
+			return "$-1\r\n"
+		}
+		f.values[args[1]] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := f.values[key]; ok {
+				delete(f.values, key)
+				deleted++
+			}
+		}
+		return ":" + strconv.Itoa(deleted) + "\r\n"
+	case "EVAL":
+		key, token := args[3], args[4]
+		if f.values[key] != token {
+			return ":0\r\n"
+		}
+		if args[1] == unlockScript {
+			delete(f.values, key)
+		}
+		return ":1\r\n"
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func TestRedisStoreAndLocks(t *testing.T) {
+	t.Parallel()
+
+	server, addr := startFakeRedis(t)
+	store, err := NewRedis(RedisConfig{URL: "redis://:secret@" + addr + "/2", KeyPrefix: "emomo:", PoolSize: 2})
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	server.mu.Lock()
+	setup := strings.Join(server.auth, ", ")
+	server.mu.Unlock()
+	if setup != "AUTH secret, SELECT 2" {
+		t.Fatalf("connection setup = %q, want AUTH and SELECT", setup)
+	}
+
+	if _, ok, err := store.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("Get(missing) = %t, %v, want a miss", ok, err)
+	}
+	if err := store.Set(ctx, "search:a", []byte("结果\r\n"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, ok, err := store.Get(ctx, "search:a"); !ok || err != nil || string(value) != "结果\r\n" {
+		t.Fatalf("Get(search:a) = %q, %t, %v, want the stored value", value, ok, err)
+	}
+	server.mu.Lock()
+	_, prefixed := server.values["emomo:search:a"]
+	server.mu.Unlock()
+	if !prefixed {
+		t.Fatal("stored key lacks the key prefix")
+	}
+	if err := store.Delete(ctx, "search:a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, _ := store.Get(ctx, "search:a"); ok {
+		t.Fatal("Get(search:a) after Delete hit, want a miss")
+	}
+
+	release, err := store.Lock(ctx, "lock:ingest:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if _, err := store.Lock(ctx, "lock:ingest:test", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Lock() error = %v, want ErrLocked", err)
+	}
+	release()
+	release()
+	again, err := store.Lock(ctx, "lock:ingest:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() after release error = %v", err)
+	}
+	again()
+}
+
+func TestRedisRetriesClosedIdleConnection(t *testing.T) {
+	t.Parallel()
+
+	server, addr := startFakeRedis(t)
+	store, err := NewRedis(RedisConfig{URL: "redis://" + addr, PoolSize: 2})
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	server.closeIdle()
+	if value, ok, err := store.Get(ctx, "a"); !ok || err != nil || string(value) != "1" {
+		t.Fatalf("Get() on a closed idle connection = %q, %t, %v, want the stored value", value, ok, err)
+	}
+	server.closeIdle()
+	release, err := store.Lock(ctx, "lock:ingest:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock() on a closed idle connection error = %v", err)
+	}
+	release()
+}
@@ -16,23 +16,77 @@ type Config struct {
 	Qdrant     QdrantConfig      `mapstructure:"qdrant"`
 	Storage    StorageConfig     `mapstructure:"storage"`
 	VLM        VLMConfig         `mapstructure:"vlm"`
+	Prompts    PromptsConfig     `mapstructure:"prompts"`
 	Embeddings []EmbeddingConfig `mapstructure:"embeddings"` // List of embedding configurations
 	Ingest     IngestConfig      `mapstructure:"ingest"`
 	Sources    SourcesConfig     `mapstructure:"sources"`
 	Search     SearchConfig      `mapstructure:"search"`
+	APIKeys    APIKeysConfig     `mapstructure:"api_keys"`
+	CDN        CDNConfig         `mapstructure:"cdn"`
+	Redis      RedisConfig       `mapstructure:"redis"`
+	Metrics    MetricsConfig     `mapstructure:"metrics"`
+	Fixtures   FixturesConfig    `mapstructure:"fixtures"`
 }
 
 // ServerConfig defines HTTP server settings.
 type ServerConfig struct {
-	Port int        `mapstructure:"port"`
-	Mode string     `mapstructure:"mode"`
-	CORS CORSConfig `mapstructure:"cors"`
+	Port            int                  `mapstructure:"port"`
+	Mode            string               `mapstructure:"mode"`
+	CORS            CORSConfig           `mapstructure:"cors"`
+	AdminAccess     AdminAccessConfig    `mapstructure:"admin_access"`
+	TrustedProxies  []string             `mapstructure:"trusted_proxies"`  // Proxies whose X-Forwarded-For is trusted (empty keeps Gin's default of trusting all)
+	DefaultLanguage string               `mapstructure:"default_language"` // Language of messages when Accept-Language matches none (en, zh-CN)
+	ErrorReporting  ErrorReportingConfig `mapstructure:"error_reporting"`
+	Warmup          WarmupConfig         `mapstructure:"warmup"`
+	Pprof           bool                 `mapstructure:"pprof"` // Mount net/http/pprof under /api/v1/admin/debug/pprof (needs api_keys.admin_auth)
+	Health          HealthConfig         `mapstructure:"health"`
+}
+
+// HealthConfig defines the dependency checks of /health/ready.
+type HealthConfig struct {
+	Timeout        time.Duration `mapstructure:"timeout"`         // Per dependency check
+	CacheTTL       time.Duration `mapstructure:"cache_ttl"`       // How long a result answers probes before checking again
+	CheckEmbedding bool          `mapstructure:"check_embedding"` // Also embed a short query with the default provider (spends tokens)
+}
+
+// WarmupConfig defines what is pre-loaded before /readyz reports ready.
+type WarmupConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`     // Warm caches after startup and keep /readyz at 503 until done
+	Timeout    time.Duration `mapstructure:"timeout"`     // Upper bound of the warm-up; /readyz turns ready afterwards even on failure
+	HotQueries []string      `mapstructure:"hot_queries"` // Queries whose embeddings are computed ahead of the first search
+}
+
+// ErrorReportingConfig defines where recovered panics are reported besides the logs.
+type ErrorReportingConfig struct {
+	WebhookURL string        `mapstructure:"webhook_url"` // Receives each panic report as JSON (empty only logs)
+	Token      string        `mapstructure:"token"`       // Optional bearer token of the webhook
+	Timeout    time.Duration `mapstructure:"timeout"`     // Per-request timeout of the webhook
+}
+
+// AdminAccessConfig restricts the admin page and /api/v1/admin/* to client IPs.
+type AdminAccessConfig struct {
+	Allow []string `mapstructure:"allow"` // CIDRs or IPs allowed (empty allows all not denied)
+	Deny  []string `mapstructure:"deny"`  // CIDRs or IPs denied, checked first
 }
 
 // CORSConfig defines Cross-Origin Resource Sharing settings.
 type CORSConfig struct {
-	AllowedOrigins  []string `mapstructure:"allowed_origins"`
-	AllowAllOrigins bool     `mapstructure:"allow_all_origins"`
+	AllowedOrigins  []string          `mapstructure:"allowed_origins"`
+	AllowAllOrigins bool              `mapstructure:"allow_all_origins"`
+	AllowedHeaders  []string          `mapstructure:"allowed_headers"` // Request headers allowed by preflights (empty uses the built-in list)
+	MaxAge          time.Duration     `mapstructure:"max_age"`         // Preflight cache lifetime sent as Access-Control-Max-Age (0 omits it)
+	Groups          []CORSGroupConfig `mapstructure:"groups"`          // Policies of route groups; the first matching group wins
+}
+
+// CORSGroupConfig defines the CORS policy of the routes under some path prefixes,
+// e.g. the admin API, replacing the default policy for them.
+type CORSGroupConfig struct {
+	Name            string        `mapstructure:"name"`          // Descriptive label, e.g. admin
+	PathPrefixes    []string      `mapstructure:"path_prefixes"` // e.g. /api/v1/admin
+	AllowedOrigins  []string      `mapstructure:"allowed_origins"`
+	AllowAllOrigins bool          `mapstructure:"allow_all_origins"`
+	AllowedHeaders  []string      `mapstructure:"allowed_headers"`
+	MaxAge          time.Duration `mapstructure:"max_age"`
 }
 
 // DatabaseConfig defines database connection and pool settings.
@@ -50,6 +104,12 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`    // Connection pool: max idle
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`    // Connection pool: max open
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"` // Connection pool: max lifetime
+
+	OperationTimeout time.Duration `mapstructure:"operation_timeout"` // Default per-query timeout (0 disables)
+	BusyTimeout      time.Duration `mapstructure:"busy_timeout"`      // SQLite: how long to wait on a locked database
+
+	PoolMonitorInterval time.Duration `mapstructure:"pool_monitor_interval"` // Pool stats sampling interval (0 disables)
+	PoolSaturationRatio float64       `mapstructure:"pool_saturation_ratio"` // in_use/max_open ratio treated as saturated
 }
 
 // DSN builds the Data Source Name for the configured database.
@@ -73,11 +133,15 @@ func (c *DatabaseConfig) DSN() string {
 
 // QdrantConfig defines Qdrant connection settings.
 type QdrantConfig struct {
-	Host       string `mapstructure:"host"`
-	Port       int    `mapstructure:"port"`
-	Collection string `mapstructure:"collection"` // Default collection name (fallback)
-	APIKey     string `mapstructure:"api_key"`    // Qdrant Cloud API Key
-	UseTLS     bool   `mapstructure:"use_tls"`    // Enable TLS (auto-enabled when APIKey is set)
+	Host             string        `mapstructure:"host"`
+	Port             int           `mapstructure:"port"`
+	Collection       string        `mapstructure:"collection"`        // Default collection name (fallback)
+	APIKey           string        `mapstructure:"api_key"`           // Qdrant Cloud API Key
+	UseTLS           bool          `mapstructure:"use_tls"`           // Enable TLS (auto-enabled when APIKey is set)
+	PoolSize         int           `mapstructure:"pool_size"`         // gRPC connections per collection client
+	KeepaliveTime    time.Duration `mapstructure:"keepalive_time"`    // Idle ping interval (0 disables keepalive)
+	KeepaliveTimeout time.Duration `mapstructure:"keepalive_timeout"` // Ping ack timeout before reconnecting
+	OperationTimeout time.Duration `mapstructure:"operation_timeout"` // Default per-RPC timeout (0 disables)
 }
 
 // StorageConfig holds configuration for S3-compatible storage (R2, S3, etc.).
@@ -90,6 +154,8 @@ type StorageConfig struct {
 	Bucket    string `mapstructure:"bucket"`     // Bucket name
 	Region    string `mapstructure:"region"`     // Region (for AWS S3)
 	PublicURL string `mapstructure:"public_url"` // Public URL prefix (e.g., R2.dev domain)
+
+	OperationTimeout time.Duration `mapstructure:"operation_timeout"` // Default per-request timeout (0 disables)
 }
 
 // VLMConfig defines configuration for the Vision Language Model provider.
@@ -100,20 +166,236 @@ type VLMConfig struct {
 	BaseURL  string `mapstructure:"base_url"`
 }
 
+// PromptsConfig configures overrides of the built-in LLM prompts.
+type PromptsConfig struct {
+	Dir string `mapstructure:"dir"` // Directory of <name>.txt files replacing built-in prompts (empty uses none)
+}
+
 // IngestConfig defines ingestion concurrency and batching settings.
 type IngestConfig struct {
-	Workers    int `mapstructure:"workers"`
-	BatchSize  int `mapstructure:"batch_size"`
-	RetryCount int `mapstructure:"retry_count"`
+	Workers        int                   `mapstructure:"workers"`
+	BatchSize      int                   `mapstructure:"batch_size"`
+	QueueSize      int                   `mapstructure:"queue_size"` // Items buffered so higher priorities can overtake backfill
+	RetryCount     int                   `mapstructure:"retry_count"`
+	Validation     ImageValidationConfig `mapstructure:"validation"`
+	Media          MediaConfig           `mapstructure:"media"`
+	SceneTags      SceneTaggingConfig    `mapstructure:"scene_tagging"`
+	Cleanup        DescriptionCleanup    `mapstructure:"description_cleanup"`
+	EmbeddingText  EmbeddingTextWeights  `mapstructure:"embedding_text"`
+	Chunking       DescriptionChunking   `mapstructure:"chunking"`
+	NearDuplicates string                `mapstructure:"near_duplicates"` // skip, link or force for images matching a stored meme's perceptual hash
+	Quality        DescriptionQuality    `mapstructure:"description_quality"`
+	Origins        OriginCheckConfig     `mapstructure:"origin_check"`
+	Download       DownloadConfig        `mapstructure:"download"`
+	Sparse         SparseEncoderConfig   `mapstructure:"sparse_encoder"`
+	Jobs           JobLimitsConfig       `mapstructure:"jobs"`
+	Upload         UploadConfig          `mapstructure:"upload"`
+	Webhook        WebhookConfig         `mapstructure:"webhook"`
+	Staging        StagingConfig         `mapstructure:"staging"`
+}
+
+// UploadConfig configures POST /api/v1/memes/upload, where users contribute
+// memes that stay out of search until an admin approves them.
+type UploadConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	MaxBytes   int64         `mapstructure:"max_bytes"`   // Largest accepted image
+	RateLimit  int           `mapstructure:"rate_limit"`  // Uploads per client IP and window (0 = unlimited)
+	RateWindow time.Duration `mapstructure:"rate_window"` // Window of rate_limit
+}
+
+// WebhookConfig configures POST /api/v1/ingest/webhook/:source, which the
+// crawler calls after writing a staging manifest to queue an ingest of the
+// source.
+type WebhookConfig struct {
+	Secret  string        `mapstructure:"secret"`   // HMAC-SHA256 key shared with the crawler (empty disables the webhook)
+	Limit   int           `mapstructure:"limit"`    // Items per triggered ingest
+	MaxSkew time.Duration `mapstructure:"max_skew"` // Largest accepted distance of X-Webhook-Timestamp from now
+	Review  bool          `mapstructure:"review"`   // Hold new memes of triggered ingests in the moderation queue
+}
+
+// StagingConfig configures POST /api/v1/admin/staging/upload, which writes
+// crawler batches into the staging layout of a source.
+type StagingConfig struct {
+	MaxBytes int64 `mapstructure:"max_bytes"` // Largest accepted upload and total size of its unpacked files
+}
+
+// JobLimitsConfig bounds the admin ingest and source delete jobs the API server
+// runs at once. Jobs over a source's limit wait in a per-source queue.
+type JobLimitsConfig struct {
+	MaxConcurrent int            `mapstructure:"max_concurrent"` // Jobs running at once across sources (0 = unlimited)
+	PerSource     int            `mapstructure:"per_source"`     // Jobs running at once per source
+	SourceLimits  map[string]int `mapstructure:"source_limits"`  // Per-source overrides of per_source, keyed by source ID
+	QueueSize     int            `mapstructure:"queue_size"`     // Jobs waiting per source before requests are rejected
+}
+
+// SparseEncoderConfig configures the learned sparse encoder (e.g. SPLADE) whose
+// vectors are written next to BM25 on hybrid indexes.
+type SparseEncoderConfig struct {
+	Endpoint string        `mapstructure:"endpoint"` // /embed_sparse URL (empty disables learned sparse vectors)
+	APIKey   string        `mapstructure:"api_key"`  // Optional bearer token
+	Timeout  time.Duration `mapstructure:"timeout"`  // Per-request timeout
+}
+
+// OriginCheckConfig configures checks of remote source URLs before download and
+// their periodic re-verification.
+type OriginCheckConfig struct {
+	Timeout          time.Duration `mapstructure:"timeout"`           // Per-request timeout
+	MaxBytes         int64         `mapstructure:"max_bytes"`         // Largest accepted remote image
+	UserAgent        string        `mapstructure:"user_agent"`        // Identifies the crawler to origins
+	ReverifyInterval time.Duration `mapstructure:"reverify_interval"` // Time between re-verification passes in the API server (0 disables)
+	ReverifyBatch    int           `mapstructure:"reverify_batch"`    // Memes checked per pass
+	ReverifyMaxAge   time.Duration `mapstructure:"reverify_max_age"`  // How long a previous check stays valid
+}
+
+// DownloadConfig configures the outbound HTTP requests of remote image
+// downloads and origin checks.
+type DownloadConfig struct {
+	Proxy     string `mapstructure:"proxy"`      // http, https or socks5 proxy URL (empty uses HTTP_PROXY/HTTPS_PROXY)
+	UserAgent string `mapstructure:"user_agent"` // Overrides origin_check.user_agent for every request (empty keeps it)
+	Referer   string `mapstructure:"referer"`    // Sent with requests; "origin" sends each URL's scheme and host
+}
+
+// DescriptionQuality configures scoring of new VLM descriptions. Low scores are
+// retried with a stricter prompt and then flagged for curator review.
+type DescriptionQuality struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	MinScore     float64 `mapstructure:"min_score"`     // Share of quality checks a description must pass (0-1)
+	Retry        bool    `mapstructure:"retry"`         // Retry once with a stricter prompt before flagging
+	TagsFallback bool    `mapstructure:"tags_fallback"` // Index memes the VLM fails on by their file name, tags and category
+}
+
+// DescriptionCleanup configures boilerplate stripping from VLM descriptions
+// before they become embedding and BM25 text.
+type DescriptionCleanup struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Phrases  []string `mapstructure:"phrases"`  // Literal fragments to remove (empty uses the built-in list)
+	Patterns []string `mapstructure:"patterns"` // Regular expressions to remove (empty uses the built-in list)
+}
+
+// EmbeddingTextWeights sets how many times the OCR, description and tag
+// segments are repeated in caption embedding text (at most 5 each).
+type EmbeddingTextWeights struct {
+	OCRWeight         int `mapstructure:"ocr_weight"`
+	DescriptionWeight int `mapstructure:"description_weight"`
+	TagsWeight        int `mapstructure:"tags_weight"`
+}
+
+// DescriptionChunking configures the extra caption points written for the part
+// of long descriptions the 120-rune caption text leaves out.
+type DescriptionChunking struct {
+	ChunkRunes int `mapstructure:"chunk_runes"` // Runes per chunk (0 disables chunking)
+	MaxChunks  int `mapstructure:"max_chunks"`  // Extra points per meme and caption index at most
+}
+
+// SceneTaggingConfig configures the optional scene classification pass run after
+// the VLM description. Empty APIKey/BaseURL fall back to the VLM settings.
+type SceneTaggingConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Model     string `mapstructure:"model"`
+	APIKey    string `mapstructure:"api_key"`
+	BaseURL   string `mapstructure:"base_url"`
+	MaxTokens int    `mapstructure:"max_tokens"` // Reply budget; a few tags need very few tokens
+}
+
+// MediaConfig configures conversion of HEIC/AVIF stills and WebM/MP4 clips.
+type MediaConfig struct {
+	FFmpegPath      string        `mapstructure:"ffmpeg_path"`       // ffmpeg binary (empty skips these formats)
+	MaxClipDuration time.Duration `mapstructure:"max_clip_duration"` // Clips are truncated to this length
+	Timeout         time.Duration `mapstructure:"timeout"`           // Per-conversion deadline
+	PosterOffset    time.Duration `mapstructure:"poster_offset"`     // Clip position used as poster frame
+}
+
+// ImageValidationConfig bounds the image dimensions ingest accepts.
+// Files outside the bounds, or that fail to decode, are quarantined.
+type ImageValidationConfig struct {
+	MinWidth  int `mapstructure:"min_width"`  // 0 disables the bound
+	MinHeight int `mapstructure:"min_height"` // 0 disables the bound
+	MaxWidth  int `mapstructure:"max_width"`  // 0 disables the bound
+	MaxHeight int `mapstructure:"max_height"` // 0 disables the bound
 }
 
 // SearchConfig defines search runtime settings.
 type SearchConfig struct {
-	ScoreThreshold float32               `mapstructure:"score_threshold"`
-	DefaultProfile string                `mapstructure:"default_profile"`
-	Profiles       []SearchProfileConfig `mapstructure:"profiles"`
-	Retrieval      RetrievalConfig       `mapstructure:"retrieval"`
-	QueryExpansion QueryExpansionConfig  `mapstructure:"query_expansion"`
+	ScoreThreshold     float32               `mapstructure:"score_threshold"`
+	CategoryThresholds []CategoryThreshold   `mapstructure:"category_thresholds"` // Per-category overrides of score_threshold
+	DefaultProfile     string                `mapstructure:"default_profile"`
+	Profiles           []SearchProfileConfig `mapstructure:"profiles"`
+	Retrieval          RetrievalConfig       `mapstructure:"retrieval"`
+	QueryExpansion     QueryExpansionConfig  `mapstructure:"query_expansion"`
+	Cache              SearchCacheConfig     `mapstructure:"cache"`
+	ImageSearch        ImageSearchConfig     `mapstructure:"image_search"`
+	Shadow             ShadowSearchConfig    `mapstructure:"shadow"`
+	StatsHistory       StatsHistoryConfig    `mapstructure:"stats_history"`
+	Rerank             RerankConfig          `mapstructure:"rerank"`
+	Popularity         PopularityConfig      `mapstructure:"popularity"`
+	QueryLogging       QueryLoggingConfig    `mapstructure:"query_logging"`
+}
+
+// QueryLoggingConfig controls how search query text appears in logs.
+type QueryLoggingConfig struct {
+	Mode     string `mapstructure:"mode"`      // plain, hash or truncate
+	MaxRunes int    `mapstructure:"max_runes"` // Runes kept by truncate
+	Salt     string `mapstructure:"salt"`      // HMAC key of hash
+}
+
+// RerankConfig configures the optional rerank stage after retrieval.
+type RerankConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Provider string        `mapstructure:"provider"` // "jina" (rerank API) or "llm" (chat model scoring)
+	Model    string        `mapstructure:"model"`
+	APIKey   string        `mapstructure:"api_key"`  // llm falls back to the VLM key
+	BaseURL  string        `mapstructure:"base_url"` // Empty uses the Jina API (jina) or the VLM base URL (llm)
+	TopN     int           `mapstructure:"top_n"`    // Candidates retrieved and sent to the reranker
+	Timeout  time.Duration `mapstructure:"timeout"`  // Per rerank call; on failure the retrieval order is kept
+}
+
+// PopularityConfig controls the activity counts of memes and the popularity
+// stage that blends them into search ranking.
+type PopularityConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`        // Count impressions, clicks and downloads per meme and day
+	Weight        float64       `mapstructure:"weight"`         // Share of the popularity prior in the ranking score, 0-1 (0 only counts)
+	Days          int           `mapstructure:"days"`           // Days of activity the prior sums; older rows are pruned
+	TopN          int           `mapstructure:"top_n"`          // Candidates retrieved and reordered
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // Counts are buffered and written this often (0 writes them on the request path)
+	ClickLimit    int           `mapstructure:"click_limit"`    // Clicks per client IP and window (0 = unlimited)
+	ClickWindow   time.Duration `mapstructure:"click_window"`   // Window of click_limit
+}
+
+// StatsHistoryConfig controls the daily stats served by GET /api/v1/stats/history.
+type StatsHistoryConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`           // Count searches per day and store daily snapshots
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"` // Time between snapshots of today's counts
+}
+
+// ShadowSearchConfig mirrors a share of searches to a canary collection or
+// profile and logs how its results compare.
+type ShadowSearchConfig struct {
+	Target      string        `mapstructure:"target"`        // Canary collection or profile (empty disables)
+	Percent     float64       `mapstructure:"percent"`       // Share of searches mirrored, 0-100
+	Timeout     time.Duration `mapstructure:"timeout"`       // Deadline of each shadow search
+	MaxInFlight int           `mapstructure:"max_in_flight"` // Shadow searches running at once; extra samples are dropped
+}
+
+// ImageSearchConfig bounds uploads to POST /api/v1/search/image.
+type ImageSearchConfig struct {
+	Enabled   bool  `mapstructure:"enabled"`
+	MaxBytes  int64 `mapstructure:"max_bytes"`  // Largest accepted image
+	MaxWidth  int   `mapstructure:"max_width"`  // 0 disables the bound
+	MaxHeight int   `mapstructure:"max_height"` // 0 disables the bound
+}
+
+// SearchCacheConfig defines the in-memory caches of the search service.
+type SearchCacheConfig struct {
+	TTL             time.Duration `mapstructure:"ttl"`              // Lifetime of cached category lists and stats (0 disables)
+	QueryEmbeddings int           `mapstructure:"query_embeddings"` // Query embeddings kept in memory, least recently used evicted (0 disables)
+	Results         int           `mapstructure:"results"`          // Search responses kept in memory, least recently used evicted (0 disables)
+	ResultTTL       time.Duration `mapstructure:"result_ttl"`       // Lifetime of a cached search response (0 disables)
+}
+
+// CategoryThreshold overrides the search score threshold for one category.
+type CategoryThreshold struct {
+	Category  string  `mapstructure:"category"`  // Exact category name
+	Threshold float32 `mapstructure:"threshold"` // Minimum dense score; 0 keeps every result of the category
 }
 
 // SearchProfileConfig groups multiple embedding configs into one search profile.
@@ -141,10 +423,71 @@ type RetrievalWeights struct {
 
 // QueryExpansionConfig configures optional LLM-based query expansion.
 type QueryExpansionConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Model   string `mapstructure:"model"`
-	APIKey  string `mapstructure:"api_key"`
-	BaseURL string `mapstructure:"base_url"`
+	Enabled       bool          `mapstructure:"enabled"`
+	Model         string        `mapstructure:"model"`
+	APIKey        string        `mapstructure:"api_key"`
+	BaseURL       string        `mapstructure:"base_url"`
+	MaxConcurrent int           `mapstructure:"max_concurrent"` // Expansions in flight at once; beyond it searches skip expansion (0 = unlimited)
+	QueueTimeout  time.Duration `mapstructure:"queue_timeout"`  // How long a search waits for a free slot before skipping expansion
+	CacheSize     int           `mapstructure:"cache_size"`     // Expansions kept in memory, least recently used evicted (0 disables)
+	PersistCache  bool          `mapstructure:"persist_cache"`  // Store expansions in the database and reload them on startup
+	DefaultLang   string        `mapstructure:"default_lang"`   // Expansion prompt language of requests without lang: zh or en
+}
+
+// APIKeysConfig defines API keys, their monthly quotas and the token prices
+// used to compute their cost.
+type APIKeysConfig struct {
+	Require            bool           `mapstructure:"require"`               // Reject /api/v1 requests without a key (401)
+	AdminAuth          bool           `mapstructure:"admin_auth"`            // Restrict ingest and admin routes to keys with a role (401/403); implied by configured keys
+	LLMCostPer1K       float64        `mapstructure:"llm_cost_per_1k"`       // Cost of 1000 query expansion tokens
+	EmbeddingCostPer1K float64        `mapstructure:"embedding_cost_per_1k"` // Cost of 1000 query embedding tokens
+	Keys               []APIKeyConfig `mapstructure:"keys"`
+}
+
+// APIKeyConfig defines one API key. Zero quotas are unlimited.
+type APIKeyConfig struct {
+	ID              string  `mapstructure:"id"`               // Stable ID used in usage records and admin routes
+	Key             string  `mapstructure:"key"`              // Secret sent in X-API-Key or Authorization: Bearer
+	MonthlyRequests int64   `mapstructure:"monthly_requests"` // Requests per calendar month (429 when exceeded)
+	MonthlyCost     float64 `mapstructure:"monthly_cost"`     // Cost per calendar month (402 when exceeded)
+	Role            string  `mapstructure:"role"`             // Access to ingest and admin routes: admin, readonly or empty for none
+}
+
+// CDNConfig defines HTTP caching of meme and category lists and the purge
+// webhook fired when memes change.
+type CDNConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // Send Cache-Control and surrogate keys on cacheable endpoints
+	MaxAge       time.Duration `mapstructure:"max_age"`       // Browser cache lifetime
+	SMaxAge      time.Duration `mapstructure:"s_maxage"`      // CDN cache lifetime
+	PurgeURL     string        `mapstructure:"purge_url"`     // Webhook receiving {"surrogate_keys": [...]} (empty disables purging)
+	PurgeToken   string        `mapstructure:"purge_token"`   // Optional bearer token of the webhook
+	PurgeTimeout time.Duration `mapstructure:"purge_timeout"` // Per-request timeout of the webhook
+}
+
+// RedisConfig defines the Redis server shared by API replicas for the search
+// result and query expansion caches and the per-source ingest locks.
+type RedisConfig struct {
+	URL       string        `mapstructure:"url"`        // redis://[:password@]host:6379/0 or rediss:// (empty keeps caches and locks in process)
+	KeyPrefix string        `mapstructure:"key_prefix"` // Prepended to every key
+	PoolSize  int           `mapstructure:"pool_size"`  // Idle connections kept
+	Timeout   time.Duration `mapstructure:"timeout"`    // Dial and per-command timeout
+}
+
+// MetricsConfig defines where request, search, ingest and database pool
+// metrics are sent.
+type MetricsConfig struct {
+	Sink          string        `mapstructure:"sink"`           // none, statsd or datadog (DogStatsD with tags)
+	Address       string        `mapstructure:"address"`        // Agent UDP address
+	Prefix        string        `mapstructure:"prefix"`         // Prepended to every metric name
+	Tags          []string      `mapstructure:"tags"`           // Added to every metric, e.g. env:prod (datadog only)
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // How long metrics are buffered before sending
+}
+
+// FixturesConfig defines recording and replay of the LLM, VLM and embedding
+// API exchanges, for pipeline tests without live keys.
+type FixturesConfig struct {
+	Mode string `mapstructure:"mode"` // off, record (call the APIs and save sanitized responses) or replay (answer from saved responses only)
+	Dir  string `mapstructure:"dir"`  // Directory of the fixture files
 }
 
 // SourcesConfig defines configuration for available data sources.
@@ -154,11 +497,27 @@ type SourcesConfig struct {
 
 // LocalDirConfig defines configuration for the local static directory source.
 type LocalDirConfig struct {
-	Enabled      bool   `mapstructure:"enabled"`
-	RootPath     string `mapstructure:"root_path"`
-	SourceID     string `mapstructure:"source_id"`
-	ManifestPath string `mapstructure:"manifest_path"`
-	QueuePath    string `mapstructure:"queue_path"`
+	Enabled      bool               `mapstructure:"enabled"`
+	RootPath     string             `mapstructure:"root_path"`
+	SourceID     string             `mapstructure:"source_id"`
+	ManifestPath string             `mapstructure:"manifest_path"`
+	QueuePath    string             `mapstructure:"queue_path"`
+	Skip         SkipRulesConfig    `mapstructure:"skip"`
+	Priorities   []CategoryPriority `mapstructure:"priorities"` // Matching categories are ingested first
+}
+
+// CategoryPriority assigns an ingest priority to categories matching a glob.
+type CategoryPriority struct {
+	Pattern  string `mapstructure:"pattern"`
+	Priority int    `mapstructure:"priority"` // Higher values are ingested first
+}
+
+// SkipRulesConfig defines which source items are skipped before ingest reads them.
+type SkipRulesConfig struct {
+	MinFileSize       int64    `mapstructure:"min_file_size"`      // Skip files smaller than this many bytes (0 keeps all)
+	SkipFormats       []string `mapstructure:"skip_formats"`       // Formats to skip, e.g. webm
+	IncludeCategories []string `mapstructure:"include_categories"` // Category globs to ingest (empty includes all)
+	ExcludeCategories []string `mapstructure:"exclude_categories"` // Category globs to skip
 }
 
 // Load reads configuration from file/environment and returns a Config.
@@ -211,6 +570,10 @@ func Load(configPath string) (*Config, error) {
 		cfg.Embeddings[i].ResolveEnvVars()
 	}
 
+	if err := cfg.APIKeys.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api_keys: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -227,6 +590,21 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.mode", "debug")
 	v.SetDefault("server.cors.allow_all_origins", true)
 	v.SetDefault("server.cors.allowed_origins", []string{})
+	v.SetDefault("server.cors.allowed_headers", []string{})
+	v.SetDefault("server.cors.max_age", "0s")
+	v.SetDefault("server.admin_access.allow", []string{})
+	v.SetDefault("server.admin_access.deny", []string{})
+	v.SetDefault("server.trusted_proxies", []string{})
+	v.SetDefault("server.default_language", "en")
+	v.SetDefault("server.error_reporting.webhook_url", "")
+	v.SetDefault("server.error_reporting.timeout", "10s")
+	v.SetDefault("server.warmup.enabled", false)
+	v.SetDefault("server.warmup.timeout", "30s")
+	v.SetDefault("server.warmup.hot_queries", []string{})
+	v.SetDefault("server.pprof", false)
+	v.SetDefault("server.health.timeout", "3s")
+	v.SetDefault("server.health.cache_ttl", "5s")
+	v.SetDefault("server.health.check_embedding", false)
 
 	// Database defaults
 	v.SetDefault("database.driver", "sqlite")
@@ -241,6 +619,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_idle_conns", 10)
 	v.SetDefault("database.max_open_conns", 100)
 	v.SetDefault("database.conn_max_lifetime", "1h")
+	v.SetDefault("database.operation_timeout", "10s")
+	v.SetDefault("database.busy_timeout", "5s")
+	v.SetDefault("database.pool_monitor_interval", "30s")
+	v.SetDefault("database.pool_saturation_ratio", 0.9)
 
 	// Qdrant defaults
 	v.SetDefault("qdrant.host", "localhost")
@@ -248,11 +630,16 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("qdrant.collection", "emomo")
 	v.SetDefault("qdrant.api_key", "")
 	v.SetDefault("qdrant.use_tls", false)
+	v.SetDefault("qdrant.pool_size", 1)
+	v.SetDefault("qdrant.keepalive_time", "30s")
+	v.SetDefault("qdrant.keepalive_timeout", "10s")
+	v.SetDefault("qdrant.operation_timeout", "15s")
 
 	// Storage defaults
 	v.SetDefault("storage.endpoint", "localhost:9000")
 	v.SetDefault("storage.use_ssl", false)
 	v.SetDefault("storage.bucket", "memes")
+	v.SetDefault("storage.operation_timeout", "60s")
 
 	// VLM defaults
 	v.SetDefault("vlm.provider", "openai")
@@ -262,12 +649,59 @@ func setDefaults(v *viper.Viper) {
 	// Ingest defaults
 	v.SetDefault("ingest.workers", 5)
 	v.SetDefault("ingest.batch_size", 10)
+	v.SetDefault("ingest.queue_size", 100)
 	v.SetDefault("ingest.retry_count", 3)
+	v.SetDefault("ingest.validation.min_width", 32)
+	v.SetDefault("ingest.validation.min_height", 32)
+	v.SetDefault("ingest.validation.max_width", 8192)
+	v.SetDefault("ingest.validation.max_height", 8192)
+	v.SetDefault("ingest.media.ffmpeg_path", "ffmpeg")
+	v.SetDefault("ingest.media.max_clip_duration", "10s")
+	v.SetDefault("ingest.media.timeout", "60s")
+	v.SetDefault("ingest.media.poster_offset", "500ms")
+	v.SetDefault("ingest.origin_check.timeout", "10s")
+	v.SetDefault("ingest.origin_check.max_bytes", 20<<20)
+	v.SetDefault("ingest.origin_check.user_agent", "emomo-ingest/1.0")
+	v.SetDefault("ingest.origin_check.reverify_interval", "6h")
+	v.SetDefault("ingest.origin_check.reverify_batch", 200)
+	v.SetDefault("ingest.origin_check.reverify_max_age", "168h")
+	v.SetDefault("ingest.download.proxy", "")
+	v.SetDefault("ingest.download.user_agent", "")
+	v.SetDefault("ingest.download.referer", "")
+	v.SetDefault("ingest.sparse_encoder.endpoint", "")
+	v.SetDefault("ingest.sparse_encoder.timeout", "30s")
+	v.SetDefault("ingest.jobs.max_concurrent", 4)
+	v.SetDefault("ingest.jobs.per_source", 1)
+	v.SetDefault("ingest.webhook.secret", "")
+	v.SetDefault("ingest.webhook.limit", 10000)
+	v.SetDefault("ingest.webhook.max_skew", "5m")
+	v.SetDefault("ingest.webhook.review", false)
+	v.SetDefault("ingest.jobs.queue_size", 10)
+	v.SetDefault("ingest.upload.enabled", false)
+	v.SetDefault("ingest.upload.max_bytes", 10<<20)
+	v.SetDefault("ingest.upload.rate_limit", 10)
+	v.SetDefault("ingest.upload.rate_window", "1h")
+	v.SetDefault("ingest.staging.max_bytes", 100<<20)
+	v.SetDefault("ingest.scene_tagging.enabled", false)
+	v.SetDefault("ingest.scene_tagging.model", "gpt-4o-mini")
+	v.SetDefault("ingest.scene_tagging.max_tokens", 20)
+	v.SetDefault("ingest.description_cleanup.enabled", true)
+	v.SetDefault("ingest.embedding_text.ocr_weight", 1)
+	v.SetDefault("ingest.embedding_text.description_weight", 1)
+	v.SetDefault("ingest.embedding_text.tags_weight", 1)
+	v.SetDefault("ingest.chunking.chunk_runes", 200)
+	v.SetDefault("ingest.chunking.max_chunks", 3)
+	v.SetDefault("ingest.near_duplicates", "skip")
+	v.SetDefault("ingest.description_quality.enabled", true)
+	v.SetDefault("ingest.description_quality.min_score", 0.67)
+	v.SetDefault("ingest.description_quality.retry", true)
+	v.SetDefault("ingest.description_quality.tags_fallback", false)
 
 	// Sources defaults
 	v.SetDefault("sources.localdir.enabled", true)
 	v.SetDefault("sources.localdir.root_path", "./data/memes")
 	v.SetDefault("sources.localdir.source_id", "localdir")
+	v.SetDefault("sources.localdir.skip.min_file_size", 2048)
 	v.SetDefault("sources.localdir.manifest_path", "")
 	v.SetDefault("sources.localdir.queue_path", "")
 
@@ -282,6 +716,65 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("search.retrieval.weights.keyword", 0.10)
 	v.SetDefault("search.query_expansion.enabled", true)
 	v.SetDefault("search.query_expansion.model", "gpt-4o-mini")
+	v.SetDefault("search.query_expansion.max_concurrent", 0)
+	v.SetDefault("search.query_expansion.queue_timeout", "2s")
+	v.SetDefault("search.query_expansion.cache_size", 1000)
+	v.SetDefault("search.query_expansion.persist_cache", false)
+	v.SetDefault("search.query_expansion.default_lang", "zh")
+	v.SetDefault("search.cache.ttl", "30s")
+	v.SetDefault("search.cache.query_embeddings", 1000)
+	v.SetDefault("search.cache.results", 1000)
+	v.SetDefault("search.cache.result_ttl", "60s")
+	v.SetDefault("search.image_search.enabled", true)
+	v.SetDefault("search.stats_history.enabled", true)
+	v.SetDefault("search.query_logging.mode", "plain")
+	v.SetDefault("search.query_logging.max_runes", 8)
+	v.SetDefault("search.rerank.enabled", false)
+	v.SetDefault("search.rerank.provider", "jina")
+	v.SetDefault("search.rerank.model", "jina-reranker-v2-base-multilingual")
+	v.SetDefault("search.rerank.top_n", 30)
+	v.SetDefault("search.rerank.timeout", "5s")
+	v.SetDefault("search.stats_history.snapshot_interval", "1h")
+	v.SetDefault("search.popularity.enabled", true)
+	v.SetDefault("search.popularity.weight", 0)
+	v.SetDefault("search.popularity.days", 30)
+	v.SetDefault("search.popularity.top_n", 30)
+	v.SetDefault("search.popularity.flush_interval", "10s")
+	v.SetDefault("search.popularity.click_limit", 60)
+	v.SetDefault("search.popularity.click_window", "1m")
+	v.SetDefault("search.image_search.max_bytes", 5<<20)
+	v.SetDefault("search.image_search.max_width", 4096)
+	v.SetDefault("search.image_search.max_height", 4096)
+	v.SetDefault("search.shadow.percent", 0)
+	v.SetDefault("search.shadow.timeout", "10s")
+	v.SetDefault("search.shadow.max_in_flight", 4)
+
+	// API key defaults
+	v.SetDefault("api_keys.require", false)
+	v.SetDefault("api_keys.admin_auth", false)
+	v.SetDefault("api_keys.llm_cost_per_1k", 0.0)
+	v.SetDefault("api_keys.embedding_cost_per_1k", 0.0)
+
+	// CDN defaults
+	v.SetDefault("cdn.enabled", false)
+	v.SetDefault("cdn.max_age", "60s")
+	v.SetDefault("cdn.s_maxage", "10m")
+	v.SetDefault("cdn.purge_url", "")
+	v.SetDefault("cdn.purge_timeout", "10s")
+	v.SetDefault("redis.url", "")
+	v.SetDefault("redis.key_prefix", "emomo:")
+	v.SetDefault("redis.pool_size", 10)
+	v.SetDefault("redis.timeout", "3s")
+
+	// Metrics defaults
+	v.SetDefault("metrics.sink", "none")
+	v.SetDefault("metrics.address", "127.0.0.1:8125")
+	v.SetDefault("metrics.prefix", "emomo.")
+	v.SetDefault("metrics.flush_interval", "1s")
+
+	// Provider fixture defaults
+	v.SetDefault("fixtures.mode", "off")
+	v.SetDefault("fixtures.dir", "testdata/fixtures")
 }
 
 // bindEnvVars binds environment variables to configuration keys.
@@ -300,6 +793,7 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("database.dbname", "DATABASE_DBNAME")
 	v.BindEnv("database.sslmode", "DATABASE_SSLMODE")
 	v.BindEnv("database.auto_migrate", "DATABASE_AUTO_MIGRATE")
+	v.BindEnv("database.operation_timeout", "DATABASE_OPERATION_TIMEOUT")
 
 	// Qdrant
 	v.BindEnv("qdrant.host", "QDRANT_HOST")
@@ -307,6 +801,10 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("qdrant.collection", "QDRANT_COLLECTION")
 	v.BindEnv("qdrant.api_key", "QDRANT_API_KEY")
 	v.BindEnv("qdrant.use_tls", "QDRANT_USE_TLS")
+	v.BindEnv("qdrant.pool_size", "QDRANT_POOL_SIZE")
+	v.BindEnv("qdrant.keepalive_time", "QDRANT_KEEPALIVE_TIME")
+	v.BindEnv("qdrant.keepalive_timeout", "QDRANT_KEEPALIVE_TIMEOUT")
+	v.BindEnv("qdrant.operation_timeout", "QDRANT_OPERATION_TIMEOUT")
 
 	// Storage
 	v.BindEnv("storage.type", "STORAGE_TYPE")
@@ -317,22 +815,55 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("storage.bucket", "STORAGE_BUCKET")
 	v.BindEnv("storage.region", "STORAGE_REGION")
 	v.BindEnv("storage.public_url", "STORAGE_PUBLIC_URL")
+	v.BindEnv("storage.operation_timeout", "STORAGE_OPERATION_TIMEOUT")
 
 	// VLM
 	v.BindEnv("vlm.api_key", "OPENAI_API_KEY")
 	v.BindEnv("vlm.base_url", "OPENAI_BASE_URL")
 	v.BindEnv("vlm.model", "VLM_MODEL")
 
+	// Ingest
+	v.BindEnv("ingest.media.ffmpeg_path", "FFMPEG_PATH")
+	v.BindEnv("ingest.upload.enabled", "UPLOAD_ENABLED")
+	v.BindEnv("ingest.scene_tagging.enabled", "SCENE_TAGGING_ENABLED")
+	v.BindEnv("ingest.scene_tagging.model", "SCENE_TAGGING_MODEL")
+	v.BindEnv("ingest.scene_tagging.api_key", "SCENE_TAGGING_API_KEY")
+	v.BindEnv("ingest.scene_tagging.base_url", "SCENE_TAGGING_BASE_URL")
+	v.BindEnv("ingest.download.proxy", "INGEST_DOWNLOAD_PROXY")
+	v.BindEnv("ingest.sparse_encoder.endpoint", "SPARSE_ENCODER_ENDPOINT")
+	v.BindEnv("ingest.sparse_encoder.api_key", "SPARSE_ENCODER_API_KEY")
+
+	// Error reporting
+	v.BindEnv("server.error_reporting.webhook_url", "ERROR_WEBHOOK_URL")
+	v.BindEnv("server.error_reporting.token", "ERROR_WEBHOOK_TOKEN")
+
+	// Prompts
+	v.BindEnv("prompts.dir", "PROMPTS_DIR")
+
+	// CDN
+	v.BindEnv("cdn.purge_url", "CDN_PURGE_URL")
+	v.BindEnv("cdn.purge_token", "CDN_PURGE_TOKEN")
+	v.BindEnv("redis.url", "REDIS_URL")
+	v.BindEnv("metrics.sink", "METRICS_SINK")
+	v.BindEnv("metrics.address", "STATSD_ADDRESS")
+
 	// Search
 	v.BindEnv("search.score_threshold", "SEARCH_SCORE_THRESHOLD")
 	v.BindEnv("search.query_expansion.model", "QUERY_EXPANSION_MODEL")
 	v.BindEnv("search.query_expansion.api_key", "QUERY_EXPANSION_API_KEY")
 	v.BindEnv("search.query_expansion.base_url", "QUERY_EXPANSION_BASE_URL")
+	v.BindEnv("search.query_expansion.default_lang", "QUERY_EXPANSION_DEFAULT_LANG")
+	v.BindEnv("search.rerank.api_key", "RERANK_API_KEY")
+	v.BindEnv("search.query_logging.salt", "SEARCH_QUERY_LOG_SALT")
+	v.BindEnv("search.rerank.base_url", "RERANK_BASE_URL")
+	v.BindEnv("search.popularity.weight", "SEARCH_POPULARITY_WEIGHT")
 
 	// Sources
 	v.BindEnv("sources.localdir.root_path", "LOCAL_MEMES_DIR")
 	v.BindEnv("sources.localdir.source_id", "LOCALDIR_SOURCE_ID")
 	v.BindEnv("sources.localdir.manifest_path", "LOCALDIR_MANIFEST_PATH")
+	v.BindEnv("ingest.webhook.secret", "INGEST_WEBHOOK_SECRET")
+	v.BindEnv("sources.localdir.skip.min_file_size", "LOCALDIR_MIN_FILE_SIZE")
 	v.BindEnv("sources.localdir.queue_path", "LOCALDIR_QUEUE_PATH")
 }
 
@@ -381,6 +912,45 @@ func (c *Config) GetDefaultCollection() string {
 	return c.Qdrant.Collection
 }
 
+// AdminAuthEnabled reports whether ingest and admin routes need a key with a
+// role: when admin_auth is on, or as soon as keys are configured, so that a
+// deployment with keys never leaves those routes open.
+func (c *APIKeysConfig) AdminAuthEnabled() bool {
+	return c.AdminAuth || len(c.Keys) > 0
+}
+
+// Validate reports configured keys that would authenticate ambiguously: an
+// empty ID would pass as an anonymous request, duplicate IDs or keys would
+// share usage counters, and a misspelled role would silently grant none.
+// Parameters: none.
+//
+// Returns:
+//   - error: non-nil naming the first invalid key.
+func (c *APIKeysConfig) Validate() error {
+	ids := make(map[string]bool, len(c.Keys))
+	secrets := make(map[string]bool, len(c.Keys))
+	for i, key := range c.Keys {
+		switch {
+		case strings.TrimSpace(key.ID) == "":
+			return fmt.Errorf("keys[%d]: id is required", i)
+		case ids[key.ID]:
+			return fmt.Errorf("keys[%d]: duplicate id %q", i, key.ID)
+		case strings.TrimSpace(key.Key) == "":
+			return fmt.Errorf("keys[%d] (%s): key is required", i, key.ID)
+		case secrets[key.Key]:
+			return fmt.Errorf("keys[%d] (%s): key is already used by another id", i, key.ID)
+		}
+		switch key.Role {
+		case "", "admin", "readonly":
+		default:
+			return fmt.Errorf("keys[%d] (%s): unknown role %q (want admin, readonly or empty)", i, key.ID, key.Role)
+		}
+		ids[key.ID] = true
+		secrets[key.Key] = true
+	}
+	return nil
+}
+
 // GetSearchProfileByName returns the search profile with the given name.
 func (c *Config) GetSearchProfileByName(name string) *SearchProfileConfig {
 	for i := range c.Search.Profiles {
@@ -1,6 +1,12 @@
 package config
 
-import "testing"
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestConfigDefaultSearchProfileUsesExplicitDefault(t *testing.T) {
 	t.Parallel()
@@ -46,3 +52,71 @@ func TestConfigGetSearchProfileByName(t *testing.T) {
 		t.Fatalf("caption embedding = %q, want qwen3vl_caption", profile.CaptionEmbedding)
 	}
 }
+
+func TestSummaryRedactsSecrets(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Database:   DatabaseConfig{Driver: "postgres", URL: "postgres://emomo:db-pass@db:5432/emomo?sslmode=require"},
+		Qdrant:     QdrantConfig{Host: "qdrant", Port: 6334, APIKey: "qdrant-key"},
+		Storage:    StorageConfig{Bucket: "memes", AccessKey: "access-id", SecretKey: "secret-key"},
+		VLM:        VLMConfig{Model: "gpt-4o-mini", APIKey: "vlm-key"},
+		Embeddings: []EmbeddingConfig{{Name: "jina", Model: "jina-embeddings-v3", APIKey: "embed-key"}},
+		APIKeys:    APIKeysConfig{Keys: []APIKeyConfig{{ID: "web", Key: "client-key"}}},
+		Redis:      RedisConfig{URL: "redis://:redis-pass@cache:6379/0"},
+	}
+
+	out, err := json.Marshal(cfg.Summary())
+	if err != nil {
+		t.Fatalf("failed to encode summary: %v", err)
+	}
+	summary := string(out)
+	for _, secret := range []string{"db-pass", "qdrant-key", "access-id", "secret-key", "vlm-key", "embed-key", "client-key", "redis-pass"} {
+		if strings.Contains(summary, secret) {
+			t.Fatalf("Summary() = %s, leaks %q", summary, secret)
+		}
+	}
+	for _, want := range []string{"db:5432", "jina-embeddings-v3", `"web"`, "cache:6379"} {
+		if !strings.Contains(summary, want) {
+			t.Fatalf("Summary() = %s, want it to contain %s", summary, want)
+		}
+	}
+}
+
+func TestAPIKeysConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keys    []APIKeyConfig
+		wantErr string
+	}{
+		{"valid", []APIKeyConfig{{ID: "web", Key: "k1"}, {ID: "ops", Key: "k2", Role: "admin"}, {ID: "bi", Key: "k3", Role: "readonly"}}, ""},
+		{"no keys", nil, ""},
+		{"empty id", []APIKeyConfig{{ID: " ", Key: "k1"}}, "id is required"},
+		{"duplicate id", []APIKeyConfig{{ID: "web", Key: "k1"}, {ID: "web", Key: "k2"}}, `duplicate id "web"`},
+		{"empty key", []APIKeyConfig{{ID: "web"}}, "key is required"},
+		{"duplicate key", []APIKeyConfig{{ID: "web", Key: "k1"}, {ID: "app", Key: "k1"}}, "already used"},
+		{"misspelled role", []APIKeyConfig{{ID: "ops", Key: "k1", Role: "Admin"}}, `unknown role "Admin"`},
+	}
+	for _, tt := range tests {
+		cfg := APIKeysConfig{Keys: tt.keys}
+		err := cfg.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%s) error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "api_keys:\n  keys:\n    - id: ops\n      key: change-me\n      role: superuser\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "superuser") {
+		t.Fatalf("Load() error = %v, want the unknown role rejected", err)
+	}
+}
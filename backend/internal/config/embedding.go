@@ -19,6 +19,42 @@ type EmbeddingConfig struct {
 	Dimensions   int    `mapstructure:"dimensions"`    // Embedding vector dimensions
 	Collection   string `mapstructure:"collection"`    // Qdrant collection name for this embedding
 	IsDefault    bool   `mapstructure:"is_default"`    // Whether this is the default embedding config
+
+	QueryPrefix    string `mapstructure:"query_prefix"`    // Instruction prepended to search queries (e.g. for bge/e5 models)
+	DocumentPrefix string `mapstructure:"document_prefix"` // Instruction prepended to ingested document text
+	Normalize      bool   `mapstructure:"normalize"`       // L2-normalize vectors client-side (for providers returning unnormalized embeddings)
+
+	CollectionParams CollectionParamsConfig `mapstructure:"collection_params"` // Qdrant index/optimizer tuning for this collection
+}
+
+// CollectionParamsConfig tunes the HNSW index and optimizer of a Qdrant collection.
+// Zero values fall back to the repository defaults. The settings only apply when
+// the collection is created; existing collections keep their current parameters.
+type CollectionParamsConfig struct {
+	HNSWM                int  `mapstructure:"hnsw_m"`                 // HNSW graph degree (default: 16)
+	HNSWEfConstruct      int  `mapstructure:"hnsw_ef_construct"`      // HNSW build-time candidate list size (default: 128)
+	FullScanThreshold    int  `mapstructure:"full_scan_threshold"`    // KB threshold below which plain search is used (default: 10000)
+	OnDiskVectors        bool `mapstructure:"on_disk_vectors"`        // Serve dense vectors from disk instead of RAM
+	OnDiskHNSW           bool `mapstructure:"on_disk_hnsw"`           // Store the HNSW index on disk
+	DefaultSegmentNumber int  `mapstructure:"default_segment_number"` // Target number of segments (0 = Qdrant default)
+	IndexingThreshold    int  `mapstructure:"indexing_threshold"`     // KB of vectors before indexing starts (0 = Qdrant default)
+}
+
+// Validate checks that tuning values are non-negative.
+func (c *CollectionParamsConfig) Validate() error {
+	switch {
+	case c.HNSWM < 0:
+		return fmt.Errorf("hnsw_m must not be negative")
+	case c.HNSWEfConstruct < 0:
+		return fmt.Errorf("hnsw_ef_construct must not be negative")
+	case c.FullScanThreshold < 0:
+		return fmt.Errorf("full_scan_threshold must not be negative")
+	case c.DefaultSegmentNumber < 0:
+		return fmt.Errorf("default_segment_number must not be negative")
+	case c.IndexingThreshold < 0:
+		return fmt.Errorf("indexing_threshold must not be negative")
+	}
+	return nil
 }
 
 // ResolveEnvVars resolves environment variable references in the configuration.
@@ -71,6 +107,10 @@ func (c *EmbeddingConfig) Validate() error {
 		return fmt.Errorf("embedding %q: unknown document_mode %q", c.Name, c.DocumentMode)
 	}
 
+	if err := c.CollectionParams.Validate(); err != nil {
+		return fmt.Errorf("embedding %q: collection_params: %w", c.Name, err)
+	}
+
 	return nil
 }
 
@@ -118,5 +158,11 @@ func (c *EmbeddingConfig) Clone() *EmbeddingConfig {
 		Dimensions:   c.Dimensions,
 		Collection:   c.Collection,
 		IsDefault:    c.IsDefault,
+
+		QueryPrefix:    c.QueryPrefix,
+		DocumentPrefix: c.DocumentPrefix,
+		Normalize:      c.Normalize,
+
+		CollectionParams: c.CollectionParams,
 	}
 }
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// redactedValue replaces secrets in Summary.
+const redactedValue = "[redacted]"
+
+// Summary returns the settings that decide what the API server talks to and
+// what it serves, for the startup log. Passwords, API keys and tokens are
+// replaced by "[redacted]" when set and left empty otherwise, so the log
+// shows whether a secret is configured without revealing it.
+// Parameters: none.
+//
+// Returns:
+//   - map[string]any: settings grouped by config section.
+func (c *Config) Summary() map[string]any {
+	embeddings := make([]map[string]any, 0, len(c.Embeddings))
+	for _, e := range c.Embeddings {
+		embeddings = append(embeddings, map[string]any{
+			"name":          e.Name,
+			"provider":      e.Provider,
+			"model":         e.Model,
+			"base_url":      redactURL(e.BaseURL),
+			"api_key":       redactSecret(e.APIKey),
+			"dimensions":    e.Dimensions,
+			"collection":    e.GetCollection(c.Qdrant.Collection),
+			"document_mode": e.GetDocumentMode(),
+			"default":       e.IsDefault,
+		})
+	}
+
+	database := map[string]any{"driver": c.Database.Driver}
+	switch {
+	case c.Database.Driver == "sqlite":
+		database["path"] = c.Database.Path
+	case c.Database.URL != "":
+		database["url"] = redactURL(c.Database.URL)
+	default:
+		database["host"] = c.Database.Host + ":" + strconv.Itoa(c.Database.Port)
+		database["dbname"] = c.Database.DBName
+		database["user"] = c.Database.User
+		database["password"] = redactSecret(c.Database.Password)
+	}
+
+	keyIDs := make([]string, 0, len(c.APIKeys.Keys))
+	for _, key := range c.APIKeys.Keys {
+		keyIDs = append(keyIDs, key.ID)
+	}
+
+	return map[string]any{
+		"server": map[string]any{
+			"port":   c.Server.Port,
+			"mode":   c.Server.Mode,
+			"warmup": c.Server.Warmup.Enabled,
+			"pprof":  c.Server.Pprof,
+		},
+		"database": database,
+		"qdrant": map[string]any{
+			"host":       c.Qdrant.Host + ":" + strconv.Itoa(c.Qdrant.Port),
+			"collection": c.Qdrant.Collection,
+			"use_tls":    c.Qdrant.UseTLS,
+			"api_key":    redactSecret(c.Qdrant.APIKey),
+		},
+		"storage": map[string]any{
+			"type":       c.Storage.Type,
+			"endpoint":   c.Storage.Endpoint,
+			"bucket":     c.Storage.Bucket,
+			"public_url": c.Storage.PublicURL,
+			"access_key": redactSecret(c.Storage.AccessKey),
+			"secret_key": redactSecret(c.Storage.SecretKey),
+		},
+		"embeddings": embeddings,
+		"vlm": map[string]any{
+			"provider": c.VLM.Provider,
+			"model":    c.VLM.Model,
+			"base_url": redactURL(c.VLM.BaseURL),
+			"api_key":  redactSecret(c.VLM.APIKey),
+		},
+		"search": map[string]any{
+			"default_profile": c.Search.DefaultProfile,
+			"profiles":        len(c.Search.Profiles),
+			"score_threshold": c.Search.ScoreThreshold,
+			"query_expansion": c.Search.QueryExpansion.Enabled,
+			"rerank":          c.Search.Rerank.Enabled,
+			"popularity":      c.Search.Popularity.Weight,
+			"image_search":    c.Search.ImageSearch.Enabled,
+			"query_logging":   c.Search.QueryLogging.Mode,
+		},
+		"sources": map[string]any{
+			"localdir": c.Sources.LocalDir.Enabled,
+		},
+		"api_keys": map[string]any{
+			"require":    c.APIKeys.Require,
+			"admin_auth": c.APIKeys.AdminAuthEnabled(),
+			"keys":       keyIDs,
+		},
+		"redis":    redactURL(c.Redis.URL),
+		"cdn":      c.CDN.Enabled,
+		"metrics":  c.Metrics.Sink,
+		"fixtures": c.Fixtures.Mode,
+	}
+}
+
+// redactSecret hides a configured secret.
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactURL hides the password and query of a URL, which may carry tokens.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redactedValue
+	}
+	return u.Redacted()
+}
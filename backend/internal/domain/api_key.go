@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// APIKey is an API key created through the admin API. Only a hash of the
+// secret is stored; keys from config.yaml are not recorded here.
+type APIKey struct {
+	ID              string      `gorm:"type:text;primaryKey" json:"id"`
+	Name            string      `gorm:"type:text;not null" json:"name"`
+	Prefix          string      `gorm:"type:text;not null" json:"prefix"`           // First characters of the secret, to recognize it
+	KeyHash         string      `gorm:"type:text;not null;uniqueIndex" json:"-"`    // SHA-256 of the secret, hex
+	Scopes          StringArray `gorm:"type:text" json:"scopes"`                    // search, ingest, admin
+	MonthlyRequests int64       `gorm:"not null;default:0" json:"monthly_requests"` // Request quota (0 = unlimited)
+	MonthlyCost     float64     `gorm:"not null;default:0" json:"monthly_cost"`     // Cost quota (0 = unlimited)
+	ExpiresAt       *time.Time  `json:"expires_at,omitempty"`                       // Rejected from then on; nil never expires
+	LastUsedAt      *time.Time  `json:"last_used_at,omitempty"`                     // Updated at most once a minute
+	RevokedAt       *time.Time  `gorm:"index" json:"revoked_at,omitempty"`          // Rejected from then on
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// TableName returns the database table name for APIKey.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// APIKeyUsage is the usage of one API key in one calendar month (UTC).
+type APIKeyUsage struct {
+	KeyID           string    `gorm:"type:text;primaryKey" json:"key_id"`
+	Month           string    `gorm:"type:text;primaryKey" json:"month"` // YYYY-MM
+	Requests        int64     `gorm:"not null;default:0" json:"requests"`
+	LLMTokens       int64     `gorm:"not null;default:0" json:"llm_tokens"`       // Chat completion tokens (query expansion etc.)
+	EmbeddingTokens int64     `gorm:"not null;default:0" json:"embedding_tokens"` // Query embedding tokens
+	Cost            float64   `gorm:"not null;default:0" json:"cost"`             // Token cost at the configured prices
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name for APIKeyUsage.
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// CategoryCover is the meme an admin pinned as the cover image of a category.
+type CategoryCover struct {
+	Category  string    `gorm:"type:text;primaryKey" json:"category"`
+	MemeID    string    `gorm:"type:text;not null" json:"meme_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name for CategoryCover.
+func (CategoryCover) TableName() string {
+	return "category_covers"
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// SuggestionStatus is the review state of a category suggestion.
+type SuggestionStatus string
+
+const (
+	SuggestionStatusPending  SuggestionStatus = "pending"
+	SuggestionStatusAccepted SuggestionStatus = "accepted"
+	SuggestionStatusRejected SuggestionStatus = "rejected"
+)
+
+// SuggestionKind is how a category suggestion was found.
+type SuggestionKind string
+
+const (
+	SuggestionKindCluster SuggestionKind = "cluster" // A k-means cluster named by the LLM
+	SuggestionKindOutlier SuggestionKind = "outlier" // Memes far from their category's centroid and closer to another one
+)
+
+// CategorySuggestion is a category proposed for a group of memes by the
+// offline category discovery job, waiting for review. Accepting it moves the
+// memes to Label.
+type CategorySuggestion struct {
+	ID             string           `gorm:"type:text;primaryKey" json:"id"`
+	RunID          string           `gorm:"type:text;not null;index" json:"run_id"` // Discovery run that produced the suggestion
+	Kind           SuggestionKind   `gorm:"type:text;default:cluster" json:"kind"`  // How the memes were found: cluster or outlier
+	Collection     string           `gorm:"type:text;not null" json:"collection"`   // Qdrant collection the vectors came from
+	SourceCategory string           `gorm:"type:text" json:"source_category"`       // Category the clustered memes had, e.g. 未分类
+	Label          string           `gorm:"type:text;not null" json:"label"`        // Proposed category name
+	Rationale      string           `gorm:"type:text" json:"rationale,omitempty"`   // Model's reason for the label
+	Size           int              `json:"size"`                                   // Memes in the cluster
+	MemeIDs        StringArray      `gorm:"type:text" json:"meme_ids"`              // Every meme of the cluster
+	ExemplarIDs    StringArray      `gorm:"type:text" json:"exemplar_ids"`          // Memes closest to the centroid, shown to the model
+	Status         SuggestionStatus `gorm:"type:text;index;default:pending" json:"status"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// TableName returns the database table name for CategorySuggestion.
+func (CategorySuggestion) TableName() string {
+	return "category_suggestions"
+}
@@ -13,17 +13,31 @@ const (
 	JobStatusFailed    JobStatus = "failed"
 )
 
+// JobKind is the operation an ingest job record tracks.
+type JobKind string
+
+const (
+	JobKindIngest         JobKind = "ingest"          // A run of IngestFromSource
+	JobKindSourceDelete   JobKind = "source_delete"   // Removal of all memes of a source
+	JobKindVectorExport   JobKind = "vector_export"   // Export of Qdrant points to Parquet files
+	JobKindMemeDelete     JobKind = "meme_delete"     // Removal of a single meme by an admin
+	JobKindTaxonomyImport JobKind = "taxonomy_import" // Renames and merges of categories and tags
+)
+
 // IngestJob represents a data ingestion job and its progress metadata.
 type IngestJob struct {
 	ID             string     `gorm:"type:text;primaryKey" json:"id"`
 	SourceID       string     `gorm:"type:text;not null;index" json:"source_id"`
+	Kind           JobKind    `gorm:"type:text;not null;default:ingest;index" json:"kind"`
 	Status         JobStatus  `gorm:"default:pending" json:"status"`
 	TotalItems     int        `gorm:"default:0" json:"total_items"`
 	ProcessedItems int        `gorm:"default:0" json:"processed_items"`
 	FailedItems    int        `gorm:"default:0" json:"failed_items"`
+	SkippedItems   int        `gorm:"default:0" json:"skipped_items"`
 	StartedAt      *time.Time `json:"started_at,omitempty"`
 	CompletedAt    *time.Time `json:"completed_at,omitempty"`
 	ErrorLog       string     `json:"error_log,omitempty"`
+	Report         string     `gorm:"type:text" json:"-"` // JSON run report, served by the admin report endpoint
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 }
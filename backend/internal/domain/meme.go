@@ -4,19 +4,65 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
 // MemeStatus represents the processing status of a meme record.
-// Values include MemeStatusPending, MemeStatusActive, and MemeStatusFailed.
+// Values include MemeStatusPending, MemeStatusActive, MemeStatusFailed,
+// MemeStatusReview and MemeStatusRejected.
 type MemeStatus string
 
 const (
 	MemeStatusPending MemeStatus = "pending"
 	MemeStatusActive  MemeStatus = "active"
 	MemeStatusFailed  MemeStatus = "failed"
+	// MemeStatusReview marks an uploaded or crawled meme awaiting moderation.
+	MemeStatusReview MemeStatus = "review"
+	// MemeStatusRejected marks a meme turned down by a moderator. Its row stays
+	// so ingest skips the image, but it has no points in the index.
+	MemeStatusRejected MemeStatus = "rejected"
 )
 
+// OriginStatus is the result of the last check of a meme's source URL.
+// Dead and blocked origins are listed for curator review.
+type OriginStatus string
+
+const (
+	OriginStatusOK      OriginStatus = "ok"
+	OriginStatusDead    OriginStatus = "dead"    // Gone, or no longer serving an image
+	OriginStatusBlocked OriginStatus = "blocked" // The origin refuses access or image indexing
+)
+
+// MemeSort orders meme lists.
+type MemeSort string
+
+const (
+	MemeSortNewest   MemeSort = "newest"    // Most recently ingested first
+	MemeSortOldest   MemeSort = "oldest"    // Earliest ingested first
+	MemeSortPopular  MemeSort = "popular"   // Most downloaded first
+	MemeSortFileSize MemeSort = "file_size" // Largest file first
+	MemeSortRandom   MemeSort = "random"    // Random order; every page is drawn anew
+)
+
+// ParseMemeSort validates a requested sort. Empty selects MemeSortNewest.
+// Parameters:
+//   - value: sort name from a request.
+//
+// Returns:
+//   - MemeSort: the parsed sort.
+//   - error: non-nil for unknown sorts.
+func ParseMemeSort(value string) (MemeSort, error) {
+	switch sort := MemeSort(value); sort {
+	case "":
+		return MemeSortNewest, nil
+	case MemeSortNewest, MemeSortOldest, MemeSortPopular, MemeSortFileSize, MemeSortRandom:
+		return sort, nil
+	default:
+		return "", fmt.Errorf("unknown sort %q", value)
+	}
+}
+
 // StringArray is a custom type for storing string arrays as JSON in the database.
 type StringArray []string
 
@@ -66,18 +112,29 @@ type Meme struct {
 	SourceID       string      `gorm:"type:text;not null;index:idx_memes_source,unique" json:"source_id"`
 	StorageKey     string      `gorm:"type:text" json:"storage_key"`
 	LocalPath      string      `gorm:"column:local_path" json:"local_path,omitempty"`
+	SourceURL      string      `gorm:"type:text" json:"source_url,omitempty"` // Remote origin for URL-based sources
 	Width          int         `json:"width"`
 	Height         int         `json:"height"`
 	Format         string      `json:"format"`
-	IsAnimated     bool        `json:"is_animated"` // Kept for schema compatibility; new ingestion stores false.
-	FileSize       int64       `json:"file_size"`
+	IsAnimated     bool        `json:"is_animated"`                           // True for clips; GIF ingestion is not supported.
+	PosterKey      string      `gorm:"type:text" json:"poster_key,omitempty"` // Static poster frame for animated memes
+	PosterURL      string      `gorm:"-" json:"poster_url,omitempty"`         // Derived from PosterKey for API responses
+	FileSize       int64       `gorm:"index:idx_memes_status_file_size,priority:2" json:"file_size"`
 	MD5Hash        string      `gorm:"uniqueIndex:idx_memes_md5" json:"md5_hash"`
-	PerceptualHash string      `gorm:"type:text" json:"perceptual_hash,omitempty"`
+	PerceptualHash string      `gorm:"type:text;index:idx_memes_perceptual_hash" json:"perceptual_hash,omitempty"` // 64-bit dHash as hex
+	VariantOf      string      `gorm:"type:text;index:idx_memes_variant_of" json:"variant_of,omitempty"`           // Meme this one is a near duplicate of
+	DominantColors StringArray `gorm:"type:text" json:"dominant_colors,omitempty"`                                 // Hex palette ordered by pixel share
+	Saturation     float64     `json:"saturation"`                                                                 // Mean HSV saturation (0-1)
 	Tags           StringArray `gorm:"type:text" json:"tags"`
 	Category       string      `gorm:"type:text;index:idx_memes_category" json:"category"`
-	Status         MemeStatus  `gorm:"type:text;index:idx_memes_status;default:pending" json:"status"`
-	CreatedAt      time.Time   `json:"created_at"`
+	Status         MemeStatus  `gorm:"type:text;index:idx_memes_status;index:idx_memes_status_created,priority:1;index:idx_memes_status_downloads,priority:1;index:idx_memes_status_file_size,priority:1;default:pending" json:"status"`
+	DownloadCount  int64       `gorm:"not null;default:0;index:idx_memes_status_downloads,priority:2" json:"download_count"` // Single and bundle downloads, for the popular sort
+	CreatedAt      time.Time   `gorm:"index:idx_memes_status_created,priority:2" json:"created_at"`
 	UpdatedAt      time.Time   `json:"updated_at"`
+
+	// Origin checks of SourceURL; dead and blocked origins are listed for review.
+	OriginStatus    OriginStatus `gorm:"type:text;index:idx_memes_origin_status" json:"origin_status,omitempty"` // Empty until first checked
+	OriginCheckedAt *time.Time   `json:"origin_checked_at,omitempty"`
 }
 
 // TableName returns the database table name for Meme.
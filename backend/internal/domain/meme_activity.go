@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// MemeActivity counts how often one meme was shown, picked and downloaded on
+// one UTC day. The counts of recent days are the popularity prior of search
+// ranking.
+type MemeActivity struct {
+	MemeID      string    `gorm:"type:text;primaryKey" json:"meme_id"`
+	Date        string    `gorm:"type:text;primaryKey;index" json:"date"` // YYYY-MM-DD (UTC)
+	Impressions int64     `gorm:"not null;default:0" json:"impressions"`  // Times returned by a search
+	Clicks      int64     `gorm:"not null;default:0" json:"clicks"`       // Times opened from search results
+	Downloads   int64     `gorm:"not null;default:0" json:"downloads"`    // Single and bundle downloads
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name for MemeActivity.
+func (MemeActivity) TableName() string {
+	return "meme_activity"
+}
@@ -5,13 +5,19 @@ import "time"
 // MemeDescription represents a VLM-generated description for a meme.
 // This allows the same meme to have multiple descriptions from different VLM models.
 type MemeDescription struct {
-	ID          string    `gorm:"type:text;primaryKey" json:"id"`
-	MemeID      string    `gorm:"type:text;not null;index:idx_meme_descriptions_meme" json:"meme_id"`
-	MD5Hash     string    `gorm:"type:text;not null;uniqueIndex:idx_meme_descriptions_md5_model" json:"md5_hash"`
-	VLMModel    string    `gorm:"type:text;not null;uniqueIndex:idx_meme_descriptions_md5_model" json:"vlm_model"`
-	Description string    `gorm:"type:text;not null" json:"description"`
-	OCRText     string    `gorm:"type:text" json:"ocr_text"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            string      `gorm:"type:text;primaryKey" json:"id"`
+	MemeID        string      `gorm:"type:text;not null;index:idx_meme_descriptions_meme" json:"meme_id"`
+	MD5Hash       string      `gorm:"type:text;not null;uniqueIndex:idx_meme_descriptions_md5_model" json:"md5_hash"`
+	VLMModel      string      `gorm:"type:text;not null;uniqueIndex:idx_meme_descriptions_md5_model" json:"vlm_model"`
+	Description   string      `gorm:"type:text;not null" json:"description"`
+	PromptVersion string      `gorm:"type:text;index" json:"prompt_version"` // Hash of the prompts that produced Description
+	OCRText       string      `gorm:"type:text" json:"ocr_text"`
+	SceneTags     StringArray `gorm:"type:text" json:"scene_tags,omitempty"`                 // Usage scenes from the optional scene tagger
+	BM25Text      string      `gorm:"column:bm25_text;type:text" json:"bm25_text,omitempty"` // Document text of the BM25 sparse vector
+	QualityScore  float64     `json:"quality_score"`                                         // Heuristic description quality (0-1)
+	QualityIssues StringArray `gorm:"type:text" json:"quality_issues,omitempty"`             // Failed quality checks
+	NeedsReview   bool        `gorm:"default:false;index" json:"needs_review"`               // Flagged for curator review
+	CreatedAt     time.Time   `json:"created_at"`
 }
 
 // TableName returns the database table name for MemeDescription.
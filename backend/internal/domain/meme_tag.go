@@ -0,0 +1,40 @@
+package domain
+
+import "strings"
+
+// MemeTag is one tag of a meme. The rows mirror Meme.Tags so memes can be
+// listed by tag with an index instead of scanning the JSON column.
+type MemeTag struct {
+	MemeID string `gorm:"type:text;primaryKey" json:"meme_id"`
+	Tag    string `gorm:"type:text;primaryKey;index:idx_meme_tags_tag" json:"tag"`
+}
+
+// TableName returns the database table name for MemeTag.
+// Parameters: none.
+// Returns:
+//   - string: table name for GORM mapping.
+func (MemeTag) TableName() string {
+	return "meme_tags"
+}
+
+// NewMemeTags builds the tag rows of a meme, trimming tags and dropping empty
+// and repeated ones.
+// Parameters:
+//   - memeID: meme the tags belong to.
+//   - tags: tags as stored on the meme.
+//
+// Returns:
+//   - []MemeTag: one row per distinct tag.
+func NewMemeTags(memeID string, tags []string) []MemeTag {
+	rows := make([]MemeTag, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		rows = append(rows, MemeTag{MemeID: memeID, Tag: tag})
+	}
+	return rows
+}
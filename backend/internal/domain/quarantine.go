@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// QuarantinedItem records a source file that failed image validation during ingest.
+// Quarantined files are never uploaded, described, or indexed.
+type QuarantinedItem struct {
+	ID         string    `gorm:"type:text;primaryKey" json:"id"`
+	SourceType string    `gorm:"type:text;not null;uniqueIndex:idx_quarantined_items_source" json:"source_type"`
+	SourceID   string    `gorm:"type:text;not null;uniqueIndex:idx_quarantined_items_source" json:"source_id"`
+	LocalPath  string    `gorm:"type:text" json:"local_path,omitempty"`
+	MD5Hash    string    `gorm:"type:text" json:"md5_hash"`
+	Format     string    `gorm:"type:text" json:"format"`
+	FileSize   int64     `json:"file_size"`
+	Reason     string    `gorm:"type:text;not null" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name for QuarantinedItem.
+func (QuarantinedItem) TableName() string {
+	return "quarantined_items"
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// CachedExpansion is a stored query expansion. Version hashes the expansion
+// model and prompt, so expansions from another model or prompt are never
+// reused.
+type CachedExpansion struct {
+	Version   string    `gorm:"type:text;primaryKey" json:"version"`
+	Query     string    `gorm:"type:text;primaryKey" json:"query"`
+	Expansion string    `gorm:"type:text;not null" json:"expansion"`
+	UpdatedAt time.Time `gorm:"index:idx_query_expansion_cache_updated_at" json:"updated_at"`
+}
+
+// TableName returns the database table name for CachedExpansion.
+func (CachedExpansion) TableName() string {
+	return "query_expansion_cache"
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// StatsSnapshot holds the key numbers of one UTC day for the stats history.
+// Queries grows with every search of the day; the other counts are replaced
+// by each snapshot, so a past day keeps the counts of its last snapshot.
+type StatsSnapshot struct {
+	Date        string     `gorm:"type:text;primaryKey" json:"date"`      // YYYY-MM-DD (UTC)
+	TotalMemes  int64      `gorm:"not null;default:0" json:"total_memes"` // Active memes
+	Sources     CountMap   `gorm:"type:text" json:"sources"`              // Active memes per source type
+	Collections CountMap   `gorm:"type:text" json:"collections"`          // meme_vectors rows per searchable collection
+	Queries     int64      `gorm:"not null;default:0" json:"queries"`     // Text and image searches served
+	SnapshotAt  *time.Time `json:"snapshot_at,omitempty"`                 // When the counts were taken; nil if only queries were recorded
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName returns the database table name for StatsSnapshot.
+func (StatsSnapshot) TableName() string {
+	return "stats_snapshots"
+}
+
+// CountMap is a map of counts stored as a JSON object.
+type CountMap map[string]int64
+
+// Value implements the driver.Valuer interface for database serialization.
+// Parameters: none.
+// Returns:
+//   - driver.Value: JSON-encoded string representation of the map.
+//   - error: non-nil if marshaling fails.
+func (m CountMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface for database deserialization.
+// Parameters:
+//   - value: raw database value to decode.
+//
+// Returns:
+//   - error: non-nil if decoding fails or the type is unexpected.
+func (m *CountMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = CountMap{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("failed to scan CountMap")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, m)
+}
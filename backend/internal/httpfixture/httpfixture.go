@@ -0,0 +1,282 @@
+// Package httpfixture records the HTTP exchanges of the LLM, VLM and embedding
+// clients as fixture files and replays them, so the full ingest and search
+// pipelines can run in tests without live API keys. Credentials are stripped
+// before anything is written.
+package httpfixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mode selects what the transport does with provider requests.
+type Mode string
+
+const (
+	ModeOff    Mode = "off"    // Send requests to the live API
+	ModeRecord Mode = "record" // Send requests to the live API and save the responses
+	ModeReplay Mode = "replay" // Answer requests from saved responses only
+)
+
+const (
+	redacted = "REDACTED"
+	// maxBodyPreview bounds the request body kept in a fixture for reviewers.
+	// Requests are matched by a hash of the full body.
+	maxBodyPreview = 2048
+)
+
+// ErrNoFixture is returned in replay mode for requests that were never recorded.
+var ErrNoFixture = errors.New("no recorded fixture")
+
+// secretHeaders carry credentials. They are never written, and their values
+// are scrubbed from URLs and bodies.
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Api-Key"}
+
+// secretParams are query parameters whose values are replaced in fixtures.
+var secretParams = []string{"key", "api_key", "apikey", "token", "access_token"}
+
+// Config configures fixture recording and replay.
+type Config struct {
+	Mode string            // "off", "record" or "replay" (empty is off)
+	Dir  string            // Directory of the fixture files
+	Next http.RoundTripper // Transport of live requests (nil uses http.DefaultTransport)
+}
+
+// Transport is an http.RoundTripper that records or replays fixtures.
+type Transport struct {
+	mode Mode
+	dir  string
+	next http.RoundTripper
+}
+
+// Fixture is one recorded exchange, stored as JSON.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest describes the recorded request. The body is a preview for
+// reviewers and is not used for matching.
+type FixtureRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// FixtureResponse is the recorded response.
+type FixtureResponse struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// New creates a fixture transport.
+// Parameters:
+//   - cfg: mode, fixture directory and live transport.
+//
+// Returns:
+//   - http.RoundTripper: the fixture transport, or nil when the mode is off so
+//     callers keep their default transport.
+//   - error: non-nil for an unknown mode or a missing directory.
+func New(cfg Config) (http.RoundTripper, error) {
+	mode := Mode(cfg.Mode)
+	switch mode {
+	case "", ModeOff:
+		return nil, nil
+	case ModeRecord, ModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown fixture mode %q (want off, record or replay)", cfg.Mode)
+	}
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("fixture directory is required in %s mode", mode)
+	}
+	next := cfg.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{mode: mode, dir: cfg.Dir, next: next}, nil
+}
+
+// RoundTrip answers req from a fixture in replay mode, or sends it to the live
+// API and saves the response in record mode. Throttling and server errors are
+// passed through unrecorded, so a retry records the real answer.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	secrets := requestSecrets(req.Header)
+	fixtureURL := sanitizeURL(req.URL, secrets)
+	path := t.fixturePath(req.Method, req.URL.Host, fixtureURL, scrub(string(body), secrets))
+
+	if t.mode == ModeReplay {
+		return t.replay(req, path, fixtureURL)
+	}
+
+	live := req.Clone(req.Context())
+	live.Body = io.NopCloser(bytes.NewReader(body))
+	live.ContentLength = int64(len(body))
+	resp, err := t.next.RoundTrip(live)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp, nil
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := Fixture{
+		Request: FixtureRequest{
+			Method: req.Method,
+			URL:    fixtureURL,
+			Body:   preview(scrub(string(body), secrets)),
+		},
+		Response: FixtureResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        scrub(string(respBody), secrets),
+		},
+	}
+	if err := writeFixture(path, &fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// replay builds the response of req from the fixture at path.
+func (t *Transport) replay(req *http.Request, path, fixtureURL string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s (expected %s; record it with fixtures.mode=record)",
+			ErrNoFixture, req.Method, fixtureURL, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+
+	header := http.Header{}
+	if fixture.Response.ContentType != "" {
+		header.Set("Content-Type", fixture.Response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(fixture.Response.Body)),
+		ContentLength: int64(len(fixture.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// fixturePath names the fixture of a request after its host and a hash of the
+// method, sanitized URL and sanitized body, so the same request always maps to
+// the same file regardless of the credentials used.
+func (t *Transport) fixturePath(method, host, fixtureURL, body string) string {
+	sum := sha256.Sum256([]byte(method + " " + fixtureURL + "\n" + body))
+	name := strings.ToLower(method) + "-" + hex.EncodeToString(sum[:8]) + ".json"
+	return filepath.Join(t.dir, strings.ReplaceAll(host, ":", "_"), name)
+}
+
+// writeFixture saves fixture at path through a temporary file, so concurrent
+// recordings of the same request never leave a partial file.
+func writeFixture(path string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("failed to create fixture: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save fixture: %w", err)
+	}
+	return nil
+}
+
+// requestSecrets collects the credentials sent in the headers of a request.
+func requestSecrets(header http.Header) []string {
+	var secrets []string
+	for _, name := range secretHeaders {
+		value := strings.TrimSpace(header.Get(name))
+		if scheme, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			value = strings.TrimSpace(token)
+		}
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// sanitizeURL returns u with credential query parameters and secrets replaced.
+func sanitizeURL(u *url.URL, secrets []string) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for name := range query {
+		for _, param := range secretParams {
+			if strings.EqualFold(name, param) {
+				query.Set(name, redacted)
+			}
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return scrub(clean.String(), secrets)
+}
+
+// scrub replaces every occurrence of a secret in s.
+func scrub(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// preview truncates a request body for the fixture file.
+func preview(body string) string {
+	if len(body) <= maxBodyPreview {
+		return body
+	}
+	return body[:maxBodyPreview] + "...(truncated)"
+}
@@ -0,0 +1,96 @@
+package httpfixture
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/timmy/emomo/internal/llmclient"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRecordThenReplayWithoutKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	calls := 0
+	live := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "描述这张图") {
+			t.Errorf("live request body = %s, want the chat request", body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body: io.NopCloser(strings.NewReader(
+				`{"choices":[{"message":{"content":"一只猫 sk-live-secret"},"finish_reason":"stop"}],"usage":{"total_tokens":5}}`)),
+		}, nil
+	})
+	recorder, err := New(Config{Mode: "record", Dir: dir, Next: live})
+	if err != nil {
+		t.Fatalf("New(record) error = %v", err)
+	}
+	req := llmclient.ChatRequest{Model: "vlm", Messages: []llmclient.Message{{Role: "user", Content: "描述这张图"}}}
+	recording := llmclient.New(llmclient.Config{APIKey: "sk-live-secret", BaseURL: "https://llm.test/v1", Transport: recorder})
+	if _, err := recording.Chat(context.Background(), req); err != nil {
+		t.Fatalf("Chat() while recording error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "llm.test", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "sk-live-secret") {
+		t.Fatalf("fixture contains the API key: %s", data)
+	}
+
+	replayer, err := New(Config{Mode: "replay", Dir: dir, Next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		t.Errorf("replay sent a live request")
+		return nil, errors.New("offline")
+	})})
+	if err != nil {
+		t.Fatalf("New(replay) error = %v", err)
+	}
+	replaying := llmclient.New(llmclient.Config{APIKey: "placeholder", BaseURL: "https://llm.test/v1", Transport: replayer})
+	resp, err := replaying.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Chat() while replaying error = %v", err)
+	}
+	if resp.Content != "一只猫 REDACTED" || resp.Usage.TotalTokens != 5 || calls != 1 {
+		t.Fatalf("Chat() = %+v after %d live calls, want the recorded answer", resp, calls)
+	}
+
+	req.Messages[0].Content = "另一张图"
+	if _, err := replaying.Chat(context.Background(), req); !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("Chat() of an unrecorded request error = %v, want ErrNoFixture", err)
+	}
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	t.Parallel()
+
+	if transport, err := New(Config{}); transport != nil || err != nil {
+		t.Fatalf("New(off) = %v, %v, want nil transport", transport, err)
+	}
+	if _, err := New(Config{Mode: "replay"}); err == nil {
+		t.Fatalf("New(replay) without dir error = nil, want an error")
+	}
+	if _, err := New(Config{Mode: "rewind", Dir: "fixtures"}); err == nil {
+		t.Fatalf("New(rewind) error = nil, want an error")
+	}
+}
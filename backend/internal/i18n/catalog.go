@@ -0,0 +1,393 @@
+package i18n
+
+// Message keys. API errors are returned as {"error": <message>, "code": <key>},
+// so clients can match the key regardless of the language.
+const (
+	MsgInvalidRequest   = "error.invalid_request"
+	MsgSearchFailed     = "error.search_failed"
+	MsgImageRequired    = "error.image_required"
+	MsgImageTooLarge    = "error.image_too_large"
+	MsgInvalidImage     = "error.invalid_image"
+	MsgImageSearchOff   = "error.image_search_disabled"
+	MsgBadCollection    = "error.unknown_collection"
+	MsgBadProfile       = "error.unknown_profile"
+	MsgGetCategories    = "error.get_categories"
+	MsgGetStats         = "error.get_stats"
+	MsgGetStatsHistory  = "error.get_stats_history"
+	MsgListMemes        = "error.list_memes"
+	MsgMemeIDRequired   = "error.meme_id_required"
+	MsgMemeNotFound     = "error.meme_not_found"
+	MsgMemeIDsRequired  = "error.meme_ids_required"
+	MsgTooManyMemes     = "error.too_many_memes"
+	MsgTooManyMemeIDs   = "error.too_many_meme_ids"
+	MsgGetMemes         = "error.get_memes"
+	MsgTooManyTags      = "error.too_many_tags"
+	MsgInvalidSort      = "error.invalid_sort"
+	MsgDownloadMeme     = "error.download_meme"
+	MsgRecordClick      = "error.record_click"
+	MsgUnknownEmotion   = "error.unknown_emotion"
+	MsgInvalidDay       = "error.invalid_day"
+	MsgPickMeme         = "error.pick_meme"
+	MsgStillUnavailable = "error.still_unavailable"
+	MsgUnknownSource    = "error.unknown_source"
+	MsgIngestRunning    = "error.ingest_running"
+	MsgIngestFailed     = "error.ingest_failed"
+	MsgBadSignature     = "error.bad_signature"
+	MsgSourceJobRunning = "error.source_job_running"
+	MsgGetSourceStats   = "error.get_source_stats"
+	MsgDeleteSource     = "error.delete_source"
+	MsgDeleteMeme       = "error.delete_meme"
+	MsgInvalidUpload    = "error.invalid_upload"
+	MsgUploadDuplicate  = "error.upload_near_duplicate"
+	MsgUploadRejected   = "error.upload_rejected"
+	MsgUploadMeme       = "error.upload_meme"
+	MsgInvalidStaging   = "error.invalid_staging"
+	MsgStagingTooLarge  = "error.staging_too_large"
+	MsgStagingConflict  = "error.staging_conflict"
+	MsgNoStaging        = "error.staging_unsupported"
+	MsgStageUpload      = "error.stage_upload"
+	MsgRateLimited      = "error.rate_limited"
+	MsgListModeration   = "error.list_moderation"
+	MsgMemeNotInReview  = "error.meme_not_in_review"
+	MsgApproveMeme      = "error.approve_meme"
+	MsgRejectMeme       = "error.reject_meme"
+	MsgExportTaxonomy   = "error.export_taxonomy"
+	MsgInvalidTaxonomy  = "error.invalid_taxonomy"
+	MsgImportTaxonomy   = "error.import_taxonomy"
+	MsgTaxonomyRunning  = "error.taxonomy_running"
+	MsgExportRunning    = "error.export_running"
+	MsgExportVectors    = "error.export_vectors"
+	MsgListQuarantined  = "error.list_quarantined"
+	MsgListReview       = "error.list_review"
+	MsgListSuggestions  = "error.list_suggestions"
+	MsgNoSuggestion     = "error.suggestion_not_found"
+	MsgSuggestionDone   = "error.suggestion_not_pending"
+	MsgAcceptSuggestion = "error.accept_suggestion"
+	MsgRejectSuggestion = "error.reject_suggestion"
+	MsgCoverMismatch    = "error.cover_mismatch"
+	MsgCoverNotFound    = "error.cover_not_found"
+	MsgUpdateCover      = "error.update_cover"
+	MsgRedescribe       = "error.redescribe"
+	MsgListIngestJobs   = "error.list_ingest_jobs"
+	MsgReportNotFound   = "error.report_not_found"
+	MsgGetReport        = "error.get_report"
+	MsgJobNotFound      = "error.job_not_found"
+	MsgGetIngestJob     = "error.get_ingest_job"
+	MsgReportFormat     = "error.report_format"
+	MsgListDeadOrigins  = "error.list_dead_origins"
+	MsgUnknownAPIKey    = "error.unknown_api_key"
+	MsgGetKeyUsage      = "error.get_key_usage"
+	MsgAccessDenied     = "error.access_denied"
+	MsgAPIKeyRequired   = "error.api_key_required"
+	MsgInvalidAPIKey    = "error.invalid_api_key"
+	MsgAPIKeyForbidden  = "error.api_key_forbidden"
+	MsgAPIKeyScope      = "error.api_key_scope"
+	MsgAPIKeyExpired    = "error.api_key_expired"
+	MsgCheckAPIKey      = "error.check_api_key"
+	MsgCreateAPIKey     = "error.create_api_key"
+	MsgListAPIKeys      = "error.list_api_keys"
+	MsgRevokeAPIKey     = "error.revoke_api_key"
+	MsgRequestQuota     = "error.request_quota"
+	MsgCostQuota        = "error.cost_quota"
+	MsgCheckQuota       = "error.check_quota"
+	MsgInternalError    = "error.internal"
+
+	MsgIngestQueued = "message.ingest_queued"
+
+	MsgSearchUnderstanding  = "search.understanding"
+	MsgSearchUnderstood     = "search.understood"
+	MsgSearchEmbedding      = "search.embedding"
+	MsgSearchSearching      = "search.searching"
+	MsgSearchLoading        = "search.loading"
+	MsgSearchHybridFallback = "search.hybrid_fallback"
+	MsgSearchReranking      = "search.reranking"
+
+	MsgAdminSubtitle       = "admin.subtitle"
+	MsgAdminSource         = "admin.source"
+	MsgAdminSourceLocalDir = "admin.source_localdir"
+	MsgAdminLimit          = "admin.limit"
+	MsgAdminForce          = "admin.force"
+	MsgAdminAPIKey         = "admin.api_key"
+	MsgAdminStart          = "admin.start"
+	MsgAdminRunning        = "admin.running"
+	MsgAdminRunningStatus  = "admin.running_status"
+	MsgAdminTotal          = "admin.total"
+	MsgAdminProcessed      = "admin.processed"
+	MsgAdminSkipped        = "admin.skipped"
+	MsgAdminFailed         = "admin.failed"
+	MsgAdminIngestFailed   = "admin.ingest_failed"
+	MsgAdminNetworkError   = "admin.network_error"
+	MsgAdminCompleted      = "admin.completed"
+	MsgAdminQuickLinks     = "admin.quick_links"
+	MsgAdminLinkStats      = "admin.link_stats"
+	MsgAdminLinkCategories = "admin.link_categories"
+	MsgAdminLinkMemes      = "admin.link_memes"
+	MsgAdminLinkHealth     = "admin.link_health"
+	MsgAdminStaging        = "admin.staging"
+	MsgAdminStagingHelp    = "admin.staging_help"
+	MsgAdminUpload         = "admin.upload"
+	MsgAdminUploading      = "admin.uploading"
+	MsgAdminStaged         = "admin.staged"
+	MsgAdminUploadFailed   = "admin.upload_failed"
+)
+
+// catalogs holds the messages of every supported language. English must
+// contain every key; other languages fall back to it.
+var catalogs = map[string]map[string]string{
+	En: {
+		MsgInvalidRequest:   "Invalid request: %s",
+		MsgSearchFailed:     "Search failed: %s",
+		MsgImageRequired:    "An image is required (multipart field \"image\" or base64 \"image\")",
+		MsgImageTooLarge:    "Image exceeds the %d byte limit",
+		MsgInvalidImage:     "Invalid image: %s",
+		MsgImageSearchOff:   "Image search is not available",
+		MsgGetCategories:    "Failed to get categories: %s",
+		MsgGetStats:         "Failed to get stats: %s",
+		MsgGetStatsHistory:  "Failed to get stats history: %s",
+		MsgListMemes:        "Failed to list memes: %s",
+		MsgMemeIDRequired:   "Meme ID is required",
+		MsgMemeNotFound:     "Meme not found",
+		MsgMemeIDsRequired:  "At least one meme ID is required",
+		MsgTooManyMemes:     "At most %d memes can be downloaded at once",
+		MsgTooManyMemeIDs:   "At most %d memes can be requested at once",
+		MsgGetMemes:         "Failed to get memes",
+		MsgTooManyTags:      "At most %d tags can be combined",
+		MsgInvalidSort:      "Unknown sort %q; use newest, oldest, popular, file_size or random",
+		MsgDownloadMeme:     "Failed to download meme",
+		MsgRecordClick:      "Failed to record click",
+		MsgUnknownEmotion:   "Unknown emotion %q",
+		MsgInvalidDay:       "Invalid date %q; use YYYY-MM-DD, not after today (UTC)",
+		MsgPickMeme:         "Failed to pick memes",
+		MsgStillUnavailable: "No static frame is available for this meme",
+		MsgUnknownSource:    "Unknown source: %s",
+		MsgBadCollection:    "Unknown collection %q; available: %s",
+		MsgBadProfile:       "Unknown search profile %q; available: %s",
+		MsgIngestRunning:    "Ingest is already running for source %s",
+		MsgIngestFailed:     "Ingest failed: %s",
+		MsgBadSignature:     "Missing, expired or invalid webhook signature",
+		MsgSourceJobRunning: "A job is already running for source %s",
+		MsgGetSourceStats:   "Failed to get source stats",
+		MsgDeleteSource:     "Failed to delete source: %s",
+		MsgDeleteMeme:       "Failed to delete meme: %s",
+		MsgInvalidUpload:    "Invalid upload: %s",
+		MsgUploadDuplicate:  "A near duplicate of this image is already stored",
+		MsgUploadRejected:   "This image was rejected by moderation",
+		MsgUploadMeme:       "Failed to upload meme",
+		MsgInvalidStaging:   "Invalid staging upload: %s",
+		MsgStagingTooLarge:  "Staging upload exceeds the %d byte limit",
+		MsgStagingConflict:  "A different file is already staged under this name: %s",
+		MsgNoStaging:        "Source %s does not accept staging uploads",
+		MsgStageUpload:      "Failed to write staging upload",
+		MsgRateLimited:      "Too many requests; retry in %d seconds",
+		MsgListModeration:   "Failed to list the moderation queue",
+		MsgMemeNotInReview:  "Meme %s is not awaiting review",
+		MsgApproveMeme:      "Failed to approve meme: %s",
+		MsgRejectMeme:       "Failed to reject meme: %s",
+		MsgExportTaxonomy:   "Failed to export taxonomy",
+		MsgInvalidTaxonomy:  "Invalid taxonomy: %s",
+		MsgImportTaxonomy:   "Failed to import taxonomy: %s",
+		MsgTaxonomyRunning:  "A taxonomy import is already running",
+		MsgExportRunning:    "A vector export is already running",
+		MsgExportVectors:    "Failed to export vectors: %s",
+		MsgListQuarantined:  "Failed to list quarantined items",
+		MsgListReview:       "Failed to list descriptions for review",
+		MsgListSuggestions:  "Failed to list category suggestions",
+		MsgNoSuggestion:     "Category suggestion not found",
+		MsgSuggestionDone:   "Category suggestion %s was already reviewed",
+		MsgAcceptSuggestion: "Failed to accept category suggestion: %s",
+		MsgRejectSuggestion: "Failed to reject category suggestion: %s",
+		MsgCoverMismatch:    "Meme %s is not in category %s",
+		MsgCoverNotFound:    "Category %s has no cover",
+		MsgUpdateCover:      "Failed to update category cover",
+		MsgRedescribe:       "Failed to re-describe meme",
+		MsgListIngestJobs:   "Failed to list ingest jobs",
+		MsgReportNotFound:   "No report for ingest job: %s",
+		MsgJobNotFound:      "Unknown ingest job: %s",
+		MsgGetReport:        "Failed to get ingest report",
+		MsgGetIngestJob:     "Failed to get ingest job",
+		MsgReportFormat:     "format must be json or csv",
+		MsgListDeadOrigins:  "Failed to list dead origins",
+		MsgUnknownAPIKey:    "Unknown API key: %s",
+		MsgGetKeyUsage:      "Failed to get API key usage",
+		MsgAccessDenied:     "Access denied",
+		MsgAPIKeyRequired:   "API key required",
+		MsgInvalidAPIKey:    "Invalid API key",
+		MsgAPIKeyForbidden:  "This API key lacks the %s role",
+		MsgAPIKeyScope:      "This API key lacks the %s scope",
+		MsgAPIKeyExpired:    "API key expired",
+		MsgCheckAPIKey:      "Failed to check API key",
+		MsgCreateAPIKey:     "Failed to create API key",
+		MsgListAPIKeys:      "Failed to list API keys",
+		MsgRevokeAPIKey:     "Failed to revoke API key",
+		MsgRequestQuota:     "Monthly request quota exceeded",
+		MsgCostQuota:        "Monthly cost quota exceeded",
+		MsgCheckQuota:       "Failed to check quota",
+		MsgInternalError:    "Internal server error (incident %s)",
+
+		MsgIngestQueued: "Ingest job queued",
+
+		MsgSearchUnderstanding:  "AI is working out what you are looking for...",
+		MsgSearchUnderstood:     "Query understood",
+		MsgSearchEmbedding:      "Generating semantic vectors...",
+		MsgSearchSearching:      "Searching the meme library...",
+		MsgSearchLoading:        "Loading meme details...",
+		MsgSearchHybridFallback: "Hybrid search failed, falling back to semantic search...",
+		MsgSearchReranking:      "Reranking the best matches...",
+
+		MsgAdminSubtitle:       "Meme semantic search admin panel",
+		MsgAdminSource:         "Source",
+		MsgAdminSourceLocalDir: "Local image directory",
+		MsgAdminLimit:          "Items to ingest",
+		MsgAdminForce:          "Force reprocessing (skip duplicate check)",
+		MsgAdminAPIKey:         "API key (kept in this browser)",
+		MsgAdminStart:          "Start ingest",
+		MsgAdminRunning:        "Ingesting...",
+		MsgAdminRunningStatus:  "Ingesting, please wait...",
+		MsgAdminTotal:          "Total",
+		MsgAdminProcessed:      "Processed",
+		MsgAdminSkipped:        "Skipped",
+		MsgAdminFailed:         "Failed",
+		MsgAdminIngestFailed:   "Ingest failed",
+		MsgAdminNetworkError:   "Network error: ",
+		MsgAdminCompleted:      "Ingest completed successfully",
+		MsgAdminQuickLinks:     "Quick links",
+		MsgAdminLinkStats:      "System stats",
+		MsgAdminLinkCategories: "Categories",
+		MsgAdminLinkMemes:      "Memes",
+		MsgAdminLinkHealth:     "Health check",
+		MsgAdminStaging:        "Upload to staging",
+		MsgAdminStagingHelp:    "Zip of images with a stage2 manifest (.jsonl)",
+		MsgAdminUpload:         "Upload",
+		MsgAdminUploading:      "Uploading...",
+		MsgAdminStaged:         "Staged {written} new files ({unchanged} unchanged); {source} now has {total} items",
+		MsgAdminUploadFailed:   "Upload failed",
+	},
+	ZhCN: {
+		MsgInvalidRequest:   "请求无效：%s",
+		MsgSearchFailed:     "搜索失败：%s",
+		MsgImageRequired:    "请上传图片（multipart 字段 \"image\" 或 base64 字段 \"image\"）",
+		MsgImageTooLarge:    "图片超过 %d 字节上限",
+		MsgInvalidImage:     "图片无效：%s",
+		MsgImageSearchOff:   "以图搜图功能未启用",
+		MsgGetCategories:    "获取分类失败：%s",
+		MsgGetStats:         "获取统计失败：%s",
+		MsgGetStatsHistory:  "获取历史统计失败：%s",
+		MsgListMemes:        "获取表情包列表失败：%s",
+		MsgMemeIDRequired:   "缺少表情包 ID",
+		MsgMemeNotFound:     "表情包不存在",
+		MsgMemeIDsRequired:  "至少需要一个表情包 ID",
+		MsgTooManyMemes:     "一次最多下载 %d 个表情包",
+		MsgTooManyMemeIDs:   "一次最多查询 %d 个表情包",
+		MsgGetMemes:         "获取表情包失败",
+		MsgTooManyTags:      "最多同时筛选 %d 个标签",
+		MsgInvalidSort:      "未知的排序方式 %q，可选 newest、oldest、popular、file_size、random",
+		MsgDownloadMeme:     "下载表情包失败",
+		MsgRecordClick:      "记录点击失败",
+		MsgUnknownEmotion:   "未知的情绪 %q",
+		MsgInvalidDay:       "无效的日期 %q，格式为 YYYY-MM-DD，且不能晚于今天（UTC）",
+		MsgPickMeme:         "选取表情包失败",
+		MsgStillUnavailable: "该表情包暂无静态图",
+		MsgUnknownSource:    "未知数据源：%s",
+		MsgBadCollection:    "未知的 collection %q，可选：%s",
+		MsgBadProfile:       "未知的搜索 profile %q，可选：%s",
+		MsgIngestRunning:    "数据源 %s 正在导入",
+		MsgIngestFailed:     "导入失败：%s",
+		MsgBadSignature:     "Webhook 签名缺失、过期或无效",
+		MsgSourceJobRunning: "数据源 %s 已有任务在运行",
+		MsgGetSourceStats:   "获取数据源统计失败",
+		MsgDeleteSource:     "删除数据源失败：%s",
+		MsgDeleteMeme:       "删除表情包失败：%s",
+		MsgInvalidUpload:    "上传内容无效：%s",
+		MsgUploadDuplicate:  "已存在与该图片几乎相同的表情包",
+		MsgUploadRejected:   "该图片已被审核驳回",
+		MsgUploadMeme:       "上传表情包失败",
+		MsgInvalidStaging:   "暂存上传无效：%s",
+		MsgStagingTooLarge:  "暂存上传超过 %d 字节上限",
+		MsgStagingConflict:  "已暂存同名但内容不同的文件：%s",
+		MsgNoStaging:        "数据源 %s 不支持暂存上传",
+		MsgStageUpload:      "写入暂存上传失败",
+		MsgRateLimited:      "请求过于频繁，请 %d 秒后重试",
+		MsgListModeration:   "获取待审核队列失败",
+		MsgMemeNotInReview:  "表情包 %s 不在待审核状态",
+		MsgApproveMeme:      "审核通过表情包失败：%s",
+		MsgRejectMeme:       "驳回表情包失败：%s",
+		MsgExportTaxonomy:   "导出分类与标签体系失败",
+		MsgInvalidTaxonomy:  "分类与标签体系无效：%s",
+		MsgImportTaxonomy:   "导入分类与标签体系失败：%s",
+		MsgTaxonomyRunning:  "已有分类与标签体系导入正在运行",
+		MsgExportRunning:    "已有向量导出任务在运行",
+		MsgExportVectors:    "导出向量失败：%s",
+		MsgListQuarantined:  "获取隔离文件列表失败",
+		MsgListReview:       "获取待审核描述失败",
+		MsgListSuggestions:  "获取分类建议失败",
+		MsgNoSuggestion:     "分类建议不存在",
+		MsgSuggestionDone:   "分类建议 %s 已审核",
+		MsgAcceptSuggestion: "采纳分类建议失败：%s",
+		MsgRejectSuggestion: "拒绝分类建议失败：%s",
+		MsgCoverMismatch:    "表情包 %s 不属于分类 %s",
+		MsgCoverNotFound:    "分类 %s 没有封面",
+		MsgUpdateCover:      "更新分类封面失败",
+		MsgRedescribe:       "重新生成描述失败",
+		MsgListIngestJobs:   "获取导入任务列表失败",
+		MsgReportNotFound:   "导入任务 %s 没有报告",
+		MsgJobNotFound:      "导入任务 %s 不存在",
+		MsgGetReport:        "获取导入报告失败",
+		MsgGetIngestJob:     "获取导入任务失败",
+		MsgReportFormat:     "format 只能是 json 或 csv",
+		MsgListDeadOrigins:  "获取失效来源列表失败",
+		MsgUnknownAPIKey:    "未知 API Key：%s",
+		MsgGetKeyUsage:      "获取 API Key 用量失败",
+		MsgAccessDenied:     "禁止访问",
+		MsgAPIKeyRequired:   "缺少 API Key",
+		MsgInvalidAPIKey:    "API Key 无效",
+		MsgAPIKeyForbidden:  "该 API Key 没有 %s 角色",
+		MsgAPIKeyScope:      "该 API Key 没有 %s 权限范围",
+		MsgAPIKeyExpired:    "API Key 已过期",
+		MsgCheckAPIKey:      "校验 API Key 失败",
+		MsgCreateAPIKey:     "创建 API Key 失败",
+		MsgListAPIKeys:      "获取 API Key 列表失败",
+		MsgRevokeAPIKey:     "吊销 API Key 失败",
+		MsgRequestQuota:     "本月请求次数已用完",
+		MsgCostQuota:        "本月费用额度已用完",
+		MsgCheckQuota:       "检查配额失败",
+		MsgInternalError:    "服务器内部错误（事件编号 %s）",
+
+		MsgIngestQueued: "导入任务已加入队列",
+
+		MsgSearchUnderstanding:  "AI 正在理解搜索意图...",
+		MsgSearchUnderstood:     "理解完成",
+		MsgSearchEmbedding:      "正在生成语义向量...",
+		MsgSearchSearching:      "在表情库中搜索...",
+		MsgSearchLoading:        "加载表情包详情...",
+		MsgSearchHybridFallback: "混合检索失败，切换为语义检索...",
+		MsgSearchReranking:      "正在精排最匹配的结果...",
+
+		MsgAdminSubtitle:       "表情包语义搜索系统管理面板",
+		MsgAdminSource:         "数据源",
+		MsgAdminSourceLocalDir: "本地静态图片目录",
+		MsgAdminLimit:          "导入数量",
+		MsgAdminForce:          "强制重新处理（跳过重复检查）",
+		MsgAdminAPIKey:         "API Key（保存在本浏览器）",
+		MsgAdminStart:          "开始导入",
+		MsgAdminRunning:        "导入中...",
+		MsgAdminRunningStatus:  "正在导入数据，请稍候...",
+		MsgAdminTotal:          "总计",
+		MsgAdminProcessed:      "已处理",
+		MsgAdminSkipped:        "跳过",
+		MsgAdminFailed:         "失败",
+		MsgAdminIngestFailed:   "导入失败",
+		MsgAdminNetworkError:   "网络错误: ",
+		MsgAdminCompleted:      "导入完成",
+		MsgAdminQuickLinks:     "快速链接",
+		MsgAdminLinkStats:      "系统统计",
+		MsgAdminLinkCategories: "分类列表",
+		MsgAdminLinkMemes:      "表情包",
+		MsgAdminLinkHealth:     "健康检查",
+		MsgAdminStaging:        "上传到暂存区",
+		MsgAdminStagingHelp:    "包含图片和 stage2 清单（.jsonl）的 zip",
+		MsgAdminUpload:         "上传",
+		MsgAdminUploading:      "上传中...",
+		MsgAdminStaged:         "已暂存 {written} 个新文件（{unchanged} 个未变）；{source} 现有 {total} 项",
+		MsgAdminUploadFailed:   "上传失败",
+	},
+}
@@ -0,0 +1,167 @@
+// Package i18n translates user-facing API messages and admin UI text. The
+// language of a request is negotiated from Accept-Language by the API
+// middleware and carried in the request context.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages.
+const (
+	ZhCN = "zh-CN"
+	En   = "en"
+)
+
+// DefaultLanguage is used when a request accepts no supported language.
+const DefaultLanguage = En
+
+type languageKey struct{}
+
+// Supported returns the supported languages.
+// Parameters: none.
+// Returns:
+//   - []string: language tags.
+func Supported() []string {
+	return []string{ZhCN, En}
+}
+
+// Normalize maps a language tag to a supported language, e.g. "zh-Hans" or
+// "zh_TW" to zh-CN and "en-GB" to en.
+// Parameters:
+//   - tag: BCP 47 language tag.
+//
+// Returns:
+//   - string: supported language.
+//   - bool: false if the language is not supported.
+func Normalize(tag string) (string, bool) {
+	primary := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	switch primary {
+	case "zh":
+		return ZhCN, true
+	case "en":
+		return En, true
+	}
+	return "", false
+}
+
+// Match picks the supported language the client prefers most.
+// Parameters:
+//   - acceptLanguage: Accept-Language header value, e.g. "zh-CN,zh;q=0.9,en;q=0.8".
+//   - fallback: language returned when none matches.
+//
+// Returns:
+//   - string: negotiated language.
+func Match(acceptLanguage, fallback string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, ok := Normalize(tag)
+		if !ok || q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{lang: lang, q: q})
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang
+}
+
+// NewContext returns a copy of ctx carrying lang.
+// Parameters:
+//   - ctx: parent context.
+//   - lang: supported language.
+//
+// Returns:
+//   - context.Context: context carrying lang.
+func NewContext(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext returns the language of ctx, or DefaultLanguage.
+// Parameters:
+//   - ctx: request context.
+//
+// Returns:
+//   - string: language.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// T returns the message of key in lang, formatted with args. Messages missing
+// from lang fall back to English, unknown keys to the key itself.
+// Parameters:
+//   - lang: supported language.
+//   - key: message key.
+//   - args: fmt arguments of the message.
+//
+// Returns:
+//   - string: translated message.
+func T(lang, key string, args ...any) string {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		if msg, ok = catalogs[En][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Message returns the message of key in the language of ctx.
+// Parameters:
+//   - ctx: request context.
+//   - key: message key.
+//   - args: fmt arguments of the message.
+//
+// Returns:
+//   - string: translated message.
+func Message(ctx context.Context, key string, args ...any) string {
+	return T(FromContext(ctx), key, args...)
+}
+
+// Catalog returns the messages of lang whose keys start with prefix, e.g.
+// "admin." for the admin UI script.
+// Parameters:
+//   - lang: supported language.
+//   - prefix: key prefix.
+//
+// Returns:
+//   - map[string]string: messages keyed by message key.
+func Catalog(lang, prefix string) map[string]string {
+	out := make(map[string]string)
+	for key := range catalogs[En] {
+		if strings.HasPrefix(key, prefix) {
+			out[key] = T(lang, key)
+		}
+	}
+	return out
+}
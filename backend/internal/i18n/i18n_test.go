@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	for header, want := range map[string]string{
+		"":                           En,
+		"zh-CN,zh;q=0.9,en;q=0.8":    ZhCN,
+		"en-US,en;q=0.9,zh-CN;q=0.8": En,
+		"fr-FR, zh-TW;q=0.5":         ZhCN,
+		"en;q=0.2, zh-Hans;q=0.7":    ZhCN,
+		"fr, de;q=0.8":               En,
+		"zh;q=0, en;q=0.1":           En,
+		"zh-CN;q=bogus, en-GB;q=0.3": En,
+		"ZH-cn":                      ZhCN,
+	} {
+		if got := Match(header, En); got != want {
+			t.Errorf("Match(%q) = %q, want %q", header, got, want)
+		}
+	}
+	if got := Match("fr", ZhCN); got != ZhCN {
+		t.Fatalf("Match(fr) with zh-CN fallback = %q, want zh-CN", got)
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	t.Parallel()
+
+	for key, en := range catalogs[En] {
+		for _, lang := range Supported() {
+			msg, ok := catalogs[lang][key]
+			if !ok {
+				t.Errorf("%s has no message for %s", lang, key)
+				continue
+			}
+			if strings.Count(msg, "%") != strings.Count(en, "%") {
+				t.Errorf("%s message %q for %s has different arguments than %q", lang, msg, key, en)
+			}
+		}
+	}
+	for lang, catalog := range catalogs {
+		for key := range catalog {
+			if _, ok := catalogs[En][key]; !ok {
+				t.Errorf("%s message %s is missing from the English catalog", lang, key)
+			}
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	t.Parallel()
+
+	ctx := NewContext(context.Background(), ZhCN)
+	if got := Message(ctx, MsgUnknownSource, "foo"); got != "未知数据源：foo" {
+		t.Fatalf("Message(zh-CN) = %q, want the Chinese message", got)
+	}
+	if got := Message(context.Background(), MsgUnknownSource, "foo"); got != "Unknown source: foo" {
+		t.Fatalf("Message() without a language = %q, want English", got)
+	}
+	if got := T(ZhCN, "no.such.key"); got != "no.such.key" {
+		t.Fatalf("T(unknown key) = %q, want the key", got)
+	}
+}
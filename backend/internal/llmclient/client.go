@@ -0,0 +1,380 @@
+// Package llmclient is a small client for OpenAI-compatible chat completion
+// APIs, shared by the VLM, query expansion and scene tagging services.
+package llmclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/usage"
+)
+
+const (
+	defaultBaseURL   = "https://api.openai.com/v1"
+	defaultTimeout   = 60 * time.Second
+	defaultRetryWait = time.Second
+)
+
+// ErrNoChoices is returned when a completion contains no choices.
+var ErrNoChoices = errors.New("no choices in response")
+
+// Config configures a chat completion client.
+type Config struct {
+	APIKey     string
+	BaseURL    string            // API base URL (empty uses https://api.openai.com/v1)
+	Timeout    time.Duration     // Per-request timeout (0 uses 60s)
+	MaxRetries int               // Retries after throttling, 5xx and network errors
+	RetryWait  time.Duration     // Wait before the first retry, doubled per attempt (0 uses 1s)
+	Transport  http.RoundTripper // Replaces the HTTP transport, e.g. with fixture replay (nil uses the default)
+}
+
+// Client calls the /chat/completions endpoint of an OpenAI-compatible API.
+type Client struct {
+	client     *resty.Client
+	endpoint   string
+	maxRetries int
+	retryWait  time.Duration
+
+	promptTokens     atomic.Int64
+	completionTokens atomic.Int64
+}
+
+// Message is one chat message. Content is a string or a slice of ContentPart.
+type Message struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// ContentPart is one part of a multimodal user message.
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL or data URL.
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ChatRequest is a chat completion request. Stream is set by ChatStream.
+type ChatRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float32  `json:"temperature,omitempty"` // nil uses the model default
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+// Usage is the token usage reported for a completion.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatResponse is the first choice of a completion with its usage.
+type ChatResponse struct {
+	Content      string
+	FinishReason string
+	Usage        Usage
+}
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	StatusCode int
+	Message    string // Error message from the response body, if any
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request may succeed when sent again.
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+type completionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage    `json:"usage,omitempty"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// New creates a chat completion client.
+// Parameters:
+//   - cfg: API key, base URL, timeout and retry settings.
+//
+// Returns:
+//   - *Client: initialized client.
+func New(cfg Config) *Client {
+	client := resty.New()
+	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
+	client.SetHeader("Content-Type", "application/json")
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	client.SetTimeout(timeout)
+	if cfg.Transport != nil {
+		client.SetTransport(cfg.Transport)
+	}
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	retryWait := cfg.RetryWait
+	if retryWait <= 0 {
+		retryWait = defaultRetryWait
+	}
+
+	return &Client{
+		client:     client,
+		endpoint:   baseURL + "/chat/completions",
+		maxRetries: cfg.MaxRetries,
+		retryWait:  retryWait,
+	}
+}
+
+// SetTransport replaces the HTTP transport, e.g. to stub the API in tests.
+// Parameters:
+//   - transport: round tripper used for all requests.
+//
+// Returns: none.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.client.SetTransport(transport)
+}
+
+// TotalUsage returns the tokens used by all completions of this client.
+// Parameters: none.
+// Returns:
+//   - Usage: accumulated prompt, completion and total tokens.
+func (c *Client) TotalUsage() Usage {
+	prompt := int(c.promptTokens.Load())
+	completion := int(c.completionTokens.Load())
+	return Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+// Float32 returns a pointer to v, for ChatRequest.Temperature.
+func Float32(v float32) *float32 {
+	return &v
+}
+
+// Chat sends a chat completion request and returns the first choice.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: chat completion request.
+//
+// Returns:
+//   - *ChatResponse: first choice content and usage.
+//   - error: *APIError for non-2xx responses, ErrNoChoices for empty
+//     completions, or the transport error.
+func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	req.Stream = false
+
+	var result *ChatResponse
+	err := c.withRetry(ctx, func() error {
+		var resp completionResponse
+		httpResp, err := c.client.R().
+			SetContext(ctx).
+			SetBody(req).
+			SetResult(&resp).
+			SetError(&resp).
+			Post(c.endpoint)
+		if err != nil {
+			return err
+		}
+		if httpResp.IsError() {
+			return newAPIError(httpResp.StatusCode(), resp.Error, httpResp.Body())
+		}
+		if resp.Error != nil {
+			return &APIError{StatusCode: httpResp.StatusCode(), Message: resp.Error.Message, Body: string(httpResp.Body())}
+		}
+		if len(resp.Choices) == 0 {
+			return fmt.Errorf("%w (status: %d, body: %s)", ErrNoChoices, httpResp.StatusCode(), string(httpResp.Body()))
+		}
+
+		result = &ChatResponse{
+			Content:      resp.Choices[0].Message.Content,
+			FinishReason: resp.Choices[0].FinishReason,
+		}
+		if resp.Usage != nil {
+			result.Usage = *resp.Usage
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.recordUsage(ctx, result.Usage)
+	return result, nil
+}
+
+// ChatStream sends a streaming chat completion request and passes each content
+// delta to onDelta as it arrives. Only connecting is retried; once deltas have
+// been delivered, errors are returned as is.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: chat completion request.
+//   - onDelta: called with every non-empty content delta, in order.
+//
+// Returns:
+//   - *ChatResponse: full content, finish reason and usage (if reported).
+//   - error: *APIError for non-2xx responses or the transport or read error.
+func (c *Client) ChatStream(ctx context.Context, req ChatRequest, onDelta func(string)) (*ChatResponse, error) {
+	req.Stream = true
+
+	var body io.ReadCloser
+	err := c.withRetry(ctx, func() error {
+		httpResp, err := c.client.R().
+			SetContext(ctx).
+			SetHeader("Accept", "text/event-stream").
+			SetBody(req).
+			SetDoNotParseResponse(true).
+			Post(c.endpoint)
+		if err != nil {
+			return err
+		}
+		raw := httpResp.RawBody()
+		if httpResp.IsError() {
+			data, _ := io.ReadAll(raw)
+			raw.Close()
+			var parsed completionResponse
+			_ = json.Unmarshal(data, &parsed)
+			return newAPIError(httpResp.StatusCode(), parsed.Error, data)
+		}
+		body = raw
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	result := &ChatResponse{}
+	var content strings.Builder
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue // Blank separators, comments and other fields
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue // Skip malformed data
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if reason := chunk.Choices[0].FinishReason; reason != nil {
+			result.FinishReason = *reason
+		}
+		if delta := chunk.Choices[0].Delta.Content; delta != "" {
+			content.WriteString(delta)
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream read error: %w", err)
+	}
+
+	result.Content = content.String()
+	c.recordUsage(ctx, result.Usage)
+	return result, nil
+}
+
+// withRetry runs call until it succeeds, fails permanently or the retries run
+// out, doubling the wait between attempts.
+func (c *Client) withRetry(ctx context.Context, call func() error) error {
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= c.maxRetries || !isRetryable(ctx, err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// isRetryable reports whether err is throttling, a server error or a network
+// error that was not caused by ctx ending.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrNoChoices) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// recordUsage adds a completion's tokens to the client totals and to the
+// request meter of ctx.
+func (c *Client) recordUsage(ctx context.Context, u Usage) {
+	c.promptTokens.Add(int64(u.PromptTokens))
+	c.completionTokens.Add(int64(u.CompletionTokens))
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.PromptTokens + u.CompletionTokens
+	}
+	usage.AddLLMTokens(ctx, total)
+}
+
+func newAPIError(statusCode int, parsed *apiError, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Body: string(body)}
+	if parsed != nil {
+		apiErr.Message = parsed.Message
+	}
+	return apiErr
+}
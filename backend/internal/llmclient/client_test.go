@@ -0,0 +1,114 @@
+package llmclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/usage"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func textResponse(status int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestChatRetriesThrottlingAndRecordsUsage(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := New(Config{APIKey: "key", BaseURL: "https://llm.test/v1/", MaxRetries: 2, RetryWait: time.Millisecond})
+	client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if r.URL.String() != "https://llm.test/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request = %s with auth %q, want chat completions with bearer key", r.URL, r.Header.Get("Authorization"))
+		}
+		if calls == 1 {
+			return textResponse(http.StatusTooManyRequests, "application/json", `{"error":{"message":"slow down"}}`), nil
+		}
+		return textResponse(http.StatusOK, "application/json",
+			`{"choices":[{"message":{"content":"你好"},"finish_reason":"stop"}],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`), nil
+	}))
+
+	meter := &usage.Meter{}
+	ctx := usage.NewContext(context.Background(), meter)
+	resp, err := client.Chat(ctx, ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if calls != 2 || resp.Content != "你好" || resp.FinishReason != "stop" || resp.Usage.TotalTokens != 9 {
+		t.Fatalf("Chat() = %+v after %d calls, want content after one retry", resp, calls)
+	}
+	if total := client.TotalUsage(); total.PromptTokens != 7 || total.TotalTokens != 9 {
+		t.Fatalf("TotalUsage() = %+v, want 7 prompt and 9 total tokens", total)
+	}
+	if got := meter.LLMTokens(); got != 9 {
+		t.Fatalf("meter.LLMTokens() = %d, want 9", got)
+	}
+}
+
+func TestChatDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := New(Config{MaxRetries: 3, RetryWait: time.Millisecond})
+	client.SetTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return textResponse(http.StatusBadRequest, "application/json", `{"error":{"message":"bad model"}}`), nil
+	}))
+
+	_, err := client.Chat(context.Background(), ChatRequest{Model: "m"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "bad model" {
+		t.Fatalf("Chat() error = %v, want APIError 400 bad model", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	t.Parallel()
+
+	client := New(Config{})
+	client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("request = %s, want stream enabled", body)
+		}
+		return textResponse(http.StatusOK, "text/event-stream", strings.Join([]string{
+			": keep-alive",
+			`data: {"choices":[{"delta":{"content":"开"}}]}`,
+			"",
+			`data: {"choices":[{"delta":{"content":"心"},"finish_reason":"stop"}]}`,
+			"data: not json",
+			`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+			"data: [DONE]",
+			"",
+		}, "\n")), nil
+	}))
+
+	var deltas []string
+	resp, err := client.ChatStream(context.Background(), ChatRequest{Model: "m"}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if resp.Content != "开心" || resp.FinishReason != "stop" || resp.Usage.TotalTokens != 5 || len(deltas) != 2 {
+		t.Fatalf("ChatStream() = %+v with deltas %v, want 开心 in two deltas", resp, deltas)
+	}
+}
@@ -81,6 +81,25 @@ func FromContext(ctx context.Context) *Logger {
 	return l
 }
 
+// DetachContext returns a context for work that outlives a request, such as a
+// background ingest job. It carries the logger of ctx, so request_id and other
+// fields stay on the job's logs, but none of ctx's cancellation, deadline or
+// other values.
+// Parameters:
+//   - ctx: request context to detach from.
+// Returns:
+//   - context.Context: uncancelable context holding ctx's logger.
+func DetachContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	if ctx == nil {
+		return detached
+	}
+	if l, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return l.WithContext(detached)
+	}
+	return detached
+}
+
 // ============================================
 // Context Field Injection
 // ============================================
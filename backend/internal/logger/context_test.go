@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDetachContextKeepsFieldsWithoutCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(SetRequestID(context.Background(), "req-1"))
+	detached := DetachContext(ctx)
+	cancel()
+
+	if err := detached.Err(); err != nil {
+		t.Fatalf("detached Err() = %v, want nil after parent cancel", err)
+	}
+	if got := GetRequestID(detached); got != "req-1" {
+		t.Fatalf("GetRequestID(detached) = %q, want req-1", got)
+	}
+	if got := GetRequestID(DetachContext(context.Background())); got != "" {
+		t.Fatalf("GetRequestID(detached background) = %q, want empty", got)
+	}
+}
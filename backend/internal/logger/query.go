@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+)
+
+// Query logging modes.
+const (
+	QueryLogPlain    = "plain"    // Log queries as typed
+	QueryLogHash     = "hash"     // Log a keyed hash; equal queries share it
+	QueryLogTruncate = "truncate" // Log the first runes only
+
+	defaultQueryLogMaxRunes = 8
+	queryLogHashChars       = 16
+)
+
+// QueryLogConfig controls how search query text appears in logs.
+type QueryLogConfig struct {
+	Mode     string // plain (default), hash or truncate
+	MaxRunes int    // Runes kept by truncate (default 8)
+	Salt     string // HMAC key of hash; without one, short queries can be found by hashing guesses
+}
+
+var queryLogConfig atomic.Pointer[QueryLogConfig]
+
+// SetQueryLogging sets how Query renders search queries for every logger.
+// Parameters:
+//   - cfg: query logging mode and options.
+//
+// Returns:
+//   - error: non-nil if the mode is unknown; the previous setting is kept.
+func SetQueryLogging(cfg QueryLogConfig) error {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = QueryLogPlain
+	case QueryLogPlain, QueryLogHash, QueryLogTruncate:
+	default:
+		return fmt.Errorf("unknown query logging mode %q (want plain, hash or truncate)", cfg.Mode)
+	}
+	if cfg.MaxRunes <= 0 {
+		cfg.MaxRunes = defaultQueryLogMaxRunes
+	}
+	queryLogConfig.Store(&cfg)
+	return nil
+}
+
+// Query returns search query text as it may be logged: unchanged, as a keyed
+// hash ("sha256:" and 16 hex digits) or cut to its first runes ("…" marks a
+// cut), per SetQueryLogging.
+// Parameters:
+//   - query: query, expansion or other text derived from a search.
+//
+// Returns:
+//   - string: loggable form of query.
+func Query(query string) string {
+	cfg := queryLogConfig.Load()
+	if cfg == nil || query == "" {
+		return query
+	}
+	switch cfg.Mode {
+	case QueryLogHash:
+		mac := hmac.New(sha256.New, []byte(cfg.Salt))
+		mac.Write([]byte(query))
+		return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:queryLogHashChars]
+	case QueryLogTruncate:
+		runes := []rune(query)
+		if len(runes) <= cfg.MaxRunes {
+			return query
+		}
+		return string(runes[:cfg.MaxRunes]) + "…"
+	default:
+		return query
+	}
+}
+
+// QueryParams returns a raw URL query string with the values of params passed
+// through Query, e.g. the query of GET /api/v1/search/stream.
+// Parameters:
+//   - rawQuery: URL query string without the leading "?".
+//   - params: names of the parameters carrying search text.
+//
+// Returns:
+//   - string: rawQuery, re-encoded when a value was changed.
+func QueryParams(rawQuery string, params ...string) string {
+	if cfg := queryLogConfig.Load(); cfg == nil || cfg.Mode == QueryLogPlain || rawQuery == "" {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "<unparsable query>"
+	}
+	changed := false
+	for _, param := range params {
+		for i, value := range values[param] {
+			values[param][i] = Query(value)
+			changed = true
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return values.Encode()
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestQueryLoggingModes changes the package-wide setting, so it does not run
+// in parallel.
+func TestQueryLoggingModes(t *testing.T) {
+	t.Cleanup(func() { queryLogConfig.Store(nil) })
+
+	if got := Query("无语的猫"); got != "无语的猫" {
+		t.Fatalf("Query() unconfigured = %q, want it unchanged", got)
+	}
+
+	if err := SetQueryLogging(QueryLogConfig{Mode: QueryLogHash, Salt: "pepper"}); err != nil {
+		t.Fatalf("SetQueryLogging(hash) error = %v", err)
+	}
+	hashed := Query("无语的猫")
+	if !strings.HasPrefix(hashed, "sha256:") || len(hashed) != len("sha256:")+queryLogHashChars || strings.Contains(hashed, "猫") {
+		t.Fatalf("Query() hashed = %q, want sha256: and %d hex digits", hashed, queryLogHashChars)
+	}
+	if Query("无语的猫") != hashed || Query("开心") == hashed {
+		t.Fatal("Query() hashes are not stable per query")
+	}
+	if got := QueryParams("query=%E6%97%A0%E8%AF%AD&top_k=5", "query"); strings.Contains(got, "%E6%97%A0") || !strings.Contains(got, "top_k=5") {
+		t.Fatalf("QueryParams() = %q, want the query hashed and top_k kept", got)
+	}
+
+	if err := SetQueryLogging(QueryLogConfig{Mode: QueryLogTruncate, MaxRunes: 2}); err != nil {
+		t.Fatalf("SetQueryLogging(truncate) error = %v", err)
+	}
+	if got := Query("无语的猫"); got != "无语…" {
+		t.Fatalf("Query() truncated = %q, want 无语…", got)
+	}
+	if got := Query("猫"); got != "猫" {
+		t.Fatalf("Query() short = %q, want it unchanged", got)
+	}
+
+	if err := SetQueryLogging(QueryLogConfig{Mode: "redact"}); err == nil {
+		t.Fatal("SetQueryLogging(redact) returned nil error")
+	}
+	if got := Query("无语的猫"); got != "无语…" {
+		t.Fatalf("Query() after a rejected mode = %q, want the previous setting kept", got)
+	}
+}
@@ -0,0 +1,125 @@
+// Package metrics records counters, gauges and timings through a pluggable
+// Sink, so services report metrics without depending on an exporter. The
+// package-level functions write to the sink set with SetSink and do nothing
+// until one is set.
+package metrics
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Sink names accepted by metrics.sink.
+const (
+	SinkNone    = "none"    // Discard metrics
+	SinkStatsD  = "statsd"  // Plain StatsD; tags are dropped
+	SinkDatadog = "datadog" // DogStatsD, with tags
+)
+
+// Sink receives metrics. Tags are "key:value" strings; sinks without tag
+// support drop them. Implementations must be safe for concurrent use and
+// must not block the caller on the network.
+type Sink interface {
+	// Count adds value to a counter.
+	Count(name string, value int64, tags ...string)
+	// Gauge sets a gauge to value.
+	Gauge(name string, value float64, tags ...string)
+	// Timing records a duration.
+	Timing(name string, d time.Duration, tags ...string)
+}
+
+// Config selects and configures the sink of metrics.sink.
+type Config struct {
+	Sink          string        // none (default), statsd or datadog
+	Address       string        // Agent UDP address (default 127.0.0.1:8125)
+	Prefix        string        // Prepended to every metric name
+	Tags          []string      // Added to every metric (datadog only)
+	FlushInterval time.Duration // How long metrics are buffered (default 1s)
+}
+
+// New creates the sink of a configuration.
+// Parameters:
+//   - cfg: sink name and its settings.
+//
+// Returns:
+//   - Sink: the sink; a *StatsD, which must be closed, for statsd and
+//     datadog, Nop for none.
+//   - error: non-nil if the sink is unknown or cannot be created.
+func New(cfg Config) (Sink, error) {
+	switch cfg.Sink {
+	case "", SinkNone:
+		return Nop{}, nil
+	case SinkStatsD, SinkDatadog:
+		return NewStatsD(StatsDConfig{
+			Address:       cfg.Address,
+			Prefix:        cfg.Prefix,
+			Tags:          cfg.Tags,
+			Datadog:       cfg.Sink == SinkDatadog,
+			FlushInterval: cfg.FlushInterval,
+		})
+	default:
+		return nil, fmt.Errorf("unknown metrics sink %q (want none, statsd or datadog)", cfg.Sink)
+	}
+}
+
+// Nop is a Sink that discards every metric.
+type Nop struct{}
+
+// Count discards the counter.
+func (Nop) Count(string, int64, ...string) {}
+
+// Gauge discards the gauge.
+func (Nop) Gauge(string, float64, ...string) {}
+
+// Timing discards the timing.
+func (Nop) Timing(string, time.Duration, ...string) {}
+
+// sinkHolder wraps the current sink for atomic.Pointer.
+type sinkHolder struct{ sink Sink }
+
+var current atomic.Pointer[sinkHolder]
+
+// SetSink sets the sink of the package-level functions.
+// Parameters:
+//   - sink: sink to write to (nil discards metrics).
+//
+// Returns: none.
+func SetSink(sink Sink) {
+	if sink == nil {
+		sink = Nop{}
+	}
+	current.Store(&sinkHolder{sink: sink})
+}
+
+// Count adds value to a counter of the current sink.
+func Count(name string, value int64, tags ...string) {
+	if h := current.Load(); h != nil {
+		h.sink.Count(name, value, tags...)
+	}
+}
+
+// Gauge sets a gauge of the current sink.
+func Gauge(name string, value float64, tags ...string) {
+	if h := current.Load(); h != nil {
+		h.sink.Gauge(name, value, tags...)
+	}
+}
+
+// Timing records a duration in the current sink.
+func Timing(name string, d time.Duration, tags ...string) {
+	if h := current.Load(); h != nil {
+		h.sink.Timing(name, d, tags...)
+	}
+}
+
+// Tag formats a "key:value" tag.
+// Parameters:
+//   - key: tag name.
+//   - value: tag value; formatted with %v.
+//
+// Returns:
+//   - string: the tag.
+func Tag(key string, value any) string {
+	return fmt.Sprintf("%s:%v", key, value)
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultStatsDAddress       = "127.0.0.1:8125"
+	defaultStatsDFlushInterval = time.Second
+	// statsDMaxPacket keeps packets under the usual 1500-byte MTU.
+	statsDMaxPacket = 1432
+)
+
+// StatsDConfig holds the settings of a StatsD or DogStatsD sink.
+type StatsDConfig struct {
+	Address       string        // Agent UDP address (default 127.0.0.1:8125)
+	Prefix        string        // Prepended to every metric name, e.g. "emomo."
+	Tags          []string      // Added to every metric (Datadog only)
+	Datadog       bool          // Send DogStatsD tags; plain StatsD drops them
+	FlushInterval time.Duration // How long metrics are buffered (default 1s)
+}
+
+// StatsD is a Sink that sends metrics to a StatsD server or Datadog agent
+// over UDP. Lines are buffered into packets and sent when a packet is full or
+// every flush interval; send errors drop the packet, as StatsD over UDP does.
+type StatsD struct {
+	conn    net.Conn
+	prefix  string
+	tags    []string
+	datadog bool
+
+	mu  sync.Mutex
+	buf []byte
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewStatsD creates a StatsD sink and starts its flush loop.
+// Parameters:
+//   - cfg: agent address, metric prefix, tags and flush interval.
+//
+// Returns:
+//   - *StatsD: sink that sends until Close.
+//   - error: non-nil if the address cannot be resolved.
+func NewStatsD(cfg StatsDConfig) (*StatsD, error) {
+	address := cfg.Address
+	if address == "" {
+		address = defaultStatsDAddress
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open statsd connection: %w", err)
+	}
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = defaultStatsDFlushInterval
+	}
+
+	s := &StatsD{
+		conn:    conn,
+		prefix:  cfg.Prefix,
+		tags:    cfg.Tags,
+		datadog: cfg.Datadog,
+		buf:     make([]byte, 0, statsDMaxPacket),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.loop(interval)
+	return s, nil
+}
+
+// Count adds value to a counter.
+func (s *StatsD) Count(name string, value int64, tags ...string) {
+	s.write(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Gauge sets a gauge to value.
+func (s *StatsD) Gauge(name string, value float64, tags ...string) {
+	s.write(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+// Timing records a duration in milliseconds.
+func (s *StatsD) Timing(name string, d time.Duration, tags ...string) {
+	ms := float64(d) / float64(time.Millisecond)
+	s.write(name, strconv.FormatFloat(ms, 'f', 3, 64), "ms", tags)
+}
+
+// Close sends buffered metrics and closes the connection.
+// Parameters: none.
+//
+// Returns:
+//   - error: non-nil if the connection cannot be closed.
+func (s *StatsD) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return s.conn.Close()
+}
+
+// write buffers one line, sending the buffer first when the line would not
+// fit in the packet.
+func (s *StatsD) write(name, value, kind string, tags []string) {
+	line := s.line(name, value, kind, tags)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buf) > 0 && len(s.buf)+1+len(line) > statsDMaxPacket {
+		s.flushLocked()
+	}
+	if len(s.buf) > 0 {
+		s.buf = append(s.buf, '\n')
+	}
+	s.buf = append(s.buf, line...)
+}
+
+// line formats name:value|kind, with |#tags for Datadog.
+func (s *StatsD) line(name, value, kind string, tags []string) string {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(sanitizeName(name))
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if s.datadog && len(s.tags)+len(tags) > 0 {
+		b.WriteString("|#")
+		for i, tag := range append(append([]string(nil), s.tags...), tags...) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(sanitizeTag(tag))
+		}
+	}
+	return b.String()
+}
+
+// loop sends the buffer every interval until Close.
+func (s *StatsD) loop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush sends the buffered lines.
+func (s *StatsD) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+}
+
+// flushLocked sends the buffered lines; s.mu must be held.
+func (s *StatsD) flushLocked() {
+	if len(s.buf) == 0 {
+		return
+	}
+	// A lost packet is a gap in the metrics; nothing is logged, since every
+	// flush would repeat the message while the agent is down.
+	_, _ = s.conn.Write(s.buf)
+	s.buf = s.buf[:0]
+}
+
+// sanitizeName replaces the characters StatsD uses as separators.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', '\n', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// sanitizeTag replaces the characters DogStatsD uses to separate tags.
+func sanitizeTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', '#', ',', '\n', ' ':
+			return '_'
+		}
+		return r
+	}, tag)
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readPacket(t *testing.T, conn *net.UDPConn) string {
+	t.Helper()
+
+	buf := make([]byte, 2*statsDMaxPacket)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestStatsDFormatsDatadogLines(t *testing.T) {
+	t.Parallel()
+
+	server := listenUDP(t)
+	sink, err := NewStatsD(StatsDConfig{
+		Address:       server.LocalAddr().String(),
+		Prefix:        "emomo.",
+		Tags:          []string{"env:test"},
+		Datadog:       true,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewStatsD() error = %v", err)
+	}
+
+	sink.Count("http.requests", 2, Tag("route", "/api/v1/search"), Tag("status", 200))
+	sink.Gauge("db.pool.in_use", 3)
+	sink.Timing("search.duration", 1500*time.Microsecond, "route:short query")
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"emomo.http.requests:2|c|#env:test,route:/api/v1/search,status:200",
+		"emomo.db.pool.in_use:3|g|#env:test",
+		"emomo.search.duration:1.500|ms|#env:test,route:short_query",
+	}, "\n")
+	if got := readPacket(t, server); got != want {
+		t.Fatalf("packet = %q, want %q", got, want)
+	}
+}
+
+func TestStatsDSplitsPacketsAndDropsTags(t *testing.T) {
+	t.Parallel()
+
+	server := listenUDP(t)
+	sink, err := NewStatsD(StatsDConfig{Address: server.LocalAddr().String(), FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewStatsD() error = %v", err)
+	}
+	defer sink.Close()
+
+	line := "search.cache.hit:1|c"
+	lines := statsDMaxPacket/(len(line)+1) + 1
+	for i := 0; i < lines; i++ {
+		sink.Count("search.cache.hit", 1, "cached:true")
+	}
+
+	packet := readPacket(t, server)
+	if len(packet) > statsDMaxPacket {
+		t.Fatalf("packet length = %d, want at most %d", len(packet), statsDMaxPacket)
+	}
+	for _, got := range strings.Split(packet, "\n") {
+		if got != line {
+			t.Fatalf("line = %q, want %q", got, line)
+		}
+	}
+}
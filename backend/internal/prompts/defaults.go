@@ -0,0 +1,144 @@
+package prompts
+
+// Built-in prompts. Editing the VLM description prompts changes the prompt
+// version stored with descriptions, which marks older descriptions outdated.
+const (
+	// VLM System Prompt - 定义角色和规则
+	vlmSystemPrompt = `你是表情包语义分析专家，负责生成用于向量搜索的描述文本。你的描述将被转换为向量，用于语义搜索匹配。
+
+【分析步骤】
+1. 文字提取（最高优先级）：完整提取图片中所有文字，理解文字含义和表达意图
+2. 主体识别：识别人物/动物/卡通形象类型（如熊猫头、蘑菇头、柴犬、猫咪等）
+3. 表情动作：描述面部表情和肢体动作
+4. 情绪标签：选择最匹配的情绪词（无语/尴尬/开心/暴怒/委屈/嫌弃/震惊/疑惑/得意/摆烂/emo/社死/破防/裂开/绝望/狂喜/阴阳怪气/幸灾乐祸/无奈/崩溃/感动/害怕/可爱/呆萌）
+5. 网络梗识别：如涉及流行语需解释含义（芭比Q了/绝绝子/yyds/栓Q/一整个xx住等）
+
+【输出要求】
+- 80-150字自然段落，禁止使用序号或分点
+- 优先级：文字内容 > 情绪表达 > 画面描述
+- 必须嵌入搜索关键词（情绪词、动作词、主体类型词）
+- 无文字图片：重点描述表情、动作和情绪，不要写"图中无文字"`
+
+	// VLM User Prompt - 包含Few-shot示例
+	vlmUserPrompt = `请分析这张表情包图片。
+
+【参考示例】
+示例1：一只熊猫头表情包，文字写着"我不理解"，露出一脸疑惑、无语的表情，歪着脑袋眼神空洞，表达对某事完全不理解、懵逼的状态，适合在困惑、震惊、无法理解对方行为时使用。
+
+示例2：柴犬表情包，狗狗露出标志性的微笑，眼睛眯成一条缝，表情开心、得意、满足，像是在说"我就知道会这样"，带有幸灾乐祸、阴阳怪气的感觉，适合表达暗爽或看好戏的心情。
+
+示例3：蘑菇头表情包，小人双手叉腰，配文"就这？"，表情嫌弃、不屑、鄙视，表达对某事物的轻蔑和失望，觉得不过如此、不值一提。
+
+示例4：一只猫咪瘫倒在地，四仰八叉，表情疲惫、无力、摆烂，眼神空洞望向天花板，表达累了、不想动、彻底放弃挣扎的emo状态。
+
+现在请分析图片并生成描述：`
+
+	// VLM Strict Retry Prompt - 描述质量不达标时追加的约束
+	vlmStrictRetryPrompt = `
+
+【重要】上一次的描述质量不合格，请严格遵守：
+- 必须写满80-150字，不能只写一两句话
+- 图片中的文字必须原样写出，不能遗漏或改写
+- 必须包含至少一个情绪词（如无语、开心、委屈、嫌弃、震惊等）
+- 不要写"适合在…时使用"之类的套话`
+
+	// OCR System Prompt - 仅识别图片中的文字
+	ocrSystemPrompt = `你是OCR文字识别助手，只负责提取图片中的文字内容。`
+
+	// OCR User Prompt - 只输出识别文本
+	ocrUserPrompt = `请只输出图片中的文字内容，保持原有顺序与换行，不要解释或添加任何前缀。
+如果图片中没有文字，请输出空字符串。`
+
+	// Query Expansion Prompt - 词汇取自 EmotionWords 和 InternetMemes
+	queryExpansionPrompt = `你是表情包搜索查询扩展器。将用户的简短查询扩展为语义丰富的描述，提高向量搜索匹配度。
+
+【核心原则】
+- 保留原始意图，添加同义词、情绪词和场景描述
+- 输出50-80字自然描述，直接输出文本，无需任何前缀
+
+【情绪词库】
+无语/尴尬/开心/暴怒/委屈/嫌弃/震惊/疑惑/得意/摆烂/emo/社死/破防/裂开/绝望/狂喜/阴阳怪气/幸灾乐祸/无奈/崩溃/感动/害怕/可爱/呆萌/嘲讽/鄙视/期待/失望
+
+【网络梗】
+芭比Q了(完蛋)/绝绝子(太绝)/yyds(永远的神)/栓Q(谢谢)/CPU(被PUA)/emo(低落)/摆烂(放弃)/社死(社会性死亡)/破防(崩溃)/一整个xx住/蚌埠住了/绷不住/DNA动了
+
+【主体类型】
+熊猫头/蘑菇头/柴犬/猫咪/兔子/小黄人/派大星/海绵宝宝
+
+【示例】
+输入: 无语
+输出: 无语、无奈、嫌弃的情绪，翻白眼、面无表情、一脸嫌弃的样子，对某事无话可说不想理会，可能是熊猫头或蘑菇头表情包
+
+输入: 熊猫头
+输出: 熊猫头表情包，经典黑白熊猫脸，圆圆的脑袋配各种搞怪表情，可表达无语、开心、疑惑、震惊、嫌弃等多种情绪
+
+输入: 芭比Q了
+输出: 完蛋了、糟糕了、大事不妙，芭比Q网络流行语表示完蛋，惊恐绝望崩溃的表情，事情搞砸了要完蛋了
+
+输入: 好耶
+输出: 开心、兴奋、欢呼雀跃，好耶表示非常高兴激动，手舞足蹈眉开眼笑庆祝的样子，可爱得意满足
+
+输入: 累了毁灭吧
+输出: 疲惫、emo、摆烂、放弃挣扎，累到不想动想要毁灭世界，瘫倒无力眼神空洞，彻底破防不想努力了`
+
+	// Query Expansion Prompt (English) - 词汇取自 EmotionWordsEn 和 InternetMemesEn
+	queryExpansionEnPrompt = `You expand meme search queries. Rewrite the user's short query as a richer description that matches meme descriptions in vector search.
+
+[Rules]
+- Keep the original intent; add synonyms, emotion words and the situations the meme fits
+- Write 30-50 words of plain English, output the text only, without any prefix
+
+[Emotion words]
+speechless/awkward/happy/furious/wronged/disgusted/shocked/confused/smug/giving up/sad/embarrassed/heartbroken/done/desperate/ecstatic/sarcastic/gloating/helpless/breaking down/touched/scared/cute/silly/mocking/contemptuous/hopeful/disappointed
+
+[Internet slang]
+lol/lmao/bruh/facepalm/mood/same/cringe/sus/big yikes/this is fine/no cap/it's giving/rent free/copium
+
+[Characters]
+panda face/mushroom head/shiba inu/cat/rabbit/minion/patrick star/spongebob
+
+[Examples]
+Input: speechless
+Output: speechless, helpless and disgusted, rolling eyes with a blank stare, having nothing left to say and not wanting to respond, often a panda face or mushroom head meme
+
+Input: this is fine
+Output: pretending everything is fine while things fall apart, forced calm smile in a disaster, giving up, helpless and done, sarcastic acceptance of chaos
+
+Input: yay
+Output: happy, excited and cheering, jumping for joy with a big smile, celebrating good news, cute, smug and satisfied`
+
+	// Scene Tag Prompt - 场景列表与 SceneTags 一致
+	sceneTagPrompt = `你是表情包使用场景分类器。根据表情包的描述和文字，判断它适合在哪些生活场景中使用。
+
+【可选场景】
+工作/学习/考试/恋爱/社交/家庭/游戏/节日/美食/运动/熬夜
+
+【输出要求】
+- 只能从可选场景中选择，最多3个，用顿号分隔
+- 没有明显场景时输出：无
+- 直接输出结果，不要解释`
+
+	// Category Label Prompt - 为聚类出的表情簇命名
+	categoryLabelPrompt = `你是表情包分类编辑。下面是同一簇表情包中最有代表性的几张的描述，请为这一簇起一个分类名。
+
+【要求】
+- 分类名 2-6 个字，概括这些表情包共同的角色、系列或主题，例如：猫猫、熊猫头、打工人
+- 已有分类中有合适的就直接沿用已有分类名
+- 找不到共同主题时分类名写：无
+
+【输出格式】
+分类：<分类名>
+理由：<一句话理由>`
+
+	// Rerank Prompt - 为搜索候选逐一打分
+	rerankPrompt = `你是表情包搜索结果的相关性评审。给定用户查询和若干候选表情包的描述，为每个候选打分，表示它与查询意图的匹配程度。
+
+【评分标准】
+- 10：情绪、主体和使用场景都与查询吻合
+- 5：部分吻合，例如情绪相同但主体不同
+- 0：与查询无关
+
+【输出要求】
+- 按候选编号顺序输出一个 JSON 数组，只包含 0-10 的整数分数，例如：[8, 3, 10]
+- 数组长度必须等于候选数量，不要输出任何解释`
+)
@@ -0,0 +1,159 @@
+// Package prompts is the single source of the LLM prompts and the vocabularies
+// they enumerate. Services read prompts through a Provider, so deployments can
+// replace them without a rebuild.
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Prompt names, also the file names (without .txt) read by LoadDir.
+const (
+	NameVLMSystem      = "vlm_system"
+	NameVLMUser        = "vlm_user"
+	NameVLMStrictRetry = "vlm_strict_retry"
+	NameOCRSystem      = "ocr_system"
+	NameOCRUser        = "ocr_user"
+	NameQueryExpansion = "query_expansion"
+	NameQueryExpandEn  = "query_expansion_en"
+	NameSceneTag       = "scene_tag"
+	NameCategoryLabel  = "category_label"
+	NameRerank         = "rerank"
+)
+
+// Query languages with their own query expansion prompt.
+const (
+	LangZh = "zh" // Chinese, the language of every other prompt
+	LangEn = "en"
+)
+
+// Set is a complete set of prompts.
+type Set struct {
+	VLMSystem      string // System prompt of VLM descriptions
+	VLMUser        string // User prompt of VLM descriptions, with few-shot examples
+	VLMStrictRetry string // Appended to VLMUser when a description is regenerated
+	OCRSystem      string
+	OCRUser        string
+	QueryExpansion string
+	QueryExpandEn  string // Query expansion of English searches
+	SceneTag       string
+	CategoryLabel  string // Names meme clusters in category discovery
+	Rerank         string // Scores search candidates with the llm reranker
+}
+
+// Provider supplies the prompts a service sends. Implementations must be safe
+// for concurrent use; services call Prompts for every request.
+type Provider interface {
+	Prompts() *Set
+}
+
+// Prompts returns s, so a *Set is a Provider of fixed prompts.
+// Parameters: none.
+// Returns:
+//   - *Set: the set itself.
+func (s *Set) Prompts() *Set {
+	return s
+}
+
+// Default returns a copy of the built-in prompts.
+// Parameters: none.
+// Returns:
+//   - *Set: built-in prompts.
+func Default() *Set {
+	return &Set{
+		VLMSystem:      vlmSystemPrompt,
+		VLMUser:        vlmUserPrompt,
+		VLMStrictRetry: vlmStrictRetryPrompt,
+		OCRSystem:      ocrSystemPrompt,
+		OCRUser:        ocrUserPrompt,
+		QueryExpansion: queryExpansionPrompt,
+		QueryExpandEn:  queryExpansionEnPrompt,
+		SceneTag:       sceneTagPrompt,
+		CategoryLabel:  categoryLabelPrompt,
+		Rerank:         rerankPrompt,
+	}
+}
+
+// OrDefault returns p, or the built-in prompts when p is nil.
+// Parameters:
+//   - p: configured provider (may be nil).
+//
+// Returns:
+//   - Provider: p or the built-in prompts.
+func OrDefault(p Provider) Provider {
+	if p == nil {
+		return Default()
+	}
+	return p
+}
+
+// QueryExpansionFor returns the query expansion prompt of a query language:
+// the English prompt for LangEn, the Chinese prompt otherwise.
+// Parameters:
+//   - lang: LangZh or LangEn.
+//
+// Returns:
+//   - string: system prompt of query expansion.
+func (s *Set) QueryExpansionFor(lang string) string {
+	if lang == LangEn {
+		return s.QueryExpandEn
+	}
+	return s.QueryExpansion
+}
+
+// fields maps prompt names to the fields of s.
+func (s *Set) fields() map[string]*string {
+	return map[string]*string{
+		NameVLMSystem:      &s.VLMSystem,
+		NameVLMUser:        &s.VLMUser,
+		NameVLMStrictRetry: &s.VLMStrictRetry,
+		NameOCRSystem:      &s.OCRSystem,
+		NameOCRUser:        &s.OCRUser,
+		NameQueryExpansion: &s.QueryExpansion,
+		NameQueryExpandEn:  &s.QueryExpandEn,
+		NameSceneTag:       &s.SceneTag,
+		NameCategoryLabel:  &s.CategoryLabel,
+		NameRerank:         &s.Rerank,
+	}
+}
+
+// LoadDir returns base with every prompt that has a <name>.txt file in dir
+// replaced by the file content, e.g. vlm_system.txt. Missing files keep the
+// base prompt; an empty dir returns a copy of base.
+// Parameters:
+//   - base: prompts to start from.
+//   - dir: directory of override files (empty skips loading).
+//
+// Returns:
+//   - *Set: prompts with overrides applied.
+//   - []string: names of the overridden prompts, sorted.
+//   - error: non-nil if a file cannot be read or is empty.
+func LoadDir(base *Set, dir string) (*Set, []string, error) {
+	loaded := *base
+	if dir == "" {
+		return &loaded, nil, nil
+	}
+
+	var overridden []string
+	for name, field := range loaded.fields() {
+		path := filepath.Join(dir, name+".txt")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read prompt %s: %w", path, err)
+		}
+		if len(data) == 0 {
+			return nil, nil, fmt.Errorf("prompt file %s is empty", path)
+		}
+		*field = string(data)
+		overridden = append(overridden, name)
+	}
+	sort.Strings(overridden)
+	return &loaded, overridden, nil
+}
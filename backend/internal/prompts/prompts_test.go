@@ -0,0 +1,94 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// promptList returns the "/"-separated words following marker in prompt, up
+// to the end of the line or a closing parenthesis.
+func promptList(t *testing.T, prompt, marker string) []string {
+	t.Helper()
+
+	m := regexp.MustCompile(regexp.QuoteMeta(marker) + `\n?([^\n）]+)`).FindStringSubmatch(prompt)
+	if m == nil {
+		t.Fatalf("prompt has no list after %q", marker)
+	}
+	return strings.Split(m[1], "/")
+}
+
+func assertInLexicon(t *testing.T, name string, words, lexicon []string) {
+	t.Helper()
+
+	known := make(map[string]bool, len(lexicon))
+	for _, word := range lexicon {
+		known[word] = true
+	}
+	for _, word := range words {
+		if !known[word] {
+			t.Errorf("%s lists %q, which is missing from the lexicon", name, word)
+		}
+	}
+}
+
+func TestPromptVocabulariesMatchLexicons(t *testing.T) {
+	t.Parallel()
+
+	p := Default()
+	assertInLexicon(t, NameVLMSystem, promptList(t, p.VLMSystem, "选择最匹配的情绪词（"), EmotionWords)
+	assertInLexicon(t, NameQueryExpansion, promptList(t, p.QueryExpansion, "【情绪词库】"), EmotionWords)
+	assertInLexicon(t, NameQueryExpandEn, promptList(t, p.QueryExpandEn, "[Emotion words]"), EmotionWordsEn)
+	assertInLexicon(t, NameQueryExpandEn, promptList(t, p.QueryExpandEn, "[Internet slang]"), InternetMemesEn)
+	if p.QueryExpansionFor(LangEn) != p.QueryExpandEn || p.QueryExpansionFor(LangZh) != p.QueryExpansion {
+		t.Fatal("QueryExpansionFor() does not pick the prompt of the language")
+	}
+
+	if got := promptList(t, p.SceneTag, "【可选场景】"); !reflect.DeepEqual(got, SceneTags) {
+		t.Fatalf("%s scenes = %v, want SceneTags %v", NameSceneTag, got, SceneTags)
+	}
+}
+
+func TestDefaultSetsEveryPrompt(t *testing.T) {
+	t.Parallel()
+
+	for name, field := range Default().fields() {
+		if strings.TrimSpace(*field) == "" {
+			t.Errorf("built-in prompt %s is empty", name)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, NameSceneTag+".txt"), []byte("自定义场景提示"), 0o644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	base := Default()
+	loaded, overridden, err := LoadDir(base, dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if loaded.SceneTag != "自定义场景提示" || loaded.VLMSystem != base.VLMSystem {
+		t.Fatalf("LoadDir() = %+v, want only the scene tag prompt replaced", loaded)
+	}
+	if !reflect.DeepEqual(overridden, []string{NameSceneTag}) {
+		t.Fatalf("overridden = %v, want [%s]", overridden, NameSceneTag)
+	}
+	if base.SceneTag == loaded.SceneTag {
+		t.Fatal("LoadDir() modified the base set")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, NameOCRUser+".txt"), nil, 0o644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+	if _, _, err := LoadDir(base, dir); err == nil {
+		t.Fatal("LoadDir() with an empty prompt file succeeded, want error")
+	}
+}
@@ -0,0 +1,37 @@
+package prompts
+
+// EmotionWords is the emotion lexicon shared by the VLM and query expansion
+// prompts, keyword extraction and query routing.
+var EmotionWords = []string{
+	"无语", "尴尬", "开心", "暴怒", "委屈", "嫌弃", "震惊", "疑惑", "得意", "摆烂",
+	"emo", "社死", "破防", "裂开", "绝望", "狂喜", "阴阳怪气", "幸灾乐祸", "无奈", "崩溃",
+	"感动", "害怕", "可爱", "呆萌", "嘲讽", "鄙视", "期待", "失望", "愤怒", "悲伤",
+}
+
+// InternetMemes is the meme-slang lexicon shared by the VLM and query
+// expansion prompts and query routing.
+var InternetMemes = []string{
+	"芭比Q了(完蛋了)", "绝绝子(太绝了)", "yyds(永远的神)", "真的栓Q(真的谢谢)",
+	"CPU(被PUA)", "一整个xx住", "xx子", "我不理解", "好耶", "啊这", "6",
+	"笑死", "裂开", "麻了", "蚌埠住了", "绷不住了", "DNA动了",
+}
+
+// EmotionWordsEn is the emotion lexicon of the English query expansion
+// prompt.
+var EmotionWordsEn = []string{
+	"speechless", "awkward", "happy", "furious", "wronged", "disgusted", "shocked", "confused", "smug", "giving up",
+	"sad", "embarrassed", "heartbroken", "done", "desperate", "ecstatic", "sarcastic", "gloating", "helpless", "breaking down",
+	"touched", "scared", "cute", "silly", "mocking", "contemptuous", "hopeful", "disappointed", "angry", "lonely",
+}
+
+// InternetMemesEn is the slang lexicon of the English query expansion prompt.
+var InternetMemesEn = []string{
+	"lol", "lmao", "bruh", "facepalm", "mood", "same", "cringe", "sus", "big yikes",
+	"this is fine", "no cap", "it's giving", "rent free", "copium",
+}
+
+// SceneTags is the closed vocabulary of usage scenes a meme can be tagged with.
+// Tags are stored as structured payload, separate from the free-text description.
+var SceneTags = []string{
+	"工作", "学习", "考试", "恋爱", "社交", "家庭", "游戏", "节日", "美食", "运动", "熬夜",
+}
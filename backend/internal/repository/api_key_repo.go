@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository handles API keys created through the admin API.
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *APIKeyRepository: repository instance bound to db.
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create inserts a new API key.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - key: key to insert.
+//
+// Returns:
+//   - error: non-nil if the insert fails.
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByHash returns the key whose secret hashes to keyHash.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyHash: hex SHA-256 of the secret.
+//
+// Returns:
+//   - *domain.APIKey: the key, revoked or not.
+//   - error: gorm.ErrRecordNotFound if no key matches.
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByID returns a key by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: key ID.
+//
+// Returns:
+//   - *domain.APIKey: the key, revoked or not.
+//   - error: gorm.ErrRecordNotFound if the key does not exist.
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List returns keys, newest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - includeRevoked: whether revoked keys are listed too.
+//
+// Returns:
+//   - []domain.APIKey: the keys.
+//   - error: non-nil if the query fails.
+func (r *APIKeyRepository) List(ctx context.Context, includeRevoked bool) ([]domain.APIKey, error) {
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if !includeRevoked {
+		query = query.Where("revoked_at IS NULL")
+	}
+	var keys []domain.APIKey
+	err := query.Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marks a key revoked at the given time; revoking a revoked key keeps
+// the first time.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: key ID.
+//   - at: revocation time.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records when a key was last used.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: key ID.
+//   - at: time of use.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIKeyUsageRepository handles monthly API key usage counters.
+type APIKeyUsageRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyUsageRepository creates a new APIKeyUsageRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *APIKeyUsageRepository: repository instance bound to db.
+func NewAPIKeyUsageRepository(db *gorm.DB) *APIKeyUsageRepository {
+	return &APIKeyUsageRepository{db: db}
+}
+
+// Add increments the counters of delta.KeyID in delta.Month, creating the row
+// on first use.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - delta: amounts to add.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *APIKeyUsageRepository) Add(ctx context.Context, delta *domain.APIKeyUsage) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key_id"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests":         gorm.Expr("api_key_usage.requests + excluded.requests"),
+			"llm_tokens":       gorm.Expr("api_key_usage.llm_tokens + excluded.llm_tokens"),
+			"embedding_tokens": gorm.Expr("api_key_usage.embedding_tokens + excluded.embedding_tokens"),
+			"cost":             gorm.Expr("api_key_usage.cost + excluded.cost"),
+			"updated_at":       gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(delta).Error
+}
+
+// Get returns the usage of a key in a month, zero when the key was not used.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: API key ID.
+//   - month: month as YYYY-MM.
+//
+// Returns:
+//   - *domain.APIKeyUsage: usage of the month.
+//   - error: non-nil if the query fails.
+func (r *APIKeyUsageRepository) Get(ctx context.Context, keyID, month string) (*domain.APIKeyUsage, error) {
+	var row domain.APIKeyUsage
+	err := r.db.WithContext(ctx).Where("key_id = ? AND month = ?", keyID, month).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &domain.APIKeyUsage{KeyID: keyID, Month: month}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// ListByKey returns the monthly usage of a key, most recent month first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: API key ID.
+//   - limit: maximum number of months to return.
+//
+// Returns:
+//   - []domain.APIKeyUsage: monthly usage rows.
+//   - error: non-nil if the query fails.
+func (r *APIKeyUsageRepository) ListByKey(ctx context.Context, keyID string, limit int) ([]domain.APIKeyUsage, error) {
+	var rows []domain.APIKeyUsage
+	err := r.db.WithContext(ctx).
+		Where("key_id = ?", keyID).
+		Order("month DESC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
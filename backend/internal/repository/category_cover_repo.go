@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CategoryCoverRepository handles the cover memes pinned to categories.
+type CategoryCoverRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryCoverRepository creates a new CategoryCoverRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *CategoryCoverRepository: repository instance bound to db.
+func NewCategoryCoverRepository(db *gorm.DB) *CategoryCoverRepository {
+	return &CategoryCoverRepository{db: db}
+}
+
+// Upsert pins a cover, replacing the category's previous cover.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - cover: category and meme to pin.
+//
+// Returns:
+//   - error: non-nil if the write fails.
+func (r *CategoryCoverRepository) Upsert(ctx context.Context, cover *domain.CategoryCover) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"meme_id", "updated_at"}),
+	}).Create(cover).Error
+}
+
+// Delete removes the cover of a category.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - category: category name.
+//
+// Returns:
+//   - bool: whether the category had a cover.
+//   - error: non-nil if the delete fails.
+func (r *CategoryCoverRepository) Delete(ctx context.Context, category string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("category = ?", category).Delete(&domain.CategoryCover{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// List retrieves every pinned cover.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - []domain.CategoryCover: covers ordered by category.
+//   - error: non-nil if the query fails.
+func (r *CategoryCoverRepository) List(ctx context.Context) ([]domain.CategoryCover, error) {
+	var covers []domain.CategoryCover
+	err := r.db.WithContext(ctx).Order("category").Find(&covers).Error
+	return covers, err
+}
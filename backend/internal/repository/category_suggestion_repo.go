@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+)
+
+// CategorySuggestionRepository handles the category suggestion review queue.
+type CategorySuggestionRepository struct {
+	db *gorm.DB
+}
+
+// NewCategorySuggestionRepository creates a new CategorySuggestionRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *CategorySuggestionRepository: repository instance bound to db.
+func NewCategorySuggestionRepository(db *gorm.DB) *CategorySuggestionRepository {
+	return &CategorySuggestionRepository{db: db}
+}
+
+// CreateBatch inserts suggestions.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - suggestions: suggestions to persist.
+//
+// Returns:
+//   - error: non-nil if the insert fails.
+func (r *CategorySuggestionRepository) CreateBatch(ctx context.Context, suggestions []domain.CategorySuggestion) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&suggestions).Error
+}
+
+// List retrieves suggestions, largest clusters of the newest run first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - status: review status to filter by (empty lists all).
+//   - limit: maximum number of suggestions to return.
+//   - offset: number of suggestions to skip.
+//
+// Returns:
+//   - []domain.CategorySuggestion: suggestions.
+//   - error: non-nil if the query fails.
+func (r *CategorySuggestionRepository) List(ctx context.Context, status domain.SuggestionStatus, limit, offset int) ([]domain.CategorySuggestion, error) {
+	var suggestions []domain.CategorySuggestion
+	err := r.filter(ctx, status).
+		Order("created_at DESC").
+		Order("size DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&suggestions).Error
+	return suggestions, err
+}
+
+// Count returns the number of suggestions with the given status.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - status: review status to filter by (empty counts all).
+//
+// Returns:
+//   - int64: number of suggestions.
+//   - error: non-nil if the query fails.
+func (r *CategorySuggestionRepository) Count(ctx context.Context, status domain.SuggestionStatus) (int64, error) {
+	var count int64
+	err := r.filter(ctx, status).Count(&count).Error
+	return count, err
+}
+
+// GetByID retrieves a suggestion by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: suggestion ID.
+//
+// Returns:
+//   - *domain.CategorySuggestion: the suggestion.
+//   - error: gorm.ErrRecordNotFound if it does not exist, or a query error.
+func (r *CategorySuggestionRepository) GetByID(ctx context.Context, id string) (*domain.CategorySuggestion, error) {
+	var suggestion domain.CategorySuggestion
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&suggestion).Error; err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+// UpdateStatus records the review decision of a suggestion.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - suggestion: suggestion carrying the new status.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *CategorySuggestionRepository) UpdateStatus(ctx context.Context, suggestion *domain.CategorySuggestion) error {
+	suggestion.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.CategorySuggestion{}).
+		Where("id = ?", suggestion.ID).
+		Updates(map[string]interface{}{
+			"status":     suggestion.Status,
+			"updated_at": suggestion.UpdatedAt,
+		}).Error
+}
+
+func (r *CategorySuggestionRepository) filter(ctx context.Context, status domain.SuggestionStatus) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&domain.CategorySuggestion{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return query
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DataSourceRepository handles data source sync state.
+type DataSourceRepository struct {
+	db *gorm.DB
+}
+
+// NewDataSourceRepository creates a new DataSourceRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *DataSourceRepository: repository instance bound to db.
+func NewDataSourceRepository(db *gorm.DB) *DataSourceRepository {
+	return &DataSourceRepository{db: db}
+}
+
+// GetByID retrieves a data source record by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: source identifier.
+//
+// Returns:
+//   - *domain.DataSource: matching record.
+//   - error: gorm.ErrRecordNotFound if the source has never synced.
+func (r *DataSourceRepository) GetByID(ctx context.Context, id string) (*domain.DataSource, error) {
+	var ds domain.DataSource
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&ds).Error; err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}
+
+// MarkSynced records a completed sync, creating the source record on first use.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: source identifier.
+//   - name: human-readable source name.
+//   - sourceType: kind of source (static or api).
+//   - syncedAt: time the sync finished.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *DataSourceRepository) MarkSynced(ctx context.Context, id, name string, sourceType domain.SourceType, syncedAt time.Time) error {
+	ds := &domain.DataSource{
+		ID:         id,
+		Name:       name,
+		Type:       sourceType,
+		LastSyncAt: &syncedAt,
+		IsEnabled:  true,
+		CreatedAt:  syncedAt,
+		UpdatedAt:  syncedAt,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "last_sync_at", "updated_at"}),
+	}).Create(ds).Error
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/timmy/emomo/internal/config"
 	"github.com/timmy/emomo/internal/domain"
@@ -66,9 +68,21 @@ func InitDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 			&domain.MemeDescription{},
 			&domain.DataSource{},
 			&domain.IngestJob{},
+			&domain.QuarantinedItem{},
+			&domain.CategorySuggestion{},
+			&domain.APIKeyUsage{},
+			&domain.CategoryCover{},
+			&domain.MemeTag{},
+			&domain.StatsSnapshot{},
+			&domain.MemeActivity{},
+			&domain.CachedExpansion{},
+			&domain.APIKey{},
 		); err != nil {
 			return nil, fmt.Errorf("failed to migrate database: %w", err)
 		}
+		if err := backfillMemeTags(db); err != nil {
+			return nil, fmt.Errorf("failed to backfill meme tags: %w", err)
+		}
 	} else {
 		log.Printf("[DB] AutoMigrate disabled")
 	}
@@ -76,6 +90,52 @@ func InitDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// memeTagBackfillBatch is the number of memes whose tags are copied per batch.
+const memeTagBackfillBatch = 500
+
+// backfillMemeTags fills meme_tags from the tags column when the table is
+// still empty, for databases created before the table existed.
+func backfillMemeTags(db *gorm.DB) error {
+	var existing int64
+	if err := db.Model(&domain.MemeTag{}).Limit(1).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	copied := 0
+	afterID := ""
+	for {
+		var memes []domain.Meme
+		if err := db.Select("id", "tags").
+			Where("id > ?", afterID).
+			Order("id ASC").
+			Limit(memeTagBackfillBatch).
+			Find(&memes).Error; err != nil {
+			return err
+		}
+		if len(memes) == 0 {
+			break
+		}
+		var rows []domain.MemeTag
+		for _, meme := range memes {
+			rows = append(rows, domain.NewMemeTags(meme.ID, meme.Tags)...)
+		}
+		if len(rows) > 0 {
+			if err := db.CreateInBatches(rows, memeTagBackfillBatch).Error; err != nil {
+				return err
+			}
+		}
+		copied += len(rows)
+		afterID = memes[len(memes)-1].ID
+	}
+	if copied > 0 {
+		log.Printf("[DB] Backfilled %d meme tags", copied)
+	}
+	return nil
+}
+
 // initPostgres initializes a PostgreSQL database connection using the unified DSN
 func initPostgres(cfg *config.DatabaseConfig, gormConfig *gorm.Config) (*gorm.DB, error) {
 	dsn := cfg.DSN()
@@ -101,16 +161,33 @@ func initSQLite(cfg *config.DatabaseConfig, gormConfig *gorm.Config) (*gorm.DB,
 		}
 	}
 
-	dsn := cfg.DSN()
+	dsn := sqliteDSN(cfg.DSN(), cfg.BusyTimeout)
 	db, err := gorm.Open(sqlite.Open(dsn), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to SQLite: %w", err)
 	}
 
-	// Enable WAL mode for better concurrency (SQLite specific)
-	// These are PRAGMA statements, separate from the connection pool settings
-	db.Exec("PRAGMA journal_mode=WAL")
-	db.Exec("PRAGMA foreign_keys=ON")
+	// Queue autocommit writes in-process so workers don't race for the file lock
+	if err := db.Use(&sqliteWriteSerializer{}); err != nil {
+		return nil, fmt.Errorf("failed to register SQLite write serializer: %w", err)
+	}
 
 	return db, nil
 }
+
+// sqliteDSN appends connection parameters so every pooled connection gets WAL,
+// a busy timeout, foreign keys, and BEGIN IMMEDIATE transactions. PRAGMAs issued
+// through db.Exec only reach whichever single connection happens to run them.
+func sqliteDSN(path string, busyTimeout time.Duration) string {
+	if busyTimeout <= 0 {
+		busyTimeout = 5 * time.Second
+	}
+	params := fmt.Sprintf("_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on&_txlock=immediate",
+		busyTimeout.Milliseconds())
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + params
+}
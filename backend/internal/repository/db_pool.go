@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/metrics"
+	"gorm.io/gorm"
+)
+
+const (
+	// poolLeakTicks is how many consecutive saturated samples are reported as a suspected leak.
+	poolLeakTicks = 3
+
+	defaultPoolSaturationRatio = 0.9
+)
+
+// PoolStats is a snapshot of the database connection pool.
+type PoolStats struct {
+	MaxOpen        int   `json:"max_open"`
+	Open           int   `json:"open"`
+	InUse          int   `json:"in_use"`
+	Idle           int   `json:"idle"`
+	WaitCount      int64 `json:"wait_count"`
+	WaitDurationMs int64 `json:"wait_duration_ms"`
+}
+
+// GetPoolStats returns the current connection pool statistics for db.
+// Parameters:
+//   - db: GORM database handle.
+//
+// Returns:
+//   - PoolStats: pool snapshot.
+//   - error: non-nil if the underlying sql.DB cannot be obtained.
+func GetPoolStats(db *gorm.DB) (PoolStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return PoolStats{}, fmt.Errorf("failed to get sql.DB instance: %w", err)
+	}
+	return poolStatsFrom(sqlDB.Stats()), nil
+}
+
+func poolStatsFrom(s sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpen:        s.MaxOpenConnections,
+		Open:           s.OpenConnections,
+		InUse:          s.InUse,
+		Idle:           s.Idle,
+		WaitCount:      s.WaitCount,
+		WaitDurationMs: s.WaitDuration.Milliseconds(),
+	}
+}
+
+// PingDB checks that the database accepts connections.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - db: GORM database handle.
+//
+// Returns:
+//   - error: non-nil if the database cannot be reached.
+func PingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql.DB instance: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// PoolMonitorConfig controls the periodic pool sampler.
+type PoolMonitorConfig struct {
+	Interval        time.Duration // Sampling interval (0 disables the monitor)
+	SaturationRatio float64       // in_use/max_open at or above which the pool counts as saturated
+}
+
+// StartPoolMonitor samples pool statistics until ctx is cancelled. Each sample is
+// logged at debug level with metric fields; callers waiting for a connection and pools that stay
+// saturated for several samples in a row (typically a leaked transaction) are
+// logged as warnings.
+// Parameters:
+//   - ctx: context that stops the monitor when cancelled.
+//   - db: GORM database handle to sample.
+//   - cfg: sampling interval and saturation threshold.
+//
+// Returns: none.
+func StartPoolMonitor(ctx context.Context, db *gorm.DB, cfg PoolMonitorConfig) {
+	if cfg.Interval <= 0 {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.CtxWarn(ctx, "Pool monitor disabled: error=%v", err)
+		return
+	}
+
+	monitor := &poolMonitor{saturationRatio: cfg.SaturationRatio}
+	if monitor.saturationRatio <= 0 || monitor.saturationRatio > 1 {
+		monitor.saturationRatio = defaultPoolSaturationRatio
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				monitor.observe(ctx, poolStatsFrom(sqlDB.Stats()))
+			}
+		}
+	}()
+}
+
+type poolMonitor struct {
+	saturationRatio float64
+	lastWaitCount   int64
+	saturatedTicks  int
+}
+
+// observe logs one sample and reports the warnings it triggers.
+func (m *poolMonitor) observe(ctx context.Context, stats PoolStats) (waited, leaked bool) {
+	logger.With(logger.Fields{
+		"db_max_open":         stats.MaxOpen,
+		"db_open":             stats.Open,
+		"db_in_use":           stats.InUse,
+		"db_idle":             stats.Idle,
+		"db_wait_count":       stats.WaitCount,
+		"db_wait_duration_ms": stats.WaitDurationMs,
+	}).Debug(ctx, "Database pool stats")
+	metrics.Gauge("db.pool.open", float64(stats.Open))
+	metrics.Gauge("db.pool.in_use", float64(stats.InUse))
+	metrics.Gauge("db.pool.idle", float64(stats.Idle))
+
+	if newWaits := stats.WaitCount - m.lastWaitCount; newWaits > 0 {
+		metrics.Count("db.pool.waits", newWaits)
+		logger.CtxWarn(ctx, "Database pool saturated: new_waits=%d, in_use=%d, max_open=%d",
+			newWaits, stats.InUse, stats.MaxOpen)
+		waited = true
+	}
+	m.lastWaitCount = stats.WaitCount
+
+	if stats.MaxOpen > 0 && float64(stats.InUse) >= float64(stats.MaxOpen)*m.saturationRatio {
+		m.saturatedTicks++
+	} else {
+		m.saturatedTicks = 0
+	}
+	if m.saturatedTicks >= poolLeakTicks {
+		logger.CtxWarn(ctx, "Database pool stuck near capacity, possible leaked connection or transaction: in_use=%d, max_open=%d, samples=%d",
+			stats.InUse, stats.MaxOpen, m.saturatedTicks)
+		leaked = true
+	}
+
+	return waited, leaked
+}
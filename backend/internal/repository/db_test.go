@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens a migrated SQLite database through InitDB. Tests outside the
+// package use testutil.NewTestDB, which cannot be imported here.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := InitDB(&config.DatabaseConfig{
+		Driver:      "sqlite",
+		Path:        filepath.Join(t.TempDir(), "emomo.db"),
+		AutoMigrate: true,
+	})
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+func TestInitDBSQLiteHandlesConcurrentWriters(t *testing.T) {
+	t.Parallel()
+
+	db, err := InitDB(&config.DatabaseConfig{
+		Driver:       "sqlite",
+		Path:         filepath.Join(t.TempDir(), "memes.db"),
+		AutoMigrate:  true,
+		MaxIdleConns: 4,
+		MaxOpenConns: 8,
+	})
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+
+	var busyTimeout int
+	if err := db.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error; err != nil {
+		t.Fatalf("read busy_timeout: %v", err)
+	}
+	if busyTimeout != 5000 {
+		t.Fatalf("busy_timeout = %d, want 5000", busyTimeout)
+	}
+
+	repo := NewMemeRepository(db)
+	ctx := context.Background()
+
+	const writers = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- repo.Upsert(ctx, &domain.Meme{
+				ID:         fmt.Sprintf("meme-%d", i),
+				SourceType: "test",
+				SourceID:   fmt.Sprintf("source-%d", i),
+				MD5Hash:    fmt.Sprintf("md5-%d", i),
+				Status:     domain.MemeStatusActive,
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Upsert() error = %v", err)
+		}
+	}
+
+	count, err := repo.CountByStatus(ctx, domain.MemeStatusActive)
+	if err != nil {
+		t.Fatalf("CountByStatus() error = %v", err)
+	}
+	if count != writers {
+		t.Fatalf("active memes = %d, want %d", count, writers)
+	}
+}
+
+func TestPoolMonitorFlagsWaitsAndSustainedSaturation(t *testing.T) {
+	t.Parallel()
+
+	monitor := &poolMonitor{saturationRatio: 0.9}
+	ctx := context.Background()
+
+	if waited, leaked := monitor.observe(ctx, PoolStats{MaxOpen: 10, InUse: 2}); waited || leaked {
+		t.Fatalf("observe(idle pool) = (%v, %v), want (false, false)", waited, leaked)
+	}
+	if waited, _ := monitor.observe(ctx, PoolStats{MaxOpen: 10, InUse: 10, WaitCount: 3}); !waited {
+		t.Fatal("observe() waited = false after wait count grew, want true")
+	}
+	if _, leaked := monitor.observe(ctx, PoolStats{MaxOpen: 10, InUse: 9, WaitCount: 3}); leaked {
+		t.Fatal("observe() leaked = true after two saturated samples, want false")
+	}
+	if _, leaked := monitor.observe(ctx, PoolStats{MaxOpen: 10, InUse: 10, WaitCount: 3}); !leaked {
+		t.Fatal("observe() leaked = false after three saturated samples, want true")
+	}
+	if _, leaked := monitor.observe(ctx, PoolStats{MaxOpen: 10, InUse: 1, WaitCount: 3}); leaked {
+		t.Fatal("observe() leaked = true after pool drained, want false")
+	}
+}
+
+// panickyRow panics in its BeforeCreate hook or, through Payload, while the
+// INSERT is built and run.
+type panickyRow struct {
+	ID        string `gorm:"primaryKey"`
+	HookPanic bool
+	Payload   panickyValue
+}
+
+func (r *panickyRow) BeforeCreate(*gorm.DB) error {
+	if r.HookPanic {
+		panic("hook panic")
+	}
+	return nil
+}
+
+type panickyValue bool
+
+func (v panickyValue) Value() (driver.Value, error) {
+	if v {
+		panic("valuer panic")
+	}
+	return false, nil
+}
+
+func TestSQLiteWriteSerializerSurvivesPanics(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&panickyRow{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	// Autocommit writes go through the serializer; default transactions skip it
+	db = db.Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	create := func(row *panickyRow) (recovered any) {
+		defer func() { recovered = recover() }()
+		db.Create(row)
+		return nil
+	}
+	// A leaked write lock blocks forever, so the writes run with a deadline
+	done := make(chan string, 1)
+	go func() {
+		switch {
+		case create(&panickyRow{ID: "hook", HookPanic: true}) == nil:
+			done <- "Create() with a panicking hook did not panic"
+		case create(&panickyRow{ID: "valuer", Payload: true}) == nil:
+			done <- "Create() with a panicking Valuer did not panic"
+		default:
+			if err := db.Create(&panickyRow{ID: "after"}).Error; err != nil {
+				done <- fmt.Sprintf("Create() after panics error = %v", err)
+			}
+			done <- ""
+		}
+	}()
+	select {
+	case failure := <-done:
+		if failure != "" {
+			t.Fatal(failure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Create() after panics blocked: the write lock was not released")
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+)
+
+// IngestJobRepository handles ingest run records.
+type IngestJobRepository struct {
+	db *gorm.DB
+}
+
+// NewIngestJobRepository creates a new IngestJobRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *IngestJobRepository: repository instance bound to db.
+func NewIngestJobRepository(db *gorm.DB) *IngestJobRepository {
+	return &IngestJobRepository{db: db}
+}
+
+// Create inserts a new ingest job.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - job: job record to insert.
+//
+// Returns:
+//   - error: non-nil if the insert fails.
+func (r *IngestJobRepository) Create(ctx context.Context, job *domain.IngestJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// Save updates every column of an existing ingest job.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - job: job record with its final state.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *IngestJobRepository) Save(ctx context.Context, job *domain.IngestJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+// UpdateProgress stores the live counts of a running ingest job.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: job identifier.
+//   - total: items fetched so far.
+//   - processed: items finished so far, including failed and skipped ones.
+//   - failed: items that failed.
+//   - skipped: items that were skipped.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *IngestJobRepository) UpdateProgress(ctx context.Context, id string, total, processed, failed, skipped int) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"total_items":     total,
+		"processed_items": processed,
+		"failed_items":    failed,
+		"skipped_items":   skipped,
+	}).Error
+}
+
+// ListByStatus retrieves jobs of a kind in the given status that were last
+// updated before a cutoff, oldest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - kind: job kind to match.
+//   - status: job status to match.
+//   - updatedBefore: only jobs whose updated_at is earlier are returned.
+//
+// Returns:
+//   - []domain.IngestJob: matching jobs.
+//   - error: non-nil if the query fails.
+func (r *IngestJobRepository) ListByStatus(ctx context.Context, kind domain.JobKind, status domain.JobStatus, updatedBefore time.Time) ([]domain.IngestJob, error) {
+	var jobs []domain.IngestJob
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND status = ? AND updated_at < ?", kind, status, updatedBefore).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// MarkFailed marks a job as failed and stores the reason in its error log.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: job identifier.
+//   - errorLog: reason the job failed.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *IngestJobRepository) MarkFailed(ctx context.Context, id, errorLog string) error {
+	return r.db.WithContext(ctx).Model(&domain.IngestJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       domain.JobStatusFailed,
+		"completed_at": time.Now(),
+		"error_log":    errorLog,
+	}).Error
+}
+
+// GetByID retrieves an ingest job by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: job identifier.
+//
+// Returns:
+//   - *domain.IngestJob: matching job.
+//   - error: gorm.ErrRecordNotFound if no job has that ID.
+func (r *IngestJobRepository) GetByID(ctx context.Context, id string) (*domain.IngestJob, error) {
+	var job domain.IngestJob
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List retrieves ingest jobs, most recent first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of jobs to return.
+//   - offset: number of jobs to skip.
+//
+// Returns:
+//   - []domain.IngestJob: ingest jobs.
+//   - error: non-nil if the query fails.
+func (r *IngestJobRepository) List(ctx context.Context, limit, offset int) ([]domain.IngestJob, error) {
+	var jobs []domain.IngestJob
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// Count returns the number of ingest jobs.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - int64: number of ingest jobs.
+//   - error: non-nil if the query fails.
+func (r *IngestJobRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.IngestJob{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MemeActivityRepository handles the daily activity counts of memes.
+type MemeActivityRepository struct {
+	db *gorm.DB
+}
+
+// NewMemeActivityRepository creates a new MemeActivityRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *MemeActivityRepository: repository instance bound to db.
+func NewMemeActivityRepository(db *gorm.DB) *MemeActivityRepository {
+	return &MemeActivityRepository{db: db}
+}
+
+// Add adds the counts of rows to the rows of their meme and day, creating
+// them on first use.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - rows: counts to add, one per meme and day.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *MemeActivityRepository) Add(ctx context.Context, rows []domain.MemeActivity) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "meme_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"impressions": gorm.Expr("meme_activity.impressions + excluded.impressions"),
+			"clicks":      gorm.Expr("meme_activity.clicks + excluded.clicks"),
+			"downloads":   gorm.Expr("meme_activity.downloads + excluded.downloads"),
+			"updated_at":  gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&rows).Error
+}
+
+// SumSince returns the counts of memes summed over the days from since on.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeIDs: memes to sum.
+//   - since: first day as YYYY-MM-DD.
+//
+// Returns:
+//   - map[string]domain.MemeActivity: sums by meme ID; memes without activity are missing.
+//   - error: non-nil if the query fails.
+func (r *MemeActivityRepository) SumSince(ctx context.Context, memeIDs []string, since string) (map[string]domain.MemeActivity, error) {
+	sums := make(map[string]domain.MemeActivity, len(memeIDs))
+	if len(memeIDs) == 0 {
+		return sums, nil
+	}
+	var rows []domain.MemeActivity
+	if err := r.db.WithContext(ctx).
+		Model(&domain.MemeActivity{}).
+		Select("meme_id, SUM(impressions) AS impressions, SUM(clicks) AS clicks, SUM(downloads) AS downloads").
+		Where("meme_id IN ? AND date >= ?", memeIDs, since).
+		Group("meme_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		sums[row.MemeID] = row
+	}
+	return sums, nil
+}
+
+// DeleteBefore removes the rows of days before a day.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - before: first day to keep as YYYY-MM-DD.
+//
+// Returns:
+//   - int64: number of rows deleted.
+//   - error: non-nil if the delete fails.
+func (r *MemeActivityRepository) DeleteBefore(ctx context.Context, before string) (int64, error) {
+	result := r.db.WithContext(ctx).Where("date < ?", before).Delete(&domain.MemeActivity{})
+	return result.RowsAffected, result.Error
+}
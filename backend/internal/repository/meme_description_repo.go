@@ -22,6 +22,16 @@ func NewMemeDescriptionRepository(db *gorm.DB) *MemeDescriptionRepository {
 	return &MemeDescriptionRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+// Parameters:
+//   - tx: transaction handle obtained from gorm.DB.Transaction or Begin.
+//
+// Returns:
+//   - *MemeDescriptionRepository: repository whose queries run inside tx.
+func (r *MemeDescriptionRepository) WithTx(tx *gorm.DB) *MemeDescriptionRepository {
+	return &MemeDescriptionRepository{db: tx}
+}
+
 // Create inserts a new meme description record.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -48,6 +58,144 @@ func (r *MemeDescriptionRepository) UpdateOCRText(ctx context.Context, id, ocrTe
 		Update("ocr_text", ocrText).Error
 }
 
+// UpdateSceneTags updates the scene tags for a description record.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: description record ID.
+//   - tags: scene tags to store.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeDescriptionRepository) UpdateSceneTags(ctx context.Context, id string, tags []string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Where("id = ?", id).
+		Update("scene_tags", domain.StringArray(tags)).Error
+}
+
+// UpdateBM25Text updates the BM25 sparse-vector text for a description record.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: description record ID.
+//   - text: BM25 document text the sparse vectors were built from.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeDescriptionRepository) UpdateBM25Text(ctx context.Context, id, text string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Where("id = ?", id).
+		Update("bm25_text", text).Error
+}
+
+// ListNeedingReview retrieves descriptions flagged by the quality check,
+// lowest quality score first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of descriptions to return.
+//   - offset: number of descriptions to skip.
+//
+// Returns:
+//   - []domain.MemeDescription: flagged descriptions.
+//   - error: non-nil if the query fails.
+func (r *MemeDescriptionRepository) ListNeedingReview(ctx context.Context, limit, offset int) ([]domain.MemeDescription, error) {
+	var descs []domain.MemeDescription
+	err := r.db.WithContext(ctx).
+		Where("needs_review = ?", true).
+		Order("quality_score ASC, created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&descs).Error
+	return descs, err
+}
+
+// CountNeedingReview returns the number of descriptions flagged for review.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - int64: number of flagged descriptions.
+//   - error: non-nil if the query fails.
+func (r *MemeDescriptionRepository) CountNeedingReview(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Where("needs_review = ?", true).
+		Count(&count).Error
+	return count, err
+}
+
+// PromptVersionCount is the number of descriptions produced by one prompt version.
+type PromptVersionCount struct {
+	PromptVersion string `json:"prompt_version"` // Empty for descriptions created before versioning
+	Count         int64  `json:"count"`
+}
+
+// CountByPromptVersion counts the descriptions of a VLM model per prompt version.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - vlmModel: VLM model name.
+//
+// Returns:
+//   - []PromptVersionCount: counts per prompt version, largest first.
+//   - error: non-nil if the query fails.
+func (r *MemeDescriptionRepository) CountByPromptVersion(ctx context.Context, vlmModel string) ([]PromptVersionCount, error) {
+	var counts []PromptVersionCount
+	err := r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Select("COALESCE(prompt_version, '') AS prompt_version, COUNT(*) AS count").
+		Where("vlm_model = ?", vlmModel).
+		Group("COALESCE(prompt_version, '')").
+		Order("count DESC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ListOutdatedPrompt retrieves descriptions of a VLM model that were produced
+// by a prompt version other than the current one, oldest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - vlmModel: VLM model name.
+//   - promptVersion: current prompt version.
+//   - limit: maximum number of descriptions to return.
+//
+// Returns:
+//   - []domain.MemeDescription: outdated descriptions.
+//   - error: non-nil if the query fails.
+func (r *MemeDescriptionRepository) ListOutdatedPrompt(ctx context.Context, vlmModel, promptVersion string, limit int) ([]domain.MemeDescription, error) {
+	var descs []domain.MemeDescription
+	err := r.db.WithContext(ctx).
+		Where("vlm_model = ? AND COALESCE(prompt_version, '') <> ?", vlmModel, promptVersion).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&descs).Error
+	return descs, err
+}
+
+// UpdateRegenerated stores a regenerated description in place. The BM25 text is
+// cleared so the next reembed --stale run refreshes the vectors built from it.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - desc: description record carrying the regenerated fields.
+//
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeDescriptionRepository) UpdateRegenerated(ctx context.Context, desc *domain.MemeDescription) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Where("id = ?", desc.ID).
+		Updates(map[string]interface{}{
+			"description":    desc.Description,
+			"prompt_version": desc.PromptVersion,
+			"ocr_text":       desc.OCRText,
+			"scene_tags":     desc.SceneTags,
+			"quality_score":  desc.QualityScore,
+			"quality_issues": desc.QualityIssues,
+			"needs_review":   desc.NeedsReview,
+			"bm25_text":      "",
+		}).Error
+}
+
 // GetByMD5AndModel retrieves a description by MD5 hash and VLM model.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -150,3 +298,39 @@ func (r *MemeDescriptionRepository) Search(ctx context.Context, query string, li
 func (r *MemeDescriptionRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&domain.MemeDescription{}, "id = ?", id).Error
 }
+
+// CountByMemeIDs counts descriptions belonging to the given memes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeIDs: meme identifiers.
+//
+// Returns:
+//   - int64: number of descriptions.
+//   - error: non-nil if the query fails.
+func (r *MemeDescriptionRepository) CountByMemeIDs(ctx context.Context, memeIDs []string) (int64, error) {
+	if len(memeIDs) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.MemeDescription{}).
+		Where("meme_id IN ?", memeIDs).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByMemeIDs deletes all descriptions belonging to the given memes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeIDs: meme identifiers.
+//
+// Returns:
+//   - error: non-nil if the delete fails.
+func (r *MemeDescriptionRepository) DeleteByMemeIDs(ctx context.Context, memeIDs []string) error {
+	if len(memeIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("meme_id IN ?", memeIDs).
+		Delete(&domain.MemeDescription{}).Error
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/timmy/emomo/internal/domain"
 	"gorm.io/gorm"
@@ -11,7 +13,8 @@ import (
 
 // MemeRepository handles meme data operations.
 type MemeRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	timeout time.Duration
 }
 
 // NewMemeRepository creates a new MemeRepository.
@@ -23,6 +26,30 @@ func NewMemeRepository(db *gorm.DB) *MemeRepository {
 	return &MemeRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+// Parameters:
+//   - tx: transaction handle obtained from gorm.DB.Transaction or Begin.
+// Returns:
+//   - *MemeRepository: repository whose queries run inside tx.
+func (r *MemeRepository) WithTx(tx *gorm.DB) *MemeRepository {
+	return &MemeRepository{db: tx, timeout: r.timeout}
+}
+
+// SetOperationTimeout sets the default deadline applied to each query when the
+// caller's context has none (or a later one). Zero disables the default.
+// Parameters:
+//   - timeout: per-operation timeout.
+// Returns: none.
+func (r *MemeRepository) SetOperationTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// session returns a context-bound handle limited by the operation timeout.
+func (r *MemeRepository) session(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	ctx, cancel := withOperationTimeout(ctx, r.timeout)
+	return r.db.WithContext(ctx), cancel
+}
+
 // Create inserts a new meme record.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -30,7 +57,15 @@ func NewMemeRepository(db *gorm.DB) *MemeRepository {
 // Returns:
 //   - error: non-nil if the insert fails.
 func (r *MemeRepository) Create(ctx context.Context, meme *domain.Meme) error {
-	return r.db.WithContext(ctx).Create(meme).Error
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(meme).Error; err != nil {
+			return err
+		}
+		return replaceMemeTags(tx, meme.ID, meme.Tags)
+	})
 }
 
 // Upsert creates or updates a meme record keyed by source fields.
@@ -40,10 +75,48 @@ func (r *MemeRepository) Create(ctx context.Context, meme *domain.Meme) error {
 // Returns:
 //   - error: non-nil if the upsert fails.
 func (r *MemeRepository) Upsert(ctx context.Context, meme *domain.Meme) error {
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "source_type"}, {Name: "source_id"}},
-		UpdateAll: true,
-	}).Create(meme).Error
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		columns, err := memeUpsertColumns(tx)
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "source_type"}, {Name: "source_id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(meme).Error; err != nil {
+			return err
+		}
+		// An update keeps the stored ID, which may differ from meme.ID
+		var id string
+		if err := tx.Model(&domain.Meme{}).
+			Where("source_type = ? AND source_id = ?", meme.SourceType, meme.SourceID).
+			Pluck("id", &id).Error; err != nil {
+			return err
+		}
+		return replaceMemeTags(tx, id, meme.Tags)
+	})
+}
+
+// memeUpsertColumns lists the columns an upsert overwrites: every column but
+// the primary key, created_at and download_count, so re-ingesting a meme
+// keeps its download history.
+func memeUpsertColumns(db *gorm.DB) ([]string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&domain.Meme{}); err != nil {
+		return nil, fmt.Errorf("failed to parse meme schema: %w", err)
+	}
+	columns := make([]string, 0, len(stmt.Schema.DBNames))
+	for _, column := range stmt.Schema.DBNames {
+		switch column {
+		case "id", "created_at", "download_count":
+			continue
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
 }
 
 // Update updates an existing meme record.
@@ -53,7 +126,44 @@ func (r *MemeRepository) Upsert(ctx context.Context, meme *domain.Meme) error {
 // Returns:
 //   - error: non-nil if the update fails.
 func (r *MemeRepository) Update(ctx context.Context, meme *domain.Meme) error {
-	return r.db.WithContext(ctx).Save(meme).Error
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(meme).Error; err != nil {
+			return err
+		}
+		return replaceMemeTags(tx, meme.ID, meme.Tags)
+	})
+}
+
+// replaceMemeTags rewrites the meme_tags rows of a meme from its tags.
+func replaceMemeTags(tx *gorm.DB, memeID string, tags []string) error {
+	if err := tx.Where("meme_id = ?", memeID).Delete(&domain.MemeTag{}).Error; err != nil {
+		return fmt.Errorf("failed to clear meme tags: %w", err)
+	}
+	rows := domain.NewMemeTags(memeID, tags)
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save meme tags: %w", err)
+	}
+	return nil
+}
+
+// UpdatePosterKey sets the poster frame object of a meme.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: meme ID.
+//   - posterKey: storage key of the poster frame.
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeRepository) UpdatePosterKey(ctx context.Context, id, posterKey string) error {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Model(&domain.Meme{}).Where("id = ?", id).Update("poster_key", posterKey).Error
 }
 
 // GetByID retrieves a meme by its ID.
@@ -64,8 +174,11 @@ func (r *MemeRepository) Update(ctx context.Context, meme *domain.Meme) error {
 //   - *domain.Meme: meme record if found.
 //   - error: non-nil if lookup fails.
 func (r *MemeRepository) GetByID(ctx context.Context, id string) (*domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var meme domain.Meme
-	if err := r.db.WithContext(ctx).First(&meme, "id = ?", id).Error; err != nil {
+	if err := db.First(&meme, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &meme, nil
@@ -79,8 +192,32 @@ func (r *MemeRepository) GetByID(ctx context.Context, id string) (*domain.Meme,
 //   - *domain.Meme: meme record if found.
 //   - error: non-nil if lookup fails.
 func (r *MemeRepository) GetByMD5Hash(ctx context.Context, md5Hash string) (*domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var meme domain.Meme
-	if err := r.db.WithContext(ctx).First(&meme, "md5_hash = ?", md5Hash).Error; err != nil {
+	if err := db.First(&meme, "md5_hash = ?", md5Hash).Error; err != nil {
+		return nil, err
+	}
+	return &meme, nil
+}
+
+// GetByPerceptualHash retrieves the earliest active meme with a perceptual hash.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - hash: perceptual hash of the meme image.
+// Returns:
+//   - *domain.Meme: meme record if found.
+//   - error: gorm.ErrRecordNotFound if no active meme has the hash.
+func (r *MemeRepository) GetByPerceptualHash(ctx context.Context, hash string) (*domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var meme domain.Meme
+	err := db.Where("perceptual_hash = ? AND status = ?", hash, domain.MemeStatusActive).
+		Order("created_at ASC").
+		First(&meme).Error
+	if err != nil {
 		return nil, err
 	}
 	return &meme, nil
@@ -94,8 +231,11 @@ func (r *MemeRepository) GetByMD5Hash(ctx context.Context, md5Hash string) (*dom
 //   - bool: true if a record exists.
 //   - error: non-nil if the lookup fails.
 func (r *MemeRepository) ExistsByMD5Hash(ctx context.Context, md5Hash string) (bool, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.Meme{}).Where("md5_hash = ?", md5Hash).Count(&count).Error; err != nil {
+	if err := db.Model(&domain.Meme{}).Where("md5_hash = ?", md5Hash).Count(&count).Error; err != nil {
 		return false, err
 	}
 	return count > 0, nil
@@ -110,8 +250,11 @@ func (r *MemeRepository) ExistsByMD5Hash(ctx context.Context, md5Hash string) (b
 //   - *domain.Meme: meme record if found.
 //   - error: non-nil if lookup fails.
 func (r *MemeRepository) GetBySourceID(ctx context.Context, sourceType, sourceID string) (*domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var meme domain.Meme
-	if err := r.db.WithContext(ctx).First(&meme, "source_type = ? AND source_id = ?", sourceType, sourceID).Error; err != nil {
+	if err := db.First(&meme, "source_type = ? AND source_id = ?", sourceType, sourceID).Error; err != nil {
 		return nil, err
 	}
 	return &meme, nil
@@ -126,8 +269,11 @@ func (r *MemeRepository) GetBySourceID(ctx context.Context, sourceType, sourceID
 //   - bool: true if a record exists.
 //   - error: non-nil if the lookup fails.
 func (r *MemeRepository) ExistsBySourceID(ctx context.Context, sourceType, sourceID string) (bool, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.Meme{}).
+	if err := db.Model(&domain.Meme{}).
 		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
 		Count(&count).Error; err != nil {
 		return false, err
@@ -135,7 +281,7 @@ func (r *MemeRepository) ExistsBySourceID(ctx context.Context, sourceType, sourc
 	return count > 0, nil
 }
 
-// ListByStatus retrieves memes by status with pagination.
+// ListByStatus retrieves memes by status with pagination, oldest first.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //   - status: meme status to filter by.
@@ -145,9 +291,40 @@ func (r *MemeRepository) ExistsBySourceID(ctx context.Context, sourceType, sourc
 //   - []domain.Meme: matching meme records.
 //   - error: non-nil if the query fails.
 func (r *MemeRepository) ListByStatus(ctx context.Context, status domain.MemeStatus, limit, offset int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var memes []domain.Meme
-	if err := r.db.WithContext(ctx).
+	if err := db.
 		Where("status = ?", status).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
+// ListBySourceTypeAndStatus retrieves memes of a source by status with
+// pagination, oldest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored as meme.source_type.
+//   - status: meme status to filter by.
+//   - limit: maximum number of records to return.
+//   - offset: number of records to skip.
+// Returns:
+//   - []domain.Meme: matching meme records.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListBySourceTypeAndStatus(ctx context.Context, sourceType string, status domain.MemeStatus, limit, offset int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := db.
+		Where("source_type = ? AND status = ?", sourceType, status).
+		Order("created_at ASC, id ASC").
 		Limit(limit).
 		Offset(offset).
 		Find(&memes).Error; err != nil {
@@ -156,6 +333,31 @@ func (r *MemeRepository) ListByStatus(ctx context.Context, status domain.MemeSta
 	return memes, nil
 }
 
+// ListByStatusAfter retrieves memes by status in ID order, starting after a
+// cursor, so long scans can be resumed and are not disturbed by new memes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - status: meme status to filter by.
+//   - afterID: only memes with a greater ID are returned; empty starts at the beginning.
+//   - limit: maximum number of records to return.
+// Returns:
+//   - []domain.Meme: the next page of memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListByStatusAfter(ctx context.Context, status domain.MemeStatus, afterID string, limit int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := db.
+		Where("status = ? AND id > ?", status, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
 // ListByCategory retrieves memes by category with pagination.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -166,22 +368,185 @@ func (r *MemeRepository) ListByStatus(ctx context.Context, status domain.MemeSta
 //   - []domain.Meme: matching meme records.
 //   - error: non-nil if the query fails.
 func (r *MemeRepository) ListByCategory(ctx context.Context, category string, limit, offset int) ([]domain.Meme, error) {
-	var memes []domain.Meme
-	query := r.db.WithContext(ctx)
-	if category != "" {
-		query = query.Where("category = ?", category)
+	return r.List(ctx, MemeListFilter{Category: category}, limit, offset)
+}
+
+// ListByTag retrieves active memes carrying a tag, newest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - tag: exact tag to filter by.
+//   - limit: maximum number of records to return.
+//   - offset: number of records to skip.
+// Returns:
+//   - []domain.Meme: matching meme records.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListByTag(ctx context.Context, tag string, limit, offset int) ([]domain.Meme, error) {
+	return r.List(ctx, MemeListFilter{Tags: []string{tag}}, limit, offset)
+}
+
+// MemeListFilter selects and orders the active memes of a list.
+type MemeListFilter struct {
+	Category      string          // Empty means all categories
+	Tags          []string        // Exact tags a meme must all carry
+	Emotion       string          // Emotion word a tag or VLM description of the meme contains; empty means any
+	CreatedBefore time.Time       // Only memes ingested before; zero means no bound
+	Sort          domain.MemeSort // Empty means newest first
+}
+
+// memeListOrders maps each sort to its ORDER BY clause. The ID tie-breaker
+// keeps offset pages stable; the status-prefixed indexes on memes cover
+// every clause except random.
+var memeListOrders = map[domain.MemeSort]string{
+	domain.MemeSortNewest:   "created_at DESC, id DESC",
+	domain.MemeSortOldest:   "created_at ASC, id ASC",
+	domain.MemeSortPopular:  "download_count DESC, created_at DESC, id DESC",
+	domain.MemeSortFileSize: "file_size DESC, id DESC",
+	domain.MemeSortRandom:   "RANDOM()",
+}
+
+// List retrieves active memes matching a filter, in the filter's sort order.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category, tags and sort of the list.
+//   - limit: maximum number of records to return.
+//   - offset: number of records to skip.
+// Returns:
+//   - []domain.Meme: matching meme records.
+//   - error: non-nil if the sort is unknown or the query fails.
+func (r *MemeRepository) List(ctx context.Context, filter MemeListFilter, limit, offset int) ([]domain.Meme, error) {
+	sort := filter.Sort
+	if sort == "" {
+		sort = domain.MemeSortNewest
 	}
-	if err := query.
-		Where("status = ?", domain.MemeStatusActive).
+	order, ok := memeListOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown meme sort %q", sort)
+	}
+
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := filterMemeList(db, filter).
 		Limit(limit).
 		Offset(offset).
-		Order("created_at DESC").
+		Order(order).
 		Find(&memes).Error; err != nil {
 		return nil, err
 	}
 	return memes, nil
 }
 
+// CountList counts the active memes matching a filter; the sort is ignored.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category and tags of the list.
+// Returns:
+//   - int64: number of matching memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountList(ctx context.Context, filter MemeListFilter) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var count int64
+	if err := filterMemeList(db, filter).
+		Model(&domain.Meme{}).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// filterMemeList narrows db to the active memes of a filter's category,
+// tags, emotion and ingest time.
+func filterMemeList(db *gorm.DB, filter MemeListFilter) *gorm.DB {
+	query := db.Where("status = ?", domain.MemeStatusActive)
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	for _, tag := range filter.Tags {
+		tagged := db.Session(&gorm.Session{NewDB: true}).
+			Model(&domain.MemeTag{}).
+			Select("meme_id").
+			Where("tag = ?", tag)
+		query = query.Where("id IN (?)", tagged)
+	}
+	if filter.Emotion != "" {
+		// Emotion words are extracted from descriptions by substring, so the
+		// descriptions are matched the same way.
+		tagged := db.Session(&gorm.Session{NewDB: true}).
+			Model(&domain.MemeTag{}).
+			Select("meme_id").
+			Where("tag = ?", filter.Emotion)
+		described := db.Session(&gorm.Session{NewDB: true}).
+			Model(&domain.MemeDescription{}).
+			Select("meme_id").
+			Where("LOWER(description) LIKE ?", "%"+strings.ToLower(filter.Emotion)+"%")
+		query = query.Where("id IN (?) OR id IN (?)", tagged, described)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	return query
+}
+
+// Random retrieves active memes matching a filter in random order; the
+// filter's sort is ignored.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category, tags, emotion and ingest time of the memes.
+//   - limit: maximum number of records to return.
+// Returns:
+//   - []domain.Meme: randomly drawn meme records.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) Random(ctx context.Context, filter MemeListFilter, limit int) ([]domain.Meme, error) {
+	filter.Sort = domain.MemeSortRandom
+	return r.List(ctx, filter, limit, 0)
+}
+
+// GetNth retrieves the active meme at a position of a filter's memes in
+// ingest order, oldest first; the filter's sort is ignored. With a
+// CreatedBefore bound, a position keeps naming the same meme while new
+// memes are ingested.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category, tags, emotion and ingest time of the memes.
+//   - n: zero-based position.
+// Returns:
+//   - *domain.Meme: meme at the position.
+//   - error: gorm.ErrRecordNotFound past the last meme, or a query error.
+func (r *MemeRepository) GetNth(ctx context.Context, filter MemeListFilter, n int) (*domain.Meme, error) {
+	filter.Sort = domain.MemeSortOldest
+	memes, err := r.List(ctx, filter, 1, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(memes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &memes[0], nil
+}
+
+// IncrementDownloads adds one download to each meme, feeding the popular
+// sort. updated_at is left alone, so downloads never look like edits.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - ids: downloaded meme IDs.
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeRepository) IncrementDownloads(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.
+		Model(&domain.Meme{}).
+		Where("id IN ?", ids).
+		UpdateColumn("download_count", gorm.Expr("download_count + 1")).Error
+}
+
 // GetCategories retrieves all unique categories.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -189,8 +554,11 @@ func (r *MemeRepository) ListByCategory(ctx context.Context, category string, li
 //   - []string: distinct category names.
 //   - error: non-nil if the query fails.
 func (r *MemeRepository) GetCategories(ctx context.Context) ([]string, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var categories []string
-	if err := r.db.WithContext(ctx).
+	if err := db.
 		Model(&domain.Meme{}).
 		Where("status = ?", domain.MemeStatusActive).
 		Distinct("category").
@@ -200,6 +568,143 @@ func (r *MemeRepository) GetCategories(ctx context.Context) ([]string, error) {
 	return categories, nil
 }
 
+// CategoryCount holds the active meme counts of one category.
+type CategoryCount struct {
+	Category string
+	Count    int64 // Active memes in the category
+	Animated int64 // Active animated memes
+	Added    int64 // Active memes ingested since the requested time
+}
+
+// CountByCategory counts active memes per category, including how many are
+// animated and how many were ingested since the given time.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - since: start of the window counted in CategoryCount.Added.
+// Returns:
+//   - []CategoryCount: counts per category, largest first.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountByCategory(ctx context.Context, since time.Time) ([]CategoryCount, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var counts []CategoryCount
+	if err := db.
+		Model(&domain.Meme{}).
+		Select("category, COUNT(*) AS count, "+
+			"SUM(CASE WHEN is_animated THEN 1 ELSE 0 END) AS animated, "+
+			"SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END) AS added", since).
+		Where("status = ?", domain.MemeStatusActive).
+		Group("category").
+		Order("count DESC, category").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// SourceCount holds the number of active memes of a source type.
+type SourceCount struct {
+	SourceType string
+	Count      int64
+}
+
+// CountBySource counts active memes per source type.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+// Returns:
+//   - []SourceCount: counts per source type, largest first.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var counts []SourceCount
+	if err := db.
+		Model(&domain.Meme{}).
+		Select("source_type, COUNT(*) AS count").
+		Where("status = ?", domain.MemeStatusActive).
+		Group("source_type").
+		Order("count DESC, source_type").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// TagCount holds the number of active memes carrying a tag.
+type TagCount struct {
+	Tag   string
+	Count int64
+}
+
+// CountByTag counts active memes per tag, using the meme_tags rows.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+// Returns:
+//   - []TagCount: counts per tag, largest first.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountByTag(ctx context.Context) ([]TagCount, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var counts []TagCount
+	if err := db.
+		Model(&domain.MemeTag{}).
+		Select("meme_tags.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN memes ON memes.id = meme_tags.meme_id").
+		Where("memes.status = ?", domain.MemeStatusActive).
+		Group("meme_tags.tag").
+		Order("count DESC, tag").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ListByCategoriesOrTags retrieves memes of any status in one of the given
+// categories or carrying one of the given tags, in ID order, starting after a
+// cursor.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - categories: categories to match.
+//   - tags: exact tags to match.
+//   - afterID: only memes with a greater ID are returned; empty starts at the beginning.
+//   - limit: maximum number of records to return.
+// Returns:
+//   - []domain.Meme: the next page of memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListByCategoriesOrTags(ctx context.Context, categories, tags []string, afterID string, limit int) ([]domain.Meme, error) {
+	if len(categories) == 0 && len(tags) == 0 {
+		return nil, nil
+	}
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	match := db.Session(&gorm.Session{NewDB: true})
+	if len(categories) > 0 {
+		match = match.Or("category IN ?", categories)
+	}
+	if len(tags) > 0 {
+		tagged := db.Session(&gorm.Session{NewDB: true}).
+			Model(&domain.MemeTag{}).
+			Select("meme_id").
+			Where("tag IN ?", tags)
+		match = match.Or("id IN (?)", tagged)
+	}
+
+	var memes []domain.Meme
+	if err := db.
+		Where("id > ?", afterID).
+		Where(match).
+		Order("id ASC").
+		Limit(limit).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
 // CountByStatus counts memes by status.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -208,8 +713,121 @@ func (r *MemeRepository) GetCategories(ctx context.Context) ([]string, error) {
 //   - int64: number of matching records.
 //   - error: non-nil if the query fails.
 func (r *MemeRepository) CountByStatus(ctx context.Context, status domain.MemeStatus) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var count int64
+	if err := db.Model(&domain.Meme{}).Where("status = ?", status).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountBySourceType counts memes from a source with the given status.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored as meme.source_type.
+//   - status: meme status to count.
+// Returns:
+//   - int64: number of matching records.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountBySourceType(ctx context.Context, sourceType string, status domain.MemeStatus) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var count int64
+	if err := db.Model(&domain.Meme{}).
+		Where("source_type = ? AND status = ?", sourceType, status).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListOriginsToVerify retrieves memes with a source URL that has not been
+// checked since checkedBefore, never-checked memes first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - checkedBefore: only memes last checked before this time are returned.
+//   - limit: maximum number of records to return.
+// Returns:
+//   - []domain.Meme: memes due for an origin check.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListOriginsToVerify(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := db.
+		Where("COALESCE(source_url, '') <> ''").
+		Where("origin_checked_at IS NULL OR origin_checked_at < ?", checkedBefore).
+		Order("origin_checked_at IS NOT NULL, origin_checked_at ASC").
+		Limit(limit).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
+// UpdateOriginStatus records the result of an origin check.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: meme ID.
+//   - status: origin status to store.
+//   - checkedAt: time of the check.
+// Returns:
+//   - error: non-nil if the update fails.
+func (r *MemeRepository) UpdateOriginStatus(ctx context.Context, id string, status domain.OriginStatus, checkedAt time.Time) error {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Model(&domain.Meme{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"origin_status":     status,
+			"origin_checked_at": checkedAt,
+		}).Error
+}
+
+// ListDeadOrigins retrieves memes whose origin was found dead or blocked,
+// most recently checked first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of records to return.
+//   - offset: number of records to skip.
+// Returns:
+//   - []domain.Meme: memes needing origin review.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListDeadOrigins(ctx context.Context, limit, offset int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := db.
+		Where("origin_status IN ?", []domain.OriginStatus{domain.OriginStatusDead, domain.OriginStatusBlocked}).
+		Order("origin_checked_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
+// CountDeadOrigins counts memes whose origin was found dead or blocked.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+// Returns:
+//   - int64: number of matching records.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountDeadOrigins(ctx context.Context) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.Meme{}).Where("status = ?", status).Count(&count).Error; err != nil {
+	if err := db.Model(&domain.Meme{}).
+		Where("origin_status IN ?", []domain.OriginStatus{domain.OriginStatusDead, domain.OriginStatusBlocked}).
+		Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -223,11 +841,14 @@ func (r *MemeRepository) CountByStatus(ctx context.Context, status domain.MemeSt
 //   - []domain.Meme: matching meme records.
 //   - error: non-nil if the query fails.
 func (r *MemeRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
 	if len(ids) == 0 {
 		return []domain.Meme{}, nil
 	}
 	var memes []domain.Meme
-	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&memes).Error; err != nil {
+	if err := db.Where("id IN ?", ids).Find(&memes).Error; err != nil {
 		return nil, fmt.Errorf("failed to get memes by IDs: %w", err)
 	}
 	return memes, nil
@@ -240,5 +861,104 @@ func (r *MemeRepository) GetByIDs(ctx context.Context, ids []string) ([]domain.M
 // Returns:
 //   - error: non-nil if the delete fails.
 func (r *MemeRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&domain.Meme{}, "id = ?", id).Error
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MemeTag{}, "meme_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Meme{}, "id = ?", id).Error
+	})
+}
+
+// ListBySourceType retrieves memes of any status from a source, ordered by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored as meme.source_type.
+//   - afterID: only memes with a greater ID are returned; empty starts at the beginning.
+//   - limit: maximum number of records to return.
+// Returns:
+//   - []domain.Meme: the next page of memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) ListBySourceType(ctx context.Context, sourceType, afterID string, limit int) ([]domain.Meme, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var memes []domain.Meme
+	if err := db.
+		Where("source_type = ? AND id > ?", sourceType, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&memes).Error; err != nil {
+		return nil, err
+	}
+	return memes, nil
+}
+
+// CountStorageKeyRefs counts memes outside a source that reference a storage
+// object as their image or poster.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - key: storage object key.
+//   - excludeSourceType: source whose memes are not counted.
+// Returns:
+//   - int64: number of referencing memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountStorageKeyRefs(ctx context.Context, key, excludeSourceType string) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var count int64
+	if err := db.Model(&domain.Meme{}).
+		Where("source_type <> ?", excludeSourceType).
+		Where("storage_key = ? OR poster_key = ?", key, key).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountOtherStorageKeyRefs counts memes other than one that reference a
+// storage object as their image or poster.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - key: storage object key.
+//   - excludeMemeID: meme that is not counted.
+// Returns:
+//   - int64: number of referencing memes.
+//   - error: non-nil if the query fails.
+func (r *MemeRepository) CountOtherStorageKeyRefs(ctx context.Context, key, excludeMemeID string) (int64, error) {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	var count int64
+	if err := db.Model(&domain.Meme{}).
+		Where("id <> ?", excludeMemeID).
+		Where("storage_key = ? OR poster_key = ?", key, key).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteByIDs removes memes by ID.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - ids: meme IDs to delete.
+// Returns:
+//   - error: non-nil if the delete fails.
+func (r *MemeRepository) DeleteByIDs(ctx context.Context, ids []string) error {
+	db, cancel := r.session(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MemeTag{}, "meme_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Meme{}, "id IN ?", ids).Error
+	})
 }
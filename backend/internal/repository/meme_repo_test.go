@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+)
+
+func TestMemeRepositoryListsByTag(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	repo := NewMemeRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	for i, meme := range []domain.Meme{
+		{ID: "cat", Category: "动物", Tags: domain.StringArray{"猫", " 可爱 ", "猫"}},
+		{ID: "dog", Category: "动物", Tags: domain.StringArray{"狗", "可爱"}},
+		{ID: "boss", Category: "职场", Tags: domain.StringArray{"可爱"}},
+	} {
+		meme.SourceType, meme.SourceID, meme.MD5Hash = "test", meme.ID, "md5-"+meme.ID
+		meme.Status = domain.MemeStatusActive
+		meme.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := repo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+
+	memes, err := repo.ListByTag(ctx, "可爱", 10, 0)
+	if err != nil || len(memes) != 3 || memes[0].ID != "boss" {
+		t.Fatalf("ListByTag(可爱) = %v, %v, want boss, dog, cat", memeIDs(memes), err)
+	}
+	if memes, _ := repo.ListByTag(ctx, "可爱", 1, 1); len(memes) != 1 || memes[0].ID != "dog" {
+		t.Fatalf("ListByTag(可爱) page 2 = %v, want dog", memeIDs(memes))
+	}
+	memes, err = repo.List(ctx, MemeListFilter{Category: "动物", Tags: []string{"可爱", "猫"}}, 10, 0)
+	if err != nil || len(memes) != 1 || memes[0].ID != "cat" {
+		t.Fatalf("List(动物, 可爱+猫) = %v, %v, want cat", memeIDs(memes), err)
+	}
+
+	cat, _ := repo.GetByID(ctx, "cat")
+	cat.Tags = domain.StringArray{"橘猫"}
+	if err := repo.Update(ctx, cat); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if memes, _ := repo.ListByTag(ctx, "猫", 10, 0); len(memes) != 0 {
+		t.Fatalf("ListByTag(猫) after retagging = %v, want none", memeIDs(memes))
+	}
+	if err := repo.Delete(ctx, "cat"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	var rows int64
+	db.Model(&domain.MemeTag{}).Where("meme_id = ?", "cat").Count(&rows)
+	if rows != 0 {
+		t.Fatalf("meme_tags rows of a deleted meme = %d, want 0", rows)
+	}
+}
+
+func TestMemeRepositoryListSorts(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	repo := NewMemeRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	for i, meme := range []domain.Meme{
+		{ID: "small", FileSize: 100},
+		{ID: "large", FileSize: 900},
+		{ID: "medium", FileSize: 500},
+	} {
+		meme.SourceType, meme.SourceID, meme.MD5Hash = "test", meme.ID, "md5-"+meme.ID
+		meme.Status = domain.MemeStatusActive
+		meme.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		if err := repo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+	if err := repo.IncrementDownloads(ctx, []string{"small", "medium"}); err != nil {
+		t.Fatalf("IncrementDownloads() error = %v", err)
+	}
+	if err := repo.IncrementDownloads(ctx, []string{"small"}); err != nil {
+		t.Fatalf("IncrementDownloads() error = %v", err)
+	}
+
+	for sort, want := range map[domain.MemeSort]string{
+		"":                      "medium,large,small",
+		domain.MemeSortNewest:   "medium,large,small",
+		domain.MemeSortOldest:   "small,large,medium",
+		domain.MemeSortPopular:  "small,medium,large",
+		domain.MemeSortFileSize: "large,medium,small",
+	} {
+		memes, err := repo.List(ctx, MemeListFilter{Sort: sort}, 10, 0)
+		if got := strings.Join(memeIDs(memes), ","); err != nil || got != want {
+			t.Fatalf("List(sort=%q) = %s, %v, want %s", sort, got, err, want)
+		}
+	}
+	if memes, err := repo.List(ctx, MemeListFilter{Sort: domain.MemeSortRandom}, 10, 0); err != nil || len(memes) != 3 {
+		t.Fatalf("List(sort=random) = %v, %v, want all three memes", memeIDs(memes), err)
+	}
+	if _, err := repo.List(ctx, MemeListFilter{Sort: "likes"}, 10, 0); err == nil {
+		t.Fatalf("List(sort=likes) error = nil, want an error")
+	}
+
+	reingested := domain.Meme{ID: "small", SourceType: "test", SourceID: "small", MD5Hash: "md5-small",
+		FileSize: 120, Status: domain.MemeStatusActive}
+	if err := repo.Upsert(ctx, &reingested); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if small, _ := repo.GetByID(ctx, "small"); small == nil || small.FileSize != 120 || small.DownloadCount != 2 {
+		t.Fatalf("GetByID(small) after re-ingest = %+v, want file_size 120 and 2 downloads", small)
+	}
+}
+
+func TestMemeRepositoryListsByCategoriesOrTags(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	repo := NewMemeRepository(db)
+	ctx := context.Background()
+	for _, meme := range []domain.Meme{
+		{ID: "a", Category: "猫猫", Tags: domain.StringArray{"可爱"}, Status: domain.MemeStatusActive},
+		{ID: "b", Category: "职场", Tags: domain.StringArray{"猫咪"}, Status: domain.MemeStatusPending},
+		{ID: "c", Category: "职场", Tags: domain.StringArray{"加班", "可爱"}, Status: domain.MemeStatusActive},
+		{ID: "d", Category: "猫猫", Status: domain.MemeStatusFailed},
+	} {
+		meme.SourceType, meme.SourceID, meme.MD5Hash = "test", meme.ID, "md5-"+meme.ID
+		if err := repo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+
+	memes, err := repo.ListByCategoriesOrTags(ctx, []string{"猫猫"}, []string{"猫咪"}, "", 2)
+	if got := strings.Join(memeIDs(memes), ","); err != nil || got != "a,b" {
+		t.Fatalf("ListByCategoriesOrTags() = %s, %v, want a,b", got, err)
+	}
+	memes, _ = repo.ListByCategoriesOrTags(ctx, []string{"猫猫"}, []string{"猫咪"}, "b", 2)
+	if got := strings.Join(memeIDs(memes), ","); got != "d" {
+		t.Fatalf("ListByCategoriesOrTags() page 2 = %s, want d", got)
+	}
+
+	counts, err := repo.CountByTag(ctx)
+	if err != nil || len(counts) != 2 || counts[0] != (TagCount{Tag: "可爱", Count: 2}) {
+		t.Fatalf("CountByTag() = %+v, %v, want 可爱 2 and 加班 1 from active memes", counts, err)
+	}
+}
+
+func TestBackfillMemeTagsCopiesExistingTags(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+	// Written before meme_tags existed, bypassing the repository
+	old := domain.Meme{ID: "old", SourceType: "test", SourceID: "old", MD5Hash: "md5-old",
+		Tags: domain.StringArray{"熊猫", ""}, Status: domain.MemeStatusActive}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if err := backfillMemeTags(db); err != nil {
+		t.Fatalf("backfillMemeTags() error = %v", err)
+	}
+	memes, err := NewMemeRepository(db).ListByTag(context.Background(), "熊猫", 10, 0)
+	if err != nil || len(memes) != 1 {
+		t.Fatalf("ListByTag(熊猫) after backfill = %v, %v, want old", memeIDs(memes), err)
+	}
+}
+
+func memeIDs(memes []domain.Meme) []string {
+	ids := make([]string, len(memes))
+	for i, meme := range memes {
+		ids[i] = meme.ID
+	}
+	return ids
+}
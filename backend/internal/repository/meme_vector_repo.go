@@ -5,6 +5,7 @@ import (
 
 	"github.com/timmy/emomo/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // MemeVectorRepository handles meme vector data operations.
@@ -22,6 +23,16 @@ func NewMemeVectorRepository(db *gorm.DB) *MemeVectorRepository {
 	return &MemeVectorRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction.
+// Parameters:
+//   - tx: transaction handle obtained from gorm.DB.Transaction or Begin.
+//
+// Returns:
+//   - *MemeVectorRepository: repository whose queries run inside tx.
+func (r *MemeVectorRepository) WithTx(tx *gorm.DB) *MemeVectorRepository {
+	return &MemeVectorRepository{db: tx}
+}
+
 // Create inserts a new meme vector record.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -39,6 +50,30 @@ func (r *MemeVectorRepository) Create(ctx context.Context, vector *domain.MemeVe
 	return r.db.WithContext(ctx).Create(vector).Error
 }
 
+// Upsert inserts a meme vector record, replacing the existing record for the
+// same MD5 hash, collection, and vector type.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - vector: meme vector record to persist.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *MemeVectorRepository) Upsert(ctx context.Context, vector *domain.MemeVector) error {
+	if vector.VectorType == "" {
+		vector.VectorType = domain.MemeVectorTypeImage
+	}
+	if vector.EmbeddingMode == "" {
+		vector.EmbeddingMode = domain.MemeVectorEmbeddingModeIndependent
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "md5_hash"}, {Name: "collection"}, {Name: "vector_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"meme_id", "embedding_model", "embedding_provider", "embedding_mode", "dimension",
+			"input_hash", "description_id", "qdrant_point_id", "status", "created_at",
+		}),
+	}).Create(vector).Error
+}
+
 // ExistsByMD5AndCollection checks if a vector record exists for the MD5 hash and collection.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -151,6 +186,23 @@ func (r *MemeVectorRepository) GetByCollection(ctx context.Context, collection s
 	return vectors, nil
 }
 
+// ListPointIDsByCollection returns the Qdrant point IDs recorded for a collection.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - collection: Qdrant collection name.
+//
+// Returns:
+//   - []string: point IDs referenced by meme_vectors rows.
+//   - error: non-nil if the query fails.
+func (r *MemeVectorRepository) ListPointIDsByCollection(ctx context.Context, collection string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.MemeVector{}).
+		Where("collection = ?", collection).
+		Pluck("qdrant_point_id", &ids).Error
+	return ids, err
+}
+
 // CountByCollection counts the number of vectors in a collection.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -169,6 +221,28 @@ func (r *MemeVectorRepository) CountByCollection(ctx context.Context, collection
 	return count, nil
 }
 
+// CountMemesByCollection counts the active memes with at least one active
+// vector in a collection. A meme with image and caption vectors counts once.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - collection: Qdrant collection name.
+//
+// Returns:
+//   - int64: number of distinct active memes with vectors in the collection.
+//   - error: non-nil if the query fails.
+func (r *MemeVectorRepository) CountMemesByCollection(ctx context.Context, collection string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.MemeVector{}).
+		Joins("JOIN memes ON memes.id = meme_vectors.meme_id").
+		Where("meme_vectors.collection = ? AND meme_vectors.status = ? AND memes.status = ?",
+			collection, domain.MemeVectorStatusActive, domain.MemeStatusActive).
+		Distinct("meme_vectors.meme_id").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // Delete removes a meme vector by ID.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -201,6 +275,42 @@ func (r *MemeVectorRepository) DeleteByMD5CollectionAndVectorType(ctx context.Co
 		Delete(&domain.MemeVector{}).Error
 }
 
+// CountByMemeIDs counts vector records belonging to the given memes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeIDs: meme identifiers.
+//
+// Returns:
+//   - int64: number of vector records.
+//   - error: non-nil if the query fails.
+func (r *MemeVectorRepository) CountByMemeIDs(ctx context.Context, memeIDs []string) (int64, error) {
+	if len(memeIDs) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.MemeVector{}).
+		Where("meme_id IN ?", memeIDs).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByMemeIDs deletes all vector records belonging to the given memes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeIDs: meme identifiers.
+//
+// Returns:
+//   - error: non-nil if the delete fails.
+func (r *MemeVectorRepository) DeleteByMemeIDs(ctx context.Context, memeIDs []string) error {
+	if len(memeIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("meme_id IN ?", memeIDs).
+		Delete(&domain.MemeVector{}).Error
+}
+
 func normalizeVectorType(vectorType string) string {
 	if vectorType == "" {
 		return domain.MemeVectorTypeImage
@@ -6,20 +6,12 @@ import (
 	"time"
 
 	"github.com/timmy/emomo/internal/domain"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 func TestMemeVectorRepositorySeparatesVectorTypesWithinCollection(t *testing.T) {
 	t.Parallel()
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("failed to open sqlite: %v", err)
-	}
-	if err := db.AutoMigrate(&domain.MemeVector{}); err != nil {
-		t.Fatalf("failed to migrate meme_vectors: %v", err)
-	}
+	db := newTestDB(t)
 
 	repo := NewMemeVectorRepository(db)
 	ctx := context.Background()
@@ -62,3 +54,38 @@ func TestMemeVectorRepositorySeparatesVectorTypesWithinCollection(t *testing.T)
 		t.Fatal("expected caption vector to exist")
 	}
 }
+
+func TestMemeVectorRepositoryUpsertReplacesRoute(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+
+	repo := NewMemeVectorRepository(db)
+	ctx := context.Background()
+	first := domain.MemeVector{
+		ID:             "vector-1",
+		MemeID:         "meme-1",
+		MD5Hash:        "md5",
+		Collection:     "memes",
+		EmbeddingModel: "model",
+		QdrantPointID:  "00000000-0000-0000-0000-000000000001",
+	}
+	if err := repo.Upsert(ctx, &first); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	second := first
+	second.ID = "vector-2"
+	second.QdrantPointID = "00000000-0000-0000-0000-000000000002"
+	if err := repo.Upsert(ctx, &second); err != nil {
+		t.Fatalf("Upsert() on existing route error = %v", err)
+	}
+
+	pointIDs, err := repo.ListPointIDsByCollection(ctx, "memes")
+	if err != nil {
+		t.Fatalf("ListPointIDsByCollection() error = %v", err)
+	}
+	if len(pointIDs) != 1 || pointIDs[0] != second.QdrantPointID {
+		t.Fatalf("point IDs = %v, want [%s]", pointIDs, second.QdrantPointID)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	// MaxPayloadDescriptionRunes caps vlm_description in Qdrant payloads. The
+	// full description stays in meme_descriptions.
+	MaxPayloadDescriptionRunes = 300
+	// MaxPayloadOCRRunes caps ocr_text once a payload exceeds maxPayloadBytes.
+	MaxPayloadOCRRunes = 500
+
+	// maxPayloadBytes is the payload size above which OCR text is truncated too.
+	maxPayloadBytes = 8 << 10
+)
+
+// truncationSuffix marks text cut short for the payload.
+const truncationSuffix = "…"
+
+// guardPayload returns payload with an overlong description truncated and, if
+// the payload is still larger than maxPayloadBytes, its OCR text truncated.
+// Truncations are logged with the payload sizes so they can be aggregated.
+// The input is not modified.
+func guardPayload(ctx context.Context, payload *MemePayload) *MemePayload {
+	description, descTruncated := truncateRunes(payload.VLMDescription, MaxPayloadDescriptionRunes)
+	size := payloadSize(payload)
+	if !descTruncated && size <= maxPayloadBytes {
+		return payload
+	}
+
+	guarded := *payload
+	guarded.VLMDescription = description
+	ocrTruncated := false
+	if payloadSize(&guarded) > maxPayloadBytes {
+		guarded.OCRText, ocrTruncated = truncateRunes(guarded.OCRText, MaxPayloadOCRRunes)
+	}
+	guardedSize := payloadSize(&guarded)
+
+	entry := logger.With(logger.Fields{
+		logger.FieldSize:           size,
+		"payload_bytes_stored":     guardedSize,
+		"payload_desc_truncated":   descTruncated,
+		"payload_ocr_truncated":    ocrTruncated,
+		"payload_over_size_budget": guardedSize > maxPayloadBytes,
+	})
+	if guardedSize > maxPayloadBytes {
+		entry.Warn(ctx, "Oversized Qdrant payload: meme_id=%s, bytes=%d, limit=%d", payload.MemeID, guardedSize, maxPayloadBytes)
+	} else {
+		entry.Info(ctx, "Truncated Qdrant payload: meme_id=%s, bytes=%d, stored_bytes=%d", payload.MemeID, size, guardedSize)
+	}
+	return &guarded
+}
+
+// guardPayloadUpdate truncates the description of a payload update like guardPayload.
+func guardPayloadUpdate(update *PayloadUpdate) *PayloadUpdate {
+	if update.VLMDescription == nil {
+		return update
+	}
+	description, truncated := truncateRunes(*update.VLMDescription, MaxPayloadDescriptionRunes)
+	if !truncated {
+		return update
+	}
+	guarded := *update
+	guarded.VLMDescription = &description
+	return &guarded
+}
+
+// payloadSize approximates the stored size of a payload by its text bytes.
+func payloadSize(payload *MemePayload) int {
+	size := len(payload.MemeID) + len(payload.SourceType) + len(payload.Category) +
+		len(payload.VLMDescription) + len(payload.OCRText) + len(payload.StorageURL) +
+		len(payload.PosterURL) + len(payload.TextLang)
+	for _, list := range [][]string{payload.Tags, payload.Colors, payload.SceneTags} {
+		for _, s := range list {
+			size += len(s)
+		}
+	}
+	return size
+}
+
+// truncateRunes cuts text to at most limit runes, ending it with
+// truncationSuffix when it was cut.
+func truncateRunes(text string, limit int) (string, bool) {
+	if utf8.RuneCountInString(text) <= limit {
+		return text, false
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + truncationSuffix, true
+}
@@ -3,14 +3,20 @@ package repository
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/qdrant/go-client/qdrant"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -20,6 +26,28 @@ const (
 	DenseVectorName        = "dense"
 	SparseVectorName       = "bm25"
 	SparseVectorModel      = "qdrant/bm25"
+	// LearnedSparseVectorName holds client-encoded sparse vectors (e.g. SPLADE),
+	// stored next to the server-side BM25 vector.
+	LearnedSparseVectorName = "splade"
+
+	defaultHNSWM             = 16
+	defaultHNSWEfConstruct   = 128
+	defaultFullScanThreshold = 10000
+
+	// qdrantServiceConfig retries calls that fail with UNAVAILABLE, which is what
+	// the client sees while it re-establishes a connection dropped by the server.
+	qdrantServiceConfig = `{
+	"methodConfig": [{
+		"name": [{"service": "qdrant.Points"}, {"service": "qdrant.Collections"}],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
 )
 
 // QdrantConnectionConfig holds configuration for Qdrant connection.
@@ -30,6 +58,57 @@ type QdrantConnectionConfig struct {
 	APIKey          string // Qdrant Cloud API Key (enables TLS automatically)
 	UseTLS          bool   // Explicitly enable TLS without API Key
 	VectorDimension int    // Vector dimension for this collection (default: 1024)
+	Params          CollectionParams
+
+	PoolSize         int           // Number of gRPC connections to round-robin across (default: 1)
+	KeepaliveTime    time.Duration // Ping interval for idle connections (0 disables keepalive)
+	KeepaliveTimeout time.Duration // How long to wait for a ping ack before closing the connection
+	OperationTimeout time.Duration // Default deadline per RPC when the caller sets none (0 disables)
+}
+
+// CollectionParams holds HNSW and optimizer settings used when creating a collection.
+// Zero values fall back to the built-in defaults (HNSW) or Qdrant's defaults (optimizer).
+type CollectionParams struct {
+	HNSWM                uint64
+	HNSWEfConstruct      uint64
+	FullScanThreshold    uint64
+	OnDiskVectors        bool
+	OnDiskHNSW           bool
+	DefaultSegmentNumber uint64
+	IndexingThreshold    uint64
+}
+
+func (p CollectionParams) hnswConfig() *pb.HnswConfigDiff {
+	cfg := &pb.HnswConfigDiff{
+		M:                 optionalUint64(valueOrDefault(p.HNSWM, defaultHNSWM)),
+		EfConstruct:       optionalUint64(valueOrDefault(p.HNSWEfConstruct, defaultHNSWEfConstruct)),
+		FullScanThreshold: optionalUint64(valueOrDefault(p.FullScanThreshold, defaultFullScanThreshold)),
+	}
+	if p.OnDiskHNSW {
+		cfg.OnDisk = optionalBool(true)
+	}
+	return cfg
+}
+
+func (p CollectionParams) optimizersConfig() *pb.OptimizersConfigDiff {
+	if p.DefaultSegmentNumber == 0 && p.IndexingThreshold == 0 {
+		return nil
+	}
+	cfg := &pb.OptimizersConfigDiff{}
+	if p.DefaultSegmentNumber > 0 {
+		cfg.DefaultSegmentNumber = optionalUint64(p.DefaultSegmentNumber)
+	}
+	if p.IndexingThreshold > 0 {
+		cfg.IndexingThreshold = optionalUint64(p.IndexingThreshold)
+	}
+	return cfg
+}
+
+func valueOrDefault(v, def uint64) uint64 {
+	if v == 0 {
+		return def
+	}
+	return v
 }
 
 // apiKeyInterceptor creates a unary interceptor that adds API key to metadata
@@ -40,13 +119,24 @@ func apiKeyInterceptor(apiKey string) grpc.UnaryClientInterceptor {
 	}
 }
 
+// timeoutInterceptor applies a default deadline to RPCs whose context has no earlier one.
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := withOperationTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 // QdrantRepository handles vector operations with Qdrant.
 type QdrantRepository struct {
-	conn            *grpc.ClientConn
-	pointsClient    pb.PointsClient
+	conns           []*grpc.ClientConn
+	pointsClients   []pb.PointsClient
+	nextPoints      atomic.Uint64
 	collectClient   pb.CollectionsClient
 	collectionName  string
 	vectorDimension int
+	params          CollectionParams
 }
 
 // NewQdrantRepository creates a new QdrantRepository.
@@ -58,11 +148,14 @@ type QdrantRepository struct {
 //   - error: non-nil if the connection cannot be established.
 //
 // Supports both local Qdrant (insecure) and Qdrant Cloud (TLS + API Key).
+// Connections send keepalive pings when configured, reconnect with backoff, and
+// points traffic is spread across PoolSize connections.
 func NewQdrantRepository(cfg *QdrantConnectionConfig) (*QdrantRepository, error) {
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
 	// Build gRPC dial options
 	var opts []grpc.DialOption
+	var interceptors []grpc.UnaryClientInterceptor
 
 	// Determine if TLS should be used
 	// TLS is enabled if: APIKey is set OR UseTLS is explicitly true
@@ -78,16 +171,60 @@ func NewQdrantRepository(cfg *QdrantConnectionConfig) (*QdrantRepository, error)
 
 		// Add API Key authentication if provided (using unary interceptor)
 		if cfg.APIKey != "" {
-			opts = append(opts, grpc.WithUnaryInterceptor(apiKeyInterceptor(cfg.APIKey)))
+			interceptors = append(interceptors, apiKeyInterceptor(cfg.APIKey))
 		}
 	} else {
 		// Local mode: no TLS, no authentication
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	conn, err := grpc.NewClient(addr, opts...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to qdrant: %w", err)
+	if cfg.OperationTimeout > 0 {
+		interceptors = append(interceptors, timeoutInterceptor(cfg.OperationTimeout))
+	}
+	if len(interceptors) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(interceptors...))
+	}
+
+	// Keep idle connections alive so load balancers don't silently drop them
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	// Reconnect quickly after a dropped connection and retry the failed call
+	opts = append(opts,
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  200 * time.Millisecond,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   10 * time.Second,
+			},
+			MinConnectTimeout: 5 * time.Second,
+		}),
+		grpc.WithDefaultServiceConfig(qdrantServiceConfig),
+	)
+
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+
+	conns := make([]*grpc.ClientConn, 0, poolSize)
+	pointsClients := make([]pb.PointsClient, 0, poolSize)
+	for i := 0; i < poolSize; i++ {
+		conn, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return nil, fmt.Errorf("failed to connect to qdrant: %w", err)
+		}
+		conns = append(conns, conn)
+		pointsClients = append(pointsClients, pb.NewPointsClient(conn))
 	}
 
 	// Use default dimension if not specified
@@ -97,20 +234,36 @@ func NewQdrantRepository(cfg *QdrantConnectionConfig) (*QdrantRepository, error)
 	}
 
 	return &QdrantRepository{
-		conn:            conn,
-		pointsClient:    pb.NewPointsClient(conn),
-		collectClient:   pb.NewCollectionsClient(conn),
+		conns:           conns,
+		pointsClients:   pointsClients,
+		collectClient:   pb.NewCollectionsClient(conns[0]),
 		collectionName:  cfg.Collection,
 		vectorDimension: vectorDim,
+		params:          cfg.Params,
 	}, nil
 }
 
-// Close closes the gRPC connection.
+// Close closes all pooled gRPC connections.
 // Parameters: none.
 // Returns:
-//   - error: non-nil if closing the connection fails.
+//   - error: non-nil if closing any connection fails.
 func (r *QdrantRepository) Close() error {
-	return r.conn.Close()
+	var errs []error
+	for _, conn := range r.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// points returns the next points client in round-robin order.
+func (r *QdrantRepository) points() pb.PointsClient {
+	if len(r.pointsClients) == 1 {
+		return r.pointsClients[0]
+	}
+	idx := r.nextPoints.Add(1) - 1
+	return r.pointsClients[idx%uint64(len(r.pointsClients))]
 }
 
 // EnsureCollection creates the collection if it doesn't exist.
@@ -137,6 +290,14 @@ func (r *QdrantRepository) EnsureCollection(ctx context.Context) error {
 		return nil
 	}
 
+	denseParams := &pb.VectorParams{
+		Size:     uint64(r.vectorDimension),
+		Distance: pb.Distance_Cosine,
+	}
+	if r.params.OnDiskVectors {
+		denseParams.OnDisk = optionalBool(true)
+	}
+
 	// Create collection with named vectors (dense + sparse)
 	_, err = r.collectClient.Create(ctx, &pb.CreateCollection{
 		CollectionName: r.collectionName,
@@ -144,22 +305,17 @@ func (r *QdrantRepository) EnsureCollection(ctx context.Context) error {
 			Config: &pb.VectorsConfig_ParamsMap{
 				ParamsMap: &pb.VectorParamsMap{
 					Map: map[string]*pb.VectorParams{
-						DenseVectorName: {
-							Size:     uint64(r.vectorDimension),
-							Distance: pb.Distance_Cosine,
-						},
+						DenseVectorName: denseParams,
 					},
 				},
 			},
 		},
 		SparseVectorsConfig: pb.NewSparseVectorsConfig(map[string]*pb.SparseVectorParams{
-			SparseVectorName: {},
+			SparseVectorName:        {},
+			LearnedSparseVectorName: {},
 		}),
-		HnswConfig: &pb.HnswConfigDiff{
-			M:                 optionalUint64(16),
-			EfConstruct:       optionalUint64(128),
-			FullScanThreshold: optionalUint64(10000),
-		},
+		HnswConfig:       r.params.hnswConfig(),
+		OptimizersConfig: r.params.optimizersConfig(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
@@ -168,20 +324,23 @@ func (r *QdrantRepository) EnsureCollection(ctx context.Context) error {
 	return nil
 }
 
+// ensureSparseConfig adds the BM25 and learned sparse vectors to collections
+// created before they existed.
 func (r *QdrantRepository) ensureSparseConfig(ctx context.Context, existing *pb.SparseVectorConfig) error {
-	if existing != nil {
-		if _, ok := existing.GetMap()[SparseVectorName]; ok {
-			return nil
-		}
-	}
-
 	paramsMap := make(map[string]*pb.SparseVectorParams)
-	if existing != nil {
-		for name, params := range existing.GetMap() {
-			paramsMap[name] = params
+	for name, params := range existing.GetMap() {
+		paramsMap[name] = params
+	}
+	missing := false
+	for _, name := range []string{SparseVectorName, LearnedSparseVectorName} {
+		if _, ok := paramsMap[name]; !ok {
+			paramsMap[name] = &pb.SparseVectorParams{}
+			missing = true
 		}
 	}
-	paramsMap[SparseVectorName] = &pb.SparseVectorParams{}
+	if !missing {
+		return nil
+	}
 
 	_, err := r.collectClient.Update(ctx, &pb.UpdateCollection{
 		CollectionName:      r.collectionName,
@@ -194,6 +353,38 @@ func (r *QdrantRepository) ensureSparseConfig(ctx context.Context, existing *pb.
 	return nil
 }
 
+// CollectionHealth is the state of a collection reported by Qdrant.
+type CollectionHealth struct {
+	Status string // green, yellow (optimizing), grey (optimization pending) or red
+	Points uint64
+}
+
+// CheckCollection fetches the collection info without changing anything.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - *CollectionHealth: collection status and point count.
+//   - error: non-nil if Qdrant is unreachable, the collection is missing or
+//     its status is red.
+func (r *QdrantRepository) CheckCollection(ctx context.Context) (*CollectionHealth, error) {
+	info, err := r.collectClient.Get(ctx, &pb.GetCollectionInfoRequest{
+		CollectionName: r.collectionName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	result := info.GetResult()
+	health := &CollectionHealth{
+		Status: strings.ToLower(result.GetStatus().String()),
+		Points: result.GetPointsCount(),
+	}
+	if result.GetStatus() == pb.CollectionStatus_Red {
+		return health, fmt.Errorf("collection %s status is red", r.collectionName)
+	}
+	return health, nil
+}
+
 // GetCollectionName returns the collection name.
 // Parameters: none.
 // Returns:
@@ -222,6 +413,10 @@ func optionalString(v string) *string {
 	return &v
 }
 
+func optionalBool(v bool) *bool {
+	return &v
+}
+
 // MemePayload represents the payload stored with each vector.
 type MemePayload struct {
 	MemeID         string   `json:"meme_id"`
@@ -231,6 +426,13 @@ type MemePayload struct {
 	VLMDescription string   `json:"vlm_description"`
 	OCRText        string   `json:"ocr_text"`
 	StorageURL     string   `json:"storage_url"`
+	PosterURL      string   `json:"poster_url,omitempty"`     // Static poster frame for animated memes
+	Colors         []string `json:"colors,omitempty"`         // Named palette colors; omitted when the palette is unknown
+	Saturation     float64  `json:"saturation,omitempty"`     // Mean palette saturation (0-1)
+	TextLang       string   `json:"text_lang,omitempty"`      // Language of the OCR text (zh, en, ja); empty without text
+	SceneTags      []string `json:"scene_tags,omitempty"`     // Usage scenes from the optional scene tagger
+	Chunk          int      `json:"chunk,omitempty"`          // Description chunk of an extra caption point; 0 for the main point
+	PendingReview  bool     `json:"pending_review,omitempty"` // Meme awaiting moderation; searches skip it
 }
 
 // Upsert inserts or updates a vector with payload.
@@ -259,19 +461,11 @@ func (r *QdrantRepository) Upsert(ctx context.Context, pointID string, vector []
 			Vectors: pb.NewVectorsMap(map[string]*pb.Vector{
 				DenseVectorName: pb.NewVectorDense(vector),
 			}),
-			Payload: map[string]*pb.Value{
-				"meme_id":         {Kind: &pb.Value_StringValue{StringValue: payload.MemeID}},
-				"source_type":     {Kind: &pb.Value_StringValue{StringValue: payload.SourceType}},
-				"category":        {Kind: &pb.Value_StringValue{StringValue: payload.Category}},
-				"vlm_description": {Kind: &pb.Value_StringValue{StringValue: payload.VLMDescription}},
-				"ocr_text":        {Kind: &pb.Value_StringValue{StringValue: payload.OCRText}},
-				"storage_url":     {Kind: &pb.Value_StringValue{StringValue: payload.StorageURL}},
-				"tags":            tagsToValue(payload.Tags),
-			},
+			Payload: buildPayload(guardPayload(ctx, payload)),
 		},
 	}
 
-	_, err = r.pointsClient.Upsert(ctx, &pb.UpsertPoints{
+	_, err = r.points().Upsert(ctx, &pb.UpsertPoints{
 		CollectionName: r.collectionName,
 		Points:         points,
 	})
@@ -293,6 +487,23 @@ func (r *QdrantRepository) Upsert(ctx context.Context, pointID string, vector []
 // Returns:
 //   - error: non-nil if the upsert fails.
 func (r *QdrantRepository) UpsertHybrid(ctx context.Context, pointID string, vector []float32, bm25Text string, payload *MemePayload) error {
+	return r.UpsertHybridLearned(ctx, pointID, vector, bm25Text, nil, payload)
+}
+
+// UpsertHybridLearned is UpsertHybrid that also writes a client-encoded sparse
+// vector under LearnedSparseVectorName. Upserts replace all vectors of a point,
+// so writers of learned vectors must pass them on every upsert.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointID: UUID string for the vector point.
+//   - vector: dense embedding vector values.
+//   - bm25Text: text used for server-side BM25 sparse vector generation.
+//   - learned: optional learned sparse vector (nil omits it).
+//   - payload: metadata payload stored with the vector.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *QdrantRepository) UpsertHybridLearned(ctx context.Context, pointID string, vector []float32, bm25Text string, learned *SparseVector, payload *MemePayload) error {
 	uid, err := uuid.Parse(pointID)
 	if err != nil {
 		return fmt.Errorf("invalid point ID: %w", err)
@@ -313,6 +524,13 @@ func (r *QdrantRepository) UpsertHybrid(ctx context.Context, pointID string, vec
 		vectorsMap[SparseVectorName] = pb.NewVectorDocument(doc)
 	}
 
+	if learned != nil && len(learned.Indices) > 0 {
+		if len(learned.Indices) != len(learned.Values) {
+			return fmt.Errorf("sparse vector has %d indices but %d values", len(learned.Indices), len(learned.Values))
+		}
+		vectorsMap[LearnedSparseVectorName] = pb.NewVectorSparse(learned.Indices, learned.Values)
+	}
+
 	points := []*pb.PointStruct{
 		{
 			Id: &pb.PointId{
@@ -321,19 +539,11 @@ func (r *QdrantRepository) UpsertHybrid(ctx context.Context, pointID string, vec
 				},
 			},
 			Vectors: pb.NewVectorsMap(vectorsMap),
-			Payload: map[string]*pb.Value{
-				"meme_id":         {Kind: &pb.Value_StringValue{StringValue: payload.MemeID}},
-				"source_type":     {Kind: &pb.Value_StringValue{StringValue: payload.SourceType}},
-				"category":        {Kind: &pb.Value_StringValue{StringValue: payload.Category}},
-				"vlm_description": {Kind: &pb.Value_StringValue{StringValue: payload.VLMDescription}},
-				"ocr_text":        {Kind: &pb.Value_StringValue{StringValue: payload.OCRText}},
-				"storage_url":     {Kind: &pb.Value_StringValue{StringValue: payload.StorageURL}},
-				"tags":            tagsToValue(payload.Tags),
-			},
+			Payload: buildPayload(guardPayload(ctx, payload)),
 		},
 	}
 
-	_, err = r.pointsClient.Upsert(ctx, &pb.UpsertPoints{
+	_, err = r.points().Upsert(ctx, &pb.UpsertPoints{
 		CollectionName: r.collectionName,
 		Points:         points,
 	})
@@ -366,7 +576,7 @@ func (r *QdrantRepository) UpdateSparseVector(ctx context.Context, pointID strin
 		SparseVectorName: pb.NewVectorDocument(doc),
 	})
 
-	_, err = r.pointsClient.UpdateVectors(ctx, &pb.UpdatePointVectors{
+	_, err = r.points().UpdateVectors(ctx, &pb.UpdatePointVectors{
 		CollectionName: r.collectionName,
 		Points: []*pb.PointVectors{
 			{
@@ -386,6 +596,52 @@ func (r *QdrantRepository) UpdateSparseVector(ctx context.Context, pointID strin
 	return nil
 }
 
+// SparseVector is a client-encoded sparse vector of term indices and weights.
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+// UpdateLearnedSparseVector writes a client-encoded sparse vector to an
+// existing point, leaving its other vectors and payload unchanged.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointID: UUID string for the vector point.
+//   - vector: sparse indices and weights; must have equal lengths.
+//
+// Returns:
+//   - error: non-nil if the ID or vector is invalid or the update fails.
+func (r *QdrantRepository) UpdateLearnedSparseVector(ctx context.Context, pointID string, vector SparseVector) error {
+	uid, err := uuid.Parse(pointID)
+	if err != nil {
+		return fmt.Errorf("invalid point ID: %w", err)
+	}
+	if len(vector.Indices) != len(vector.Values) {
+		return fmt.Errorf("sparse vector has %d indices but %d values", len(vector.Indices), len(vector.Values))
+	}
+
+	_, err = r.points().UpdateVectors(ctx, &pb.UpdatePointVectors{
+		CollectionName: r.collectionName,
+		Points: []*pb.PointVectors{
+			{
+				Id: &pb.PointId{
+					PointIdOptions: &pb.PointId_Uuid{
+						Uuid: uid.String(),
+					},
+				},
+				Vectors: pb.NewVectorsMap(map[string]*pb.Vector{
+					LearnedSparseVectorName: pb.NewVectorSparse(vector.Indices, vector.Values),
+				}),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update learned sparse vector: %w", err)
+	}
+
+	return nil
+}
+
 func tagsToValue(tags []string) *pb.Value {
 	values := make([]*pb.Value, len(tags))
 	for i, tag := range tags {
@@ -412,6 +668,14 @@ type HybridSearchPlan struct {
 	DenseLimit  int
 	SparseLimit int
 	RRFK        uint32
+	Grouping    *SearchGrouping // Optional: cap results sharing a payload value
+}
+
+// SearchGrouping limits how many results may share one payload value, using
+// Qdrant's group_by.
+type SearchGrouping struct {
+	Field string // Keyword payload field, e.g. category
+	Size  int    // Maximum results per distinct value
 }
 
 // Search performs a vector similarity search.
@@ -435,12 +699,9 @@ func (r *QdrantRepository) Search(ctx context.Context, vector []float32, topK in
 		},
 	}
 
-	// Apply filters if provided
-	if filters != nil {
-		req.Filter = buildFilter(filters)
-	}
+	req.Filter = searchFilter(filters)
 
-	resp, err := r.pointsClient.Search(ctx, req)
+	resp, err := r.points().Search(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -457,6 +718,71 @@ func (r *QdrantRepository) Search(ctx context.Context, vector []float32, topK in
 	return results, nil
 }
 
+// SearchGroups performs a dense vector search returning at most grouping.Size
+// results per value of grouping.Field.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - vector: query embedding vector.
+//   - topK: maximum number of results to return.
+//   - grouping: payload field and per-group limit.
+//   - filters: optional filter criteria for the search.
+//
+// Returns:
+//   - []SearchResult: results ordered by score.
+//   - error: non-nil if the search fails.
+func (r *QdrantRepository) SearchGroups(ctx context.Context, vector []float32, topK int, grouping *SearchGrouping, filters *SearchFilters) ([]SearchResult, error) {
+	if grouping == nil {
+		return r.Search(ctx, vector, topK, filters)
+	}
+
+	resp, err := r.points().SearchGroups(ctx, &pb.SearchPointGroups{
+		CollectionName: r.collectionName,
+		Vector:         vector,
+		VectorName:     optionalString(DenseVectorName),
+		Filter:         searchFilter(filters),
+		Limit:          uint32(topK),
+		GroupBy:        grouping.Field,
+		GroupSize:      uint32(grouping.Size),
+		WithPayload: &pb.WithPayloadSelector{
+			SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups: %w", err)
+	}
+
+	return flattenGroups(resp.GetResult().GetGroups(), topK), nil
+}
+
+// flattenGroups merges the hits of all groups into one list ordered by score.
+// Qdrant returns up to topK groups, so the best topK hits are kept.
+func flattenGroups(groups []*pb.PointGroup, topK int) []SearchResult {
+	var results []SearchResult
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for _, scored := range group.GetHits() {
+			id := scored.GetId().GetUuid()
+			// A point with several values of the field appears in each of their groups
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			results = append(results, SearchResult{
+				ID:      id,
+				Score:   scored.GetScore(),
+				Payload: parsePayload(scored.GetPayload()),
+			})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
 // SparseSearch performs a BM25 sparse-vector search.
 func (r *QdrantRepository) SparseSearch(ctx context.Context, queryText string, topK int, filters *SearchFilters) ([]SearchResult, error) {
 	queryText = strings.TrimSpace(queryText)
@@ -477,11 +803,9 @@ func (r *QdrantRepository) SparseSearch(ctx context.Context, queryText string, t
 		Limit:       optionalUint64(uint64(topK)),
 		WithPayload: pb.NewWithPayload(true),
 	}
-	if filters != nil {
-		req.Filter = buildFilter(filters)
-	}
+	req.Filter = searchFilter(filters)
 
-	resp, err := r.pointsClient.Query(ctx, req)
+	resp, err := r.points().Query(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sparse query: %w", err)
 	}
@@ -524,7 +848,7 @@ func (r *QdrantRepository) HybridSearch(
 		topK = 20
 	}
 
-	filter := buildFilter(filters)
+	filter := searchFilter(filters)
 	prefetch := make([]*pb.PrefetchQuery, 0, 2)
 
 	if plan.UseDense && len(denseVector) > 0 {
@@ -556,6 +880,22 @@ func (r *QdrantRepository) HybridSearch(
 
 	query := pb.NewQueryRRF(&pb.Rrf{K: optionalUint32(plan.RRFK)})
 
+	if plan.Grouping != nil {
+		resp, err := r.points().QueryGroups(ctx, &pb.QueryPointGroups{
+			CollectionName: r.collectionName,
+			Prefetch:       prefetch,
+			Query:          query,
+			Limit:          optionalUint64(uint64(topK)),
+			GroupSize:      optionalUint64(uint64(plan.Grouping.Size)),
+			GroupBy:        plan.Grouping.Field,
+			WithPayload:    pb.NewWithPayload(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query groups: %w", err)
+		}
+		return flattenGroups(resp.GetResult().GetGroups(), topK), nil
+	}
+
 	req := &pb.QueryPoints{
 		CollectionName: r.collectionName,
 		Prefetch:       prefetch,
@@ -564,7 +904,7 @@ func (r *QdrantRepository) HybridSearch(
 		WithPayload:    pb.NewWithPayload(true),
 	}
 
-	resp, err := r.pointsClient.Query(ctx, req)
+	resp, err := r.points().Query(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query: %w", err)
 	}
@@ -583,8 +923,37 @@ func (r *QdrantRepository) HybridSearch(
 
 // SearchFilters defines optional filters for search.
 type SearchFilters struct {
-	Category   *string
-	SourceType *string
+	Category      *string
+	SourceType    *string
+	MemeID        *string  // Points of one meme, including description chunk points
+	Color         *string  // Named color that must appear in the meme's palette
+	MinSaturation *float64 // Lower bound on mean palette saturation
+	MaxSaturation *float64 // Upper bound on mean palette saturation
+	TextLang      *string  // Language of the overlaid text
+	Scene         *string  // Scene tag that must be present
+}
+
+// pendingReviewKey is the payload flag of memes awaiting moderation.
+const pendingReviewKey = "pending_review"
+
+// searchFilter returns the filter of a search: the conditions of filters,
+// skipping points of memes awaiting moderation. Points without the flag match.
+func searchFilter(filters *SearchFilters) *pb.Filter {
+	filter := buildFilter(filters)
+	if filter == nil {
+		filter = &pb.Filter{}
+	}
+	filter.MustNot = append(filter.MustNot, &pb.Condition{
+		ConditionOneOf: &pb.Condition_Field{
+			Field: &pb.FieldCondition{
+				Key: pendingReviewKey,
+				Match: &pb.Match{
+					MatchValue: &pb.Match_Boolean{Boolean: true},
+				},
+			},
+		},
+	})
+	return filter
 }
 
 func buildFilter(filters *SearchFilters) *pb.Filter {
@@ -620,6 +989,72 @@ func buildFilter(filters *SearchFilters) *pb.Filter {
 		})
 	}
 
+	if filters.MemeID != nil && *filters.MemeID != "" {
+		conditions = append(conditions, &pb.Condition{
+			ConditionOneOf: &pb.Condition_Field{
+				Field: &pb.FieldCondition{
+					Key: "meme_id",
+					Match: &pb.Match{
+						MatchValue: &pb.Match_Keyword{Keyword: *filters.MemeID},
+					},
+				},
+			},
+		})
+	}
+
+	if filters.Color != nil && *filters.Color != "" {
+		conditions = append(conditions, &pb.Condition{
+			ConditionOneOf: &pb.Condition_Field{
+				Field: &pb.FieldCondition{
+					Key: "colors",
+					Match: &pb.Match{
+						MatchValue: &pb.Match_Keyword{Keyword: *filters.Color},
+					},
+				},
+			},
+		})
+	}
+
+	if filters.TextLang != nil && *filters.TextLang != "" {
+		conditions = append(conditions, &pb.Condition{
+			ConditionOneOf: &pb.Condition_Field{
+				Field: &pb.FieldCondition{
+					Key: "text_lang",
+					Match: &pb.Match{
+						MatchValue: &pb.Match_Keyword{Keyword: *filters.TextLang},
+					},
+				},
+			},
+		})
+	}
+
+	if filters.Scene != nil && *filters.Scene != "" {
+		conditions = append(conditions, &pb.Condition{
+			ConditionOneOf: &pb.Condition_Field{
+				Field: &pb.FieldCondition{
+					Key: "scene_tags",
+					Match: &pb.Match{
+						MatchValue: &pb.Match_Keyword{Keyword: *filters.Scene},
+					},
+				},
+			},
+		})
+	}
+
+	if filters.MinSaturation != nil || filters.MaxSaturation != nil {
+		conditions = append(conditions, &pb.Condition{
+			ConditionOneOf: &pb.Condition_Field{
+				Field: &pb.FieldCondition{
+					Key: "saturation",
+					Range: &pb.Range{
+						Gte: filters.MinSaturation,
+						Lte: filters.MaxSaturation,
+					},
+				},
+			},
+		})
+	}
+
 	if len(conditions) == 0 {
 		return nil
 	}
@@ -629,6 +1064,44 @@ func buildFilter(filters *SearchFilters) *pb.Filter {
 	}
 }
 
+// buildPayload converts a meme payload to Qdrant values.
+func buildPayload(payload *MemePayload) map[string]*pb.Value {
+	values := map[string]*pb.Value{
+		"meme_id":         stringValue(payload.MemeID),
+		"source_type":     stringValue(payload.SourceType),
+		"category":        stringValue(payload.Category),
+		"vlm_description": stringValue(payload.VLMDescription),
+		"ocr_text":        stringValue(payload.OCRText),
+		"storage_url":     stringValue(payload.StorageURL),
+		"poster_url":      stringValue(payload.PosterURL),
+		"tags":            tagsToValue(payload.Tags),
+	}
+	setOptionalPayload(values, payload)
+	return values
+}
+
+func stringValue(v string) *pb.Value {
+	return &pb.Value{Kind: &pb.Value_StringValue{StringValue: v}}
+}
+
+// setOptionalPayload adds fields that are only known for some memes. Unknown
+// values are left out rather than zeroed, so filters on them never match.
+func setOptionalPayload(values map[string]*pb.Value, payload *MemePayload) {
+	if len(payload.Colors) > 0 {
+		values["colors"] = tagsToValue(payload.Colors)
+		values["saturation"] = &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: payload.Saturation}}
+	}
+	if payload.TextLang != "" {
+		values["text_lang"] = &pb.Value{Kind: &pb.Value_StringValue{StringValue: payload.TextLang}}
+	}
+	if len(payload.SceneTags) > 0 {
+		values["scene_tags"] = tagsToValue(payload.SceneTags)
+	}
+	if payload.PendingReview {
+		values[pendingReviewKey] = &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: true}}
+	}
+}
+
 func parsePayload(payload map[string]*pb.Value) *MemePayload {
 	if payload == nil {
 		return nil
@@ -653,6 +1126,29 @@ func parsePayload(payload map[string]*pb.Value) *MemePayload {
 	if v, ok := payload["storage_url"]; ok {
 		p.StorageURL = v.GetStringValue()
 	}
+	if v, ok := payload["poster_url"]; ok {
+		p.PosterURL = v.GetStringValue()
+	}
+	if v, ok := payload["colors"]; ok {
+		if list := v.GetListValue(); list != nil {
+			for _, item := range list.Values {
+				p.Colors = append(p.Colors, item.GetStringValue())
+			}
+		}
+	}
+	if v, ok := payload["saturation"]; ok {
+		p.Saturation = v.GetDoubleValue()
+	}
+	if v, ok := payload["text_lang"]; ok {
+		p.TextLang = v.GetStringValue()
+	}
+	if v, ok := payload["scene_tags"]; ok {
+		if list := v.GetListValue(); list != nil {
+			for _, item := range list.Values {
+				p.SceneTags = append(p.SceneTags, item.GetStringValue())
+			}
+		}
+	}
 	if v, ok := payload["tags"]; ok {
 		if list := v.GetListValue(); list != nil {
 			for _, item := range list.Values {
@@ -660,10 +1156,240 @@ func parsePayload(payload map[string]*pb.Value) *MemePayload {
 			}
 		}
 	}
+	if v, ok := payload[pendingReviewKey]; ok {
+		p.PendingReview = v.GetBoolValue()
+	}
 
 	return p
 }
 
+// PayloadUpdate lists payload fields to change on existing points. Nil fields
+// are left as they are.
+type PayloadUpdate struct {
+	Category       *string
+	Tags           []string
+	VLMDescription *string
+	OCRText        *string
+	StorageURL     *string
+	PosterURL      *string
+	TextLang       *string
+	SceneTags      []string
+}
+
+// values returns the Qdrant values of the set fields.
+func (u *PayloadUpdate) values() map[string]*pb.Value {
+	values := make(map[string]*pb.Value)
+	for key, field := range map[string]*string{
+		"category":        u.Category,
+		"vlm_description": u.VLMDescription,
+		"ocr_text":        u.OCRText,
+		"storage_url":     u.StorageURL,
+		"poster_url":      u.PosterURL,
+		"text_lang":       u.TextLang,
+	} {
+		if field != nil {
+			values[key] = stringValue(*field)
+		}
+	}
+	if u.Tags != nil {
+		values["tags"] = tagsToValue(u.Tags)
+	}
+	if u.SceneTags != nil {
+		values["scene_tags"] = tagsToValue(u.SceneTags)
+	}
+	return values
+}
+
+// SetPayload changes payload fields of existing points without touching their
+// vectors or their other fields.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointIDs: UUID strings for the vector points.
+//   - update: fields to set.
+//
+// Returns:
+//   - error: non-nil if an ID is invalid, no field is set, or the update fails.
+func (r *QdrantRepository) SetPayload(ctx context.Context, pointIDs []string, update *PayloadUpdate) error {
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	values := guardPayloadUpdate(update).values()
+	if len(values) == 0 {
+		return errors.New("payload update sets no fields")
+	}
+	ids, err := parsePointIDs(pointIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.points().SetPayload(ctx, &pb.SetPayloadPoints{
+		CollectionName: r.collectionName,
+		Payload:        values,
+		PointsSelector: pointsSelector(ids),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set payload: %w", err)
+	}
+
+	return nil
+}
+
+// SetPayloadByMemeID changes payload fields of every point of a meme,
+// including its description chunk points.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme ID stored in the point payload.
+//   - update: fields to set.
+//
+// Returns:
+//   - error: non-nil if memeID is empty, no field is set, or the update fails.
+func (r *QdrantRepository) SetPayloadByMemeID(ctx context.Context, memeID string, update *PayloadUpdate) error {
+	filter := buildFilter(&SearchFilters{MemeID: &memeID})
+	if filter == nil {
+		return errors.New("meme ID is required")
+	}
+	values := guardPayloadUpdate(update).values()
+	if len(values) == 0 {
+		return errors.New("payload update sets no fields")
+	}
+
+	wait := true
+	_, err := r.points().SetPayload(ctx, &pb.SetPayloadPoints{
+		CollectionName: r.collectionName,
+		Wait:           &wait,
+		Payload:        values,
+		PointsSelector: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{Filter: filter},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set payload: %w", err)
+	}
+	return nil
+}
+
+// OverwritePayload replaces the whole payload of existing points, keeping their vectors.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointIDs: UUID strings for the vector points.
+//   - payload: the new payload.
+//
+// Returns:
+//   - error: non-nil if an ID is invalid or the update fails.
+func (r *QdrantRepository) OverwritePayload(ctx context.Context, pointIDs []string, payload *MemePayload) error {
+	if len(pointIDs) == 0 {
+		return nil
+	}
+	ids, err := parsePointIDs(pointIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.points().OverwritePayload(ctx, &pb.SetPayloadPoints{
+		CollectionName: r.collectionName,
+		Payload:        buildPayload(guardPayload(ctx, payload)),
+		PointsSelector: pointsSelector(ids),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to overwrite payload: %w", err)
+	}
+
+	return nil
+}
+
+// parsePointIDs converts UUID strings to Qdrant point IDs.
+func parsePointIDs(pointIDs []string) ([]*pb.PointId, error) {
+	ids := make([]*pb.PointId, 0, len(pointIDs))
+	for _, pointID := range pointIDs {
+		uid, err := uuid.Parse(pointID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point ID %q: %w", pointID, err)
+		}
+		ids = append(ids, &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: uid.String()}})
+	}
+	return ids, nil
+}
+
+func pointsSelector(ids []*pb.PointId) *pb.PointsSelector {
+	return &pb.PointsSelector{
+		PointsSelectorOneOf: &pb.PointsSelector_Points{
+			Points: &pb.PointsIdsList{Ids: ids},
+		},
+	}
+}
+
+// Point is a stored point read back from the collection.
+type Point struct {
+	ID      string
+	Payload *MemePayload
+	Vector  []float32 // Dense vector; nil unless requested
+}
+
+// GetPoints reads points by ID. Unknown IDs are left out, and the result is
+// not guaranteed to follow the order of pointIDs.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointIDs: UUID strings for the vector points.
+//   - withVectors: also return the dense vector of each point.
+//
+// Returns:
+//   - []Point: the points found.
+//   - error: non-nil if an ID is invalid or the request fails.
+func (r *QdrantRepository) GetPoints(ctx context.Context, pointIDs []string, withVectors bool) ([]Point, error) {
+	if len(pointIDs) == 0 {
+		return []Point{}, nil
+	}
+	ids, err := parsePointIDs(pointIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := &pb.WithVectorsSelector{SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: false}}
+	if withVectors {
+		vectors = &pb.WithVectorsSelector{
+			SelectorOptions: &pb.WithVectorsSelector_Include{
+				Include: &pb.VectorsSelector{Names: []string{DenseVectorName}},
+			},
+		}
+	}
+
+	resp, err := r.points().Get(ctx, &pb.GetPoints{
+		CollectionName: r.collectionName,
+		Ids:            ids,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+		WithVectors:    vectors,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get points: %w", err)
+	}
+
+	points := make([]Point, 0, len(resp.GetResult()))
+	for _, retrieved := range resp.GetResult() {
+		points = append(points, Point{
+			ID:      retrieved.GetId().GetUuid(),
+			Payload: parsePayload(retrieved.GetPayload()),
+			Vector:  denseVector(retrieved.GetVectors()),
+		})
+	}
+	return points, nil
+}
+
+// denseVector extracts the dense vector from a point's vectors, whether it is
+// stored under DenseVectorName or as the collection's unnamed vector.
+func denseVector(vectors *pb.VectorsOutput) []float32 {
+	out := vectors.GetVector()
+	if named := vectors.GetVectors(); named != nil {
+		out = named.GetVectors()[DenseVectorName]
+	}
+	if out == nil {
+		return nil
+	}
+	if dense := out.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	return out.GetData()
+}
+
 // PointExists checks if a point exists by ID.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
@@ -678,7 +1404,7 @@ func (r *QdrantRepository) PointExists(ctx context.Context, pointID string) (boo
 		return false, fmt.Errorf("invalid point ID: %w", err)
 	}
 
-	resp, err := r.pointsClient.Get(ctx, &pb.GetPoints{
+	resp, err := r.points().Get(ctx, &pb.GetPoints{
 		CollectionName: r.collectionName,
 		Ids: []*pb.PointId{
 			{PointIdOptions: &pb.PointId_Uuid{Uuid: uid.String()}},
@@ -704,7 +1430,7 @@ func (r *QdrantRepository) Delete(ctx context.Context, pointID string) error {
 		return fmt.Errorf("invalid point ID: %w", err)
 	}
 
-	_, err = r.pointsClient.Delete(ctx, &pb.DeletePoints{
+	_, err = r.points().Delete(ctx, &pb.DeletePoints{
 		CollectionName: r.collectionName,
 		Points: &pb.PointsSelector{
 			PointsSelectorOneOf: &pb.PointsSelector_Points{
@@ -722,3 +1448,214 @@ func (r *QdrantRepository) Delete(ctx context.Context, pointID string) error {
 
 	return nil
 }
+
+// DeleteBatch removes several points by ID in one request.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - pointIDs: UUID strings for the vector points.
+//
+// Returns:
+//   - error: non-nil if an ID is invalid or the delete fails.
+func (r *QdrantRepository) DeleteBatch(ctx context.Context, pointIDs []string) error {
+	if len(pointIDs) == 0 {
+		return nil
+	}
+
+	ids, err := parsePointIDs(pointIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.points().Delete(ctx, &pb.DeletePoints{
+		CollectionName: r.collectionName,
+		Points:         pointsSelector(ids),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByFilter removes every point matching the filters and waits until the
+// delete is applied. An empty filter is rejected rather than clearing the collection.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filters: payload conditions the points must all match.
+//
+// Returns:
+//   - error: non-nil if the filter is empty or the delete fails.
+func (r *QdrantRepository) DeleteByFilter(ctx context.Context, filters *SearchFilters) error {
+	filter := buildFilter(filters)
+	if filter == nil {
+		return errors.New("delete by filter requires at least one condition")
+	}
+
+	wait := true
+	_, err := r.points().Delete(ctx, &pb.DeletePoints{
+		CollectionName: r.collectionName,
+		Wait:           &wait,
+		Points: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{Filter: filter},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete points by filter: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBySourceType removes every point whose payload source_type matches.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored in the point payload.
+//
+// Returns:
+//   - error: non-nil if sourceType is empty or the delete fails.
+func (r *QdrantRepository) DeleteBySourceType(ctx context.Context, sourceType string) error {
+	return r.DeleteByFilter(ctx, &SearchFilters{SourceType: &sourceType})
+}
+
+// DeleteByMemeID removes every point whose payload meme_id matches, including
+// the description chunk points of the meme.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme ID stored in the point payload.
+//
+// Returns:
+//   - error: non-nil if memeID is empty or the delete fails.
+func (r *QdrantRepository) DeleteByMemeID(ctx context.Context, memeID string) error {
+	return r.DeleteByFilter(ctx, &SearchFilters{MemeID: &memeID})
+}
+
+// ClearPendingReview removes the moderation flag from every point of a meme,
+// so searches return it.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme ID stored in the point payload.
+//
+// Returns:
+//   - error: non-nil if memeID is empty or the update fails.
+func (r *QdrantRepository) ClearPendingReview(ctx context.Context, memeID string) error {
+	filter := buildFilter(&SearchFilters{MemeID: &memeID})
+	if filter == nil {
+		return errors.New("meme ID is required")
+	}
+
+	wait := true
+	_, err := r.points().DeletePayload(ctx, &pb.DeletePayloadPoints{
+		CollectionName: r.collectionName,
+		Wait:           &wait,
+		Keys:           []string{pendingReviewKey},
+		PointsSelector: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{Filter: filter},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear pending review flag: %w", err)
+	}
+	return nil
+}
+
+// CountBySourceType counts the points whose payload source_type matches.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored in the point payload.
+//
+// Returns:
+//   - uint64: exact number of matching points.
+//   - error: non-nil if the count fails.
+func (r *QdrantRepository) CountBySourceType(ctx context.Context, sourceType string) (uint64, error) {
+	exact := true
+	resp, err := r.points().Count(ctx, &pb.CountPoints{
+		CollectionName: r.collectionName,
+		Filter:         buildFilter(&SearchFilters{SourceType: &sourceType}),
+		Exact:          &exact,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count points by source type: %w", err)
+	}
+	return resp.GetResult().GetCount(), nil
+}
+
+// ScrollPoints lists points with their payloads page by page.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - offset: point ID to start from; empty starts at the beginning.
+//   - limit: maximum number of points to return.
+//   - withVectors: also return the dense vector of each point.
+//
+// Returns:
+//   - []Point: points in this page.
+//   - string: offset for the next page, empty when the scroll is complete.
+//   - error: non-nil if the scroll fails.
+func (r *QdrantRepository) ScrollPoints(ctx context.Context, offset string, limit uint32, withVectors bool) ([]Point, string, error) {
+	vectors := &pb.WithVectorsSelector{SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: false}}
+	if withVectors {
+		vectors = &pb.WithVectorsSelector{
+			SelectorOptions: &pb.WithVectorsSelector_Include{
+				Include: &pb.VectorsSelector{Names: []string{DenseVectorName}},
+			},
+		}
+	}
+	req := &pb.ScrollPoints{
+		CollectionName: r.collectionName,
+		Limit:          &limit,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+		WithVectors:    vectors,
+	}
+	if offset != "" {
+		req.Offset = &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: offset}}
+	}
+
+	resp, err := r.points().Scroll(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll points: %w", err)
+	}
+
+	points := make([]Point, 0, len(resp.GetResult()))
+	for _, retrieved := range resp.GetResult() {
+		points = append(points, Point{
+			ID:      retrieved.GetId().GetUuid(),
+			Payload: parsePayload(retrieved.GetPayload()),
+			Vector:  denseVector(retrieved.GetVectors()),
+		})
+	}
+	return points, resp.GetNextPageOffset().GetUuid(), nil
+}
+
+// ScrollPointIDs lists point IDs in the collection page by page, without payloads or vectors.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - offset: point ID to start from; empty starts at the beginning.
+//   - limit: maximum number of IDs to return.
+//
+// Returns:
+//   - []string: point IDs in this page.
+//   - string: offset for the next page, empty when the scroll is complete.
+//   - error: non-nil if the scroll fails.
+func (r *QdrantRepository) ScrollPointIDs(ctx context.Context, offset string, limit uint32) ([]string, string, error) {
+	req := &pb.ScrollPoints{
+		CollectionName: r.collectionName,
+		Limit:          &limit,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: false}},
+		WithVectors:    &pb.WithVectorsSelector{SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: false}},
+	}
+	if offset != "" {
+		req.Offset = &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: offset}}
+	}
+
+	resp, err := r.points().Scroll(ctx, req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll points: %w", err)
+	}
+
+	ids := make([]string, 0, len(resp.GetResult()))
+	for _, point := range resp.GetResult() {
+		if uid := point.GetId().GetUuid(); uid != "" {
+			ids = append(ids, uid)
+		}
+	}
+	return ids, resp.GetNextPageOffset().GetUuid(), nil
+}
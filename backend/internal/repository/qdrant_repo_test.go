@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/qdrant/go-client/qdrant"
+)
+
+func TestCollectionParamsHNSWConfigFallsBackToDefaults(t *testing.T) {
+	t.Parallel()
+
+	hnsw := CollectionParams{}.hnswConfig()
+	if hnsw.GetM() != defaultHNSWM {
+		t.Fatalf("M = %d, want %d", hnsw.GetM(), defaultHNSWM)
+	}
+	if hnsw.GetEfConstruct() != defaultHNSWEfConstruct {
+		t.Fatalf("EfConstruct = %d, want %d", hnsw.GetEfConstruct(), defaultHNSWEfConstruct)
+	}
+	if hnsw.GetFullScanThreshold() != defaultFullScanThreshold {
+		t.Fatalf("FullScanThreshold = %d, want %d", hnsw.GetFullScanThreshold(), defaultFullScanThreshold)
+	}
+	if hnsw.OnDisk != nil {
+		t.Fatalf("OnDisk = %v, want unset", hnsw.GetOnDisk())
+	}
+	if optimizers := (CollectionParams{}).optimizersConfig(); optimizers != nil {
+		t.Fatalf("optimizersConfig() = %v, want nil", optimizers)
+	}
+}
+
+func TestCollectionParamsOverridesDefaults(t *testing.T) {
+	t.Parallel()
+
+	params := CollectionParams{
+		HNSWM:                32,
+		HNSWEfConstruct:      256,
+		OnDiskHNSW:           true,
+		DefaultSegmentNumber: 4,
+		IndexingThreshold:    50000,
+	}
+
+	hnsw := params.hnswConfig()
+	if hnsw.GetM() != 32 {
+		t.Fatalf("M = %d, want 32", hnsw.GetM())
+	}
+	if hnsw.GetEfConstruct() != 256 {
+		t.Fatalf("EfConstruct = %d, want 256", hnsw.GetEfConstruct())
+	}
+	if !hnsw.GetOnDisk() {
+		t.Fatal("OnDisk = false, want true")
+	}
+
+	optimizers := params.optimizersConfig()
+	if optimizers.GetDefaultSegmentNumber() != 4 {
+		t.Fatalf("DefaultSegmentNumber = %d, want 4", optimizers.GetDefaultSegmentNumber())
+	}
+	if optimizers.GetIndexingThreshold() != 50000 {
+		t.Fatalf("IndexingThreshold = %d, want 50000", optimizers.GetIndexingThreshold())
+	}
+}
+
+func TestNewQdrantRepositoryRoundRobinsPooledConnections(t *testing.T) {
+	t.Parallel()
+
+	repo, err := NewQdrantRepository(&QdrantConnectionConfig{
+		Host:          "localhost",
+		Port:          6334,
+		Collection:    "pool_test",
+		PoolSize:      3,
+		KeepaliveTime: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewQdrantRepository() error = %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	if len(repo.conns) != 3 {
+		t.Fatalf("pooled connections = %d, want 3", len(repo.conns))
+	}
+
+	first := repo.points()
+	second := repo.points()
+	third := repo.points()
+	fourth := repo.points()
+	if first == second || second == third {
+		t.Fatal("points() returned the same client for consecutive calls, want round-robin")
+	}
+	if first != fourth {
+		t.Fatal("points() did not wrap around to the first client")
+	}
+}
+
+func TestDeleteByFilterRejectsEmptyFilter(t *testing.T) {
+	t.Parallel()
+
+	repo, err := NewQdrantRepository(&QdrantConnectionConfig{
+		Host:       "localhost",
+		Port:       6334,
+		Collection: "delete_test",
+	})
+	if err != nil {
+		t.Fatalf("NewQdrantRepository() error = %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	empty := ""
+	for _, filters := range []*SearchFilters{nil, {}, {Category: &empty}} {
+		if err := repo.DeleteByFilter(context.Background(), filters); err == nil {
+			t.Fatalf("DeleteByFilter(%+v) error = nil, want empty filter rejected", filters)
+		}
+	}
+}
+
+func TestPayloadUpdateValuesOnlySetsGivenFields(t *testing.T) {
+	t.Parallel()
+
+	category := "猫猫"
+	emptyURL := ""
+	values := (&PayloadUpdate{Category: &category, PosterURL: &emptyURL, Tags: []string{}}).values()
+	if len(values) != 3 {
+		t.Fatalf("values() has %d fields, want category, poster_url and tags", len(values))
+	}
+	if got := values["category"].GetStringValue(); got != category {
+		t.Fatalf("category = %q, want %q", got, category)
+	}
+	if _, ok := values["poster_url"]; !ok {
+		t.Fatal("poster_url missing, want an explicit empty value to clear it")
+	}
+	if list := values["tags"].GetListValue(); list == nil || len(list.Values) != 0 {
+		t.Fatalf("tags = %v, want an empty list", values["tags"])
+	}
+	if _, ok := values["storage_url"]; ok {
+		t.Fatal("storage_url set, want unset fields left out")
+	}
+}
+
+func TestDenseVectorReadsNamedAndUnnamedVectors(t *testing.T) {
+	t.Parallel()
+
+	dense := &pb.VectorOutput{Vector: &pb.VectorOutput_Dense{Dense: &pb.DenseVector{Data: []float32{0.1, 0.2}}}}
+	tests := []struct {
+		name    string
+		vectors *pb.VectorsOutput
+		want    int
+	}{
+		{name: "none", vectors: nil, want: 0},
+		{name: "unnamed", vectors: &pb.VectorsOutput{VectorsOptions: &pb.VectorsOutput_Vector{Vector: dense}}, want: 2},
+		{name: "named", vectors: &pb.VectorsOutput{VectorsOptions: &pb.VectorsOutput_Vectors{Vectors: &pb.NamedVectorsOutput{
+			Vectors: map[string]*pb.VectorOutput{DenseVectorName: dense},
+		}}}, want: 2},
+		{name: "sparse only", vectors: &pb.VectorsOutput{VectorsOptions: &pb.VectorsOutput_Vectors{Vectors: &pb.NamedVectorsOutput{
+			Vectors: map[string]*pb.VectorOutput{SparseVectorName: {}},
+		}}}, want: 0},
+	}
+	for _, tt := range tests {
+		if got := denseVector(tt.vectors); len(got) != tt.want {
+			t.Fatalf("denseVector(%s) = %v, want %d values", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFlattenGroupsOrdersHitsByScore(t *testing.T) {
+	t.Parallel()
+
+	hit := func(id string, score float32) *pb.ScoredPoint {
+		return &pb.ScoredPoint{Id: &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: id}}, Score: score}
+	}
+	groups := []*pb.PointGroup{
+		{Hits: []*pb.ScoredPoint{hit("a", 0.9), hit("b", 0.5)}},
+		{Hits: []*pb.ScoredPoint{hit("c", 0.7), hit("a", 0.9)}},
+		{Hits: []*pb.ScoredPoint{hit("d", 0.1)}},
+	}
+	results := flattenGroups(groups, 3)
+	if len(results) != 3 || results[0].ID != "a" || results[1].ID != "c" || results[2].ID != "b" {
+		t.Fatalf("flattenGroups() = %+v, want a, c, b", results)
+	}
+}
+
+func TestGuardPayloadTruncatesLongText(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	short := &MemePayload{MemeID: "m", VLMDescription: "开心的猫"}
+	if got := guardPayload(ctx, short); got != short {
+		t.Fatalf("guardPayload(short) = %+v, want the payload unchanged", got)
+	}
+
+	long := &MemePayload{
+		MemeID:         "m",
+		VLMDescription: strings.Repeat("猫", MaxPayloadDescriptionRunes+50),
+		OCRText:        strings.Repeat("字", 4000),
+	}
+	got := guardPayload(ctx, long)
+	if n := len([]rune(got.VLMDescription)); n != MaxPayloadDescriptionRunes || !strings.HasSuffix(got.VLMDescription, truncationSuffix) {
+		t.Fatalf("description runes = %d, want %d ending in %q", n, MaxPayloadDescriptionRunes, truncationSuffix)
+	}
+	if n := len([]rune(got.OCRText)); n != MaxPayloadOCRRunes {
+		t.Fatalf("OCR runes = %d, want %d for an oversized payload", n, MaxPayloadOCRRunes)
+	}
+	if len([]rune(long.VLMDescription)) != MaxPayloadDescriptionRunes+50 {
+		t.Fatal("guardPayload modified its input")
+	}
+}
+
+func TestSearchFilterSkipsPendingReviewPoints(t *testing.T) {
+	t.Parallel()
+
+	category := "猫猫"
+	for _, filters := range []*SearchFilters{nil, {Category: &category}} {
+		filter := searchFilter(filters)
+		if len(filter.MustNot) != 1 || filter.MustNot[0].GetField().GetKey() != pendingReviewKey {
+			t.Fatalf("searchFilter(%+v).MustNot = %v, want the pending review flag excluded", filters, filter.MustNot)
+		}
+		if filters != nil && len(filter.Must) != 1 {
+			t.Fatalf("searchFilter(%+v).Must = %v, want the category condition kept", filters, filter.Must)
+		}
+	}
+
+	payload := parsePayload(buildPayload(&MemePayload{MemeID: "m", PendingReview: true}))
+	if !payload.PendingReview {
+		t.Fatal("parsePayload() PendingReview = false, want the flag round-tripped")
+	}
+	if _, ok := buildPayload(&MemePayload{MemeID: "m"})[pendingReviewKey]; ok {
+		t.Fatal("buildPayload() set pending_review on a published meme, want it left out")
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuarantineRepository handles quarantined ingest items.
+type QuarantineRepository struct {
+	db *gorm.DB
+}
+
+// NewQuarantineRepository creates a new QuarantineRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *QuarantineRepository: repository instance bound to db.
+func NewQuarantineRepository(db *gorm.DB) *QuarantineRepository {
+	return &QuarantineRepository{db: db}
+}
+
+// Upsert records a quarantined item, refreshing the reason if the same source
+// item was quarantined before.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - item: quarantined item to persist.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *QuarantineRepository) Upsert(ctx context.Context, item *domain.QuarantinedItem) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_type"}, {Name: "source_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"local_path", "md5_hash", "format", "file_size", "reason", "updated_at"}),
+	}).Create(item).Error
+}
+
+// List retrieves quarantined items, most recently updated first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of items to return.
+//   - offset: number of items to skip.
+//
+// Returns:
+//   - []domain.QuarantinedItem: quarantined items.
+//   - error: non-nil if the query fails.
+func (r *QuarantineRepository) List(ctx context.Context, limit, offset int) ([]domain.QuarantinedItem, error) {
+	var items []domain.QuarantinedItem
+	err := r.db.WithContext(ctx).
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, err
+}
+
+// Count returns the number of quarantined items.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - int64: number of quarantined items.
+//   - error: non-nil if the query fails.
+func (r *QuarantineRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.QuarantinedItem{}).Count(&count).Error
+	return count, err
+}
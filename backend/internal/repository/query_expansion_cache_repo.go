@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueryExpansionCacheRepository stores query expansions across restarts.
+type QueryExpansionCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewQueryExpansionCacheRepository creates a new QueryExpansionCacheRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *QueryExpansionCacheRepository: repository instance bound to db.
+func NewQueryExpansionCacheRepository(db *gorm.DB) *QueryExpansionCacheRepository {
+	return &QueryExpansionCacheRepository{db: db}
+}
+
+// Upsert stores an expansion, replacing an earlier one of the same query and version.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - entry: expansion to store.
+//
+// Returns:
+//   - error: non-nil if the write fails.
+func (r *QueryExpansionCacheRepository) Upsert(ctx context.Context, entry *domain.CachedExpansion) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "version"}, {Name: "query"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expansion", "updated_at"}),
+	}).Create(entry).Error
+}
+
+// ListRecent returns the most recently stored expansions of the versions.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - versions: expansion model and prompt versions, one per query language.
+//   - limit: maximum number of expansions to return.
+//
+// Returns:
+//   - []domain.CachedExpansion: expansions, newest first.
+//   - error: non-nil if the query fails.
+func (r *QueryExpansionCacheRepository) ListRecent(ctx context.Context, versions []string, limit int) ([]domain.CachedExpansion, error) {
+	var entries []domain.CachedExpansion
+	if err := r.db.WithContext(ctx).
+		Where("version IN ?", versions).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Prune deletes the expansions of other versions and those of versions stored
+// before a time.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - versions: versions to keep.
+//   - before: expansions of versions stored earlier are deleted (zero keeps them all).
+//
+// Returns:
+//   - int64: number of expansions deleted.
+//   - error: non-nil if the delete fails.
+func (r *QueryExpansionCacheRepository) Prune(ctx context.Context, versions []string, before time.Time) (int64, error) {
+	query := r.db.WithContext(ctx).Where("version NOT IN ?", versions)
+	if !before.IsZero() {
+		query = query.Or("updated_at < ?", before)
+	}
+	result := query.Delete(&domain.CachedExpansion{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// sqliteWriteSerializer is a GORM plugin that funnels autocommit writes through a
+// single mutex so concurrent workers queue in-process instead of racing for the
+// SQLite write lock. Statements running inside an explicit transaction are left
+// alone; those acquire the lock up front via BEGIN IMMEDIATE and wait on busy_timeout.
+//
+// The mutex wraps only the callbacks that run the SQL and is released by a
+// defer, so a panicking hook, Valuer or driver cannot leave it held and block
+// every later write. Hooks and association saves run outside of it.
+type sqliteWriteSerializer struct {
+	mu sync.Mutex
+}
+
+// callbackProcessor is the part of GORM's create, update, delete and raw
+// processors the serializer uses.
+type callbackProcessor interface {
+	Get(name string) func(*gorm.DB)
+	Replace(name string, fn func(*gorm.DB)) error
+}
+
+// Name implements gorm.Plugin.
+func (s *sqliteWriteSerializer) Name() string {
+	return "emomo:sqlite_write_serializer"
+}
+
+// Initialize implements gorm.Plugin by wrapping every write processor.
+func (s *sqliteWriteSerializer) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		s.wrap(cb.Create(), "gorm:create"),
+		s.wrap(cb.Update(), "gorm:update"),
+		s.wrap(cb.Delete(), "gorm:delete"),
+		s.wrap(cb.Raw(), "gorm:raw"),
+	)
+}
+
+// wrap replaces the callback name of p with one that runs it under the mutex.
+func (s *sqliteWriteSerializer) wrap(p callbackProcessor, name string) error {
+	next := p.Get(name)
+	if next == nil {
+		return fmt.Errorf("callback %s not registered", name)
+	}
+	return p.Replace(name, func(db *gorm.DB) {
+		if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+		}
+		next(db)
+	})
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/timmy/emomo/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StatsSnapshotRepository handles the daily rows of the stats history.
+type StatsSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsSnapshotRepository creates a new StatsSnapshotRepository.
+// Parameters:
+//   - db: GORM database handle used for queries.
+//
+// Returns:
+//   - *StatsSnapshotRepository: repository instance bound to db.
+func NewStatsSnapshotRepository(db *gorm.DB) *StatsSnapshotRepository {
+	return &StatsSnapshotRepository{db: db}
+}
+
+// AddQueries adds searches to the query count of a day, creating the row on
+// first use.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - date: day as YYYY-MM-DD.
+//   - queries: number of searches to add.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *StatsSnapshotRepository) AddQueries(ctx context.Context, date string, queries int64) error {
+	row := &domain.StatsSnapshot{Date: date, Queries: queries}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"queries":    gorm.Expr("stats_snapshots.queries + excluded.queries"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(row).Error
+}
+
+// SaveCounts stores the meme and vector counts of a day, keeping its query count.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - snapshot: counts of snapshot.Date; Queries is ignored for existing rows.
+//
+// Returns:
+//   - error: non-nil if the upsert fails.
+func (r *StatsSnapshotRepository) SaveCounts(ctx context.Context, snapshot *domain.StatsSnapshot) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_memes", "sources", "collections", "snapshot_at", "updated_at"}),
+	}).Create(snapshot).Error
+}
+
+// ListSince returns the rows from a day on, oldest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - since: first day as YYYY-MM-DD.
+//
+// Returns:
+//   - []domain.StatsSnapshot: daily rows; days without a row are missing.
+//   - error: non-nil if the query fails.
+func (r *StatsSnapshotRepository) ListSince(ctx context.Context, since string) ([]domain.StatsSnapshot, error) {
+	var rows []domain.StatsSnapshot
+	if err := r.db.WithContext(ctx).
+		Where("date >= ?", since).
+		Order("date ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
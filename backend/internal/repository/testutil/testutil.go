@@ -0,0 +1,42 @@
+// Package testutil provides the database of repository and service tests.
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewTestDB opens a SQLite database in a temporary directory through
+// repository.InitDB, so tests run against the schema, pragmas and write
+// serializer production uses. The database is closed when the test ends.
+// Parameters:
+//   - t: test that owns the database.
+//
+// Returns:
+//   - *gorm.DB: migrated database handle.
+func NewTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := repository.InitDB(&config.DatabaseConfig{
+		Driver:      "sqlite",
+		Path:        filepath.Join(t.TempDir(), "emomo.db"),
+		AutoMigrate: true,
+	})
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	// Statement logs of every test drown the failures
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
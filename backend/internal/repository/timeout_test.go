@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithOperationTimeoutKeepsEarlierCallerDeadline(t *testing.T) {
+	t.Parallel()
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+
+	ctx, release := withOperationTimeout(parent, time.Minute)
+	defer release()
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(parentDeadline) {
+		t.Fatalf("deadline = %v, want caller deadline %v", deadline, parentDeadline)
+	}
+
+	ctx, release = withOperationTimeout(context.Background(), time.Second)
+	defer release()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("withOperationTimeout() did not set a deadline on an unbounded context")
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// TxRepositories bundles repositories that share one database transaction.
+// A field is nil when the corresponding repository was not registered.
+type TxRepositories struct {
+	Memes        *MemeRepository
+	Vectors      *MemeVectorRepository
+	Descriptions *MemeDescriptionRepository
+}
+
+// UnitOfWork groups SQL writes across repositories into a single transaction.
+type UnitOfWork struct {
+	db    *gorm.DB
+	repos TxRepositories
+}
+
+// NewUnitOfWork creates a new UnitOfWork.
+// Parameters:
+//   - db: GORM database handle used to open transactions.
+//   - repos: repositories to rebind to each transaction; nil fields stay nil.
+//
+// Returns:
+//   - *UnitOfWork: unit of work bound to db.
+func NewUnitOfWork(db *gorm.DB, repos TxRepositories) *UnitOfWork {
+	return &UnitOfWork{db: db, repos: repos}
+}
+
+// Do runs fn inside a transaction. The transaction commits when fn returns nil
+// and rolls back on error or panic.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - fn: callback receiving repositories bound to the transaction.
+//
+// Returns:
+//   - error: the error returned by fn, or a commit failure.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos *TxRepositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repos := &TxRepositories{}
+		if u.repos.Memes != nil {
+			repos.Memes = u.repos.Memes.WithTx(tx)
+		}
+		if u.repos.Vectors != nil {
+			repos.Vectors = u.repos.Vectors.WithTx(tx)
+		}
+		if u.repos.Descriptions != nil {
+			repos.Descriptions = u.repos.Descriptions.WithTx(tx)
+		}
+		return fn(repos)
+	})
+}
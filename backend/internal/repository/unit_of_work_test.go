@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+)
+
+func TestUnitOfWorkRollsBackAllRepositoriesOnError(t *testing.T) {
+	t.Parallel()
+
+	db := newTestDB(t)
+
+	memeRepo := NewMemeRepository(db)
+	uow := NewUnitOfWork(db, TxRepositories{
+		Memes:   memeRepo,
+		Vectors: NewMemeVectorRepository(db),
+	})
+	ctx := context.Background()
+
+	vector := &domain.MemeVector{
+		ID:         "vector-1",
+		MemeID:     "meme-1",
+		MD5Hash:    "md5",
+		Collection: "memes",
+		Status:     domain.MemeVectorStatusActive,
+	}
+	err := uow.Do(ctx, func(repos *TxRepositories) error {
+		if repos.Descriptions != nil {
+			t.Fatal("Descriptions repository = non-nil, want nil when not registered")
+		}
+		if err := repos.Memes.Upsert(ctx, &domain.Meme{
+			ID:         "meme-1",
+			SourceType: "test",
+			SourceID:   "source-1",
+			MD5Hash:    "md5",
+			Status:     domain.MemeStatusActive,
+		}); err != nil {
+			return err
+		}
+		if err := repos.Vectors.Create(ctx, vector); err != nil {
+			return err
+		}
+		duplicate := *vector
+		duplicate.ID = "vector-2"
+		return repos.Vectors.Create(ctx, &duplicate)
+	})
+	if err == nil {
+		t.Fatal("Do() error = nil, want unique constraint violation")
+	}
+
+	var memeCount, vectorCount int64
+	if err := db.Model(&domain.Meme{}).Count(&memeCount).Error; err != nil {
+		t.Fatalf("count memes: %v", err)
+	}
+	if err := db.Model(&domain.MemeVector{}).Count(&vectorCount).Error; err != nil {
+		t.Fatalf("count vectors: %v", err)
+	}
+	if memeCount != 0 || vectorCount != 0 {
+		t.Fatalf("rows after rollback = (memes %d, vectors %d), want (0, 0)", memeCount, vectorCount)
+	}
+
+	if err := uow.Do(ctx, func(repos *TxRepositories) error {
+		return repos.Vectors.Create(ctx, vector)
+	}); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if err := db.Model(&domain.MemeVector{}).Count(&vectorCount).Error; err != nil {
+		t.Fatalf("count vectors: %v", err)
+	}
+	if vectorCount != 1 {
+		t.Fatalf("vectors after commit = %d, want 1", vectorCount)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// APIKeyScope grants a key created through the admin API access to a group
+// of routes.
+type APIKeyScope string
+
+const (
+	// APIKeyScopeSearch may use the public search, meme and stats routes.
+	APIKeyScopeSearch APIKeyScope = "search"
+	// APIKeyScopeIngest may trigger ingests and read their status.
+	APIKeyScopeIngest APIKeyScope = "ingest"
+	// APIKeyScopeAdmin may use every route, including /api/v1/admin.
+	APIKeyScopeAdmin APIKeyScope = "admin"
+
+	// managedKeyPrefix starts every secret created through the admin API, so
+	// they can be told apart from config keys without a database lookup.
+	managedKeyPrefix = "emk_"
+	// managedKeyShownChars is how much of a secret is kept to recognize it.
+	managedKeyShownChars = 12
+	// lastUsedInterval is how stale last_used_at may get before a request
+	// updates it.
+	lastUsedInterval = time.Minute
+)
+
+var (
+	// ErrInvalidKeyRequest wraps the reason a key could not be created.
+	ErrInvalidKeyRequest = errors.New("invalid api key request")
+	// ErrAPIKeyExpired is returned when a key is used after its expiry.
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrMissingRole is returned when a config key lacks the role of a route.
+	ErrMissingRole = errors.New("api key lacks the required role")
+	// ErrMissingScope is returned when a managed key lacks the scope of a route.
+	ErrMissingScope = errors.New("api key lacks the required scope")
+	// errNoKeyRepository is returned by key management without SetKeyRepository.
+	errNoKeyRepository = errors.New("api key repository is not configured")
+)
+
+// CreateKeyRequest describes a key to create.
+type CreateKeyRequest struct {
+	Name            string     `json:"name"`
+	Scopes          []string   `json:"scopes"`                     // search, ingest, admin
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`       // nil never expires
+	MonthlyRequests int64      `json:"monthly_requests,omitempty"` // Request quota (0 = unlimited)
+	MonthlyCost     float64    `json:"monthly_cost,omitempty"`     // Cost quota (0 = unlimited)
+}
+
+// CreatedKey is a new key with its secret, which is not stored and cannot be
+// shown again.
+type CreatedKey struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+// SetKeyRepository enables keys created through the admin API.
+// Parameters:
+//   - repo: repository of managed keys.
+//
+// Returns: none.
+func (s *UsageService) SetKeyRepository(repo *repository.APIKeyRepository) {
+	s.keyRepo = repo
+}
+
+// CreateKey creates a key and returns it with its secret.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: name, scopes, expiry and quotas of the key.
+//
+// Returns:
+//   - *CreatedKey: the stored key and its secret.
+//   - error: ErrInvalidKeyRequest for a bad request, or a repository error.
+func (s *UsageService) CreateKey(ctx context.Context, req CreateKeyRequest) (*CreatedKey, error) {
+	if s.keyRepo == nil {
+		return nil, errNoKeyRepository
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidKeyRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidKeyRequest)
+	}
+	scopes := make(domain.StringArray, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		switch APIKeyScope(scope) {
+		case APIKeyScopeSearch, APIKeyScopeIngest, APIKeyScopeAdmin:
+		default:
+			return nil, fmt.Errorf("%w: unknown scope %q (want search, ingest or admin)", ErrInvalidKeyRequest, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	now := s.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at is in the past", ErrInvalidKeyRequest)
+	}
+	if req.MonthlyRequests < 0 || req.MonthlyCost < 0 {
+		return nil, fmt.Errorf("%w: quotas must not be negative", ErrInvalidKeyRequest)
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	secret = managedKeyPrefix + secret
+
+	key := domain.APIKey{
+		ID:              "key_" + id,
+		Name:            name,
+		Prefix:          secret[:managedKeyShownChars],
+		KeyHash:         hashAPIKey(secret),
+		Scopes:          scopes,
+		MonthlyRequests: req.MonthlyRequests,
+		MonthlyCost:     req.MonthlyCost,
+		ExpiresAt:       req.ExpiresAt,
+		CreatedAt:       now,
+	}
+	if err := s.keyRepo.Create(ctx, &key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	logger.CtxInfo(ctx, "API key created: key_id=%s, name=%s, scopes=%v", key.ID, key.Name, []string(key.Scopes))
+	return &CreatedKey{APIKey: key, Key: secret}, nil
+}
+
+// ListKeys returns the keys created through the admin API, newest first.
+// Config keys are not listed.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - includeRevoked: whether revoked keys are listed too.
+//
+// Returns:
+//   - []domain.APIKey: the keys, without their secrets.
+//   - error: non-nil if the keys cannot be loaded.
+func (s *UsageService) ListKeys(ctx context.Context, includeRevoked bool) ([]domain.APIKey, error) {
+	if s.keyRepo == nil {
+		return nil, errNoKeyRepository
+	}
+	keys, err := s.keyRepo.List(ctx, includeRevoked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey revokes a key created through the admin API. Its usage history
+// is kept.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: key ID.
+//
+// Returns:
+//   - *domain.APIKey: the revoked key.
+//   - error: ErrUnknownAPIKey if no managed key has the ID, or a repository error.
+func (s *UsageService) RevokeKey(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	if s.keyRepo == nil {
+		return nil, errNoKeyRepository
+	}
+	if err := s.keyRepo.Revoke(ctx, keyID, s.now()); err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnknownAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key: %w", err)
+	}
+	logger.CtxInfo(ctx, "API key revoked: key_id=%s, name=%s", key.ID, key.Name)
+	return key, nil
+}
+
+// authenticateManaged returns the ID of the managed key whose secret is
+// secret, and records its use.
+func (s *UsageService) authenticateManaged(ctx context.Context, secret string) (string, error) {
+	key, err := s.keyRepo.GetByHash(ctx, hashAPIKey(secret))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrUnknownAPIKey
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load api key: %w", err)
+	}
+	now := s.now()
+	if key.RevokedAt != nil {
+		return "", ErrUnknownAPIKey
+	}
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return "", ErrAPIKeyExpired
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval {
+		if err := s.keyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			logger.CtxWarn(ctx, "Failed to record API key use: key_id=%s, error=%v", key.ID, err)
+		}
+	}
+	return key.ID, nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a secret. Secrets are random, so an
+// unsalted hash is enough to keep them out of the database.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes as hex.
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
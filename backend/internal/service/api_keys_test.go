@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestUsageServiceManagedKeys(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	s := NewUsageService(&config.APIKeysConfig{AdminAuth: true}, repository.NewAPIKeyUsageRepository(db))
+	keys := repository.NewAPIKeyRepository(db)
+	s.SetKeyRepository(keys)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, req := range []CreateKeyRequest{
+		{Scopes: []string{"search"}},
+		{Name: "partner"},
+		{Name: "partner", Scopes: []string{"root"}},
+		{Name: "partner", Scopes: []string{"search"}, ExpiresAt: &now},
+	} {
+		if _, err := s.CreateKey(ctx, req); !errors.Is(err, ErrInvalidKeyRequest) {
+			t.Fatalf("CreateKey(%+v) error = %v, want ErrInvalidKeyRequest", req, err)
+		}
+	}
+
+	expires := now.Add(time.Hour)
+	created, err := s.CreateKey(ctx, CreateKeyRequest{
+		Name:            " partner ",
+		Scopes:          []string{"search", "ingest", "search"},
+		ExpiresAt:       &expires,
+		MonthlyRequests: 10,
+	})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if created.Name != "partner" || len(created.Scopes) != 2 || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("CreateKey() = %+v, want a trimmed name, deduplicated scopes and a prefix of the secret", created)
+	}
+	stored, err := keys.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.KeyHash == created.Key || stored.KeyHash != hashAPIKey(created.Key) {
+		t.Fatalf("stored hash = %q, want the SHA-256 of the secret", stored.KeyHash)
+	}
+
+	id, err := s.Authenticate(ctx, created.Key)
+	if err != nil || id != created.ID {
+		t.Fatalf("Authenticate() = %q, %v, want %s", id, err, created.ID)
+	}
+	if stored, _ = keys.GetByID(ctx, id); stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(now) {
+		t.Fatalf("last_used_at = %v, want %v", stored.LastUsedAt, now)
+	}
+	if _, err := s.Authenticate(ctx, created.Key+"x"); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Fatalf("Authenticate(wrong secret) error = %v, want ErrUnknownAPIKey", err)
+	}
+
+	for _, tc := range []struct {
+		scope APIKeyScope
+		want  error
+	}{
+		{APIKeyScopeSearch, nil},
+		{APIKeyScopeIngest, nil},
+		{APIKeyScopeAdmin, ErrMissingScope},
+	} {
+		if err := s.Authorize(ctx, id, tc.scope, false); !errors.Is(err, tc.want) {
+			t.Fatalf("Authorize(%s) error = %v, want %v", tc.scope, err, tc.want)
+		}
+	}
+	if usage, err := s.GetUsage(ctx, id, 12); err != nil || usage.MonthlyRequests != 10 {
+		t.Fatalf("GetUsage() = %+v, %v, want the key's quota", usage, err)
+	}
+
+	now = expires
+	if _, err := s.Authenticate(ctx, created.Key); !errors.Is(err, ErrAPIKeyExpired) {
+		t.Fatalf("Authenticate() after expiry error = %v, want ErrAPIKeyExpired", err)
+	}
+
+	if _, err := s.RevokeKey(ctx, "key_missing"); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Fatalf("RevokeKey(missing) error = %v, want ErrUnknownAPIKey", err)
+	}
+	admin, err := s.CreateKey(ctx, CreateKeyRequest{Name: "ops", Scopes: []string{"admin"}})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+	if err := s.Authorize(ctx, admin.ID, APIKeyScopeIngest, false); err != nil {
+		t.Fatalf("Authorize(admin key, ingest) error = %v, want nil", err)
+	}
+	revoked, err := s.RevokeKey(ctx, admin.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("RevokeKey() = %+v, %v, want a revoked key", revoked, err)
+	}
+	if _, err := s.Authenticate(ctx, admin.Key); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Fatalf("Authenticate(revoked) error = %v, want ErrUnknownAPIKey", err)
+	}
+
+	listed, err := s.ListKeys(ctx, false)
+	if err != nil || len(listed) != 1 || listed[0].ID != created.ID {
+		t.Fatalf("ListKeys(false) = %+v, %v, want only the active key", listed, err)
+	}
+	if listed, _ = s.ListKeys(ctx, true); len(listed) != 2 {
+		t.Fatalf("ListKeys(true) returned %d keys, want 2", len(listed))
+	}
+}
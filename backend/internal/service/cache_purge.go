@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const defaultCachePurgeTimeout = 10 * time.Second
+
+// Surrogate keys tag cacheable responses so a CDN can purge them by content
+// instead of by URL.
+const (
+	SurrogateKeyMemes      = "memes"      // Every meme list and detail response
+	SurrogateKeyCategories = "categories" // The category list
+)
+
+// CategorySurrogateKey returns the key of meme lists filtered by category.
+// Categories are percent-encoded because CDNs only accept ASCII keys.
+// Parameters:
+//   - category: meme category.
+//
+// Returns:
+//   - string: surrogate key, e.g. "category-%E7%8C%AB%E7%8C%AB".
+func CategorySurrogateKey(category string) string {
+	return "category-" + url.PathEscape(category)
+}
+
+// MemeSurrogateKey returns the key of a meme detail response.
+// Parameters:
+//   - id: meme ID.
+//
+// Returns:
+//   - string: surrogate key, e.g. "meme-<id>".
+func MemeSurrogateKey(id string) string {
+	return "meme-" + id
+}
+
+// CachePurger invalidates cached responses tagged with surrogate keys.
+type CachePurger interface {
+	// Purge invalidates every response tagged with one of keys.
+	Purge(ctx context.Context, keys []string) error
+}
+
+// WebhookPurgerConfig configures a purge webhook.
+type WebhookPurgerConfig struct {
+	URL     string        // Endpoint receiving {"surrogate_keys": [...]}
+	Token   string        // Optional bearer token
+	Timeout time.Duration // Per-request timeout (0 uses 10s)
+}
+
+// WebhookPurger posts purged surrogate keys to a webhook, e.g. a worker that
+// calls the purge API of the CDN in front of the server.
+type WebhookPurger struct {
+	client *resty.Client
+	url    string
+}
+
+type purgeRequest struct {
+	SurrogateKeys []string `json:"surrogate_keys"`
+}
+
+// NewWebhookPurger creates a webhook purger.
+// Parameters:
+//   - cfg: webhook URL, token and timeout.
+//
+// Returns:
+//   - *WebhookPurger: initialized purger.
+//   - error: non-nil if no URL is configured.
+func NewWebhookPurger(cfg *WebhookPurgerConfig) (*WebhookPurger, error) {
+	if cfg == nil || strings.TrimSpace(cfg.URL) == "" {
+		return nil, fmt.Errorf("cache purge webhook url is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultCachePurgeTimeout
+	}
+
+	client := resty.New()
+	client.SetTimeout(timeout)
+	client.SetHeader("Content-Type", "application/json")
+	if cfg.Token != "" {
+		client.SetHeader("Authorization", "Bearer "+cfg.Token)
+	}
+	return &WebhookPurger{client: client, url: strings.TrimSpace(cfg.URL)}, nil
+}
+
+// Purge posts keys to the webhook.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keys: surrogate keys to purge.
+//
+// Returns:
+//   - error: non-nil if the request fails or the webhook answers with an error.
+func (p *WebhookPurger) Purge(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetBody(purgeRequest{SurrogateKeys: keys}).
+		Post(p.url)
+	if err != nil {
+		return fmt.Errorf("failed to call cache purge webhook: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("cache purge webhook error: status=%d, body=%s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+// SetCachePurger sets the purger notified when memes are added or removed.
+// Parameters:
+//   - purger: cache purger (nil disables purging).
+//
+// Returns: none.
+func (s *IngestService) SetCachePurger(purger CachePurger) {
+	s.purger = purger
+}
+
+// purgeCache purges keys, logging failures; cached responses then expire on
+// their own.
+func (s *IngestService) purgeCache(ctx context.Context, keys []string) {
+	if s.purger == nil || len(keys) == 0 {
+		return
+	}
+	// Purge after canceled runs as well: their committed memes are visible.
+	ctx, cancel := context.WithTimeout(logger.DetachContext(ctx), defaultCachePurgeTimeout)
+	defer cancel()
+	if err := s.purger.Purge(ctx, keys); err != nil {
+		logger.CtxWarn(ctx, "Failed to purge cached responses: keys=%v, error=%v", keys, err)
+		return
+	}
+	logger.CtxInfo(ctx, "Purged cached responses: keys=%v", keys)
+}
+
+// ingestPurgeKeys returns the keys of the responses an ingest run changed.
+func ingestPurgeKeys(report *IngestReport) []string {
+	if report == nil || report.New+report.Reused == 0 {
+		return nil
+	}
+	keys := []string{SurrogateKeyMemes, SurrogateKeyCategories}
+	categories := make([]string, 0, len(report.Categories))
+	for _, category := range report.Categories {
+		categories = append(categories, CategorySurrogateKey(category.Category))
+	}
+	sort.Strings(categories)
+	return append(keys, categories...)
+}
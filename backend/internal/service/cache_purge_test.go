@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestIngestPurgeKeys(t *testing.T) {
+	t.Parallel()
+
+	if keys := ingestPurgeKeys(&IngestReport{Skipped: 3}); keys != nil {
+		t.Fatalf("ingestPurgeKeys() without changes = %v, want nil", keys)
+	}
+
+	got := ingestPurgeKeys(&IngestReport{
+		New:        2,
+		Reused:     1,
+		Categories: []CategoryReport{{Category: "猫猫", New: 2}, {Category: "dogs", Reused: 1}},
+	})
+	want := []string{SurrogateKeyMemes, SurrogateKeyCategories, "category-%E7%8C%AB%E7%8C%AB", "category-dogs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ingestPurgeKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestWebhookPurger(t *testing.T) {
+	t.Parallel()
+
+	purger, err := NewWebhookPurger(&WebhookPurgerConfig{URL: "https://purge.test/hook", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewWebhookPurger() error = %v", err)
+	}
+	var got purgeRequest
+	purger.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() != "https://purge.test/hook" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request = %s with auth %q, want the webhook with bearer token", r.URL, r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode purge request: %v", err)
+		}
+		return jsonResponse(t, http.StatusOK, map[string]any{}), nil
+	}))
+
+	if err := purger.Purge(context.Background(), []string{"memes", "meme-1"}); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.SurrogateKeys, []string{"memes", "meme-1"}) {
+		t.Fatalf("purged keys = %v, want [memes meme-1]", got.SurrogateKeys)
+	}
+
+	purger.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return jsonResponse(t, http.StatusBadGateway, map[string]string{"error": "cdn down"}), nil
+	}))
+	if err := purger.Purge(context.Background(), []string{"memes"}); err == nil {
+		t.Fatal("Purge() with a failing webhook succeeded, want error")
+	}
+
+	if _, err := NewWebhookPurger(&WebhookPurgerConfig{}); err == nil {
+		t.Fatal("NewWebhookPurger() without a URL succeeded, want error")
+	}
+}
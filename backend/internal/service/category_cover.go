@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCoverCategoryMismatch is returned when a cover meme belongs to another category.
+	ErrCoverCategoryMismatch = errors.New("meme does not belong to the category")
+	// ErrCoverNotFound is returned when a category has no pinned cover.
+	ErrCoverNotFound = errors.New("category has no cover")
+)
+
+// CategoryCover is the cover image of a category in the category list.
+type CategoryCover struct {
+	MemeID    string `json:"meme_id"`
+	URL       string `json:"url"`
+	PosterURL string `json:"poster_url,omitempty"` // Static frame when the cover is animated
+}
+
+// SetCategoryCoverRepository sets the repository of pinned category covers.
+// Parameters:
+//   - coverRepo: category cover repository (nil disables covers).
+//
+// Returns: none.
+func (s *IngestService) SetCategoryCoverRepository(coverRepo *repository.CategoryCoverRepository) {
+	s.coverRepo = coverRepo
+}
+
+// SetCategoryCover pins an active meme of a category as its cover, replacing
+// the previous cover, and purges the cached category list.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - category: category name.
+//   - memeID: meme to pin.
+//
+// Returns:
+//   - *domain.CategoryCover: the pinned cover.
+//   - error: ErrMemeNotFound, ErrCoverCategoryMismatch, or a database error.
+func (s *IngestService) SetCategoryCover(ctx context.Context, category, memeID string) (*domain.CategoryCover, error) {
+	if s.coverRepo == nil {
+		return nil, errors.New("category cover repository not configured")
+	}
+	meme, err := s.memeRepo.GetByID(ctx, memeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && meme.Status != domain.MemeStatusActive) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+	if meme.Category != category {
+		return nil, ErrCoverCategoryMismatch
+	}
+
+	cover := &domain.CategoryCover{Category: category, MemeID: memeID}
+	if err := s.coverRepo.Upsert(ctx, cover); err != nil {
+		return nil, fmt.Errorf("failed to pin category cover: %w", err)
+	}
+	logger.CtxInfo(ctx, "Pinned category cover: category=%s, meme_id=%s", category, memeID)
+	s.purgeCache(ctx, []string{SurrogateKeyCategories})
+	return cover, nil
+}
+
+// ClearCategoryCover removes the pinned cover of a category.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - category: category name.
+//
+// Returns:
+//   - error: ErrCoverNotFound if the category had no cover, or a database error.
+func (s *IngestService) ClearCategoryCover(ctx context.Context, category string) error {
+	if s.coverRepo == nil {
+		return errors.New("category cover repository not configured")
+	}
+	found, err := s.coverRepo.Delete(ctx, category)
+	if err != nil {
+		return fmt.Errorf("failed to clear category cover: %w", err)
+	}
+	if !found {
+		return ErrCoverNotFound
+	}
+	logger.CtxInfo(ctx, "Cleared category cover: category=%s", category)
+	s.purgeCache(ctx, []string{SurrogateKeyCategories})
+	return nil
+}
+
+// SetCategoryCoverRepository sets the repository of pinned category covers.
+// Parameters:
+//   - coverRepo: category cover repository (nil disables covers).
+//
+// Returns: none.
+func (s *SearchService) SetCategoryCoverRepository(coverRepo *repository.CategoryCoverRepository) {
+	s.coverRepo = coverRepo
+}
+
+// GetCategoryCovers returns the pinned cover of each category. Covers are
+// read on every call, so pins show up without waiting for the category cache.
+// Covers whose meme is gone, inactive or moved to another category are left out.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - map[string]CategoryCover: covers keyed by category (nil when covers are disabled).
+//   - error: non-nil if lookup fails.
+func (s *SearchService) GetCategoryCovers(ctx context.Context) (map[string]CategoryCover, error) {
+	if s.coverRepo == nil {
+		return nil, nil
+	}
+	pinned, err := s.coverRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list category covers: %w", err)
+	}
+	covers := make(map[string]CategoryCover, len(pinned))
+	if len(pinned) == 0 {
+		return covers, nil
+	}
+
+	ids := make([]string, len(pinned))
+	for i, cover := range pinned {
+		ids[i] = cover.MemeID
+	}
+	memes, err := s.memeRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cover memes: %w", err)
+	}
+	byID := make(map[string]*domain.Meme, len(memes))
+	for i := range memes {
+		byID[memes[i].ID] = &memes[i]
+	}
+
+	for _, cover := range pinned {
+		meme, ok := byID[cover.MemeID]
+		if !ok || meme.Status != domain.MemeStatusActive || meme.Category != cover.Category || meme.StorageKey == "" || s.storage == nil {
+			continue
+		}
+		covers[cover.Category] = CategoryCover{
+			MemeID:    meme.ID,
+			URL:       s.storage.GetURL(meme.StorageKey),
+			PosterURL: posterURLFor(s.storage, meme),
+		}
+	}
+	return covers, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestCategoryCoversArePinnedPerCategory(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, meme := range []domain.Meme{
+		{ID: "cat-1", SourceID: "s1", MD5Hash: "md5-1", StorageKey: "ab/cat-1.jpg", Category: "猫", Status: domain.MemeStatusActive},
+		{ID: "cat-2", SourceID: "s2", MD5Hash: "md5-2", StorageKey: "ab/cat-2.jpg", Category: "猫", Status: domain.MemeStatusActive},
+		{ID: "dog-1", SourceID: "s3", MD5Hash: "md5-3", StorageKey: "ab/dog-1.jpg", Category: "狗", Status: domain.MemeStatusActive},
+	} {
+		meme.SourceType = "test"
+		if err := memeRepo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create() meme error = %v", err)
+		}
+	}
+
+	coverRepo := repository.NewCategoryCoverRepository(db)
+	ingest := &IngestService{memeRepo: memeRepo}
+	ingest.SetCategoryCoverRepository(coverRepo)
+	search := NewSearchService(memeRepo, nil, nil, nil, nil, newMemoryObjectStorage(), nil, nil)
+	search.SetCategoryCoverRepository(coverRepo)
+
+	if _, err := ingest.SetCategoryCover(ctx, "猫", "dog-1"); !errors.Is(err, ErrCoverCategoryMismatch) {
+		t.Fatalf("SetCategoryCover(other category) error = %v, want ErrCoverCategoryMismatch", err)
+	}
+	if _, err := ingest.SetCategoryCover(ctx, "猫", "missing"); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("SetCategoryCover(missing) error = %v, want ErrMemeNotFound", err)
+	}
+	if _, err := ingest.SetCategoryCover(ctx, "猫", "cat-1"); err != nil {
+		t.Fatalf("SetCategoryCover() error = %v", err)
+	}
+	if _, err := ingest.SetCategoryCover(ctx, "猫", "cat-2"); err != nil {
+		t.Fatalf("SetCategoryCover() replacing the cover error = %v", err)
+	}
+
+	covers, err := search.GetCategoryCovers(ctx)
+	if err != nil {
+		t.Fatalf("GetCategoryCovers() error = %v", err)
+	}
+	want := CategoryCover{MemeID: "cat-2", URL: "https://storage.test/ab/cat-2.jpg"}
+	if len(covers) != 1 || covers["猫"] != want {
+		t.Fatalf("GetCategoryCovers() = %+v, want only 猫 -> %+v", covers, want)
+	}
+
+	if err := ingest.ClearCategoryCover(ctx, "猫"); err != nil {
+		t.Fatalf("ClearCategoryCover() error = %v", err)
+	}
+	if err := ingest.ClearCategoryCover(ctx, "猫"); !errors.Is(err, ErrCoverNotFound) {
+		t.Fatalf("ClearCategoryCover() twice error = %v, want ErrCoverNotFound", err)
+	}
+	if covers, err := search.GetCategoryCovers(ctx); err != nil || len(covers) != 0 {
+		t.Fatalf("GetCategoryCovers() after clear = %+v, %v, want none", covers, err)
+	}
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/llmclient"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	defaultDiscoveryMinClusterSize = 5
+	defaultDiscoveryExemplars      = 8
+	defaultDiscoveryMaxTokens      = 100
+	maxKMeansIterations            = 50
+
+	// noCategoryLabel is the reply for clusters without a common theme.
+	noCategoryLabel = "无"
+)
+
+// CategoryDiscoveryConfig configures the offline category discovery job.
+type CategoryDiscoveryConfig struct {
+	Model          string
+	APIKey         string
+	BaseURL        string
+	Prompts        prompts.Provider  // nil uses the built-in prompts
+	Clusters       int               // k for k-means; 0 picks sqrt(points/2)
+	MinClusterSize int               // Smaller clusters get no suggestion (0 uses 5)
+	Exemplars      int               // Memes per cluster shown to the model (0 uses 8)
+	Seed           int64             // Seed of the k-means++ initialization
+	Deviations     float64           // FindOutliers: standard deviations below the mean similarity to the centroid (0 uses 2)
+	Margin         float64           // FindOutliers: similarity another centroid must add to be suggested (0 uses 0.02)
+	DryRun         bool              // Cluster only: no LLM calls and nothing stored
+	Transport      http.RoundTripper // nil uses the default HTTP transport
+}
+
+// CategoryDiscovery clusters meme embeddings with k-means, asks the LLM for a
+// category name per cluster based on the descriptions of its exemplars, and
+// stores the names as suggestions for review.
+type CategoryDiscovery struct {
+	client         *llmclient.Client
+	model          string
+	prompts        prompts.Provider
+	clusters       int
+	minClusterSize int
+	exemplars      int
+	seed           int64
+	deviations     float64
+	margin         float64
+	dryRun         bool
+	repo           *repository.CategorySuggestionRepository
+}
+
+// CategoryDiscoveryReport summarizes a discovery run.
+type CategoryDiscoveryReport struct {
+	RunID       string              `json:"run_id"`
+	Points      int                 `json:"points"`
+	Clusters    []DiscoveredCluster `json:"clusters"`
+	Suggestions int                 `json:"suggestions"`
+	Failed      int                 `json:"failed"` // Clusters whose labeling failed
+}
+
+// DiscoveredCluster is one k-means cluster of a run.
+type DiscoveredCluster struct {
+	Size        int      `json:"size"`
+	Label       string   `json:"label,omitempty"`
+	ExemplarIDs []string `json:"exemplar_ids"`
+}
+
+// NewCategoryDiscovery creates a category discovery job.
+// Parameters:
+//   - cfg: job configuration.
+//   - repo: review queue the suggestions are written to.
+//
+// Returns:
+//   - *CategoryDiscovery: initialized job.
+func NewCategoryDiscovery(cfg *CategoryDiscoveryConfig, repo *repository.CategorySuggestionRepository) *CategoryDiscovery {
+	d := &CategoryDiscovery{
+		model:          cfg.Model,
+		prompts:        prompts.OrDefault(cfg.Prompts),
+		clusters:       cfg.Clusters,
+		minClusterSize: cfg.MinClusterSize,
+		exemplars:      cfg.Exemplars,
+		seed:           cfg.Seed,
+		deviations:     cfg.Deviations,
+		margin:         cfg.Margin,
+		dryRun:         cfg.DryRun,
+		repo:           repo,
+	}
+	if d.minClusterSize <= 0 {
+		d.minClusterSize = defaultDiscoveryMinClusterSize
+	}
+	if d.exemplars <= 0 {
+		d.exemplars = defaultDiscoveryExemplars
+	}
+	if d.deviations <= 0 {
+		d.deviations = defaultOutlierDeviations
+	}
+	if d.margin <= 0 {
+		d.margin = defaultOutlierMargin
+	}
+	if !cfg.DryRun {
+		d.client = llmclient.New(llmclient.Config{
+			APIKey:     cfg.APIKey,
+			BaseURL:    cfg.BaseURL,
+			Timeout:    30 * time.Second,
+			MaxRetries: 1,
+			Transport:  cfg.Transport,
+		})
+	}
+	return d
+}
+
+// Discover clusters the points and writes one suggestion per labeled cluster.
+// Points without a vector are ignored.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - collection: collection the points were read from.
+//   - sourceCategory: category the points share, recorded on the suggestions.
+//   - points: points with payloads and dense vectors.
+//   - existing: current categories the model may reuse.
+//
+// Returns:
+//   - *CategoryDiscoveryReport: clusters and suggestion counts.
+//   - error: non-nil if storing the suggestions fails or ctx is canceled.
+func (d *CategoryDiscovery) Discover(ctx context.Context, collection, sourceCategory string, points []repository.Point, existing []string) (*CategoryDiscoveryReport, error) {
+	points = pointsWithVectors(points)
+	report := &CategoryDiscoveryReport{RunID: uuid.New().String(), Points: len(points), Clusters: []DiscoveredCluster{}}
+	if len(points) == 0 {
+		return report, nil
+	}
+
+	k := d.clusters
+	if k <= 0 {
+		k = int(math.Sqrt(float64(len(points)) / 2))
+	}
+	vectors := make([][]float32, len(points))
+	for i, point := range points {
+		vectors[i] = point.Vector
+	}
+	assignments, centroids := kMeans(vectors, k, d.seed)
+
+	members := make([][]int, len(centroids))
+	for i, cluster := range assignments {
+		members[cluster] = append(members[cluster], i)
+	}
+	sort.SliceStable(members, func(i, j int) bool { return len(members[i]) > len(members[j]) })
+
+	createdAt := time.Now()
+	var suggestions []domain.CategorySuggestion
+	for _, cluster := range members {
+		if len(cluster) < d.minClusterSize {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		exemplars := d.pickExemplars(vectors, cluster)
+		summary := DiscoveredCluster{Size: len(cluster)}
+		for _, i := range exemplars {
+			summary.ExemplarIDs = append(summary.ExemplarIDs, points[i].Payload.MemeID)
+		}
+		if d.dryRun {
+			report.Clusters = append(report.Clusters, summary)
+			continue
+		}
+
+		label, rationale, err := d.label(ctx, points, exemplars, existing)
+		if err != nil {
+			report.Failed++
+			logger.CtxWarn(ctx, "Failed to label cluster: size=%d, error=%v", len(cluster), err)
+			report.Clusters = append(report.Clusters, summary)
+			continue
+		}
+		summary.Label = label
+		report.Clusters = append(report.Clusters, summary)
+		if label == "" {
+			continue
+		}
+
+		memeIDs := make(domain.StringArray, 0, len(cluster))
+		for _, i := range cluster {
+			memeIDs = append(memeIDs, points[i].Payload.MemeID)
+		}
+		suggestions = append(suggestions, domain.CategorySuggestion{
+			ID:             uuid.New().String(),
+			RunID:          report.RunID,
+			Kind:           domain.SuggestionKindCluster,
+			Collection:     collection,
+			SourceCategory: sourceCategory,
+			Label:          label,
+			Rationale:      rationale,
+			Size:           len(cluster),
+			MemeIDs:        memeIDs,
+			ExemplarIDs:    summary.ExemplarIDs,
+			Status:         domain.SuggestionStatusPending,
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		})
+	}
+
+	if d.dryRun || d.repo == nil {
+		return report, nil
+	}
+	if err := d.repo.CreateBatch(ctx, suggestions); err != nil {
+		return report, fmt.Errorf("failed to store category suggestions: %w", err)
+	}
+	report.Suggestions = len(suggestions)
+	return report, nil
+}
+
+// pickExemplars returns the members closest to the cluster mean.
+func (d *CategoryDiscovery) pickExemplars(vectors [][]float32, cluster []int) []int {
+	centroid := make([]float64, len(vectors[cluster[0]]))
+	for _, i := range cluster {
+		for j, v := range vectors[i] {
+			centroid[j] += float64(v)
+		}
+	}
+	similarity := make(map[int]float64, len(cluster))
+	for _, i := range cluster {
+		similarity[i] = cosine(vectors[i], centroid)
+	}
+
+	exemplars := append([]int(nil), cluster...)
+	sort.SliceStable(exemplars, func(a, b int) bool { return similarity[exemplars[a]] > similarity[exemplars[b]] })
+	return exemplars[:min(d.exemplars, len(exemplars))]
+}
+
+// label asks the model for the category of a cluster. An empty label means the
+// model found no common theme.
+func (d *CategoryDiscovery) label(ctx context.Context, points []repository.Point, exemplars []int, existing []string) (string, string, error) {
+	var input strings.Builder
+	if len(existing) > 0 {
+		input.WriteString("已有分类：" + strings.Join(existing, "、") + "\n\n")
+	}
+	for n, i := range exemplars {
+		payload := points[i].Payload
+		fmt.Fprintf(&input, "%d. %s", n+1, compactDescription(payload.VLMDescription))
+		if payload.OCRText != "" {
+			fmt.Fprintf(&input, "（文字：%s）", payload.OCRText)
+		}
+		input.WriteString("\n")
+	}
+
+	resp, err := d.client.Chat(ctx, llmclient.ChatRequest{
+		Model: d.model,
+		Messages: []llmclient.Message{
+			{Role: "system", Content: d.prompts.Prompts().CategoryLabel},
+			{Role: "user", Content: input.String()},
+		},
+		MaxTokens:   defaultDiscoveryMaxTokens,
+		Temperature: llmclient.Float32(0),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("category label API call failed: %w", err)
+	}
+	label, rationale := parseCategoryLabel(resp.Content)
+	return label, rationale, nil
+}
+
+// parseCategoryLabel reads the "分类：" and "理由：" lines of a model reply.
+func parseCategoryLabel(reply string) (string, string) {
+	var label, rationale string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		for _, sep := range []string{"：", ":"} {
+			if key, value, ok := strings.Cut(line, sep); ok {
+				switch strings.TrimSpace(key) {
+				case "分类":
+					label = strings.Trim(strings.TrimSpace(value), "\"“”「」")
+				case "理由":
+					rationale = strings.TrimSpace(value)
+				}
+				break
+			}
+		}
+	}
+	if label == noCategoryLabel {
+		return "", rationale
+	}
+	return label, rationale
+}
+
+// pointsWithVectors drops points that lack a vector or payload.
+func pointsWithVectors(points []repository.Point) []repository.Point {
+	kept := make([]repository.Point, 0, len(points))
+	for _, point := range points {
+		if len(point.Vector) > 0 && point.Payload != nil {
+			kept = append(kept, point)
+		}
+	}
+	return kept
+}
+
+// kMeans clusters vectors by cosine similarity (spherical k-means) with
+// k-means++ initialization. It returns the cluster of every vector and the
+// unit-length centroids.
+func kMeans(vectors [][]float32, k int, seed int64) ([]int, [][]float64) {
+	k = max(1, min(k, len(vectors)))
+	rng := rand.New(rand.NewSource(seed))
+
+	// k-means++: each further centroid is drawn with probability proportional
+	// to its cosine distance from the closest centroid so far.
+	centroids := [][]float64{unit(vectors[rng.Intn(len(vectors))])}
+	distance := make([]float64, len(vectors))
+	for len(centroids) < k {
+		var total float64
+		for i, v := range vectors {
+			distance[i] = math.Inf(1)
+			for _, c := range centroids {
+				distance[i] = math.Min(distance[i], 1-cosine(v, c))
+			}
+			distance[i] = math.Max(distance[i], 0)
+			total += distance[i]
+		}
+		if total == 0 {
+			break
+		}
+		target := rng.Float64() * total
+		next := len(vectors) - 1
+		for i, d := range distance {
+			if target -= d; target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, unit(vectors[next]))
+	}
+
+	assignments := make([]int, len(vectors))
+	for iteration := 0; iteration < maxKMeansIterations; iteration++ {
+		changed := false
+		for i, v := range vectors {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if sim := cosine(v, centroid); sim > bestSim {
+					best, bestSim = c, sim
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+
+		sums := make([][]float64, len(centroids))
+		for i, v := range vectors {
+			c := assignments[i]
+			if sums[c] == nil {
+				sums[c] = make([]float64, len(v))
+			}
+			for j, x := range v {
+				sums[c][j] += float64(x)
+			}
+		}
+		for c, sum := range sums {
+			if sum != nil { // Empty clusters keep their centroid
+				centroids[c] = normalize(sum)
+			}
+		}
+	}
+	return assignments, centroids
+}
+
+// cosine returns the cosine similarity of a vector and a centroid.
+func cosine(v []float32, c []float64) float64 {
+	var dot, vNorm, cNorm float64
+	for i := range min(len(v), len(c)) {
+		dot += float64(v[i]) * c[i]
+		vNorm += float64(v[i]) * float64(v[i])
+		cNorm += c[i] * c[i]
+	}
+	if vNorm == 0 || cNorm == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(vNorm*cNorm)
+}
+
+func unit(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = float64(x)
+	}
+	return normalize(out)
+}
+
+func normalize(v []float64) []float64 {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		return v
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// ListCategorySuggestions returns discovered category suggestions, newest run
+// and largest clusters first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - status: review status to filter by (empty lists all).
+//   - limit: maximum number of suggestions to return.
+//   - offset: number of suggestions to skip.
+//
+// Returns:
+//   - []domain.CategorySuggestion: suggestions.
+//   - int64: total number of suggestions with the status.
+//   - error: non-nil if the suggestion queue is not configured or the query fails.
+func (s *IngestService) ListCategorySuggestions(ctx context.Context, status domain.SuggestionStatus, limit, offset int) ([]domain.CategorySuggestion, int64, error) {
+	if s.suggestionRepo == nil {
+		return nil, 0, errors.New("category suggestion repository not configured")
+	}
+	suggestions, err := s.suggestionRepo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list category suggestions: %w", err)
+	}
+	total, err := s.suggestionRepo.Count(ctx, status)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count category suggestions: %w", err)
+	}
+	return suggestions, total, nil
+}
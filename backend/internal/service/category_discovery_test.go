@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+// blob is a named group of test points around a direction.
+type blob struct {
+	name      string
+	direction []float32
+}
+
+// blobPoints returns n points around each blob direction.
+func blobPoints(n int, blobs ...blob) []repository.Point {
+	var points []repository.Point
+	for _, b := range blobs {
+		name, direction := b.name, b.direction
+		for i := 0; i < n; i++ {
+			vector := append([]float32(nil), direction...)
+			vector[i%len(vector)] += 0.05
+			points = append(points, repository.Point{
+				ID:      fmt.Sprintf("%s-%d", name, i),
+				Vector:  vector,
+				Payload: &repository.MemePayload{MemeID: fmt.Sprintf("%s-%d", name, i), VLMDescription: name + "表情", Category: "未分类"},
+			})
+		}
+	}
+	return points
+}
+
+func TestKMeansSeparatesBlobs(t *testing.T) {
+	t.Parallel()
+
+	points := blobPoints(6, blob{"cat", []float32{1, 0, 0}}, blob{"dog", []float32{0, 1, 0}})
+	vectors := make([][]float32, len(points))
+	for i, point := range points {
+		vectors[i] = point.Vector
+	}
+
+	assignments, centroids := kMeans(vectors, 2, 1)
+	if len(centroids) != 2 {
+		t.Fatalf("centroids = %d, want 2", len(centroids))
+	}
+	clusterOf := make(map[string]int)
+	for i, point := range points {
+		name := strings.Split(point.ID, "-")[0]
+		if c, ok := clusterOf[name]; ok && c != assignments[i] {
+			t.Fatalf("points of %s split across clusters: %v", name, assignments)
+		}
+		clusterOf[name] = assignments[i]
+	}
+	if clusterOf["cat"] == clusterOf["dog"] {
+		t.Fatalf("cat and dog share cluster %d", clusterOf["cat"])
+	}
+}
+
+func TestParseCategoryLabel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		reply, label, rationale string
+	}{
+		{"分类：猫猫\n理由：都是猫", "猫猫", "都是猫"},
+		{"分类: “熊猫头”\n", "熊猫头", ""},
+		{"分类：无\n理由：主题不一致", "", "主题不一致"},
+		{"不知道", "", ""},
+	}
+	for _, tt := range tests {
+		label, rationale := parseCategoryLabel(tt.reply)
+		if label != tt.label || rationale != tt.rationale {
+			t.Fatalf("parseCategoryLabel(%q) = %q, %q, want %q, %q", tt.reply, label, rationale, tt.label, tt.rationale)
+		}
+	}
+}
+
+func TestCategoryDiscoveryStoresSuggestions(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	repo := repository.NewCategorySuggestionRepository(db)
+
+	discovery := NewCategoryDiscovery(&CategoryDiscoveryConfig{Clusters: 4, MinClusterSize: 5, Exemplars: 3}, repo)
+	discovery.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		reply := "分类：无\n理由：没有共同主题"
+		switch {
+		case strings.Contains(string(body), "cat表情"):
+			reply = "分类：猫猫\n理由：都是猫"
+		case strings.Contains(string(body), "dog表情"):
+			return jsonResponse(t, http.StatusBadRequest, map[string]any{"error": map[string]any{"message": "bad request"}}), nil
+		}
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": reply}, "finish_reason": "stop"}},
+		}), nil
+	}))
+
+	points := blobPoints(6, blob{"cat", []float32{1, 0, 0}}, blob{"dog", []float32{0, 1, 0}}, blob{"mix", []float32{0, 0, 1}})
+	points = append(points, blobPoints(2, blob{"tiny", []float32{-1, 0, 0}})...)
+	points = append(points, repository.Point{ID: "no-vector", Payload: &repository.MemePayload{MemeID: "no-vector"}})
+
+	report, err := discovery.Discover(context.Background(), "emomo", "未分类", points, []string{"熊猫头"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if report.Points != 20 || report.Suggestions != 1 || report.Failed != 1 {
+		t.Fatalf("report = %+v, want 20 points, 1 suggestion and 1 failed cluster", report)
+	}
+
+	suggestions, err := repo.List(context.Background(), domain.SuggestionStatusPending, 10, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("suggestions = %+v, want 1", suggestions)
+	}
+	got := suggestions[0]
+	if got.Label != "猫猫" || got.Size != 6 || len(got.ExemplarIDs) != 3 || got.SourceCategory != "未分类" || got.RunID != report.RunID {
+		t.Fatalf("suggestion = %+v, want 猫猫 cluster of the run with 3 exemplars", got)
+	}
+}
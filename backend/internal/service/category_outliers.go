@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	defaultOutlierDeviations = 2.0
+	defaultOutlierMargin     = 0.02
+
+	// uncategorizedCategory holds memes of sources without category
+	// directories. It is never suggested as a target.
+	uncategorizedCategory = "未分类"
+)
+
+// CategoryOutlierReport summarizes an outlier search in one category.
+type CategoryOutlierReport struct {
+	RunID       string          `json:"run_id"`
+	Category    string          `json:"category"`
+	Memes       int             `json:"memes"`     // Memes of the category with a vector
+	Threshold   float64         `json:"threshold"` // Similarity to the centroid below which a meme is an outlier
+	Outliers    int             `json:"outliers"`  // Memes below the threshold, with or without a better category
+	Targets     []OutlierTarget `json:"targets"`
+	Suggestions int             `json:"suggestions"`
+}
+
+// OutlierTarget is a category some outliers are closer to.
+type OutlierTarget struct {
+	Label       string   `json:"label"`
+	Size        int      `json:"size"`
+	ExemplarIDs []string `json:"exemplar_ids"` // Memes gaining the most similarity by the move
+}
+
+// outlierMove is an outlier and the category it is closest to.
+type outlierMove struct {
+	memeID  string
+	current float64 // Similarity to its own centroid
+	target  float64 // Similarity to the target centroid
+}
+
+// FindOutliers looks for memes of category that are far from its centroid in
+// vector space and closer to the centroid of another category. A meme is an
+// outlier when its similarity to its own centroid is more than the configured
+// standard deviations below the category's mean; it is suggested for the
+// category whose centroid is most similar when that beats its own by the
+// margin. One suggestion of kind outlier is stored per target category. No
+// LLM is called. Categories with fewer than the minimum cluster size memes
+// get no centroid, and 未分类 is never a target.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - collection: collection the points were read from.
+//   - category: category to check.
+//   - points: points of every category with payloads and dense vectors.
+//
+// Returns:
+//   - *CategoryOutlierReport: outliers and suggestion counts.
+//   - error: non-nil if storing the suggestions fails or ctx is canceled.
+func (d *CategoryDiscovery) FindOutliers(ctx context.Context, collection, category string, points []repository.Point) (*CategoryOutlierReport, error) {
+	report := &CategoryOutlierReport{RunID: uuid.New().String(), Category: category, Targets: []OutlierTarget{}}
+
+	// One vector per meme: chunked captions write several points
+	byCategory := make(map[string][]repository.Point)
+	seen := make(map[string]bool)
+	for _, point := range pointsWithVectors(points) {
+		if seen[point.Payload.MemeID] {
+			continue
+		}
+		seen[point.Payload.MemeID] = true
+		byCategory[point.Payload.Category] = append(byCategory[point.Payload.Category], point)
+	}
+	members := byCategory[category]
+	report.Memes = len(members)
+	if len(members) < max(d.minClusterSize, 2) {
+		return report, nil
+	}
+
+	centroids := make(map[string][]float64)
+	for name, group := range byCategory {
+		if name != category && name != "" && name != uncategorizedCategory && len(group) >= d.minClusterSize {
+			centroids[name] = centroidOf(group)
+		}
+	}
+	own := centroidOf(members)
+
+	similarities := make([]float64, len(members))
+	var mean float64
+	for i, point := range members {
+		similarities[i] = cosine(point.Vector, own)
+		mean += similarities[i]
+	}
+	mean /= float64(len(members))
+	var variance float64
+	for _, sim := range similarities {
+		variance += (sim - mean) * (sim - mean)
+	}
+	report.Threshold = mean - d.deviations*math.Sqrt(variance/float64(len(members)))
+
+	moves := make(map[string][]outlierMove)
+	for i, point := range members {
+		if similarities[i] >= report.Threshold {
+			continue
+		}
+		report.Outliers++
+		best, bestSim := "", math.Inf(-1)
+		for name, centroid := range centroids {
+			if sim := cosine(point.Vector, centroid); sim > bestSim {
+				best, bestSim = name, sim
+			}
+		}
+		if best != "" && bestSim-similarities[i] >= d.margin {
+			moves[best] = append(moves[best], outlierMove{memeID: point.Payload.MemeID, current: similarities[i], target: bestSim})
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	labels := make([]string, 0, len(moves))
+	for label := range moves {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if len(moves[labels[i]]) != len(moves[labels[j]]) {
+			return len(moves[labels[i]]) > len(moves[labels[j]])
+		}
+		return labels[i] < labels[j]
+	})
+
+	createdAt := time.Now()
+	suggestions := make([]domain.CategorySuggestion, 0, len(labels))
+	for _, label := range labels {
+		group := moves[label]
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].target-group[i].current > group[j].target-group[j].current
+		})
+		memeIDs := make(domain.StringArray, 0, len(group))
+		var current, target float64
+		for _, move := range group {
+			memeIDs = append(memeIDs, move.memeID)
+			current += move.current
+			target += move.target
+		}
+		exemplars := memeIDs[:min(d.exemplars, len(memeIDs))]
+		report.Targets = append(report.Targets, OutlierTarget{Label: label, Size: len(group), ExemplarIDs: exemplars})
+
+		suggestions = append(suggestions, domain.CategorySuggestion{
+			ID:             uuid.New().String(),
+			RunID:          report.RunID,
+			Kind:           domain.SuggestionKindOutlier,
+			Collection:     collection,
+			SourceCategory: category,
+			Label:          label,
+			Rationale: fmt.Sprintf("与「%s」中心的平均相似度 %.2f，与「%s」中心为 %.2f",
+				category, current/float64(len(group)), label, target/float64(len(group))),
+			Size:        len(group),
+			MemeIDs:     memeIDs,
+			ExemplarIDs: exemplars,
+			Status:      domain.SuggestionStatusPending,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		})
+	}
+
+	if d.dryRun || d.repo == nil {
+		return report, nil
+	}
+	if err := d.repo.CreateBatch(ctx, suggestions); err != nil {
+		return report, fmt.Errorf("failed to store category suggestions: %w", err)
+	}
+	report.Suggestions = len(suggestions)
+	return report, nil
+}
+
+// centroidOf returns the unit-length mean of the points' vectors.
+func centroidOf(points []repository.Point) []float64 {
+	sum := make([]float64, len(points[0].Vector))
+	for _, point := range points {
+		for i, x := range unit(point.Vector) {
+			if i < len(sum) {
+				sum[i] += x
+			}
+		}
+	}
+	return normalize(sum)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestFindOutliersQueuesMovesForReview(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	suggestionRepo := repository.NewCategorySuggestionRepository(db)
+	memeRepo := repository.NewMemeRepository(db)
+
+	// 猫猫 holds six cats and a dog; 狗狗 holds six dogs
+	points := blobPoints(6, blob{"cat", []float32{1, 0, 0}}, blob{"dog", []float32{0, 1, 0}})
+	points = append(points, blobPoints(1, blob{"stray", []float32{0, 1, 0.1}})...)
+	for _, point := range points {
+		point.Payload.Category = "猫猫"
+		if point.Payload.MemeID[:3] == "dog" {
+			point.Payload.Category = "狗狗"
+		}
+		meme := &domain.Meme{ID: point.Payload.MemeID, SourceType: "test", SourceID: point.Payload.MemeID, MD5Hash: "md5-" + point.Payload.MemeID,
+			Category: point.Payload.Category, Tags: domain.StringArray{"表情"}, Status: domain.MemeStatusActive}
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+
+	discovery := NewCategoryDiscovery(&CategoryDiscoveryConfig{DryRun: true}, suggestionRepo)
+	report, err := discovery.FindOutliers(ctx, "memes", "猫猫", points)
+	if err != nil {
+		t.Fatalf("FindOutliers(dry run) error = %v", err)
+	}
+	if report.Memes != 7 || report.Outliers != 1 || len(report.Targets) != 1 || report.Suggestions != 0 {
+		t.Fatalf("FindOutliers(dry run) = %+v, want one outlier and nothing stored", report)
+	}
+
+	discovery = NewCategoryDiscovery(&CategoryDiscoveryConfig{}, suggestionRepo)
+	report, err = discovery.FindOutliers(ctx, "memes", "猫猫", points)
+	if err != nil || report.Suggestions != 1 {
+		t.Fatalf("FindOutliers() = %+v, %v, want one suggestion", report, err)
+	}
+	suggestions, _ := suggestionRepo.List(ctx, domain.SuggestionStatusPending, 10, 0)
+	if len(suggestions) != 1 {
+		t.Fatalf("pending suggestions = %d, want 1", len(suggestions))
+	}
+	suggestion := suggestions[0]
+	if suggestion.Kind != domain.SuggestionKindOutlier || suggestion.SourceCategory != "猫猫" || suggestion.Label != "狗狗" ||
+		len(suggestion.MemeIDs) != 1 || suggestion.MemeIDs[0] != "stray-0" {
+		t.Fatalf("suggestion = %+v, want stray-0 moved from 猫猫 to 狗狗", suggestion)
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo}
+	ingest.SetCategorySuggestionRepository(suggestionRepo)
+	decision, err := ingest.AcceptCategorySuggestion(ctx, suggestion.ID, nil)
+	if err != nil || decision.Moved != 1 || decision.Suggestion.Status != domain.SuggestionStatusAccepted {
+		t.Fatalf("AcceptCategorySuggestion() = %+v, %v, want the meme moved", decision, err)
+	}
+	if stray, _ := memeRepo.GetByID(ctx, "stray-0"); stray.Category != "狗狗" || len(stray.Tags) != 1 {
+		t.Fatalf("meme after accept = %q %v, want 狗狗 with its tags", stray.Category, stray.Tags)
+	}
+	if _, err := ingest.RejectCategorySuggestion(ctx, suggestion.ID); !errors.Is(err, ErrSuggestionNotPending) {
+		t.Fatalf("RejectCategorySuggestion(accepted) error = %v, want ErrSuggestionNotPending", err)
+	}
+	if _, err := ingest.AcceptCategorySuggestion(ctx, "missing", nil); !errors.Is(err, ErrSuggestionNotFound) {
+		t.Fatalf("AcceptCategorySuggestion(missing) error = %v, want ErrSuggestionNotFound", err)
+	}
+}
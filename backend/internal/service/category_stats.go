@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultCategoryTrendDays is the growth window used when none is given.
+	DefaultCategoryTrendDays = 30
+	// MaxCategoryTrendDays bounds the growth window.
+	MaxCategoryTrendDays = 365
+)
+
+// CategoryStats summarizes the content of one category for the dashboard.
+type CategoryStats struct {
+	Category      string   `json:"category"`
+	Count         int64    `json:"count"`
+	Animated      int64    `json:"animated"`
+	AnimatedRatio float64  `json:"animated_ratio"`
+	Added         int64    `json:"added"`            // Memes ingested within the trend window
+	Growth        *float64 `json:"growth,omitempty"` // Added relative to the count before the window; nil for new categories
+}
+
+// GetCategoryStats returns per-category counts, the share of animated memes,
+// and growth over the last days, cached for the cache TTL. Growth is based on
+// when memes were ingested.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - days: trend window in days, clamped to [1, MaxCategoryTrendDays].
+//
+// Returns:
+//   - []CategoryStats: stats per category, largest first.
+//   - error: non-nil if the counts cannot be loaded.
+func (s *SearchService) GetCategoryStats(ctx context.Context, days int) ([]CategoryStats, error) {
+	days = clampTrendDays(days)
+	stats, err := s.cached(fmt.Sprintf("%s:%d", cacheKeyCategoryStats, days), func() (any, error) {
+		return s.loadCategoryStats(ctx, days)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats.([]CategoryStats), nil
+}
+
+// loadCategoryStats computes the stats returned by GetCategoryStats.
+func (s *SearchService) loadCategoryStats(ctx context.Context, days int) ([]CategoryStats, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	counts, err := s.memeRepo.CountByCategory(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memes per category: %w", err)
+	}
+
+	stats := make([]CategoryStats, len(counts))
+	for i, count := range counts {
+		stats[i] = CategoryStats{
+			Category: count.Category,
+			Count:    count.Count,
+			Animated: count.Animated,
+			Added:    count.Added,
+		}
+		if count.Count > 0 {
+			stats[i].AnimatedRatio = float64(count.Animated) / float64(count.Count)
+		}
+		if before := count.Count - count.Added; before > 0 {
+			growth := float64(count.Added) / float64(before)
+			stats[i].Growth = &growth
+		}
+	}
+	return stats, nil
+}
+
+// clampTrendDays maps a requested trend window into the supported range.
+func clampTrendDays(days int) int {
+	switch {
+	case days <= 0:
+		return DefaultCategoryTrendDays
+	case days > MaxCategoryTrendDays:
+		return MaxCategoryTrendDays
+	}
+	return days
+}
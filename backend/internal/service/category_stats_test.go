@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestGetCategoryStatsCountsAnimatedAndGrowth(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	old := time.Now().AddDate(0, 0, -60)
+	for _, meme := range []domain.Meme{
+		{ID: "cat-1", Category: "猫", Status: domain.MemeStatusActive, CreatedAt: old},
+		{ID: "cat-2", Category: "猫", Status: domain.MemeStatusActive, CreatedAt: old, IsAnimated: true},
+		{ID: "cat-3", Category: "猫", Status: domain.MemeStatusActive, IsAnimated: true},
+		{ID: "cat-4", Category: "猫", Status: domain.MemeStatusPending},
+		{ID: "dog-1", Category: "狗", Status: domain.MemeStatusActive},
+	} {
+		meme.SourceType = "test"
+		meme.SourceID = meme.ID
+		meme.MD5Hash = "md5-" + meme.ID
+		if err := memeRepo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create() meme error = %v", err)
+		}
+	}
+
+	search := NewSearchService(memeRepo, nil, nil, nil, nil, nil, nil, nil)
+	stats, err := search.GetCategoryStats(ctx, 30)
+	if err != nil {
+		t.Fatalf("GetCategoryStats() error = %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("GetCategoryStats() = %+v, want 2 categories", stats)
+	}
+
+	cats := stats[0]
+	if cats.Category != "猫" || cats.Count != 3 || cats.Animated != 2 || cats.Added != 1 {
+		t.Fatalf("stats[0] = %+v, want 猫 with 3 memes, 2 animated, 1 added", cats)
+	}
+	if cats.AnimatedRatio < 0.66 || cats.AnimatedRatio > 0.67 {
+		t.Fatalf("stats[0].AnimatedRatio = %v, want 2/3", cats.AnimatedRatio)
+	}
+	if cats.Growth == nil || *cats.Growth != 0.5 {
+		t.Fatalf("stats[0].Growth = %v, want 0.5", cats.Growth)
+	}
+
+	dogs := stats[1]
+	if dogs.Count != 1 || dogs.Added != 1 || dogs.Growth != nil {
+		t.Fatalf("stats[1] = %+v, want a new category without growth", dogs)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSuggestionNotFound is returned when a category suggestion does not exist.
+	ErrSuggestionNotFound = errors.New("category suggestion not found")
+	// ErrSuggestionNotPending is returned when deciding a suggestion that was
+	// already accepted or rejected.
+	ErrSuggestionNotPending = errors.New("category suggestion is not pending")
+)
+
+// CategorySuggestionDecision reports the review decision of a suggestion and
+// the memes it moved.
+type CategorySuggestionDecision struct {
+	Suggestion *domain.CategorySuggestion `json:"suggestion"`
+	Moved      int                        `json:"moved"`
+	Skipped    int                        `json:"skipped"`          // Deleted memes and memes no longer in the source category
+	Failed     []string                   `json:"failed,omitempty"` // Memes whose move failed; accepting again retries them
+}
+
+// AcceptCategorySuggestion moves the memes of a pending suggestion to its
+// label, in the payloads of every ingest collection and then the meme row,
+// keeping their tags. memeIDs narrows the move to some of the suggestion's
+// memes; IDs outside it are ignored. Memes that were deleted or left the
+// source category since the suggestion was made are skipped. The suggestion
+// turns accepted once every meme moved; after a failure it stays pending, so
+// accepting again retries the rest.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: suggestion ID.
+//   - memeIDs: memes to move (empty moves all of them).
+//
+// Returns:
+//   - *CategorySuggestionDecision: the suggestion and the move counts.
+//   - error: ErrSuggestionNotFound, ErrSuggestionNotPending, or a database error.
+func (s *IngestService) AcceptCategorySuggestion(ctx context.Context, id string, memeIDs []string) (*CategorySuggestionDecision, error) {
+	suggestion, err := s.getPendingSuggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	selected := []string(suggestion.MemeIDs)
+	if len(memeIDs) > 0 {
+		wanted := make(map[string]bool, len(memeIDs))
+		for _, memeID := range memeIDs {
+			wanted[memeID] = true
+		}
+		selected = selected[:0:0]
+		for _, memeID := range suggestion.MemeIDs {
+			if wanted[memeID] {
+				selected = append(selected, memeID)
+			}
+		}
+	}
+
+	memes, err := s.memeRepo.GetByIDs(ctx, selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memes: %w", err)
+	}
+	decision := &CategorySuggestionDecision{Suggestion: suggestion, Skipped: len(selected) - len(memes)}
+	collections := s.sourceCollections()
+	for i := range memes {
+		meme := &memes[i]
+		if suggestion.SourceCategory != "" && meme.Category != suggestion.SourceCategory {
+			decision.Skipped++
+			continue
+		}
+		if err := s.renameMeme(ctx, collections, meme, suggestion.Label, meme.Tags); err != nil {
+			logger.CtxWarn(ctx, "Failed to move meme to suggested category: meme_id=%s, category=%s, error=%v",
+				meme.ID, suggestion.Label, err)
+			decision.Failed = append(decision.Failed, meme.ID)
+			continue
+		}
+		decision.Moved++
+	}
+
+	if decision.Moved > 0 {
+		s.purgeCache(ctx, []string{SurrogateKeyMemes, SurrogateKeyCategories,
+			CategorySurrogateKey(suggestion.SourceCategory), CategorySurrogateKey(suggestion.Label)})
+	}
+	if len(decision.Failed) == 0 {
+		suggestion.Status = domain.SuggestionStatusAccepted
+		if err := s.suggestionRepo.UpdateStatus(ctx, suggestion); err != nil {
+			return nil, fmt.Errorf("failed to update category suggestion: %w", err)
+		}
+	}
+	logger.CtxInfo(ctx, "Category suggestion accepted: suggestion_id=%s, kind=%s, from=%s, to=%s, moved=%d, skipped=%d, failed=%d",
+		suggestion.ID, suggestion.Kind, suggestion.SourceCategory, suggestion.Label, decision.Moved, decision.Skipped, len(decision.Failed))
+	return decision, nil
+}
+
+// RejectCategorySuggestion turns down a pending suggestion. No meme changes.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: suggestion ID.
+//
+// Returns:
+//   - *CategorySuggestionDecision: the rejected suggestion.
+//   - error: ErrSuggestionNotFound, ErrSuggestionNotPending, or a database error.
+func (s *IngestService) RejectCategorySuggestion(ctx context.Context, id string) (*CategorySuggestionDecision, error) {
+	suggestion, err := s.getPendingSuggestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	suggestion.Status = domain.SuggestionStatusRejected
+	if err := s.suggestionRepo.UpdateStatus(ctx, suggestion); err != nil {
+		return nil, fmt.Errorf("failed to update category suggestion: %w", err)
+	}
+	logger.CtxInfo(ctx, "Category suggestion rejected: suggestion_id=%s, kind=%s, from=%s, to=%s",
+		suggestion.ID, suggestion.Kind, suggestion.SourceCategory, suggestion.Label)
+	return &CategorySuggestionDecision{Suggestion: suggestion}, nil
+}
+
+// getPendingSuggestion loads a suggestion that still awaits review.
+func (s *IngestService) getPendingSuggestion(ctx context.Context, id string) (*domain.CategorySuggestion, error) {
+	if s.suggestionRepo == nil {
+		return nil, errors.New("category suggestion repository not configured")
+	}
+	suggestion, err := s.suggestionRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSuggestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category suggestion: %w", err)
+	}
+	if suggestion.Status != domain.SuggestionStatusPending {
+		return nil, ErrSuggestionNotPending
+	}
+	return suggestion, nil
+}
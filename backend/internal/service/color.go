@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	paletteSize        = 5    // Maximum number of dominant colors kept per meme
+	paletteMinShare    = 0.05 // Colors covering less of the image are dropped
+	paletteSampleEdge  = 64   // Images are sampled on roughly this many pixels per edge
+	monochromeMaxSat   = 0.15 // Mean saturation at or below which a meme counts as black-and-white
+	colorfulMinSat     = 0.40 // Mean saturation at or above which a meme counts as colorful
+	achromaticMaxSat   = 0.20 // Palette colors below this saturation are named black/gray/white
+	paletteQuantizeBit = 4    // Bits kept per channel when bucketing pixels
+)
+
+// Special color filter values that match on mean saturation instead of a named color.
+const (
+	ColorFilterMonochrome = "monochrome"
+	ColorFilterColorful   = "colorful"
+)
+
+// colorNames lists the named colors a palette can be reduced to and a search can filter on.
+var colorNames = []string{"black", "gray", "white", "red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink"}
+
+// queryColorWords maps color phrases in Chinese queries to color filter values.
+// Only explicit phrases are listed so words like 红包 or 白眼 do not trigger a filter.
+var queryColorWords = []struct {
+	phrase string
+	color  string
+}{
+	{"黑白", ColorFilterMonochrome},
+	{"灰白", ColorFilterMonochrome},
+	{"单色", ColorFilterMonochrome},
+	{"彩色", ColorFilterColorful},
+	{"五颜六色", ColorFilterColorful},
+	{"黑色", "black"},
+	{"灰色", "gray"},
+	{"白色", "white"},
+	{"红色", "red"},
+	{"橙色", "orange"},
+	{"橘色", "orange"},
+	{"黄色", "yellow"},
+	{"绿色", "green"},
+	{"青色", "cyan"},
+	{"蓝色", "blue"},
+	{"紫色", "purple"},
+	{"粉色", "pink"},
+	{"粉红", "pink"},
+}
+
+// ColorPalette describes the dominant colors of an image.
+type ColorPalette struct {
+	Colors     []string // Hex colors ordered by pixel share, e.g. "#1e1e1e"
+	Saturation float64  // Mean HSV saturation over all opaque pixels (0-1)
+}
+
+// extractPalette computes the dominant colors of an encoded image by bucketing
+// sampled pixels and averaging the largest buckets.
+func extractPalette(data []byte) (*ColorPalette, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return paletteFromImage(img), nil
+}
+
+type colorBucket struct {
+	r, g, b uint64
+	count   int
+}
+
+func paletteFromImage(img image.Image) *ColorPalette {
+	bounds := img.Bounds()
+	step := max(1, max(bounds.Dx(), bounds.Dy())/paletteSampleEdge)
+
+	buckets := make(map[uint32]*colorBucket)
+	var total int
+	var saturationSum float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue // Transparent background does not count as a color
+			}
+			r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
+			shift := 8 - paletteQuantizeBit
+			key := uint32(r8>>shift)<<(2*paletteQuantizeBit) | uint32(g8>>shift)<<paletteQuantizeBit | uint32(b8>>shift)
+			bucket, ok := buckets[key]
+			if !ok {
+				bucket = &colorBucket{}
+				buckets[key] = bucket
+			}
+			bucket.r += uint64(r8)
+			bucket.g += uint64(g8)
+			bucket.b += uint64(b8)
+			bucket.count++
+
+			_, s, _ := rgbToHSV(r8, g8, b8)
+			saturationSum += s
+			total++
+		}
+	}
+
+	palette := &ColorPalette{}
+	if total == 0 {
+		return palette
+	}
+	palette.Saturation = math.Round(saturationSum/float64(total)*1000) / 1000
+
+	ordered := make([]*colorBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		ordered = append(ordered, bucket)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].count != ordered[j].count {
+			return ordered[i].count > ordered[j].count
+		}
+		return hexColor(ordered[i]) < hexColor(ordered[j])
+	})
+
+	for _, bucket := range ordered {
+		// The largest bucket is always kept so noisy images still get a palette.
+		if len(palette.Colors) == paletteSize ||
+			(len(palette.Colors) > 0 && float64(bucket.count)/float64(total) < paletteMinShare) {
+			break
+		}
+		palette.Colors = append(palette.Colors, hexColor(bucket))
+	}
+	return palette
+}
+
+func hexColor(bucket *colorBucket) string {
+	n := uint64(bucket.count)
+	return fmt.Sprintf("#%02x%02x%02x", bucket.r/n, bucket.g/n, bucket.b/n)
+}
+
+// rgbToHSV converts 8-bit RGB to hue in degrees and saturation/value in 0-1.
+func rgbToHSV(r, g, b uint8) (h, s, v float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	maxC := math.Max(rf, math.Max(gf, bf))
+	minC := math.Min(rf, math.Min(gf, bf))
+	delta := maxC - minC
+
+	v = maxC
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	if delta == 0 {
+		return 0, s, v
+	}
+	switch maxC {
+	case rf:
+		h = 60 * math.Mod((gf-bf)/delta, 6)
+	case gf:
+		h = 60 * ((bf-rf)/delta + 2)
+	default:
+		h = 60 * ((rf-gf)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+// colorName reduces a hex color to one of colorNames.
+func colorName(hex string) string {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return ""
+	}
+	h, s, v := rgbToHSV(r, g, b)
+	switch {
+	case v < 0.2:
+		return "black"
+	case s < achromaticMaxSat && v > 0.85:
+		return "white"
+	case s < achromaticMaxSat:
+		return "gray"
+	}
+	switch {
+	case h < 15 || h >= 345:
+		return "red"
+	case h < 45:
+		return "orange"
+	case h < 70:
+		return "yellow"
+	case h < 165:
+		return "green"
+	case h < 195:
+		return "cyan"
+	case h < 255:
+		return "blue"
+	case h < 290:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
+
+// paletteColorNames returns the distinct color names of a palette in palette order.
+func paletteColorNames(colors []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, hex := range colors {
+		name := colorName(hex)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// detectQueryColor returns the color filter implied by a query such as
+// "黑白的熊猫头", or "" if the query names no color.
+func detectQueryColor(query string) string {
+	for _, word := range queryColorWords {
+		if strings.Contains(query, word.phrase) {
+			return word.color
+		}
+	}
+	return ""
+}
+
+// applyColorFilter adds the payload conditions for a color filter value.
+func applyColorFilter(filters *repository.SearchFilters, color string) error {
+	switch color {
+	case "":
+		return nil
+	case ColorFilterMonochrome:
+		maxSat := monochromeMaxSat
+		filters.MaxSaturation = &maxSat
+		return nil
+	case ColorFilterColorful:
+		minSat := colorfulMinSat
+		filters.MinSaturation = &minSat
+		return nil
+	}
+	for _, name := range colorNames {
+		if color == name {
+			filters.Color = &name
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color: %s", color)
+}
+
+// PaletteColorNames exposes the palette-to-color-name mapping used by ingest,
+// so cmd/reembed writes the same color payload fields.
+func PaletteColorNames(colors []string) []string {
+	return paletteColorNames(colors)
+}
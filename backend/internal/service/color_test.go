@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestExtractPaletteOrdersColorsByShare(t *testing.T) {
+	t.Parallel()
+
+	// Three quarters red, one quarter white, with a transparent strip that must be ignored.
+	img := image.NewNRGBA(image.Rect(0, 0, 80, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 80; x++ {
+			switch {
+			case x >= 64:
+				img.Set(x, y, color.NRGBA{})
+			case x < 48:
+				img.Set(x, y, color.NRGBA{R: 220, G: 20, B: 20, A: 255})
+			default:
+				img.Set(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+
+	palette, err := extractPalette(buf.Bytes())
+	if err != nil {
+		t.Fatalf("extractPalette() error = %v", err)
+	}
+	if want := []string{"#dc1414", "#ffffff"}; !reflect.DeepEqual(palette.Colors, want) {
+		t.Fatalf("Colors = %v, want %v", palette.Colors, want)
+	}
+	if palette.Saturation < 0.6 || palette.Saturation > 0.7 {
+		t.Fatalf("Saturation = %v, want about 0.68", palette.Saturation)
+	}
+	if names := paletteColorNames(palette.Colors); !reflect.DeepEqual(names, []string{"red", "white"}) {
+		t.Fatalf("paletteColorNames() = %v, want [red white]", names)
+	}
+}
+
+func TestColorName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"#000000": "black",
+		"#808080": "gray",
+		"#f5f5f5": "white",
+		"#e02020": "red",
+		"#f0a020": "orange",
+		"#f0e040": "yellow",
+		"#30c040": "green",
+		"#2060e0": "blue",
+		"#9030d0": "purple",
+		"#f060b0": "pink",
+	}
+	for hex, want := range tests {
+		if got := colorName(hex); got != want {
+			t.Fatalf("colorName(%s) = %q, want %q", hex, got, want)
+		}
+	}
+}
+
+func TestBuildSearchFiltersDetectsQueryColor(t *testing.T) {
+	t.Parallel()
+
+	search := &SearchService{}
+	req := &SearchRequest{Query: "黑白的熊猫头"}
+	filters, err := search.buildSearchFilters(req)
+	if err != nil {
+		t.Fatalf("buildSearchFilters() error = %v", err)
+	}
+	if filters.MaxSaturation == nil || *filters.MaxSaturation != monochromeMaxSat {
+		t.Fatalf("MaxSaturation = %v, want %v", filters.MaxSaturation, monochromeMaxSat)
+	}
+	if got := stringValue(req.Color); got != ColorFilterMonochrome {
+		t.Fatalf("req.Color = %q, want %q", got, ColorFilterMonochrome)
+	}
+
+	// An explicit empty color disables detection.
+	empty := ""
+	filters, err = search.buildSearchFilters(&SearchRequest{Query: "黑白的熊猫头", Color: &empty})
+	if err != nil {
+		t.Fatalf("buildSearchFilters() error = %v", err)
+	}
+	if filters.MaxSaturation != nil || filters.Color != nil {
+		t.Fatalf("filters = %+v, want no color conditions", filters)
+	}
+
+	unknown := "beige"
+	if _, err := search.buildSearchFilters(&SearchRequest{Query: "猫", Color: &unknown}); err == nil {
+		t.Fatal("buildSearchFilters(beige) error = nil, want unknown color")
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+// DescriptionChunking configures extra caption points for descriptions longer
+// than the compact caption text, which keeps only the first 120 runes.
+type DescriptionChunking struct {
+	ChunkRunes int // Runes per chunk (0 disables chunking)
+	MaxChunks  int // Extra points per meme and caption index at most
+}
+
+// enabled reports whether descriptions are chunked.
+func (c DescriptionChunking) enabled() bool {
+	return c.ChunkRunes > 0 && c.MaxChunks > 0
+}
+
+// split returns the chunks of the part of description the compact caption
+// text leaves out. Chunks break at whitespace or punctuation near the chunk
+// size when possible.
+func (c DescriptionChunking) split(description string) []string {
+	if !c.enabled() {
+		return nil
+	}
+	runes := []rune(normalizeWhitespace(strings.TrimSpace(description)))
+	if len(runes) <= maxVLMEmbeddingRunes {
+		return nil
+	}
+	rest := runes[maxVLMEmbeddingRunes:]
+
+	var chunks []string
+	for len(rest) > 0 && len(chunks) < c.MaxChunks {
+		end := min(c.ChunkRunes, len(rest))
+		if end < len(rest) {
+			end = chunkBreak(rest[:end])
+		}
+		if chunk := strings.TrimSpace(string(rest[:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		rest = rest[end:]
+	}
+	return chunks
+}
+
+// chunkBreak returns where to cut a full chunk: after the last sentence
+// punctuation or space in its second half, or at its end.
+func chunkBreak(chunk []rune) int {
+	for i := len(chunk) - 1; i >= len(chunk)/2; i-- {
+		if strings.ContainsRune(" ，。；！？,.;!?", chunk[i]) {
+			return i + 1
+		}
+	}
+	return len(chunk)
+}
+
+// chunkPointID derives the point ID of a description chunk from the same
+// inputs as the main point, so re-ingesting overwrites chunks in place.
+func chunkPointID(md5Hash, collection string, chunk int) string {
+	return generateDeterministicPointID(md5Hash, collection, domain.MemeVectorTypeCaption+"#"+strconv.Itoa(chunk))
+}
+
+// upsertChunkPoints writes one caption point per description chunk next to
+// the main caption point of index, and removes chunk points a previous, longer
+// description left behind. Chunk points share the meme's payload, so search
+// results are merged by meme ID and source deletes remove them by filter.
+// The IDs written so far are returned even on error.
+func (s *IngestService) upsertChunkPoints(ctx context.Context, index IngestVectorIndex, input vectorUpsertInput) ([]string, error) {
+	written := make([]string, 0, len(input.Chunks))
+	for i, chunk := range input.Chunks {
+		text := s.textWeights.CaptionText("", chunk, input.Payload.Category, nil, nil)
+		embedding, err := index.Embedding.EmbedDocument(ctx, EmbeddingDocument{Text: text})
+		if err != nil {
+			return written, fmt.Errorf("failed to generate chunk embedding: %w", err)
+		}
+
+		payload := *input.Payload
+		payload.Chunk = i + 1
+		pointID := chunkPointID(input.MD5Hash, index.Collection, i+1)
+		if index.UseSparse {
+			learned, err := s.encodeLearnedSparse(ctx, chunk)
+			if err != nil {
+				return written, err
+			}
+			err = index.QdrantRepo.UpsertHybridLearned(ctx, pointID, embedding, chunk, learned, &payload)
+			if err != nil {
+				return written, fmt.Errorf("failed to upsert hybrid chunk vector: %w", err)
+			}
+		} else if err := index.QdrantRepo.Upsert(ctx, pointID, embedding, &payload); err != nil {
+			return written, fmt.Errorf("failed to upsert dense chunk vector: %w", err)
+		}
+		written = append(written, pointID)
+	}
+
+	if input.Replace && len(input.Chunks) < s.chunking.MaxChunks {
+		stale := make([]string, 0, s.chunking.MaxChunks)
+		for i := len(input.Chunks) + 1; i <= s.chunking.MaxChunks; i++ {
+			stale = append(stale, chunkPointID(input.MD5Hash, index.Collection, i))
+		}
+		if err := index.QdrantRepo.DeleteBatch(ctx, stale); err != nil {
+			return written, fmt.Errorf("failed to delete stale chunk vectors: %w", err)
+		}
+	}
+	return written, nil
+}
+
+// SetDescriptionChunking tells collection searches how many caption points
+// ingest writes per meme, so they retrieve enough points to fill top_k with
+// distinct memes.
+// Parameters:
+//   - chunking: chunking of ingest.chunking.
+//
+// Returns: none.
+func (s *SearchService) SetDescriptionChunking(chunking DescriptionChunking) {
+	s.chunking = chunking
+}
+
+// chunkedRetrieval scales the points and group size of a collection search
+// by the caption points a meme may have, since dedupeByMemeID then keeps one
+// per meme. The group size still applies to memes after deduplication.
+func (s *SearchService) chunkedRetrieval(limit int, grouping *repository.SearchGrouping) (int, *repository.SearchGrouping) {
+	if !s.chunking.enabled() {
+		return limit, grouping
+	}
+	points := 1 + s.chunking.MaxChunks
+	if grouping == nil {
+		return limit * points, nil
+	}
+	scaled := *grouping
+	scaled.Size *= points
+	return limit * points, &scaled
+}
+
+// dedupeByMemeID keeps the first result of each meme, which is its best
+// scoring point when results are ranked.
+func dedupeByMemeID(hits []repository.SearchResult) []repository.SearchResult {
+	seen := make(map[string]bool, len(hits))
+	deduped := make([]repository.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Payload != nil {
+			if seen[hit.Payload.MemeID] {
+				continue
+			}
+			seen[hit.Payload.MemeID] = true
+		}
+		deduped = append(deduped, hit)
+	}
+	return deduped
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/timmy/emomo/internal/repository"
+)
+
+func TestDescriptionChunkingSplitsDescriptionTail(t *testing.T) {
+	t.Parallel()
+
+	chunking := DescriptionChunking{ChunkRunes: 20, MaxChunks: 2}
+	if got := chunking.split(strings.Repeat("猫", maxVLMEmbeddingRunes)); got != nil {
+		t.Fatalf("split(short) = %q, want nil", got)
+	}
+	if got := (DescriptionChunking{}).split(strings.Repeat("猫", 500)); got != nil {
+		t.Fatalf("split() with chunking disabled = %q, want nil", got)
+	}
+
+	head := strings.Repeat("头", maxVLMEmbeddingRunes)
+	tail := strings.Repeat("猫", 15) + "。" + strings.Repeat("狗", 30) + strings.Repeat("鸟", 30)
+	got := chunking.split(head + tail)
+	if len(got) != 2 {
+		t.Fatalf("split() returned %d chunks, want 2: %q", len(got), got)
+	}
+	if want := strings.Repeat("猫", 15) + "。"; got[0] != want {
+		t.Fatalf("split()[0] = %q, want %q", got[0], want)
+	}
+	if n := utf8.RuneCountInString(got[1]); n != 20 {
+		t.Fatalf("split()[1] has %d runes, want 20", n)
+	}
+	if strings.Contains(strings.Join(got, ""), "头") {
+		t.Fatalf("split() = %q, want only text after the caption text", got)
+	}
+}
+
+func TestDedupeByMemeIDKeepsBestPoint(t *testing.T) {
+	t.Parallel()
+
+	hits := []repository.SearchResult{
+		{ID: "a#1", Score: 0.9, Payload: &repository.MemePayload{MemeID: "a", Chunk: 1}},
+		{ID: "b", Score: 0.8, Payload: &repository.MemePayload{MemeID: "b"}},
+		{ID: "a", Score: 0.7, Payload: &repository.MemePayload{MemeID: "a"}},
+	}
+	got := dedupeByMemeID(hits)
+	if len(got) != 2 || got[0].ID != "a#1" || got[1].ID != "b" {
+		t.Fatalf("dedupeByMemeID() = %+v, want a#1 then b", got)
+	}
+}
+
+func TestChunkedRetrievalScalesPointsPerMeme(t *testing.T) {
+	t.Parallel()
+
+	grouping := &repository.SearchGrouping{Field: "category", Size: 2}
+	s := &SearchService{}
+	if points, got := s.chunkedRetrieval(20, grouping); points != 20 || got != grouping {
+		t.Fatalf("chunkedRetrieval() without chunking = %d, %+v, want 20 and the grouping", points, got)
+	}
+
+	s.SetDescriptionChunking(DescriptionChunking{ChunkRunes: 200, MaxChunks: 3})
+	points, got := s.chunkedRetrieval(20, grouping)
+	if points != 80 || got.Size != 8 || grouping.Size != 2 {
+		t.Fatalf("chunkedRetrieval() = %d, %+v, want 80 points in groups of 8", points, got)
+	}
+}
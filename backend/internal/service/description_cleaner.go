@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDescriptionBoilerplatePhrases are literal fragments the VLM adds to
+// descriptions that carry no search signal.
+var DefaultDescriptionBoilerplatePhrases = []string{
+	"图中无文字",
+	"图片中没有文字",
+	"这是一张表情包",
+}
+
+// DefaultDescriptionBoilerplatePatterns are regular expressions for templated
+// clauses such as "适合在困惑、震惊时使用" that every description ends with.
+var DefaultDescriptionBoilerplatePatterns = []string{
+	`[，,；;]?\s*(?:非常|很|特别)?适合(?:在|于|用于|用来)?[^，,。！？!?]*?(?:时|時|的时候)(?:使用|发送)`,
+	`^(?:这张|该)表情包(?:图片)?[，,]?`,
+}
+
+var (
+	repeatedCommaRe  = regexp.MustCompile(`[，,]\s*[，,]+`)
+	commaBeforeEndRe = regexp.MustCompile(`[，,；;]\s*([。！？!?])`)
+)
+
+// DescriptionCleaner strips boilerplate from VLM descriptions before they are
+// turned into embedding and BM25 text. The stored description is left as is.
+type DescriptionCleaner struct {
+	phrases  []string
+	patterns []*regexp.Regexp
+}
+
+// DescriptionCleanerConfig holds the boilerplate lists for the cleaner.
+// Empty lists fall back to the defaults.
+type DescriptionCleanerConfig struct {
+	Phrases  []string
+	Patterns []string
+}
+
+// NewDescriptionCleaner creates a description cleaner.
+// Parameters:
+//   - cfg: phrase and regex lists (empty lists use the defaults).
+//
+// Returns:
+//   - *DescriptionCleaner: initialized cleaner.
+//   - error: non-nil if a pattern does not compile.
+func NewDescriptionCleaner(cfg DescriptionCleanerConfig) (*DescriptionCleaner, error) {
+	phrases := cfg.Phrases
+	if len(phrases) == 0 {
+		phrases = DefaultDescriptionBoilerplatePhrases
+	}
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultDescriptionBoilerplatePatterns
+	}
+
+	cleaner := &DescriptionCleaner{phrases: dedupeStrings(phrases)}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid description boilerplate pattern %q: %w", pattern, err)
+		}
+		cleaner.patterns = append(cleaner.patterns, re)
+	}
+	return cleaner, nil
+}
+
+// Clean removes boilerplate phrases and clauses from a description and tidies
+// the punctuation left behind. A nil cleaner returns the text unchanged.
+// Parameters:
+//   - text: VLM description.
+//
+// Returns:
+//   - string: description without boilerplate.
+func (c *DescriptionCleaner) Clean(text string) string {
+	if c == nil || text == "" {
+		return text
+	}
+
+	cleaned := text
+	for _, re := range c.patterns {
+		cleaned = re.ReplaceAllString(cleaned, "")
+	}
+	for _, phrase := range c.phrases {
+		cleaned = strings.ReplaceAll(cleaned, phrase, "")
+	}
+
+	cleaned = repeatedCommaRe.ReplaceAllString(cleaned, "，")
+	cleaned = commaBeforeEndRe.ReplaceAllString(cleaned, "$1")
+	cleaned = strings.TrimLeft(cleaned, "，,。；; ")
+	return normalizeWhitespace(strings.TrimSpace(cleaned))
+}
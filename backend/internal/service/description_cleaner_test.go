@@ -0,0 +1,49 @@
+package service
+
+import "testing"
+
+func TestDescriptionCleanerStripsBoilerplate(t *testing.T) {
+	t.Parallel()
+
+	cleaner, err := NewDescriptionCleaner(DescriptionCleanerConfig{})
+	if err != nil {
+		t.Fatalf("NewDescriptionCleaner() error = %v", err)
+	}
+
+	tests := map[string]string{
+		`一只熊猫头表情包，文字写着"我不理解"，露出一脸疑惑、无语的表情，适合在困惑、震惊、无法理解对方行为时使用。`: `一只熊猫头表情包，文字写着"我不理解"，露出一脸疑惑、无语的表情。`,
+		"这张表情包，一只猫咪瘫倒在地，图中无文字，表情疲惫。":                             "一只猫咪瘫倒在地，表情疲惫。",
+		"柴犬露出微笑，非常适合在朋友出糗的时候发送":                                  "柴犬露出微笑",
+		"蘑菇头叉腰，表情嫌弃。": "蘑菇头叉腰，表情嫌弃。",
+	}
+	for input, want := range tests {
+		if got := cleaner.Clean(input); got != want {
+			t.Fatalf("Clean(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	var disabled *DescriptionCleaner
+	if got := disabled.Clean("适合在开心时使用"); got != "适合在开心时使用" {
+		t.Fatalf("nil Clean() = %q, want input unchanged", got)
+	}
+}
+
+func TestNewDescriptionCleanerUsesConfiguredLists(t *testing.T) {
+	t.Parallel()
+
+	cleaner, err := NewDescriptionCleaner(DescriptionCleanerConfig{
+		Phrases:  []string{"总之"},
+		Patterns: []string{`（[^）]*）`},
+	})
+	if err != nil {
+		t.Fatalf("NewDescriptionCleaner() error = %v", err)
+	}
+	// Configured lists replace the defaults.
+	if got := cleaner.Clean("总之，狗狗（柴犬）很开心，图中无文字"); got != "狗狗很开心，图中无文字" {
+		t.Fatalf("Clean() = %q", got)
+	}
+
+	if _, err := NewDescriptionCleaner(DescriptionCleanerConfig{Patterns: []string{"("}}); err == nil {
+		t.Fatal("NewDescriptionCleaner(invalid pattern) error = nil, want error")
+	}
+}
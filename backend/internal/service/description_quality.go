@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	descriptionMinRunes = 40  // The prompt asks for 80-150 characters; far shorter is a truncated reply
+	descriptionMaxRunes = 400 // Far longer usually means the model ignored the format
+	ocrCoverageMin      = 0.5 // Share of OCR characters the description must repeat
+
+	defaultDescriptionMinScore = 0.67
+)
+
+// Description quality issues recorded for curator review.
+const (
+	DescriptionIssueTooShort    = "too_short"
+	DescriptionIssueTooLong     = "too_long"
+	DescriptionIssueNoEmotion   = "no_emotion_words"
+	DescriptionIssueOCRMismatch = "ocr_mismatch"
+)
+
+// DescriptionQuality is the heuristic quality score of a VLM description.
+type DescriptionQuality struct {
+	Score  float64  // Share of applicable checks passed (0-1)
+	Issues []string // Failed checks
+}
+
+// DescriptionQualityConfig configures scoring of new VLM descriptions.
+type DescriptionQualityConfig struct {
+	MinScore     float64 // Descriptions scoring below are retried and then flagged for review (0 uses the default)
+	Retry        bool    // Retry low-scoring descriptions once with a stricter prompt
+	Disabled     bool    // Skip scoring entirely
+	TagsFallback bool    // Index memes the VLM fails on by their file name, tags and category, flagged for review
+}
+
+// scoreDescription checks a description for length, emotion words and
+// consistency with the OCR text. The OCR check only applies when the meme has text.
+func scoreDescription(description, ocrText string) DescriptionQuality {
+	var quality DescriptionQuality
+	checks := 0
+
+	checks++
+	switch length := len([]rune(strings.TrimSpace(description))); {
+	case length < descriptionMinRunes:
+		quality.Issues = append(quality.Issues, DescriptionIssueTooShort)
+	case length > descriptionMaxRunes:
+		quality.Issues = append(quality.Issues, DescriptionIssueTooLong)
+	}
+
+	checks++
+	if len(extractEmotionWords(description)) == 0 {
+		quality.Issues = append(quality.Issues, DescriptionIssueNoEmotion)
+	}
+
+	if ocrText != "" {
+		checks++
+		if ocrCoverage(description, ocrText) < ocrCoverageMin {
+			quality.Issues = append(quality.Issues, DescriptionIssueOCRMismatch)
+		}
+	}
+
+	quality.Score = float64(checks-len(quality.Issues)) / float64(checks)
+	return quality
+}
+
+// ocrCoverage returns the share of letters and digits in ocrText that also
+// appear in description. Punctuation and spacing are ignored.
+func ocrCoverage(description, ocrText string) float64 {
+	present := make(map[rune]bool)
+	for _, r := range strings.ToLower(description) {
+		present[r] = true
+	}
+
+	var total, found int
+	for _, r := range strings.ToLower(ocrText) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		total++
+		if present[r] {
+			found++
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+// checkDescriptionQuality scores a new description and, when it falls below the
+// minimum score, retries once with the strict prompt and keeps the better one.
+// The returned quality decides whether the description is flagged for review.
+func (s *IngestService) checkDescriptionQuality(ctx context.Context, imageData []byte, format, description, ocrText string) (string, DescriptionQuality) {
+	quality := scoreDescription(description, ocrText)
+	if quality.Score >= s.descriptionMinScore() || !s.quality.Retry || s.vlm == nil {
+		return description, quality
+	}
+
+	logger.CtxInfo(ctx, "Retrying low-quality description: score=%.2f, issues=%v", quality.Score, quality.Issues)
+	retried, err := s.vlm.DescribeImageStrict(ctx, imageData, format)
+	if err != nil {
+		logger.CtxWarn(ctx, "Strict description retry failed: error=%v", err)
+		return description, quality
+	}
+	if retriedQuality := scoreDescription(retried, ocrText); retriedQuality.Score > quality.Score {
+		return retried, retriedQuality
+	}
+	return description, quality
+}
+
+func (s *IngestService) descriptionMinScore() float64 {
+	if s.quality.MinScore > 0 {
+		return s.quality.MinScore
+	}
+	return defaultDescriptionMinScore
+}
+
+// applyDescriptionQuality records the quality score on a new description and
+// flags it for curator review when it is still below the minimum score.
+func (s *IngestService) applyDescriptionQuality(desc *domain.MemeDescription, quality DescriptionQuality) {
+	desc.QualityScore = quality.Score
+	desc.QualityIssues = quality.Issues
+	desc.NeedsReview = quality.Score < s.descriptionMinScore()
+}
+
+// describeImage generates the description and OCR text for a new meme,
+// running the quality check unless it is disabled.
+func (s *IngestService) describeImage(ctx context.Context, imageData []byte, format string) (string, string, *DescriptionQuality, error) {
+	description, err := s.vlm.DescribeImage(ctx, imageData, format)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to generate VLM description: %w", err)
+	}
+
+	ocrText, err := s.extractOCRText(ctx, imageData, format)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to extract OCR text: error=%v", err)
+		ocrText = ""
+	}
+
+	if s.quality.Disabled {
+		return description, ocrText, nil, nil
+	}
+	description, quality := s.checkDescriptionQuality(ctx, imageData, format, description, ocrText)
+	return description, ocrText, &quality, nil
+}
+
+// ListDescriptionsForReview returns descriptions flagged by the quality check.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of descriptions to return.
+//   - offset: number of descriptions to skip.
+//
+// Returns:
+//   - []domain.MemeDescription: flagged descriptions, lowest score first.
+//   - int64: total number of flagged descriptions.
+//   - error: non-nil if descriptions are not stored or the query fails.
+func (s *IngestService) ListDescriptionsForReview(ctx context.Context, limit, offset int) ([]domain.MemeDescription, int64, error) {
+	if s.descRepo == nil {
+		return nil, 0, errors.New("description repository not configured")
+	}
+	descs, err := s.descRepo.ListNeedingReview(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list descriptions for review: %w", err)
+	}
+	total, err := s.descRepo.CountNeedingReview(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count descriptions for review: %w", err)
+	}
+	return descs, total, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+const goodDescription = `一只熊猫头表情包，文字写着"我不理解"，露出一脸疑惑、无语的表情，歪着脑袋眼神空洞，表达对某事完全不理解的状态。`
+
+func TestScoreDescription(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		description string
+		ocrText     string
+		wantScore   float64
+		wantIssues  []string
+	}{
+		{name: "good with text", description: goodDescription, ocrText: "我不理解", wantScore: 1},
+		{name: "good without text", description: goodDescription, wantScore: 1},
+		{name: "short", description: "熊猫头，无语", wantScore: 0.5, wantIssues: []string{DescriptionIssueTooShort}},
+		{
+			name:        "missing text and emotion",
+			description: strings.Repeat("一只熊猫头歪着脑袋看向镜头，", 4),
+			ocrText:     "就这？",
+			wantScore:   1.0 / 3,
+			wantIssues:  []string{DescriptionIssueNoEmotion, DescriptionIssueOCRMismatch},
+		},
+	}
+
+	for _, tt := range tests {
+		quality := scoreDescription(tt.description, tt.ocrText)
+		if quality.Score != tt.wantScore {
+			t.Fatalf("%s: Score = %v, want %v", tt.name, quality.Score, tt.wantScore)
+		}
+		if !reflect.DeepEqual(quality.Issues, tt.wantIssues) {
+			t.Fatalf("%s: Issues = %v, want %v", tt.name, quality.Issues, tt.wantIssues)
+		}
+	}
+}
+
+func TestCheckDescriptionQualityRetriesWithStrictPrompt(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	vlm := NewVLMService(&VLMConfig{Model: "test-vlm", APIKey: "test-key", BaseURL: "https://vlm.test/v1"})
+	vlm.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": goodDescription}}},
+		}), nil
+	}))
+
+	ingest := &IngestService{vlm: vlm, quality: DescriptionQualityConfig{Retry: true}}
+	description, quality := ingest.checkDescriptionQuality(context.Background(), testPNG1x1, "png", "熊猫头", "我不理解")
+	if description != goodDescription || quality.Score != 1 {
+		t.Fatalf("checkDescriptionQuality() = %q (%v), want retried description", description, quality.Score)
+	}
+	if len(requests) != 1 || !strings.Contains(requests[0], "上一次的描述质量不合格") {
+		t.Fatalf("requests = %d, want one strict retry", len(requests))
+	}
+
+	desc := &domain.MemeDescription{}
+	ingest.applyDescriptionQuality(desc, scoreDescription("熊猫头", "我不理解"))
+	if !desc.NeedsReview || desc.QualityScore != 0 {
+		t.Fatalf("description = %+v, want flagged for review", desc)
+	}
+}
+
+func TestListDescriptionsForReview(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+	ctx := context.Background()
+	for _, desc := range []*domain.MemeDescription{
+		{ID: "ok", MD5Hash: "a", QualityScore: 1},
+		{ID: "bad", MD5Hash: "b", QualityScore: 0, NeedsReview: true},
+		{ID: "meh", MD5Hash: "c", QualityScore: 0.5, NeedsReview: true},
+	} {
+		desc.MemeID, desc.VLMModel, desc.Description = "meme", "vlm", "描述"
+		if err := descRepo.Create(ctx, desc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	ingest := &IngestService{descRepo: descRepo}
+	items, total, err := ingest.ListDescriptionsForReview(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListDescriptionsForReview() error = %v", err)
+	}
+	if total != 2 || len(items) != 2 || items[0].ID != "bad" || items[1].ID != "meh" {
+		t.Fatalf("ListDescriptionsForReview() = %d items (total %d), want bad then meh", len(items), total)
+	}
+}
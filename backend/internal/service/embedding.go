@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"mime"
 	"net/http"
 	"net/url"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/usage"
 )
 
 const (
@@ -65,6 +67,23 @@ type EmbeddingProviderConfig struct {
 	BaseURL      string // Base URL for provider APIs
 	DocumentMode string // Document embedding mode: "text" or "image"
 	Dimensions   int    // Embedding vector dimensions
+
+	QueryPrefix    string // Prepended to queries in EmbedQuery
+	DocumentPrefix string // Prepended to texts in Embed, EmbedBatch and EmbedDocument
+	Normalize      bool   // Scale every returned vector to unit length
+
+	Transport http.RoundTripper // nil uses the default HTTP transport
+}
+
+// newEmbeddingClient creates the API client of an embedding provider.
+func newEmbeddingClient(cfg *EmbeddingProviderConfig) *resty.Client {
+	client := resty.New()
+	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
+	client.SetHeader("Content-Type", "application/json")
+	if cfg.Transport != nil {
+		client.SetTransport(cfg.Transport)
+	}
+	return client
 }
 
 // NewEmbeddingProvider creates a new embedding provider based on the configuration.
@@ -73,16 +92,132 @@ func NewEmbeddingProvider(cfg *EmbeddingProviderConfig) (EmbeddingProvider, erro
 		return nil, fmt.Errorf("embedding provider config is nil")
 	}
 
+	var provider EmbeddingProvider
 	switch cfg.Provider {
 	case "jina":
-		return NewJinaEmbeddingProvider(cfg), nil
+		provider = NewJinaEmbeddingProvider(cfg)
 	case "siliconflow":
-		return NewSiliconFlowEmbeddingProvider(cfg), nil
+		provider = NewSiliconFlowEmbeddingProvider(cfg)
 	case "modelscope", "openai-compatible":
-		return NewOpenAICompatibleEmbeddingProvider(cfg), nil
+		provider = NewOpenAICompatibleEmbeddingProvider(cfg)
 	default:
 		return nil, fmt.Errorf("unknown embedding provider: %s", cfg.Provider)
 	}
+	if cfg.QueryPrefix != "" || cfg.DocumentPrefix != "" {
+		provider = &prefixedEmbeddingProvider{
+			EmbeddingProvider: provider,
+			queryPrefix:       cfg.QueryPrefix,
+			documentPrefix:    cfg.DocumentPrefix,
+		}
+	}
+	if cfg.Normalize {
+		provider = &normalizedEmbeddingProvider{EmbeddingProvider: provider}
+	}
+	return provider, nil
+}
+
+// normalizedEmbeddingProvider scales the vectors of an embedding provider to
+// unit length. Cosine collections do not care, but score thresholds tuned on
+// one provider only carry over to another when both return unit vectors.
+type normalizedEmbeddingProvider struct {
+	EmbeddingProvider
+}
+
+func (p *normalizedEmbeddingProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	return normalizeVector(p.EmbeddingProvider.Embed(ctx, text))
+}
+
+func (p *normalizedEmbeddingProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := p.EmbeddingProvider.EmbedBatch(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vectors {
+		l2Normalize(vectors[i])
+	}
+	return vectors, nil
+}
+
+func (p *normalizedEmbeddingProvider) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return normalizeVector(p.EmbeddingProvider.EmbedQuery(ctx, query))
+}
+
+func (p *normalizedEmbeddingProvider) EmbedDocument(ctx context.Context, doc EmbeddingDocument) ([]float32, error) {
+	return normalizeVector(p.EmbeddingProvider.EmbedDocument(ctx, doc))
+}
+
+// normalizeVector normalizes the vector of a successful embedding call.
+func normalizeVector(vector []float32, err error) ([]float32, error) {
+	if err != nil {
+		return nil, err
+	}
+	l2Normalize(vector)
+	return vector, nil
+}
+
+// l2Normalize scales vector in place to unit length; zero vectors are left as is.
+func l2Normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	scale := 1 / math.Sqrt(sum)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) * scale)
+	}
+}
+
+// prefixedEmbeddingProvider prepends instruction prefixes to the texts of an
+// embedding provider. Models such as bge and e5 expect a query instruction
+// (e.g. "为这个句子生成表示用于检索相关文章：") that documents do not carry.
+type prefixedEmbeddingProvider struct {
+	EmbeddingProvider
+	queryPrefix    string
+	documentPrefix string
+}
+
+func (p *prefixedEmbeddingProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	return p.EmbeddingProvider.Embed(ctx, p.documentPrefix+text)
+}
+
+func (p *prefixedEmbeddingProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if p.documentPrefix == "" {
+		return p.EmbeddingProvider.EmbedBatch(ctx, texts)
+	}
+	prefixed := make([]string, len(texts))
+	for i, text := range texts {
+		prefixed[i] = p.documentPrefix + text
+	}
+	return p.EmbeddingProvider.EmbedBatch(ctx, prefixed)
+}
+
+func (p *prefixedEmbeddingProvider) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	return p.EmbeddingProvider.EmbedQuery(ctx, p.queryPrefix+query)
+}
+
+// EmbedDocument prefixes the document text and text contents; images are
+// passed through unchanged.
+func (p *prefixedEmbeddingProvider) EmbedDocument(ctx context.Context, doc EmbeddingDocument) ([]float32, error) {
+	if p.documentPrefix == "" {
+		return p.EmbeddingProvider.EmbedDocument(ctx, doc)
+	}
+	if doc.Text != "" {
+		doc.Text = p.documentPrefix + doc.Text
+	}
+	if len(doc.Contents) > 0 {
+		contents := make([]EmbeddingContent, len(doc.Contents))
+		for i, content := range doc.Contents {
+			if content.Text != "" {
+				content.Text = p.documentPrefix + content.Text
+			}
+			contents[i] = content
+		}
+		doc.Contents = contents
+	}
+	return p.EmbeddingProvider.EmbedDocument(ctx, doc)
 }
 
 // =============================================================================
@@ -132,9 +267,7 @@ type siliconFlowEmbeddingResponse struct {
 
 // NewSiliconFlowEmbeddingProvider creates a new SiliconFlow embedding provider.
 func NewSiliconFlowEmbeddingProvider(cfg *EmbeddingProviderConfig) *SiliconFlowEmbeddingProvider {
-	client := resty.New()
-	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
-	client.SetHeader("Content-Type", "application/json")
+	client := newEmbeddingClient(cfg)
 
 	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
 	if baseURL == "" {
@@ -143,7 +276,7 @@ func NewSiliconFlowEmbeddingProvider(cfg *EmbeddingProviderConfig) *SiliconFlowE
 
 	return &SiliconFlowEmbeddingProvider{
 		client:       client,
-		imageClient:  &http.Client{Timeout: 30 * time.Second},
+		imageClient:  &http.Client{Timeout: 30 * time.Second, Transport: cfg.Transport},
 		baseURL:      baseURL,
 		model:        cfg.Model,
 		documentMode: normalizeEmbeddingDocumentMode(cfg.DocumentMode),
@@ -260,6 +393,7 @@ func (p *SiliconFlowEmbeddingProvider) embedMany(ctx context.Context, input any,
 	if len(resp.Data) == 0 {
 		return nil, fmt.Errorf("no embedding returned")
 	}
+	usage.AddEmbeddingTokens(ctx, resp.Usage.TotalTokens)
 
 	embeddings := make([][]float32, len(resp.Data))
 	for _, item := range resp.Data {
@@ -413,9 +547,7 @@ type jinaResponse struct {
 
 // NewJinaEmbeddingProvider creates a new Jina embedding provider.
 func NewJinaEmbeddingProvider(cfg *EmbeddingProviderConfig) *JinaEmbeddingProvider {
-	client := resty.New()
-	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
-	client.SetHeader("Content-Type", "application/json")
+	client := newEmbeddingClient(cfg)
 
 	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
 	if baseURL == "" {
@@ -480,6 +612,8 @@ func (p *JinaEmbeddingProvider) EmbedBatch(ctx context.Context, texts []string)
 	if err != nil {
 		return nil, err
 	}
+	usage.AddEmbeddingTokens(ctx, resp.Usage.TotalTokens)
+
 	if len(resp.Data) != len(texts) {
 		return nil, fmt.Errorf("unexpected number of embeddings: got %d, expected %d", len(resp.Data), len(texts))
 	}
@@ -560,6 +694,7 @@ func (p *JinaEmbeddingProvider) doRequest(ctx context.Context, req jinaRequest)
 		}
 		return nil, fmt.Errorf("Jina API error: status %d", httpResp.StatusCode())
 	}
+	usage.AddEmbeddingTokens(ctx, resp.Usage.TotalTokens)
 
 	return &resp, nil
 }
@@ -608,9 +743,7 @@ type openAIEmbeddingResponse struct {
 
 // NewOpenAICompatibleEmbeddingProvider creates a new OpenAI-compatible embedding provider.
 func NewOpenAICompatibleEmbeddingProvider(cfg *EmbeddingProviderConfig) *OpenAICompatibleEmbeddingProvider {
-	client := resty.New()
-	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
-	client.SetHeader("Content-Type", "application/json")
+	client := newEmbeddingClient(cfg)
 
 	baseURL := cfg.BaseURL
 	if baseURL == "" {
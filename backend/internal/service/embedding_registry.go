@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/timmy/emomo/internal/config"
 	"github.com/timmy/emomo/internal/domain"
@@ -24,13 +26,18 @@ type EmbeddingRegistry struct {
 
 // EmbeddingRegistryConfig holds configuration for creating an EmbeddingRegistry.
 type EmbeddingRegistryConfig struct {
-	Embeddings        []config.EmbeddingConfig
-	QdrantHost        string
-	QdrantPort        int
-	QdrantAPIKey      string
-	QdrantUseTLS      bool
-	DefaultCollection string // Fallback collection name if not specified in embedding config
-	Logger            *logger.Logger
+	Embeddings             []config.EmbeddingConfig
+	QdrantHost             string
+	QdrantPort             int
+	QdrantAPIKey           string
+	QdrantUseTLS           bool
+	QdrantPoolSize         int               // Pooled gRPC connections per collection (default: 1)
+	QdrantKeepaliveTime    time.Duration     // Idle keepalive ping interval (0 disables)
+	QdrantKeepaliveTimeout time.Duration     // Keepalive ping ack timeout
+	QdrantOperationTimeout time.Duration     // Default per-RPC deadline (0 disables)
+	DefaultCollection      string            // Fallback collection name if not specified in embedding config
+	Transport              http.RoundTripper // HTTP transport of the embedding providers (nil uses the default)
+	Logger                 *logger.Logger
 }
 
 // NewEmbeddingRegistry creates a new registry with all configured embeddings.
@@ -79,6 +86,11 @@ func NewEmbeddingRegistry(cfg *EmbeddingRegistryConfig) (*EmbeddingRegistry, err
 			BaseURL:      embCfg.BaseURL,
 			DocumentMode: embCfg.GetDocumentMode(),
 			Dimensions:   embCfg.Dimensions,
+
+			QueryPrefix:    embCfg.QueryPrefix,
+			DocumentPrefix: embCfg.DocumentPrefix,
+			Normalize:      embCfg.Normalize,
+			Transport:      cfg.Transport,
 		})
 		if err != nil {
 			logger.Warn("Failed to create embedding provider, skipping: name=%s, error=%v",
@@ -91,12 +103,17 @@ func NewEmbeddingRegistry(cfg *EmbeddingRegistryConfig) (*EmbeddingRegistry, err
 
 		// Create Qdrant repository
 		qdrantRepo, err := repository.NewQdrantRepository(&repository.QdrantConnectionConfig{
-			Host:            cfg.QdrantHost,
-			Port:            cfg.QdrantPort,
-			Collection:      collection,
-			APIKey:          cfg.QdrantAPIKey,
-			UseTLS:          cfg.QdrantUseTLS,
-			VectorDimension: embCfg.Dimensions,
+			Host:             cfg.QdrantHost,
+			Port:             cfg.QdrantPort,
+			Collection:       collection,
+			APIKey:           cfg.QdrantAPIKey,
+			UseTLS:           cfg.QdrantUseTLS,
+			VectorDimension:  embCfg.Dimensions,
+			Params:           collectionParamsFromConfig(embCfg.CollectionParams),
+			PoolSize:         cfg.QdrantPoolSize,
+			KeepaliveTime:    cfg.QdrantKeepaliveTime,
+			KeepaliveTimeout: cfg.QdrantKeepaliveTimeout,
+			OperationTimeout: cfg.QdrantOperationTimeout,
 		})
 		if err != nil {
 			logger.Warn("Failed to create Qdrant repository, skipping: name=%s, collection=%s, error=%v",
@@ -139,6 +156,19 @@ func NewEmbeddingRegistry(cfg *EmbeddingRegistryConfig) (*EmbeddingRegistry, err
 	return r, nil
 }
 
+// collectionParamsFromConfig maps the config-level tuning knobs onto repository params.
+func collectionParamsFromConfig(cfg config.CollectionParamsConfig) repository.CollectionParams {
+	return repository.CollectionParams{
+		HNSWM:                uint64(cfg.HNSWM),
+		HNSWEfConstruct:      uint64(cfg.HNSWEfConstruct),
+		FullScanThreshold:    uint64(cfg.FullScanThreshold),
+		OnDiskVectors:        cfg.OnDiskVectors,
+		OnDiskHNSW:           cfg.OnDiskHNSW,
+		DefaultSegmentNumber: uint64(cfg.DefaultSegmentNumber),
+		IndexingThreshold:    uint64(cfg.IndexingThreshold),
+	}
+}
+
 // Default returns the default embedding provider and its Qdrant repository.
 func (r *EmbeddingRegistry) Default() (EmbeddingProvider, *repository.QdrantRepository) {
 	r.mu.RLock()
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -339,3 +341,163 @@ func TestSiliconFlowEmbeddingProviderEmbedDocumentTextModeUsesTextContentAndTrun
 		t.Fatalf("unexpected truncate value: %q", got.Truncate)
 	}
 }
+
+func TestNewEmbeddingProviderAppliesQueryAndDocumentPrefixes(t *testing.T) {
+	t.Parallel()
+
+	provider, err := NewEmbeddingProvider(&EmbeddingProviderConfig{
+		Provider:       "openai-compatible",
+		Model:          "bge-large-zh",
+		APIKey:         "test-key",
+		BaseURL:        "https://openai.test",
+		QueryPrefix:    "为这个句子生成表示用于检索相关文章：",
+		DocumentPrefix: "passage: ",
+	})
+	if err != nil {
+		t.Fatalf("NewEmbeddingProvider returned error: %v", err)
+	}
+	prefixed, ok := provider.(*prefixedEmbeddingProvider)
+	if !ok {
+		t.Fatalf("NewEmbeddingProvider() = %T, want a prefixed provider", provider)
+	}
+
+	var inputs [][]string
+	prefixed.EmbeddingProvider.(*OpenAICompatibleEmbeddingProvider).client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var got openAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		inputs = append(inputs, got.Input)
+		data := make([]openAIEmbeddingData, len(got.Input))
+		for i := range data {
+			data[i] = openAIEmbeddingData{Embedding: []float64{1}, Index: i}
+		}
+		return jsonResponse(t, http.StatusOK, openAIEmbeddingResponse{Data: data}), nil
+	}))
+
+	ctx := context.Background()
+	if _, err := provider.EmbedQuery(ctx, "猫"); err != nil {
+		t.Fatalf("EmbedQuery returned error: %v", err)
+	}
+	if _, err := provider.EmbedBatch(ctx, []string{"a", "b"}); err != nil {
+		t.Fatalf("EmbedBatch returned error: %v", err)
+	}
+	if _, err := provider.EmbedDocument(ctx, EmbeddingDocument{Text: "desc"}); err != nil {
+		t.Fatalf("EmbedDocument returned error: %v", err)
+	}
+
+	want := [][]string{
+		{"为这个句子生成表示用于检索相关文章：猫"},
+		{"passage: a", "passage: b"},
+		{"passage: desc"},
+	}
+	if len(inputs) != len(want) {
+		t.Fatalf("requests = %v, want %v", inputs, want)
+	}
+	for i := range want {
+		if strings.Join(inputs[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("request %d input = %v, want %v", i, inputs[i], want[i])
+		}
+	}
+
+	plain, err := NewEmbeddingProvider(&EmbeddingProviderConfig{Provider: "jina", Model: "jina-embeddings-v4"})
+	if err != nil {
+		t.Fatalf("NewEmbeddingProvider returned error: %v", err)
+	}
+	if _, ok := plain.(*JinaEmbeddingProvider); !ok {
+		t.Fatalf("NewEmbeddingProvider() without prefixes = %T, want the bare provider", plain)
+	}
+}
+
+// Every provider and wrapper must stay usable wherever an EmbeddingProvider is
+// taken (ingest, search, query expansion caches, the registry).
+var (
+	_ EmbeddingProvider = (*JinaEmbeddingProvider)(nil)
+	_ EmbeddingProvider = (*SiliconFlowEmbeddingProvider)(nil)
+	_ EmbeddingProvider = (*OpenAICompatibleEmbeddingProvider)(nil)
+	_ EmbeddingProvider = (*prefixedEmbeddingProvider)(nil)
+	_ EmbeddingProvider = (*normalizedEmbeddingProvider)(nil)
+)
+
+func TestNewEmbeddingProviderReturnsEveryProvider(t *testing.T) {
+	t.Parallel()
+
+	for provider, want := range map[string]EmbeddingProvider{
+		"jina":              &JinaEmbeddingProvider{},
+		"siliconflow":       &SiliconFlowEmbeddingProvider{},
+		"modelscope":        &OpenAICompatibleEmbeddingProvider{},
+		"openai-compatible": &OpenAICompatibleEmbeddingProvider{},
+	} {
+		cfg := &EmbeddingProviderConfig{Provider: provider, Model: "model-" + provider, Dimensions: 8}
+		plain, err := NewEmbeddingProvider(cfg)
+		if err != nil {
+			t.Fatalf("NewEmbeddingProvider(%s) returned error: %v", provider, err)
+		}
+		if fmt.Sprintf("%T", plain) != fmt.Sprintf("%T", want) {
+			t.Fatalf("NewEmbeddingProvider(%s) = %T, want %T", provider, plain, want)
+		}
+
+		cfg.QueryPrefix, cfg.Normalize = "query: ", true
+		wrapped, err := NewEmbeddingProvider(cfg)
+		if err != nil {
+			t.Fatalf("NewEmbeddingProvider(%s, wrapped) returned error: %v", provider, err)
+		}
+		for _, p := range []EmbeddingProvider{plain, wrapped} {
+			if p.GetModel() != cfg.Model || p.GetDimensions() != cfg.Dimensions {
+				t.Fatalf("%T (%s) model, dimensions = %q, %d, want %q, %d", p, provider, p.GetModel(), p.GetDimensions(), cfg.Model, cfg.Dimensions)
+			}
+		}
+	}
+
+	if _, err := NewEmbeddingProvider(&EmbeddingProviderConfig{Provider: "unknown"}); err == nil {
+		t.Fatal("NewEmbeddingProvider(unknown) returned nil error")
+	}
+}
+
+func TestNormalizedEmbeddingProviderReturnsUnitVectors(t *testing.T) {
+	t.Parallel()
+
+	provider := &normalizedEmbeddingProvider{EmbeddingProvider: scaledEmbeddingProvider{}}
+	ctx := context.Background()
+
+	query, err := provider.EmbedQuery(ctx, "猫")
+	if err != nil {
+		t.Fatalf("EmbedQuery returned error: %v", err)
+	}
+	if query[0] != 0.6 || query[1] != 0.8 {
+		t.Fatalf("EmbedQuery() = %v, want [0.6 0.8]", query)
+	}
+
+	batch, err := provider.EmbedBatch(ctx, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedBatch returned error: %v", err)
+	}
+	for _, vector := range batch {
+		if vector[0] != 0.6 || vector[1] != 0.8 {
+			t.Fatalf("EmbedBatch() = %v, want unit vectors", batch)
+		}
+	}
+
+	zero := []float32{0, 0}
+	l2Normalize(zero)
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Fatalf("l2Normalize(zero) = %v, want it unchanged", zero)
+	}
+}
+
+// scaledEmbeddingProvider returns the unnormalized vector (3, 4).
+type scaledEmbeddingProvider struct {
+	fixedEmbeddingProvider
+}
+
+func (scaledEmbeddingProvider) EmbedQuery(context.Context, string) ([]float32, error) {
+	return []float32{3, 4}, nil
+}
+
+func (scaledEmbeddingProvider) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{3, 4}
+	}
+	return vectors, nil
+}
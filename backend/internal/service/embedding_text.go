@@ -1,6 +1,10 @@
 package service
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/timmy/emomo/internal/prompts"
+)
 
 const maxVLMEmbeddingRunes = 120
 
@@ -52,8 +56,8 @@ func extractEmotionWords(text string) []string {
 	}
 
 	lower := strings.ToLower(text)
-	matches := make([]string, 0, len(EmotionWords))
-	for _, word := range EmotionWords {
+	matches := make([]string, 0, len(prompts.EmotionWords))
+	for _, word := range prompts.EmotionWords {
 		if word == "" {
 			continue
 		}
@@ -104,20 +108,57 @@ func buildBM25Text(ocrText, description string, tags []string) string {
 	return strings.Join(segments, "\n")
 }
 
-func buildCaptionEmbeddingText(ocrText, description, category string, tags, emotions []string) string {
+// maxEmbeddingSegmentRepeat bounds segment weights so one segment cannot
+// crowd the rest out of the embedding model's input.
+const maxEmbeddingSegmentRepeat = 5
+
+// EmbeddingTextWeights sets how many times the OCR, description and tag
+// segments appear in caption embedding text. Repeating a segment raises its
+// share of the embedding; zero counts as one.
+type EmbeddingTextWeights struct {
+	OCR         int
+	Description int
+	Tags        int
+}
+
+// repeat returns how many times a segment with the given weight is written.
+func (w EmbeddingTextWeights) repeat(weight int) int {
+	if weight <= 0 {
+		return 1
+	}
+	return min(weight, maxEmbeddingSegmentRepeat)
+}
+
+// CaptionText builds the caption embedding text with segments repeated by
+// their weights. Category and emotion segments always appear once.
+// Parameters:
+//   - ocrText: normalized OCR text.
+//   - description: compacted VLM description.
+//   - category: meme category.
+//   - tags: meme tags.
+//   - emotions: emotion words found in the description.
+//
+// Returns:
+//   - string: newline-separated segments.
+func (w EmbeddingTextWeights) CaptionText(ocrText, description, category string, tags, emotions []string) string {
 	segments := make([]string, 0, 5)
+	appendSegment := func(segment string, weight int) {
+		for range w.repeat(weight) {
+			segments = append(segments, segment)
+		}
+	}
 	if ocrText != "" {
-		segments = append(segments, "图中文字："+ocrText)
+		appendSegment("图中文字："+ocrText, w.OCR)
 	}
 	if description != "" {
-		segments = append(segments, "画面描述："+description)
+		appendSegment("画面描述："+description, w.Description)
 	}
 	if category != "" {
 		segments = append(segments, "分类："+category)
 	}
 	tags = dedupeStrings(tags)
 	if len(tags) > 0 {
-		segments = append(segments, "标签："+strings.Join(tags, " "))
+		appendSegment("标签："+strings.Join(tags, " "), w.Tags)
 	}
 	emotions = dedupeStrings(emotions)
 	if len(emotions) > 0 {
@@ -126,6 +167,10 @@ func buildCaptionEmbeddingText(ocrText, description, category string, tags, emot
 	return strings.Join(segments, "\n")
 }
 
+func buildCaptionEmbeddingText(ocrText, description, category string, tags, emotions []string) string {
+	return EmbeddingTextWeights{}.CaptionText(ocrText, description, category, tags, emotions)
+}
+
 // BuildBM25Text exposes the BM25 sparse-vector text builder used by ingest, so
 // out-of-package tools (e.g. cmd/reembed) can reproduce identical sparse input
 // when re-creating Qdrant points from existing PG records.
@@ -0,0 +1,18 @@
+package service
+
+import "testing"
+
+func TestEmbeddingTextWeightsRepeatSegments(t *testing.T) {
+	t.Parallel()
+
+	got := EmbeddingTextWeights{OCR: 2, Tags: 9}.CaptionText("好的", "猫点头", "猫", []string{"ok", "ok"}, nil)
+	want := "图中文字：好的\n图中文字：好的\n画面描述：猫点头\n分类：猫\n" +
+		"标签：ok\n标签：ok\n标签：ok\n标签：ok\n标签：ok"
+	if got != want {
+		t.Fatalf("CaptionText() = %q, want %q", got, want)
+	}
+
+	if got, want := buildCaptionEmbeddingText("好的", "猫点头", "", nil, nil), "图中文字：好的\n画面描述：猫点头"; got != want {
+		t.Fatalf("buildCaptionEmbeddingText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// expansionLimiter caps the query expansion LLM calls in flight so traffic
+// spikes stay within provider rate limits. Searches that get no slot within
+// the queue timeout take the fast path without expansion.
+type expansionLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+// newExpansionLimiter returns a limiter for maxConcurrent calls, or nil when
+// maxConcurrent is not positive (unlimited).
+func newExpansionLimiter(maxConcurrent int, queueTimeout time.Duration) *expansionLimiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	return &expansionLimiter{
+		slots:        make(chan struct{}, maxConcurrent),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire takes a slot, waiting up to the queue timeout. The returned release
+// must be called once the LLM call is done; ok is false when no slot was free.
+func (l *expansionLimiter) acquire(ctx context.Context) (release func(), ok bool) {
+	if l == nil {
+		return func() {}, true
+	}
+
+	release = func() { <-l.slots }
+	select {
+	case l.slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	if l.queueTimeout <= 0 {
+		return nil, false
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// Acquire reserves a query expansion slot for a search.
+// Parameters:
+//   - ctx: request context; waiting stops when it is done.
+//
+// Returns:
+//   - func(): releases the slot; call it when the expansion has finished.
+//   - bool: false when the limit is reached and the search should skip expansion.
+func (s *QueryExpansionService) Acquire(ctx context.Context) (func(), bool) {
+	return s.limiter.acquire(ctx)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExpansionLimiterDegradesWhenFull(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	limiter := newExpansionLimiter(1, 20*time.Millisecond)
+
+	release, ok := limiter.acquire(ctx)
+	if !ok {
+		t.Fatal("acquire() with a free slot = false, want true")
+	}
+	if _, ok := limiter.acquire(ctx); ok {
+		t.Fatal("acquire() beyond the limit = true, want false after the queue timeout")
+	}
+
+	// A waiting search gets the slot once it is released.
+	done := make(chan bool)
+	waiting := newExpansionLimiter(1, time.Second)
+	releaseWaiting, _ := waiting.acquire(ctx)
+	go func() {
+		release, ok := waiting.acquire(ctx)
+		if ok {
+			release()
+		}
+		done <- ok
+	}()
+	releaseWaiting()
+	if !<-done {
+		t.Fatal("queued acquire() = false, want the released slot")
+	}
+
+	release()
+	if release, ok := limiter.acquire(ctx); !ok {
+		t.Fatal("acquire() after release = false, want true")
+	} else {
+		release()
+	}
+}
+
+func TestExpansionLimiterUnlimited(t *testing.T) {
+	t.Parallel()
+
+	if limiter := newExpansionLimiter(0, time.Second); limiter != nil {
+		t.Fatalf("newExpansionLimiter(0) = %v, want nil", limiter)
+	}
+	var limiter *expansionLimiter
+	for i := 0; i < 3; i++ {
+		if _, ok := limiter.acquire(context.Background()); !ok {
+			t.Fatal("acquire() on an unlimited limiter = false, want true")
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Health report states.
+const (
+	HealthStatusReady       = "ready"       // Every check passed
+	HealthStatusDegraded    = "degraded"    // Only non-critical checks failed
+	HealthStatusUnavailable = "unavailable" // A critical check failed
+	HealthStatusWarming     = "warming"     // Warm-up has not finished
+
+	defaultHealthCheckTimeout = 3 * time.Second
+	defaultHealthCacheTTL     = 5 * time.Second
+)
+
+// HealthCheck probes one dependency.
+type HealthCheck struct {
+	Name     string
+	Critical bool // A failure makes the service unavailable, not just degraded
+	// Check returns an optional short detail, e.g. a point count, or an error.
+	Check func(ctx context.Context) (string, error)
+}
+
+// DependencyStatus is the outcome of one health check.
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // ok or error
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport is the outcome of all health checks.
+type HealthReport struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at"`
+}
+
+// HealthCheckerConfig holds configuration for dependency health checks.
+type HealthCheckerConfig struct {
+	Timeout  time.Duration // Per check (default 3s)
+	CacheTTL time.Duration // How long a report is reused (default 5s; negative disables)
+}
+
+// HealthChecker runs dependency checks for readiness probes. Reports are
+// cached briefly so frequent probes from several orchestrators do not load
+// the dependencies; concurrent callers share one run.
+type HealthChecker struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	last    *HealthReport
+	running chan struct{}
+}
+
+// NewHealthChecker creates a HealthChecker.
+// Parameters:
+//   - cfg: check timeout and report cache lifetime.
+//   - checks: dependency checks, reported in this order.
+//
+// Returns:
+//   - *HealthChecker: initialized checker.
+func NewHealthChecker(cfg HealthCheckerConfig, checks ...HealthCheck) *HealthChecker {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	cacheTTL := cfg.CacheTTL
+	if cacheTTL == 0 {
+		cacheTTL = defaultHealthCacheTTL
+	}
+	return &HealthChecker{
+		checks:   checks,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// Check runs every check concurrently, or returns the cached report.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - HealthReport: per-dependency status and latency; Status is ready,
+//     degraded or unavailable.
+func (h *HealthChecker) Check(ctx context.Context) HealthReport {
+	for {
+		h.mu.Lock()
+		if h.last != nil && h.cacheTTL > 0 && h.now().Sub(h.last.CheckedAt) < h.cacheTTL {
+			report := *h.last
+			h.mu.Unlock()
+			return report
+		}
+		if h.running == nil {
+			break
+		}
+		running := h.running
+		h.mu.Unlock()
+		select {
+		case <-running:
+		case <-ctx.Done():
+			return HealthReport{Status: HealthStatusUnavailable, CheckedAt: h.now()}
+		}
+	}
+	running := make(chan struct{})
+	h.running = running
+	h.mu.Unlock()
+
+	// Checks outlive a probe that gives up, so their report can be cached.
+	report := h.run(context.WithoutCancel(ctx))
+
+	h.mu.Lock()
+	h.last = &report
+	h.running = nil
+	h.mu.Unlock()
+	close(running)
+	return report
+}
+
+// run executes the checks and builds a report.
+func (h *HealthChecker) run(ctx context.Context) HealthReport {
+	statuses := make([]DependencyStatus, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			detail, err := check.Check(checkCtx)
+			status := DependencyStatus{
+				Name:      check.Name,
+				Status:    "ok",
+				Critical:  check.Critical,
+				LatencyMs: time.Since(start).Milliseconds(),
+				Detail:    detail,
+			}
+			if err != nil {
+				status.Status = "error"
+				status.Error = err.Error()
+			}
+			statuses[i] = status
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthStatusReady, Dependencies: statuses, CheckedAt: h.now()}
+	for _, status := range statuses {
+		if status.Status == "ok" {
+			continue
+		}
+		if status.Critical {
+			report.Status = HealthStatusUnavailable
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+	return report
+}
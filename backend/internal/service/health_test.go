@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckerReportsAndCaches(t *testing.T) {
+	t.Parallel()
+
+	var dbCalls atomic.Int32
+	embeddingErr := errors.New("embedding api down")
+	var dbErr atomic.Pointer[error]
+	checker := NewHealthChecker(HealthCheckerConfig{Timeout: 50 * time.Millisecond, CacheTTL: time.Minute},
+		HealthCheck{Name: "database", Critical: true, Check: func(context.Context) (string, error) {
+			dbCalls.Add(1)
+			if err := dbErr.Load(); err != nil {
+				return "", *err
+			}
+			return "", nil
+		}},
+		HealthCheck{Name: "qdrant", Critical: true, Check: func(context.Context) (string, error) {
+			return "points=3", nil
+		}},
+		HealthCheck{Name: "embedding", Check: func(context.Context) (string, error) {
+			return "", embeddingErr
+		}},
+	)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	report := checker.Check(ctx)
+	if report.Status != HealthStatusDegraded {
+		t.Fatalf("Check() status = %s, want %s", report.Status, HealthStatusDegraded)
+	}
+	if len(report.Dependencies) != 3 || report.Dependencies[1].Detail != "points=3" ||
+		report.Dependencies[2].Status != "error" || report.Dependencies[2].Error != embeddingErr.Error() {
+		t.Fatalf("Check() dependencies = %+v, want qdrant detail and embedding error in order", report.Dependencies)
+	}
+
+	err := errors.New("connection refused")
+	dbErr.Store(&err)
+	if report := checker.Check(ctx); report.Status != HealthStatusDegraded || dbCalls.Load() != 1 {
+		t.Fatalf("cached Check() = %s after %d database checks, want the cached report", report.Status, dbCalls.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if report := checker.Check(ctx); report.Status != HealthStatusUnavailable {
+		t.Fatalf("Check() with the database down = %s, want %s", report.Status, HealthStatusUnavailable)
+	}
+}
+
+func TestHealthCheckerTimesOutChecks(t *testing.T) {
+	t.Parallel()
+
+	checker := NewHealthChecker(HealthCheckerConfig{Timeout: 20 * time.Millisecond, CacheTTL: -1},
+		HealthCheck{Name: "storage", Critical: true, Check: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	)
+	report := checker.Check(context.Background())
+	if report.Status != HealthStatusUnavailable || report.Dependencies[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("Check() = %+v, want the storage check to time out", report)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// defaultImageSearchMaxBytes bounds uploads when no limit is configured.
+const defaultImageSearchMaxBytes = 5 << 20
+
+var (
+	// ErrImageSearchDisabled is returned when no VLM is configured for image search.
+	ErrImageSearchDisabled = errors.New("image search is not configured")
+	// ErrImageTooLarge is returned when an uploaded image exceeds the size limit.
+	ErrImageTooLarge = errors.New("image too large")
+	// ErrInvalidImage wraps the reason an uploaded image was rejected.
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+// ImageSearchConfig bounds the images accepted by reverse image search.
+type ImageSearchConfig struct {
+	MaxBytes   int64                 // Largest accepted upload (defaults to 5 MiB)
+	Validation ImageValidationConfig // Dimension bounds; zero disables a bound
+}
+
+// ImageSearchRequest holds the options of a reverse image search. The image
+// itself arrives as a multipart file or, in JSON bodies, as base64.
+type ImageSearchRequest struct {
+	Image      string  `json:"image" form:"-"` // Base64 image or data URL (JSON bodies only)
+	TopK       int     `json:"top_k" form:"top_k"`
+	Category   *string `json:"category,omitempty" form:"category"`
+	SourceType *string `json:"source_type,omitempty" form:"source_type"`
+	Collection string  `json:"collection,omitempty" form:"collection"`
+	Profile    string  `json:"profile,omitempty" form:"profile"`
+}
+
+// SetImageSearch enables reverse image search with the given VLM.
+// Parameters:
+//   - vlm: VLM service that describes uploaded images (nil disables image search).
+//   - cfg: upload size and dimension limits.
+//
+// Returns: none.
+func (s *SearchService) SetImageSearch(vlm *VLMService, cfg ImageSearchConfig) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultImageSearchMaxBytes
+	}
+	s.vlm = vlm
+	s.imageSearch = cfg
+}
+
+// ImageSearchMaxBytes returns the largest accepted image upload.
+// Parameters: none.
+// Returns:
+//   - int64: size limit in bytes.
+func (s *SearchService) ImageSearchMaxBytes() int64 {
+	if s.imageSearch.MaxBytes <= 0 {
+		return defaultImageSearchMaxBytes
+	}
+	return s.imageSearch.MaxBytes
+}
+
+// ImageSearch finds memes similar to an uploaded image. The image is described
+// by the VLM the same way ingest describes memes, and the description is
+// searched like a text query without query expansion or filters detected
+// from the text.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - imageData: raw image bytes.
+//   - req: search options.
+//
+// Returns:
+//   - *SearchResponse: search results; Query holds the generated description.
+//   - error: ErrImageSearchDisabled, ErrImageTooLarge, ErrInvalidImage, ErrUnknownCollection,
+//     ErrUnknownProfile, or a search error.
+func (s *SearchService) ImageSearch(ctx context.Context, imageData []byte, req *ImageSearchRequest) (*SearchResponse, error) {
+	if s.vlm == nil {
+		return nil, ErrImageSearchDisabled
+	}
+	if err := s.ValidateSearchTarget(&SearchRequest{Collection: req.Collection, Profile: req.Profile}); err != nil {
+		return nil, err
+	}
+	imageData, format, err := s.prepareSearchImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := s.vlm.DescribeImage(ctx, imageData, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe image: %w", err)
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, fmt.Errorf("failed to describe image: empty description")
+	}
+	logger.CtxInfo(ctx, "Described search image: format=%s, size=%d, description=%q", format, len(imageData), logger.Query(description))
+
+	// Empty color and scene keep the description from turning into filters.
+	noFilter := ""
+	resp, err := s.textSearch(ctx, &SearchRequest{
+		Query:      description,
+		TopK:       req.TopK,
+		Category:   req.Category,
+		SourceType: req.SourceType,
+		Color:      &noFilter,
+		Scene:      &noFilter,
+		Collection: req.Collection,
+		Profile:    req.Profile,
+	}, false)
+	if err == nil {
+		s.recordQuery(ctx)
+		s.recordImpressions(ctx, resp)
+	}
+	return resp, err
+}
+
+// prepareSearchImage checks the size, format and dimensions of an upload and
+// converts formats the VLM does not take directly to JPEG.
+func (s *SearchService) prepareSearchImage(imageData []byte) ([]byte, string, error) {
+	if len(imageData) == 0 {
+		return nil, "", fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if int64(len(imageData)) > s.ImageSearchMaxBytes() {
+		return nil, "", ErrImageTooLarge
+	}
+
+	format := detectImageFormat(imageData)
+	if !isSupportedStaticImageFormat(format) {
+		return nil, "", fmt.Errorf("%w: unsupported format %s", ErrInvalidImage, format)
+	}
+	if err := validateImage(imageData, format, s.imageSearch.Validation); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if shouldConvertStaticImageToJPEG(format) {
+		converted, err := convertToJPEG(imageData, format)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		return converted, "jpeg", nil
+	}
+	return imageData, format, nil
+}
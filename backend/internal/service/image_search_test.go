@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestImageSearchRequiresVLM(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := searchService.ImageSearch(context.Background(), encodeTestPNG(t, 8, 8), &ImageSearchRequest{})
+	if !errors.Is(err, ErrImageSearchDisabled) {
+		t.Fatalf("ImageSearch() error = %v, want ErrImageSearchDisabled", err)
+	}
+}
+
+func TestPrepareSearchImage(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, nil)
+	searchService.SetImageSearch(&VLMService{}, ImageSearchConfig{
+		MaxBytes:   4096,
+		Validation: ImageValidationConfig{MaxWidth: 64, MaxHeight: 64},
+	})
+
+	data, format, err := searchService.prepareSearchImage(encodeTestPNG(t, 32, 32))
+	if err != nil || format != "png" || len(data) == 0 {
+		t.Fatalf("prepareSearchImage(png) = %d bytes, %q, %v, want png", len(data), format, err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrInvalidImage},
+		{"too large", make([]byte, 4097), ErrImageTooLarge},
+		{"not an image", []byte("GIF89a but not really an image"), ErrInvalidImage},
+		{"too wide", encodeTestPNG(t, 128, 16), ErrInvalidImage},
+	}
+	for _, tt := range tests {
+		if _, _, err := searchService.prepareSearchImage(tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("prepareSearchImage(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/source"
+)
+
+// ImageValidationConfig bounds the images accepted by ingest. Zero disables a bound.
+type ImageValidationConfig struct {
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+}
+
+// errSkipQuarantined is a sentinel error for source files that failed validation.
+var errSkipQuarantined = errors.New("skipped: quarantined")
+
+// errChecksumMismatch marks source files that differ from the checksum their
+// manifest lists, e.g. after a truncated or corrupted crawler transfer.
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// verifyChecksum compares data with the hex SHA-256 a manifest listed for it.
+// An empty checksum is not verified. The returned error message is the
+// quarantine reason.
+func verifyChecksum(data []byte, want string) error {
+	if want == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: manifest sha256 %s, file sha256 %s (%d bytes)", errChecksumMismatch, want, got, len(data))
+	}
+	return nil
+}
+
+// validateImage checks that data is a recognised, fully decodable image within
+// the configured dimensions. The returned error message is the quarantine reason.
+func validateImage(data []byte, detectedFormat string, limits ImageValidationConfig) error {
+	if detectedFormat == "unknown" {
+		return errors.New("unrecognized image format")
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unreadable image header: %v", err)
+	}
+	if (limits.MinWidth > 0 && cfg.Width < limits.MinWidth) || (limits.MinHeight > 0 && cfg.Height < limits.MinHeight) {
+		return fmt.Errorf("image too small: %dx%d (min %dx%d)", cfg.Width, cfg.Height, limits.MinWidth, limits.MinHeight)
+	}
+	if (limits.MaxWidth > 0 && cfg.Width > limits.MaxWidth) || (limits.MaxHeight > 0 && cfg.Height > limits.MaxHeight) {
+		return fmt.Errorf("image too large: %dx%d (max %dx%d)", cfg.Width, cfg.Height, limits.MaxWidth, limits.MaxHeight)
+	}
+
+	// Headers can be intact while the pixel data is truncated; only a full
+	// decode catches that. Dimensions were bounded above, so this is safe.
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("corrupt image data: %v", err)
+	}
+	return nil
+}
+
+// quarantineItem records a failed validation and returns errSkipQuarantined.
+func (s *IngestService) quarantineItem(ctx context.Context, sourceType string, item *source.MemeItem, imageData []byte, format string, reason error) error {
+	logger.CtxWarn(ctx, "Quarantining source item: source_id=%s, format=%s, reason=%v", item.SourceID, format, reason)
+
+	if s.quarantineRepo != nil {
+		now := time.Now()
+		if err := s.quarantineRepo.Upsert(ctx, &domain.QuarantinedItem{
+			ID:         uuid.New().String(),
+			SourceType: sourceType,
+			SourceID:   item.SourceID,
+			LocalPath:  item.LocalPath,
+			MD5Hash:    calculateMD5(imageData),
+			Format:     format,
+			FileSize:   int64(len(imageData)),
+			Reason:     reason.Error(),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}); err != nil {
+			return fmt.Errorf("failed to record quarantined item: %w", err)
+		}
+	}
+
+	return fmt.Errorf("%w: %w", errSkipQuarantined, reason)
+}
+
+// ListQuarantined returns quarantined source items, most recent first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of items to return.
+//   - offset: number of items to skip.
+//
+// Returns:
+//   - []domain.QuarantinedItem: quarantined items.
+//   - int64: total number of quarantined items.
+//   - error: non-nil if quarantine tracking is disabled or the query fails.
+func (s *IngestService) ListQuarantined(ctx context.Context, limit, offset int) ([]domain.QuarantinedItem, int64, error) {
+	if s.quarantineRepo == nil {
+		return nil, 0, errors.New("quarantine repository not configured")
+	}
+	items, err := s.quarantineRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list quarantined items: %w", err)
+	}
+	total, err := s.quarantineRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count quarantined items: %w", err)
+	}
+	return items, total, nil
+}
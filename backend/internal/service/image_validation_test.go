@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"github.com/timmy/emomo/internal/source"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidateImage(t *testing.T) {
+	t.Parallel()
+
+	limits := ImageValidationConfig{MinWidth: 32, MinHeight: 32, MaxWidth: 256, MaxHeight: 256}
+	valid := encodeTestPNG(t, 64, 64)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		reason string
+	}{
+		{name: "valid", data: valid, format: "png"},
+		{name: "unknown format", data: []byte("not an image at all"), format: "unknown", reason: "unrecognized"},
+		{name: "truncated", data: valid[:len(valid)-20], format: "png", reason: "corrupt"},
+		{name: "too small", data: encodeTestPNG(t, 8, 8), format: "png", reason: "too small"},
+		{name: "too large", data: encodeTestPNG(t, 300, 64), format: "png", reason: "too large"},
+	}
+
+	for _, tt := range tests {
+		err := validateImage(tt.data, tt.format, limits)
+		if tt.reason == "" {
+			if err != nil {
+				t.Fatalf("%s: validateImage() error = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Fatalf("%s: validateImage() error = %v, want reason containing %q", tt.name, err, tt.reason)
+		}
+	}
+}
+
+func TestProcessItemQuarantinesCorruptImage(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	valid := encodeTestPNG(t, 64, 64)
+	imagePath := filepath.Join(t.TempDir(), "broken.png")
+	if err := os.WriteFile(imagePath, valid[:len(valid)-20], 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	store := newMemoryObjectStorage()
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	ingest := &IngestService{storage: store}
+	ingest.SetQuarantineRepository(quarantineRepo)
+
+	_, err := ingest.processItem(context.Background(), "test", &source.MemeItem{
+		SourceID:  "broken.png",
+		LocalPath: imagePath,
+		Format:    "png",
+	}, &IngestOptions{})
+	if !errors.Is(err, errSkipQuarantined) {
+		t.Fatalf("processItem() error = %v, want errSkipQuarantined", err)
+	}
+	if len(store.objects) != 0 {
+		t.Fatalf("stored objects = %d, want 0", len(store.objects))
+	}
+
+	items, total, err := ingest.ListQuarantined(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("ListQuarantined() error = %v", err)
+	}
+	if total != 1 || len(items) != 1 {
+		t.Fatalf("quarantined items = %d (total %d), want 1", len(items), total)
+	}
+	if items[0].SourceID != "broken.png" || !strings.Contains(items[0].Reason, "corrupt") {
+		t.Fatalf("quarantined item = %+v, want broken.png with corrupt reason", items[0])
+	}
+}
+
+func TestProcessItemQuarantinesChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	valid := encodeTestPNG(t, 64, 64)
+	sum := sha256.Sum256(valid)
+	checksum := hex.EncodeToString(sum[:])
+	if err := verifyChecksum(valid, strings.ToUpper(checksum)); err != nil {
+		t.Fatalf("verifyChecksum(matching) error = %v, want nil", err)
+	}
+
+	// The transfer cut the file short, but what arrived still decodes
+	// as far as the header; only the manifest checksum tells
+	imagePath := filepath.Join(t.TempDir(), "cut.png")
+	if err := os.WriteFile(imagePath, valid[:len(valid)-20], 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	store := newMemoryObjectStorage()
+	ingest := &IngestService{storage: store}
+	ingest.SetQuarantineRepository(repository.NewQuarantineRepository(db))
+
+	result := ingest.processSourceItem(context.Background(), "test", source.MemeItem{
+		SourceID:  "cut.png",
+		LocalPath: imagePath,
+		Format:    "png",
+		SHA256:    checksum,
+	}, &IngestOptions{})
+	if !result.quarantined || !errors.Is(result.err, errChecksumMismatch) {
+		t.Fatalf("processSourceItem() = %+v, want quarantined checksum mismatch", result)
+	}
+	if len(store.objects) != 0 {
+		t.Fatalf("stored objects = %d, want 0", len(store.objects))
+	}
+
+	items, _, err := ingest.ListQuarantined(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("ListQuarantined() error = %v", err)
+	}
+	if len(items) != 1 || !strings.Contains(items[0].Reason, "manifest sha256 "+checksum) {
+		t.Fatalf("quarantined items = %+v, want cut.png with both checksums in the reason", items)
+	}
+
+	collector := newReportCollector("job", "test", time.Now())
+	collector.record(result)
+	collector.record(&processResult{sourceID: "broken.png", quarantined: true, err: errSkipQuarantined})
+	report := collector.finish(&IngestStats{TotalItems: 2, QuarantinedItems: 2}, nil)
+	if report.Quarantined != 2 || report.ChecksumMismatches != 1 {
+		t.Fatalf("report = %+v, want 2 quarantined with 1 checksum mismatch", report)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -13,42 +14,82 @@ import (
 	_ "image/png"
 	"io"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/cache"
 	"github.com/timmy/emomo/internal/domain"
 	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/metrics"
 	"github.com/timmy/emomo/internal/repository"
 	"github.com/timmy/emomo/internal/source"
 	"github.com/timmy/emomo/internal/storage"
 	_ "golang.org/x/image/webp"
+	"golang.org/x/sync/errgroup"
 )
 
 // IngestService handles the data ingestion pipeline.
 type IngestService struct {
-	memeRepo   *repository.MemeRepository
-	vectorRepo *repository.MemeVectorRepository
-	descRepo   *repository.MemeDescriptionRepository
-	qdrantRepo *repository.QdrantRepository
-	storage    storage.ObjectStorage
-	vlm        *VLMService
-	embedding  EmbeddingProvider
-	indexes    []IngestVectorIndex
-	logger     *logger.Logger
-	workers    int
-	batchSize  int
-	collection string // Target Qdrant collection name
+	memeRepo       *repository.MemeRepository
+	vectorRepo     *repository.MemeVectorRepository
+	descRepo       *repository.MemeDescriptionRepository
+	qdrantRepo     *repository.QdrantRepository
+	uow            *repository.UnitOfWork
+	sourceRepo     *repository.DataSourceRepository
+	quarantineRepo *repository.QuarantineRepository
+	jobRepo        *repository.IngestJobRepository
+	suggestionRepo *repository.CategorySuggestionRepository
+	coverRepo      *repository.CategoryCoverRepository
+	purger         CachePurger
+	sourceLocks    cache.Locker // Keeps two processes from ingesting one source (nil disables)
+	sourceLockMu   sync.Mutex
+	heldSources    map[string]*heldSourceLock // Source locks this process holds, shared by its runs
+	validation     ImageValidationConfig
+	converter      MediaConverter
+	origins        *OriginChecker
+	sceneTagger    *SceneTagger
+	cleaner        *DescriptionCleaner
+	textWeights    EmbeddingTextWeights
+	chunking       DescriptionChunking
+	nearDuplicates NearDuplicatePolicy
+	quality        DescriptionQualityConfig
+	sparseEncoder  SparseEncoder
+	skipRules      map[string]SkipRules // Per-source skip rules, keyed by source ID
+	posterOffset   time.Duration
+	storage        storage.ObjectStorage
+	vlm            *VLMService
+	embedding      EmbeddingProvider
+	indexes        []IngestVectorIndex
+	logger         *logger.Logger
+	workers        int
+	batchSize      int
+	queueSize      int
+	collection     string // Target Qdrant collection name
 }
 
 // IngestConfig holds configuration for the ingest service.
 type IngestConfig struct {
-	Workers       int
-	BatchSize     int
-	Collection    string // Target Qdrant collection name
-	VectorType    string // Fallback vector type when VectorIndexes is empty
-	VectorIndexes []IngestVectorIndex
+	Workers        int
+	BatchSize      int
+	QueueSize      int    // Items buffered for prioritization (0 uses max(2*Workers, BatchSize))
+	Collection     string // Target Qdrant collection name
+	VectorType     string // Fallback vector type when VectorIndexes is empty
+	VectorIndexes  []IngestVectorIndex
+	UnitOfWork     *repository.UnitOfWork   // Commits meme, description and vector records atomically (nil writes without a transaction)
+	Validation     ImageValidationConfig    // Bounds for accepted images; failures are quarantined
+	Converter      MediaConverter           // Converts HEIC/AVIF stills and WebM/MP4 clips (nil skips those formats)
+	Origins        *OriginChecker           // Checks and downloads items of URL-based sources (nil rejects them)
+	PosterOffset   time.Duration            // Position of the poster frame in clips; falls back to the first frame
+	SceneTagger    *SceneTagger             // Optional second pass producing scene tags (nil disables)
+	Cleaner        *DescriptionCleaner      // Strips boilerplate from descriptions before embedding (nil keeps them as is)
+	TextWeights    EmbeddingTextWeights     // Repetitions of the OCR, description and tag segments in caption embedding text
+	Chunking       DescriptionChunking      // Extra caption points for long descriptions (zero disables)
+	NearDuplicates NearDuplicatePolicy      // Handling of new images whose perceptual hash matches a stored meme (empty ingests them)
+	Quality        DescriptionQualityConfig // Scoring, strict retry and review flagging of new descriptions
+	SparseEncoder  SparseEncoder            // Learned sparse vectors written next to BM25 on hybrid indexes (nil disables)
 }
 
 // IngestVectorIndex describes one vector route to write during ingestion.
@@ -63,6 +104,16 @@ type IngestVectorIndex struct {
 	EmbeddingDimension int
 }
 
+// PointID returns the deterministic Qdrant point ID this index uses for an image.
+// Parameters:
+//   - md5Hash: MD5 hash of the processed image.
+//
+// Returns:
+//   - string: UUID string stable across ingest and reembed runs.
+func (i IngestVectorIndex) PointID(md5Hash string) string {
+	return generateDeterministicPointID(md5Hash, i.Collection, normalizeIngestVectorType(i.VectorType))
+}
+
 // NewIngestService creates a new ingest service.
 // Parameters:
 //   - memeRepo: repository for meme records.
@@ -105,21 +156,61 @@ func NewIngestService(
 	}
 
 	return &IngestService{
-		memeRepo:   memeRepo,
-		vectorRepo: vectorRepo,
-		descRepo:   descRepo,
-		qdrantRepo: qdrantRepo,
-		storage:    objectStorage,
-		vlm:        vlm,
-		embedding:  embedding,
-		indexes:    indexes,
-		logger:     log,
-		workers:    cfg.Workers,
-		batchSize:  cfg.BatchSize,
-		collection: cfg.Collection,
+		memeRepo:       memeRepo,
+		vectorRepo:     vectorRepo,
+		descRepo:       descRepo,
+		qdrantRepo:     qdrantRepo,
+		uow:            cfg.UnitOfWork,
+		validation:     cfg.Validation,
+		converter:      cfg.Converter,
+		origins:        cfg.Origins,
+		posterOffset:   cfg.PosterOffset,
+		sceneTagger:    cfg.SceneTagger,
+		cleaner:        cfg.Cleaner,
+		textWeights:    cfg.TextWeights,
+		chunking:       cfg.Chunking,
+		nearDuplicates: cfg.NearDuplicates,
+		quality:        cfg.Quality,
+		sparseEncoder:  cfg.SparseEncoder,
+		storage:        objectStorage,
+		vlm:            vlm,
+		embedding:      embedding,
+		indexes:        indexes,
+		logger:         log,
+		workers:        cfg.Workers,
+		batchSize:      cfg.BatchSize,
+		queueSize:      cfg.QueueSize,
+		collection:     cfg.Collection,
 	}
 }
 
+// SetSourceRepository sets the repository used to record source sync times.
+// Parameters:
+//   - sourceRepo: data source repository (nil disables sync tracking).
+//
+// Returns: none.
+func (s *IngestService) SetSourceRepository(sourceRepo *repository.DataSourceRepository) {
+	s.sourceRepo = sourceRepo
+}
+
+// SetQuarantineRepository sets the repository used to record files that fail validation.
+// Parameters:
+//   - quarantineRepo: quarantine repository (nil only logs failures).
+//
+// Returns: none.
+func (s *IngestService) SetQuarantineRepository(quarantineRepo *repository.QuarantineRepository) {
+	s.quarantineRepo = quarantineRepo
+}
+
+// SetCategorySuggestionRepository sets the review queue of discovered categories.
+// Parameters:
+//   - suggestionRepo: category suggestion repository.
+//
+// Returns: none.
+func (s *IngestService) SetCategorySuggestionRepository(suggestionRepo *repository.CategorySuggestionRepository) {
+	s.suggestionRepo = suggestionRepo
+}
+
 // log returns a logger from context if available, otherwise returns the default logger
 func (s *IngestService) log(ctx context.Context) *logger.Logger {
 	if l := logger.FromContext(ctx); l != nil {
@@ -130,17 +221,41 @@ func (s *IngestService) log(ctx context.Context) *logger.Logger {
 
 // IngestStats holds statistics for an ingestion run.
 type IngestStats struct {
-	TotalItems     int64
-	ProcessedItems int64
-	SkippedItems   int64
-	FailedItems    int64
-	StartTime      time.Time
-	EndTime        time.Time
+	JobID            string // Ingest job the run is recorded under
+	TotalItems       int64
+	ProcessedItems   int64
+	SkippedItems     int64
+	FailedItems      int64
+	QuarantinedItems int64
+	StartTime        time.Time
+	EndTime          time.Time
+}
+
+// recordIngestMetrics counts the items of an ingest run by outcome as
+// ingest.items, and its duration as ingest.duration.
+func recordIngestMetrics(sourceID string, stats *IngestStats, duration time.Duration, runErr error) {
+	sourceTag := metrics.Tag("source", sourceID)
+	for outcome, count := range map[string]int64{
+		"processed":   stats.ProcessedItems,
+		"skipped":     stats.SkippedItems,
+		"quarantined": stats.QuarantinedItems,
+		"failed":      stats.FailedItems,
+	} {
+		if count > 0 {
+			metrics.Count("ingest.items", count, sourceTag, metrics.Tag("outcome", outcome))
+		}
+	}
+	metrics.Timing("ingest.duration", duration, sourceTag, metrics.Tag("success", runErr == nil))
 }
 
 // IngestOptions holds options for ingestion.
 type IngestOptions struct {
-	Force bool // If true, skip existence checks and force re-process
+	Force          bool                      // If true, skip existence checks and force re-process
+	SkipRules      *SkipRules                // Overrides the source's skip rules for this run (nil keeps them)
+	Priorities     []source.CategoryPriority // Category priorities for this run; they replace the source's priority of matching items
+	JobID          string                    // Runs the job created by QueueIngestJob instead of recording a new one
+	NearDuplicates NearDuplicatePolicy       // Overrides the configured near duplicate policy for this run (empty keeps it)
+	Review         bool                      // New memes await moderation: stored as review and hidden from search
 }
 
 // IngestFromSource ingests memes from a data source.
@@ -157,120 +272,163 @@ func (s *IngestService) IngestFromSource(ctx context.Context, src source.Source,
 	if opts == nil {
 		opts = &IngestOptions{}
 	}
+	rules := s.skipRulesFor(src.GetSourceID(), opts)
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	if err := source.ValidatePriorities(opts.Priorities); err != nil {
+		return nil, err
+	}
+	runOpts := *opts
+	runOpts.SkipRules = &rules
+	opts = &runOpts
 
 	// Inject tracing fields into context
+	jobID := opts.JobID
+	if jobID == "" {
+		jobID = uuid.New().String()
+	}
 	ctx = logger.WithFields(ctx, logger.Fields{
 		logger.FieldComponent: "ingest",
-		logger.FieldJobID:     uuid.New().String(),
+		logger.FieldJobID:     jobID,
 		logger.FieldSource:    src.GetSourceID(),
 	})
 
+	release, err := s.lockSource(ctx, src.GetSourceID(), opts.JobID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	stats := &IngestStats{
+		JobID:     jobID,
 		StartTime: time.Now(),
 	}
+	job := s.startJob(ctx, jobID, src.GetSourceID(), stats.StartTime, opts.JobID != "")
+	stopProgress := s.trackJobProgress(ctx, job, stats)
+	report := newReportCollector(jobID, src.GetSourceID(), stats.StartTime)
 
 	logger.CtxInfo(ctx, "Starting ingestion: source=%s, limit=%d, force=%v",
 		src.GetSourceID(), limit, opts.Force)
 
-	// Create work channel and results channel
-	itemsChan := make(chan source.MemeItem, s.workers*2)
-	resultsChan := make(chan *processResult, s.workers*2)
-
-	// Start workers
-	var wg sync.WaitGroup
-	for i := 0; i < s.workers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			s.worker(ctx, workerID, src.GetSourceID(), itemsChan, resultsChan, opts)
-		}(i)
-	}
-
-	// Start result collector
-	done := make(chan struct{})
-	go func() {
-		for result := range resultsChan {
-			atomic.AddInt64(&stats.ProcessedItems, 1)
-			if result.skipped {
-				atomic.AddInt64(&stats.SkippedItems, 1)
-			} else if result.err != nil {
-				atomic.AddInt64(&stats.FailedItems, 1)
-				logger.CtxError(ctx, "Failed to process item: source_id=%s, error=%v",
-					result.sourceID, result.err)
-			}
+	// The producer feeds a bounded priority queue, so fetching pauses while
+	// workers are busy and high-priority items overtake queued backfill. Item
+	// failures are counted, not returned, so only cancellation stops the group
+	// early. A failed fetch ends the queue; fetched items still finish.
+	g, gctx := errgroup.WithContext(ctx)
+	queueSize := s.queueSize
+	if queueSize <= 0 {
+		queueSize = max(s.workers*2, s.batchSize)
+	}
+	items := newItemQueue(gctx, queueSize)
+
+	var fetchErr error
+	g.Go(func() error {
+		defer items.close()
+		err := s.produceItems(gctx, src, limit, opts.Priorities, items, stats)
+		if err != nil && gctx.Err() == nil {
+			fetchErr = err
+			return nil
 		}
-		close(done)
-	}()
+		return err
+	})
 
-	// Fetch items from source
-	cursor := ""
-	totalFetched := 0
-	for {
-		if ctx.Err() != nil {
-			break
-		}
+	workers := max(s.workers, 1)
+	for i := 0; i < workers; i++ {
+		g.Go(func() error {
+			for {
+				item, ok := items.pop(gctx)
+				if !ok {
+					return gctx.Err()
+				}
+				result := s.processSourceItem(gctx, src.GetSourceID(), item, opts)
+				s.recordResult(gctx, stats, result)
+				report.record(result)
+			}
+		})
+	}
 
-		remaining := limit - totalFetched
-		if remaining <= 0 {
-			break
+	runErr := g.Wait()
+	if runErr == nil {
+		runErr = fetchErr
+	}
+	stats.EndTime = time.Now()
+	duration := stats.EndTime.Sub(stats.StartTime)
+
+	if s.sourceRepo != nil && runErr == nil {
+		if err := s.sourceRepo.MarkSynced(ctx, src.GetSourceID(), src.GetDisplayName(), sourceTypeOf(src), stats.EndTime); err != nil {
+			logger.CtxWarn(ctx, "Failed to record source sync time: source=%s, error=%v", src.GetSourceID(), err)
 		}
+	}
+
+	logger.With(logger.Fields{
+		logger.FieldDurationMs: duration.Milliseconds(),
+		logger.FieldCount:      stats.ProcessedItems,
+	}).Info(ctx, "Ingestion completed: total=%d, processed=%d, skipped=%d, quarantined=%d, failed=%d",
+		stats.TotalItems, stats.ProcessedItems, stats.SkippedItems, stats.QuarantinedItems, stats.FailedItems)
+	recordIngestMetrics(src.GetSourceID(), stats, duration, runErr)
+	stopProgress()
+	final := report.finish(stats, runErr)
+	s.finishJob(ctx, job, final)
+	s.purgeCache(ctx, ingestPurgeKeys(final))
+
+	if runErr != nil {
+		return stats, fmt.Errorf("ingestion stopped early: %w", runErr)
+	}
+	return stats, nil
+}
 
-		batchLimit := s.batchSize
-		if batchLimit > remaining {
-			batchLimit = remaining
+// produceItems fetches batches from src and queues up to limit items, applying
+// the run's category priorities. It stops when the source is exhausted or ctx
+// is canceled; a failed fetch is returned.
+func (s *IngestService) produceItems(ctx context.Context, src source.Source, limit int, priorities []source.CategoryPriority, items *itemQueue, stats *IngestStats) error {
+	cursor := ""
+	totalFetched := 0
+	for totalFetched < limit {
+		batchLimit := limit - totalFetched
+		if s.batchSize > 0 && s.batchSize < batchLimit {
+			batchLimit = s.batchSize
 		}
 
-		items, nextCursor, err := src.FetchBatch(ctx, cursor, batchLimit)
+		batch, nextCursor, err := src.FetchBatch(ctx, cursor, batchLimit)
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			logger.CtxError(ctx, "Failed to fetch batch: error=%v", err)
-			break
+			return fmt.Errorf("failed to fetch batch: %w", err)
 		}
-
-		if len(items) == 0 {
-			break
+		if len(batch) == 0 {
+			return nil
 		}
 
-		atomic.AddInt64(&stats.TotalItems, int64(len(items)))
-		totalFetched += len(items)
+		atomic.AddInt64(&stats.TotalItems, int64(len(batch)))
+		totalFetched += len(batch)
 
-		for _, item := range items {
-			select {
-			case itemsChan <- item:
-			case <-ctx.Done():
-				break
+		for _, item := range batch {
+			if priority, ok := source.PriorityFor(priorities, item.Category); ok {
+				item.Priority = priority
+			}
+			if err := items.push(ctx, item); err != nil {
+				return err
 			}
 		}
 
 		if nextCursor == "" {
-			break
+			return nil
 		}
 		cursor = nextCursor
 	}
-
-	// Close items channel and wait for workers
-	close(itemsChan)
-	wg.Wait()
-
-	// Close results channel and wait for collector
-	close(resultsChan)
-	<-done
-
-	stats.EndTime = time.Now()
-	duration := stats.EndTime.Sub(stats.StartTime)
-
-	logger.With(logger.Fields{
-		logger.FieldDurationMs: duration.Milliseconds(),
-		logger.FieldCount:      stats.ProcessedItems,
-	}).Info(ctx, "Ingestion completed: total=%d, processed=%d, skipped=%d, failed=%d",
-		stats.TotalItems, stats.ProcessedItems, stats.SkippedItems, stats.FailedItems)
-
-	return stats, nil
+	return nil
 }
 
 type processResult struct {
-	sourceID string
-	skipped  bool
-	err      error
+	sourceID    string
+	category    string
+	reused      bool // An existing meme record gained missing vectors
+	skipped     bool
+	quarantined bool
+	err         error // Set for skipped and quarantined items too
 }
 
 // errSkipDuplicate is a sentinel error to indicate MD5 duplicate skip
@@ -279,44 +437,108 @@ var errSkipDuplicate = fmt.Errorf("skipped: duplicate MD5")
 // errSkipUnsupportedImageFormat is a sentinel error for unsupported source images.
 var errSkipUnsupportedImageFormat = errors.New("skipped: unsupported image format")
 
-func (s *IngestService) worker(ctx context.Context, workerID int, sourceType string, items <-chan source.MemeItem, results chan<- *processResult, opts *IngestOptions) {
-	for item := range items {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
+// errSkipRejected is a sentinel error for images a moderator rejected.
+var errSkipRejected = errors.New("skipped: rejected by moderation")
+
+// processSourceItem processes one source item and classifies the outcome.
+func (s *IngestService) processSourceItem(ctx context.Context, sourceType string, item source.MemeItem, opts *IngestOptions) *processResult {
+	result := &processResult{sourceID: item.SourceID, category: item.Category}
+	reused, err := s.processItem(ctx, sourceType, &item, opts)
+	result.reused, result.err = reused, err
+	if errors.Is(err, errSkipQuarantined) {
+		result.quarantined = true
+	} else if errors.Is(err, errSkipDuplicate) || errors.Is(err, errSkipUnsupportedImageFormat) ||
+		errors.Is(err, errSkipRule) || errors.Is(err, errSkipOrigin) || errors.Is(err, errSkipNearDuplicate) ||
+		errors.Is(err, errSkipRejected) {
+		result.skipped = true
+	}
+	return result
+}
 
-		result := &processResult{sourceID: item.SourceID}
+// recordResult adds a processed item to the run statistics.
+func (s *IngestService) recordResult(ctx context.Context, stats *IngestStats, result *processResult) {
+	atomic.AddInt64(&stats.ProcessedItems, 1)
+	switch {
+	case result.quarantined:
+		atomic.AddInt64(&stats.QuarantinedItems, 1)
+	case result.skipped:
+		atomic.AddInt64(&stats.SkippedItems, 1)
+	case result.err != nil:
+		atomic.AddInt64(&stats.FailedItems, 1)
+		logger.CtxError(ctx, "Failed to process item: source_id=%s, error=%v",
+			result.sourceID, result.err)
+	}
+}
 
-		// Process the item with the new multi-embedding logic
-		if err := s.processItem(ctx, sourceType, &item, opts); err != nil {
-			if errors.Is(err, errSkipDuplicate) || errors.Is(err, errSkipUnsupportedImageFormat) {
-				result.skipped = true
-			} else {
-				result.err = err
-			}
+// processItem ingests one source item and reports whether it reused an
+// existing meme record instead of creating one.
+func (s *IngestService) processItem(ctx context.Context, sourceType string, item *source.MemeItem, opts *IngestOptions) (bool, error) {
+	// Skip rules only look at item metadata and file size, so filtered items are never read.
+	if opts.SkipRules != nil {
+		if reason := opts.SkipRules.check(item); reason != "" {
+			logger.CtxDebug(ctx, "Skipping item by rule: source_id=%s, reason=%s", item.SourceID, reason)
+			return false, fmt.Errorf("%w: %s", errSkipRule, reason)
 		}
-
-		results <- result
 	}
-}
 
-func (s *IngestService) processItem(ctx context.Context, sourceType string, item *source.MemeItem, opts *IngestOptions) error {
 	// Read image data
-	imageData, err := s.readImage(item)
+	imageData, err := s.readImage(ctx, item)
 	if err != nil {
-		return fmt.Errorf("failed to read image: %w", err)
+		return false, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// A file that differs from its manifest checksum was damaged in transfer
+	// and must not reach storage or the index.
+	if err := verifyChecksum(imageData, item.SHA256); err != nil {
+		return false, s.quarantineItem(ctx, sourceType, item, imageData, item.Format, err)
 	}
 
 	// Detect actual image format from magic bytes (don't trust file extension)
-	actualFormat := detectImageFormat(imageData)
+	detectedFormat := detectImageFormat(imageData)
+	actualFormat := detectedFormat
 	if actualFormat == "unknown" {
 		actualFormat = item.Format // Fallback to extension if detection fails
 	}
 
+	// HEIC/AVIF stills and WebM/MP4 clips are converted up front. A clip is stored
+	// as transcoded MP4 while a JPEG frame of it goes through the still pipeline.
+	var clipData []byte
+	switch {
+	case isConvertibleStillFormat(actualFormat):
+		if s.converter == nil {
+			return false, fmt.Errorf("%w: %s (no media converter configured)", errSkipUnsupportedImageFormat, actualFormat)
+		}
+		converted, err := s.converter.ToJPEG(ctx, imageData, actualFormat)
+		if err != nil {
+			return false, fmt.Errorf("failed to convert %s to JPEG: %w", actualFormat, err)
+		}
+		logger.CtxDebug(ctx, "Converted %s to JPEG: original_size=%d, converted_size=%d",
+			actualFormat, len(imageData), len(converted))
+		imageData, detectedFormat, actualFormat = converted, detectImageFormat(converted), "jpeg"
+	case isClipFormat(actualFormat):
+		if s.converter == nil {
+			return false, fmt.Errorf("%w: %s (no media converter configured)", errSkipUnsupportedImageFormat, actualFormat)
+		}
+		clipData, err = s.converter.ToMP4(ctx, imageData, actualFormat)
+		if err != nil {
+			return false, fmt.Errorf("failed to transcode %s to MP4: %w", actualFormat, err)
+		}
+		frame, err := s.extractPosterFrame(ctx, clipData)
+		if err != nil {
+			return false, fmt.Errorf("failed to extract poster frame from %s: %w", actualFormat, err)
+		}
+		logger.CtxDebug(ctx, "Transcoded %s clip to MP4: original_size=%d, mp4_size=%d",
+			actualFormat, len(imageData), len(clipData))
+		imageData, detectedFormat, actualFormat = frame, detectImageFormat(frame), "jpeg"
+	}
+
 	if !isSupportedStaticImageFormat(actualFormat) {
-		return fmt.Errorf("%w: %s", errSkipUnsupportedImageFormat, actualFormat)
+		return false, fmt.Errorf("%w: %s", errSkipUnsupportedImageFormat, actualFormat)
+	}
+
+	// Keep corrupt, truncated or out-of-bounds files out of storage and the index.
+	if err := validateImage(imageData, detectedFormat, s.validation); err != nil {
+		return false, s.quarantineItem(ctx, sourceType, item, imageData, actualFormat, err)
 	}
 
 	// Convert WebP to JPEG for storage and VLM compatibility while preserving
@@ -325,7 +547,7 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 	if shouldConvertStaticImageToJPEG(actualFormat) {
 		converted, err := convertToJPEG(imageData, actualFormat)
 		if err != nil {
-			return fmt.Errorf("failed to convert %s to JPEG: %w", actualFormat, err)
+			return false, fmt.Errorf("failed to convert %s to JPEG: %w", actualFormat, err)
 		}
 		logger.CtxDebug(ctx, "Converted %s to JPEG: original_size=%d, converted_size=%d",
 			actualFormat, len(imageData), len(converted))
@@ -337,63 +559,72 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 			item.Format, actualFormat)
 	}
 
-	// Calculate MD5 hash (of the processed/converted image)
-	md5Hash := calculateMD5(imageData)
+	// The stored object is the clip for animated memes, otherwise the still itself.
+	storedData, storedFormat := imageData, processedFormat
+	if clipData != nil {
+		storedData, storedFormat = clipData, "mp4"
+	}
+
+	// Calculate MD5 hash (of the processed/converted object)
+	md5Hash := calculateMD5(storedData)
 
 	targetIndexes, err := s.missingVectorIndexes(ctx, md5Hash, opts.Force)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if len(targetIndexes) == 0 {
-		return errSkipDuplicate
+		return false, errSkipDuplicate
 	}
 
 	// Check if we have an existing meme record (for resource reuse)
 	existingMeme, err := s.memeRepo.GetByMD5Hash(ctx, md5Hash)
 	hasExistingMeme := err == nil && existingMeme != nil
+	if hasExistingMeme && existingMeme.Status == domain.MemeStatusRejected {
+		return false, errSkipRejected
+	}
 
 	var memeID string
 	var storageKey string
 	var storageURL string
+	var imageURL string  // URL of the still used for image embeddings (the poster for clips)
+	var posterURL string // Poster frame URL, set for animated memes only
 	var vlmDescription string
 	var ocrText string
+	var sceneTags []string
+	var storedBM25Text string // BM25 text already recorded on a reused description
 	var descriptionID string
 	var width, height int
-	uploaded := false
-	createdNewMeme := false        // Track if we created a new meme record for rollback
-	createdNewDescription := false // Track if we created a new description record for rollback
-
-	// rollbackMeme cleans up the meme record if we created one
-	rollbackMeme := func() {
-		if createdNewMeme && memeID != "" {
-			if delErr := s.memeRepo.Delete(ctx, memeID); delErr != nil {
-				logger.CtxError(ctx, "Failed to rollback meme record: meme_id=%s, error=%v", memeID, delErr)
-			} else {
-				logger.CtxDebug(ctx, "Rolled back meme record: meme_id=%s", memeID)
-			}
-		}
-	}
+	var palette ColorPalette
+	var newMeme *domain.Meme                   // Meme record to insert in the final transaction
+	var newDescription *domain.MemeDescription // Description record to insert in the final transaction
+	var pendingReview bool                     // Points of the meme are hidden from search until it is approved
+	var uploadedKeys []string
 
-	// rollbackDescription cleans up the description record if we created one
-	rollbackDescription := func() {
-		if createdNewDescription && descriptionID != "" && s.descRepo != nil {
-			if delErr := s.descRepo.Delete(ctx, descriptionID); delErr != nil {
-				logger.CtxError(ctx, "Failed to rollback description record: description_id=%s, error=%v", descriptionID, delErr)
+	// rollbackStorage cleans up the storage uploads made for this item
+	rollbackStorage := func() {
+		for _, key := range uploadedKeys {
+			if delErr := s.storage.Delete(ctx, key); delErr != nil {
+				logger.CtxError(ctx, "Failed to rollback storage upload: storage_key=%s, error=%v", key, delErr)
 			} else {
-				logger.CtxDebug(ctx, "Rolled back description record: description_id=%s", descriptionID)
+				logger.CtxDebug(ctx, "Rolled back storage upload: storage_key=%s", key)
 			}
 		}
 	}
 
-	// rollbackStorage cleans up the storage upload if we uploaded
-	rollbackStorage := func() {
-		if uploaded {
-			if delErr := s.storage.Delete(ctx, storageKey); delErr != nil {
-				logger.CtxError(ctx, "Failed to rollback storage upload: storage_key=%s, error=%v", storageKey, delErr)
-			} else {
-				logger.CtxDebug(ctx, "Rolled back storage upload: storage_key=%s", storageKey)
-			}
+	// uploadIfMissing stores data under key unless an object already exists there
+	uploadIfMissing := func(key string, data []byte, format string) error {
+		exists, err := s.storage.Exists(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check storage existence: %w", err)
 		}
+		if exists {
+			return nil
+		}
+		if err := s.storage.Upload(ctx, key, bytes.NewReader(data), int64(len(data)), getContentType(format)); err != nil {
+			return fmt.Errorf("failed to upload to storage: %w", err)
+		}
+		uploadedKeys = append(uploadedKeys, key)
+		return nil
 	}
 
 	if hasExistingMeme {
@@ -401,8 +632,15 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 		memeID = existingMeme.ID
 		storageKey = existingMeme.StorageKey
 		storageURL = s.storage.GetURL(storageKey)
+		stillKey, _ := stillObject(existingMeme)
+		imageURL = s.storage.GetURL(stillKey)
+		if existingMeme.PosterKey != "" {
+			posterURL = imageURL
+		}
 		width = existingMeme.Width
 		height = existingMeme.Height
+		palette = ColorPalette{Colors: existingMeme.DominantColors, Saturation: existingMeme.Saturation}
+		pendingReview = existingMeme.Status == domain.MemeStatusReview
 
 		logger.CtxInfo(ctx, "Reusing existing meme record: md5=%s, meme_id=%s, collection=%s",
 			md5Hash, memeID, s.collection)
@@ -417,52 +655,79 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 			width, height = 0, 0
 		}
 
-		// Upload to storage (use MD5 prefix for bucketing)
-		storageKey = fmt.Sprintf("%s/%s.%s", md5Hash[:2], md5Hash, processedFormat)
-		contentType := getContentType(processedFormat)
-
-		// Check if file already exists in storage
-		existsInStorage, err := s.storage.Exists(ctx, storageKey)
+		// Dominant colors drive the color search filter and UI theming; the
+		// perceptual hash finds re-encoded copies of stored memes
+		var perceptualHash string
+		if img, _, err := image.Decode(bytes.NewReader(imageData)); err != nil {
+			logger.CtxWarn(ctx, "Failed to decode image for color palette and perceptual hash: error=%v", err)
+		} else {
+			palette = *paletteFromImage(img)
+			perceptualHash = dHash(img)
+		}
+		variantOf, err := s.checkNearDuplicate(ctx, perceptualHash, opts)
 		if err != nil {
-			return fmt.Errorf("failed to check storage existence: %w", err)
+			return false, err
 		}
-
-		if !existsInStorage {
-			if err := s.storage.Upload(ctx, storageKey, bytes.NewReader(imageData), int64(len(imageData)), contentType); err != nil {
-				return fmt.Errorf("failed to upload to storage: %w", err)
-			}
-			uploaded = true
+		if variantOf != "" {
+			logger.CtxInfo(ctx, "Linking near duplicate as variant: md5=%s, perceptual_hash=%s, variant_of=%s",
+				md5Hash, perceptualHash, variantOf)
 		}
 
+		// Upload to storage (use MD5 prefix for bucketing)
+		storageKey = fmt.Sprintf("%s/%s.%s", md5Hash[:2], md5Hash, storedFormat)
+		if err := uploadIfMissing(storageKey, storedData, storedFormat); err != nil {
+			return false, err
+		}
 		storageURL = s.storage.GetURL(storageKey)
+		imageURL = storageURL
+
+		// Clips get a static poster frame so list views render instantly and
+		// embedding providers, which fetch images by URL, get a still.
+		var posterKey string
+		if clipData != nil {
+			posterKey = posterStorageKey(md5Hash)
+			if err := uploadIfMissing(posterKey, imageData, processedFormat); err != nil {
+				rollbackStorage()
+				return false, err
+			}
+			posterURL = s.storage.GetURL(posterKey)
+			imageURL = posterURL
+		}
 
-		// Create meme record (without VLM description - stored in meme_descriptions table)
-		meme := &domain.Meme{
-			ID:         memeID,
-			SourceType: sourceType,
-			SourceID:   item.SourceID,
-			StorageKey: storageKey,
-			LocalPath:  item.LocalPath,
-			Width:      width,
-			Height:     height,
-			Format:     processedFormat,
-			IsAnimated: false,
-			FileSize:   int64(len(imageData)),
-			MD5Hash:    md5Hash,
-			Tags:       item.Tags,
-			Category:   item.Category,
-			Status:     domain.MemeStatusActive,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-		}
-
-		// Save meme to database first
-		if err := s.memeRepo.Upsert(ctx, meme); err != nil {
-			// Rollback storage if we uploaded
-			rollbackStorage()
-			return fmt.Errorf("failed to save meme to database: %w", err)
+		// Build meme record (without VLM description - stored in meme_descriptions table).
+		// It is saved together with its vectors once every external write succeeded.
+		newMeme = &domain.Meme{
+			ID:             memeID,
+			SourceType:     sourceType,
+			SourceID:       item.SourceID,
+			StorageKey:     storageKey,
+			LocalPath:      item.LocalPath,
+			SourceURL:      remoteURL(item),
+			Width:          width,
+			Height:         height,
+			Format:         storedFormat,
+			IsAnimated:     clipData != nil,
+			PosterKey:      posterKey,
+			FileSize:       int64(len(storedData)),
+			MD5Hash:        md5Hash,
+			PerceptualHash: perceptualHash,
+			VariantOf:      variantOf,
+			DominantColors: palette.Colors,
+			Saturation:     palette.Saturation,
+			Tags:           item.Tags,
+			Category:       item.Category,
+			Status:         domain.MemeStatusActive,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+		if newMeme.SourceURL != "" {
+			checkedAt := time.Now()
+			newMeme.OriginStatus, newMeme.OriginCheckedAt = domain.OriginStatusOK, &checkedAt
+		}
+		if opts.Review {
+			newMeme.Status = domain.MemeStatusReview
+			pendingReview = true
 		}
-		createdNewMeme = true // Mark that we created a new meme record
 	}
 
 	// Get or create VLM description for current VLM model
@@ -473,6 +738,8 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 			vlmDescription = existingDesc.Description
 			descriptionID = existingDesc.ID
 			ocrText = normalizeOCRText(existingDesc.OCRText)
+			sceneTags = existingDesc.SceneTags
+			storedBM25Text = existingDesc.BM25Text
 			if ocrText == "" {
 				ocrText, err = s.extractOCRText(ctx, imageData, processedFormat)
 				if err != nil {
@@ -486,57 +753,50 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 			logger.CtxDebug(ctx, "Reusing existing VLM description: md5=%s, vlm_model=%s", md5Hash, s.vlm.GetModel())
 		} else {
 			// Generate new VLM description
-			vlmDescription, err = s.vlm.DescribeImage(ctx, imageData, processedFormat)
+			var quality *DescriptionQuality
+			var promptVersion string
+			vlmDescription, ocrText, quality, promptVersion, err = s.describeImageOrTags(ctx, imageData, processedFormat, fallbackSubject{
+				Path:     item.LocalPath,
+				SourceID: item.SourceID,
+				Category: item.Category,
+				Tags:     item.Tags,
+			})
 			if err != nil {
-				rollbackMeme()
 				rollbackStorage()
-				return fmt.Errorf("failed to generate VLM description: %w", err)
-			}
-
-			ocrText, err = s.extractOCRText(ctx, imageData, processedFormat)
-			if err != nil {
-				logger.CtxWarn(ctx, "Failed to extract OCR text: md5=%s, error=%v", md5Hash, err)
-				ocrText = ""
+				return false, err
 			}
 
-			// Save description to meme_descriptions table
-			descRecord := &domain.MemeDescription{
-				ID:          uuid.New().String(),
-				MemeID:      memeID,
-				MD5Hash:     md5Hash,
-				VLMModel:    s.vlm.GetModel(),
-				Description: vlmDescription,
-				OCRText:     ocrText,
-				CreatedAt:   time.Now(),
+			newDescription = &domain.MemeDescription{
+				ID:            uuid.New().String(),
+				MemeID:        memeID,
+				MD5Hash:       md5Hash,
+				VLMModel:      s.vlm.GetModel(),
+				Description:   vlmDescription,
+				PromptVersion: promptVersion,
+				OCRText:       ocrText,
+				CreatedAt:     time.Now(),
 			}
-			if err := s.descRepo.Create(ctx, descRecord); err != nil {
-				rollbackMeme()
-				rollbackStorage()
-				return fmt.Errorf("failed to save VLM description: %w", err)
+			if quality != nil {
+				s.applyDescriptionQuality(newDescription, *quality)
 			}
-			descriptionID = descRecord.ID
-			createdNewDescription = true
-			logger.CtxDebug(ctx, "Created new VLM description: md5=%s, vlm_model=%s, description_id=%s",
-				md5Hash, s.vlm.GetModel(), descriptionID)
+			descriptionID = newDescription.ID
 		}
 	} else {
 		// Fallback: generate VLM description without storing to database
-		vlmDescription, err = s.vlm.DescribeImage(ctx, imageData, processedFormat)
+		vlmDescription, ocrText, _, err = s.describeImage(ctx, imageData, processedFormat)
 		if err != nil {
-			rollbackMeme()
 			rollbackStorage()
-			return fmt.Errorf("failed to generate VLM description: %w", err)
+			return false, err
 		}
+	}
 
-		ocrText, err = s.extractOCRText(ctx, imageData, processedFormat)
-		if err != nil {
-			logger.CtxWarn(ctx, "Failed to extract OCR text: md5=%s, error=%v", md5Hash, err)
-			ocrText = ""
-		}
+	if len(sceneTags) == 0 {
+		sceneTags = s.tagScenes(ctx, vlmDescription, ocrText, newDescription, descriptionID)
 	}
 
-	compactDesc := compactDescription(vlmDescription)
-	captionText := buildCaptionEmbeddingText(
+	cleanedDesc := s.cleaner.Clean(vlmDescription)
+	compactDesc := compactDescription(cleanedDesc)
+	captionText := s.textWeights.CaptionText(
 		ocrText,
 		compactDesc,
 		item.Category,
@@ -544,6 +804,9 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 		extractEmotionWords(vlmDescription),
 	)
 	bm25Text := buildBM25Text(ocrText, compactDesc, item.Tags)
+	if newDescription != nil {
+		newDescription.BM25Text = bm25Text
+	}
 	payload := &repository.MemePayload{
 		MemeID:         memeID,
 		SourceType:     sourceType,
@@ -552,32 +815,61 @@ func (s *IngestService) processItem(ctx context.Context, sourceType string, item
 		VLMDescription: vlmDescription,
 		OCRText:        ocrText,
 		StorageURL:     storageURL,
+		PosterURL:      posterURL,
+		Colors:         paletteColorNames(palette.Colors),
+		Saturation:     palette.Saturation,
+		TextLang:       detectTextLanguage(ocrText),
+		SceneTags:      sceneTags,
+		PendingReview:  pendingReview,
 	}
 
-	if err := s.upsertVectorIndexes(ctx, targetIndexes, vectorUpsertInput{
+	written, err := s.upsertVectorIndexes(ctx, targetIndexes, vectorUpsertInput{
 		MemeID:         memeID,
 		MD5Hash:        md5Hash,
 		DescriptionID:  descriptionID,
-		ImageURL:       storageURL,
+		ImageURL:       imageURL,
 		ImageData:      imageData,
 		ImageMediaType: getContentType(processedFormat),
 		CaptionText:    captionText,
 		BM25Text:       bm25Text,
+		Chunks:         s.chunking.split(cleanedDesc),
 		Payload:        payload,
-	}); err != nil {
-		if createdNewMeme {
-			s.rollbackVectorIndexes(ctx, memeID, targetIndexes)
+		Replace:        opts.Force,
+	})
+	if err != nil {
+		s.rollbackVectorPoints(ctx, written)
+		rollbackStorage()
+		return false, err
+	}
+
+	// Persist meme, description and vector records atomically so a failure
+	// cannot leave a meme without its vectors (or vice versa).
+	if err := s.withTx(ctx, func(repos *repository.TxRepositories) error {
+		if newMeme != nil {
+			if err := repos.Memes.Upsert(ctx, newMeme); err != nil {
+				return fmt.Errorf("failed to save meme to database: %w", err)
+			}
+		}
+		if newDescription != nil && repos.Descriptions != nil {
+			if err := repos.Descriptions.Create(ctx, newDescription); err != nil {
+				return fmt.Errorf("failed to save VLM description: %w", err)
+			}
+		}
+		if err := saveBM25Text(ctx, repos, newDescription, descriptionID, storedBM25Text, bm25Text); err != nil {
+			return err
 		}
-		rollbackDescription()
-		rollbackMeme()
+		return saveVectorRecords(ctx, repos, written)
+	}); err != nil {
+		s.rollbackVectorPoints(ctx, written)
 		rollbackStorage()
-		return err
+		return false, err
 	}
+	s.deleteReplacedPoints(ctx, written)
 
 	logger.CtxDebug(ctx, "Successfully processed item: meme_id=%s, vectors=%d, reused=%v",
 		memeID, len(targetIndexes), hasExistingMeme)
 
-	return nil
+	return hasExistingMeme, nil
 }
 
 func (s *IngestService) extractOCRText(ctx context.Context, imageData []byte, format string) (string, error) {
@@ -591,6 +883,33 @@ func (s *IngestService) extractOCRText(ctx context.Context, imageData []byte, fo
 	return normalizeOCRText(text), nil
 }
 
+// tagScenes runs the optional scene tagger and records the tags on the
+// description: on newDesc before it is inserted, or on the stored description
+// descriptionID otherwise. Tagging failures are logged and yield no tags.
+func (s *IngestService) tagScenes(ctx context.Context, description, ocrText string, newDesc *domain.MemeDescription, descriptionID string) []string {
+	if !s.sceneTagger.IsEnabled() {
+		return nil
+	}
+	tags, err := s.sceneTagger.Tag(ctx, description, ocrText)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to tag scenes: error=%v", err)
+		return nil
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	switch {
+	case newDesc != nil:
+		newDesc.SceneTags = tags
+	case descriptionID != "" && s.descRepo != nil:
+		if err := s.descRepo.UpdateSceneTags(ctx, descriptionID, tags); err != nil {
+			logger.CtxWarn(ctx, "Failed to update scene tags: description_id=%s, error=%v", descriptionID, err)
+		}
+	}
+	return tags
+}
+
 type vectorUpsertInput struct {
 	MemeID         string
 	MD5Hash        string
@@ -600,7 +919,9 @@ type vectorUpsertInput struct {
 	ImageMediaType string
 	CaptionText    string
 	BM25Text       string
+	Chunks         []string // Description chunks indexed as extra caption points
 	Payload        *repository.MemePayload
+	Replace        bool // Existing vector records may be overwritten (force re-run)
 }
 
 func (s *IngestService) missingVectorIndexes(ctx context.Context, md5Hash string, force bool) ([]IngestVectorIndex, error) {
@@ -624,58 +945,151 @@ func (s *IngestService) missingVectorIndexes(ctx context.Context, md5Hash string
 	return missing, nil
 }
 
-func (s *IngestService) upsertVectorIndexes(ctx context.Context, indexes []IngestVectorIndex, input vectorUpsertInput) error {
+// encodeLearnedSparse encodes the BM25 document text with the configured sparse
+// encoder. It returns nil when no encoder is configured or the text is empty.
+func (s *IngestService) encodeLearnedSparse(ctx context.Context, text string) (*repository.SparseVector, error) {
+	if s.sparseEncoder == nil || strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	vectors, err := s.sparseEncoder.EncodeSparse(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode learned sparse vector: %w", err)
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("sparse encoder returned no vector")
+	}
+	return &vectors[0], nil
+}
+
+// writtenVector is a Qdrant point written during ingestion together with the
+// meme_vectors record that still has to be persisted for it.
+type writtenVector struct {
+	qdrantRepo      *repository.QdrantRepository
+	record          *domain.MemeVector
+	previousPointID string   // Point referenced by the record being replaced, if any
+	chunkPointIDs   []string // Description chunk points written next to the caption point
+}
+
+// upsertVectorIndexes embeds and writes every target index to Qdrant. The points
+// written so far are returned even on error so the caller can remove them.
+func (s *IngestService) upsertVectorIndexes(ctx context.Context, indexes []IngestVectorIndex, input vectorUpsertInput) ([]writtenVector, error) {
+	written := make([]writtenVector, 0, len(indexes))
 	var errs []error
 	for _, index := range indexes {
-		if err := s.upsertVectorIndex(ctx, index, input); err != nil {
+		vectorType := normalizeIngestVectorType(index.VectorType)
+		vector := writtenVector{qdrantRepo: index.QdrantRepo}
+		if input.Replace && s.vectorRepo != nil {
+			if previous, err := s.vectorRepo.GetByMD5CollectionAndVectorType(ctx, input.MD5Hash, index.Collection, vectorType); err == nil {
+				vector.previousPointID = previous.QdrantPointID
+			}
+		}
+
+		record, err := s.upsertVectorIndex(ctx, index, input)
+		if err != nil {
 			logger.CtxWarn(ctx, "Failed to upsert vector index: meme_id=%s, collection=%s, vector_type=%s, error=%v",
-				input.MemeID, index.Collection, normalizeIngestVectorType(index.VectorType), err)
+				input.MemeID, index.Collection, vectorType, err)
 			errs = append(errs, err)
+			continue
+		}
+		vector.record = record
+		if vectorType == domain.MemeVectorTypeCaption && s.chunking.enabled() {
+			vector.chunkPointIDs, err = s.upsertChunkPoints(ctx, index, input)
+			if err != nil {
+				logger.CtxWarn(ctx, "Failed to upsert description chunks: meme_id=%s, collection=%s, error=%v",
+					input.MemeID, index.Collection, err)
+				errs = append(errs, err)
+			}
 		}
+		written = append(written, vector)
 	}
-	return errors.Join(errs...)
+	return written, errors.Join(errs...)
 }
 
-func (s *IngestService) rollbackVectorIndexes(ctx context.Context, memeID string, indexes []IngestVectorIndex) {
-	if memeID == "" || s.vectorRepo == nil {
-		return
+// rollbackVectorPoints deletes Qdrant points whose SQL records were never committed.
+// Points that overwrote an already-recorded point keep existing so the old record
+// does not dangle. Chunk points have no record of their own and are always deleted.
+func (s *IngestService) rollbackVectorPoints(ctx context.Context, written []writtenVector) {
+	for _, vector := range written {
+		if delErr := vector.qdrantRepo.DeleteBatch(ctx, vector.chunkPointIDs); delErr != nil {
+			logger.CtxError(ctx, "Failed to rollback Qdrant chunk points: point_ids=%v, error=%v", vector.chunkPointIDs, delErr)
+		}
+		if vector.record.QdrantPointID == vector.previousPointID {
+			continue
+		}
+		if delErr := vector.qdrantRepo.Delete(ctx, vector.record.QdrantPointID); delErr != nil {
+			logger.CtxError(ctx, "Failed to rollback Qdrant point: point_id=%s, error=%v", vector.record.QdrantPointID, delErr)
+		}
 	}
+}
 
-	vectors, err := s.vectorRepo.GetByMemeID(ctx, memeID)
-	if err != nil {
-		logger.CtxError(ctx, "Failed to load vector records for rollback: meme_id=%s, error=%v", memeID, err)
-		return
+// deleteReplacedPoints removes points from before deterministic IDs once the
+// records pointing at them have been replaced.
+func (s *IngestService) deleteReplacedPoints(ctx context.Context, written []writtenVector) {
+	for _, vector := range written {
+		if vector.previousPointID == "" || vector.previousPointID == vector.record.QdrantPointID {
+			continue
+		}
+		if delErr := vector.qdrantRepo.Delete(ctx, vector.previousPointID); delErr != nil {
+			logger.CtxWarn(ctx, "Failed to delete replaced Qdrant point: point_id=%s, error=%v", vector.previousPointID, delErr)
+		}
 	}
+}
 
-	reposByRoute := make(map[string]*repository.QdrantRepository, len(indexes))
-	for _, index := range indexes {
-		vectorType := normalizeIngestVectorType(index.VectorType)
-		reposByRoute[vectorRouteKey(index.Collection, vectorType)] = index.QdrantRepo
+// withTx runs fn in a single database transaction when a unit of work is
+// configured, otherwise against the plain repositories.
+func (s *IngestService) withTx(ctx context.Context, fn func(repos *repository.TxRepositories) error) error {
+	if s.uow != nil {
+		return s.uow.Do(ctx, fn)
 	}
+	return fn(&repository.TxRepositories{
+		Memes:        s.memeRepo,
+		Vectors:      s.vectorRepo,
+		Descriptions: s.descRepo,
+	})
+}
 
-	for _, vector := range vectors {
-		routeKey := vectorRouteKey(vector.Collection, normalizeIngestVectorType(vector.VectorType))
-		qdrantRepo, ok := reposByRoute[routeKey]
-		if !ok {
-			continue
-		}
-		if qdrantRepo != nil {
-			if delErr := qdrantRepo.Delete(ctx, vector.QdrantPointID); delErr != nil {
-				logger.CtxError(ctx, "Failed to rollback Qdrant point: point_id=%s, error=%v", vector.QdrantPointID, delErr)
-			}
-		}
-		if delErr := s.vectorRepo.Delete(ctx, vector.ID); delErr != nil {
-			logger.CtxError(ctx, "Failed to rollback vector record: vector_id=%s, error=%v", vector.ID, delErr)
+// saveBM25Text records the BM25 text on a reused description when it differs
+// from what the sparse vectors were last built from. New descriptions carry it
+// on insert.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - repos: repositories of the ingest transaction.
+//   - newDesc: description created by this ingest, or nil when one is reused.
+//   - descriptionID: ID of the reused description.
+//   - stored: BM25 text stored on the reused description.
+//   - bm25Text: BM25 text the sparse vectors were built from.
+//
+// Returns:
+//   - error: non-nil if the description cannot be updated.
+func saveBM25Text(ctx context.Context, repos *repository.TxRepositories, newDesc *domain.MemeDescription, descriptionID, stored, bm25Text string) error {
+	if newDesc != nil || descriptionID == "" || repos.Descriptions == nil || stored == bm25Text {
+		return nil
+	}
+	if err := repos.Descriptions.UpdateBM25Text(ctx, descriptionID, bm25Text); err != nil {
+		return fmt.Errorf("failed to save BM25 text: %w", err)
+	}
+	return nil
+}
+
+// saveVectorRecords persists the meme_vectors rows for points already written to Qdrant.
+func saveVectorRecords(ctx context.Context, repos *repository.TxRepositories, written []writtenVector) error {
+	if repos.Vectors == nil {
+		return nil
+	}
+	for _, vector := range written {
+		if err := repos.Vectors.Upsert(ctx, vector.record); err != nil {
+			return fmt.Errorf("failed to save vector record: %w", err)
 		}
 	}
+	return nil
 }
 
-func (s *IngestService) upsertVectorIndex(ctx context.Context, index IngestVectorIndex, input vectorUpsertInput) error {
+func (s *IngestService) upsertVectorIndex(ctx context.Context, index IngestVectorIndex, input vectorUpsertInput) (*domain.MemeVector, error) {
 	if index.Embedding == nil {
-		return fmt.Errorf("embedding provider is nil for collection %s", index.Collection)
+		return nil, fmt.Errorf("embedding provider is nil for collection %s", index.Collection)
 	}
 	if index.QdrantRepo == nil {
-		return fmt.Errorf("qdrant repo is nil for collection %s", index.Collection)
+		return nil, fmt.Errorf("qdrant repo is nil for collection %s", index.Collection)
 	}
 
 	vectorType := normalizeIngestVectorType(index.VectorType)
@@ -684,41 +1098,41 @@ func (s *IngestService) upsertVectorIndex(ctx context.Context, index IngestVecto
 	switch vectorType {
 	case domain.MemeVectorTypeCaption:
 		if input.CaptionText == "" {
-			return fmt.Errorf("caption vector requires caption text")
+			return nil, fmt.Errorf("caption vector requires caption text")
 		}
 		doc.Text = input.CaptionText
 		inputHash = calculateSHA256(input.CaptionText)
 	case domain.MemeVectorTypeImage:
 		if input.ImageURL == "" {
-			return fmt.Errorf("image vector requires image url")
+			return nil, fmt.Errorf("image vector requires image url")
 		}
 		doc.ImageURL = input.ImageURL
 		doc.ImageData = input.ImageData
 		doc.ImageMediaType = input.ImageMediaType
 	default:
-		return fmt.Errorf("unsupported vector type: %s", vectorType)
+		return nil, fmt.Errorf("unsupported vector type: %s", vectorType)
 	}
 
 	embedding, err := index.Embedding.EmbedDocument(ctx, doc)
 	if err != nil {
-		return fmt.Errorf("failed to generate %s embedding: %w", vectorType, err)
+		return nil, fmt.Errorf("failed to generate %s embedding: %w", vectorType, err)
 	}
 
-	pointID := uuid.New().String()
+	pointID := generateDeterministicPointID(input.MD5Hash, index.Collection, vectorType)
 	if index.UseSparse {
-		if err := index.QdrantRepo.UpsertHybrid(ctx, pointID, embedding, input.BM25Text, input.Payload); err != nil {
-			return fmt.Errorf("failed to upsert hybrid vector: %w", err)
+		learned, err := s.encodeLearnedSparse(ctx, input.BM25Text)
+		if err != nil {
+			return nil, err
+		}
+		if err := index.QdrantRepo.UpsertHybridLearned(ctx, pointID, embedding, input.BM25Text, learned, input.Payload); err != nil {
+			return nil, fmt.Errorf("failed to upsert hybrid vector: %w", err)
 		}
 	} else {
 		if err := index.QdrantRepo.Upsert(ctx, pointID, embedding, input.Payload); err != nil {
-			return fmt.Errorf("failed to upsert dense vector: %w", err)
+			return nil, fmt.Errorf("failed to upsert dense vector: %w", err)
 		}
 	}
 
-	if s.vectorRepo == nil {
-		return nil
-	}
-
 	vectorRecord := &domain.MemeVector{
 		ID:                uuid.New().String(),
 		MemeID:            input.MemeID,
@@ -740,18 +1154,18 @@ func (s *IngestService) upsertVectorIndex(ctx context.Context, index IngestVecto
 		vectorRecord.Dimension = index.Embedding.GetDimensions()
 	}
 
-	if err := s.vectorRepo.Create(ctx, vectorRecord); err != nil {
-		if delErr := index.QdrantRepo.Delete(ctx, pointID); delErr != nil {
-			logger.CtxError(ctx, "Failed to rollback Qdrant upsert: point_id=%s, error=%v", pointID, delErr)
-		}
-		return fmt.Errorf("failed to save vector record: %w", err)
-	}
-
-	return nil
+	return vectorRecord, nil
 }
 
-func vectorRouteKey(collection, vectorType string) string {
-	return collection + "\x00" + vectorType
+// pointIDNamespace seeds the name-based UUIDs used as Qdrant point IDs.
+var pointIDNamespace = uuid.MustParse("6f1c2e8a-4b7d-5e90-a3c1-9d2f7b8e4a60")
+
+// generateDeterministicPointID derives a stable point ID from the image hash and
+// its vector route, so re-ingesting the same image overwrites its point instead
+// of adding a duplicate. The vector type keeps image and caption points apart
+// when they share a collection.
+func generateDeterministicPointID(md5Hash, collection, vectorType string) string {
+	return uuid.NewSHA1(pointIDNamespace, []byte(md5Hash+"\x00"+collection+"\x00"+vectorType)).String()
 }
 
 func normalizeIngestVectorType(vectorType string) string {
@@ -777,12 +1191,25 @@ func normalizeEmbeddingMode(mode string) string {
 	return domain.MemeVectorEmbeddingModeIndependent
 }
 
-func (s *IngestService) readImage(item *source.MemeItem) ([]byte, error) {
+func (s *IngestService) readImage(ctx context.Context, item *source.MemeItem) ([]byte, error) {
+	if item.Data != nil {
+		return item.Data, nil
+	}
 	if item.LocalPath != "" {
 		return os.ReadFile(item.LocalPath)
 	}
-	// TODO: Implement HTTP download for URL-based sources
-	return nil, fmt.Errorf("URL-based sources not implemented yet")
+	if isRemoteURL(item.URL) {
+		return s.fetchRemoteImage(ctx, item.URL)
+	}
+	return nil, fmt.Errorf("item has neither a local path nor a remote URL")
+}
+
+// remoteURL returns the item's origin URL when it was downloaded from one.
+func remoteURL(item *source.MemeItem) string {
+	if item.LocalPath == "" && isRemoteURL(item.URL) {
+		return item.URL
+	}
+	return ""
 }
 
 func calculateMD5(data []byte) string {
@@ -811,6 +1238,14 @@ func getContentType(format string) string {
 		return "image/png"
 	case "webp":
 		return "image/webp"
+	case "heic":
+		return "image/heic"
+	case "avif":
+		return "image/avif"
+	case "mp4":
+		return "video/mp4"
+	case "webm":
+		return "video/webm"
 	default:
 		return "application/octet-stream"
 	}
@@ -831,6 +1266,39 @@ func shouldConvertStaticImageToJPEG(format string) bool {
 	return format == "webp"
 }
 
+// isConvertibleStillFormat reports whether a still format needs the media
+// converter before it can be decoded.
+func isConvertibleStillFormat(format string) bool {
+	return format == "heic" || format == "avif"
+}
+
+// isClipFormat reports whether a format is a short video sticker clip.
+func isClipFormat(format string) bool {
+	return format == "mp4" || format == "webm"
+}
+
+// stillObject returns the storage key and format of the still image for a meme:
+// the meme object itself, or its poster frame for animated memes.
+func stillObject(meme *domain.Meme) (string, string) {
+	if meme.PosterKey != "" {
+		return meme.PosterKey, "jpeg"
+	}
+	return meme.StorageKey, meme.Format
+}
+
+// posterURLFor returns the poster URL of an animated meme, or "" for stills.
+func posterURLFor(objectStorage storage.ObjectStorage, meme *domain.Meme) string {
+	if meme.PosterKey == "" {
+		return ""
+	}
+	return objectStorage.GetURL(meme.PosterKey)
+}
+
+// posterStorageKey returns the storage key of the JPEG poster frame stored next to a clip.
+func posterStorageKey(md5Hash string) string {
+	return fmt.Sprintf("%s/%s_poster.jpeg", md5Hash[:2], md5Hash)
+}
+
 // detectImageFormat detects the actual image format by examining magic bytes.
 // This is more reliable than trusting file extensions.
 func detectImageFormat(data []byte) string {
@@ -884,15 +1352,50 @@ func detectImageFormat(data []byte) string {
 		return "ico"
 	}
 
-	// AVIF: starts with ftypavif (at offset 4-11)
-	if len(data) >= 12 && data[4] == 0x66 && data[5] == 0x74 && data[6] == 0x79 && data[7] == 0x70 && // "ftyp"
-		data[8] == 0x61 && data[9] == 0x76 && data[10] == 0x69 && data[11] == 0x66 { // "avif"
-		return "avif"
+	// ISO BMFF (HEIC, AVIF, MP4): "ftyp" box at offset 4 with the major brand at offset 8
+	if data[4] == 0x66 && data[5] == 0x74 && data[6] == 0x79 && data[7] == 0x70 { // "ftyp"
+		if format := isoBMFFFormat(data); format != "" {
+			return format
+		}
+	}
+
+	// WebM/Matroska: EBML header 1A 45 DF A3
+	if data[0] == 0x1A && data[1] == 0x45 && data[2] == 0xDF && data[3] == 0xA3 {
+		return "webm"
 	}
 
 	return "unknown"
 }
 
+// isoBMFFFormat maps the brands of an ISO BMFF ftyp box to a format name.
+// Compatible brands are checked too because AVIF files often carry a generic
+// major brand such as "mif1".
+func isoBMFFFormat(data []byte) string {
+	boxSize := int(binary.BigEndian.Uint32(data[0:4]))
+	if boxSize < 16 || boxSize > len(data) {
+		boxSize = 16
+	}
+	brands := []string{string(data[8:12])}
+	for off := 16; off+4 <= boxSize; off += 4 {
+		brands = append(brands, string(data[off:off+4]))
+	}
+
+	for _, brand := range brands {
+		if brand == "avif" || brand == "avis" {
+			return "avif"
+		}
+	}
+	for _, brand := range brands {
+		switch brand {
+		case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+			return "heic"
+		case "isom", "iso2", "iso4", "iso5", "iso6", "mp41", "mp42", "avc1", "M4V ", "dash":
+			return "mp4"
+		}
+	}
+	return ""
+}
+
 // convertToJPEG converts a supported static image to JPEG.
 func convertToJPEG(imageData []byte, format string) ([]byte, error) {
 	reader := bytes.NewReader(imageData)
@@ -931,10 +1434,8 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 	stats.TotalItems = int64(len(memes))
 
 	for _, meme := range memes {
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			break
-		default:
 		}
 
 		targetIndexes, err := s.missingVectorIndexes(ctx, meme.MD5Hash, false)
@@ -955,8 +1456,9 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 			continue
 		}
 
-		// Download from storage
-		reader, err := s.storage.Download(ctx, meme.StorageKey)
+		// Download the still used for description and image embedding
+		stillKey, stillFormat := stillObject(&meme)
+		reader, err := s.storage.Download(ctx, stillKey)
 		if err != nil {
 			logger.CtxError(ctx, "Failed to download from storage: error=%v", err)
 			stats.FailedItems++
@@ -974,7 +1476,10 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 		// Get or create VLM description for current VLM model
 		var description string
 		var ocrText string
+		var sceneTags []string
+		var storedBM25Text string
 		var descriptionID string
+		var newDescription *domain.MemeDescription
 		if s.descRepo != nil {
 			existingDesc, err := s.descRepo.GetByMD5AndModel(ctx, meme.MD5Hash, s.vlm.GetModel())
 			if err == nil && existingDesc != nil {
@@ -982,8 +1487,10 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 				description = existingDesc.Description
 				descriptionID = existingDesc.ID
 				ocrText = normalizeOCRText(existingDesc.OCRText)
+				sceneTags = existingDesc.SceneTags
+				storedBM25Text = existingDesc.BM25Text
 				if ocrText == "" {
-					ocrText, err = s.extractOCRText(ctx, imageData, meme.Format)
+					ocrText, err = s.extractOCRText(ctx, imageData, stillFormat)
 					if err != nil {
 						logger.CtxWarn(ctx, "Failed to extract OCR text: meme_id=%s, error=%v", meme.ID, err)
 					} else if ocrText != "" {
@@ -995,57 +1502,55 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 				logger.CtxDebug(ctx, "Reusing existing VLM description: md5=%s, vlm_model=%s", meme.MD5Hash, s.vlm.GetModel())
 			} else {
 				// Generate new VLM description
-				description, err = s.vlm.DescribeImage(ctx, imageData, meme.Format)
+				var quality *DescriptionQuality
+				var promptVersion string
+				description, ocrText, quality, promptVersion, err = s.describeImageOrTags(ctx, imageData, stillFormat, fallbackSubject{
+					Path:     meme.LocalPath,
+					SourceID: meme.SourceID,
+					Category: meme.Category,
+					Tags:     meme.Tags,
+				})
 				if err != nil {
-					logger.CtxWarn(ctx, "Failed to generate VLM description: error=%v", err)
+					logger.CtxWarn(ctx, "Failed to describe meme: meme_id=%s, error=%v", meme.ID, err)
 					stats.FailedItems++
 					continue
 				}
 
-				ocrText, err = s.extractOCRText(ctx, imageData, meme.Format)
-				if err != nil {
-					logger.CtxWarn(ctx, "Failed to extract OCR text: meme_id=%s, error=%v", meme.ID, err)
-					ocrText = ""
-				}
-
-				// Save description to meme_descriptions table
+				// Saved to meme_descriptions together with the vector records
 				descRecord := &domain.MemeDescription{
-					ID:          uuid.New().String(),
-					MemeID:      meme.ID,
-					MD5Hash:     meme.MD5Hash,
-					VLMModel:    s.vlm.GetModel(),
-					Description: description,
-					OCRText:     ocrText,
-					CreatedAt:   time.Now(),
+					ID:            uuid.New().String(),
+					MemeID:        meme.ID,
+					MD5Hash:       meme.MD5Hash,
+					VLMModel:      s.vlm.GetModel(),
+					Description:   description,
+					PromptVersion: promptVersion,
+					OCRText:       ocrText,
+					CreatedAt:     time.Now(),
 				}
-				if err := s.descRepo.Create(ctx, descRecord); err != nil {
-					logger.CtxError(ctx, "Failed to save VLM description: meme_id=%s, error=%v", meme.ID, err)
-					stats.FailedItems++
-					continue
+				if quality != nil {
+					s.applyDescriptionQuality(descRecord, *quality)
 				}
+				newDescription = descRecord
 				descriptionID = descRecord.ID
-				logger.CtxDebug(ctx, "Created new VLM description: md5=%s, vlm_model=%s, description_id=%s",
-					meme.MD5Hash, s.vlm.GetModel(), descriptionID)
 			}
 		} else {
 			// Fallback: generate VLM description without storing to database
 			var err error
-			description, err = s.vlm.DescribeImage(ctx, imageData, meme.Format)
+			description, ocrText, _, err = s.describeImage(ctx, imageData, stillFormat)
 			if err != nil {
-				logger.CtxWarn(ctx, "Failed to generate VLM description: error=%v", err)
+				logger.CtxWarn(ctx, "Failed to describe meme: meme_id=%s, error=%v", meme.ID, err)
 				stats.FailedItems++
 				continue
 			}
+		}
 
-			ocrText, err = s.extractOCRText(ctx, imageData, meme.Format)
-			if err != nil {
-				logger.CtxWarn(ctx, "Failed to extract OCR text: meme_id=%s, error=%v", meme.ID, err)
-				ocrText = ""
-			}
+		if len(sceneTags) == 0 {
+			sceneTags = s.tagScenes(ctx, description, ocrText, newDescription, descriptionID)
 		}
 
-		compactDesc := compactDescription(description)
-		captionText := buildCaptionEmbeddingText(
+		cleanedDesc := s.cleaner.Clean(description)
+		compactDesc := compactDescription(cleanedDesc)
+		captionText := s.textWeights.CaptionText(
 			ocrText,
 			compactDesc,
 			meme.Category,
@@ -1053,7 +1558,10 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 			extractEmotionWords(description),
 		)
 		bm25Text := buildBM25Text(ocrText, compactDesc, meme.Tags)
-		imageURL := s.storage.GetURL(meme.StorageKey)
+		if newDescription != nil {
+			newDescription.BM25Text = bm25Text
+		}
+		imageURL := s.storage.GetURL(stillKey)
 		payload := &repository.MemePayload{
 			MemeID:         meme.ID,
 			SourceType:     meme.SourceType,
@@ -1061,20 +1569,28 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 			Tags:           meme.Tags,
 			VLMDescription: description,
 			OCRText:        ocrText,
-			StorageURL:     imageURL,
+			StorageURL:     s.storage.GetURL(meme.StorageKey),
+			PosterURL:      posterURLFor(s.storage, &meme),
+			Colors:         paletteColorNames(meme.DominantColors),
+			Saturation:     meme.Saturation,
+			TextLang:       detectTextLanguage(ocrText),
+			SceneTags:      sceneTags,
 		}
 
-		if err := s.upsertVectorIndexes(ctx, targetIndexes, vectorUpsertInput{
+		written, err := s.upsertVectorIndexes(ctx, targetIndexes, vectorUpsertInput{
 			MemeID:         meme.ID,
 			MD5Hash:        meme.MD5Hash,
 			DescriptionID:  descriptionID,
 			ImageURL:       imageURL,
 			ImageData:      imageData,
-			ImageMediaType: getContentType(meme.Format),
+			ImageMediaType: getContentType(stillFormat),
 			CaptionText:    captionText,
 			BM25Text:       bm25Text,
+			Chunks:         s.chunking.split(cleanedDesc),
 			Payload:        payload,
-		}); err != nil {
+		})
+		if err != nil {
+			s.rollbackVectorPoints(ctx, written)
 			logger.CtxError(ctx, "Failed to upsert vector indexes: meme_id=%s, error=%v", meme.ID, err)
 			stats.FailedItems++
 			continue
@@ -1084,7 +1600,21 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 		meme.Status = domain.MemeStatusActive
 		meme.UpdatedAt = time.Now()
 
-		if err := s.memeRepo.Update(ctx, &meme); err != nil {
+		if err := s.withTx(ctx, func(repos *repository.TxRepositories) error {
+			if newDescription != nil {
+				if err := repos.Descriptions.Create(ctx, newDescription); err != nil {
+					return fmt.Errorf("failed to save VLM description: %w", err)
+				}
+			}
+			if err := saveBM25Text(ctx, repos, newDescription, descriptionID, storedBM25Text, bm25Text); err != nil {
+				return err
+			}
+			if err := saveVectorRecords(ctx, repos, written); err != nil {
+				return err
+			}
+			return repos.Memes.Update(ctx, &meme)
+		}); err != nil {
+			s.rollbackVectorPoints(ctx, written)
 			logger.CtxError(ctx, "Failed to update database: error=%v", err)
 			stats.FailedItems++
 			continue
@@ -1097,5 +1627,9 @@ func (s *IngestService) RetryPending(ctx context.Context, limit int) (*IngestSta
 	}
 
 	stats.EndTime = time.Now()
+	if stats.ProcessedItems > 0 {
+		// Retried memes become active and appear in lists
+		s.purgeCache(ctx, []string{SurrogateKeyMemes, SurrogateKeyCategories})
+	}
 	return stats, nil
 }
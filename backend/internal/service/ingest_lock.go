@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/timmy/emomo/internal/cache"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// ErrSourceLocked is returned when another process is ingesting the source.
+var ErrSourceLocked = errors.New("source is being ingested by another process")
+
+// sourceLockTTL is how long a source stays locked after its holder stops
+// without releasing it; live holders keep extending the lock.
+const sourceLockTTL = time.Minute
+
+// heldSourceLock is a source lock this process holds and the number of its
+// runs sharing it.
+type heldSourceLock struct {
+	release func()
+	runs    int
+}
+
+// SetSourceLocker makes ingest runs take a per-source lock, so API replicas
+// and ingest CLI runs sharing the locker never ingest one source at once.
+// Runs of one process share the lock: how many of them may ingest a source
+// at once is up to the job limits of the caller.
+// Parameters:
+//   - locker: shared locker such as cache.Redis (nil disables the lock).
+//
+// Returns: none.
+func (s *IngestService) SetSourceLocker(locker cache.Locker) {
+	s.sourceLocks = locker
+}
+
+// lockSource takes the ingest lock of a source, or joins it when another run
+// of this process holds it. When another process holds it, a queued job is
+// marked failed, since it will never run.
+func (s *IngestService) lockSource(ctx context.Context, sourceID, queuedJobID string) (func(), error) {
+	if s.sourceLocks == nil {
+		return func() {}, nil
+	}
+	s.sourceLockMu.Lock()
+	defer s.sourceLockMu.Unlock()
+	if held := s.heldSources[sourceID]; held != nil {
+		held.runs++
+		return s.sourceUnlocker(sourceID), nil
+	}
+
+	release, err := s.sourceLocks.Lock(ctx, "lock:ingest:"+sourceID, sourceLockTTL)
+	if errors.Is(err, cache.ErrLocked) {
+		err = ErrSourceLocked
+	} else if err != nil {
+		err = fmt.Errorf("failed to lock source: %w", err)
+	}
+	if err == nil {
+		if s.heldSources == nil {
+			s.heldSources = make(map[string]*heldSourceLock)
+		}
+		s.heldSources[sourceID] = &heldSourceLock{release: release, runs: 1}
+		return s.sourceUnlocker(sourceID), nil
+	}
+
+	logger.CtxWarn(ctx, "Ingest not started: source=%s, error=%v", sourceID, err)
+	if queuedJobID != "" && s.jobRepo != nil {
+		if markErr := s.jobRepo.MarkFailed(context.WithoutCancel(ctx), queuedJobID, err.Error()); markErr != nil {
+			logger.CtxWarn(ctx, "Failed to mark ingest job failed: job_id=%s, error=%v", queuedJobID, markErr)
+		}
+	}
+	return nil, err
+}
+
+// sourceUnlocker returns the release of one run's share of a source lock; the
+// last run releases the lock itself.
+func (s *IngestService) sourceUnlocker(sourceID string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.sourceLockMu.Lock()
+			defer s.sourceLockMu.Unlock()
+			held := s.heldSources[sourceID]
+			if held.runs--; held.runs == 0 {
+				delete(s.heldSources, sourceID)
+				held.release()
+			}
+		})
+	}
+}
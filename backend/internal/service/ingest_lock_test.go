@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timmy/emomo/internal/cache"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestIngestFromSourceSkipsLockedSource(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	locker := cache.NewLocalLocker()
+	ingest := &IngestService{}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+	ingest.SetSourceLocker(locker)
+
+	// Another replica holds the source
+	release, err := locker.Lock(ctx, "lock:ingest:scripted", sourceLockTTL)
+	if err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer release()
+
+	job, err := ingest.QueueIngestJob(ctx, "scripted")
+	if err != nil {
+		t.Fatalf("QueueIngestJob() error = %v", err)
+	}
+	if _, err := ingest.IngestFromSource(ctx, &scriptedSource{}, 10, &IngestOptions{JobID: job.ID}); !errors.Is(err, ErrSourceLocked) {
+		t.Fatalf("IngestFromSource() error = %v, want ErrSourceLocked", err)
+	}
+	stored, err := ingest.GetIngestJob(ctx, job.ID)
+	if err != nil || stored.Status != domain.JobStatusFailed || stored.ErrorLog != ErrSourceLocked.Error() {
+		t.Fatalf("queued job = %+v, %v, want it failed with the lock error", stored, err)
+	}
+}
+
+func TestLockSourceSharesLockWithinProcess(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	locker := cache.NewLocalLocker()
+	ingest := &IngestService{}
+	ingest.SetSourceLocker(locker)
+
+	// Two jobs admitted for one source (ingest.jobs.per_source: 2)
+	first, err := ingest.lockSource(ctx, "scripted", "")
+	if err != nil {
+		t.Fatalf("lockSource() error = %v", err)
+	}
+	second, err := ingest.lockSource(ctx, "scripted", "")
+	if err != nil {
+		t.Fatalf("second lockSource() error = %v, want the lock shared", err)
+	}
+
+	first()
+	first()
+	if _, err := locker.Lock(ctx, "lock:ingest:scripted", sourceLockTTL); !errors.Is(err, cache.ErrLocked) {
+		t.Fatalf("Lock() with a run left error = %v, want ErrLocked", err)
+	}
+	second()
+	release, err := locker.Lock(ctx, "lock:ingest:scripted", sourceLockTTL)
+	if err != nil {
+		t.Fatalf("Lock() after the last run error = %v, want the lock released", err)
+	}
+	release()
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+// itemQueue is a bounded work queue that hands out the highest-priority item
+// first and keeps fetch order among equal priorities. Producers block while it
+// is full, consumers while it is empty.
+type itemQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    queuedItems
+	capacity int
+	seq      int64
+	closed   bool
+}
+
+type queuedItem struct {
+	item source.MemeItem
+	seq  int64
+}
+
+// queuedItems implements heap.Interface ordered by priority, then fetch order.
+type queuedItems []queuedItem
+
+func (q queuedItems) Len() int { return len(q) }
+func (q queuedItems) Less(i, j int) bool {
+	if q[i].item.Priority != q[j].item.Priority {
+		return q[i].item.Priority > q[j].item.Priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q queuedItems) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *queuedItems) Push(x any)   { *q = append(*q, x.(queuedItem)) }
+func (q *queuedItems) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// newItemQueue creates a queue holding up to capacity items. Waiters wake up
+// when ctx is canceled.
+func newItemQueue(ctx context.Context, capacity int) *itemQueue {
+	q := &itemQueue{capacity: max(capacity, 1)}
+	q.cond = sync.NewCond(&q.mu)
+	context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	return q
+}
+
+// push adds an item, waiting for room. It returns ctx.Err() if ctx is canceled first.
+func (q *itemQueue) push(ctx context.Context, item source.MemeItem) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) >= q.capacity && ctx.Err() == nil {
+		q.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	heap.Push(&q.items, queuedItem{item: item, seq: q.seq})
+	q.seq++
+	q.cond.Broadcast()
+	return nil
+}
+
+// pop removes the highest-priority item, waiting for one. It returns false once
+// the queue is closed and drained, or when ctx is canceled.
+func (q *itemQueue) pop(ctx context.Context) (source.MemeItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed && ctx.Err() == nil {
+		q.cond.Wait()
+	}
+	if ctx.Err() != nil || len(q.items) == 0 {
+		return source.MemeItem{}, false
+	}
+	item := heap.Pop(&q.items).(queuedItem).item
+	q.cond.Broadcast()
+	return item, true
+}
+
+// close marks the end of input; queued items are still handed out.
+func (q *itemQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
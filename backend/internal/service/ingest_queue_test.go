@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+func TestItemQueuePopsHighestPriorityFirst(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queue := newItemQueue(ctx, 10)
+	for _, item := range []source.MemeItem{
+		{SourceID: "backfill-1"},
+		{SourceID: "trending-1", Priority: 10},
+		{SourceID: "backfill-2"},
+		{SourceID: "new-1", Priority: 5},
+		{SourceID: "trending-2", Priority: 10},
+	} {
+		if err := queue.push(ctx, item); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+	queue.close()
+
+	var order []string
+	for {
+		item, ok := queue.pop(ctx)
+		if !ok {
+			break
+		}
+		order = append(order, item.SourceID)
+	}
+	want := []string{"trending-1", "trending-2", "new-1", "backfill-1", "backfill-2"}
+	if len(order) != len(want) {
+		t.Fatalf("pop order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("pop order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestItemQueueUnblocksOnCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := newItemQueue(ctx, 1)
+	if err := queue.push(ctx, source.MemeItem{SourceID: "1"}); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+
+	pushed := make(chan error, 1)
+	go func() { pushed <- queue.push(ctx, source.MemeItem{SourceID: "2"}) }()
+	cancel()
+
+	select {
+	case err := <-pushed:
+		if err == nil {
+			t.Fatal("push() on full queue error = nil, want context.Canceled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("push() did not return after cancellation")
+	}
+	if _, ok := queue.pop(ctx); ok {
+		t.Fatal("pop() after cancellation = ok, want false")
+	}
+}
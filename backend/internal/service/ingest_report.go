@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// maxReportExamples caps the source IDs listed per skip or failure reason.
+const maxReportExamples = 5
+
+// ErrIngestJobNotFound is returned when an ingest job or its report does not exist.
+var ErrIngestJobNotFound = errors.New("ingest job not found")
+
+// IngestReport summarizes what an ingest run changed.
+type IngestReport struct {
+	JobID       string           `json:"job_id"`
+	SourceID    string           `json:"source_id"`
+	Status      domain.JobStatus `json:"status"`
+	StartedAt   time.Time        `json:"started_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Error       string           `json:"error,omitempty"`
+
+	Total       int64 `json:"total"`
+	New         int64 `json:"new"`    // Memes created by the run
+	Reused      int64 `json:"reused"` // Existing memes that only gained missing vectors
+	Skipped     int64 `json:"skipped"`
+	Quarantined int64 `json:"quarantined"`
+	Failed      int64 `json:"failed"`
+
+	ChecksumMismatches int64 `json:"checksum_mismatches"` // Quarantined items that differ from their manifest sha256
+
+	Categories  []CategoryReport `json:"categories"`   // Additions per category, most new memes first
+	SkipReasons []ReasonCount    `json:"skip_reasons"` // Skipped items by reason
+	Failures    []ReasonCount    `json:"failures"`     // Failed items by reason
+}
+
+// CategoryReport counts the memes a run added to one category.
+type CategoryReport struct {
+	Category string `json:"category"`
+	New      int64  `json:"new"`
+	Reused   int64  `json:"reused"`
+}
+
+// ReasonCount counts the items skipped or failed for one reason.
+type ReasonCount struct {
+	Reason   string   `json:"reason"`
+	Count    int64    `json:"count"`
+	Examples []string `json:"examples,omitempty"` // Source IDs of the first affected items
+}
+
+// WriteCSV writes the report as rows of section, name, new, reused and count.
+// Parameters:
+//   - w: destination for the CSV data.
+//
+// Returns:
+//   - error: non-nil if writing fails.
+func (r *IngestReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	count := func(n int64) string { return strconv.FormatInt(n, 10) }
+
+	rows := [][]string{
+		{"section", "name", "new", "reused", "count"},
+		{"summary", "total", "", "", count(r.Total)},
+		{"summary", "new", "", "", count(r.New)},
+		{"summary", "reused", "", "", count(r.Reused)},
+		{"summary", "skipped", "", "", count(r.Skipped)},
+		{"summary", "quarantined", "", "", count(r.Quarantined)},
+		{"summary", "checksum_mismatches", "", "", count(r.ChecksumMismatches)},
+		{"summary", "failed", "", "", count(r.Failed)},
+	}
+	for _, category := range r.Categories {
+		rows = append(rows, []string{"category", category.Category, count(category.New), count(category.Reused), count(category.New + category.Reused)})
+	}
+	for _, reason := range r.SkipReasons {
+		rows = append(rows, []string{"skip", reason.Reason, "", "", count(reason.Count)})
+	}
+	for _, reason := range r.Failures {
+		rows = append(rows, []string{"failure", reason.Reason, "", "", count(reason.Count)})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write report CSV: %w", err)
+	}
+	return nil
+}
+
+// reportCollector gathers per-item outcomes while workers run.
+type reportCollector struct {
+	mu         sync.Mutex
+	report     IngestReport
+	categories map[string]*CategoryReport
+	skips      map[string]*ReasonCount
+	failures   map[string]*ReasonCount
+}
+
+func newReportCollector(jobID, sourceID string, startedAt time.Time) *reportCollector {
+	return &reportCollector{
+		report:     IngestReport{JobID: jobID, SourceID: sourceID, StartedAt: startedAt},
+		categories: make(map[string]*CategoryReport),
+		skips:      make(map[string]*ReasonCount),
+		failures:   make(map[string]*ReasonCount),
+	}
+}
+
+// record adds one processed item. Quarantined items only appear in the
+// summary, with checksum mismatches counted apart; their reasons are kept in
+// the quarantine list.
+func (c *reportCollector) record(result *processResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case result.quarantined:
+		if errors.Is(result.err, errChecksumMismatch) {
+			c.report.ChecksumMismatches++
+		}
+	case result.skipped:
+		addReason(c.skips, reportReason(result.err), result.sourceID)
+	case result.err != nil:
+		addReason(c.failures, reportReason(result.err), result.sourceID)
+	default:
+		category := c.categories[result.category]
+		if category == nil {
+			category = &CategoryReport{Category: result.category}
+			c.categories[result.category] = category
+		}
+		if result.reused {
+			c.report.Reused++
+			category.Reused++
+		} else {
+			c.report.New++
+			category.New++
+		}
+	}
+}
+
+// finish completes the report with the run totals and outcome.
+func (c *reportCollector) finish(stats *IngestStats, runErr error) *IngestReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := c.report
+	report.CompletedAt = stats.EndTime
+	report.Total = stats.TotalItems
+	report.Skipped = stats.SkippedItems
+	report.Quarantined = stats.QuarantinedItems
+	report.Failed = stats.FailedItems
+	report.Status = domain.JobStatusCompleted
+	if runErr != nil {
+		report.Status = domain.JobStatusFailed
+		report.Error = runErr.Error()
+	}
+
+	report.Categories = make([]CategoryReport, 0, len(c.categories))
+	for _, category := range c.categories {
+		report.Categories = append(report.Categories, *category)
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.New != b.New {
+			return a.New > b.New
+		}
+		return a.Category < b.Category
+	})
+	report.SkipReasons = sortedReasons(c.skips)
+	report.Failures = sortedReasons(c.failures)
+	return &report
+}
+
+func addReason(reasons map[string]*ReasonCount, reason, sourceID string) {
+	entry := reasons[reason]
+	if entry == nil {
+		entry = &ReasonCount{Reason: reason}
+		reasons[reason] = entry
+	}
+	entry.Count++
+	if len(entry.Examples) < maxReportExamples {
+		entry.Examples = append(entry.Examples, sourceID)
+	}
+}
+
+func sortedReasons(reasons map[string]*ReasonCount) []ReasonCount {
+	sorted := make([]ReasonCount, 0, len(reasons))
+	for _, reason := range reasons {
+		sorted = append(sorted, *reason)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Reason < sorted[j].Reason
+	})
+	return sorted
+}
+
+// reportReason groups item errors by their outermost message, so errors that
+// only differ in paths or upstream details share a reason.
+func reportReason(err error) string {
+	for _, sentinel := range []error{errSkipDuplicate, errSkipUnsupportedImageFormat, errSkipRule, errSkipOrigin, errSkipNearDuplicate} {
+		if errors.Is(err, sentinel) {
+			return strings.TrimPrefix(sentinel.Error(), "skipped: ")
+		}
+	}
+	msg := err.Error()
+	if i := strings.Index(msg, ": "); i > 0 {
+		msg = msg[:i]
+	}
+	return msg
+}
+
+// SetJobRepository sets the repository used to record ingest runs and their reports.
+// Parameters:
+//   - jobRepo: ingest job repository (nil disables run records).
+//
+// Returns: none.
+func (s *IngestService) SetJobRepository(jobRepo *repository.IngestJobRepository) {
+	s.jobRepo = jobRepo
+}
+
+// jobProgressInterval is how often running ingest jobs store their counts.
+const jobProgressInterval = 2 * time.Second
+
+// QueueIngestJob records a pending ingest job, so a run started later with
+// IngestOptions.JobID can be polled from the moment it is requested.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceID: source the job will ingest.
+//
+// Returns:
+//   - *domain.IngestJob: the pending job.
+//   - error: non-nil if jobs are not recorded or the insert fails.
+func (s *IngestService) QueueIngestJob(ctx context.Context, sourceID string) (*domain.IngestJob, error) {
+	if s.jobRepo == nil {
+		return nil, errors.New("ingest job repository not configured")
+	}
+	job := &domain.IngestJob{
+		ID:       uuid.New().String(),
+		SourceID: sourceID,
+		Kind:     domain.JobKindIngest,
+		Status:   domain.JobStatusPending,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to record ingest job: %w", err)
+	}
+	return job, nil
+}
+
+// GetIngestJob returns an ingest job with its current counts.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - jobID: ingest job identifier.
+//
+// Returns:
+//   - *domain.IngestJob: the job; counts of running jobs are at most a few seconds old.
+//   - error: ErrIngestJobNotFound if the job is unknown.
+func (s *IngestService) GetIngestJob(ctx context.Context, jobID string) (*domain.IngestJob, error) {
+	if s.jobRepo == nil {
+		return nil, errors.New("ingest job repository not configured")
+	}
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIngestJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest job: %w", err)
+	}
+	return job, nil
+}
+
+// interruptedJobError is the error log of ingest jobs that stopped with the
+// process running them.
+const interruptedJobError = "interrupted: the process running the job stopped"
+
+// FailInterruptedJobs marks ingest jobs left pending or running by a stopped
+// process as failed, so job history does not show them as in progress forever.
+// Running jobs store their counts every few seconds, so only jobs not updated
+// for staleAfter are touched; ingest CLI runs in progress are left alone.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - staleAfter: how long a job may go without updates before it counts as interrupted.
+//
+// Returns:
+//   - int: number of jobs marked failed.
+//   - error: non-nil if jobs are not recorded or a query fails.
+func (s *IngestService) FailInterruptedJobs(ctx context.Context, staleAfter time.Duration) (int, error) {
+	if s.jobRepo == nil {
+		return 0, errors.New("ingest job repository not configured")
+	}
+	cutoff := time.Now().Add(-staleAfter)
+	failed := 0
+	for _, status := range []domain.JobStatus{domain.JobStatusPending, domain.JobStatusRunning} {
+		jobs, err := s.jobRepo.ListByStatus(ctx, domain.JobKindIngest, status, cutoff)
+		if err != nil {
+			return failed, fmt.Errorf("failed to list %s ingest jobs: %w", status, err)
+		}
+		for _, job := range jobs {
+			if err := s.jobRepo.MarkFailed(ctx, job.ID, interruptedJobError); err != nil {
+				return failed, fmt.Errorf("failed to mark ingest job failed: %w", err)
+			}
+			logger.CtxWarn(ctx, "Marked interrupted ingest job failed: job_id=%s, source=%s, status=%s",
+				job.ID, job.SourceID, status)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// startJob records a running ingest job, or marks a queued one as running.
+// Failures are logged, not returned, so a broken job table never blocks
+// ingestion.
+func (s *IngestService) startJob(ctx context.Context, jobID, sourceID string, startedAt time.Time, queued bool) *domain.IngestJob {
+	if s.jobRepo == nil {
+		return nil
+	}
+	if queued {
+		job, err := s.jobRepo.GetByID(ctx, jobID)
+		if err == nil {
+			job.Status = domain.JobStatusRunning
+			job.StartedAt = &startedAt
+			err = s.jobRepo.Save(ctx, job)
+		}
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to start queued ingest job: job_id=%s, error=%v", jobID, err)
+			return nil
+		}
+		return job
+	}
+
+	job := &domain.IngestJob{
+		ID:        jobID,
+		SourceID:  sourceID,
+		Kind:      domain.JobKindIngest,
+		Status:    domain.JobStatusRunning,
+		StartedAt: &startedAt,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.CtxWarn(ctx, "Failed to record ingest job: job_id=%s, error=%v", jobID, err)
+		return nil
+	}
+	return job
+}
+
+// trackJobProgress stores the counts of stats on job every
+// jobProgressInterval until the returned stop is called.
+func (s *IngestService) trackJobProgress(ctx context.Context, job *domain.IngestJob, stats *IngestStats) (stop func()) {
+	if job == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(jobProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			err := s.jobRepo.UpdateProgress(ctx, job.ID,
+				int(atomic.LoadInt64(&stats.TotalItems)),
+				int(atomic.LoadInt64(&stats.ProcessedItems)),
+				int(atomic.LoadInt64(&stats.FailedItems)),
+				int(atomic.LoadInt64(&stats.SkippedItems)))
+			if err != nil {
+				logger.CtxWarn(ctx, "Failed to save ingest progress: job_id=%s, error=%v", job.ID, err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// finishJob stores the final counts and report on the job. It runs detached
+// from ctx so canceled runs still record their partial report.
+func (s *IngestService) finishJob(ctx context.Context, job *domain.IngestJob, report *IngestReport) {
+	if job == nil {
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to encode ingest report: job_id=%s, error=%v", job.ID, err)
+		return
+	}
+
+	job.Status = report.Status
+	job.TotalItems = int(report.Total)
+	job.ProcessedItems = int(report.New + report.Reused + report.Skipped + report.Quarantined + report.Failed)
+	job.FailedItems = int(report.Failed)
+	job.SkippedItems = int(report.Skipped)
+	job.CompletedAt = &report.CompletedAt
+	job.ErrorLog = report.Error
+	job.Report = string(data)
+	if err := s.jobRepo.Save(context.WithoutCancel(ctx), job); err != nil {
+		logger.CtxWarn(ctx, "Failed to save ingest report: job_id=%s, error=%v", job.ID, err)
+	}
+}
+
+// ListIngestJobs returns recorded ingest runs, most recent first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of jobs to return.
+//   - offset: number of jobs to skip.
+//
+// Returns:
+//   - []domain.IngestJob: ingest jobs without their reports.
+//   - int64: total number of jobs.
+//   - error: non-nil if jobs are not recorded or the query fails.
+func (s *IngestService) ListIngestJobs(ctx context.Context, limit, offset int) ([]domain.IngestJob, int64, error) {
+	if s.jobRepo == nil {
+		return nil, 0, errors.New("ingest job repository not configured")
+	}
+	jobs, err := s.jobRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list ingest jobs: %w", err)
+	}
+	total, err := s.jobRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count ingest jobs: %w", err)
+	}
+	return jobs, total, nil
+}
+
+// GetIngestReport returns the report stored with an ingest job.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - jobID: ingest job identifier.
+//
+// Returns:
+//   - *IngestReport: the run report.
+//   - error: ErrIngestJobNotFound if the job is unknown, still running or not an ingest run.
+func (s *IngestService) GetIngestReport(ctx context.Context, jobID string) (*IngestReport, error) {
+	if s.jobRepo == nil {
+		return nil, errors.New("ingest job repository not configured")
+	}
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIngestJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest job: %w", err)
+	}
+	if job.Report == "" || (job.Kind != "" && job.Kind != domain.JobKindIngest) {
+		return nil, ErrIngestJobNotFound
+	}
+
+	var report IngestReport
+	if err := json.Unmarshal([]byte(job.Report), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode ingest report: %w", err)
+	}
+	return &report, nil
+}
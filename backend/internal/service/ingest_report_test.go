@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestReportCollectorGroupsOutcomes(t *testing.T) {
+	t.Parallel()
+
+	collector := newReportCollector("job", "localdir", time.Now())
+	for _, result := range []*processResult{
+		{sourceID: "a", category: "猫猫"},
+		{sourceID: "b", category: "猫猫", reused: true},
+		{sourceID: "c", category: "狗狗"},
+		{sourceID: "d", category: "猫猫"},
+		{sourceID: "e", skipped: true, err: errSkipDuplicate},
+		{sourceID: "f", skipped: true, err: fmt.Errorf("%w: format webm skipped", errSkipRule)},
+		{sourceID: "g", err: errors.New("failed to read image: open /a.png: no such file")},
+		{sourceID: "h", err: errors.New("failed to read image: open /b.png: no such file")},
+		{sourceID: "i", quarantined: true, err: errSkipQuarantined},
+	} {
+		collector.record(result)
+	}
+
+	report := collector.finish(&IngestStats{TotalItems: 9, SkippedItems: 2, QuarantinedItems: 1, FailedItems: 2}, nil)
+	if report.New != 3 || report.Reused != 1 || report.Status != domain.JobStatusCompleted {
+		t.Fatalf("report = %+v, want 3 new and 1 reused", report)
+	}
+	if len(report.Categories) != 2 || report.Categories[0] != (CategoryReport{Category: "猫猫", New: 2, Reused: 1}) {
+		t.Fatalf("Categories = %+v, want 猫猫 first", report.Categories)
+	}
+	if len(report.SkipReasons) != 2 || report.SkipReasons[0].Reason != "duplicate MD5" {
+		t.Fatalf("SkipReasons = %+v, want duplicate and skip rule", report.SkipReasons)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Reason != "failed to read image" || report.Failures[0].Count != 2 {
+		t.Fatalf("Failures = %+v, want read failures grouped", report.Failures)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	for _, row := range []string{"section,name,new,reused,count", "summary,new,,,3", "category,猫猫,2,1,3", "failure,failed to read image,,,2"} {
+		if !strings.Contains(buf.String(), row+"\n") {
+			t.Fatalf("WriteCSV() = %q, want row %q", buf.String(), row)
+		}
+	}
+}
+
+func TestIngestFromSourceStoresReportWithJob(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+
+	ingest := &IngestService{workers: 2, batchSize: 5}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+	stats, err := ingest.IngestFromSource(ctx, &scriptedSource{}, 5, nil)
+	if err != nil {
+		t.Fatalf("IngestFromSource() error = %v", err)
+	}
+
+	report, err := ingest.GetIngestReport(ctx, stats.JobID)
+	if err != nil {
+		t.Fatalf("GetIngestReport() error = %v", err)
+	}
+	if report.Total != 5 || report.Failed != 5 || len(report.Failures) != 1 || len(report.Failures[0].Examples) != 5 {
+		t.Fatalf("report = %+v, want 5 read failures", report)
+	}
+
+	jobs, total, err := ingest.ListIngestJobs(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListIngestJobs() error = %v", err)
+	}
+	if total != 1 || jobs[0].Status != domain.JobStatusCompleted || jobs[0].FailedItems != 5 {
+		t.Fatalf("ListIngestJobs() = %+v (total %d), want one completed job", jobs, total)
+	}
+
+	if _, err := ingest.GetIngestReport(ctx, "missing"); !errors.Is(err, ErrIngestJobNotFound) {
+		t.Fatalf("GetIngestReport(missing) error = %v, want ErrIngestJobNotFound", err)
+	}
+}
+
+func TestIngestFromSourceRunsQueuedJob(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+
+	ingest := &IngestService{workers: 2, batchSize: 5}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+	job, err := ingest.QueueIngestJob(ctx, "scripted")
+	if err != nil {
+		t.Fatalf("QueueIngestJob() error = %v", err)
+	}
+	if queued, err := ingest.GetIngestJob(ctx, job.ID); err != nil || queued.Status != domain.JobStatusPending {
+		t.Fatalf("GetIngestJob() before the run = %+v, %v, want a pending job", queued, err)
+	}
+
+	stats, err := ingest.IngestFromSource(ctx, &scriptedSource{}, 5, &IngestOptions{JobID: job.ID})
+	if err != nil {
+		t.Fatalf("IngestFromSource() error = %v", err)
+	}
+	if stats.JobID != job.ID {
+		t.Fatalf("IngestFromSource() job ID = %q, want %q", stats.JobID, job.ID)
+	}
+
+	done, err := ingest.GetIngestJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetIngestJob() error = %v", err)
+	}
+	if done.Status != domain.JobStatusCompleted || done.TotalItems != 5 || done.FailedItems != 5 || done.StartedAt == nil {
+		t.Fatalf("GetIngestJob() after the run = %+v, want a completed job with 5 failures", done)
+	}
+	if _, total, _ := ingest.ListIngestJobs(ctx, 10, 0); total != 1 {
+		t.Fatalf("ListIngestJobs() total = %d, want the queued job only", total)
+	}
+	if _, err := ingest.GetIngestJob(ctx, "missing"); !errors.Is(err, ErrIngestJobNotFound) {
+		t.Fatalf("GetIngestJob(missing) error = %v, want ErrIngestJobNotFound", err)
+	}
+}
+
+func TestFailInterruptedJobsSkipsActiveJobs(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	stale := time.Now().Add(-time.Hour)
+	jobs := []domain.IngestJob{
+		{ID: "stale-running", SourceID: "s", Kind: domain.JobKindIngest, Status: domain.JobStatusRunning},
+		{ID: "stale-pending", SourceID: "s", Kind: domain.JobKindIngest, Status: domain.JobStatusPending},
+		{ID: "stale-delete", SourceID: "s", Kind: domain.JobKindSourceDelete, Status: domain.JobStatusRunning},
+		{ID: "done", SourceID: "s", Kind: domain.JobKindIngest, Status: domain.JobStatusCompleted},
+	}
+	for i := range jobs {
+		if err := db.Create(&jobs[i]).Error; err != nil {
+			t.Fatalf("failed to create job: %v", err)
+		}
+	}
+	if err := db.Model(&domain.IngestJob{}).Where("1 = 1").UpdateColumn("updated_at", stale).Error; err != nil {
+		t.Fatalf("failed to age jobs: %v", err)
+	}
+	if err := db.Create(&domain.IngestJob{ID: "active", SourceID: "s", Kind: domain.JobKindIngest, Status: domain.JobStatusRunning}).Error; err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	ingest := &IngestService{}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+	failed, err := ingest.FailInterruptedJobs(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("FailInterruptedJobs() error = %v", err)
+	}
+	if failed != 2 {
+		t.Fatalf("FailInterruptedJobs() = %d, want 2", failed)
+	}
+
+	want := map[string]domain.JobStatus{
+		"stale-running": domain.JobStatusFailed,
+		"stale-pending": domain.JobStatusFailed,
+		"stale-delete":  domain.JobStatusRunning,
+		"done":          domain.JobStatusCompleted,
+		"active":        domain.JobStatusRunning,
+	}
+	for id, status := range want {
+		job, err := ingest.GetIngestJob(ctx, id)
+		if err != nil {
+			t.Fatalf("GetIngestJob(%s) error = %v", id, err)
+		}
+		if job.Status != status {
+			t.Fatalf("job %s status = %s, want %s", id, job.Status, status)
+		}
+		if status == domain.JobStatusFailed && (job.ErrorLog == "" || job.CompletedAt == nil) {
+			t.Fatalf("job %s = %+v, want an error log and completion time", id, job)
+		}
+	}
+}
@@ -6,16 +6,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	pb "github.com/qdrant/go-client/qdrant"
 	"github.com/timmy/emomo/internal/domain"
 	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
 	"github.com/timmy/emomo/internal/source"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"google.golang.org/grpc"
 )
 
 func TestIsSupportedStaticImageFormatRejectsGIF(t *testing.T) {
@@ -35,7 +41,7 @@ func TestProcessItemRejectsGIFMagicBytes(t *testing.T) {
 	}
 
 	service := &IngestService{}
-	err := service.processItem(context.Background(), "test", &source.MemeItem{
+	_, err := service.processItem(context.Background(), "test", &source.MemeItem{
 		SourceID:  "deceptive-gif",
 		LocalPath: imagePath,
 		Format:    "jpeg",
@@ -49,13 +55,7 @@ func TestProcessItemRejectsGIFMagicBytes(t *testing.T) {
 func TestProcessItemRollsBackNewMemeWhenVectorWriteFails(t *testing.T) {
 	t.Parallel()
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("failed to open sqlite: %v", err)
-	}
-	if err := db.AutoMigrate(&domain.Meme{}, &domain.MemeVector{}, &domain.MemeDescription{}); err != nil {
-		t.Fatalf("failed to migrate test database: %v", err)
-	}
+	db := testutil.NewTestDB(t)
 
 	imagePath := filepath.Join(t.TempDir(), "meme.png")
 	if err := os.WriteFile(imagePath, testPNG1x1, 0o644); err != nil {
@@ -69,15 +69,9 @@ func TestProcessItemRollsBackNewMemeWhenVectorWriteFails(t *testing.T) {
 		BaseURL: "https://vlm.test/v1",
 	})
 	vlm.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
-		return jsonResponse(t, http.StatusOK, openAIResponse{
-			Choices: []struct {
-				Message struct {
-					Content string `json:"content"`
-				} `json:"message"`
-			}{
-				{Message: struct {
-					Content string `json:"content"`
-				}{Content: "开心质问的表情包"}},
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "开心质问的表情包"}},
 			},
 		}), nil
 	}))
@@ -105,7 +99,7 @@ func TestProcessItemRollsBackNewMemeWhenVectorWriteFails(t *testing.T) {
 		},
 	)
 
-	err = ingest.processItem(context.Background(), "test", &source.MemeItem{
+	_, err := ingest.processItem(context.Background(), "test", &source.MemeItem{
 		SourceID:  "new-meme",
 		LocalPath: imagePath,
 		Format:    "png",
@@ -166,6 +160,138 @@ func TestNewIngestServiceFallbackIndexUsesConfiguredVectorType(t *testing.T) {
 	}
 }
 
+func TestGenerateDeterministicPointIDIsStablePerRoute(t *testing.T) {
+	t.Parallel()
+
+	first := generateDeterministicPointID("md5", "memes", domain.MemeVectorTypeImage)
+	if again := generateDeterministicPointID("md5", "memes", domain.MemeVectorTypeImage); again != first {
+		t.Fatalf("point ID changed between calls: %s != %s", first, again)
+	}
+	if other := generateDeterministicPointID("md5", "memes", domain.MemeVectorTypeCaption); other == first {
+		t.Fatal("image and caption routes in the same collection share a point ID")
+	}
+	if other := generateDeterministicPointID("md5", "memes_v2", domain.MemeVectorTypeImage); other == first {
+		t.Fatal("different collections share a point ID")
+	}
+
+	index := IngestVectorIndex{Collection: "memes"}
+	if got := index.PointID("md5"); got != first {
+		t.Fatalf("IngestVectorIndex.PointID() = %s, want %s", got, first)
+	}
+}
+
+func TestSaveBM25TextUpdatesReusedDescription(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+	ctx := context.Background()
+	if err := descRepo.Create(ctx, &domain.MemeDescription{ID: "desc", MemeID: "meme", MD5Hash: "md5", VLMModel: "vlm", Description: "猫"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	repos := &repository.TxRepositories{Descriptions: descRepo}
+	bm25Text := buildBM25Text("下班了", "一只猫", []string{"猫"})
+	if err := saveBM25Text(ctx, repos, nil, "desc", "", bm25Text); err != nil {
+		t.Fatalf("saveBM25Text() error = %v", err)
+	}
+	stored, err := descRepo.GetByID(ctx, "desc")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if stored.BM25Text != bm25Text {
+		t.Fatalf("BM25Text = %q, want %q", stored.BM25Text, bm25Text)
+	}
+
+	// New descriptions carry the text on insert and are not updated.
+	if err := saveBM25Text(ctx, repos, &domain.MemeDescription{}, "missing", "", bm25Text); err != nil {
+		t.Fatalf("saveBM25Text(new description) error = %v", err)
+	}
+}
+
+// scriptedSource returns batches of unreadable items and runs onFetch before each batch.
+type scriptedSource struct {
+	mu      sync.Mutex
+	calls   int
+	onFetch func(call int) error
+}
+
+func (s *scriptedSource) GetSourceID() string       { return "scripted" }
+func (s *scriptedSource) GetDisplayName() string    { return "scripted" }
+func (s *scriptedSource) SupportsIncremental() bool { return false }
+
+func (s *scriptedSource) FetchBatch(_ context.Context, _ string, limit int) ([]source.MemeItem, string, error) {
+	s.mu.Lock()
+	s.calls++
+	call := s.calls
+	s.mu.Unlock()
+
+	if s.onFetch != nil {
+		if err := s.onFetch(call); err != nil {
+			return nil, "", err
+		}
+	}
+	items := make([]source.MemeItem, limit)
+	for i := range items {
+		items[i] = source.MemeItem{SourceID: fmt.Sprintf("%d-%d", call, i), LocalPath: "/nonexistent/meme.png"}
+	}
+	return items, fmt.Sprintf("cursor-%d", call), nil
+}
+
+func TestIngestFromSourceStopsOnMidRunCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &scriptedSource{onFetch: func(call int) error {
+		if call == 3 {
+			cancel() // The producer is about to fill the queue while workers stop
+		}
+		return nil
+	}}
+
+	ingest := &IngestService{workers: 2, batchSize: 10}
+	done := make(chan struct{})
+	var stats *IngestStats
+	var err error
+	go func() {
+		stats, err = ingest.IngestFromSource(ctx, src, 1000, nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("IngestFromSource() did not return after cancellation")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("IngestFromSource() error = %v, want context.Canceled", err)
+	}
+	if stats.TotalItems > 30 || stats.ProcessedItems > stats.TotalItems {
+		t.Fatalf("stats = %+v, want at most the 30 fetched items processed", stats)
+	}
+}
+
+func TestIngestFromSourceFinishesFetchedItemsOnFetchError(t *testing.T) {
+	t.Parallel()
+
+	src := &scriptedSource{onFetch: func(call int) error {
+		if call == 3 {
+			return errors.New("source unavailable")
+		}
+		return nil
+	}}
+
+	ingest := &IngestService{workers: 2, batchSize: 5}
+	stats, err := ingest.IngestFromSource(context.Background(), src, 100, nil)
+	if err == nil || !strings.Contains(err.Error(), "source unavailable") {
+		t.Fatalf("IngestFromSource() error = %v, want fetch failure", err)
+	}
+	if stats.TotalItems != 10 || stats.ProcessedItems != 10 || stats.FailedItems != 10 {
+		t.Fatalf("stats = %+v, want the 10 fetched items processed", stats)
+	}
+}
+
 var testPNG1x1 = []byte{
 	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a,
 	0x00, 0x00, 0x00, 0x0d, 0x49, 0x48, 0x44, 0x52,
@@ -173,8 +299,8 @@ var testPNG1x1 = []byte{
 	0x08, 0x02, 0x00, 0x00, 0x00, 0x90, 0x77, 0x53,
 	0xde, 0x00, 0x00, 0x00, 0x0c, 0x49, 0x44, 0x41,
 	0x54, 0x08, 0xd7, 0x63, 0xf8, 0xcf, 0xc0, 0x00,
-	0x00, 0x03, 0x01, 0x01, 0x00, 0xc9, 0xfe, 0x92,
-	0xef, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e,
+	0x00, 0x03, 0x01, 0x01, 0x00, 0x18, 0xdd, 0x8d,
+	0xb0, 0x00, 0x00, 0x00, 0x00, 0x49, 0x45, 0x4e,
 	0x44, 0xae, 0x42, 0x60, 0x82,
 }
 
@@ -217,6 +343,10 @@ func (s *memoryObjectStorage) EnsureBucket(context.Context) error {
 	return nil
 }
 
+func (s *memoryObjectStorage) CheckBucket(context.Context) error {
+	return nil
+}
+
 func (s *memoryObjectStorage) Upload(_ context.Context, key string, reader io.Reader, _ int64, _ string) error {
 	data, err := io.ReadAll(reader)
 	if err != nil {
@@ -248,3 +378,57 @@ func (s *memoryObjectStorage) Exists(_ context.Context, key string) (bool, error
 	_, ok := s.objects[key]
 	return ok, nil
 }
+
+// deleteRecordingPoints is a Qdrant points service that records deleted IDs.
+type deleteRecordingPoints struct {
+	pb.UnimplementedPointsServer
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (p *deleteRecordingPoints) Delete(_ context.Context, req *pb.DeletePoints) (*pb.PointsOperationResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range req.GetPoints().GetPoints().GetIds() {
+		p.deleted = append(p.deleted, id.GetUuid())
+	}
+	return &pb.PointsOperationResponse{Result: &pb.UpdateResult{Status: pb.UpdateStatus_Completed}}, nil
+}
+
+func TestRollbackVectorPointsDeletesChunksOfOverwrittenPoints(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	points := &deleteRecordingPoints{}
+	server := grpc.NewServer()
+	pb.RegisterPointsServer(server, points)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	repo, err := repository.NewQdrantRepository(&repository.QdrantConnectionConfig{
+		Host:       "127.0.0.1",
+		Port:       listener.Addr().(*net.TCPAddr).Port,
+		Collection: "emomo",
+	})
+	if err != nil {
+		t.Fatalf("NewQdrantRepository() error = %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	kept, chunk1, chunk2 := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	added, chunk3 := uuid.NewString(), uuid.NewString()
+	ingest := &IngestService{}
+	ingest.rollbackVectorPoints(context.Background(), []writtenVector{
+		// Same ID as the committed record: the point stays, its chunks go
+		{qdrantRepo: repo, record: &domain.MemeVector{QdrantPointID: kept}, previousPointID: kept, chunkPointIDs: []string{chunk1, chunk2}},
+		{qdrantRepo: repo, record: &domain.MemeVector{QdrantPointID: added}, chunkPointIDs: []string{chunk3}},
+	})
+
+	got := strings.Join(points.deleted, ",")
+	if want := strings.Join([]string{chunk1, chunk2, chunk3, added}, ","); got != want {
+		t.Fatalf("deleted points = %s, want %s", got, want)
+	}
+}
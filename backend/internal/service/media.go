@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	defaultMaxClipDuration  = 10 * time.Second
+	defaultMediaConvertTime = 60 * time.Second
+)
+
+// MediaConverter turns formats the Go image decoders cannot read into web-friendly ones.
+type MediaConverter interface {
+	// ToJPEG decodes a still image (HEIC, AVIF) and re-encodes it as JPEG.
+	// Parameters:
+	//   - ctx: context for cancellation and deadlines.
+	//   - data: source file bytes.
+	//   - format: detected source format.
+	// Returns:
+	//   - []byte: JPEG bytes.
+	//   - error: non-nil if conversion fails.
+	ToJPEG(ctx context.Context, data []byte, format string) ([]byte, error)
+
+	// ToMP4 transcodes a short clip (WebM, MP4) to H.264 MP4 without audio.
+	// Parameters:
+	//   - ctx: context for cancellation and deadlines.
+	//   - data: source clip bytes.
+	//   - format: detected source format.
+	// Returns:
+	//   - []byte: MP4 bytes playable in browsers.
+	//   - error: non-nil if transcoding fails.
+	ToMP4(ctx context.Context, data []byte, format string) ([]byte, error)
+
+	// ExtractFrame returns one frame of a clip as JPEG.
+	// Parameters:
+	//   - ctx: context for cancellation and deadlines.
+	//   - data: clip bytes.
+	//   - format: clip format.
+	//   - at: offset of the frame from the start of the clip.
+	// Returns:
+	//   - []byte: JPEG bytes.
+	//   - error: non-nil if extraction fails.
+	ExtractFrame(ctx context.Context, data []byte, format string, at time.Duration) ([]byte, error)
+}
+
+// FFmpegConfig configures the ffmpeg-backed MediaConverter.
+type FFmpegConfig struct {
+	Path            string        // ffmpeg binary name or path
+	MaxClipDuration time.Duration // Clips are truncated to this length
+	Timeout         time.Duration // Per-invocation deadline
+}
+
+// FFmpegConverter implements MediaConverter by shelling out to ffmpeg.
+type FFmpegConverter struct {
+	path            string
+	maxClipDuration time.Duration
+	timeout         time.Duration
+}
+
+// NewFFmpegConverter creates a converter after checking that ffmpeg is installed.
+// Parameters:
+//   - cfg: ffmpeg binary location and limits.
+//
+// Returns:
+//   - *FFmpegConverter: converter ready for use.
+//   - error: non-nil if the ffmpeg binary cannot be found.
+func NewFFmpegConverter(cfg FFmpegConfig) (*FFmpegConverter, error) {
+	path, err := exec.LookPath(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	c := &FFmpegConverter{
+		path:            path,
+		maxClipDuration: cfg.MaxClipDuration,
+		timeout:         cfg.Timeout,
+	}
+	if c.maxClipDuration <= 0 {
+		c.maxClipDuration = defaultMaxClipDuration
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultMediaConvertTime
+	}
+	return c, nil
+}
+
+// ToJPEG converts a still image to JPEG.
+func (c *FFmpegConverter) ToJPEG(ctx context.Context, data []byte, format string) ([]byte, error) {
+	return c.run(ctx, data, format, "jpg", "-frames:v", "1", "-q:v", "2")
+}
+
+// ToMP4 transcodes a clip to H.264 MP4, dropping audio and capping its duration.
+func (c *FFmpegConverter) ToMP4(ctx context.Context, data []byte, format string) ([]byte, error) {
+	return c.run(ctx, data, format, "mp4",
+		"-t", formatSeconds(c.maxClipDuration),
+		"-an",
+		"-c:v", "libx264",
+		"-pix_fmt", "yuv420p",
+		// libx264 needs even dimensions
+		"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2",
+		"-movflags", "+faststart",
+	)
+}
+
+// ExtractFrame grabs the frame at the given offset as JPEG. -ss is passed after
+// the input so ffmpeg decodes up to the exact frame instead of the nearest keyframe.
+func (c *FFmpegConverter) ExtractFrame(ctx context.Context, data []byte, format string, at time.Duration) ([]byte, error) {
+	return c.run(ctx, data, format, "jpg", "-ss", formatSeconds(at), "-frames:v", "1", "-q:v", "2")
+}
+
+// run writes data to a temp file, runs ffmpeg with args between input and output,
+// and returns the output file. Temp files are used because MP4 demuxing needs a
+// seekable input.
+func (c *FFmpegConverter) run(ctx context.Context, data []byte, inFormat, outExt string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "emomo-media-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	inPath := filepath.Join(dir, "in."+inFormat)
+	outPath := filepath.Join(dir, "out."+outExt)
+	if err := os.WriteFile(inPath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write temp input: %w", err)
+	}
+
+	cmdArgs := append([]string{"-hide_banner", "-loglevel", "error", "-y", "-i", inPath}, args...)
+	cmdArgs = append(cmdArgs, outPath)
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.path, cmdArgs...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	out, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ffmpeg output: %w", err)
+	}
+	return out, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// extractPosterFrame picks the poster frame of a clip. The frame at the
+// configured offset is decoded exactly (ffmpeg seeks after opening the input
+// rather than jumping to the nearest keyframe); clips shorter than the offset
+// fall back to their first frame.
+func (s *IngestService) extractPosterFrame(ctx context.Context, clip []byte) ([]byte, error) {
+	return posterFrame(ctx, s.converter, clip, s.posterOffset)
+}
+
+// posterFrame extracts the frame at offset from an MP4 clip, falling back to
+// the first frame.
+func posterFrame(ctx context.Context, converter MediaConverter, clip []byte, offset time.Duration) ([]byte, error) {
+	if offset > 0 {
+		frame, err := converter.ExtractFrame(ctx, clip, "mp4", offset)
+		if err == nil && len(frame) > 0 {
+			return frame, nil
+		}
+		logger.CtxDebug(ctx, "Poster offset unusable, using first frame: offset=%s, error=%v", offset, err)
+	}
+	return converter.ExtractFrame(ctx, clip, "mp4", 0)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"github.com/timmy/emomo/internal/source"
+)
+
+// fakeMediaConverter returns canned output instead of running ffmpeg.
+type fakeMediaConverter struct {
+	frame []byte
+	mp4   []byte
+}
+
+func (c fakeMediaConverter) ToJPEG(context.Context, []byte, string) ([]byte, error) {
+	return c.frame, nil
+}
+
+func (c fakeMediaConverter) ToMP4(context.Context, []byte, string) ([]byte, error) {
+	return c.mp4, nil
+}
+
+func (c fakeMediaConverter) ExtractFrame(context.Context, []byte, string, time.Duration) ([]byte, error) {
+	return c.frame, nil
+}
+
+// shortClipConverter labels frames with their offset and has no frames past duration.
+type shortClipConverter struct {
+	fakeMediaConverter
+	duration time.Duration
+}
+
+func (c shortClipConverter) ExtractFrame(_ context.Context, _ []byte, _ string, at time.Duration) ([]byte, error) {
+	if at >= c.duration {
+		return nil, errors.New("no frame at offset")
+	}
+	return []byte(at.String()), nil
+}
+
+func encodeTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func ftypHeader(major string, compatible ...string) []byte {
+	size := 16 + 4*len(compatible)
+	box := []byte{0, 0, 0, byte(size), 'f', 't', 'y', 'p'}
+	box = append(box, major...)
+	box = append(box, 0, 0, 0, 0)
+	for _, brand := range compatible {
+		box = append(box, brand...)
+	}
+	return append(box, make([]byte, 8)...)
+}
+
+func TestDetectImageFormatRecognizesMediaContainers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "heic", data: ftypHeader("heic", "mif1", "heic"), want: "heic"},
+		{name: "heif generic brand", data: ftypHeader("mif1", "heic"), want: "heic"},
+		{name: "avif major brand", data: ftypHeader("avif", "mif1"), want: "avif"},
+		{name: "avif compatible brand", data: ftypHeader("mif1", "avif", "miaf"), want: "avif"},
+		{name: "mp4", data: ftypHeader("isom", "iso2", "avc1", "mp41"), want: "mp4"},
+		{name: "webm", data: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81, 0x01, 0x42, 0xF7, 0x81}, want: "webm"},
+		{name: "unknown brand", data: ftypHeader("qt  "), want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := detectImageFormat(tt.data); got != tt.want {
+			t.Fatalf("detectImageFormat(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProcessItemSkipsClipWithoutConverter(t *testing.T) {
+	t.Parallel()
+
+	clipPath := filepath.Join(t.TempDir(), "sticker.mp4")
+	if err := os.WriteFile(clipPath, ftypHeader("isom", "mp41"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	service := &IngestService{}
+	_, err := service.processItem(context.Background(), "test", &source.MemeItem{
+		SourceID:  "clip",
+		LocalPath: clipPath,
+		Format:    "mp4",
+	}, &IngestOptions{})
+
+	if !errors.Is(err, errSkipUnsupportedImageFormat) {
+		t.Fatalf("processItem() error = %v, want errSkipUnsupportedImageFormat", err)
+	}
+}
+
+func TestProcessItemUploadsClipAndPoster(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	clipPath := filepath.Join(t.TempDir(), "sticker.webm")
+	webm := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81, 0x01, 0x42, 0xF7, 0x81}
+	if err := os.WriteFile(clipPath, webm, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var vlmRequest string
+	vlm := NewVLMService(&VLMConfig{
+		Model:   "test-vlm",
+		APIKey:  "test-key",
+		BaseURL: "https://vlm.test/v1",
+	})
+	vlm.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		vlmRequest = string(body)
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "挥手的贴纸"}},
+			},
+		}), nil
+	}))
+
+	store := newMemoryObjectStorage()
+	ingest := NewIngestService(
+		repository.NewMemeRepository(db),
+		repository.NewMemeVectorRepository(db),
+		repository.NewMemeDescriptionRepository(db),
+		nil,
+		store,
+		vlm,
+		nil,
+		nil,
+		&IngestConfig{
+			Workers:    1,
+			BatchSize:  1,
+			Collection: "broken_collection",
+			VectorIndexes: []IngestVectorIndex{
+				{
+					VectorType: domain.MemeVectorTypeImage,
+					Collection: "broken_collection",
+					Embedding:  fixedEmbeddingProvider{},
+				},
+			},
+			Converter: fakeMediaConverter{
+				frame: encodeTestJPEG(t, 64, 64),
+				mp4:   ftypHeader("isom", "avc1"),
+			},
+		},
+	)
+
+	// The vector write fails without Qdrant, so both uploads must be rolled back.
+	_, err := ingest.processItem(context.Background(), "test", &source.MemeItem{
+		SourceID:  "clip",
+		LocalPath: clipPath,
+		Format:    "webm",
+	}, &IngestOptions{})
+	if err == nil {
+		t.Fatal("processItem() error = nil, want vector write failure")
+	}
+
+	if store.deleteCount != 2 {
+		t.Fatalf("storage delete count = %d, want 2 (clip and poster)", store.deleteCount)
+	}
+	if len(store.objects) != 0 {
+		t.Fatalf("storage objects after rollback = %d, want 0", len(store.objects))
+	}
+	if !strings.Contains(vlmRequest, "data:image/jpeg;base64,") {
+		t.Fatal("VLM request does not carry the JPEG poster frame")
+	}
+}
+
+func TestExtractPosterFrameFallsBackToFirstFrame(t *testing.T) {
+	t.Parallel()
+
+	converter := shortClipConverter{duration: time.Second}
+
+	service := &IngestService{converter: converter, posterOffset: 500 * time.Millisecond}
+	frame, err := service.extractPosterFrame(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("extractPosterFrame() error = %v", err)
+	}
+	if got := string(frame); got != "500ms" {
+		t.Fatalf("poster frame = %q, want frame at offset", got)
+	}
+
+	service.posterOffset = 2 * time.Second
+	frame, err = service.extractPosterFrame(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("extractPosterFrame() error = %v", err)
+	}
+	if got := string(frame); got != "0s" {
+		t.Fatalf("poster frame for short clip = %q, want first frame", got)
+	}
+}
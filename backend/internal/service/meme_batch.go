@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/timmy/emomo/internal/domain"
+)
+
+// MaxBatchMemes is the most memes one batch lookup may request.
+const MaxBatchMemes = 100
+
+// MemeBatchResponse holds the memes of a batch lookup.
+type MemeBatchResponse struct {
+	Memes   []domain.Meme `json:"memes"`   // Found memes in request order
+	Missing []string      `json:"missing"` // Requested IDs that do not exist
+}
+
+// GetMemesByIDs looks up memes by ID in one query, so clients resolving
+// favorites or collections need not fetch them one by one.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - ids: distinct meme IDs (at most MaxBatchMemes).
+//
+// Returns:
+//   - *MemeBatchResponse: memes in request order, plus the IDs not found.
+//   - error: non-nil if the lookup fails.
+func (s *SearchService) GetMemesByIDs(ctx context.Context, ids []string) (*MemeBatchResponse, error) {
+	memes, err := s.memeRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Meme, len(memes))
+	for _, meme := range memes {
+		byID[meme.ID] = meme
+	}
+
+	resp := &MemeBatchResponse{
+		Memes:   make([]domain.Meme, 0, len(memes)),
+		Missing: []string{},
+	}
+	for _, id := range ids {
+		meme, ok := byID[id]
+		if !ok {
+			resp.Missing = append(resp.Missing, id)
+			continue
+		}
+		if meme.PosterKey != "" && s.storage != nil {
+			meme.PosterURL = s.storage.GetURL(meme.PosterKey)
+		}
+		resp.Memes = append(resp.Memes, meme)
+	}
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestGetMemesByIDsKeepsRequestOrder(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, meme := range []domain.Meme{
+		{ID: "meme-1", MD5Hash: "m1"},
+		{ID: "meme-2", MD5Hash: "m2", PosterKey: "a/2_poster.jpeg"},
+		{ID: "meme-3", MD5Hash: "m3"},
+	} {
+		meme.SourceType, meme.SourceID = "test", meme.ID
+		meme.Status = domain.MemeStatusActive
+		if err := memeRepo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+	search := NewSearchService(memeRepo, nil, nil, nil, nil, newMemoryObjectStorage(), nil, nil)
+
+	resp, err := search.GetMemesByIDs(ctx, []string{"meme-3", "gone", "meme-1", "meme-2"})
+	if err != nil {
+		t.Fatalf("GetMemesByIDs() error = %v", err)
+	}
+	got := make([]string, len(resp.Memes))
+	for i, meme := range resp.Memes {
+		got[i] = meme.ID
+	}
+	if want := []string{"meme-3", "meme-1", "meme-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetMemesByIDs() memes = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(resp.Missing, []string{"gone"}) {
+		t.Fatalf("GetMemesByIDs() missing = %v, want [gone]", resp.Missing)
+	}
+	if resp.Memes[2].PosterURL != "https://storage.test/a/2_poster.jpeg" {
+		t.Fatalf("GetMemesByIDs() poster_url = %q, want the storage URL", resp.Memes[2].PosterURL)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"gorm.io/gorm"
+)
+
+// MemeDeleteReport records the removal of a single meme.
+type MemeDeleteReport struct {
+	JobID       string           `json:"job_id,omitempty"`
+	MemeID      string           `json:"meme_id"`
+	SourceType  string           `json:"source_type"`
+	Status      domain.JobStatus `json:"status"`
+	StartedAt   time.Time        `json:"started_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Error       string           `json:"error,omitempty"`
+
+	Descriptions  int64    `json:"descriptions"`
+	Vectors       int64    `json:"vectors"`                  // meme_vectors rows
+	Collections   []string `json:"collections"`              // Collections whose points of the meme were deleted
+	Stale         []string `json:"stale,omitempty"`          // Collections this server does not write; run reembed --dedupe-points for them
+	Objects       []string `json:"objects"`                  // Storage objects deleted
+	SharedObjects []string `json:"shared_objects,omitempty"` // Storage objects kept for other memes
+}
+
+// DeleteMeme removes one meme: its Qdrant points in every ingest collection,
+// the storage objects no other meme references, and finally its meme,
+// description and vector rows. The database rows go last so a failed run can
+// be retried. The run is recorded as an ingest job of kind meme_delete.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme to delete.
+//
+// Returns:
+//   - *MemeDeleteReport: what was removed; nil when the meme does not exist.
+//   - error: ErrMemeNotFound, or a Qdrant, storage or database error; the
+//     report then holds the progress made so far.
+func (s *IngestService) DeleteMeme(ctx context.Context, memeID string) (*MemeDeleteReport, error) {
+	meme, err := s.memeRepo.GetByID(ctx, memeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+
+	report := &MemeDeleteReport{
+		JobID:       uuid.New().String(),
+		MemeID:      meme.ID,
+		SourceType:  meme.SourceType,
+		StartedAt:   time.Now(),
+		Collections: []string{},
+		Objects:     []string{},
+	}
+	job := s.startMemeDeleteJob(ctx, report)
+
+	logger.CtxInfo(ctx, "Deleting meme: meme_id=%s, source=%s, job_id=%s", meme.ID, meme.SourceType, report.JobID)
+	err = s.deleteMeme(ctx, meme, report)
+
+	report.CompletedAt = time.Now()
+	report.Status = domain.JobStatusCompleted
+	if err != nil {
+		report.Status = domain.JobStatusFailed
+		report.Error = err.Error()
+	}
+	s.finishMemeDeleteJob(ctx, job, report)
+	if err != nil {
+		return report, err
+	}
+
+	s.purgeCache(ctx, []string{SurrogateKeyMemes, MemeSurrogateKey(meme.ID), CategorySurrogateKey(meme.Category)})
+	logger.CtxInfo(ctx, "Meme deleted: meme_id=%s, vectors=%d, collections=%v, stale=%v, objects=%d, shared_objects=%d",
+		meme.ID, report.Vectors, report.Collections, report.Stale, len(report.Objects), len(report.SharedObjects))
+	return report, nil
+}
+
+// deleteMeme removes Qdrant points first so search stops returning the meme
+// right away, then unshared storage objects, then the database rows.
+func (s *IngestService) deleteMeme(ctx context.Context, meme *domain.Meme, report *MemeDeleteReport) error {
+	ids := []string{meme.ID}
+	vectorCollections := make(map[string]bool)
+	if s.vectorRepo != nil {
+		vectors, err := s.vectorRepo.GetByMemeID(ctx, meme.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list vectors: %w", err)
+		}
+		report.Vectors = int64(len(vectors))
+		for _, vector := range vectors {
+			vectorCollections[vector.Collection] = true
+		}
+	}
+	if s.descRepo != nil {
+		count, err := s.descRepo.CountByMemeIDs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to count descriptions: %w", err)
+		}
+		report.Descriptions = count
+	}
+
+	for _, qdrantRepo := range s.sourceCollections() {
+		name := qdrantRepo.GetCollectionName()
+		if err := qdrantRepo.DeleteByMemeID(ctx, meme.ID); err != nil {
+			return fmt.Errorf("failed to delete points in %s: %w", name, err)
+		}
+		report.Collections = append(report.Collections, name)
+		delete(vectorCollections, name)
+	}
+	// Their meme_vectors rows are removed below, so dedupe finds the points
+	for collection := range vectorCollections {
+		report.Stale = append(report.Stale, collection)
+	}
+	sort.Strings(report.Stale)
+
+	for _, key := range []string{meme.StorageKey, meme.PosterKey} {
+		if key == "" {
+			continue
+		}
+		refs, err := s.memeRepo.CountOtherStorageKeyRefs(ctx, key, meme.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check storage key references: %w", err)
+		}
+		if refs > 0 {
+			report.SharedObjects = append(report.SharedObjects, key)
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete storage object %s: %w", key, err)
+		}
+		report.Objects = append(report.Objects, key)
+	}
+
+	return s.deleteMemeRows(ctx, ids)
+}
+
+// startMemeDeleteJob records a running meme deletion. Like startJob, it only
+// logs failures.
+func (s *IngestService) startMemeDeleteJob(ctx context.Context, report *MemeDeleteReport) *domain.IngestJob {
+	if s.jobRepo == nil {
+		return nil
+	}
+	job := &domain.IngestJob{
+		ID:         report.JobID,
+		SourceID:   report.SourceType,
+		Kind:       domain.JobKindMemeDelete,
+		Status:     domain.JobStatusRunning,
+		TotalItems: 1,
+		StartedAt:  &report.StartedAt,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.CtxWarn(ctx, "Failed to record meme delete job: job_id=%s, error=%v", job.ID, err)
+		return nil
+	}
+	return job
+}
+
+// finishMemeDeleteJob stores the outcome and report on the job.
+func (s *IngestService) finishMemeDeleteJob(ctx context.Context, job *domain.IngestJob, report *MemeDeleteReport) {
+	if job == nil {
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to encode meme delete report: job_id=%s, error=%v", job.ID, err)
+		return
+	}
+
+	job.Status = report.Status
+	if report.Status == domain.JobStatusCompleted {
+		job.ProcessedItems = 1
+	} else {
+		job.FailedItems = 1
+	}
+	job.CompletedAt = &report.CompletedAt
+	job.ErrorLog = report.Error
+	job.Report = string(data)
+	if err := s.jobRepo.Save(context.WithoutCancel(ctx), job); err != nil {
+		logger.CtxWarn(ctx, "Failed to save meme delete report: job_id=%s, error=%v", job.ID, err)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"gorm.io/gorm"
+)
+
+func TestDeleteMemeKeepsSharedObjects(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	vectorRepo := repository.NewMemeVectorRepository(db)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+	objects := newMemoryObjectStorage()
+
+	for _, meme := range []*domain.Meme{
+		{ID: "clip", SourceType: "test", SourceID: "1", MD5Hash: "clip", StorageKey: "clip.mp4", PosterKey: "poster.jpeg", Tags: domain.StringArray{"猫"}},
+		{ID: "still", SourceType: "test", SourceID: "2", MD5Hash: "still", StorageKey: "poster.jpeg"},
+	} {
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+		objects.objects[meme.StorageKey] = []byte("data")
+		if err := descRepo.Create(ctx, &domain.MemeDescription{ID: "d" + meme.ID, MemeID: meme.ID, MD5Hash: meme.MD5Hash, VLMModel: "vlm", Description: "desc"}); err != nil {
+			t.Fatalf("Create(description %s) error = %v", meme.ID, err)
+		}
+	}
+	for _, collection := range []string{"emomo_jina", "emomo_qwen3vl"} {
+		if err := vectorRepo.Create(ctx, &domain.MemeVector{ID: "v-" + collection, MemeID: "clip", MD5Hash: "clip", Collection: collection, EmbeddingModel: "m", QdrantPointID: "p-" + collection}); err != nil {
+			t.Fatalf("Create(vector %s) error = %v", collection, err)
+		}
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo, vectorRepo: vectorRepo, descRepo: descRepo, storage: objects}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+
+	if _, err := ingest.DeleteMeme(ctx, "missing"); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("DeleteMeme(missing) error = %v, want ErrMemeNotFound", err)
+	}
+	report, err := ingest.DeleteMeme(ctx, "clip")
+	if err != nil {
+		t.Fatalf("DeleteMeme() error = %v", err)
+	}
+	if report.Vectors != 2 || report.Descriptions != 1 || report.Status != domain.JobStatusCompleted {
+		t.Fatalf("DeleteMeme() = %+v, want 2 vectors and 1 description removed", report)
+	}
+	if !reflect.DeepEqual(report.Objects, []string{"clip.mp4"}) || !reflect.DeepEqual(report.SharedObjects, []string{"poster.jpeg"}) {
+		t.Fatalf("DeleteMeme() objects = %v, shared = %v, want clip.mp4 deleted and poster.jpeg kept", report.Objects, report.SharedObjects)
+	}
+	if !reflect.DeepEqual(report.Stale, []string{"emomo_jina", "emomo_qwen3vl"}) {
+		t.Fatalf("DeleteMeme() stale = %v, want both vector collections", report.Stale)
+	}
+	if _, ok := objects.objects["poster.jpeg"]; !ok {
+		t.Fatal("poster.jpeg deleted, want it kept for the other meme")
+	}
+	if _, err := memeRepo.GetByID(ctx, "clip"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByID(clip) error = %v, want not found", err)
+	}
+	if remaining, _ := vectorRepo.CountByMemeIDs(ctx, []string{"clip"}); remaining != 0 {
+		t.Fatalf("remaining vectors = %d, want 0", remaining)
+	}
+	if memes, _ := memeRepo.ListByTag(ctx, "猫", 10, 0); len(memes) != 0 {
+		t.Fatalf("ListByTag(猫) = %d memes, want the tag rows removed", len(memes))
+	}
+
+	jobs, _, err := ingest.ListIngestJobs(ctx, 10, 0)
+	if err != nil || len(jobs) != 1 || jobs[0].Kind != domain.JobKindMemeDelete || jobs[0].ProcessedItems != 1 {
+		t.Fatalf("ListIngestJobs() = %+v, %v, want one meme delete job", jobs, err)
+	}
+}
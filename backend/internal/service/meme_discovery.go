@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MaxRandomMemes bounds the memes drawn by one random request.
+const MaxRandomMemes = 20
+
+// ErrUnknownEmotion is returned for an emotion filter outside the emotion
+// lexicon (prompts.EmotionWords).
+var ErrUnknownEmotion = errors.New("unknown emotion")
+
+// RandomMemesResponse holds memes drawn at random.
+type RandomMemesResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// DailyMemeResponse holds the meme of a day.
+type DailyMemeResponse struct {
+	Date string       `json:"date"` // UTC day as YYYY-MM-DD
+	Meme SearchResult `json:"meme"`
+}
+
+// RandomMemes draws active memes at random, optionally narrowed to a
+// category, tags and an emotion.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category, tags and emotion of the memes; the sort is ignored.
+//   - count: memes to draw, 1 to MaxRandomMemes.
+//
+// Returns:
+//   - *RandomMemesResponse: drawn memes in search-compatible format; fewer
+//     than count when fewer memes match.
+//   - error: ErrUnknownEmotion for an unknown emotion, or a database error.
+func (s *SearchService) RandomMemes(ctx context.Context, filter repository.MemeListFilter, count int) (*RandomMemesResponse, error) {
+	emotion, err := canonicalEmotion(filter.Emotion)
+	if err != nil {
+		return nil, err
+	}
+	filter.Emotion = emotion
+
+	memes, err := s.memeRepo.Random(ctx, filter, min(max(count, 1), MaxRandomMemes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw memes: %w", err)
+	}
+	return &RandomMemesResponse{Results: s.listResults(memes)}, nil
+}
+
+// DailyMeme picks the meme of a UTC day. The pick only depends on the day
+// and the filter: a hash of both selects one of the memes ingested before the
+// day began, so every client sees the same meme all day and memes ingested
+// during the day do not change it.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - filter: category, tags and emotion of the memes; the sort and ingest
+//     bound are replaced.
+//   - day: any time of the day.
+//
+// Returns:
+//   - *DailyMemeResponse: the day and its meme.
+//   - error: ErrUnknownEmotion for an unknown emotion, ErrMemeNotFound when
+//     no meme matches, or a database error.
+func (s *SearchService) DailyMeme(ctx context.Context, filter repository.MemeListFilter, day time.Time) (*DailyMemeResponse, error) {
+	emotion, err := canonicalEmotion(filter.Emotion)
+	if err != nil {
+		return nil, err
+	}
+	filter.Emotion = emotion
+
+	date := day.UTC().Format(statsDateLayout)
+	filter.CreatedBefore, _ = time.Parse(statsDateLayout, date)
+	total, err := s.memeRepo.CountList(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memes: %w", err)
+	}
+	if total == 0 {
+		return nil, ErrMemeNotFound
+	}
+
+	meme, err := s.memeRepo.GetNth(ctx, filter, dailyIndex(date, filter, total))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+	return &DailyMemeResponse{Date: date, Meme: s.listResults([]domain.Meme{*meme})[0]}, nil
+}
+
+// dailyIndex hashes a day and a filter to a position among total memes.
+// SHA-256 rather than FNV: FNV of consecutive dates often lands on the same
+// position modulo small totals, repeating the meme for days.
+func dailyIndex(date string, filter repository.MemeListFilter, total int64) int {
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{date, filter.Category, filter.Emotion}, filter.Tags...), "\x00")))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+}
+
+// canonicalEmotion returns the lexicon spelling of an emotion word, or empty
+// for an empty filter.
+func canonicalEmotion(word string) (string, error) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return "", nil
+	}
+	for _, emotion := range prompts.EmotionWords {
+		if strings.EqualFold(word, emotion) {
+			return emotion, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownEmotion, word)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestDailyMemeIsStableForADay(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	day := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	create := func(id string, createdAt time.Time, tags ...string) {
+		t.Helper()
+		meme := &domain.Meme{ID: id, SourceType: "test", SourceID: id, MD5Hash: "md5-" + id, Category: "猫猫",
+			Tags: tags, Status: domain.MemeStatusActive, CreatedAt: createdAt}
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		create(id, day.AddDate(0, 0, -3))
+	}
+	create("happy", day.AddDate(0, 0, -3), "开心")
+	create("grumpy", day.AddDate(0, 0, -3))
+	if err := db.Create(&domain.MemeDescription{ID: "desc", MemeID: "grumpy", MD5Hash: "md5-grumpy", VLMModel: "test",
+		Description: "一只猫满脸无语地看着镜头"}).Error; err != nil {
+		t.Fatalf("failed to create description: %v", err)
+	}
+
+	search := &SearchService{memeRepo: memeRepo}
+	first, err := search.DailyMeme(ctx, repository.MemeListFilter{}, day)
+	if err != nil {
+		t.Fatalf("DailyMeme() error = %v", err)
+	}
+	if first.Date != "2026-10-16" {
+		t.Fatalf("DailyMeme().Date = %q, want 2026-10-16", first.Date)
+	}
+
+	// Memes ingested during the day do not move the pick
+	create("fresh", day.Add(-time.Hour))
+	again, err := search.DailyMeme(ctx, repository.MemeListFilter{}, day.Add(8*time.Hour))
+	if err != nil || again.Meme.ID != first.Meme.ID {
+		t.Fatalf("DailyMeme() later that day = %+v, %v, want %s again", again, err, first.Meme.ID)
+	}
+
+	picks := map[string]bool{}
+	for i := range 10 {
+		daily, err := search.DailyMeme(ctx, repository.MemeListFilter{}, day.AddDate(0, 0, -i))
+		if err != nil && !errors.Is(err, ErrMemeNotFound) {
+			t.Fatalf("DailyMeme(-%d days) error = %v", i, err)
+		}
+		if err == nil {
+			picks[daily.Meme.ID] = true
+		}
+	}
+	if len(picks) < 2 {
+		t.Fatalf("daily picks over ten days = %v, want more than one meme", picks)
+	}
+
+	for emotion, want := range map[string]string{"开心": "happy", "无语": "grumpy"} {
+		daily, err := search.DailyMeme(ctx, repository.MemeListFilter{Emotion: emotion}, day)
+		if err != nil || daily.Meme.ID != want {
+			t.Fatalf("DailyMeme(emotion=%s) = %+v, %v, want %s", emotion, daily, err, want)
+		}
+	}
+	if _, err := search.DailyMeme(ctx, repository.MemeListFilter{Emotion: "饿了"}, day); !errors.Is(err, ErrUnknownEmotion) {
+		t.Fatalf("DailyMeme(emotion=饿了) error = %v, want ErrUnknownEmotion", err)
+	}
+	if _, err := search.DailyMeme(ctx, repository.MemeListFilter{Category: "狗狗"}, day); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("DailyMeme(category=狗狗) error = %v, want ErrMemeNotFound", err)
+	}
+
+	random, err := search.RandomMemes(ctx, repository.MemeListFilter{Emotion: "EMO"}, 3)
+	if err != nil || len(random.Results) != 0 {
+		t.Fatalf("RandomMemes(emotion=EMO) = %+v, %v, want no memes", random, err)
+	}
+	random, err = search.RandomMemes(ctx, repository.MemeListFilter{}, 100)
+	if err != nil || len(random.Results) != 8 {
+		t.Fatalf("RandomMemes(count=100) = %d results, %v, want all 8 memes", len(random.Results), err)
+	}
+}
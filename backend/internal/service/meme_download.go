@@ -0,0 +1,295 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"gorm.io/gorm"
+)
+
+// MaxBundleMemes is the most memes one ZIP bundle may contain.
+const MaxBundleMemes = 100
+
+// maxFileNameRunes bounds the stem of generated download names.
+const maxFileNameRunes = 64
+
+var (
+	// ErrMemeNotFound is returned when a meme does not exist.
+	ErrMemeNotFound = errors.New("meme not found")
+	// ErrMemeNotStored is returned when a meme has no stored file to download.
+	ErrMemeNotStored = errors.New("meme has no stored file")
+)
+
+// MemeFile describes a stored meme file to be streamed to a client.
+type MemeFile struct {
+	Meme        *domain.Meme
+	Key         string // Storage key of the file
+	Name        string // Sanitized download file name
+	ContentType string
+	ETag        string // Quoted strong ETag derived from the content hash
+}
+
+// GetMemeFile returns the stored file of a meme without opening it, so
+// callers can answer conditional requests before touching storage.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: meme ID.
+//
+// Returns:
+//   - *MemeFile: storage key, file name, content type and ETag of the meme.
+//   - error: ErrMemeNotFound or ErrMemeNotStored, or a lookup error.
+func (s *SearchService) GetMemeFile(ctx context.Context, id string) (*MemeFile, error) {
+	meme, err := s.storedMeme(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newMemeFile(meme), nil
+}
+
+// newMemeFile describes the stored file of meme.
+func newMemeFile(meme *domain.Meme) *MemeFile {
+	return &MemeFile{
+		Meme:        meme,
+		Key:         meme.StorageKey,
+		Name:        MemeFileName(meme),
+		ContentType: getContentType(memeFileExt(meme)),
+		ETag:        memeETag(meme.MD5Hash, ""),
+	}
+}
+
+// OpenFile opens the body of a meme file.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - file: file from GetMemeFile or GetStillFile.
+//
+// Returns:
+//   - io.ReadCloser: file contents; the caller must close it.
+//   - error: non-nil if the download fails.
+func (s *SearchService) OpenFile(ctx context.Context, file *MemeFile) (io.ReadCloser, error) {
+	body, err := s.storage.Download(ctx, file.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download meme %s: %w", file.Meme.ID, err)
+	}
+	return body, nil
+}
+
+// storedMeme returns a meme that has a stored file.
+func (s *SearchService) storedMeme(ctx context.Context, id string) (*domain.Meme, error) {
+	meme, err := s.memeRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+	if meme.StorageKey == "" || s.storage == nil {
+		return nil, ErrMemeNotStored
+	}
+	return meme, nil
+}
+
+// memeETag returns a quoted ETag for content with the given MD5 hash, with
+// an optional variant suffix for derived files. It is empty without a hash.
+func memeETag(md5Hash, variant string) string {
+	if md5Hash == "" {
+		return ""
+	}
+	if variant != "" {
+		return `"` + md5Hash + "-" + variant + `"`
+	}
+	return `"` + md5Hash + `"`
+}
+
+// RecordDownloads counts a download of each meme for the popular sort and
+// the popularity prior of search. It is best-effort: a failure is logged and
+// never fails the download.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - ids: downloaded meme IDs.
+//
+// Returns: none.
+func (s *SearchService) RecordDownloads(ctx context.Context, ids ...string) {
+	if err := s.memeRepo.IncrementDownloads(ctx, ids); err != nil {
+		logger.CtxWarn(ctx, "Failed to record meme downloads: count=%d, error=%v", len(ids), err)
+	}
+	s.recordDownloadActivity(ctx, ids)
+}
+
+// ResolveBundle looks up the memes of a bundle, keeping the requested order
+// and dropping duplicates, unknown IDs and memes without a stored file.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - ids: requested meme IDs (at most MaxBundleMemes after de-duplication).
+//
+// Returns:
+//   - []domain.Meme: downloadable memes in request order.
+//   - error: non-nil if the lookup fails.
+func (s *SearchService) ResolveBundle(ctx context.Context, ids []string) ([]domain.Meme, error) {
+	memes, err := s.memeRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Meme, len(memes))
+	for _, meme := range memes {
+		byID[meme.ID] = meme
+	}
+
+	ordered := make([]domain.Meme, 0, len(memes))
+	for _, id := range ids {
+		meme, ok := byID[id]
+		if !ok || meme.StorageKey == "" {
+			continue
+		}
+		delete(byID, id)
+		ordered = append(ordered, meme)
+	}
+	return ordered, nil
+}
+
+// WriteMemeBundle streams memes into w as a ZIP archive. Images are stored
+// without recompression. A meme that fails to download is skipped and listed
+// in missing.txt, since the response is already under way.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memes: memes to include, typically from ResolveBundle.
+//   - w: destination of the archive.
+//
+// Returns:
+//   - int: number of memes written.
+//   - error: non-nil if writing the archive fails.
+func (s *SearchService) WriteMemeBundle(ctx context.Context, memes []domain.Meme, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+	names := make(map[string]int, len(memes))
+	var missing []string
+	written := 0
+
+	for i := range memes {
+		meme := &memes[i]
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		if s.storage == nil {
+			missing = append(missing, meme.ID)
+			continue
+		}
+
+		body, err := s.storage.Download(ctx, meme.StorageKey)
+		if err != nil {
+			logger.CtxWarn(ctx, "Skipping meme in bundle: meme_id=%s, error=%v", meme.ID, err)
+			missing = append(missing, meme.ID)
+			continue
+		}
+		entry, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     uniqueFileName(MemeFileName(meme), names),
+			Method:   zip.Store,
+			Modified: meme.CreatedAt,
+		})
+		if err == nil {
+			_, err = io.Copy(entry, body)
+		}
+		body.Close()
+		if err != nil {
+			return written, fmt.Errorf("failed to write meme %s to bundle: %w", meme.ID, err)
+		}
+		written++
+	}
+
+	if len(missing) > 0 {
+		entry, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     "missing.txt",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to write bundle manifest: %w", err)
+		}
+		if _, err := io.WriteString(entry, strings.Join(missing, "\n")+"\n"); err != nil {
+			return written, fmt.Errorf("failed to write bundle manifest: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return written, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return written, nil
+}
+
+// MemeFileName returns a download file name for a meme: its category and a
+// short ID, stripped of path separators, control and reserved characters.
+// Parameters:
+//   - meme: meme to name.
+//
+// Returns:
+//   - string: file name such as "猫猫-1a2b3c4d.jpg".
+func MemeFileName(meme *domain.Meme) string {
+	shortID := sanitizeFileName(meme.ID)
+	if len(shortID) > 8 {
+		shortID = shortID[:8]
+	}
+
+	stem := sanitizeFileName(meme.Category)
+	if runes := []rune(stem); len(runes) > maxFileNameRunes {
+		stem = strings.TrimSpace(string(runes[:maxFileNameRunes]))
+	}
+	switch {
+	case stem == "" && shortID == "":
+		stem = "meme"
+	case stem == "":
+		stem = "meme-" + shortID
+	case shortID != "":
+		stem += "-" + shortID
+	}
+
+	if ext := memeFileExt(meme); ext != "" {
+		return stem + "." + ext
+	}
+	return stem
+}
+
+// memeFileExt returns the lower-case extension of the stored file, falling
+// back to the recorded format.
+func memeFileExt(meme *domain.Meme) string {
+	ext := strings.TrimPrefix(path.Ext(meme.StorageKey), ".")
+	if ext == "" {
+		ext = meme.Format
+	}
+	return sanitizeFileName(strings.ToLower(ext))
+}
+
+// sanitizeFileName drops characters that are unsafe in file names on common
+// systems and trims leading/trailing dots and spaces.
+func sanitizeFileName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsControl(r), strings.ContainsRune(`/\:*?"<>|`, r):
+			continue
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), ". ")
+}
+
+// uniqueFileName returns name, or name with a numeric suffix when it was
+// already used in the archive.
+func uniqueFileName(name string, used map[string]int) string {
+	used[name]++
+	if used[name] == 1 {
+		return name
+	}
+	ext := path.Ext(name)
+	candidate := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), used[name], ext)
+	return uniqueFileName(candidate, used)
+}
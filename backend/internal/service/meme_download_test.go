@@ -0,0 +1,100 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestMemeFileNameSanitizes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		meme domain.Meme
+		want string
+	}{
+		{domain.Meme{ID: "1a2b3c4d-5e6f", Category: "猫猫", StorageKey: "ab/abc.JPG"}, "猫猫-1a2b3c4d.jpg"},
+		{domain.Meme{ID: "1a2b3c4d", Category: "../etc/pass\x00wd", Format: "png"}, "etcpasswd-1a2b3c4d.png"},
+		{domain.Meme{ID: "1a2b3c4d", Category: `a:b*c?"<>|`, StorageKey: "x/y.webp"}, "abc-1a2b3c4d.webp"},
+		{domain.Meme{ID: "1a2b3c4d", StorageKey: "x/y.mp4"}, "meme-1a2b3c4d.mp4"},
+	}
+	for _, tt := range tests {
+		if got := MemeFileName(&tt.meme); got != tt.want {
+			t.Errorf("MemeFileName(%q) = %q, want %q", tt.meme.Category, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMemeBundle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	objects := newMemoryObjectStorage()
+	for _, meme := range []domain.Meme{
+		{ID: "aaaaaaaa-1", SourceID: "s1", MD5Hash: "m1", Category: "猫", StorageKey: "a/1.jpg"},
+		{ID: "aaaaaaaa-2", SourceID: "s2", MD5Hash: "m2", Category: "猫", StorageKey: "a/2.jpg"},
+		{ID: "bbbbbbbb-3", SourceID: "s3", MD5Hash: "m3", Category: "狗", StorageKey: "b/3.png"},
+		{ID: "cccccccc-4", SourceID: "s4", MD5Hash: "m4", Category: "空"},
+	} {
+		meme.SourceType = "test"
+		meme.Status = domain.MemeStatusActive
+		if err := memeRepo.Create(ctx, &meme); err != nil {
+			t.Fatalf("Create() meme error = %v", err)
+		}
+		if meme.StorageKey != "" && meme.ID != "bbbbbbbb-3" {
+			objects.objects[meme.StorageKey] = []byte("image " + meme.ID)
+		}
+	}
+
+	searchService := NewSearchService(memeRepo, nil, nil, nil, nil, objects, nil, nil)
+	memes, err := searchService.ResolveBundle(ctx, []string{"bbbbbbbb-3", "aaaaaaaa-1", "cccccccc-4", "missing", "aaaaaaaa-2", "aaaaaaaa-1"})
+	if err != nil {
+		t.Fatalf("ResolveBundle() error = %v", err)
+	}
+	var ids []string
+	for _, meme := range memes {
+		ids = append(ids, meme.ID)
+	}
+	if want := []string{"bbbbbbbb-3", "aaaaaaaa-1", "aaaaaaaa-2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ResolveBundle() = %v, want %v", ids, want)
+	}
+
+	var buf bytes.Buffer
+	written, err := searchService.WriteMemeBundle(ctx, memes, &buf)
+	if err != nil || written != 2 {
+		t.Fatalf("WriteMemeBundle() = %d, %v, want 2 memes", written, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+	}
+	want := map[string]string{
+		"猫-aaaaaaaa.jpg":   "image aaaaaaaa-1",
+		"猫-aaaaaaaa-2.jpg": "image aaaaaaaa-2",
+		"missing.txt":      "bbbbbbbb-3\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bundle entries = %v, want %v", got, want)
+	}
+}
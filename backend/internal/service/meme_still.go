@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// ErrStillUnavailable is returned when an animated meme has no poster frame
+// and none can be generated because media conversion is disabled.
+var ErrStillUnavailable = errors.New("no static frame available")
+
+// SetMediaConverter enables generating missing poster frames on request.
+// Parameters:
+//   - converter: media converter (nil disables generation).
+//   - posterOffset: position of the frame in the clip; falls back to the first frame.
+//
+// Returns: none.
+func (s *SearchService) SetMediaConverter(converter MediaConverter, posterOffset time.Duration) {
+	s.converter = converter
+	s.posterOffset = posterOffset
+}
+
+// GetStillFile returns a static image of a meme without opening it: the meme
+// itself for stills, or the poster frame of an animated meme. A missing poster
+// frame is extracted from the clip, stored next to it and recorded on the
+// meme, so later requests are served from storage.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: meme ID.
+//
+// Returns:
+//   - *MemeFile: storage key, file name, content type and ETag of the still.
+//   - error: ErrMemeNotFound, ErrMemeNotStored, ErrStillUnavailable, or a storage error.
+func (s *SearchService) GetStillFile(ctx context.Context, id string) (*MemeFile, error) {
+	meme, err := s.storedMeme(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !meme.IsAnimated {
+		return newMemeFile(meme), nil
+	}
+
+	if meme.PosterKey == "" {
+		posterKey, err := s.ensurePoster(ctx, meme)
+		if err != nil {
+			return nil, err
+		}
+		meme.PosterKey = posterKey
+	}
+
+	stillKey, format := stillObject(meme)
+	named := *meme
+	named.StorageKey = stillKey
+	named.Format = format
+	return &MemeFile{
+		Meme:        meme,
+		Key:         stillKey,
+		Name:        MemeFileName(&named),
+		ContentType: getContentType(format),
+		ETag:        memeETag(meme.MD5Hash, "still"),
+	}, nil
+}
+
+// ensurePoster stores the poster frame of an animated meme, once per meme even
+// under concurrent requests, and returns its storage key.
+func (s *SearchService) ensurePoster(ctx context.Context, meme *domain.Meme) (string, error) {
+	if meme.MD5Hash == "" {
+		return "", ErrStillUnavailable
+	}
+	posterKey := posterStorageKey(meme.MD5Hash)
+
+	_, err, _ := s.stills.Do(meme.ID, func() (interface{}, error) {
+		// Detached so one caller hanging up does not fail the others.
+		ctx := logger.DetachContext(ctx)
+
+		exists, err := s.storage.Exists(ctx, posterKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check poster frame: %w", err)
+		}
+		if !exists {
+			if err := s.generatePoster(ctx, meme, posterKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.memeRepo.UpdatePosterKey(ctx, meme.ID, posterKey); err != nil {
+			return nil, fmt.Errorf("failed to record poster frame: %w", err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return posterKey, nil
+}
+
+// generatePoster extracts a frame from the stored clip of meme and uploads it
+// as posterKey.
+func (s *SearchService) generatePoster(ctx context.Context, meme *domain.Meme, posterKey string) error {
+	if s.converter == nil {
+		return ErrStillUnavailable
+	}
+
+	body, err := s.storage.Download(ctx, meme.StorageKey)
+	if err != nil {
+		return fmt.Errorf("failed to download clip: %w", err)
+	}
+	clip, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read clip: %w", err)
+	}
+
+	frame, err := posterFrame(ctx, s.converter, clip, s.posterOffset)
+	if err != nil {
+		return fmt.Errorf("failed to extract poster frame: %w", err)
+	}
+	if err := s.storage.Upload(ctx, posterKey, bytes.NewReader(frame), int64(len(frame)), getContentType("jpeg")); err != nil {
+		return fmt.Errorf("failed to upload poster frame: %w", err)
+	}
+
+	logger.CtxInfo(ctx, "Generated poster frame: meme_id=%s, key=%s, size=%d", meme.ID, posterKey, len(frame))
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestOpenStillGeneratesMissingPoster(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	md5Hash := "0123456789abcdef0123456789abcdef"
+	clip := &domain.Meme{
+		ID:         "clip-1",
+		SourceType: "test",
+		SourceID:   "source-1",
+		MD5Hash:    md5Hash,
+		Category:   "跳舞",
+		StorageKey: "01/" + md5Hash + ".mp4",
+		Format:     "mp4",
+		IsAnimated: true,
+		Status:     domain.MemeStatusActive,
+	}
+	if err := memeRepo.Create(ctx, clip); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+	objects := newMemoryObjectStorage()
+	objects.objects[clip.StorageKey] = []byte("clip")
+
+	searchService := NewSearchService(memeRepo, nil, nil, nil, nil, objects, nil, nil)
+	if _, err := searchService.GetStillFile(ctx, clip.ID); !errors.Is(err, ErrStillUnavailable) {
+		t.Fatalf("GetStillFile() without converter error = %v, want ErrStillUnavailable", err)
+	}
+
+	searchService.SetMediaConverter(fakeMediaConverter{frame: []byte("frame")}, 0)
+	file, err := searchService.GetStillFile(ctx, clip.ID)
+	if err != nil {
+		t.Fatalf("GetStillFile() error = %v", err)
+	}
+	if file.ContentType != "image/jpeg" || file.Name != "跳舞-clip-1.jpeg" || file.ETag != `"`+md5Hash+`-still"` {
+		t.Fatalf("GetStillFile() = %+v, want the JPEG poster frame", file)
+	}
+	body, err := searchService.OpenFile(ctx, file)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "frame" {
+		t.Fatalf("OpenFile() = %q, want the poster frame", data)
+	}
+
+	posterKey := posterStorageKey(md5Hash)
+	if _, ok := objects.objects[posterKey]; !ok {
+		t.Fatalf("poster frame not stored at %s", posterKey)
+	}
+	stored, err := memeRepo.GetByID(ctx, clip.ID)
+	if err != nil || stored.PosterKey != posterKey {
+		t.Fatalf("PosterKey = %q, %v, want %q", stored.PosterKey, err, posterKey)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/source"
+)
+
+const (
+	// UploadSourceType is the source type of memes contributed by users.
+	UploadSourceType = "upload"
+	// MaxUploadTags bounds the tags of one upload.
+	MaxUploadTags = 10
+
+	maxUploadTagRunes      = 32
+	maxUploadCategoryRunes = 64
+)
+
+var (
+	// ErrInvalidUpload wraps the reason an upload's tags or category were rejected.
+	ErrInvalidUpload = errors.New("invalid upload")
+	// ErrUploadNearDuplicate is returned when an upload matches a stored meme's
+	// perceptual hash under the skip near duplicate policy.
+	ErrUploadNearDuplicate = errors.New("upload is a near duplicate of a stored meme")
+	// ErrUploadRejected is returned when an upload is an image a moderator
+	// rejected.
+	ErrUploadRejected = errors.New("upload was rejected by moderation")
+)
+
+// UploadRequest is a meme contributed through POST /api/v1/memes/upload.
+type UploadRequest struct {
+	Data     []byte
+	Category string
+	Tags     []string
+}
+
+// UploadResult is the meme an upload created, or the stored meme it duplicates.
+type UploadResult struct {
+	Meme      *domain.Meme `json:"meme"`
+	Duplicate bool         `json:"duplicate"` // The image was already stored; no new meme was created
+}
+
+// UploadMeme runs one uploaded image through the ingest pipeline: dedupe,
+// VLM description, embeddings, Qdrant and storage. New memes are stored as
+// review and stay out of search until ApproveMeme is called.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: image and optional category and tags.
+//
+// Returns:
+//   - *UploadResult: the created meme, or the existing one for duplicates.
+//   - error: ErrInvalidImage, ErrInvalidUpload, ErrUploadNearDuplicate,
+//     ErrUploadRejected, or an ingest error.
+func (s *IngestService) UploadMeme(ctx context.Context, req *UploadRequest) (*UploadResult, error) {
+	category, tags, err := normalizeUploadLabels(req.Category, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	data, format, err := s.prepareUploadImage(req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &source.MemeItem{
+		SourceID: uuid.New().String(),
+		Category: category,
+		Tags:     tags,
+		Format:   format,
+		Data:     data,
+	}
+	md5Hash := calculateMD5(data)
+	reused, err := s.processItem(ctx, UploadSourceType, item, &IngestOptions{Review: true})
+	switch {
+	case errors.Is(err, errSkipDuplicate):
+		reused = true
+	case errors.Is(err, errSkipRejected):
+		return nil, ErrUploadRejected
+	case errors.Is(err, errSkipNearDuplicate):
+		return nil, fmt.Errorf("%w: %v", ErrUploadNearDuplicate, err)
+	case errors.Is(err, errSkipQuarantined):
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to ingest upload: %w", err)
+	}
+
+	meme, err := s.memeRepo.GetByMD5Hash(ctx, md5Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uploaded meme: %w", err)
+	}
+	if meme.Status == domain.MemeStatusRejected {
+		return nil, ErrUploadRejected
+	}
+	logger.CtxInfo(ctx, "Meme uploaded: meme_id=%s, md5=%s, status=%s, duplicate=%v",
+		meme.ID, md5Hash, meme.Status, reused)
+	return &UploadResult{Meme: meme, Duplicate: reused}, nil
+}
+
+// prepareUploadImage checks the format and dimensions of an upload and
+// converts WebP to JPEG up front, so the MD5 ingest stores is the MD5 of the
+// returned data.
+func (s *IngestService) prepareUploadImage(data []byte) ([]byte, string, error) {
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	format := detectImageFormat(data)
+	if !isSupportedStaticImageFormat(format) {
+		return nil, "", fmt.Errorf("%w: unsupported format %s", ErrInvalidImage, format)
+	}
+	if err := validateImage(data, format, s.validation); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if shouldConvertStaticImageToJPEG(format) {
+		converted, err := convertToJPEG(data, format)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		return converted, "jpeg", nil
+	}
+	return data, format, nil
+}
+
+// normalizeUploadLabels trims the category and tags of an upload, dropping
+// empty and repeated tags, and enforces their limits. An empty category
+// becomes 未分类, as for source items without one.
+func normalizeUploadLabels(category string, tags []string) (string, []string, error) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		category = uncategorizedCategory
+	}
+	if utf8.RuneCountInString(category) > maxUploadCategoryRunes {
+		return "", nil, fmt.Errorf("%w: category longer than %d characters", ErrInvalidUpload, maxUploadCategoryRunes)
+	}
+	if strings.ContainsAny(category, `/\`) || category == "." || category == ".." {
+		return "", nil, fmt.Errorf("%w: category must not contain path separators", ErrInvalidUpload)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, row := range domain.NewMemeTags("", tags) {
+		if utf8.RuneCountInString(row.Tag) > maxUploadTagRunes {
+			return "", nil, fmt.Errorf("%w: tag %q longer than %d characters", ErrInvalidUpload, row.Tag, maxUploadTagRunes)
+		}
+		normalized = append(normalized, row.Tag)
+	}
+	if len(normalized) > MaxUploadTags {
+		return "", nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidUpload, MaxUploadTags)
+	}
+	return category, normalized, nil
+}
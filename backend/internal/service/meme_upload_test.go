@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestUploadMemeReturnsStoredDuplicate(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	vectorRepo := repository.NewMemeVectorRepository(db)
+	md5Hash := calculateMD5(testPNG1x1)
+	if err := memeRepo.Create(ctx, &domain.Meme{ID: "stored", SourceType: "localdir", SourceID: "1", MD5Hash: md5Hash, Status: domain.MemeStatusActive}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := vectorRepo.Create(ctx, &domain.MemeVector{ID: "v", MemeID: "stored", MD5Hash: md5Hash, Collection: "emomo", VectorType: domain.MemeVectorTypeImage, EmbeddingModel: "m", QdrantPointID: "p"}); err != nil {
+		t.Fatalf("Create(vector) error = %v", err)
+	}
+	ingest := &IngestService{
+		memeRepo:   memeRepo,
+		vectorRepo: vectorRepo,
+		storage:    newMemoryObjectStorage(),
+		indexes:    []IngestVectorIndex{{VectorType: domain.MemeVectorTypeImage, Collection: "emomo"}},
+	}
+
+	result, err := ingest.UploadMeme(ctx, &UploadRequest{Data: testPNG1x1, Tags: []string{"猫"}})
+	if err != nil {
+		t.Fatalf("UploadMeme() error = %v", err)
+	}
+	if !result.Duplicate || result.Meme.ID != "stored" {
+		t.Fatalf("UploadMeme() = %+v, want the stored meme as a duplicate", result)
+	}
+	if _, err := ingest.UploadMeme(ctx, &UploadRequest{Data: []byte("GIF89a-static")}); !errors.Is(err, ErrInvalidImage) {
+		t.Fatalf("UploadMeme(gif) error = %v, want ErrInvalidImage", err)
+	}
+
+	// A rejected image is refused even while its vectors are still stored
+	if err := db.Model(&domain.Meme{}).Where("id = ?", "stored").Update("status", domain.MemeStatusRejected).Error; err != nil {
+		t.Fatalf("Update(status) error = %v", err)
+	}
+	if _, err := ingest.UploadMeme(ctx, &UploadRequest{Data: testPNG1x1}); !errors.Is(err, ErrUploadRejected) {
+		t.Fatalf("UploadMeme(rejected) error = %v, want ErrUploadRejected", err)
+	}
+}
+
+func TestNormalizeUploadLabels(t *testing.T) {
+	t.Parallel()
+
+	category, tags, err := normalizeUploadLabels(" 动物 ", []string{" 猫 ", "", "猫", "可爱"})
+	if err != nil || category != "动物" || strings.Join(tags, ",") != "猫,可爱" {
+		t.Fatalf("normalizeUploadLabels() = %q, %v, %v, want 动物 and 猫,可爱", category, tags, err)
+	}
+	if category, _, err := normalizeUploadLabels(" ", nil); err != nil || category != uncategorizedCategory {
+		t.Fatalf("normalizeUploadLabels(empty) = %q, %v, want %s", category, err, uncategorizedCategory)
+	}
+	for name, tc := range map[string]struct {
+		category string
+		tags     []string
+	}{
+		"path category": {category: "../etc"},
+		"long tag":      {tags: []string{strings.Repeat("猫", maxUploadTagRunes+1)}},
+		"too many tags": {tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")},
+	} {
+		if _, _, err := normalizeUploadLabels(tc.category, tc.tags); !errors.Is(err, ErrInvalidUpload) {
+			t.Errorf("normalizeUploadLabels(%s) error = %v, want ErrInvalidUpload", name, err)
+		}
+	}
+}
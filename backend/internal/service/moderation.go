@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrMemeNotInReview is returned when moderating a meme that awaits no review.
+var ErrMemeNotInReview = errors.New("meme is not awaiting review")
+
+// ListModerationQueue returns memes awaiting review, oldest first. Uploads
+// always land in the queue; crawled memes do when ingested with
+// IngestOptions.Review.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: only memes of this source; empty lists every source.
+//   - limit: maximum number of memes to return.
+//   - offset: number of memes to skip.
+//
+// Returns:
+//   - []domain.Meme: memes with status review.
+//   - int64: total number of memes awaiting review.
+//   - error: non-nil if a query fails.
+func (s *IngestService) ListModerationQueue(ctx context.Context, sourceType string, limit, offset int) ([]domain.Meme, int64, error) {
+	var memes []domain.Meme
+	var total int64
+	var err error
+	if sourceType == "" {
+		memes, err = s.memeRepo.ListByStatus(ctx, domain.MemeStatusReview, limit, offset)
+	} else {
+		memes, err = s.memeRepo.ListBySourceTypeAndStatus(ctx, sourceType, domain.MemeStatusReview, limit, offset)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+	if sourceType == "" {
+		total, err = s.memeRepo.CountByStatus(ctx, domain.MemeStatusReview)
+	} else {
+		total, err = s.memeRepo.CountBySourceType(ctx, sourceType, domain.MemeStatusReview)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count moderation queue: %w", err)
+	}
+	return memes, total, nil
+}
+
+// ApproveMeme publishes a meme awaiting review: the moderation flag is
+// removed from its points in every ingest collection, then the meme turns
+// active.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme to approve.
+//
+// Returns:
+//   - *domain.Meme: the approved meme.
+//   - error: ErrMemeNotFound, ErrMemeNotInReview, or a Qdrant or database error.
+func (s *IngestService) ApproveMeme(ctx context.Context, memeID string) (*domain.Meme, error) {
+	meme, err := s.getReviewMeme(ctx, memeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Points go first: a failure leaves the meme in review, so approving again retries.
+	for _, qdrantRepo := range s.sourceCollections() {
+		if err := qdrantRepo.ClearPendingReview(ctx, meme.ID); err != nil {
+			return nil, fmt.Errorf("failed to publish points in %s: %w", qdrantRepo.GetCollectionName(), err)
+		}
+	}
+	meme.Status = domain.MemeStatusActive
+	if err := s.memeRepo.Update(ctx, meme); err != nil {
+		return nil, fmt.Errorf("failed to update meme status: %w", err)
+	}
+
+	s.purgeCache(ctx, []string{SurrogateKeyMemes, SurrogateKeyCategories, MemeSurrogateKey(meme.ID), CategorySurrogateKey(meme.Category)})
+	logger.CtxInfo(ctx, "Meme approved: meme_id=%s, source=%s, category=%s", meme.ID, meme.SourceType, meme.Category)
+	return meme, nil
+}
+
+// RejectMeme turns down a meme awaiting review: its points are deleted from
+// every ingest collection, then its meme_vectors rows are removed and it is
+// marked rejected. The meme row, description and storage objects stay, so
+// ingesting or uploading the same image again skips it; DeleteMeme removes
+// them.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme to reject.
+//
+// Returns:
+//   - *domain.Meme: the rejected meme.
+//   - error: ErrMemeNotFound, ErrMemeNotInReview, or a Qdrant or database error.
+func (s *IngestService) RejectMeme(ctx context.Context, memeID string) (*domain.Meme, error) {
+	meme, err := s.getReviewMeme(ctx, memeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The points carry the moderation flag, so until they are gone search
+	// never returns them; a failure leaves the meme in review for a retry.
+	for _, qdrantRepo := range s.sourceCollections() {
+		if err := qdrantRepo.DeleteByMemeID(ctx, meme.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete points in %s: %w", qdrantRepo.GetCollectionName(), err)
+		}
+	}
+	meme.Status = domain.MemeStatusRejected
+	if err := s.withTx(ctx, func(repos *repository.TxRepositories) error {
+		if repos.Vectors != nil {
+			if err := repos.Vectors.DeleteByMemeIDs(ctx, []string{meme.ID}); err != nil {
+				return fmt.Errorf("failed to delete vectors: %w", err)
+			}
+		}
+		if err := repos.Memes.Update(ctx, meme); err != nil {
+			return fmt.Errorf("failed to update meme status: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.purgeCache(ctx, []string{MemeSurrogateKey(meme.ID)})
+	logger.CtxInfo(ctx, "Meme rejected: meme_id=%s, source=%s", meme.ID, meme.SourceType)
+	return meme, nil
+}
+
+// getReviewMeme loads a meme and checks that it awaits review.
+func (s *IngestService) getReviewMeme(ctx context.Context, memeID string) (*domain.Meme, error) {
+	meme, err := s.memeRepo.GetByID(ctx, memeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+	if meme.Status != domain.MemeStatusReview {
+		return nil, ErrMemeNotInReview
+	}
+	return meme, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestApproveMemePublishesReviewedMeme(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, meme := range []*domain.Meme{
+		{ID: "upload", SourceType: UploadSourceType, SourceID: "1", MD5Hash: "md5-upload", Status: domain.MemeStatusReview},
+		{ID: "crawled", SourceType: "localdir", SourceID: "2", MD5Hash: "md5-crawled", Status: domain.MemeStatusReview},
+	} {
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+	ingest := &IngestService{memeRepo: memeRepo}
+
+	memes, total, err := ingest.ListModerationQueue(ctx, "", 10, 0)
+	if err != nil || total != 2 || len(memes) != 2 {
+		t.Fatalf("ListModerationQueue() = %d memes, total %d, %v, want both memes", len(memes), total, err)
+	}
+	memes, total, err = ingest.ListModerationQueue(ctx, UploadSourceType, 10, 0)
+	if err != nil || total != 1 || len(memes) != 1 || memes[0].ID != "upload" {
+		t.Fatalf("ListModerationQueue(upload) = %+v, total %d, %v, want the upload", memes, total, err)
+	}
+	meme, err := ingest.ApproveMeme(ctx, "upload")
+	if err != nil || meme.Status != domain.MemeStatusActive {
+		t.Fatalf("ApproveMeme() = %+v, %v, want an active meme", meme, err)
+	}
+	if _, err := ingest.ApproveMeme(ctx, "upload"); !errors.Is(err, ErrMemeNotInReview) {
+		t.Fatalf("ApproveMeme() twice error = %v, want ErrMemeNotInReview", err)
+	}
+	if _, err := ingest.RejectMeme(ctx, "upload"); !errors.Is(err, ErrMemeNotInReview) {
+		t.Fatalf("RejectMeme(active) error = %v, want ErrMemeNotInReview", err)
+	}
+	if _, err := ingest.ApproveMeme(ctx, "missing"); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("ApproveMeme(missing) error = %v, want ErrMemeNotFound", err)
+	}
+}
+
+func TestRejectMemeKeepsImageOutOfIngest(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	vectorRepo := repository.NewMemeVectorRepository(db)
+	md5Hash := calculateMD5(testPNG1x1)
+	if err := memeRepo.Create(ctx, &domain.Meme{ID: "upload", SourceType: UploadSourceType, SourceID: "1", MD5Hash: md5Hash, Status: domain.MemeStatusReview}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := vectorRepo.Create(ctx, &domain.MemeVector{ID: "v", MemeID: "upload", MD5Hash: md5Hash, Collection: "emomo", VectorType: domain.MemeVectorTypeImage, EmbeddingModel: "m", QdrantPointID: "p"}); err != nil {
+		t.Fatalf("Create(vector) error = %v", err)
+	}
+	ingest := &IngestService{
+		memeRepo:   memeRepo,
+		vectorRepo: vectorRepo,
+		storage:    newMemoryObjectStorage(),
+		indexes:    []IngestVectorIndex{{VectorType: domain.MemeVectorTypeImage, Collection: "emomo"}},
+	}
+
+	meme, err := ingest.RejectMeme(ctx, "upload")
+	if err != nil || meme.Status != domain.MemeStatusRejected {
+		t.Fatalf("RejectMeme() = %+v, %v, want a rejected meme", meme, err)
+	}
+	if remaining, _ := vectorRepo.CountByMemeIDs(ctx, []string{"upload"}); remaining != 0 {
+		t.Fatalf("remaining vectors = %d, want 0", remaining)
+	}
+	if _, total, _ := ingest.ListModerationQueue(ctx, "", 10, 0); total != 0 {
+		t.Fatalf("ListModerationQueue() total = %d after rejecting, want 0", total)
+	}
+
+	// The vectors are gone, so only the rejected row keeps the image out
+	if result, err := ingest.UploadMeme(ctx, &UploadRequest{Data: testPNG1x1}); !errors.Is(err, ErrUploadRejected) {
+		t.Fatalf("UploadMeme(rejected image) = %+v, %v, want ErrUploadRejected", result, err)
+	}
+}
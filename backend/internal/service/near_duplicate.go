@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// errSkipNearDuplicate is a sentinel error for images whose perceptual hash
+// matches a stored meme.
+var errSkipNearDuplicate = errors.New("skipped: near duplicate")
+
+// NearDuplicatePolicy decides what ingest does with a new image whose
+// perceptual hash matches a stored meme, such as a re-compressed or resized copy.
+type NearDuplicatePolicy string
+
+const (
+	NearDuplicateForce NearDuplicatePolicy = "force" // Ingest the copy as an unrelated meme
+	NearDuplicateSkip  NearDuplicatePolicy = "skip"  // Skip the copy
+	NearDuplicateLink  NearDuplicatePolicy = "link"  // Ingest the copy with variant_of set to the stored meme
+)
+
+// ParseNearDuplicatePolicy validates a configured policy. Empty selects force,
+// which leaves near duplicates unchecked.
+// Parameters:
+//   - value: "skip", "link", "force" or empty.
+//
+// Returns:
+//   - NearDuplicatePolicy: the parsed policy.
+//   - error: non-nil for unknown values.
+func ParseNearDuplicatePolicy(value string) (NearDuplicatePolicy, error) {
+	switch policy := NearDuplicatePolicy(value); policy {
+	case "":
+		return NearDuplicateForce, nil
+	case NearDuplicateForce, NearDuplicateSkip, NearDuplicateLink:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown near duplicate policy %q (want skip, link or force)", value)
+	}
+}
+
+const (
+	dHashWidth  = 9 // Columns compared pairwise, giving 8 bits per row
+	dHashHeight = 8
+	// dHashCellSamples bounds the pixels averaged per cell on each axis.
+	dHashCellSamples = 8
+	// dHashMinBits is how many bits must differ from an all-zero and an
+	// all-one hash for it to be matched. Flat images and plain gradients hash
+	// to nearly uniform values shared by unrelated memes.
+	dHashMinBits = 8
+)
+
+// dHash returns the 64-bit difference hash of img as 16 hex digits. The image
+// is reduced to 9x8 gray cells, and each bit records whether a cell is
+// brighter than its right neighbour, so re-encoding and resizing rarely change
+// it. Transparent pixels count as white.
+func dHash(img image.Image) string {
+	bounds := img.Bounds()
+	var cells [dHashHeight][dHashWidth]float64
+	for cy := range dHashHeight {
+		y0 := bounds.Min.Y + cy*bounds.Dy()/dHashHeight
+		y1 := max(y0+1, bounds.Min.Y+(cy+1)*bounds.Dy()/dHashHeight)
+		for cx := range dHashWidth {
+			x0 := bounds.Min.X + cx*bounds.Dx()/dHashWidth
+			x1 := max(x0+1, bounds.Min.X+(cx+1)*bounds.Dx()/dHashWidth)
+			cells[cy][cx] = cellLuminance(img, x0, y0, x1, y1)
+		}
+	}
+
+	var hash uint64
+	for cy := range dHashHeight {
+		for cx := range dHashWidth - 1 {
+			hash <<= 1
+			if cells[cy][cx] > cells[cy][cx+1] {
+				hash |= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// cellLuminance averages the luminance of up to dHashCellSamples² pixels of
+// the rectangle [x0,x1)×[y0,y1).
+func cellLuminance(img image.Image, x0, y0, x1, y1 int) float64 {
+	stepX := max(1, (x1-x0)/dHashCellSamples)
+	stepY := max(1, (y1-y0)/dHashCellSamples)
+	var sum float64
+	var count int
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Colors are premultiplied, so adding the missing alpha composites over white.
+			white := 0xffff - a
+			sum += 0.299*float64(r+white) + 0.587*float64(g+white) + 0.114*float64(b+white)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// informativeHash reports whether hash carries enough structure to identify
+// an image.
+func informativeHash(hash string) bool {
+	value, err := strconv.ParseUint(hash, 16, 64)
+	if err != nil {
+		return false
+	}
+	ones := bits.OnesCount64(value)
+	return ones >= dHashMinBits && ones <= 64-dHashMinBits
+}
+
+// checkNearDuplicate applies the near duplicate policy to a new image with the
+// given perceptual hash. It returns the ID of the stored meme the image is a
+// variant of under the link policy, and errSkipNearDuplicate under the skip
+// policy. Forced runs are never checked.
+func (s *IngestService) checkNearDuplicate(ctx context.Context, hash string, opts *IngestOptions) (string, error) {
+	policy := s.nearDuplicates
+	if opts.NearDuplicates != "" {
+		policy = opts.NearDuplicates
+	}
+	if opts.Force || policy == "" || policy == NearDuplicateForce || !informativeHash(hash) {
+		return "", nil
+	}
+
+	original, err := s.memeRepo.GetByPerceptualHash(ctx, hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up perceptual hash: %w", err)
+	}
+	if policy == NearDuplicateLink {
+		return original.ID, nil
+	}
+	return "", fmt.Errorf("%w: matches meme %s", errSkipNearDuplicate, original.ID)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+// checkerImage draws a coarse checkerboard of width x height pixels.
+func checkerImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			shade := uint8(40)
+			if (x*5/width+y*3/height)%2 == 0 {
+				shade = 220
+			}
+			img.Set(x, y, color.NRGBA{R: shade, G: shade / 2, B: 255 - shade, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDHashSurvivesResizeAndRecompression(t *testing.T) {
+	t.Parallel()
+
+	original := dHash(checkerImage(360, 240))
+	if !informativeHash(original) {
+		t.Fatalf("dHash() = %s, want an informative hash", original)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, checkerImage(180, 120), &jpeg.Options{Quality: 40}); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	copyImg, _, err := image.Decode(&buf)
+	if err != nil {
+		t.Fatalf("image.Decode() error = %v", err)
+	}
+	if got := dHash(copyImg); got != original {
+		t.Fatalf("dHash(resized JPEG) = %s, want %s", got, original)
+	}
+
+	flat := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	if hash := dHash(flat); informativeHash(hash) {
+		t.Fatalf("informativeHash(%s) of a flat image = true, want false", hash)
+	}
+}
+
+func TestCheckNearDuplicateAppliesPolicy(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	hash := dHash(checkerImage(90, 60))
+	stored := &domain.Meme{
+		ID: "original", SourceType: "test", SourceID: "original", MD5Hash: "md5-original",
+		PerceptualHash: hash, Status: domain.MemeStatusActive,
+	}
+	if err := memeRepo.Create(ctx, stored); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo, nearDuplicates: NearDuplicateSkip}
+	if _, err := ingest.checkNearDuplicate(ctx, hash, &IngestOptions{}); !errors.Is(err, errSkipNearDuplicate) {
+		t.Fatalf("checkNearDuplicate() with skip error = %v, want errSkipNearDuplicate", err)
+	}
+	if variantOf, err := ingest.checkNearDuplicate(ctx, hash, &IngestOptions{NearDuplicates: NearDuplicateLink}); err != nil || variantOf != "original" {
+		t.Fatalf("checkNearDuplicate() with link = %q, %v, want original", variantOf, err)
+	}
+	if variantOf, err := ingest.checkNearDuplicate(ctx, hash, &IngestOptions{Force: true}); err != nil || variantOf != "" {
+		t.Fatalf("checkNearDuplicate() on a forced run = %q, %v, want no match", variantOf, err)
+	}
+	if variantOf, err := ingest.checkNearDuplicate(ctx, "00000000000000ff", &IngestOptions{}); err != nil || variantOf != "" {
+		t.Fatalf("checkNearDuplicate() of another hash = %q, %v, want no match", variantOf, err)
+	}
+
+	if _, err := ParseNearDuplicatePolicy("merge"); err == nil {
+		t.Fatalf("ParseNearDuplicatePolicy(merge) error = nil, want an error")
+	}
+}
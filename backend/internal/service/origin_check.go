@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	defaultOriginTimeout  = 10 * time.Second
+	defaultOriginMaxBytes = 20 << 20
+)
+
+// Origin check failures. Dead and blocked origins are definitive; any other
+// error (timeouts, 5xx) is treated as transient and leaves the status alone.
+var (
+	errOriginDead    = errors.New("origin dead")
+	errOriginBlocked = errors.New("origin blocked")
+	errOriginTooBig  = errors.New("origin image too large")
+)
+
+// errSkipOrigin is a sentinel error for URL items whose origin failed the check.
+var errSkipOrigin = errors.New("skipped: origin unavailable")
+
+// originContentTypes are the media types accepted from remote origins.
+var originContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/heic": true,
+	"image/heif": true,
+	"image/avif": true,
+	"video/mp4":  true,
+	"video/webm": true,
+}
+
+// OriginCheckConfig configures the checker for remote source URLs.
+type OriginCheckConfig struct {
+	Timeout   time.Duration     // Per-request timeout (0 uses 10s)
+	MaxBytes  int64             // Largest accepted image (0 uses 20MB)
+	UserAgent string            // Sent with every request; origins may use it to opt out
+	Transport http.RoundTripper // Download proxy and headers from source.NewDownloadTransport (nil uses the default)
+}
+
+// OriginChecker verifies that remote images are still available and may be
+// indexed before they are downloaded, and re-verifies them later.
+type OriginChecker struct {
+	client   *resty.Client
+	maxBytes int64
+}
+
+// NewOriginChecker creates an origin checker.
+// Parameters:
+//   - cfg: checker configuration (nil uses defaults).
+//
+// Returns:
+//   - *OriginChecker: initialized checker.
+func NewOriginChecker(cfg *OriginCheckConfig) *OriginChecker {
+	if cfg == nil {
+		cfg = &OriginCheckConfig{}
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultOriginTimeout
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultOriginMaxBytes
+	}
+
+	client := resty.New()
+	client.SetTimeout(timeout)
+	if cfg.Transport != nil {
+		client.SetTransport(cfg.Transport)
+	}
+	if cfg.UserAgent != "" {
+		client.SetHeader("User-Agent", cfg.UserAgent)
+	}
+	return &OriginChecker{client: client, maxBytes: maxBytes}
+}
+
+// Check sends a HEAD request and verifies the status, content type, size and
+// indexing permission of a remote image. Origins that reject HEAD are probed
+// with a one-byte ranged GET instead.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - url: remote image URL.
+//
+// Returns:
+//   - error: nil if the image may be downloaded; wraps errOriginDead or
+//     errOriginBlocked for definitive failures.
+func (c *OriginChecker) Check(ctx context.Context, url string) error {
+	resp, err := c.client.R().SetContext(ctx).Head(url)
+	if err == nil && (resp.StatusCode() == http.StatusMethodNotAllowed || resp.StatusCode() == http.StatusNotImplemented) {
+		resp, err = c.client.R().SetContext(ctx).SetHeader("Range", "bytes=0-0").Get(url)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check origin: %w", err)
+	}
+	return c.checkResponse(resp.StatusCode(), resp.Header())
+}
+
+// checkResponse classifies an origin response.
+func (c *OriginChecker) checkResponse(status int, header http.Header) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusUnavailableForLegalReasons:
+		return fmt.Errorf("%w: HTTP %d", errOriginBlocked, status)
+	case status == http.StatusTooManyRequests || status >= 500:
+		return fmt.Errorf("origin unavailable: HTTP %d", status)
+	case status >= 400:
+		return fmt.Errorf("%w: HTTP %d", errOriginDead, status)
+	case status < 200 || status >= 300:
+		return fmt.Errorf("origin returned HTTP %d", status)
+	}
+
+	if robots := strings.ToLower(header.Get("X-Robots-Tag")); strings.Contains(robots, "noimageindex") || strings.Contains(robots, "none") {
+		return fmt.Errorf("%w: X-Robots-Tag %q", errOriginBlocked, robots)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || !originContentTypes[mediaType] {
+		return fmt.Errorf("%w: content type %q", errOriginDead, header.Get("Content-Type"))
+	}
+
+	// Ranged probes report the full size in Content-Range instead.
+	size := header.Get("Content-Length")
+	if contentRange := header.Get("Content-Range"); contentRange != "" {
+		size = contentRange[strings.LastIndex(contentRange, "/")+1:]
+	}
+	if n, err := strconv.ParseInt(size, 10, 64); err == nil && n > c.maxBytes {
+		return fmt.Errorf("%w: %d bytes", errOriginTooBig, n)
+	}
+	return nil
+}
+
+// Download fetches a remote image, refusing bodies over the size limit.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - url: remote image URL.
+//
+// Returns:
+//   - []byte: image data.
+//   - error: non-nil if the request fails or the body is too large.
+func (c *OriginChecker) Download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := c.client.R().SetContext(ctx).SetDoNotParseResponse(true).Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download origin: %w", err)
+	}
+	body := resp.RawBody()
+	defer body.Close()
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to download origin: HTTP %d", resp.StatusCode())
+	}
+	data, err := io.ReadAll(io.LimitReader(body, c.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read origin: %w", err)
+	}
+	if int64(len(data)) > c.maxBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", errOriginTooBig, c.maxBytes)
+	}
+	return data, nil
+}
+
+// isRemoteURL reports whether a source item URL points at an HTTP origin.
+func isRemoteURL(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
+}
+
+// fetchRemoteImage checks a remote origin and downloads it. Items whose origin
+// fails the check are skipped rather than failed.
+func (s *IngestService) fetchRemoteImage(ctx context.Context, url string) ([]byte, error) {
+	if s.origins == nil {
+		return nil, fmt.Errorf("URL-based sources need an origin checker")
+	}
+	if err := s.origins.Check(ctx, url); err != nil {
+		if errors.Is(err, errOriginDead) || errors.Is(err, errOriginBlocked) || errors.Is(err, errOriginTooBig) {
+			return nil, fmt.Errorf("%w: %v", errSkipOrigin, err)
+		}
+		return nil, err
+	}
+	return s.origins.Download(ctx, url)
+}
+
+// OriginVerifyStats summarizes one pass over stored source URLs.
+type OriginVerifyStats struct {
+	Checked int64 `json:"checked"`
+	OK      int64 `json:"ok"`
+	Dead    int64 `json:"dead"`
+	Blocked int64 `json:"blocked"`
+	Errors  int64 `json:"errors"` // Transient failures; the status is left unchanged
+}
+
+// VerifyOrigins re-checks stored source URLs not checked within maxAge and
+// records their status. Dead and blocked origins are listed by ListDeadOrigins.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of memes to check.
+//   - maxAge: how long a previous check stays valid.
+//
+// Returns:
+//   - *OriginVerifyStats: statistics for the pass.
+//   - error: non-nil if no checker is configured or the listing fails.
+func (s *IngestService) VerifyOrigins(ctx context.Context, limit int, maxAge time.Duration) (*OriginVerifyStats, error) {
+	if s.origins == nil {
+		return nil, errors.New("origin checker not configured")
+	}
+	memes, err := s.memeRepo.ListOriginsToVerify(ctx, time.Now().Add(-maxAge), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list origins to verify: %w", err)
+	}
+
+	stats := &OriginVerifyStats{}
+	for _, meme := range memes {
+		if ctx.Err() != nil {
+			break
+		}
+		stats.Checked++
+
+		status := domain.OriginStatusOK
+		if err := s.origins.Check(ctx, meme.SourceURL); err != nil {
+			switch {
+			case errors.Is(err, errOriginDead):
+				status = domain.OriginStatusDead
+			case errors.Is(err, errOriginBlocked):
+				status = domain.OriginStatusBlocked
+			case errors.Is(err, errOriginTooBig):
+				// Still alive; size only matters for new downloads.
+			default:
+				logger.CtxWarn(ctx, "Origin check failed: meme_id=%s, error=%v", meme.ID, err)
+				stats.Errors++
+				continue
+			}
+			if status != domain.OriginStatusOK && meme.OriginStatus != status {
+				logger.CtxWarn(ctx, "Origin marked for review: meme_id=%s, status=%s, error=%v", meme.ID, status, err)
+			}
+		}
+
+		switch status {
+		case domain.OriginStatusDead:
+			stats.Dead++
+		case domain.OriginStatusBlocked:
+			stats.Blocked++
+		default:
+			stats.OK++
+		}
+		if err := s.memeRepo.UpdateOriginStatus(ctx, meme.ID, status, time.Now()); err != nil {
+			logger.CtxWarn(ctx, "Failed to record origin status: meme_id=%s, error=%v", meme.ID, err)
+		}
+	}
+	return stats, nil
+}
+
+// OriginVerifierConfig configures periodic origin re-verification.
+type OriginVerifierConfig struct {
+	Interval  time.Duration // Time between passes (0 disables)
+	BatchSize int           // Memes checked per pass
+	MaxAge    time.Duration // How long a previous check stays valid
+}
+
+// StartOriginVerifier re-verifies stored source URLs every interval until ctx
+// is cancelled.
+// Parameters:
+//   - ctx: context that stops the verifier when cancelled.
+//   - cfg: interval, batch size and check age.
+//
+// Returns: none.
+func (s *IngestService) StartOriginVerifier(ctx context.Context, cfg OriginVerifierConfig) {
+	if cfg.Interval <= 0 || s.origins == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats, err := s.VerifyOrigins(ctx, cfg.BatchSize, cfg.MaxAge)
+				if err != nil {
+					logger.CtxWarn(ctx, "Origin verification failed: error=%v", err)
+					continue
+				}
+				if stats.Checked > 0 {
+					logger.CtxInfo(ctx, "Origin verification completed: checked=%d, ok=%d, dead=%d, blocked=%d, errors=%d",
+						stats.Checked, stats.OK, stats.Dead, stats.Blocked, stats.Errors)
+				}
+			}
+		}
+	}()
+}
+
+// ListDeadOrigins returns memes whose origin was found dead or blocked.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of memes to return.
+//   - offset: number of memes to skip.
+//
+// Returns:
+//   - []domain.Meme: memes needing origin review, most recently checked first.
+//   - int64: total number of such memes.
+//   - error: non-nil if the query fails.
+func (s *IngestService) ListDeadOrigins(ctx context.Context, limit, offset int) ([]domain.Meme, int64, error) {
+	memes, err := s.memeRepo.ListDeadOrigins(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list dead origins: %w", err)
+	}
+	total, err := s.memeRepo.CountDeadOrigins(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead origins: %w", err)
+	}
+	return memes, total, nil
+}
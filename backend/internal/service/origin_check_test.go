@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"github.com/timmy/emomo/internal/source"
+)
+
+// originResponse builds a fake origin response.
+func originResponse(status int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// newTestOriginChecker serves every request from the routes keyed by URL path.
+func newTestOriginChecker(routes map[string]func(*http.Request) *http.Response) *OriginChecker {
+	checker := NewOriginChecker(&OriginCheckConfig{MaxBytes: 1024})
+	checker.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if route, ok := routes[r.URL.Path]; ok {
+			return route(r), nil
+		}
+		return originResponse(http.StatusNotFound, http.Header{}, nil), nil
+	}))
+	return checker
+}
+
+func TestOriginCheckerCheck(t *testing.T) {
+	t.Parallel()
+
+	png := http.Header{"Content-Type": {"image/png"}, "Content-Length": {"68"}}
+	checker := newTestOriginChecker(map[string]func(*http.Request) *http.Response{
+		"/ok.png":      func(*http.Request) *http.Response { return originResponse(http.StatusOK, png, nil) },
+		"/private.png": func(*http.Request) *http.Response { return originResponse(http.StatusForbidden, png, nil) },
+		"/page.png": func(*http.Request) *http.Response {
+			return originResponse(http.StatusOK, http.Header{"Content-Type": {"text/html; charset=utf-8"}}, nil)
+		},
+		"/noindex.png": func(*http.Request) *http.Response {
+			return originResponse(http.StatusOK, http.Header{"Content-Type": {"image/png"}, "X-Robots-Tag": {"noimageindex"}}, nil)
+		},
+		"/busy.png": func(*http.Request) *http.Response { return originResponse(http.StatusServiceUnavailable, png, nil) },
+		"/no-head.png": func(r *http.Request) *http.Response {
+			if r.Method == http.MethodHead {
+				return originResponse(http.StatusMethodNotAllowed, http.Header{}, nil)
+			}
+			return originResponse(http.StatusPartialContent, http.Header{"Content-Type": {"image/png"}, "Content-Range": {"bytes 0-0/4096"}}, nil)
+		},
+	})
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{path: "/ok.png"},
+		{path: "/gone.png", want: errOriginDead},
+		{path: "/private.png", want: errOriginBlocked},
+		{path: "/page.png", want: errOriginDead},
+		{path: "/noindex.png", want: errOriginBlocked},
+		{path: "/no-head.png", want: errOriginTooBig},
+	}
+	for _, tt := range tests {
+		err := checker.Check(context.Background(), "https://origin.test"+tt.path)
+		if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Fatalf("Check(%s) error = %v, want %v", tt.path, err, tt.want)
+		}
+	}
+
+	err := checker.Check(context.Background(), "https://origin.test/busy.png")
+	if err == nil || errors.Is(err, errOriginDead) || errors.Is(err, errOriginBlocked) {
+		t.Fatalf("Check(busy) error = %v, want transient error", err)
+	}
+}
+
+func TestProcessItemSkipsDeadOrigin(t *testing.T) {
+	t.Parallel()
+
+	ingest := &IngestService{origins: newTestOriginChecker(nil)}
+	_, err := ingest.processItem(context.Background(), "test", &source.MemeItem{
+		SourceID: "remote",
+		URL:      "https://origin.test/gone.png",
+		Format:   "png",
+	}, &IngestOptions{})
+	if !errors.Is(err, errSkipOrigin) {
+		t.Fatalf("processItem() error = %v, want errSkipOrigin", err)
+	}
+}
+
+func TestVerifyOriginsMarksDeadOriginsForReview(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, meme := range []*domain.Meme{
+		{ID: "alive", SourceID: "1", MD5Hash: "a", SourceURL: "https://origin.test/ok.png"},
+		{ID: "dead", SourceID: "2", MD5Hash: "b", SourceURL: "https://origin.test/gone.png"},
+		{ID: "local", SourceID: "3", MD5Hash: "c"},
+	} {
+		meme.SourceType = "test"
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo, origins: newTestOriginChecker(map[string]func(*http.Request) *http.Response{
+		"/ok.png": func(*http.Request) *http.Response {
+			return originResponse(http.StatusOK, http.Header{"Content-Type": {"image/png"}}, nil)
+		},
+	})}
+	stats, err := ingest.VerifyOrigins(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("VerifyOrigins() error = %v", err)
+	}
+	if stats.Checked != 2 || stats.OK != 1 || stats.Dead != 1 {
+		t.Fatalf("VerifyOrigins() = %+v, want one ok and one dead", stats)
+	}
+
+	memes, total, err := ingest.ListDeadOrigins(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListDeadOrigins() error = %v", err)
+	}
+	if total != 1 || memes[0].ID != "dead" || memes[0].OriginStatus != domain.OriginStatusDead {
+		t.Fatalf("ListDeadOrigins() = %+v, want the dead meme", memes)
+	}
+
+	// Checks stay valid for maxAge, so an immediate second pass checks nothing.
+	stats, err = ingest.VerifyOrigins(ctx, 10, 1<<62)
+	if err != nil || stats.Checked != 0 {
+		t.Fatalf("VerifyOrigins() = %+v, %v, want no memes due", stats, err)
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+// PageInfo is the pagination metadata of an offset-paged list response.
+type PageInfo struct {
+	Total      int64 `json:"total"`       // Items matching the list filters, across all pages
+	Limit      int   `json:"limit"`       // Page size
+	Offset     int   `json:"offset"`      // Items skipped before this page
+	TotalPages int   `json:"total_pages"` // Pages of Limit items needed for Total
+	HasMore    bool  `json:"has_more"`    // Whether items follow this page
+}
+
+// NewPageInfo computes the pagination metadata of a page.
+// Parameters:
+//   - total: items matching the list filters.
+//   - limit: page size; non-positive sizes report zero pages.
+//   - offset: items skipped before the page.
+//
+// Returns:
+//   - PageInfo: metadata for the list response.
+func NewPageInfo(total int64, limit, offset int) PageInfo {
+	info := PageInfo{Total: total, Limit: limit, Offset: offset}
+	if limit > 0 {
+		info.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+	info.HasMore = int64(offset)+int64(limit) < total
+	return info
+}
@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestNewPageInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		total         int64
+		limit, offset int
+		wantPages     int
+		wantMore      bool
+	}{
+		{total: 0, limit: 20, offset: 0, wantPages: 0, wantMore: false},
+		{total: 45, limit: 20, offset: 0, wantPages: 3, wantMore: true},
+		{total: 45, limit: 20, offset: 20, wantPages: 3, wantMore: true},
+		{total: 45, limit: 20, offset: 40, wantPages: 3, wantMore: false},
+		{total: 40, limit: 20, offset: 20, wantPages: 2, wantMore: false},
+		{total: 5, limit: 0, offset: 0, wantPages: 0, wantMore: true},
+	}
+	for _, tt := range tests {
+		info := NewPageInfo(tt.total, tt.limit, tt.offset)
+		if info.TotalPages != tt.wantPages || info.HasMore != tt.wantMore {
+			t.Fatalf("NewPageInfo(%d, %d, %d) = %+v, want %d pages, has_more=%v",
+				tt.total, tt.limit, tt.offset, info, tt.wantPages, tt.wantMore)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// This file holds a minimal Parquet writer for vector exports: one row group,
+// one uncompressed PLAIN data page per column, with the schema
+//
+//	required binary meme_id (UTF8)
+//	required binary point_id (UTF8)
+//	repeated float vector
+//	required binary payload (UTF8, JSON)
+//
+// Readers such as pyarrow and DuckDB load vector as list<float>.
+
+// Parquet format constants (see parquet.thrift).
+const (
+	parquetMagic = "PAR1"
+
+	parquetTypeFloat     = 4
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+	parquetRepeated = 2
+
+	parquetConvertedUTF8 = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageData          = 0
+)
+
+// vectorExportRow is one exported Qdrant point.
+type vectorExportRow struct {
+	MemeID  string
+	PointID string
+	Vector  []float32
+	Payload string // JSON-encoded payload
+}
+
+// parquetColumn is an encoded column chunk waiting to be written.
+type parquetColumn struct {
+	name      string
+	physical  int32
+	utf8      bool
+	repeated  bool
+	numValues int32 // Level entries, or values for required columns
+	page      []byte
+}
+
+// writeVectorParquet writes rows as a Parquet file.
+func writeVectorParquet(w io.Writer, rows []vectorExportRow) error {
+	columns := []parquetColumn{
+		stringColumn("meme_id", rows, func(r vectorExportRow) string { return r.MemeID }),
+		stringColumn("point_id", rows, func(r vectorExportRow) string { return r.PointID }),
+		vectorColumn("vector", rows),
+		stringColumn("payload", rows, func(r vectorExportRow) string { return r.Payload }),
+	}
+
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+
+	var chunks []func(t *thriftWriter)
+	var totalSize int64
+	for _, col := range columns {
+		offset := int64(out.Len())
+
+		header := &thriftWriter{}
+		header.structBegin()
+		header.i32Field(1, parquetPageData)
+		header.i32Field(2, int32(len(col.page)))
+		header.i32Field(3, int32(len(col.page)))
+		header.structField(5)
+		header.i32Field(1, col.numValues)
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		out.Write(header.buf.Bytes())
+		out.Write(col.page)
+		size := int64(header.buf.Len() + len(col.page))
+		totalSize += size
+
+		chunks = append(chunks, func(t *thriftWriter) {
+			t.structBegin()
+			t.i64Field(2, offset)
+			t.structField(3)
+			t.i32Field(1, col.physical)
+			t.listField(2, thriftI32, 2)
+			t.i32(parquetEncodingPlain)
+			t.i32(parquetEncodingRLE)
+			t.listField(3, thriftBinary, 1)
+			t.binary(col.name)
+			t.i32Field(4, parquetCodecUncompressed)
+			t.i64Field(5, int64(col.numValues))
+			t.i64Field(6, size)
+			t.i64Field(7, size)
+			t.i64Field(9, offset)
+			t.structEnd()
+			t.structEnd()
+		})
+	}
+
+	footer := &thriftWriter{}
+	footer.structBegin()
+	footer.i32Field(1, 1)
+	footer.listField(2, thriftStruct, len(columns)+1)
+	footer.structBegin()
+	footer.binaryField(4, "schema")
+	footer.i32Field(5, int32(len(columns)))
+	footer.structEnd()
+	for _, col := range columns {
+		footer.structBegin()
+		footer.i32Field(1, col.physical)
+		repetition := int32(parquetRequired)
+		if col.repeated {
+			repetition = parquetRepeated
+		}
+		footer.i32Field(3, repetition)
+		footer.binaryField(4, col.name)
+		if col.utf8 {
+			footer.i32Field(6, parquetConvertedUTF8)
+		}
+		footer.structEnd()
+	}
+	footer.i64Field(3, int64(len(rows)))
+	footer.listField(4, thriftStruct, 1)
+	footer.structBegin()
+	footer.listField(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		chunk(footer)
+	}
+	footer.i64Field(2, totalSize)
+	footer.i64Field(3, int64(len(rows)))
+	footer.structEnd()
+	footer.binaryField(6, "emomo vector export")
+	footer.structEnd()
+
+	out.Write(footer.buf.Bytes())
+	if err := binary.Write(&out, binary.LittleEndian, uint32(footer.buf.Len())); err != nil {
+		return err
+	}
+	out.WriteString(parquetMagic)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write parquet file: %w", err)
+	}
+	return nil
+}
+
+// stringColumn PLAIN-encodes a required UTF8 column.
+func stringColumn(name string, rows []vectorExportRow, value func(vectorExportRow) string) parquetColumn {
+	var page bytes.Buffer
+	for _, row := range rows {
+		v := value(row)
+		_ = binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+		page.WriteString(v)
+	}
+	return parquetColumn{
+		name:      name,
+		physical:  parquetTypeByteArray,
+		utf8:      true,
+		numValues: int32(len(rows)),
+		page:      page.Bytes(),
+	}
+}
+
+// vectorColumn encodes a repeated float column. Every value after the first
+// of a row has repetition level 1; an empty vector is a single entry with
+// definition level 0 and no value.
+func vectorColumn(name string, rows []vectorExportRow) parquetColumn {
+	var repLevels, defLevels []byte
+	var values bytes.Buffer
+	for _, row := range rows {
+		if len(row.Vector) == 0 {
+			repLevels = append(repLevels, 0)
+			defLevels = append(defLevels, 0)
+			continue
+		}
+		for i, v := range row.Vector {
+			rep := byte(1)
+			if i == 0 {
+				rep = 0
+			}
+			repLevels = append(repLevels, rep)
+			defLevels = append(defLevels, 1)
+			_ = binary.Write(&values, binary.LittleEndian, math.Float32bits(v))
+		}
+	}
+
+	var page bytes.Buffer
+	writeLevels(&page, repLevels)
+	writeLevels(&page, defLevels)
+	page.Write(values.Bytes())
+	return parquetColumn{
+		name:      name,
+		physical:  parquetTypeFloat,
+		repeated:  true,
+		numValues: int32(len(repLevels)),
+		page:      page.Bytes(),
+	}
+}
+
+// writeLevels writes 0/1 levels as length-prefixed RLE runs of bit width 1.
+func writeLevels(w *bytes.Buffer, levels []byte) {
+	var runs bytes.Buffer
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		writeUvarint(&runs, uint64(j-i)<<1)
+		runs.WriteByte(levels[i])
+		i = j
+	}
+	_ = binary.Write(w, binary.LittleEndian, uint32(runs.Len()))
+	w.Write(runs.Bytes())
+}
+
+func writeUvarint(w *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	w.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+// Thrift compact protocol types used by the Parquet footer.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID []int16 // Last field ID per open struct
+}
+
+func (t *thriftWriter) structBegin() {
+	t.lastID = append(t.lastID, 0)
+}
+
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0)
+	t.lastID = t.lastID[:len(t.lastID)-1]
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.lastID[len(t.lastID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		writeUvarint(&t.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	writeUvarint(&t.buf, zigzag(v))
+}
+
+func (t *thriftWriter) binaryField(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(v)
+}
+
+// structField starts a nested struct field; close it with structEnd.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.structBegin()
+}
+
+// listField starts a list field; the caller writes its size elements.
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elemType)
+	writeUvarint(&t.buf, uint64(size))
+}
+
+func (t *thriftWriter) i32(v int32) {
+	writeUvarint(&t.buf, zigzag(int64(v)))
+}
+
+func (t *thriftWriter) binary(v string) {
+	writeUvarint(&t.buf, uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteVectorParquetFooter(t *testing.T) {
+	t.Parallel()
+
+	rows := []vectorExportRow{
+		{MemeID: "m1", PointID: "p1", Vector: []float32{0.5, -1}, Payload: `{"meme_id":"m1"}`},
+		{MemeID: "m2", PointID: "p2", Payload: "{}"},
+		{MemeID: "m3", PointID: "p3", Vector: []float32{2}, Payload: "{}"},
+	}
+	var buf bytes.Buffer
+	if err := writeVectorParquet(&buf, rows); err != nil {
+		t.Fatalf("writeVectorParquet() error = %v", err)
+	}
+
+	data := buf.Bytes()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatalf("file does not start and end with %q", parquetMagic)
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footerLen <= 0 || footerLen > len(data)-12 {
+		t.Fatalf("footer length = %d, file size %d", footerLen, len(data))
+	}
+
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	r := &thriftReader{buf: footer}
+	fields := r.readStruct()
+	if r.err {
+		t.Fatal("footer is not a valid compact thrift struct")
+	}
+	if r.pos != len(footer) {
+		t.Fatalf("footer decoded %d of %d bytes", r.pos, len(footer))
+	}
+	if fields[3] != int64(len(rows)) {
+		t.Fatalf("num_rows = %v, want %d", fields[3], len(rows))
+	}
+	if fields[2] != int64(5) {
+		t.Fatalf("schema elements = %v, want 5", fields[2])
+	}
+}
+
+func TestVectorColumnLevels(t *testing.T) {
+	t.Parallel()
+
+	col := vectorColumn("vector", []vectorExportRow{
+		{Vector: []float32{1, 2}},
+		{},
+	})
+	// Entries: 1, 2 (repeated), then the empty row.
+	if col.numValues != 3 {
+		t.Fatalf("numValues = %d, want 3", col.numValues)
+	}
+	// Two length-prefixed level blocks followed by two float values.
+	repLen := int(binary.LittleEndian.Uint32(col.page))
+	defStart := 4 + repLen
+	defLen := int(binary.LittleEndian.Uint32(col.page[defStart:]))
+	values := col.page[defStart+4+defLen:]
+	if len(values) != 8 {
+		t.Fatalf("values size = %d, want 8", len(values))
+	}
+}
+
+// thriftReader decodes compact thrift structs in tests. readStruct returns
+// top-level i32/i64 field values and the sizes of list fields by field ID.
+type thriftReader struct {
+	buf []byte
+	pos int
+	err bool
+}
+
+func (r *thriftReader) readStruct() map[int16]int64 {
+	fields := make(map[int16]int64)
+	var last int16
+	for !r.err {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		typ := header & 0x0F
+		if delta := header >> 4; delta != 0 {
+			last += int16(delta)
+		} else {
+			last = int16(unzigzag(r.uvarint()))
+		}
+		fields[last] = r.value(typ)
+	}
+	return fields
+}
+
+func (r *thriftReader) value(typ byte) int64 {
+	switch typ {
+	case thriftI32, thriftI64:
+		return unzigzag(r.uvarint())
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		if r.pos > len(r.buf) {
+			r.err = true
+		}
+	case thriftList:
+		header := r.byte()
+		size := int64(header >> 4)
+		if size == 15 {
+			size = int64(r.uvarint())
+		}
+		for i := int64(0); i < size && !r.err; i++ {
+			r.value(header & 0x0F)
+		}
+		return size
+	case thriftStruct:
+		r.readStruct()
+	default:
+		r.err = true
+	}
+	return 0
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.buf) {
+		r.err = true
+		return 0
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[min(r.pos, len(r.buf)):])
+	if n <= 0 {
+		r.err = true
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPopularityDays = 30
+	defaultPopularityTopN = 30
+
+	// popularityPriorImpressions and popularityPriorCTR smooth the
+	// click-through rate: a meme starts as if it had this many impressions at
+	// this rate, so a few lucky clicks do not make it the most popular.
+	popularityPriorImpressions = 20
+	popularityPriorCTR         = 0.05
+
+	// maxBufferedActivity is the number of meme and day rows buffered before
+	// a flush is triggered ahead of the interval.
+	maxBufferedActivity = 5000
+	// activityFlushBatch is the number of rows written per upsert.
+	activityFlushBatch = 500
+)
+
+// PopularityConfig controls the popularity stage of search ranking.
+type PopularityConfig struct {
+	Weight float64 // Share of the popularity prior in the ranking score, 0-1 (0 only records activity)
+	Days   int     // Days of activity the prior sums (default 30)
+	TopN   int     // Candidates retrieved and reordered (default 30)
+}
+
+// SetPopularity enables activity recording: search impressions, result
+// clicks and downloads are counted per meme and day. With a weight, the top
+// candidates of each search are reordered by a blend of their similarity and
+// a popularity prior of those counts.
+// Parameters:
+//   - activityRepo: repository of daily activity rows (nil disables both).
+//   - cfg: weight, window and candidates of the stage.
+//
+// Returns: none.
+func (s *SearchService) SetPopularity(activityRepo *repository.MemeActivityRepository, cfg PopularityConfig) {
+	if cfg.Days <= 0 {
+		cfg.Days = defaultPopularityDays
+	}
+	if cfg.TopN <= 0 {
+		cfg.TopN = defaultPopularityTopN
+	}
+	cfg.Weight = min(max(cfg.Weight, 0), 1)
+	s.activityRepo = activityRepo
+	s.popularity = cfg
+}
+
+// StartActivityFlush buffers activity in memory and writes it every
+// interval, so searches and clicks do not wait for database upserts. The
+// buffer is also flushed when it grows past maxBufferedActivity rows and when
+// ctx ends; call FlushActivity on shutdown for the last counts.
+// Parameters:
+//   - ctx: context whose end stops the flushes.
+//   - interval: time between flushes (0 writes activity on the request path).
+//
+// Returns: none.
+func (s *SearchService) StartActivityFlush(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.activityRepo == nil {
+		return
+	}
+	s.activity = &activityBuffer{rows: make(map[activityKey]domain.MemeActivity), full: make(chan struct{}, 1)}
+	flush := func() {
+		if err := s.FlushActivity(context.WithoutCancel(ctx)); err != nil {
+			logger.CtxWarn(ctx, "Failed to flush meme activity: error=%v", err)
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				flush()
+				return
+			case <-ticker.C:
+				flush()
+			case <-s.activity.full:
+				flush()
+			}
+		}
+	}()
+}
+
+// FlushActivity writes the buffered activity. Counts of a failed write are
+// dropped, as activity is best-effort.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - error: non-nil if an upsert fails.
+func (s *SearchService) FlushActivity(ctx context.Context) error {
+	if s.activity == nil {
+		return nil
+	}
+	rows := s.activity.take()
+	for start := 0; start < len(rows); start += activityFlushBatch {
+		batch := rows[start:min(start+activityFlushBatch, len(rows))]
+		if err := s.activityRepo.Add(ctx, batch); err != nil {
+			return fmt.Errorf("failed to write %d activity rows: %w", len(rows)-start, err)
+		}
+	}
+	return nil
+}
+
+// addActivity counts activity rows, in the buffer when StartActivityFlush
+// started one, else in the database.
+func (s *SearchService) addActivity(ctx context.Context, rows []domain.MemeActivity) error {
+	if s.activity != nil {
+		s.activity.add(rows)
+		return nil
+	}
+	return s.activityRepo.Add(ctx, rows)
+}
+
+// activityKey identifies an activity row.
+type activityKey struct {
+	memeID string
+	date   string
+}
+
+// activityBuffer sums activity rows per meme and day until they are flushed.
+type activityBuffer struct {
+	mu   sync.Mutex
+	rows map[activityKey]domain.MemeActivity
+	full chan struct{} // Signaled when rows reaches maxBufferedActivity
+}
+
+// add sums rows into the buffer.
+func (b *activityBuffer) add(rows []domain.MemeActivity) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, row := range rows {
+		key := activityKey{memeID: row.MemeID, date: row.Date}
+		if sum, ok := b.rows[key]; ok {
+			sum.Impressions += row.Impressions
+			sum.Clicks += row.Clicks
+			sum.Downloads += row.Downloads
+			sum.UpdatedAt = row.UpdatedAt
+			row = sum
+		}
+		b.rows[key] = row
+	}
+	if len(b.rows) >= maxBufferedActivity {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take empties the buffer and returns its rows.
+func (b *activityBuffer) take() []domain.MemeActivity {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	rows := make([]domain.MemeActivity, 0, len(b.rows))
+	for _, row := range b.rows {
+		rows = append(rows, row)
+	}
+	clear(b.rows)
+	return rows
+}
+
+// popularityEnabled reports whether search results are reordered by popularity.
+func (s *SearchService) popularityEnabled() bool {
+	return s.activityRepo != nil && s.popularity.Weight > 0
+}
+
+// RecordClick counts a meme opened from search results.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - id: meme ID.
+//
+// Returns:
+//   - error: ErrMemeNotFound for an unknown meme, or a database error.
+func (s *SearchService) RecordClick(ctx context.Context, id string) error {
+	_, err := s.memeRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMemeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get meme: %w", err)
+	}
+	if s.activityRepo == nil {
+		return nil
+	}
+	row := activityRow(id, time.Now())
+	row.Clicks = 1
+	return s.addActivity(ctx, []domain.MemeActivity{row})
+}
+
+// recordImpressions counts the results of a served search, usually in the
+// activity buffer. It is best-effort: a failure is logged and never fails the
+// search.
+func (s *SearchService) recordImpressions(ctx context.Context, resp *SearchResponse) {
+	if s.activityRepo == nil || len(resp.Results) == 0 {
+		return
+	}
+	now := time.Now()
+	rows := make([]domain.MemeActivity, len(resp.Results))
+	for i, result := range resp.Results {
+		rows[i] = activityRow(result.ID, now)
+		rows[i].Impressions = 1
+	}
+	if err := s.addActivity(ctx, rows); err != nil {
+		logger.CtxWarn(ctx, "Failed to record search impressions: count=%d, error=%v", len(rows), err)
+	}
+}
+
+// recordDownloadActivity counts downloads of memes for the popularity prior.
+// It is best-effort like RecordDownloads.
+func (s *SearchService) recordDownloadActivity(ctx context.Context, ids []string) {
+	if s.activityRepo == nil || len(ids) == 0 {
+		return
+	}
+	now := time.Now()
+	rows := make([]domain.MemeActivity, 0, len(ids))
+	seen := make(map[string]int, len(ids))
+	for _, id := range ids {
+		if i, ok := seen[id]; ok {
+			rows[i].Downloads++
+			continue
+		}
+		seen[id] = len(rows)
+		row := activityRow(id, now)
+		row.Downloads = 1
+		rows = append(rows, row)
+	}
+	if err := s.addActivity(ctx, rows); err != nil {
+		logger.CtxWarn(ctx, "Failed to record download activity: count=%d, error=%v", len(ids), err)
+	}
+}
+
+// PruneActivity deletes activity older than the popularity window.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - int64: number of daily rows deleted.
+//   - error: non-nil if the delete fails.
+func (s *SearchService) PruneActivity(ctx context.Context) (int64, error) {
+	if s.activityRepo == nil {
+		return 0, nil
+	}
+	return s.activityRepo.DeleteBefore(ctx, popularitySince(time.Now(), s.popularity.Days))
+}
+
+// boostPopular reorders results by (1-weight)·relevance + weight·popularity.
+// Relevance is the rerank score when the rerank stage scored every result,
+// else the retrieval score, relative to the best of the results; results
+// with similar relevance, as for ambiguous queries, are ordered mostly by
+// popularity. Popularity averages the smoothed click-through rate and the
+// recent downloads, each relative to the best of the results. When the
+// activity lookup fails, the order is kept.
+func (s *SearchService) boostPopular(ctx context.Context, results []SearchResult) {
+	if !s.popularityEnabled() || len(results) < 2 {
+		return
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	activity, err := s.activityRepo.SumSince(ctx, ids, popularitySince(time.Now(), s.popularity.Days))
+	if err != nil {
+		logger.CtxWarn(ctx, "Popularity lookup failed, keeping ranking: candidates=%d, error=%v", len(results), err)
+		return
+	}
+
+	reranked := true
+	for _, result := range results {
+		reranked = reranked && result.RerankScore != nil
+	}
+	relevance := make([]float64, len(results))
+	ctr := make([]float64, len(results))
+	downloads := make([]float64, len(results))
+	var topRelevance, topCTR, topDownloads float64
+	for i, result := range results {
+		relevance[i] = float64(result.Score)
+		if reranked {
+			relevance[i] = float64(*result.RerankScore)
+		}
+		counts := activity[result.ID]
+		ctr[i] = (float64(counts.Clicks) + popularityPriorCTR*popularityPriorImpressions) /
+			(float64(counts.Impressions) + popularityPriorImpressions)
+		downloads[i] = math.Log1p(float64(counts.Downloads))
+		topRelevance = max(topRelevance, relevance[i])
+		topCTR = max(topCTR, ctr[i])
+		topDownloads = max(topDownloads, downloads[i])
+	}
+
+	weight := s.popularity.Weight
+	scores := make(map[string]float64, len(results))
+	for i := range results {
+		popularity := ratio(ctr[i], topCTR)
+		if topDownloads > 0 {
+			popularity = (popularity + downloads[i]/topDownloads) / 2
+		}
+		score := float32(popularity)
+		results[i].Popularity = &score
+		scores[results[i].ID] = (1-weight)*ratio(relevance[i], topRelevance) + weight*popularity
+	}
+	sort.SliceStable(results, func(i, j int) bool { return scores[results[i].ID] > scores[results[j].ID] })
+}
+
+// ratio returns x relative to top, or 1 when top is zero.
+func ratio(x, top float64) float64 {
+	if top <= 0 {
+		return 1
+	}
+	return x / top
+}
+
+// popularitySince returns the first day of a window of days ending today.
+func popularitySince(now time.Time, days int) string {
+	return now.UTC().AddDate(0, 0, 1-days).Format(statsDateLayout)
+}
+
+// activityRow returns an empty row of counts for a meme on the UTC day of now.
+func activityRow(memeID string, now time.Time) domain.MemeActivity {
+	return domain.MemeActivity{MemeID: memeID, Date: now.UTC().Format(statsDateLayout), UpdatedAt: now}
+}
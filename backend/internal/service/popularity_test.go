@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestPopularityBoostsFrequentlyChosenMemes(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, id := range []string{"a", "b", "c"} {
+		meme := &domain.Meme{ID: id, SourceType: "test", SourceID: id, MD5Hash: "md5-" + id, Category: "猫猫", Status: domain.MemeStatusActive}
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+	}
+
+	search := &SearchService{memeRepo: memeRepo}
+	search.SetPopularity(repository.NewMemeActivityRepository(db), PopularityConfig{Weight: 0.5})
+	if got := search.rerankCandidates(10); got != defaultPopularityTopN {
+		t.Fatalf("rerankCandidates(10) = %d, want top_n %d", got, defaultPopularityTopN)
+	}
+
+	// Every meme was shown ten times; b and c were picked half of the time
+	// and b was downloaded twice
+	shown := &SearchResponse{Results: []SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}}}
+	for range 10 {
+		search.recordImpressions(ctx, shown)
+	}
+	for range 5 {
+		for _, id := range []string{"b", "c"} {
+			if err := search.RecordClick(ctx, id); err != nil {
+				t.Fatalf("RecordClick(%s) error = %v", id, err)
+			}
+		}
+	}
+	search.RecordDownloads(ctx, "b", "b")
+	if err := search.RecordClick(ctx, "missing"); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("RecordClick(missing) error = %v, want ErrMemeNotFound", err)
+	}
+
+	candidates := func() []SearchResult {
+		return []SearchResult{{ID: "a", Score: 0.80}, {ID: "b", Score: 0.78}, {ID: "c", Score: 0.40}}
+	}
+	results := search.rerankResults(ctx, "猫", candidates(), 3)
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	// b is about as relevant as a and more popular; c is popular but far off
+	if strings.Join(ids, ",") != "b,a,c" {
+		t.Fatalf("ranked ids = %v, want [b a c]", ids)
+	}
+	if results[0].Popularity == nil || *results[0].Popularity != 1 {
+		t.Fatalf("first result = %+v, want b with popularity 1", results[0])
+	}
+
+	search.SetPopularity(repository.NewMemeActivityRepository(db), PopularityConfig{})
+	results = search.rerankResults(ctx, "猫", candidates(), 3)
+	if results[0].ID != "a" || results[0].Popularity != nil {
+		t.Fatalf("results without weight = %+v, want retrieval order without popularity", results)
+	}
+}
+
+func TestActivityIsBufferedUntilFlushed(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	memeRepo := repository.NewMemeRepository(db)
+	meme := &domain.Meme{ID: "a", SourceType: "test", SourceID: "a", MD5Hash: "md5-a", Status: domain.MemeStatusActive}
+	if err := memeRepo.Create(ctx, meme); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	activityRepo := repository.NewMemeActivityRepository(db)
+	search := &SearchService{memeRepo: memeRepo}
+	search.SetPopularity(activityRepo, PopularityConfig{})
+	search.StartActivityFlush(ctx, time.Hour)
+
+	shown := &SearchResponse{Results: []SearchResult{{ID: "a"}, {ID: "b"}}}
+	for range 3 {
+		search.recordImpressions(ctx, shown)
+	}
+	if err := search.RecordClick(ctx, "a"); err != nil {
+		t.Fatalf("RecordClick() error = %v", err)
+	}
+	since := popularitySince(time.Now(), 1)
+	if sums, err := activityRepo.SumSince(ctx, []string{"a", "b"}, since); err != nil || len(sums) != 0 {
+		t.Fatalf("SumSince() before flush = %+v, %v, want nothing written", sums, err)
+	}
+
+	if err := search.FlushActivity(ctx); err != nil {
+		t.Fatalf("FlushActivity() error = %v", err)
+	}
+	sums, err := activityRepo.SumSince(ctx, []string{"a", "b"}, since)
+	if err != nil || sums["a"].Impressions != 3 || sums["a"].Clicks != 1 || sums["b"].Impressions != 3 {
+		t.Fatalf("SumSince() after flush = %+v, %v, want a shown 3 times and clicked once", sums, err)
+	}
+	if err := search.FlushActivity(ctx); err != nil {
+		t.Fatalf("second FlushActivity() error = %v", err)
+	}
+	if sums, _ := activityRepo.SumSince(ctx, []string{"a"}, since); sums["a"].Impressions != 3 {
+		t.Fatalf("SumSince() after an empty flush = %+v, want the counts unchanged", sums)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+// PromptVersionReport summarizes which prompt versions produced the stored
+// descriptions of the current VLM model.
+type PromptVersionReport struct {
+	VLMModel       string                          `json:"vlm_model"`
+	CurrentVersion string                          `json:"current_version"`
+	Versions       []repository.PromptVersionCount `json:"versions"`
+	Outdated       int64                           `json:"outdated"` // Descriptions from other (or unknown) versions
+}
+
+// PromptVersionReport counts the descriptions of the current VLM model per prompt version.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - *PromptVersionReport: counts per version and the number of outdated descriptions.
+//   - error: non-nil if descriptions are not stored or the query fails.
+func (s *IngestService) PromptVersionReport(ctx context.Context) (*PromptVersionReport, error) {
+	if s.descRepo == nil || s.vlm == nil {
+		return nil, errors.New("description repository not configured")
+	}
+	counts, err := s.descRepo.CountByPromptVersion(ctx, s.vlm.GetModel())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count descriptions by prompt version: %w", err)
+	}
+
+	report := &PromptVersionReport{
+		VLMModel:       s.vlm.GetModel(),
+		CurrentVersion: s.vlm.PromptVersion(),
+		Versions:       counts,
+	}
+	for _, count := range counts {
+		if count.PromptVersion != report.CurrentVersion {
+			report.Outdated += count.Count
+		}
+	}
+	return report, nil
+}
+
+// RegenerateOutdatedDescriptions re-describes memes whose description for the
+// current VLM model came from an older prompt version, updating the records in
+// place. Vectors are not touched; run reembed --stale afterwards to refresh them.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - limit: maximum number of descriptions to regenerate.
+//
+// Returns:
+//   - *IngestStats: statistics for the run.
+//   - error: non-nil if descriptions are not stored or the listing fails.
+func (s *IngestService) RegenerateOutdatedDescriptions(ctx context.Context, limit int) (*IngestStats, error) {
+	if s.descRepo == nil || s.vlm == nil {
+		return nil, errors.New("description repository not configured")
+	}
+	stats := &IngestStats{StartTime: time.Now()}
+
+	descs, err := s.descRepo.ListOutdatedPrompt(ctx, s.vlm.GetModel(), s.vlm.PromptVersion(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdated descriptions: %w", err)
+	}
+	stats.TotalItems = int64(len(descs))
+
+	for i := range descs {
+		if ctx.Err() != nil {
+			break
+		}
+		desc := &descs[i]
+
+		meme, err := s.memeRepo.GetByID(ctx, desc.MemeID)
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to load meme for description: description_id=%s, error=%v", desc.ID, err)
+			stats.FailedItems++
+			continue
+		}
+		stillKey, stillFormat := stillObject(meme)
+		reader, err := s.storage.Download(ctx, stillKey)
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to download meme: meme_id=%s, error=%v", meme.ID, err)
+			stats.FailedItems++
+			continue
+		}
+		imageData, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to read meme: meme_id=%s, error=%v", meme.ID, err)
+			stats.FailedItems++
+			continue
+		}
+
+		description, ocrText, quality, err := s.describeImage(ctx, imageData, stillFormat)
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to describe meme: meme_id=%s, error=%v", meme.ID, err)
+			stats.FailedItems++
+			continue
+		}
+		desc.Description = description
+		desc.OCRText = ocrText
+		desc.PromptVersion = s.vlm.PromptVersion()
+		if quality != nil {
+			s.applyDescriptionQuality(desc, *quality)
+		}
+		if s.sceneTagger.IsEnabled() {
+			desc.SceneTags = nil
+			s.tagScenes(ctx, description, ocrText, desc, "")
+		}
+
+		if err := s.descRepo.UpdateRegenerated(ctx, desc); err != nil {
+			logger.CtxError(ctx, "Failed to save regenerated description: description_id=%s, error=%v", desc.ID, err)
+			stats.FailedItems++
+			continue
+		}
+		stats.ProcessedItems++
+	}
+
+	stats.EndTime = time.Now()
+	return stats, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestRegenerateOutdatedDescriptions(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+
+	vlm := NewVLMService(&VLMConfig{Model: "test-vlm", APIKey: "test-key", BaseURL: "https://vlm.test/v1"})
+	vlm.client.SetTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": goodDescription}}},
+		}), nil
+	}))
+
+	store := newMemoryObjectStorage()
+	if err := store.Upload(ctx, "ab/meme.png", bytes.NewReader(testPNG1x1), int64(len(testPNG1x1)), "image/png"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := memeRepo.Create(ctx, &domain.Meme{ID: "meme", SourceType: "test", SourceID: "1", StorageKey: "ab/meme.png", Format: "png", MD5Hash: "md5", Status: domain.MemeStatusActive}); err != nil {
+		t.Fatalf("Create(meme) error = %v", err)
+	}
+	for _, desc := range []*domain.MemeDescription{
+		{ID: "old", MemeID: "meme", MD5Hash: "md5", VLMModel: "test-vlm", Description: "旧描述", BM25Text: "旧描述"},
+		{ID: "current", MemeID: "meme", MD5Hash: "md5-2", VLMModel: "test-vlm", Description: "新描述", PromptVersion: vlm.PromptVersion()},
+		{ID: "other-model", MemeID: "meme", MD5Hash: "md5", VLMModel: "other-vlm", Description: "别的模型"},
+	} {
+		if err := descRepo.Create(ctx, desc); err != nil {
+			t.Fatalf("Create(%s) error = %v", desc.ID, err)
+		}
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo, descRepo: descRepo, storage: store, vlm: vlm}
+	report, err := ingest.PromptVersionReport(ctx)
+	if err != nil {
+		t.Fatalf("PromptVersionReport() error = %v", err)
+	}
+	if report.Outdated != 1 || len(report.Versions) != 2 {
+		t.Fatalf("PromptVersionReport() = %+v, want 1 outdated of 2 versions", report)
+	}
+
+	stats, err := ingest.RegenerateOutdatedDescriptions(ctx, 10)
+	if err != nil {
+		t.Fatalf("RegenerateOutdatedDescriptions() error = %v", err)
+	}
+	if stats.TotalItems != 1 || stats.ProcessedItems != 1 {
+		t.Fatalf("stats = %+v, want one regenerated description", stats)
+	}
+
+	regenerated, err := descRepo.GetByID(ctx, "old")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if regenerated.Description != goodDescription || regenerated.PromptVersion != vlm.PromptVersion() {
+		t.Fatalf("regenerated = %+v, want current prompt description", regenerated)
+	}
+	if regenerated.BM25Text != "" {
+		t.Fatalf("BM25Text = %q, want cleared for reembed --stale", regenerated.BM25Text)
+	}
+
+	report, err = ingest.PromptVersionReport(ctx)
+	if err != nil {
+		t.Fatalf("PromptVersionReport() error = %v", err)
+	}
+	if report.Outdated != 0 {
+		t.Fatalf("Outdated = %d after regeneration, want 0", report.Outdated)
+	}
+}
+
+func TestVLMServiceUsesPromptProvider(t *testing.T) {
+	t.Parallel()
+
+	// Moving the prompts must not mark every stored description outdated.
+	if got := NewVLMService(&VLMConfig{}).PromptVersion(); got != "581dfc353c8a" {
+		t.Fatalf("built-in PromptVersion() = %s, want 581dfc353c8a", got)
+	}
+
+	custom := prompts.Default()
+	custom.VLMSystem = "自定义系统提示"
+	vlm := NewVLMService(&VLMConfig{Model: "test-vlm", APIKey: "test-key", BaseURL: "https://vlm.test/v1", Prompts: custom})
+	var system string
+	vlm.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content any    `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		system, _ = body.Messages[0].Content.(string)
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": goodDescription}}},
+		}), nil
+	}))
+
+	if _, err := vlm.DescribeImage(context.Background(), testPNG1x1, "png"); err != nil {
+		t.Fatalf("DescribeImage() error = %v", err)
+	}
+	if system != custom.VLMSystem {
+		t.Fatalf("system prompt = %q, want the overridden prompt", system)
+	}
+	if vlm.PromptVersion() == "581dfc353c8a" {
+		t.Fatal("PromptVersion() did not change with the overridden prompt")
+	}
+}
@@ -1,59 +1,31 @@
 package service
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/go-resty/resty/v2"
-)
-
-const (
-	// Query Expansion Prompt - 与 vlm.go 词汇表保持同步
-	queryExpansionPrompt = `你是表情包搜索查询扩展器。将用户的简短查询扩展为语义丰富的描述，提高向量搜索匹配度。
-
-【核心原则】
-- 保留原始意图，添加同义词、情绪词和场景描述
-- 输出50-80字自然描述，直接输出文本，无需任何前缀
-
-【情绪词库】
-无语/尴尬/开心/暴怒/委屈/嫌弃/震惊/疑惑/得意/摆烂/emo/社死/破防/裂开/绝望/狂喜/阴阳怪气/幸灾乐祸/无奈/崩溃/感动/害怕/可爱/呆萌/嘲讽/鄙视/期待/失望
-
-【网络梗】
-芭比Q了(完蛋)/绝绝子(太绝)/yyds(永远的神)/栓Q(谢谢)/CPU(被PUA)/emo(低落)/摆烂(放弃)/社死(社会性死亡)/破防(崩溃)/一整个xx住/蚌埠住了/绷不住/DNA动了
-
-【主体类型】
-熊猫头/蘑菇头/柴犬/猫咪/兔子/小黄人/派大星/海绵宝宝
-
-【示例】
-输入: 无语
-输出: 无语、无奈、嫌弃的情绪，翻白眼、面无表情、一脸嫌弃的样子，对某事无话可说不想理会，可能是熊猫头或蘑菇头表情包
-
-输入: 熊猫头
-输出: 熊猫头表情包，经典黑白熊猫脸，圆圆的脑袋配各种搞怪表情，可表达无语、开心、疑惑、震惊、嫌弃等多种情绪
-
-输入: 芭比Q了
-输出: 完蛋了、糟糕了、大事不妙，芭比Q网络流行语表示完蛋，惊恐绝望崩溃的表情，事情搞砸了要完蛋了
-
-输入: 好耶
-输出: 开心、兴奋、欢呼雀跃，好耶表示非常高兴激动，手舞足蹈眉开眼笑庆祝的样子，可爱得意满足
-
-输入: 累了毁灭吧
-输出: 疲惫、emo、摆烂、放弃挣扎，累到不想动想要毁灭世界，瘫倒无力眼神空洞，彻底破防不想努力了`
+	"github.com/timmy/emomo/internal/cache"
+	"github.com/timmy/emomo/internal/llmclient"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
 )
 
 // QueryExpansionService handles query expansion using an LLM.
 type QueryExpansionService struct {
-	client   *resty.Client
-	model    string
-	endpoint string
-	apiKey   string
-	enabled  bool
+	client  *llmclient.Client
+	model   string
+	prompts prompts.Provider
+	enabled bool
+	lang    string            // Language of queries that name none (prompts.LangZh or LangEn)
+	limiter *expansionLimiter // nil is unlimited
+
+	cache      *lruCache[string]                         // Expansions by version and query (nil disables)
+	shared     cache.Store                               // Replaces cache when set, shared between replicas
+	cacheStore *repository.QueryExpansionCacheRepository // Persists the cache (nil keeps it in memory)
 }
 
 // QueryExpansionConfig holds configuration for query expansion service.
@@ -62,8 +34,20 @@ type QueryExpansionConfig struct {
 	Model   string
 	APIKey  string
 	BaseURL string
+	Prompts prompts.Provider // nil uses the built-in prompts
+	Transport http.RoundTripper // nil uses the default HTTP transport
+	DefaultLang string // Language of queries that name none: zh (default) or en
+
+	MaxConcurrent int           // Expansions in flight at once (0 = unlimited)
+	QueueTimeout  time.Duration // How long a search waits for a slot before skipping expansion
+
+	CacheSize int         // Expansions kept in memory, least recently used evicted (0 disables)
+	Shared    cache.Store // Shares expansions between replicas in place of the in-memory cache (nil keeps them in process)
 }
 
+// queryExpansionTemperature is kept low for more consistent expansions.
+const queryExpansionTemperature = 0.3
+
 // NewQueryExpansionService creates a new query expansion service.
 // Parameters:
 //   - cfg: query expansion configuration (nil disables expansion).
@@ -74,23 +58,27 @@ func NewQueryExpansionService(cfg *QueryExpansionConfig) *QueryExpansionService
 		return &QueryExpansionService{enabled: false}
 	}
 
-	client := resty.New()
-	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
-	client.SetHeader("Content-Type", "application/json")
-	client.SetTimeout(30 * time.Second)
-
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
+	var local *lruCache[string]
+	if cfg.CacheSize > 0 && cfg.Shared == nil {
+		local = newLRUCache[string](cfg.CacheSize)
 	}
-	endpoint := baseURL + "/chat/completions"
 
+	// No retries: expansion sits on the search path and falls back to the
+	// original query.
 	return &QueryExpansionService{
-		client:   client,
-		model:    cfg.Model,
-		endpoint: endpoint,
-		apiKey:   cfg.APIKey,
-		enabled:  true,
+		client: llmclient.New(llmclient.Config{
+			APIKey:  cfg.APIKey,
+			BaseURL: cfg.BaseURL,
+			Timeout:   30 * time.Second,
+			Transport: cfg.Transport,
+		}),
+		model:   cfg.Model,
+		prompts: prompts.OrDefault(cfg.Prompts),
+		enabled: true,
+		lang:    normalizeQueryLang(cfg.DefaultLang, prompts.LangZh),
+		limiter: newExpansionLimiter(cfg.MaxConcurrent, cfg.QueueTimeout),
+		cache:   local,
+		shared:  cfg.Shared,
 	}
 }
 
@@ -102,57 +90,46 @@ func (s *QueryExpansionService) IsEnabled() bool {
 	return s.enabled
 }
 
-// queryExpansionRequest represents the request to the LLM API
-type queryExpansionRequest struct {
-	Model     string                      `json:"model"`
-	Messages  []queryExpansionMessage     `json:"messages"`
-	MaxTokens int                         `json:"max_tokens"`
-	Temperature float32                   `json:"temperature"`
+// queryLang returns lang, or the default language when lang is empty or
+// unsupported.
+func (s *QueryExpansionService) queryLang(lang string) string {
+	return normalizeQueryLang(lang, s.lang)
 }
 
-type queryExpansionMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type queryExpansionResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
-// queryExpansionStreamRequest represents a streaming request to the LLM API
-type queryExpansionStreamRequest struct {
-	Model       string                  `json:"model"`
-	Messages    []queryExpansionMessage `json:"messages"`
-	MaxTokens   int                     `json:"max_tokens"`
-	Temperature float32                 `json:"temperature"`
-	Stream      bool                    `json:"stream"`
+// normalizeQueryLang returns lang when it has its own expansion prompt, and
+// fallback otherwise.
+func normalizeQueryLang(lang, fallback string) string {
+	switch lang {
+	case prompts.LangZh, prompts.LangEn:
+		return lang
+	default:
+		return fallback
+	}
 }
 
-// streamDelta represents a delta in the streaming response
-type streamDelta struct {
-	Choices []struct {
-		Delta struct {
-			Content string `json:"content"`
-		} `json:"delta"`
-		FinishReason *string `json:"finish_reason"`
-	} `json:"choices"`
+// expansionRequest builds the chat request expanding query with the prompt of
+// its language.
+func (s *QueryExpansionService) expansionRequest(query, lang string) llmclient.ChatRequest {
+	return llmclient.ChatRequest{
+		Model: s.model,
+		Messages: []llmclient.Message{
+			{Role: "system", Content: s.prompts.Prompts().QueryExpansionFor(lang)},
+			{Role: "user", Content: query},
+		},
+		MaxTokens:   150,
+		Temperature: llmclient.Float32(queryExpansionTemperature),
+	}
 }
 
 // Expand expands a short query into a richer semantic description.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //   - query: original query string.
+//   - lang: query language, prompts.LangZh or LangEn (empty uses the default).
 // Returns:
 //   - string: expanded query text (or original on fallback).
 //   - error: non-nil if the expansion request fails.
-func (s *QueryExpansionService) Expand(ctx context.Context, query string) (string, error) {
+func (s *QueryExpansionService) Expand(ctx context.Context, query, lang string) (string, error) {
 	if !s.enabled {
 		return query, nil
 	}
@@ -161,53 +138,28 @@ func (s *QueryExpansionService) Expand(ctx context.Context, query string) (strin
 	if len([]rune(query)) > 50 {
 		return query, nil
 	}
-
-	req := queryExpansionRequest{
-		Model: s.model,
-		Messages: []queryExpansionMessage{
-			{
-				Role:    "system",
-				Content: queryExpansionPrompt,
-			},
-			{
-				Role:    "user",
-				Content: query,
-			},
-		},
-		MaxTokens:   150,
-		Temperature: 0.3, // Lower temperature for more consistent expansions
+	lang = s.queryLang(lang)
+	if expanded, ok := s.cachedExpansion(ctx, query, lang); ok {
+		return expanded, nil
 	}
 
-	var resp queryExpansionResponse
-	httpResp, err := s.client.R().
-		SetContext(ctx).
-		SetBody(req).
-		SetResult(&resp).
-		Post(s.endpoint)
-
+	resp, err := s.client.Chat(ctx, s.expansionRequest(query, lang))
+	if errors.Is(err, llmclient.ErrNoChoices) {
+		return query, nil
+	}
 	if err != nil {
 		// On error, fall back to original query
 		return query, fmt.Errorf("query expansion API call failed: %w", err)
 	}
 
-	if httpResp.StatusCode() < 200 || httpResp.StatusCode() >= 300 {
-		if resp.Error != nil {
-			return query, fmt.Errorf("query expansion API error: %s", resp.Error.Message)
-		}
-		return query, fmt.Errorf("query expansion API error: status %d", httpResp.StatusCode())
-	}
-
-	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
-		return query, nil
-	}
-
-	expanded := strings.TrimSpace(resp.Choices[0].Message.Content)
+	expanded := strings.TrimSpace(resp.Content)
 
 	// Validate expansion - if it's too short or seems invalid, return original
 	if len([]rune(expanded)) < 10 {
 		return query, nil
 	}
 
+	s.storeExpansion(ctx, query, lang, expanded)
 	return expanded, nil
 }
 
@@ -215,10 +167,11 @@ func (s *QueryExpansionService) Expand(ctx context.Context, query string) (strin
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //   - query: original query string.
+//   - lang: query language (empty uses the default).
 // Returns:
 //   - string: expanded query or original when expansion fails.
-func (s *QueryExpansionService) ExpandWithFallback(ctx context.Context, query string) string {
-	expanded, err := s.Expand(ctx, query)
+func (s *QueryExpansionService) ExpandWithFallback(ctx context.Context, query, lang string) string {
+	expanded, err := s.Expand(ctx, query, lang)
 	if err != nil {
 		return query
 	}
@@ -229,11 +182,12 @@ func (s *QueryExpansionService) ExpandWithFallback(ctx context.Context, query st
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //   - query: original query string.
+//   - lang: query language (empty uses the default).
 //   - tokenCh: channel to receive individual tokens.
 // Returns:
 //   - string: complete expanded query.
 //   - error: non-nil if the expansion request fails.
-func (s *QueryExpansionService) ExpandStream(ctx context.Context, query string, tokenCh chan<- string) (string, error) {
+func (s *QueryExpansionService) ExpandStream(ctx context.Context, query, lang string, tokenCh chan<- string) (string, error) {
 	defer close(tokenCh)
 
 	if !s.enabled {
@@ -244,96 +198,27 @@ func (s *QueryExpansionService) ExpandStream(ctx context.Context, query string,
 	if len([]rune(query)) > 50 {
 		return query, nil
 	}
-
-	req := queryExpansionStreamRequest{
-		Model: s.model,
-		Messages: []queryExpansionMessage{
-			{
-				Role:    "system",
-				Content: queryExpansionPrompt,
-			},
-			{
-				Role:    "user",
-				Content: query,
-			},
-		},
-		MaxTokens:   150,
-		Temperature: 0.3,
-		Stream:      true,
+	// A cached expansion arrives as a single token
+	lang = s.queryLang(lang)
+	if expanded, ok := s.cachedExpansion(ctx, query, lang); ok {
+		tokenCh <- expanded
+		return expanded, nil
 	}
 
-	reqBody, err := json.Marshal(req)
+	resp, err := s.client.ChatStream(ctx, s.expansionRequest(query, lang), func(token string) {
+		tokenCh <- token
+	})
 	if err != nil {
-		return query, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request manually for streaming
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.endpoint, strings.NewReader(string(reqBody)))
-	if err != nil {
-		return query, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return query, fmt.Errorf("stream request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return query, fmt.Errorf("stream API error: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	// Parse SSE stream
-	var fullContent strings.Builder
-	scanner := bufio.NewScanner(resp.Body)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, ":") {
-			continue
-		}
-
-		// Parse data lines
-		if strings.HasPrefix(line, "data: ") {
-			data := strings.TrimPrefix(line, "data: ")
-
-			// Check for stream end
-			if data == "[DONE]" {
-				break
-			}
-
-			// Parse JSON delta
-			var delta streamDelta
-			if err := json.Unmarshal([]byte(data), &delta); err != nil {
-				continue // Skip malformed data
-			}
-
-			if len(delta.Choices) > 0 && delta.Choices[0].Delta.Content != "" {
-				content := delta.Choices[0].Delta.Content
-				fullContent.WriteString(content)
-				tokenCh <- content
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return query, fmt.Errorf("stream read error: %w", err)
+		return query, fmt.Errorf("query expansion stream failed: %w", err)
 	}
 
-	expanded := strings.TrimSpace(fullContent.String())
+	expanded := strings.TrimSpace(resp.Content)
 
 	// Validate expansion
 	if len([]rune(expanded)) < 10 {
 		return query, nil
 	}
 
+	s.storeExpansion(ctx, query, lang, expanded)
 	return expanded, nil
 }
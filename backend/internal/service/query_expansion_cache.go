@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+// PromptVersion returns the version of the expansions in a language: a hash
+// of the model and the query expansion prompt of that language. Cached
+// expansions of another version are never reused, so editing the prompt
+// invalidates them, and each language caches its expansions apart.
+// Parameters:
+//   - lang: query language (empty uses the default).
+//
+// Returns:
+//   - string: short hash of the current model and prompt.
+func (s *QueryExpansionService) PromptVersion(lang string) string {
+	return promptVersion(s.model, s.prompts.Prompts().QueryExpansionFor(s.queryLang(lang)))
+}
+
+// promptVersions returns the current version of every query language.
+func (s *QueryExpansionService) promptVersions() []string {
+	return []string{s.PromptVersion(prompts.LangZh), s.PromptVersion(prompts.LangEn)}
+}
+
+// SetCacheStore persists the expansion cache: new expansions are written to
+// store and LoadCache reads them back after a restart.
+// Parameters:
+//   - store: repository of stored expansions.
+//
+// Returns: none.
+func (s *QueryExpansionService) SetCacheStore(store *repository.QueryExpansionCacheRepository) {
+	s.cacheStore = store
+}
+
+// LoadCache fills the expansion cache with the most recent stored expansions
+// of the current versions, then deletes those of other versions and those
+// that no longer fit in the cache.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - int: number of expansions loaded.
+//   - error: non-nil if reading or pruning the store fails.
+func (s *QueryExpansionService) LoadCache(ctx context.Context) (int, error) {
+	if s.cache == nil || s.cacheStore == nil {
+		return 0, nil
+	}
+	versions := s.promptVersions()
+	entries, err := s.cacheStore.ListRecent(ctx, versions, s.cache.size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load query expansions: %w", err)
+	}
+	// Oldest first, so the newest end up most recently used
+	for i := len(entries) - 1; i >= 0; i-- {
+		s.cache.add(expansionCacheKey(entries[i].Version, entries[i].Query), entries[i].Expansion)
+	}
+
+	var before time.Time
+	if len(entries) == s.cache.size {
+		before = entries[len(entries)-1].UpdatedAt
+	}
+	pruned, err := s.cacheStore.Prune(ctx, versions, before)
+	if err != nil {
+		return len(entries), fmt.Errorf("failed to prune query expansions: %w", err)
+	}
+	logger.CtxInfo(ctx, "Query expansion cache loaded: versions=%v, loaded=%d, pruned=%d", versions, len(entries), pruned)
+	return len(entries), nil
+}
+
+// sharedExpansionTTL bounds how long an expansion stays in a shared store,
+// which unlike the in-memory cache has no size limit.
+const sharedExpansionTTL = 7 * 24 * time.Hour
+
+// cachedExpansion returns the cached expansion of query for the current
+// version of lang. A failing shared store is logged and counts as a miss.
+func (s *QueryExpansionService) cachedExpansion(ctx context.Context, query, lang string) (string, bool) {
+	key := expansionCacheKey(s.PromptVersion(lang), query)
+	if s.shared != nil {
+		value, ok, err := s.shared.Get(ctx, key)
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to read shared query expansion: query=%q, error=%v", logger.Query(query), err)
+		}
+		return string(value), ok && err == nil
+	}
+	if s.cache == nil {
+		return "", false
+	}
+	return s.cache.get(key)
+}
+
+// storeExpansion caches an expansion and writes it to the store. A failed
+// write is only logged; the expansion stays cached in memory.
+func (s *QueryExpansionService) storeExpansion(ctx context.Context, query, lang, expanded string) {
+	version := s.PromptVersion(lang)
+	switch {
+	case s.shared != nil:
+		if err := s.shared.Set(context.WithoutCancel(ctx), expansionCacheKey(version, query), []byte(expanded), sharedExpansionTTL); err != nil {
+			logger.CtxWarn(ctx, "Failed to write shared query expansion: query=%q, error=%v", logger.Query(query), err)
+		}
+	case s.cache != nil:
+		s.cache.add(expansionCacheKey(version, query), expanded)
+	default:
+		return
+	}
+	if s.cacheStore == nil {
+		return
+	}
+	entry := &domain.CachedExpansion{Version: version, Query: query, Expansion: expanded, UpdatedAt: time.Now()}
+	if err := s.cacheStore.Upsert(context.WithoutCancel(ctx), entry); err != nil {
+		logger.CtxWarn(ctx, "Failed to store query expansion: query=%q, error=%v", logger.Query(query), err)
+	}
+}
+
+// expansionCacheKey identifies an expansion by version and query.
+func expansionCacheKey(version, query string) string {
+	return "expansion:" + version + "\x00" + query
+}
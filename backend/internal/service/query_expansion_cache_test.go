@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestQueryExpansionCacheSurvivesRestartAndPromptChange(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	store := repository.NewQueryExpansionCacheRepository(db)
+
+	var calls atomic.Int32
+	newService := func(prompt string) *QueryExpansionService {
+		set := prompts.Default()
+		set.QueryExpansion = prompt
+		s := NewQueryExpansionService(&QueryExpansionConfig{
+			Enabled:   true,
+			Model:     "test-llm",
+			APIKey:    "test-key",
+			BaseURL:   "https://llm.test/v1",
+			Prompts:   set,
+			CacheSize: 10,
+			Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				calls.Add(1)
+				return jsonResponse(t, http.StatusOK, map[string]any{
+					"choices": []map[string]any{{"message": map[string]string{"content": "一只无语的猫，表示不想说话"}}},
+				}), nil
+			}),
+		})
+		s.SetCacheStore(store)
+		if _, err := s.LoadCache(ctx); err != nil {
+			t.Fatalf("LoadCache() error = %v", err)
+		}
+		return s
+	}
+
+	first := newService("prompt v1")
+	for i := 0; i < 2; i++ {
+		if expanded, err := first.Expand(ctx, "无语", ""); err != nil || expanded != "一只无语的猫，表示不想说话" {
+			t.Fatalf("Expand() = %q, %v, want the LLM expansion", expanded, err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("LLM calls = %d, want 1 with the second expansion cached", got)
+	}
+
+	restarted := newService("prompt v1")
+	if loaded := restarted.cache.len(); loaded != 1 {
+		t.Fatalf("cache after restart = %d entries, want 1", loaded)
+	}
+	tokenCh := make(chan string, 1)
+	if expanded, err := restarted.ExpandStream(ctx, "无语", "", tokenCh); err != nil || expanded != "一只无语的猫，表示不想说话" || <-tokenCh != expanded {
+		t.Fatalf("ExpandStream() after restart = %q, %v, want the stored expansion", expanded, err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("LLM calls after restart = %d, want 1", got)
+	}
+
+	edited := newService("prompt v2")
+	if loaded := edited.cache.len(); loaded != 0 {
+		t.Fatalf("cache after prompt change = %d entries, want 0", loaded)
+	}
+	if entries, _ := store.ListRecent(ctx, []string{restarted.PromptVersion(prompts.LangZh)}, 10); len(entries) != 0 {
+		t.Fatalf("stored expansions of the old prompt = %d, want them pruned", len(entries))
+	}
+	if _, err := edited.Expand(ctx, "无语", ""); err != nil || calls.Load() != 2 {
+		t.Fatalf("Expand() after prompt change = %v with %d LLM calls, want a fresh expansion", err, calls.Load())
+	}
+}
+
+func TestQueryExpansionUsesPromptOfLanguage(t *testing.T) {
+	t.Parallel()
+
+	var systemPrompts []string
+	s := NewQueryExpansionService(&QueryExpansionConfig{
+		Enabled:     true,
+		Model:       "test-llm",
+		APIKey:      "test-key",
+		BaseURL:     "https://llm.test/v1",
+		CacheSize:   10,
+		DefaultLang: prompts.LangEn,
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			var body struct {
+				Messages []struct {
+					Role    string `json:"role"`
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode chat request: %v", err)
+			}
+			systemPrompts = append(systemPrompts, body.Messages[0].Content)
+			return jsonResponse(t, http.StatusOK, map[string]any{
+				"choices": []map[string]any{{"message": map[string]string{"content": "a speechless cat"}}},
+			}), nil
+		}),
+	})
+	ctx := context.Background()
+	set := prompts.Default()
+
+	for _, lang := range []string{"", prompts.LangEn, prompts.LangZh, "fr"} {
+		if _, err := s.Expand(ctx, "speechless", lang); err != nil {
+			t.Fatalf("Expand(lang=%q) error = %v", lang, err)
+		}
+	}
+	// Empty, en and unsupported languages share the English expansion
+	if len(systemPrompts) != 2 {
+		t.Fatalf("LLM calls = %d, want 2 with each language cached apart", len(systemPrompts))
+	}
+	if systemPrompts[0] != set.QueryExpandEn || systemPrompts[1] != set.QueryExpansion {
+		t.Fatal("system prompts do not match the languages, want English then Chinese")
+	}
+	if s.PromptVersion(prompts.LangEn) == s.PromptVersion(prompts.LangZh) {
+		t.Fatal("PromptVersion() is the same for both languages")
+	}
+}
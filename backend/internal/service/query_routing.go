@@ -4,6 +4,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/timmy/emomo/internal/prompts"
 	"github.com/timmy/emomo/internal/repository"
 )
 
@@ -73,6 +74,16 @@ func buildHybridPlan(route QueryRoute, topK int) repository.HybridSearchPlan {
 	return plan
 }
 
+// routeRetrievalWeights adapts the profile fusion weights to the query route
+// the same way buildHybridPlan shifts prefetch limits: exact-match queries
+// (quotes, digits, short text) lean on the BM25 keyword route.
+func routeRetrievalWeights(route QueryRoute, weights RetrievalWeights) RetrievalWeights {
+	if route == QueryRouteExact {
+		weights.Keyword *= exactSparseBoost
+	}
+	return weights
+}
+
 func clampPrefetch(limit int) int {
 	if limit <= 0 {
 		return 1
@@ -102,7 +113,7 @@ func containsDigit(text string) bool {
 
 func containsIntentKeyword(text string) bool {
 	lower := strings.ToLower(text)
-	for _, word := range EmotionWords {
+	for _, word := range prompts.EmotionWords {
 		if word == "" {
 			continue
 		}
@@ -110,7 +121,7 @@ func containsIntentKeyword(text string) bool {
 			return true
 		}
 	}
-	for _, word := range InternetMemes {
+	for _, word := range prompts.InternetMemes {
 		if word == "" {
 			continue
 		}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RedescribeResult reports a regenerated description and the vectors written for it.
+type RedescribeResult struct {
+	MemeID        string   `json:"meme_id"`
+	VLMModel      string   `json:"vlm_model"`
+	PromptVersion string   `json:"prompt_version"`
+	Description   string   `json:"description"`
+	OCRText       string   `json:"ocr_text,omitempty"`
+	NeedsReview   bool     `json:"needs_review"`
+	Reembedded    []string `json:"reembedded"`      // "collection/vector_type" of every vector rewritten
+	Stale         []string `json:"stale,omitempty"` // Vectors in collections this server does not ingest into; run reembed --stale for them
+}
+
+// RedescribeMeme re-runs the VLM on the stored image of a meme, replaces the
+// description of the current VLM model, and rewrites the meme's vectors and
+// payloads in every ingest collection. Vectors in other collections still hold
+// the old text and are listed as stale.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - memeID: meme to re-describe.
+//
+// Returns:
+//   - *RedescribeResult: the new description and the rewritten vectors.
+//   - error: ErrMemeNotFound, ErrMemeNotStored, or a VLM, embedding or database error.
+func (s *IngestService) RedescribeMeme(ctx context.Context, memeID string) (*RedescribeResult, error) {
+	if s.descRepo == nil || s.vlm == nil {
+		return nil, errors.New("description repository not configured")
+	}
+	if len(s.indexes) == 0 {
+		return nil, fmt.Errorf("no ingest vector indexes configured")
+	}
+	meme, err := s.memeRepo.GetByID(ctx, memeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meme: %w", err)
+	}
+	if meme.StorageKey == "" {
+		return nil, ErrMemeNotStored
+	}
+
+	stillKey, stillFormat := stillObject(meme)
+	reader, err := s.storage.Download(ctx, stillKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from storage: %w", err)
+	}
+	imageData, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image data: %w", err)
+	}
+
+	description, ocrText, quality, err := s.describeImage(ctx, imageData, stillFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe meme: %w", err)
+	}
+
+	// Replace the description of this VLM model in place, or add one
+	desc, err := s.descRepo.GetByMD5AndModel(ctx, meme.MD5Hash, s.vlm.GetModel())
+	var newDescription *domain.MemeDescription
+	if err != nil || desc == nil {
+		desc = &domain.MemeDescription{
+			ID:        uuid.New().String(),
+			MemeID:    meme.ID,
+			MD5Hash:   meme.MD5Hash,
+			VLMModel:  s.vlm.GetModel(),
+			CreatedAt: time.Now(),
+		}
+		newDescription = desc
+	}
+	desc.Description = description
+	desc.OCRText = ocrText
+	desc.PromptVersion = s.vlm.PromptVersion()
+	if quality != nil {
+		s.applyDescriptionQuality(desc, *quality)
+	}
+	desc.SceneTags = nil
+	sceneTags := s.tagScenes(ctx, description, ocrText, desc, "")
+
+	cleanedDesc := s.cleaner.Clean(description)
+	compactDesc := compactDescription(cleanedDesc)
+	bm25Text := buildBM25Text(ocrText, compactDesc, meme.Tags)
+	desc.BM25Text = bm25Text
+	payload := &repository.MemePayload{
+		MemeID:         meme.ID,
+		SourceType:     meme.SourceType,
+		Category:       meme.Category,
+		Tags:           meme.Tags,
+		VLMDescription: description,
+		OCRText:        ocrText,
+		StorageURL:     s.storage.GetURL(meme.StorageKey),
+		PosterURL:      posterURLFor(s.storage, meme),
+		Colors:         paletteColorNames(meme.DominantColors),
+		Saturation:     meme.Saturation,
+		TextLang:       detectTextLanguage(ocrText),
+		SceneTags:      sceneTags,
+	}
+
+	written, err := s.upsertVectorIndexes(ctx, s.indexes, vectorUpsertInput{
+		MemeID:         meme.ID,
+		MD5Hash:        meme.MD5Hash,
+		DescriptionID:  desc.ID,
+		ImageURL:       s.storage.GetURL(stillKey),
+		ImageData:      imageData,
+		ImageMediaType: getContentType(stillFormat),
+		CaptionText: s.textWeights.CaptionText(
+			ocrText,
+			compactDesc,
+			meme.Category,
+			meme.Tags,
+			extractEmotionWords(description),
+		),
+		BM25Text: bm25Text,
+		Chunks:   s.chunking.split(cleanedDesc),
+		Payload:  payload,
+		Replace:  true,
+	})
+	if err != nil {
+		s.rollbackVectorPoints(ctx, written)
+		return nil, fmt.Errorf("failed to upsert vector indexes: %w", err)
+	}
+
+	if err := s.withTx(ctx, func(repos *repository.TxRepositories) error {
+		if newDescription != nil {
+			if err := repos.Descriptions.Create(ctx, newDescription); err != nil {
+				return fmt.Errorf("failed to save VLM description: %w", err)
+			}
+		} else {
+			if err := repos.Descriptions.UpdateRegenerated(ctx, desc); err != nil {
+				return fmt.Errorf("failed to save VLM description: %w", err)
+			}
+			// UpdateRegenerated clears the BM25 text
+			if err := saveBM25Text(ctx, repos, nil, desc.ID, "", bm25Text); err != nil {
+				return err
+			}
+		}
+		return saveVectorRecords(ctx, repos, written)
+	}); err != nil {
+		s.rollbackVectorPoints(ctx, written)
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+	s.deleteReplacedPoints(ctx, written)
+
+	result := &RedescribeResult{
+		MemeID:        meme.ID,
+		VLMModel:      desc.VLMModel,
+		PromptVersion: desc.PromptVersion,
+		Description:   description,
+		OCRText:       ocrText,
+		NeedsReview:   desc.NeedsReview,
+		Reembedded:    make([]string, 0, len(written)),
+	}
+	rewritten := make(map[string]bool, len(written))
+	for _, vector := range written {
+		key := vector.record.Collection + "/" + vector.record.VectorType
+		rewritten[key] = true
+		result.Reembedded = append(result.Reembedded, key)
+	}
+	if s.vectorRepo != nil {
+		vectors, err := s.vectorRepo.GetByMemeID(ctx, meme.ID)
+		if err != nil {
+			logger.CtxWarn(ctx, "Failed to list meme vectors: meme_id=%s, error=%v", meme.ID, err)
+		}
+		for _, vector := range vectors {
+			if key := vector.Collection + "/" + vector.VectorType; !rewritten[key] {
+				result.Stale = append(result.Stale, key)
+			}
+		}
+	}
+
+	logger.CtxInfo(ctx, "Re-described meme: meme_id=%s, vlm_model=%s, prompt_version=%s, reembedded=%v, stale=%v",
+		meme.ID, result.VLMModel, result.PromptVersion, result.Reembedded, result.Stale)
+	s.purgeCache(ctx, []string{MemeSurrogateKey(meme.ID)})
+	return result, nil
+}
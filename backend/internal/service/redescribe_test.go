@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestRedescribeMemeKeepsOldDescriptionWhenVectorWriteFails(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+
+	store := newMemoryObjectStorage()
+	if err := store.Upload(ctx, "ab/meme.png", bytes.NewReader(testPNG1x1), int64(len(testPNG1x1)), "image/png"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	vlmCalls := 0
+	vlm := NewVLMService(&VLMConfig{Model: "test-vlm", APIKey: "test-key", BaseURL: "https://vlm.test/v1"})
+	vlm.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		vlmCalls++
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "新的描述"}},
+			},
+		}), nil
+	}))
+
+	memeRepo := repository.NewMemeRepository(db)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+	meme := &domain.Meme{
+		ID: "meme-1", SourceType: "test", SourceID: "meme-1", StorageKey: "ab/meme.png",
+		Format: "png", MD5Hash: "md5-1", Status: domain.MemeStatusActive,
+	}
+	if err := memeRepo.Create(ctx, meme); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+	if err := descRepo.Create(ctx, &domain.MemeDescription{
+		ID: "desc-1", MemeID: meme.ID, MD5Hash: meme.MD5Hash, VLMModel: "test-vlm", Description: "旧的描述",
+	}); err != nil {
+		t.Fatalf("Create() description error = %v", err)
+	}
+
+	ingest := NewIngestService(memeRepo, repository.NewMemeVectorRepository(db), descRepo, nil, store, vlm, nil, nil,
+		&IngestConfig{
+			Workers:    1,
+			BatchSize:  1,
+			Collection: "broken_collection",
+			VectorIndexes: []IngestVectorIndex{
+				{VectorType: domain.MemeVectorTypeImage, Collection: "broken_collection", Embedding: fixedEmbeddingProvider{}},
+			},
+		},
+	)
+
+	if _, err := ingest.RedescribeMeme(ctx, "missing"); !errors.Is(err, ErrMemeNotFound) {
+		t.Fatalf("RedescribeMeme(missing) error = %v, want ErrMemeNotFound", err)
+	}
+	if _, err := ingest.RedescribeMeme(ctx, meme.ID); err == nil {
+		t.Fatal("RedescribeMeme() error = nil, want vector write failure")
+	}
+	if vlmCalls == 0 {
+		t.Fatal("RedescribeMeme() did not call the VLM")
+	}
+	desc, err := descRepo.GetByMD5AndModel(ctx, meme.MD5Hash, "test-vlm")
+	if err != nil {
+		t.Fatalf("GetByMD5AndModel() error = %v", err)
+	}
+	if desc.Description != "旧的描述" {
+		t.Fatalf("description after failed re-describe = %q, want the old one", desc.Description)
+	}
+}
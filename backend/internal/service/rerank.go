@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/llmclient"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/prompts"
+	"github.com/timmy/emomo/internal/usage"
+)
+
+const (
+	// RerankProviderJina scores candidates with the Jina rerank API (a cross-encoder).
+	RerankProviderJina = "jina"
+	// RerankProviderLLM scores candidates with a chat model and the rerank prompt.
+	RerankProviderLLM = "llm"
+
+	defaultRerankTopN    = 30
+	defaultRerankTimeout = 5 * time.Second
+
+	// llmRerankMaxScore is the top of the 0-10 scale the rerank prompt asks for.
+	llmRerankMaxScore = 10
+)
+
+// Reranker scores search candidates against a query.
+type Reranker interface {
+	// Rerank returns the relevance of each document to query, in document
+	// order; higher is more relevant.
+	Rerank(ctx context.Context, query string, documents []string) ([]float32, error)
+	// GetModel returns the model name being used.
+	GetModel() string
+}
+
+// RerankConfig holds configuration for the search rerank stage.
+type RerankConfig struct {
+	Enabled   bool
+	Provider  string // "jina" or "llm"
+	Model     string
+	APIKey    string
+	BaseURL   string
+	TopN      int               // Candidates sent to the reranker (default 30)
+	Timeout   time.Duration     // Per rerank call (default 5s)
+	Prompts   prompts.Provider  // nil uses the built-in prompts (llm only)
+	Transport http.RoundTripper // nil uses the default HTTP transport
+}
+
+// NewReranker creates the reranker of a configuration.
+// Parameters:
+//   - cfg: rerank configuration (nil or disabled returns no reranker).
+//
+// Returns:
+//   - Reranker: the configured reranker, or nil when reranking is disabled.
+//   - error: non-nil if the provider is unknown.
+func NewReranker(cfg *RerankConfig) (Reranker, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRerankTimeout
+	}
+
+	switch cfg.Provider {
+	case RerankProviderJina:
+		client := resty.New().
+			SetHeader("Authorization", "Bearer "+cfg.APIKey).
+			SetHeader("Content-Type", "application/json").
+			SetTimeout(timeout)
+		if cfg.Transport != nil {
+			client.SetTransport(cfg.Transport)
+		}
+		baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+		if baseURL == "" {
+			baseURL = jinaDefaultBaseURL
+		}
+		return &jinaReranker{client: client, baseURL: baseURL, model: cfg.Model}, nil
+	case RerankProviderLLM:
+		// No retries: reranking sits on the search path and falls back to
+		// the retrieval order.
+		return &llmReranker{
+			client: llmclient.New(llmclient.Config{
+				APIKey:    cfg.APIKey,
+				BaseURL:   cfg.BaseURL,
+				Timeout:   timeout,
+				Transport: cfg.Transport,
+			}),
+			model:   cfg.Model,
+			prompts: prompts.OrDefault(cfg.Prompts),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rerank provider: %s", cfg.Provider)
+	}
+}
+
+// SetReranker enables the rerank stage: the first topN retrieved results are
+// rescored by reranker and reordered before the response is cut to top_k.
+// Parameters:
+//   - reranker: reranker to use (nil disables reranking).
+//   - topN: candidates sent to the reranker (0 uses the default of 30).
+//
+// Returns: none.
+func (s *SearchService) SetReranker(reranker Reranker, topN int) {
+	if topN <= 0 {
+		topN = defaultRerankTopN
+	}
+	s.reranker = reranker
+	s.rerankTopN = topN
+}
+
+// rerankCandidates returns how many results to retrieve for a response of
+// topK, so the reranker and the popularity stage can promote results ranked
+// below topK.
+func (s *SearchService) rerankCandidates(topK int) int {
+	candidates := topK
+	if s.reranker != nil {
+		candidates = max(candidates, s.rerankTopN)
+	}
+	if s.popularityEnabled() {
+		candidates = max(candidates, s.popularity.TopN)
+	}
+	return candidates
+}
+
+// rerankResults reorders the first rerankTopN results by reranker relevance,
+// then the first popularity top_n by popularity, and cuts them to topK.
+// Results past them follow in retrieval order. When the reranker fails, the
+// retrieval order is kept.
+func (s *SearchService) rerankResults(ctx context.Context, query string, results []SearchResult, topK int) []SearchResult {
+	if s.reranker != nil && len(results) > 1 {
+		head := results[:min(len(results), s.rerankTopN)]
+		documents := make([]string, len(head))
+		for i, result := range head {
+			documents[i] = rerankDocument(result)
+		}
+
+		start := time.Now()
+		scores, err := s.reranker.Rerank(ctx, query, documents)
+		if err != nil {
+			logger.CtxWarn(ctx, "Rerank failed, keeping retrieval order: model=%s, candidates=%d, error=%v",
+				s.reranker.GetModel(), len(head), err)
+		} else {
+			for i := range head {
+				score := scores[i]
+				head[i].RerankScore = &score
+			}
+			sort.SliceStable(head, func(i, j int) bool { return *head[i].RerankScore > *head[j].RerankScore })
+			logger.CtxInfo(ctx, "Reranked results: model=%s, candidates=%d, duration_ms=%d",
+				s.reranker.GetModel(), len(head), time.Since(start).Milliseconds())
+		}
+	}
+	s.boostPopular(ctx, results[:min(len(results), s.popularity.TopN)])
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
+// rerankDocument is the text a candidate is scored by: its VLM description
+// and the text on the meme.
+func rerankDocument(result SearchResult) string {
+	document := result.Description
+	if result.OCRText != "" {
+		document += "\n文字：" + result.OCRText
+	}
+	return document
+}
+
+// =============================================================================
+// Jina Reranker
+// =============================================================================
+
+// jinaReranker scores candidates with the Jina rerank API.
+type jinaReranker struct {
+	client  *resty.Client
+	baseURL string
+	model   string
+}
+
+type jinaRerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	ReturnDocuments bool     `json:"return_documents"`
+}
+
+type jinaRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float32 `json:"relevance_score"`
+	} `json:"results"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// GetModel returns the model name being used.
+func (r *jinaReranker) GetModel() string {
+	return r.model
+}
+
+// Rerank scores documents with the Jina rerank API. Its tokens count as
+// embedding tokens in the API key usage.
+func (r *jinaReranker) Rerank(ctx context.Context, query string, documents []string) ([]float32, error) {
+	var resp jinaRerankResponse
+	httpResp, err := r.client.R().
+		SetContext(ctx).
+		SetBody(jinaRerankRequest{Model: r.model, Query: query, Documents: documents}).
+		SetResult(&resp).
+		Post(r.baseURL + "/rerank")
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Jina rerank API: %w", err)
+	}
+	if httpResp.StatusCode() != http.StatusOK {
+		if resp.Detail != "" {
+			return nil, fmt.Errorf("Jina rerank API error: %s", resp.Detail)
+		}
+		return nil, fmt.Errorf("Jina rerank API error: status %d", httpResp.StatusCode())
+	}
+	usage.AddEmbeddingTokens(ctx, resp.Usage.TotalTokens)
+
+	if len(resp.Results) != len(documents) {
+		return nil, fmt.Errorf("unexpected number of rerank scores: got %d, expected %d", len(resp.Results), len(documents))
+	}
+	scores := make([]float32, len(documents))
+	for _, result := range resp.Results {
+		if result.Index < 0 || result.Index >= len(scores) {
+			return nil, fmt.Errorf("rerank result index %d out of range", result.Index)
+		}
+		scores[result.Index] = result.RelevanceScore
+	}
+	return scores, nil
+}
+
+// =============================================================================
+// LLM Reranker
+// =============================================================================
+
+// llmReranker scores candidates with a chat model, scaled to 0-1.
+type llmReranker struct {
+	client  *llmclient.Client
+	model   string
+	prompts prompts.Provider
+}
+
+// GetModel returns the model name being used.
+func (r *llmReranker) GetModel() string {
+	return r.model
+}
+
+// Rerank asks the model for a 0-10 score per numbered candidate.
+func (r *llmReranker) Rerank(ctx context.Context, query string, documents []string) ([]float32, error) {
+	var input strings.Builder
+	input.WriteString("查询：" + query + "\n\n候选：")
+	for i, document := range documents {
+		input.WriteString("\n[" + strconv.Itoa(i+1) + "] " + strings.Join(strings.Fields(document), " "))
+	}
+
+	resp, err := r.client.Chat(ctx, llmclient.ChatRequest{
+		Model: r.model,
+		Messages: []llmclient.Message{
+			{Role: "system", Content: r.prompts.Prompts().Rerank},
+			{Role: "user", Content: input.String()},
+		},
+		MaxTokens:   8 * len(documents),
+		Temperature: llmclient.Float32(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rerank API call failed: %w", err)
+	}
+	return parseRerankScores(resp.Content, len(documents))
+}
+
+// parseRerankScores reads the JSON array of 0-10 scores from a model reply
+// and scales them to 0-1.
+func parseRerankScores(reply string, count int) ([]float32, error) {
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("rerank reply has no score array: %q", reply)
+	}
+	var raw []float32
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank scores: %w", err)
+	}
+	if len(raw) != count {
+		return nil, fmt.Errorf("unexpected number of rerank scores: got %d, expected %d", len(raw), count)
+	}
+	scores := make([]float32, count)
+	for i, score := range raw {
+		scores[i] = min(max(score, 0), llmRerankMaxScore) / llmRerankMaxScore
+	}
+	return scores, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stubReranker scores documents by their length, or fails.
+type stubReranker struct {
+	err       error
+	documents []string
+}
+
+func (r *stubReranker) Rerank(_ context.Context, _ string, documents []string) ([]float32, error) {
+	r.documents = documents
+	if r.err != nil {
+		return nil, r.err
+	}
+	scores := make([]float32, len(documents))
+	for i, document := range documents {
+		scores[i] = float32(len([]rune(document)))
+	}
+	return scores, nil
+}
+
+func (r *stubReranker) GetModel() string {
+	return "stub"
+}
+
+func TestRerankResultsReordersTopCandidates(t *testing.T) {
+	t.Parallel()
+
+	candidates := func() []SearchResult {
+		return []SearchResult{
+			{ID: "a", Description: "猫"},
+			{ID: "b", Description: "一只无语的猫", OCRText: "无语"},
+			{ID: "c", Description: "狗狗"},
+			{ID: "d", Description: "不在精排范围内的很长很长的描述"},
+		}
+	}
+	reranker := &stubReranker{}
+	search := &SearchService{}
+	search.SetReranker(reranker, 3)
+	if got := search.rerankCandidates(2); got != 3 {
+		t.Fatalf("rerankCandidates(2) = %d, want top_n 3", got)
+	}
+
+	results := search.rerankResults(context.Background(), "无语", candidates(), 3)
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	if strings.Join(ids, ",") != "b,c,a" {
+		t.Fatalf("reranked ids = %v, want [b c a]", ids)
+	}
+	if results[0].RerankScore == nil || *results[0].RerankScore != 12 {
+		t.Fatalf("first result = %+v, want b with rerank_score 12", results[0])
+	}
+	if len(reranker.documents) != 3 || reranker.documents[1] != "一只无语的猫\n文字：无语" {
+		t.Fatalf("reranked documents = %q, want the top 3 with OCR text", reranker.documents)
+	}
+
+	reranker.err = errors.New("timeout")
+	results = search.rerankResults(context.Background(), "无语", candidates(), 2)
+	if len(results) != 2 || results[0].ID != "a" || results[0].RerankScore != nil {
+		t.Fatalf("results after rerank failure = %+v, want retrieval order without scores", results)
+	}
+}
+
+func TestNewRerankerScoresCandidates(t *testing.T) {
+	t.Parallel()
+
+	jina, err := NewReranker(&RerankConfig{
+		Enabled:  true,
+		Provider: RerankProviderJina,
+		Model:    "jina-reranker-v2-base-multilingual",
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if r.URL.String() != jinaDefaultBaseURL+"/rerank" {
+				t.Errorf("rerank URL = %s, want %s/rerank", r.URL, jinaDefaultBaseURL)
+			}
+			var body jinaRerankRequest
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Query != "无语" || len(body.Documents) != 2 {
+				t.Errorf("rerank request = %+v, %v, want the query and 2 documents", body, err)
+			}
+			return jsonResponse(t, http.StatusOK, map[string]any{
+				"results": []map[string]any{{"index": 1, "relevance_score": 0.9}, {"index": 0, "relevance_score": 0.2}},
+			}), nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewReranker(jina) error = %v", err)
+	}
+	scores, err := jina.Rerank(context.Background(), "无语", []string{"狗狗", "无语的猫"})
+	if err != nil || len(scores) != 2 || scores[0] != 0.2 || scores[1] != 0.9 {
+		t.Fatalf("Rerank() = %v, %v, want [0.2 0.9] in document order", scores, err)
+	}
+
+	llm, err := NewReranker(&RerankConfig{
+		Enabled:  true,
+		Provider: RerankProviderLLM,
+		Model:    "test-llm",
+		BaseURL:  "https://llm.test/v1",
+		Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			return jsonResponse(t, http.StatusOK, map[string]any{
+				"choices": []map[string]any{{"message": map[string]string{"content": "评分：[2, 12]"}}},
+			}), nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewReranker(llm) error = %v", err)
+	}
+	scores, err = llm.Rerank(context.Background(), "无语", []string{"狗狗", "无语的猫"})
+	if err != nil || len(scores) != 2 || scores[0] != 0.2 || scores[1] != 1 {
+		t.Fatalf("Rerank() = %v, %v, want [0.2 1] clamped to 0-1", scores, err)
+	}
+
+	if _, err := parseRerankScores("[1, 2]", 3); err == nil {
+		t.Fatal("parseRerankScores() with a missing score returned nil error")
+	}
+	if reranker, err := NewReranker(&RerankConfig{Enabled: true, Provider: "unknown"}); err == nil || reranker != nil {
+		t.Fatalf("NewReranker(unknown) = %v, %v, want an error", reranker, err)
+	}
+}
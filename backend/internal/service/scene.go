@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/timmy/emomo/internal/llmclient"
+	"github.com/timmy/emomo/internal/prompts"
+)
+
+const (
+	// maxSceneTags caps the tags kept per meme so the filter stays selective.
+	maxSceneTags = 3
+
+	defaultSceneTagMaxTokens = 20
+)
+
+// SceneTagger assigns scene tags with a cheap text-only LLM call.
+type SceneTagger struct {
+	client    *llmclient.Client
+	model     string
+	maxTokens int
+	prompts   prompts.Provider
+	enabled   bool
+}
+
+// SceneTaggerConfig holds configuration for the scene tagger.
+type SceneTaggerConfig struct {
+	Enabled   bool
+	Model     string
+	APIKey    string
+	BaseURL   string
+	MaxTokens int
+	Prompts   prompts.Provider  // nil uses the built-in prompts
+	Transport http.RoundTripper // nil uses the default HTTP transport
+}
+
+// NewSceneTagger creates a scene tagger.
+// Parameters:
+//   - cfg: tagger configuration (nil or disabled returns a no-op tagger).
+//
+// Returns:
+//   - *SceneTagger: initialized tagger.
+func NewSceneTagger(cfg *SceneTaggerConfig) *SceneTagger {
+	if cfg == nil || !cfg.Enabled {
+		return &SceneTagger{enabled: false}
+	}
+
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultSceneTagMaxTokens
+	}
+
+	return &SceneTagger{
+		client: llmclient.New(llmclient.Config{
+			APIKey:     cfg.APIKey,
+			BaseURL:    cfg.BaseURL,
+			Timeout:    15 * time.Second,
+			MaxRetries: 1,
+			Transport:  cfg.Transport,
+		}),
+		model:     cfg.Model,
+		maxTokens: maxTokens,
+		prompts:   prompts.OrDefault(cfg.Prompts),
+		enabled:   true,
+	}
+}
+
+// IsEnabled returns whether scene tagging is enabled.
+// Parameters: none.
+// Returns:
+//   - bool: true when the tagger calls the LLM.
+func (t *SceneTagger) IsEnabled() bool {
+	return t != nil && t.enabled
+}
+
+// Tag classifies a meme into scene tags from its description and overlaid text.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - description: VLM description of the meme.
+//   - ocrText: text extracted from the meme (may be empty).
+//
+// Returns:
+//   - []string: scene tags from prompts.SceneTags (empty when none apply or the tagger is disabled).
+//   - error: non-nil if the API request fails.
+func (t *SceneTagger) Tag(ctx context.Context, description, ocrText string) ([]string, error) {
+	if !t.IsEnabled() || strings.TrimSpace(description+ocrText) == "" {
+		return nil, nil
+	}
+
+	input := "描述：" + compactDescription(description)
+	if ocrText != "" {
+		input += "\n文字：" + ocrText
+	}
+	resp, err := t.client.Chat(ctx, llmclient.ChatRequest{
+		Model: t.model,
+		Messages: []llmclient.Message{
+			{Role: "system", Content: t.prompts.Prompts().SceneTag},
+			{Role: "user", Content: input},
+		},
+		MaxTokens:   t.maxTokens,
+		Temperature: llmclient.Float32(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scene tag API call failed: %w", err)
+	}
+
+	return parseSceneTags(resp.Content), nil
+}
+
+// parseSceneTags keeps the known scene tags from a model reply, in reply order.
+func parseSceneTags(reply string) []string {
+	fields := strings.FieldsFunc(reply, func(r rune) bool {
+		return strings.ContainsRune("、,，/ \n\t;；", r)
+	})
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if !isSceneTag(field) || seen[field] {
+			continue
+		}
+		seen[field] = true
+		tags = append(tags, field)
+		if len(tags) == maxSceneTags {
+			break
+		}
+	}
+	return tags
+}
+
+func isSceneTag(tag string) bool {
+	for _, known := range prompts.SceneTags {
+		if tag == known {
+			return true
+		}
+	}
+	return false
+}
+
+// detectQueryScene returns the scene named in a query (e.g. "考试前的我"), or "".
+func detectQueryScene(query string) string {
+	for _, tag := range prompts.SceneTags {
+		if strings.Contains(query, tag) {
+			return tag
+		}
+	}
+	return ""
+}
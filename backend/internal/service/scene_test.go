@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSceneTags(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]string{
+		"考试、熬夜":       {"考试", "熬夜"},
+		"恋爱, 社交，恋爱":   {"恋爱", "社交"},
+		"工作/学习/考试/游戏": {"工作", "学习", "考试"},
+		"无":           nil,
+		"上班摸鱼":        nil,
+	}
+	for reply, want := range tests {
+		if got := parseSceneTags(reply); !reflect.DeepEqual(got, want) {
+			t.Fatalf("parseSceneTags(%q) = %v, want %v", reply, got, want)
+		}
+	}
+}
+
+func TestSceneTaggerTag(t *testing.T) {
+	t.Parallel()
+
+	var request string
+	tagger := NewSceneTagger(&SceneTaggerConfig{
+		Enabled: true,
+		Model:   "test-model",
+		APIKey:  "test-key",
+		BaseURL: "https://llm.test/v1",
+	})
+	tagger.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(r.Body)
+		request = string(body)
+		return jsonResponse(t, http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "考试、熬夜、其他"}},
+			},
+		}), nil
+	}))
+
+	tags, err := tagger.Tag(context.Background(), "一只熊猫头趴在书堆上", "明天考试")
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if want := []string{"考试", "熬夜"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("Tag() = %v, want %v", tags, want)
+	}
+	if !strings.Contains(request, `"max_tokens":20`) {
+		t.Fatalf("request = %s, want default max_tokens 20", request)
+	}
+
+	disabled := NewSceneTagger(nil)
+	if tags, err := disabled.Tag(context.Background(), "描述", ""); err != nil || tags != nil {
+		t.Fatalf("disabled Tag() = %v, %v, want nil, nil", tags, err)
+	}
+}
+
+func TestBuildSearchFiltersDetectsQueryScene(t *testing.T) {
+	t.Parallel()
+
+	// Detection is off until scene tagging is enabled.
+	search := &SearchService{}
+	filters, err := search.buildSearchFilters(&SearchRequest{Query: "考试前的我"})
+	if err != nil {
+		t.Fatalf("buildSearchFilters() error = %v", err)
+	}
+	if filters.Scene != nil {
+		t.Fatalf("Scene = %q, want no scene filter", *filters.Scene)
+	}
+
+	search.SetQuerySceneDetection(true)
+	req := &SearchRequest{Query: "考试前的我"}
+	filters, err = search.buildSearchFilters(req)
+	if err != nil {
+		t.Fatalf("buildSearchFilters() error = %v", err)
+	}
+	if filters.Scene == nil || *filters.Scene != "考试" {
+		t.Fatalf("Scene = %v, want 考试", filters.Scene)
+	}
+	if got := stringValue(req.Scene); got != "考试" {
+		t.Fatalf("req.Scene = %q, want 考试", got)
+	}
+
+	unknown := "上班"
+	if _, err := search.buildSearchFilters(&SearchRequest{Query: "猫", Scene: &unknown}); err == nil {
+		t.Fatal("buildSearchFilters(上班) error = nil, want unknown scene")
+	}
+}
@@ -3,20 +3,31 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/i18n"
 	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/metrics"
 	"github.com/timmy/emomo/internal/repository"
 	"github.com/timmy/emomo/internal/storage"
+	"golang.org/x/sync/singleflight"
 )
 
+// defaultGroupSize is the number of results per group when a search groups
+// results without a group size.
+const defaultGroupSize = 2
+
 // SearchConfig holds configuration for search service.
 type SearchConfig struct {
-	ScoreThreshold    float32
-	DefaultCollection string // Default search collection key (embedding config name)
-	DefaultProfile    string
-	Retrieval         RetrievalConfig
+	ScoreThreshold     float32
+	CategoryThresholds map[string]float32 // Per-category overrides of ScoreThreshold
+	DefaultCollection  string             // Default search collection key (embedding config name)
+	DefaultProfile     string
+	Retrieval          RetrievalConfig
 }
 
 // CollectionConfig holds configuration for a single collection.
@@ -48,17 +59,37 @@ type SearchProfileConfig struct {
 
 // SearchService handles meme search operations.
 type SearchService struct {
-	memeRepo          *repository.MemeRepository
-	memeDescRepo      *repository.MemeDescriptionRepository
-	defaultQdrantRepo *repository.QdrantRepository
-	defaultEmbedding  EmbeddingProvider
-	queryExpansion    *QueryExpansionService
-	storage           storage.ObjectStorage
-	logger            *logger.Logger
-	scoreThreshold    float32
-	defaultCollection string
-	defaultProfile    string
-	retrieval         RetrievalConfig
+	memeRepo           *repository.MemeRepository
+	memeDescRepo       *repository.MemeDescriptionRepository
+	vectorRepo         *repository.MemeVectorRepository
+	coverRepo          *repository.CategoryCoverRepository
+	statsRepo          *repository.StatsSnapshotRepository
+	defaultQdrantRepo  *repository.QdrantRepository
+	defaultEmbedding   EmbeddingProvider
+	queryExpansion     *QueryExpansionService
+	storage            storage.ObjectStorage
+	logger             *logger.Logger
+	scoreThreshold     float32
+	categoryThresholds map[string]float32
+	defaultCollection  string
+	defaultProfile     string
+	retrieval          RetrievalConfig
+	sceneFromQuery     bool         // Filter by a scene named in the query when no scene is requested
+	cache              *searchCache // Optional in-memory caches (nil disables)
+	vlm                *VLMService  // Describes uploads for image search (nil disables)
+	imageSearch        ImageSearchConfig
+	shadow             *shadowSearch // Mirrors sampled searches to a canary (nil disables)
+	reranker           Reranker      // Reorders the top candidates (nil disables)
+	rerankTopN         int
+	activityRepo       *repository.MemeActivityRepository // Daily impressions, clicks and downloads (nil disables)
+	popularity         PopularityConfig
+	activity           *activityBuffer     // Activity waiting for the next flush (nil writes it on the request path)
+	chunking           DescriptionChunking // Caption points per meme, to retrieve enough distinct memes
+
+	// Lazy poster frames for GET /memes/:id/still
+	converter    MediaConverter
+	posterOffset time.Duration
+	stills       singleflight.Group
 
 	// Multi-collection support: collection name -> config
 	collections map[string]*CollectionConfig
@@ -89,29 +120,32 @@ func NewSearchService(
 	cfg *SearchConfig,
 ) *SearchService {
 	var threshold float32
+	var categoryThresholds map[string]float32
 	var defaultCollection string
 	var defaultProfile string
 	retrieval := defaultRetrievalConfig()
 	if cfg != nil {
 		threshold = cfg.ScoreThreshold
+		categoryThresholds = cfg.CategoryThresholds
 		defaultCollection = cfg.DefaultCollection
 		defaultProfile = cfg.DefaultProfile
 		retrieval = normalizeRetrievalConfig(cfg.Retrieval)
 	}
 	return &SearchService{
-		memeRepo:          memeRepo,
-		memeDescRepo:      memeDescRepo,
-		defaultQdrantRepo: qdrantRepo,
-		defaultEmbedding:  embedding,
-		queryExpansion:    queryExpansion,
-		storage:           objectStorage,
-		logger:            log,
-		scoreThreshold:    threshold,
-		defaultCollection: defaultCollection,
-		defaultProfile:    defaultProfile,
-		retrieval:         retrieval,
-		collections:       make(map[string]*CollectionConfig),
-		profiles:          make(map[string]*SearchProfileConfig),
+		memeRepo:           memeRepo,
+		memeDescRepo:       memeDescRepo,
+		defaultQdrantRepo:  qdrantRepo,
+		defaultEmbedding:   embedding,
+		queryExpansion:     queryExpansion,
+		storage:            objectStorage,
+		logger:             log,
+		scoreThreshold:     threshold,
+		categoryThresholds: categoryThresholds,
+		defaultCollection:  defaultCollection,
+		defaultProfile:     defaultProfile,
+		retrieval:          retrieval,
+		collections:        make(map[string]*CollectionConfig),
+		profiles:           make(map[string]*SearchProfileConfig),
 	}
 }
 
@@ -129,6 +163,25 @@ func (s *SearchService) RegisterCollection(name string, qdrantRepo *repository.Q
 	}
 }
 
+// SetVectorRepository sets the meme_vectors repository used for per-collection stats.
+// Parameters:
+//   - vectorRepo: repository for meme vectors (nil disables collection stats).
+//
+// Returns: none.
+func (s *SearchService) SetVectorRepository(vectorRepo *repository.MemeVectorRepository) {
+	s.vectorRepo = vectorRepo
+}
+
+// SetQuerySceneDetection enables filtering by a scene tag named in the query.
+// Only enable it when ingest runs the scene tagger, otherwise such queries match nothing.
+// Parameters:
+//   - enabled: whether scene words in queries become scene filters.
+//
+// Returns: none.
+func (s *SearchService) SetQuerySceneDetection(enabled bool) {
+	s.sceneFromQuery = enabled
+}
+
 // RegisterProfile registers a multi-route search profile.
 func (s *SearchService) RegisterProfile(
 	name string,
@@ -198,8 +251,11 @@ func (s *SearchService) resolveCollection(name string) (*repository.QdrantReposi
 	}
 
 	cfg, ok := s.collections[name]
+	if !ok && name == s.defaultCollection {
+		return s.defaultQdrantRepo, s.defaultEmbedding, s.defaultCollection, nil
+	}
 	if !ok {
-		return nil, nil, "", fmt.Errorf("unknown collection: %s", name)
+		return nil, nil, "", fmt.Errorf("%w: %s", ErrUnknownCollection, name)
 	}
 
 	return cfg.QdrantRepo, cfg.Embedding, name, nil
@@ -262,11 +318,39 @@ func (s *SearchService) resolveRequestedProfile(req *SearchRequest) (*SearchProf
 		return profile, name, true, nil
 	}
 	if req.Profile != "" {
-		return nil, "", false, fmt.Errorf("unknown profile: %s", req.Profile)
+		return nil, "", false, fmt.Errorf("%w: %s", ErrUnknownProfile, req.Profile)
 	}
 	return nil, "", false, nil
 }
 
+// meetsThreshold reports whether a dense score passes the threshold of its
+// category, falling back to the global threshold. Zero keeps every result.
+func (s *SearchService) meetsThreshold(score float32, category string) bool {
+	threshold := s.scoreThreshold
+	if override, ok := s.categoryThresholds[category]; ok {
+		threshold = override
+	}
+	return threshold <= 0 || score >= threshold
+}
+
+// reserveExpansion reports whether query should be expanded and takes an
+// expansion slot for it. Exact-match routes never expand; when all slots stay
+// busy for the queue timeout the search degrades to the fast path. Cached
+// expansions need no LLM call and take no slot.
+func (s *SearchService) reserveExpansion(ctx context.Context, route QueryRoute, query, lang string) (func(), bool) {
+	if route == QueryRouteExact || s.queryExpansion == nil || !s.queryExpansion.IsEnabled() {
+		return nil, false
+	}
+	if _, ok := s.queryExpansion.cachedExpansion(ctx, query, lang); ok {
+		return func() {}, true
+	}
+	release, ok := s.queryExpansion.Acquire(ctx)
+	if !ok {
+		logger.CtxWarn(ctx, "Query expansion limit reached, using original query: query=%q", logger.Query(query))
+	}
+	return release, ok
+}
+
 // log returns a logger from context if available, otherwise returns the default logger
 func (s *SearchService) log(ctx context.Context) *logger.Logger {
 	if l := logger.FromContext(ctx); l != nil {
@@ -277,24 +361,38 @@ func (s *SearchService) log(ctx context.Context) *logger.Logger {
 
 // SearchRequest represents a text search request.
 type SearchRequest struct {
-	Query      string  `json:"query" binding:"required"`
-	TopK       int     `json:"top_k"`
-	Category   *string `json:"category,omitempty"`
-	SourceType *string `json:"source_type,omitempty"`
-	Collection string  `json:"collection,omitempty"` // Optional: specify which collection to search
-	Profile    string  `json:"profile,omitempty"`    // Optional: specify multi-route search profile
+	Query      string  `json:"query" form:"query" binding:"required"`
+	TopK       int     `json:"top_k" form:"top_k"`
+	Category   *string `json:"category,omitempty" form:"category"`
+	SourceType *string `json:"source_type,omitempty" form:"source_type"`
+	Color      *string `json:"color,omitempty" form:"color"`           // Optional: named color, "monochrome" or "colorful"; detected from the query when unset
+	TextLang   *string `json:"text_lang,omitempty" form:"text_lang"`   // Optional: language of the text on the meme (zh, en, ja)
+	Scene      *string `json:"scene,omitempty" form:"scene"`           // Optional: scene tag such as 工作 or 考试
+	Collection string  `json:"collection,omitempty" form:"collection"` // Optional: specify which collection to search
+	Profile    string  `json:"profile,omitempty" form:"profile"`       // Optional: specify multi-route search profile
+	GroupBy    string  `json:"group_by,omitempty" form:"group_by"`     // Optional: "category" caps the results sharing one value
+	GroupSize  int     `json:"group_size,omitempty" form:"group_size"` // Results per group when GroupBy is set (default 2)
+	Lang       string  `json:"lang,omitempty" form:"lang"`             // Optional: language of the query (zh, en), picks the expansion prompt
+	NoCache    bool    `json:"-" form:"-"`                             // Skip the result cache (X-Search-Cache: bypass)
+
+	expansion string // Expanded query reused from another search instead of calling the LLM
 }
 
 // SearchResult represents a single search result.
 type SearchResult struct {
 	ID          string   `json:"id"`
 	URL         string   `json:"url"`
+	PosterURL   string   `json:"poster_url,omitempty"` // Static frame for animated memes; render this in list views
 	Score       float32  `json:"score"`
 	Description string   `json:"description"`
+	OCRText     string   `json:"ocr_text,omitempty"` // Text the VLM read from the image
 	Category    string   `json:"category"`
 	Tags        []string `json:"tags"`
 	Width       int      `json:"width,omitempty"`
 	Height      int      `json:"height,omitempty"`
+	Colors      []string `json:"dominant_colors,omitempty"` // Hex palette ordered by pixel share
+	RerankScore *float32 `json:"rerank_score,omitempty"`    // Reranker relevance when the rerank stage scored this result
+	Popularity  *float32 `json:"popularity,omitempty"`      // Popularity prior, 0-1, when the popularity stage ranked this result
 }
 
 // SearchResponse represents the search response.
@@ -305,6 +403,9 @@ type SearchResponse struct {
 	ExpandedQuery string         `json:"expanded_query,omitempty"`
 	Collection    string         `json:"collection,omitempty"` // Which collection was searched
 	Profile       string         `json:"profile,omitempty"`    // Which profile was searched
+	Color         string         `json:"color,omitempty"`      // Color filter that was applied
+	Scene         string         `json:"scene,omitempty"`      // Scene filter that was applied
+	Cached        bool           `json:"cached,omitempty"`     // Served from the result cache
 }
 
 // SearchProgress represents a progress update during streaming search.
@@ -323,8 +424,53 @@ type SearchProgress struct {
 //
 // Returns:
 //   - *SearchResponse: search results and metadata.
-//   - error: non-nil if search fails.
+//   - error: ErrUnknownCollection or ErrUnknownProfile for an unregistered name, or a search error.
 func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	if err := s.ValidateSearchTarget(req); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := s.cachedSearch(ctx, req, func() (*SearchResponse, error) {
+		return s.textSearch(ctx, req, true)
+	})
+	recordSearchMetrics(req, resp, time.Since(start), err)
+	if err == nil {
+		s.recordQuery(ctx)
+		s.recordImpressions(ctx, resp)
+		s.logSearch(ctx, req, resp, time.Since(start))
+		s.mirrorSearch(ctx, req, resp, time.Since(start))
+	}
+	return resp, err
+}
+
+// logSearch logs a served text search for analytics: the query as allowed by
+// search.query_logging, its length and route, the result count and latency.
+func (s *SearchService) logSearch(ctx context.Context, req *SearchRequest, resp *SearchResponse, latency time.Duration) {
+	logger.With(logger.Fields{
+		logger.FieldDurationMs: latency.Milliseconds(),
+		logger.FieldCount:      resp.Total,
+	}).Info(ctx, "Search completed: query=%q, query_runes=%d, route=%s, expanded=%t, cached=%t",
+		logger.Query(req.Query), len([]rune(req.Query)), classifyQuery(req.Query), resp.ExpandedQuery != "", resp.Cached)
+}
+
+// recordSearchMetrics counts a text search by route as search.requests, with
+// its latency as search.duration, or as search.errors when it failed.
+func recordSearchMetrics(req *SearchRequest, resp *SearchResponse, latency time.Duration, err error) {
+	route := metrics.Tag("route", classifyQuery(req.Query))
+	if err != nil {
+		metrics.Count("search.errors", 1, route)
+		return
+	}
+	tags := []string{route, metrics.Tag("cached", resp.Cached), metrics.Tag("expanded", resp.ExpandedQuery != "")}
+	metrics.Count("search.requests", 1, tags...)
+	metrics.Timing("search.duration", latency, tags...)
+	if resp.Total == 0 {
+		metrics.Count("search.empty", 1, route)
+	}
+}
+
+// textSearch runs a search; expand allows LLM query expansion.
+func (s *SearchService) textSearch(ctx context.Context, req *SearchRequest, expand bool) (*SearchResponse, error) {
 	// Set defaults
 	if req.TopK <= 0 {
 		req.TopK = 20
@@ -335,7 +481,7 @@ func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*Se
 
 	originalQuery := req.Query
 	route := classifyQuery(originalQuery)
-	expandedQuery := ""
+	expandedQuery := req.expansion
 
 	// Inject search tracing fields into context
 	ctx = logger.WithFields(ctx, logger.Fields{
@@ -344,14 +490,17 @@ func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*Se
 	})
 
 	// Expand query using LLM if enabled (skip exact-match routes)
-	if route != QueryRouteExact && s.queryExpansion != nil && s.queryExpansion.IsEnabled() {
-		expanded, err := s.queryExpansion.Expand(ctx, req.Query)
-		if err != nil {
-			logger.CtxWarn(ctx, "Query expansion failed, using original query: query=%q, error=%v",
-				req.Query, err)
-		} else if expanded != req.Query {
-			expandedQuery = expanded
-			logger.CtxInfo(ctx, "Query expanded: original=%q, expanded=%q", req.Query, expanded)
+	if expand && expandedQuery == "" {
+		if release, ok := s.reserveExpansion(ctx, route, req.Query, req.Lang); ok {
+			expanded, err := s.queryExpansion.Expand(ctx, req.Query, req.Lang)
+			release()
+			if err != nil {
+				logger.CtxWarn(ctx, "Query expansion failed, using original query: query=%q, error=%v",
+					logger.Query(req.Query), err)
+			} else if expanded != req.Query {
+				expandedQuery = expanded
+				logger.CtxInfo(ctx, "Query expanded: original=%q, expanded=%q", logger.Query(req.Query), logger.Query(expanded))
+			}
 		}
 	}
 
@@ -364,7 +513,7 @@ func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*Se
 	if profile, profileName, ok, err := s.resolveRequestedProfile(req); err != nil {
 		return nil, err
 	} else if ok {
-		return s.searchProfile(ctx, req, profileName, profile, originalQuery, queryForEmbedding, expandedQuery)
+		return s.searchProfile(ctx, req, route, profileName, profile, originalQuery, queryForEmbedding, expandedQuery)
 	}
 
 	qdrantRepo, embedding, collectionName, err := s.resolveCollection(req.Collection)
@@ -373,55 +522,52 @@ func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*Se
 	}
 
 	logger.CtxInfo(ctx, "Performing text search: query=%q, query_for_embedding=%q, top_k=%d, collection=%s, route=%s",
-		originalQuery, queryForEmbedding, req.TopK, collectionName, route)
+		logger.Query(originalQuery), logger.Query(queryForEmbedding), req.TopK, collectionName, route)
 
 	// Generate query embedding using the appropriate embedding provider
-	queryEmbedding, err := embedding.EmbedQuery(ctx, queryForEmbedding)
+	queryEmbedding, err := s.embedQuery(ctx, embedding, queryForEmbedding)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
 	// Build filters
-	filters := &repository.SearchFilters{
-		Category:   req.Category,
-		SourceType: req.SourceType,
+	filters, err := s.buildSearchFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	grouping, err := buildSearchGrouping(req)
+	if err != nil {
+		return nil, err
 	}
 
-	plan := buildHybridPlan(route, req.TopK)
+	// With reranking, more candidates are retrieved than returned, and with
+	// chunking more points than candidates
+	candidates := s.rerankCandidates(req.TopK)
+	points, pointGrouping := s.chunkedRetrieval(candidates, grouping)
+	plan := buildHybridPlan(route, points)
+	plan.Grouping = pointGrouping
 	usingHybrid := true
 
-	qdrantResults, err := qdrantRepo.HybridSearch(ctx, queryEmbedding, originalQuery, req.TopK, &plan, filters)
+	qdrantResults, err := qdrantRepo.HybridSearch(ctx, queryEmbedding, originalQuery, points, &plan, filters)
 	if err != nil {
 		usingHybrid = false
 		logger.CtxWarn(ctx, "Hybrid search failed, falling back to dense search: error=%v", err)
-		qdrantResults, err = qdrantRepo.Search(ctx, queryEmbedding, req.TopK, filters)
+		qdrantResults, err = qdrantRepo.SearchGroups(ctx, queryEmbedding, points, pointGrouping, filters)
 		if err != nil {
 			return nil, fmt.Errorf("failed to search in Qdrant: %w", err)
 		}
 	}
+	// Long descriptions are indexed as several caption points per meme
+	qdrantResults = dedupeByMemeID(qdrantResults)
 
-	results := make([]SearchResult, 0, req.TopK)
-	for _, qr := range qdrantResults {
-		if qr.Payload == nil {
-			continue
-		}
-		if !usingHybrid && s.scoreThreshold > 0 && qr.Score < s.scoreThreshold {
-			continue
-		}
-		results = append(results, SearchResult{
-			ID:          qr.Payload.MemeID,
-			URL:         qr.Payload.StorageURL,
-			Score:       qr.Score,
-			Description: qr.Payload.VLMDescription,
-			Category:    qr.Payload.Category,
-			Tags:        qr.Payload.Tags,
-		})
-	}
-
-	// Slice to TopK
-	if len(results) > req.TopK {
-		results = results[:req.TopK]
+	results := s.collectionResults(qdrantResults, usingHybrid, points)
+	if grouping != nil {
+		// Qdrant capped the points per category, not the memes
+		results = limitPerCategory(results, grouping.Size, candidates)
 	}
+	results = results[:min(len(results), candidates)]
+	results = s.rerankResults(ctx, originalQuery, results, req.TopK)
 
 	// Optionally enrich with full meme data from database
 	if len(results) > 0 {
@@ -454,12 +600,44 @@ func (s *SearchService) TextSearch(ctx context.Context, req *SearchRequest) (*Se
 		Query:         originalQuery,
 		ExpandedQuery: expandedQuery,
 		Collection:    collectionName,
+		Color:         stringValue(req.Color),
+		Scene:         stringValue(req.Scene),
 	}, nil
 }
 
+// collectionResults converts Qdrant hits of a collection search into results,
+// up to topK. Dense-only hits must pass their score threshold; hybrid RRF
+// scores are not comparable to it.
+func (s *SearchService) collectionResults(hits []repository.SearchResult, hybrid bool, topK int) []SearchResult {
+	results := make([]SearchResult, 0, topK)
+	for _, qr := range hits {
+		if qr.Payload == nil {
+			continue
+		}
+		if !hybrid && !s.meetsThreshold(qr.Score, qr.Payload.Category) {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:          qr.Payload.MemeID,
+			URL:         qr.Payload.StorageURL,
+			PosterURL:   qr.Payload.PosterURL,
+			Score:       qr.Score,
+			Description: qr.Payload.VLMDescription,
+			OCRText:     qr.Payload.OCRText,
+			Category:    qr.Payload.Category,
+			Tags:        qr.Payload.Tags,
+		})
+	}
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results
+}
+
 func (s *SearchService) searchProfile(
 	ctx context.Context,
 	req *SearchRequest,
+	route QueryRoute,
 	profileName string,
 	profile *SearchProfileConfig,
 	originalQuery string,
@@ -472,22 +650,26 @@ func (s *SearchService) searchProfile(
 		return nil, fmt.Errorf("profile %q is incomplete", profileName)
 	}
 
-	logger.CtxInfo(ctx, "Performing profile search: query=%q, query_for_embedding=%q, top_k=%d, profile=%s",
-		originalQuery, queryForEmbedding, req.TopK, profileName)
+	logger.CtxInfo(ctx, "Performing profile search: query=%q, query_for_embedding=%q, top_k=%d, profile=%s, route=%s",
+		logger.Query(originalQuery), logger.Query(queryForEmbedding), req.TopK, profileName, route)
 
-	imageQueryEmbedding, err := profile.Image.Embedding.EmbedQuery(ctx, queryForEmbedding)
+	imageQueryEmbedding, err := s.embedQuery(ctx, profile.Image.Embedding, queryForEmbedding)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate image route query embedding: %w", err)
 	}
 
-	captionQueryEmbedding, err := profile.Caption.Embedding.EmbedQuery(ctx, queryForEmbedding)
+	captionQueryEmbedding, err := s.embedQuery(ctx, profile.Caption.Embedding, queryForEmbedding)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate caption route query embedding: %w", err)
 	}
 
-	filters := &repository.SearchFilters{
-		Category:   req.Category,
-		SourceType: req.SourceType,
+	filters, err := s.buildSearchFilters(req)
+	if err != nil {
+		return nil, err
+	}
+	grouping, err := buildSearchGrouping(req)
+	if err != nil {
+		return nil, err
 	}
 
 	imageResults, imageErr := profile.Image.QdrantRepo.Search(ctx, imageQueryEmbedding, s.retrieval.ImageTopK, filters)
@@ -508,6 +690,10 @@ func (s *SearchService) searchProfile(
 		keywordResults = nil
 	}
 
+	// A meme counts once per route, at the rank of its best description chunk
+	captionResults = dedupeByMemeID(captionResults)
+	keywordResults = dedupeByMemeID(keywordResults)
+
 	if imageErr != nil && captionErr != nil && keywordErr != nil {
 		return nil, fmt.Errorf("all profile search routes failed: image=%v, caption=%v, keyword=%v", imageErr, captionErr, keywordErr)
 	}
@@ -516,7 +702,17 @@ func (s *SearchService) searchProfile(
 	if finalTopK <= 0 {
 		finalTopK = s.retrieval.FinalTopK
 	}
-	results := fuseProfileResults(imageResults, captionResults, keywordResults, s.retrieval.Weights, finalTopK)
+	// Routes are fused locally, so groups are capped after fusion rather than by Qdrant
+	candidates := s.rerankCandidates(finalTopK)
+	fuseTopK := candidates
+	if grouping != nil {
+		fuseTopK = math.MaxInt
+	}
+	results := fuseProfileResults(imageResults, captionResults, keywordResults, routeRetrievalWeights(route, s.retrieval.Weights), fuseTopK)
+	if grouping != nil {
+		results = limitPerCategory(results, grouping.Size, candidates)
+	}
+	results = s.rerankResults(ctx, originalQuery, results, finalTopK)
 	s.enrichSearchResults(ctx, results)
 
 	return &SearchResponse{
@@ -525,6 +721,8 @@ func (s *SearchService) searchProfile(
 		Query:         originalQuery,
 		ExpandedQuery: expandedQuery,
 		Profile:       profileName,
+		Color:         stringValue(req.Color),
+		Scene:         stringValue(req.Scene),
 	}, nil
 }
 
@@ -563,14 +761,16 @@ func fuseProfileResults(
 			if qr.Payload == nil || qr.Payload.MemeID == "" {
 				continue
 			}
-			rankScore := route.weight * (1 / float32(rank+60))
+			rankScore := route.weight * (1 / float32(rank+defaultRRFK))
 			item, ok := byMemeID[qr.Payload.MemeID]
 			if !ok {
 				item = &scoredResult{
 					result: SearchResult{
 						ID:          qr.Payload.MemeID,
 						URL:         qr.Payload.StorageURL,
+						PosterURL:   qr.Payload.PosterURL,
 						Description: qr.Payload.VLMDescription,
+						OCRText:     qr.Payload.OCRText,
 						Category:    qr.Payload.Category,
 						Tags:        qr.Payload.Tags,
 					},
@@ -609,6 +809,87 @@ func fuseProfileResults(
 	return results
 }
 
+// buildSearchFilters converts request filters to Qdrant payload filters. When no
+// color is requested, one named in the query (e.g. "黑白的熊猫头") is applied and
+// recorded on req so the response reports it; scenes likewise when enabled.
+func (s *SearchService) buildSearchFilters(req *SearchRequest) (*repository.SearchFilters, error) {
+	filters := &repository.SearchFilters{
+		Category:   req.Category,
+		SourceType: req.SourceType,
+	}
+	if req.Color == nil {
+		if detected := detectQueryColor(req.Query); detected != "" {
+			req.Color = &detected
+		}
+	}
+	if err := applyColorFilter(filters, stringValue(req.Color)); err != nil {
+		return nil, err
+	}
+	lang, err := normalizeTextLang(stringValue(req.TextLang))
+	if err != nil {
+		return nil, err
+	}
+	if lang != "" {
+		filters.TextLang = &lang
+	}
+
+	if req.Scene == nil && s.sceneFromQuery {
+		if detected := detectQueryScene(req.Query); detected != "" {
+			req.Scene = &detected
+		}
+	}
+	if scene := stringValue(req.Scene); scene != "" {
+		if !isSceneTag(scene) {
+			return nil, fmt.Errorf("unknown scene: %s", scene)
+		}
+		filters.Scene = &scene
+	}
+	return filters, nil
+}
+
+// buildSearchGrouping validates the requested grouping. Only category is
+// supported; it is the one keyword field every result carries.
+func buildSearchGrouping(req *SearchRequest) (*repository.SearchGrouping, error) {
+	if req.GroupBy == "" {
+		return nil, nil
+	}
+	if req.GroupBy != "category" {
+		return nil, fmt.Errorf("unsupported group_by: %s", req.GroupBy)
+	}
+	size := req.GroupSize
+	if size <= 0 {
+		size = defaultGroupSize
+	}
+	if req.TopK > 0 && size > req.TopK {
+		size = req.TopK
+	}
+	return &repository.SearchGrouping{Field: req.GroupBy, Size: size}, nil
+}
+
+// limitPerCategory keeps at most size results per category, in order, up to topK results.
+func limitPerCategory(results []SearchResult, size, topK int) []SearchResult {
+	counts := make(map[string]int)
+	limited := make([]SearchResult, 0, min(len(results), topK))
+	for _, result := range results {
+		if len(limited) == topK {
+			break
+		}
+		if counts[result.Category] >= size {
+			continue
+		}
+		counts[result.Category]++
+		limited = append(limited, result)
+	}
+	return limited
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
 func (s *SearchService) enrichSearchResults(ctx context.Context, results []SearchResult) {
 	if len(results) == 0 || s.memeRepo == nil {
 		return
@@ -633,6 +914,7 @@ func (s *SearchService) enrichSearchResults(ctx context.Context, results []Searc
 		if meme, ok := memeMap[results[i].ID]; ok {
 			results[i].Width = meme.Width
 			results[i].Height = meme.Height
+			results[i].Colors = meme.DominantColors
 		}
 	}
 }
@@ -641,12 +923,38 @@ func (s *SearchService) enrichSearchResults(ctx context.Context, results []Searc
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //   - req: search request parameters.
-//   - progressCh: channel for sending progress updates.
+//   - progressCh: channel for sending progress updates; closed without
+//     updates when the response comes from the result cache.
 //
 // Returns:
 //   - *SearchResponse: search results and metadata.
-//   - error: non-nil if search fails.
+//   - error: ErrUnknownCollection or ErrUnknownProfile for an unregistered name, or a search error.
 func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchRequest, progressCh chan<- SearchProgress) (*SearchResponse, error) {
+	if err := s.ValidateSearchTarget(req); err != nil {
+		close(progressCh)
+		return nil, err
+	}
+	start := time.Now()
+	searched := false
+	resp, err := s.cachedSearch(ctx, req, func() (*SearchResponse, error) {
+		searched = true
+		return s.textSearchWithProgress(ctx, req, progressCh)
+	})
+	if !searched {
+		close(progressCh)
+	}
+	recordSearchMetrics(req, resp, time.Since(start), err)
+	if err == nil {
+		s.recordQuery(ctx)
+		s.recordImpressions(ctx, resp)
+		s.logSearch(ctx, req, resp, time.Since(start))
+		s.mirrorSearch(ctx, req, resp, time.Since(start))
+	}
+	return resp, err
+}
+
+// textSearchWithProgress runs a search for TextSearchWithProgress.
+func (s *SearchService) textSearchWithProgress(ctx context.Context, req *SearchRequest, progressCh chan<- SearchProgress) (*SearchResponse, error) {
 	defer close(progressCh)
 
 	// Set defaults
@@ -662,11 +970,11 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 	expandedQuery := ""
 
 	// Stage 1: Query Expansion (with streaming)
-	if route != QueryRouteExact && s.queryExpansion != nil && s.queryExpansion.IsEnabled() {
+	if release, ok := s.reserveExpansion(ctx, route, req.Query, req.Lang); ok {
 		// Send start event
 		progressCh <- SearchProgress{
 			Stage:   "query_expansion_start",
-			Message: "AI 正在理解搜索意图...",
+			Message: i18n.Message(ctx, i18n.MsgSearchUnderstanding),
 		}
 
 		// Create token channel for streaming
@@ -676,7 +984,7 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 
 		go func() {
 			defer close(expandDone)
-			expandedQuery, expandErr = s.queryExpansion.ExpandStream(ctx, req.Query, tokenCh)
+			expandedQuery, expandErr = s.queryExpansion.ExpandStream(ctx, req.Query, req.Lang, tokenCh)
 		}()
 
 		// Stream thinking tokens
@@ -689,18 +997,19 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 		}
 
 		<-expandDone
+		release()
 
 		if expandErr != nil {
 			logger.CtxWarn(ctx, "Query expansion failed, using original query: query=%q, error=%v",
-				req.Query, expandErr)
+				logger.Query(req.Query), expandErr)
 			// Silent fallback - continue with original query
 			expandedQuery = ""
 		} else if expandedQuery != req.Query && expandedQuery != "" {
-			logger.CtxInfo(ctx, "Query expanded: original=%q, expanded=%q", req.Query, expandedQuery)
+			logger.CtxInfo(ctx, "Query expanded: original=%q, expanded=%q", logger.Query(req.Query), logger.Query(expandedQuery))
 
 			progressCh <- SearchProgress{
 				Stage:         "query_expansion_done",
-				Message:       "理解完成",
+				Message:       i18n.Message(ctx, i18n.MsgSearchUnderstood),
 				ExpandedQuery: expandedQuery,
 			}
 		}
@@ -715,7 +1024,7 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 	// Stage 2: Generate Embedding
 	progressCh <- SearchProgress{
 		Stage:   "embedding",
-		Message: "正在生成语义向量...",
+		Message: i18n.Message(ctx, i18n.MsgSearchEmbedding),
 	}
 
 	if profile, profileName, ok, err := s.resolveRequestedProfile(req); err != nil {
@@ -723,16 +1032,16 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 	} else if ok {
 		progressCh <- SearchProgress{
 			Stage:   "searching",
-			Message: "在表情库中搜索...",
+			Message: i18n.Message(ctx, i18n.MsgSearchSearching),
 		}
-		result, err := s.searchProfile(ctx, req, profileName, profile, originalQuery, queryForEmbedding, expandedQuery)
+		result, err := s.searchProfile(ctx, req, route, profileName, profile, originalQuery, queryForEmbedding, expandedQuery)
 		if err != nil {
 			return nil, err
 		}
 		if len(result.Results) > 0 {
 			progressCh <- SearchProgress{
 				Stage:   "enriching",
-				Message: "加载表情包详情...",
+				Message: i18n.Message(ctx, i18n.MsgSearchLoading),
 			}
 		}
 		return result, nil
@@ -744,9 +1053,9 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 	}
 
 	logger.CtxInfo(ctx, "Performing text search: query=%q, query_for_embedding=%q, top_k=%d, collection=%s, route=%s",
-		originalQuery, queryForEmbedding, req.TopK, collectionName, route)
+		logger.Query(originalQuery), logger.Query(queryForEmbedding), req.TopK, collectionName, route)
 
-	queryEmbedding, err := embedding.EmbedQuery(ctx, queryForEmbedding)
+	queryEmbedding, err := s.embedQuery(ctx, embedding, queryForEmbedding)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
@@ -754,60 +1063,83 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 	// Stage 3: Search in Qdrant
 	progressCh <- SearchProgress{
 		Stage:   "searching",
-		Message: "在表情库中搜索...",
+		Message: i18n.Message(ctx, i18n.MsgSearchSearching),
 	}
 
-	filters := &repository.SearchFilters{
-		Category:   req.Category,
-		SourceType: req.SourceType,
+	filters, err := s.buildSearchFilters(req)
+	if err != nil {
+		return nil, err
 	}
 
-	plan := buildHybridPlan(route, req.TopK)
+	grouping, err := buildSearchGrouping(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// With reranking, more candidates are retrieved than returned, and with
+	// chunking more points than candidates
+	candidates := s.rerankCandidates(req.TopK)
+	points, pointGrouping := s.chunkedRetrieval(candidates, grouping)
+	plan := buildHybridPlan(route, points)
+	plan.Grouping = pointGrouping
 	usingHybrid := true
 
-	qdrantResults, err := qdrantRepo.HybridSearch(ctx, queryEmbedding, originalQuery, req.TopK, &plan, filters)
+	qdrantResults, err := qdrantRepo.HybridSearch(ctx, queryEmbedding, originalQuery, points, &plan, filters)
 	if err != nil {
 		usingHybrid = false
 		logger.CtxWarn(ctx, "Hybrid search failed, falling back to dense search: error=%v", err)
 		progressCh <- SearchProgress{
 			Stage:   "searching",
-			Message: "混合检索失败，切换为语义检索...",
+			Message: i18n.Message(ctx, i18n.MsgSearchHybridFallback),
 		}
-		qdrantResults, err = qdrantRepo.Search(ctx, queryEmbedding, req.TopK, filters)
+		qdrantResults, err = qdrantRepo.SearchGroups(ctx, queryEmbedding, points, pointGrouping, filters)
 		if err != nil {
 			return nil, fmt.Errorf("failed to search in Qdrant: %w", err)
 		}
 	}
+	// Long descriptions are indexed as several caption points per meme
+	qdrantResults = dedupeByMemeID(qdrantResults)
 
-	results := make([]SearchResult, 0, req.TopK)
+	results := make([]SearchResult, 0, len(qdrantResults))
 	for _, qr := range qdrantResults {
 		if qr.Payload == nil {
 			continue
 		}
-		if !usingHybrid && s.scoreThreshold > 0 && qr.Score < s.scoreThreshold {
+		if !usingHybrid && !s.meetsThreshold(qr.Score, qr.Payload.Category) {
 			continue
 		}
 		result := SearchResult{
 			ID:          qr.Payload.MemeID,
 			URL:         qr.Payload.StorageURL,
+			PosterURL:   qr.Payload.PosterURL,
 			Score:       qr.Score,
 			Description: qr.Payload.VLMDescription,
+			OCRText:     qr.Payload.OCRText,
 			Category:    qr.Payload.Category,
 			Tags:        qr.Payload.Tags,
 		}
 		results = append(results, result)
 	}
+	if grouping != nil {
+		// Qdrant capped the points per category, not the memes
+		results = limitPerCategory(results, grouping.Size, candidates)
+	}
+	results = results[:min(len(results), candidates)]
 
-	// Slice to TopK
-	if len(results) > req.TopK {
-		results = results[:req.TopK]
+	// Stage 4: Rerank the top candidates, then slice to TopK
+	if s.reranker != nil && len(results) > 1 {
+		progressCh <- SearchProgress{
+			Stage:   "reranking",
+			Message: i18n.Message(ctx, i18n.MsgSearchReranking),
+		}
 	}
+	results = s.rerankResults(ctx, originalQuery, results, req.TopK)
 
-	// Stage 4: Enrich with database data
+	// Stage 5: Enrich with database data
 	if len(results) > 0 {
 		progressCh <- SearchProgress{
 			Stage:   "enriching",
-			Message: "加载表情包详情...",
+			Message: i18n.Message(ctx, i18n.MsgSearchLoading),
 		}
 
 		ids := make([]string, len(results))
@@ -839,10 +1171,12 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 		Query:         originalQuery,
 		ExpandedQuery: expandedQuery,
 		Collection:    collectionName,
+		Color:         stringValue(req.Color),
+		Scene:         stringValue(req.Scene),
 	}, nil
 }
 
-// GetCategories returns all available categories.
+// GetCategories returns all available categories, cached for the cache TTL.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //
@@ -850,7 +1184,13 @@ func (s *SearchService) TextSearchWithProgress(ctx context.Context, req *SearchR
 //   - []string: distinct category names.
 //   - error: non-nil if lookup fails.
 func (s *SearchService) GetCategories(ctx context.Context) ([]string, error) {
-	return s.memeRepo.GetCategories(ctx)
+	categories, err := s.cached(cacheKeyCategories, func() (any, error) {
+		return s.memeRepo.GetCategories(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return categories.([]string), nil
 }
 
 // GetMemeByID retrieves a meme by its ID.
@@ -862,21 +1202,27 @@ func (s *SearchService) GetCategories(ctx context.Context) ([]string, error) {
 //   - *domain.Meme: meme record if found.
 //   - error: non-nil if lookup fails.
 func (s *SearchService) GetMemeByID(ctx context.Context, id string) (*domain.Meme, error) {
-	return s.memeRepo.GetByID(ctx, id)
+	meme, err := s.memeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if meme.PosterKey != "" && s.storage != nil {
+		meme.PosterURL = s.storage.GetURL(meme.PosterKey)
+	}
+	return meme, nil
 }
 
 // MemeListResponse represents the response for listing memes.
 type MemeListResponse struct {
 	Results []SearchResult `json:"results"`
-	Total   int            `json:"total"`
-	Limit   int            `json:"limit"`
-	Offset  int            `json:"offset"`
+	PageInfo
 }
 
-// ListMemes retrieves memes with optional category filter.
+// ListMemes retrieves memes with optional category and tag filters, in the
+// filter's sort order.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
-//   - category: category name to filter by; empty means all.
+//   - filter: category, tags and sort of the list.
 //   - limit: maximum number of records to return.
 //   - offset: number of records to skip.
 //
@@ -885,7 +1231,8 @@ type MemeListResponse struct {
 //   - error: non-nil if retrieval fails.
 //
 // Returns results in the same format as search results for API consistency.
-func (s *SearchService) ListMemes(ctx context.Context, category string, limit, offset int) (*MemeListResponse, error) {
+// The total is counted per category and tag set and cached for the cache TTL.
+func (s *SearchService) ListMemes(ctx context.Context, filter repository.MemeListFilter, limit, offset int) (*MemeListResponse, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -893,41 +1240,71 @@ func (s *SearchService) ListMemes(ctx context.Context, category string, limit, o
 		limit = 100
 	}
 
-	memes, err := s.memeRepo.ListByCategory(ctx, category, limit, offset)
+	memes, err := s.memeRepo.List(ctx, filter, limit, offset)
 	if err != nil {
 		return nil, err
 	}
+	total, err := s.countMemeList(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memes: %w", err)
+	}
+
+	return &MemeListResponse{
+		Results:  s.listResults(memes),
+		PageInfo: NewPageInfo(total, limit, offset),
+	}, nil
+}
 
-	// Convert domain.Meme to SearchResult format for API consistency
+// listResults converts memes to SearchResult format for API consistency.
+func (s *SearchService) listResults(memes []domain.Meme) []SearchResult {
 	results := make([]SearchResult, len(memes))
 	for i, meme := range memes {
 		// Generate URL from storage_key
 		url := ""
+		posterURL := ""
 		if meme.StorageKey != "" && s.storage != nil {
 			url = s.storage.GetURL(meme.StorageKey)
 		}
+		if meme.PosterKey != "" && s.storage != nil {
+			posterURL = s.storage.GetURL(meme.PosterKey)
+		}
 
 		results[i] = SearchResult{
 			ID:          meme.ID,
 			URL:         url,
+			PosterURL:   posterURL,
 			Score:       0,  // No score for listing (not a search)
 			Description: "", // VLM description moved to meme_descriptions table; use search for descriptions
 			Category:    meme.Category,
 			Tags:        meme.Tags,
 			Width:       meme.Width,
 			Height:      meme.Height,
+			Colors:      meme.DominantColors,
 		}
 	}
+	return results
+}
 
-	return &MemeListResponse{
-		Results: results,
-		Total:   len(results),
-		Limit:   limit,
-		Offset:  offset,
-	}, nil
+// countMemeList counts the memes of a list filter, cached for the cache TTL.
+// Tag order does not matter, so the tags are sorted into the cache key.
+func (s *SearchService) countMemeList(ctx context.Context, filter repository.MemeListFilter) (int64, error) {
+	tags := append([]string(nil), filter.Tags...)
+	sort.Strings(tags)
+	key := cacheKeyMemeCount + ":" + filter.Category + "\x00" + strings.Join(tags, "\x00")
+	if filter.Emotion != "" || !filter.CreatedBefore.IsZero() {
+		key += fmt.Sprintf("\x01%s\x00%d", filter.Emotion, filter.CreatedBefore.Unix())
+	}
+	total, err := s.cached(key, func() (any, error) {
+		return s.memeRepo.CountList(ctx, filter)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total.(int64), nil
 }
 
-// GetStats returns search-related statistics.
+// GetStats returns search-related statistics, cached for the cache TTL. The
+// result cache counters under "search_cache" are always current.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 //
@@ -935,6 +1312,27 @@ func (s *SearchService) ListMemes(ctx context.Context, category string, limit, o
 //   - map[string]interface{}: aggregated stats for search and ingest.
 //   - error: non-nil if statistics cannot be computed.
 func (s *SearchService) GetStats(ctx context.Context) (map[string]interface{}, error) {
+	stats, err := s.cached(cacheKeyStats, func() (any, error) {
+		return s.loadStats(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultCache := s.ResultCacheStats()
+	if resultCache == nil {
+		return stats.(map[string]interface{}), nil
+	}
+	// The cached map is shared, so the counters go into a copy.
+	withCache := make(map[string]interface{}, len(stats.(map[string]interface{}))+1)
+	for k, v := range stats.(map[string]interface{}) {
+		withCache[k] = v
+	}
+	withCache["search_cache"] = resultCache
+	return withCache, nil
+}
+
+// loadStats computes the statistics returned by GetStats.
+func (s *SearchService) loadStats(ctx context.Context) (map[string]interface{}, error) {
 	activeCount, err := s.memeRepo.CountByStatus(ctx, domain.MemeStatusActive)
 	if err != nil {
 		return nil, err
@@ -950,11 +1348,83 @@ func (s *SearchService) GetStats(ctx context.Context) (map[string]interface{}, e
 		return nil, err
 	}
 
+	collectionStats, err := s.getCollectionStats(ctx, activeCount)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"total_active":          activeCount,
 		"total_pending":         pendingCount,
 		"total_categories":      len(categories),
 		"available_collections": s.GetAvailableCollections(),
 		"available_profiles":    s.GetAvailableProfiles(),
+		"collections":           collectionStats,
 	}, nil
 }
+
+// CollectionStats reports how many vectors a Qdrant collection holds and how
+// many of the active memes they cover.
+type CollectionStats struct {
+	Collection  string  `json:"collection"`
+	Vectors     int64   `json:"vectors"`      // meme_vectors rows of any type and status
+	Memes       int64   `json:"memes"`        // Active memes with an active vector
+	CoveragePct float64 `json:"coverage_pct"` // memes / active memes * 100
+}
+
+// getCollectionStats counts meme_vectors rows and the active memes they cover
+// for every Qdrant collection the service can search, sorted by collection
+// name.
+func (s *SearchService) getCollectionStats(ctx context.Context, activeCount int64) ([]CollectionStats, error) {
+	if s.vectorRepo == nil {
+		return []CollectionStats{}, nil
+	}
+
+	names := make(map[string]struct{})
+	addRepo := func(repo *repository.QdrantRepository) {
+		if repo != nil {
+			names[repo.GetCollectionName()] = struct{}{}
+		}
+	}
+	addRepo(s.defaultQdrantRepo)
+	for _, cfg := range s.collections {
+		addRepo(cfg.QdrantRepo)
+	}
+	for _, profile := range s.profiles {
+		if profile.Image != nil {
+			addRepo(profile.Image.QdrantRepo)
+		}
+		if profile.Caption != nil {
+			addRepo(profile.Caption.QdrantRepo)
+		}
+	}
+
+	stats := make([]CollectionStats, 0, len(names))
+	for name := range names {
+		count, err := s.vectorRepo.CountByCollection(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count vectors for collection %s: %w", name, err)
+		}
+		memes, err := s.vectorRepo.CountMemesByCollection(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count memes for collection %s: %w", name, err)
+		}
+		stats = append(stats, CollectionStats{
+			Collection:  name,
+			Vectors:     count,
+			Memes:       memes,
+			CoveragePct: coveragePercent(memes, activeCount),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Collection < stats[j].Collection })
+
+	return stats, nil
+}
+
+// coveragePercent returns part/total as a percentage rounded to two decimals.
+func coveragePercent(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
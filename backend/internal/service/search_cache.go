@@ -0,0 +1,413 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timmy/emomo/internal/cache"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/metrics"
+)
+
+const (
+	cacheKeyCategories    = "categories"
+	cacheKeyStats         = "stats"
+	cacheKeyCategoryStats = "category_stats"
+	cacheKeyMemeCount     = "meme_count"
+
+	// cacheSweepSize is the number of cached values above which expired ones
+	// are dropped on write; meme list counts are keyed by arbitrary filters.
+	cacheSweepSize = 256
+)
+
+// SearchCacheConfig configures the in-memory caches of the search service.
+type SearchCacheConfig struct {
+	TTL             time.Duration // Lifetime of cached category lists and stats (0 disables)
+	QueryEmbeddings int           // Query embeddings kept, least recently used evicted (0 disables)
+	Results         int           // Search responses kept, least recently used evicted (0 disables)
+	ResultTTL       time.Duration // Lifetime of a cached search response (0 disables)
+	Shared          cache.Store   // Shares responses between replicas in place of the local LRU (nil keeps them in process)
+}
+
+// ResultCacheStats reports the search result cache since startup.
+type ResultCacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"` // hits / (hits + misses)
+	Entries int     `json:"entries"`  // Responses held in process; 0 with a shared store
+}
+
+// searchCache holds the category list, stats, query embeddings and search
+// responses of a SearchService. It is safe for concurrent use.
+type searchCache struct {
+	ttl        time.Duration
+	now        func() time.Time
+	embeddings *lruCache[[]float32]
+
+	results   *lruCache[cachedResult]
+	shared    cache.Store // Replaces results when set
+	resultTTL time.Duration
+	hits      atomic.Int64
+	misses    atomic.Int64
+
+	mu     sync.Mutex
+	values map[string]cachedValue
+}
+
+type cachedValue struct {
+	value   any
+	expires time.Time
+}
+
+// cachedResult is a cached search response, JSON-encoded in a shared store.
+type cachedResult struct {
+	Response *SearchResponse `json:"response"`
+	Expires  time.Time       `json:"expires"`
+}
+
+// SetCache enables the in-memory caches; a zero config disables them.
+// Parameters:
+//   - cfg: cache lifetimes and sizes.
+//
+// Returns: none.
+func (s *SearchService) SetCache(cfg SearchCacheConfig) {
+	resultsEnabled := (cfg.Results > 0 || cfg.Shared != nil) && cfg.ResultTTL > 0
+	if cfg.TTL <= 0 && cfg.QueryEmbeddings <= 0 && !resultsEnabled {
+		s.cache = nil
+		return
+	}
+	c := &searchCache{
+		ttl:    cfg.TTL,
+		now:    time.Now,
+		values: make(map[string]cachedValue),
+	}
+	if cfg.QueryEmbeddings > 0 {
+		c.embeddings = newLRUCache[[]float32](cfg.QueryEmbeddings)
+	}
+	switch {
+	case !resultsEnabled:
+	case cfg.Shared != nil:
+		c.shared = cfg.Shared
+		c.resultTTL = cfg.ResultTTL
+	default:
+		c.results = newLRUCache[cachedResult](cfg.Results)
+		c.resultTTL = cfg.ResultTTL
+	}
+	s.cache = c
+}
+
+// cached returns the value stored under key, calling load on a miss or after
+// the TTL. Load errors are not cached.
+func (s *SearchService) cached(key string, load func() (any, error)) (any, error) {
+	c := s.cache
+	if c == nil || c.ttl <= 0 {
+		return load()
+	}
+
+	c.mu.Lock()
+	entry, ok := c.values[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	now := c.now()
+	if len(c.values) >= cacheSweepSize {
+		for k, entry := range c.values {
+			if !now.Before(entry.expires) {
+				delete(c.values, k)
+			}
+		}
+	}
+	c.values[key] = cachedValue{value: value, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// embedQuery embeds query with provider, reusing a cached vector of the same
+// model and text when the query embedding cache is enabled.
+func (s *SearchService) embedQuery(ctx context.Context, provider EmbeddingProvider, query string) ([]float32, error) {
+	if s.cache == nil || s.cache.embeddings == nil {
+		return provider.EmbedQuery(ctx, query)
+	}
+
+	key := queryEmbeddingKey(provider, query)
+	if vector, ok := s.cache.embeddings.get(key); ok {
+		return vector, nil
+	}
+	vector, err := provider.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.embeddings.add(key, vector)
+	return vector, nil
+}
+
+// queryEmbeddingKey identifies a query vector by model, dimensions and text.
+func queryEmbeddingKey(provider EmbeddingProvider, query string) string {
+	return fmt.Sprintf("%s\x00%d\x00%s", provider.GetModel(), provider.GetDimensions(), query)
+}
+
+// cachedSearch serves req from the result cache when an identical search ran
+// within the result TTL, and otherwise runs search and caches its response.
+// Requests with NoCache bypass the cache and count as neither hit nor miss.
+func (s *SearchService) cachedSearch(ctx context.Context, req *SearchRequest, search func() (*SearchResponse, error)) (*SearchResponse, error) {
+	c := s.cache
+	if c == nil || (c.results == nil && c.shared == nil) || req.NoCache || req.expansion != "" {
+		return search()
+	}
+
+	key := resultCacheKey(req)
+	if entry, ok := c.getResult(ctx, key); ok && c.now().Before(entry.Expires) {
+		c.hits.Add(1)
+		metrics.Count("search.cache.hits", 1)
+		resp := *entry.Response
+		resp.Results = append([]SearchResult(nil), entry.Response.Results...)
+		resp.Query = req.Query
+		resp.Cached = true
+		return &resp, nil
+	}
+	c.misses.Add(1)
+	metrics.Count("search.cache.misses", 1)
+
+	resp, err := search()
+	if err != nil {
+		return nil, err
+	}
+	stored := *resp
+	stored.Results = append([]SearchResult(nil), resp.Results...)
+	c.putResult(ctx, key, cachedResult{Response: &stored, Expires: c.now().Add(c.resultTTL)})
+	return resp, nil
+}
+
+// getResult looks a response up in the shared store or the local LRU. A
+// failing shared store is logged and counts as a miss.
+func (c *searchCache) getResult(ctx context.Context, key string) (cachedResult, bool) {
+	if c.shared == nil {
+		return c.results.get(key)
+	}
+	data, ok, err := c.shared.Get(ctx, sharedResultKey(key))
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to read shared search cache: error=%v", err)
+		return cachedResult{}, false
+	}
+	var entry cachedResult
+	if !ok || json.Unmarshal(data, &entry) != nil || entry.Response == nil {
+		return cachedResult{}, false
+	}
+	return entry, true
+}
+
+// putResult stores a response in the shared store or the local LRU.
+func (c *searchCache) putResult(ctx context.Context, key string, entry cachedResult) {
+	if c.shared == nil {
+		c.results.add(key, entry)
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = c.shared.Set(context.WithoutCancel(ctx), sharedResultKey(key), data, c.resultTTL)
+	}
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to write shared search cache: error=%v", err)
+	}
+}
+
+// sharedResultKey is the shared store key of a result cache key; hashing
+// keeps keys short whatever the query length.
+func sharedResultKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "search:" + hex.EncodeToString(sum[:16])
+}
+
+// resultCacheKey identifies a search by its normalized query, result count
+// and filters. Queries differing only in case or whitespace share a key.
+func resultCacheKey(req *SearchRequest) string {
+	topK := req.TopK
+	switch {
+	case topK <= 0:
+		topK = 20
+	case topK > 100:
+		topK = 100
+	}
+	return strings.Join([]string{
+		strings.ToLower(strings.Join(strings.Fields(req.Query), " ")),
+		strconv.Itoa(topK),
+		req.Collection,
+		req.Profile,
+		stringValue(req.Category),
+		stringValue(req.SourceType),
+		stringValue(req.Color),
+		stringValue(req.TextLang),
+		stringValue(req.Scene),
+		req.GroupBy,
+		strconv.Itoa(req.GroupSize),
+		req.Lang,
+	}, "\x00")
+}
+
+// ResultCacheStats returns the hits, misses and size of the search result
+// cache. Hits and misses are counted per process, also with a shared store.
+// Parameters: none.
+//
+// Returns:
+//   - *ResultCacheStats: cache counters, or nil when the result cache is disabled.
+func (s *SearchService) ResultCacheStats() *ResultCacheStats {
+	c := s.cache
+	if c == nil || (c.results == nil && c.shared == nil) {
+		return nil
+	}
+	stats := &ResultCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if c.results != nil {
+		stats.Entries = c.results.len()
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Warm pre-loads the category list, the stats and the embeddings of queries
+// for the default collection and profile, so the first requests after a
+// deploy are served from memory. Queries are embedded as given; with query
+// expansion enabled, searches embed the expanded text instead.
+// Parameters:
+//   - ctx: context bounding the warm-up.
+//   - queries: hot queries to embed ahead of time.
+//
+// Returns:
+//   - error: non-nil if any part failed; the rest is still warmed.
+func (s *SearchService) Warm(ctx context.Context, queries []string) error {
+	var errs []error
+	if _, err := s.GetCategories(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to warm categories: %w", err))
+	}
+	if _, err := s.GetStats(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to warm stats: %w", err))
+	}
+
+	providers := s.defaultQueryProviders()
+	for _, query := range queries {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		for _, provider := range providers {
+			if _, err := s.embedQuery(ctx, provider, query); err != nil {
+				errs = append(errs, fmt.Errorf("failed to warm query embedding %q (%s): %w", query, provider.GetModel(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// defaultQueryProviders returns the embedding providers a search without an
+// explicit collection or profile uses, one per model and dimension.
+func (s *SearchService) defaultQueryProviders() []EmbeddingProvider {
+	candidates := []EmbeddingProvider{s.defaultEmbedding}
+	if profile, _, ok := s.resolveProfile(""); ok && profile != nil {
+		if profile.Image != nil {
+			candidates = append(candidates, profile.Image.Embedding)
+		}
+		if profile.Caption != nil {
+			candidates = append(candidates, profile.Caption.Embedding)
+		}
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	providers := make([]EmbeddingProvider, 0, len(candidates))
+	for _, provider := range candidates {
+		if provider == nil {
+			continue
+		}
+		key := queryEmbeddingKey(provider, "")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// lruCache is a size-bounded map evicting the least recently used entry. It
+// holds query embeddings and query expansions.
+type lruCache[V any] struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUCache[V any](size int) *lruCache[V] {
+	return &lruCache[V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lruCache[V]) get(key string) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).value, true
+}
+
+func (l *lruCache[V]) add(key string, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		elem.Value.(*lruEntry[V]).value = value
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry[V]{key: key, value: value})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+func (l *lruCache[V]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
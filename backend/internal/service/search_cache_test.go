@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+// countingEmbeddingProvider counts query embeddings.
+type countingEmbeddingProvider struct {
+	fixedEmbeddingProvider
+	queries atomic.Int32
+}
+
+func (p *countingEmbeddingProvider) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
+	p.queries.Add(1)
+	return p.fixedEmbeddingProvider.EmbedQuery(ctx, query)
+}
+
+func TestSearchServiceWarmPreloadsCaches(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	if err := memeRepo.Create(ctx, &domain.Meme{
+		ID:         "meme-1",
+		SourceType: "test",
+		SourceID:   "source-1",
+		MD5Hash:    "md5-1",
+		Category:   "猫",
+		Status:     domain.MemeStatusActive,
+	}); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+
+	provider := &countingEmbeddingProvider{}
+	searchService := NewSearchService(memeRepo, nil, nil, provider, nil, nil, nil, nil)
+	searchService.SetCache(SearchCacheConfig{TTL: time.Minute, QueryEmbeddings: 10})
+
+	if err := searchService.Warm(ctx, []string{"开心", " ", "无语"}); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+	if got := provider.queries.Load(); got != 2 {
+		t.Fatalf("EmbedQuery calls = %d, want 2", got)
+	}
+
+	// Served from memory: new rows and repeated queries do not hit the backends.
+	if err := memeRepo.Create(ctx, &domain.Meme{
+		ID:         "meme-2",
+		SourceType: "test",
+		SourceID:   "source-2",
+		MD5Hash:    "md5-2",
+		Category:   "狗",
+		Status:     domain.MemeStatusActive,
+	}); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+	categories, err := searchService.GetCategories(ctx)
+	if err != nil || len(categories) != 1 {
+		t.Fatalf("GetCategories() = %v, %v, want the warmed list", categories, err)
+	}
+	stats, err := searchService.GetStats(ctx)
+	if err != nil || stats["total_active"] != int64(1) {
+		t.Fatalf("GetStats() = %v, %v, want the warmed stats", stats, err)
+	}
+	if _, err := searchService.embedQuery(ctx, provider, "开心"); err != nil {
+		t.Fatalf("embedQuery() error = %v", err)
+	}
+	if got := provider.queries.Load(); got != 2 {
+		t.Fatalf("EmbedQuery calls after a warm query = %d, want 2", got)
+	}
+
+	// Expired entries are reloaded.
+	searchService.cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	categories, err = searchService.GetCategories(ctx)
+	if err != nil || len(categories) != 2 {
+		t.Fatalf("GetCategories() after TTL = %v, %v, want 2 categories", categories, err)
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	lru := newLRUCache[[]float32](2)
+	lru.add("a", []float32{1})
+	lru.add("b", []float32{2})
+	if _, ok := lru.get("a"); !ok {
+		t.Fatal("get(a) missed, want hit")
+	}
+	lru.add("c", []float32{3})
+
+	if _, ok := lru.get("b"); ok {
+		t.Fatal("get(b) hit, want b evicted")
+	}
+	if _, ok := lru.get("a"); !ok {
+		t.Fatal("get(a) missed, want a kept")
+	}
+	if got := lru.len(); got != 2 {
+		t.Fatalf("len() = %d, want 2", got)
+	}
+}
+
+func TestListMemesReportsCachedTotal(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	create := func(id string) {
+		if err := memeRepo.Create(ctx, &domain.Meme{ID: id, SourceType: "test", SourceID: id, MD5Hash: "md5-" + id,
+			Category: "猫", Tags: domain.StringArray{"可爱", "橘猫"}, Status: domain.MemeStatusActive}); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+	}
+	for _, id := range []string{"meme-1", "meme-2", "meme-3"} {
+		create(id)
+	}
+	searchService := NewSearchService(memeRepo, nil, nil, &fixedEmbeddingProvider{}, nil, nil, nil, nil)
+	searchService.SetCache(SearchCacheConfig{TTL: time.Minute})
+
+	filter := repository.MemeListFilter{Category: "猫", Tags: []string{"可爱", "橘猫"}}
+	resp, err := searchService.ListMemes(ctx, filter, 2, 0)
+	if err != nil {
+		t.Fatalf("ListMemes() error = %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Total != 3 || resp.TotalPages != 2 || !resp.HasMore {
+		t.Fatalf("ListMemes() page 1 = %d results, %+v, want 2 of 3 with more", len(resp.Results), resp.PageInfo)
+	}
+
+	// The count is cached for the tag set in any order; the page is not.
+	create("meme-4")
+	filter.Tags = []string{"橘猫", "可爱"}
+	resp, err = searchService.ListMemes(ctx, filter, 2, 2)
+	if err != nil {
+		t.Fatalf("ListMemes() error = %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Total != 3 || resp.HasMore {
+		t.Fatalf("ListMemes() page 2 = %d results, %+v, want 2 results and the cached total 3", len(resp.Results), resp.PageInfo)
+	}
+}
+
+func TestCachedSearchServesRepeatedQueries(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, nil)
+	searchService.SetCache(SearchCacheConfig{Results: 10, ResultTTL: time.Minute})
+	now := time.Now()
+	searchService.cache.now = func() time.Time { return now }
+
+	searches := 0
+	search := func(req *SearchRequest) *SearchResponse {
+		t.Helper()
+		resp, err := searchService.cachedSearch(context.Background(), req, func() (*SearchResponse, error) {
+			searches++
+			return &SearchResponse{Results: []SearchResult{{ID: "meme-1"}}, Total: 1, Query: req.Query}, nil
+		})
+		if err != nil {
+			t.Fatalf("cachedSearch(%q) error = %v", req.Query, err)
+		}
+		return resp
+	}
+
+	search(&SearchRequest{Query: "开心 猫"})
+	resp := search(&SearchRequest{Query: "  开心   猫 ", TopK: 20})
+	if searches != 1 || !resp.Cached || resp.Query != "  开心   猫 " {
+		t.Fatalf("repeated search = %+v after %d searches, want a cached response echoing the query", resp, searches)
+	}
+	resp.Results[0].ID = "changed"
+
+	category := "猫"
+	if resp := search(&SearchRequest{Query: "开心 猫", Category: &category}); resp.Cached || searches != 2 {
+		t.Fatalf("filtered search cached = %t after %d searches, want a new search", resp.Cached, searches)
+	}
+	if resp := search(&SearchRequest{Query: "开心 猫", NoCache: true}); resp.Cached || searches != 3 {
+		t.Fatalf("bypass search cached = %t after %d searches, want a new search", resp.Cached, searches)
+	}
+	if resp := search(&SearchRequest{Query: "开心 猫"}); !resp.Cached || resp.Results[0].ID != "meme-1" {
+		t.Fatalf("cached results = %+v, want the stored copy", resp.Results)
+	}
+
+	now = now.Add(time.Minute)
+	if resp := search(&SearchRequest{Query: "开心 猫"}); resp.Cached || searches != 4 {
+		t.Fatalf("expired search cached = %t after %d searches, want a new search", resp.Cached, searches)
+	}
+
+	stats := searchService.ResultCacheStats()
+	if stats == nil || stats.Hits != 2 || stats.Misses != 3 || stats.Entries != 2 {
+		t.Fatalf("ResultCacheStats() = %+v, want 2 hits, 3 misses and 2 entries", stats)
+	}
+}
+
+// mapStore is a cache.Store shared by the services of a test.
+type mapStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (m *mapStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	return value, ok, nil
+}
+
+func (m *mapStore) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapStore) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
+
+func TestCachedSearchSharesResultsBetweenReplicas(t *testing.T) {
+	t.Parallel()
+
+	store := &mapStore{values: make(map[string][]byte)}
+	replicas := make([]*SearchService, 2)
+	for i := range replicas {
+		replicas[i] = NewSearchService(nil, nil, nil, nil, nil, nil, nil, nil)
+		replicas[i].SetCache(SearchCacheConfig{ResultTTL: time.Minute, Shared: store})
+	}
+
+	ctx := context.Background()
+	searches := 0
+	search := func() (*SearchResponse, error) {
+		searches++
+		return &SearchResponse{Results: []SearchResult{{ID: "meme-1", Score: 0.9}}, Total: 1, Query: "开心"}, nil
+	}
+	if _, err := replicas[0].cachedSearch(ctx, &SearchRequest{Query: "开心"}, search); err != nil {
+		t.Fatalf("cachedSearch(replica 0) error = %v", err)
+	}
+	resp, err := replicas[1].cachedSearch(ctx, &SearchRequest{Query: "开心"}, search)
+	if err != nil || searches != 1 || !resp.Cached || resp.Results[0].ID != "meme-1" {
+		t.Fatalf("cachedSearch(replica 1) = %+v, %v after %d searches, want replica 0's response", resp, err, searches)
+	}
+	if stats := replicas[1].ResultCacheStats(); stats == nil || stats.Hits != 1 || stats.Entries != 0 {
+		t.Fatalf("ResultCacheStats(replica 1) = %+v, want 1 hit and no local entries", stats)
+	}
+}
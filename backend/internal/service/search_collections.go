@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/timmy/emomo/internal/repository"
+)
+
+var (
+	// ErrUnknownCollection is returned when a search names a collection that
+	// is not registered.
+	ErrUnknownCollection = errors.New("unknown collection")
+	// ErrUnknownProfile is returned when a search names a profile that is not
+	// registered.
+	ErrUnknownProfile = errors.New("unknown profile")
+)
+
+// CollectionInfo describes a collection a search can be routed to.
+type CollectionInfo struct {
+	Name       string `json:"name"`              // Key passed as "collection"
+	Default    bool   `json:"default"`           // Searched when the request names none
+	Qdrant     string `json:"qdrant_collection"` // Qdrant collection holding the vectors
+	Model      string `json:"model"`             // Embedding model of queries
+	Dimensions int    `json:"dimensions"`
+}
+
+// ProfileInfo describes a multi-route search profile.
+type ProfileInfo struct {
+	Name    string          `json:"name"`    // Key passed as "profile"
+	Default bool            `json:"default"` // Searched when the request names neither profile nor collection
+	Image   *CollectionInfo `json:"image,omitempty"`
+	Caption *CollectionInfo `json:"caption,omitempty"`
+}
+
+// ListCollections returns the collections a search can name, default first.
+// Parameters: none.
+//
+// Returns:
+//   - []CollectionInfo: registered collections with their embedding model.
+func (s *SearchService) ListCollections() []CollectionInfo {
+	names := s.GetAvailableCollections()
+	infos := make([]CollectionInfo, 0, len(names))
+	for _, name := range names {
+		repo, embedding := s.defaultQdrantRepo, s.defaultEmbedding
+		if cfg, ok := s.collections[name]; ok {
+			repo, embedding = cfg.QdrantRepo, cfg.Embedding
+		}
+		info := collectionInfo(name, repo, embedding)
+		info.Default = name == s.defaultCollection
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ListProfiles returns the search profiles a search can name, default first.
+// Parameters: none.
+//
+// Returns:
+//   - []ProfileInfo: registered profiles with the collections of their routes.
+func (s *SearchService) ListProfiles() []ProfileInfo {
+	infos := make([]ProfileInfo, 0, len(s.profiles))
+	for _, name := range s.GetAvailableProfiles() {
+		cfg, ok := s.profiles[name]
+		if !ok {
+			// The configured default profile may be missing from the registry.
+			continue
+		}
+		info := ProfileInfo{Name: name, Default: name == s.defaultProfile}
+		if cfg.Image != nil {
+			image := collectionInfo("image", cfg.Image.QdrantRepo, cfg.Image.Embedding)
+			info.Image = &image
+		}
+		if cfg.Caption != nil {
+			caption := collectionInfo("caption", cfg.Caption.QdrantRepo, cfg.Caption.Embedding)
+			info.Caption = &caption
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ValidateSearchTarget checks the collection and profile a search names
+// before any work is spent on it. A collection may also name a profile.
+// Parameters:
+//   - req: search request; empty names select the defaults.
+//
+// Returns:
+//   - error: ErrUnknownProfile or ErrUnknownCollection wrapping the name, or nil.
+func (s *SearchService) ValidateSearchTarget(req *SearchRequest) error {
+	_, _, ok, err := s.resolveRequestedProfile(req)
+	if err != nil || ok {
+		return err
+	}
+	_, _, _, err = s.resolveCollection(req.Collection)
+	return err
+}
+
+// collectionInfo describes the Qdrant collection and embedding model of a
+// search route; either may be nil in tests.
+func collectionInfo(name string, repo *repository.QdrantRepository, embedding EmbeddingProvider) CollectionInfo {
+	info := CollectionInfo{Name: name}
+	if repo != nil {
+		info.Qdrant = repo.GetCollectionName()
+	}
+	if embedding != nil {
+		info.Model = embedding.GetModel()
+		info.Dimensions = embedding.GetDimensions()
+	}
+	return info
+}
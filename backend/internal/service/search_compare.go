@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// SearchComparison holds the results of one query in every registered
+// collection, for choosing an embedding model before switching the default.
+type SearchComparison struct {
+	Query         string                 `json:"query"`
+	ExpandedQuery string                 `json:"expanded_query,omitempty"` // Shared by every collection, so only the model differs
+	Baseline      string                 `json:"baseline"`                 // Collection the overlaps are measured against (the default)
+	Collections   []CollectionComparison `json:"collections"`
+}
+
+// CollectionComparison is the ranked result of one collection.
+type CollectionComparison struct {
+	Collection string         `json:"collection"`
+	Model      string         `json:"model"`
+	Default    bool           `json:"default"`
+	LatencyMs  int64          `json:"latency_ms"`
+	Error      string         `json:"error,omitempty"`
+	Overlap    float64        `json:"overlap"`   // Share of baseline results this collection also returned
+	TopMatch   bool           `json:"top_match"` // Same first result as the baseline
+	Results    []SearchResult `json:"results"`
+}
+
+// CompareCollections runs req against every registered collection in
+// parallel and returns the ranked results side by side. The query is
+// expanded once and the expansion reused, and the result cache is skipped.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: search request; Collection and Profile are ignored.
+//
+// Returns:
+//   - *SearchComparison: results of each collection, default first.
+//   - error: non-nil if the filters of req are invalid.
+func (s *SearchService) CompareCollections(ctx context.Context, req *SearchRequest) (*SearchComparison, error) {
+	if _, err := s.buildSearchFilters(req); err != nil {
+		return nil, err
+	}
+	if _, err := buildSearchGrouping(req); err != nil {
+		return nil, err
+	}
+	ctx = logger.WithFields(ctx, logger.Fields{logger.FieldComponent: "search"})
+
+	expanded := ""
+	if release, ok := s.reserveExpansion(ctx, classifyQuery(req.Query), req.Query, req.Lang); ok {
+		result, err := s.queryExpansion.Expand(ctx, req.Query, req.Lang)
+		release()
+		if err != nil {
+			logger.CtxWarn(ctx, "Query expansion failed, comparing the original query: query=%q, error=%v",
+				logger.Query(req.Query), err)
+		} else if result != req.Query {
+			expanded = result
+		}
+	}
+
+	collections := s.ListCollections()
+	comparison := &SearchComparison{
+		Query:         req.Query,
+		ExpandedQuery: expanded,
+		Collections:   make([]CollectionComparison, len(collections)),
+	}
+	var wg sync.WaitGroup
+	for i, collection := range collections {
+		comparison.Collections[i] = CollectionComparison{
+			Collection: collection.Name,
+			Model:      collection.Model,
+			Default:    collection.Default,
+			Results:    []SearchResult{},
+		}
+		wg.Add(1)
+		go func(out *CollectionComparison) {
+			defer wg.Done()
+			collectionReq := *req
+			collectionReq.Collection = out.Collection
+			collectionReq.Profile = ""
+			collectionReq.expansion = expanded
+
+			start := time.Now()
+			resp, err := s.textSearch(ctx, &collectionReq, false)
+			out.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				out.Error = err.Error()
+				return
+			}
+			out.Results = resp.Results
+		}(&comparison.Collections[i])
+	}
+	wg.Wait()
+
+	if len(comparison.Collections) > 0 {
+		baseline := comparison.Collections[0]
+		comparison.Baseline = baseline.Collection
+		for i := range comparison.Collections {
+			cmp := compareShadowResults(baseline.Results, comparison.Collections[i].Results)
+			comparison.Collections[i].Overlap, comparison.Collections[i].TopMatch = cmp.Overlap, cmp.TopMatch
+		}
+	}
+	logger.CtxInfo(ctx, "Compared collections: query=%q, collections=%d", logger.Query(req.Query), len(collections))
+	return comparison, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// unreachableEmbeddingProvider fails every query embedding.
+type unreachableEmbeddingProvider struct {
+	fixedEmbeddingProvider
+	model string
+}
+
+func (p unreachableEmbeddingProvider) EmbedQuery(context.Context, string) ([]float32, error) {
+	return nil, errors.New(p.model + " unreachable")
+}
+
+func (p unreachableEmbeddingProvider) GetModel() string {
+	return p.model
+}
+
+func TestCompareCollectionsReportsEveryCollection(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, &SearchConfig{
+		DefaultCollection: "qwen3",
+	})
+	searchService.RegisterCollection("jina", nil, unreachableEmbeddingProvider{model: "jina-v3"})
+	searchService.RegisterCollection("qwen3", nil, unreachableEmbeddingProvider{model: "qwen3-8b"})
+
+	got, err := searchService.CompareCollections(context.Background(), &SearchRequest{Query: "开心", Collection: "jina"})
+	if err != nil {
+		t.Fatalf("CompareCollections() error = %v", err)
+	}
+	if got.Baseline != "qwen3" || len(got.Collections) != 2 {
+		t.Fatalf("CompareCollections() = %+v, want qwen3 as baseline and two collections", got)
+	}
+	for i, want := range []string{"qwen3", "jina"} {
+		collection := got.Collections[i]
+		if collection.Collection != want || collection.Error == "" || collection.Results == nil {
+			t.Fatalf("collection %d = %+v, want %s with its error and no results", i, collection, want)
+		}
+	}
+	if !got.Collections[0].Default || got.Collections[1].Model != "jina-v3" {
+		t.Fatalf("collections = %+v, want qwen3 default and jina on jina-v3", got.Collections)
+	}
+
+	if _, err := searchService.CompareCollections(context.Background(), &SearchRequest{Query: "开心", GroupBy: "tag"}); err == nil {
+		t.Fatal("CompareCollections() with unsupported group_by error = nil, want error")
+	}
+}
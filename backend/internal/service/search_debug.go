@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+// Retrieval route names reported by DebugSearch.
+const (
+	debugRouteHybrid  = "hybrid"
+	debugRouteDense   = "dense"
+	debugRouteImage   = "image"
+	debugRouteCaption = "caption"
+	debugRouteKeyword = "keyword"
+)
+
+// SearchDebugResponse lists every intermediate artifact of one search, for
+// tuning relevance from the admin console.
+type SearchDebugResponse struct {
+	Query          string                 `json:"query"`
+	Route          QueryRoute             `json:"route"`
+	Expansion      SearchDebugExpansion   `json:"expansion"`
+	Collection     string                 `json:"collection,omitempty"`
+	Profile        string                 `json:"profile,omitempty"`
+	Plan           *SearchDebugPlan       `json:"plan,omitempty"`    // Hybrid prefetch plan of collection searches
+	Weights        *SearchDebugWeights    `json:"weights,omitempty"` // Route-adjusted fusion weights of profile searches
+	Filters        SearchDebugFilters     `json:"filters"`
+	Embeddings     []SearchDebugEmbedding `json:"embeddings"`
+	ScoreThreshold float32                `json:"score_threshold"` // Global dense threshold; category overrides apply per hit
+	Routes         []SearchDebugRoute     `json:"routes"`          // Raw Qdrant hits before thresholding and fusion
+	Results        []SearchResult         `json:"results"`         // What a regular search would return
+	TimingsMs      map[string]int64       `json:"timings_ms"`
+}
+
+// SearchDebugExpansion reports the LLM query expansion stage.
+type SearchDebugExpansion struct {
+	Attempted bool   `json:"attempted"`
+	Expanded  string `json:"expanded,omitempty"` // Empty when expansion was skipped, failed or changed nothing
+	Error     string `json:"error,omitempty"`
+}
+
+// SearchDebugPlan is the hybrid prefetch plan of a collection search.
+type SearchDebugPlan struct {
+	DenseLimit  int    `json:"dense_limit"`
+	SparseLimit int    `json:"sparse_limit"`
+	RRFK        uint32 `json:"rrf_k"`
+	GroupBy     string `json:"group_by,omitempty"`
+	GroupSize   int    `json:"group_size,omitempty"`
+}
+
+// SearchDebugWeights are the fusion weights of a profile search.
+type SearchDebugWeights struct {
+	Image   float32 `json:"image"`
+	Caption float32 `json:"caption"`
+	Keyword float32 `json:"keyword"`
+}
+
+// SearchDebugFilters are the payload filters applied, including those
+// detected from the query.
+type SearchDebugFilters struct {
+	Category      string   `json:"category,omitempty"`
+	SourceType    string   `json:"source_type,omitempty"`
+	Color         string   `json:"color,omitempty"`
+	MinSaturation *float64 `json:"min_saturation,omitempty"`
+	MaxSaturation *float64 `json:"max_saturation,omitempty"`
+	TextLang      string   `json:"text_lang,omitempty"`
+	Scene         string   `json:"scene,omitempty"`
+}
+
+// SearchDebugEmbedding describes one query embedding.
+type SearchDebugEmbedding struct {
+	Route      string  `json:"route"`
+	Model      string  `json:"model"`
+	Dimensions int     `json:"dimensions"`
+	Norm       float64 `json:"norm"` // L2 norm; far from 1 hints at an unnormalized provider
+}
+
+// SearchDebugRoute holds the raw hits of one retrieval route.
+type SearchDebugRoute struct {
+	Name  string           `json:"name"`
+	Limit int              `json:"limit"`
+	Error string           `json:"error,omitempty"`
+	Hits  []SearchDebugHit `json:"hits"`
+}
+
+// SearchDebugHit is one raw Qdrant hit.
+type SearchDebugHit struct {
+	Rank        int     `json:"rank"`
+	MemeID      string  `json:"meme_id"`
+	Score       float32 `json:"score"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	Chunk       int     `json:"chunk,omitempty"`            // Description chunk the point indexes, if any
+	Threshold   *bool   `json:"passes_threshold,omitempty"` // Dense routes only
+}
+
+// DebugSearch runs a search through every stage and returns the intermediate
+// artifacts next to the final results. Collection searches also query the
+// dense route on its own to show which hits the score threshold would keep.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - req: search request parameters.
+//
+// Returns:
+//   - *SearchDebugResponse: artifacts of each stage and the final results.
+//   - error: non-nil if the request is invalid or every route fails.
+func (s *SearchService) DebugSearch(ctx context.Context, req *SearchRequest) (*SearchDebugResponse, error) {
+	if req.TopK <= 0 {
+		req.TopK = 20
+	}
+	if req.TopK > 100 {
+		req.TopK = 100
+	}
+	ctx = logger.WithFields(ctx, logger.Fields{logger.FieldComponent: "search"})
+
+	resp := &SearchDebugResponse{
+		Query:          req.Query,
+		Route:          classifyQuery(req.Query),
+		ScoreThreshold: s.scoreThreshold,
+		TimingsMs:      make(map[string]int64),
+	}
+	stage := func(name string, start time.Time) {
+		resp.TimingsMs[name] = time.Since(start).Milliseconds()
+	}
+
+	start := time.Now()
+	queryForEmbedding := req.Query
+	if release, ok := s.reserveExpansion(ctx, resp.Route, req.Query, req.Lang); ok {
+		resp.Expansion.Attempted = true
+		expanded, err := s.queryExpansion.Expand(ctx, req.Query, req.Lang)
+		release()
+		switch {
+		case err != nil:
+			resp.Expansion.Error = err.Error()
+		case expanded != req.Query:
+			resp.Expansion.Expanded = expanded
+			queryForEmbedding = expanded
+		}
+	}
+	stage("expansion", start)
+
+	filters, err := s.buildSearchFilters(req)
+	if err != nil {
+		return nil, err
+	}
+	grouping, err := buildSearchGrouping(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Filters = debugFilters(filters)
+
+	profile, profileName, ok, err := s.resolveRequestedProfile(req)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		err = s.debugProfile(ctx, resp, req, profileName, profile, queryForEmbedding, filters, grouping, stage)
+	} else {
+		err = s.debugCollection(ctx, resp, req, queryForEmbedding, filters, grouping, stage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.CtxInfo(ctx, "Debug search: query=%q, route=%s, results=%d", logger.Query(req.Query), resp.Route, len(resp.Results))
+	return resp, nil
+}
+
+// debugCollection fills resp with the stages of a single-collection search.
+func (s *SearchService) debugCollection(
+	ctx context.Context,
+	resp *SearchDebugResponse,
+	req *SearchRequest,
+	queryForEmbedding string,
+	filters *repository.SearchFilters,
+	grouping *repository.SearchGrouping,
+	stage func(string, time.Time),
+) error {
+	qdrantRepo, embedding, collectionName, err := s.resolveCollection(req.Collection)
+	if err != nil {
+		return err
+	}
+	resp.Collection = collectionName
+
+	start := time.Now()
+	vector, err := s.embedQuery(ctx, embedding, queryForEmbedding)
+	if err != nil {
+		return fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+	stage("embedding", start)
+	resp.Embeddings = []SearchDebugEmbedding{debugEmbedding(debugRouteDense, embedding, vector)}
+
+	plan := buildHybridPlan(resp.Route, req.TopK)
+	plan.Grouping = grouping
+	resp.Plan = &SearchDebugPlan{DenseLimit: plan.DenseLimit, SparseLimit: plan.SparseLimit, RRFK: plan.RRFK}
+	if grouping != nil {
+		resp.Plan.GroupBy, resp.Plan.GroupSize = grouping.Field, grouping.Size
+	}
+
+	start = time.Now()
+	hybridHits, hybridErr := qdrantRepo.HybridSearch(ctx, vector, req.Query, req.TopK, &plan, filters)
+	stage(debugRouteHybrid, start)
+	resp.Routes = append(resp.Routes, s.debugRoute(debugRouteHybrid, req.TopK, hybridHits, hybridErr, false))
+
+	// The dense route alone shows what thresholding would keep on fallback.
+	start = time.Now()
+	denseHits, denseErr := qdrantRepo.Search(ctx, vector, plan.DenseLimit, filters)
+	stage(debugRouteDense, start)
+	resp.Routes = append(resp.Routes, s.debugRoute(debugRouteDense, plan.DenseLimit, denseHits, denseErr, true))
+
+	if hybridErr == nil {
+		resp.Results = s.collectionResults(dedupeByMemeID(hybridHits), true, req.TopK)
+	} else {
+		fallback, err := qdrantRepo.SearchGroups(ctx, vector, req.TopK, grouping, filters)
+		if err != nil {
+			return fmt.Errorf("failed to search in Qdrant: %w", err)
+		}
+		resp.Results = s.collectionResults(dedupeByMemeID(fallback), false, req.TopK)
+	}
+	s.enrichSearchResults(ctx, resp.Results)
+	return nil
+}
+
+// debugProfile fills resp with the stages of a multi-route profile search.
+func (s *SearchService) debugProfile(
+	ctx context.Context,
+	resp *SearchDebugResponse,
+	req *SearchRequest,
+	profileName string,
+	profile *SearchProfileConfig,
+	queryForEmbedding string,
+	filters *repository.SearchFilters,
+	grouping *repository.SearchGrouping,
+	stage func(string, time.Time),
+) error {
+	if profile == nil || profile.Image == nil || profile.Caption == nil ||
+		profile.Image.QdrantRepo == nil || profile.Image.Embedding == nil ||
+		profile.Caption.QdrantRepo == nil || profile.Caption.Embedding == nil {
+		return fmt.Errorf("profile %q is incomplete", profileName)
+	}
+	resp.Profile = profileName
+
+	start := time.Now()
+	imageVector, err := s.embedQuery(ctx, profile.Image.Embedding, queryForEmbedding)
+	if err != nil {
+		return fmt.Errorf("failed to generate image route query embedding: %w", err)
+	}
+	captionVector, err := s.embedQuery(ctx, profile.Caption.Embedding, queryForEmbedding)
+	if err != nil {
+		return fmt.Errorf("failed to generate caption route query embedding: %w", err)
+	}
+	stage("embedding", start)
+	resp.Embeddings = []SearchDebugEmbedding{
+		debugEmbedding(debugRouteImage, profile.Image.Embedding, imageVector),
+		debugEmbedding(debugRouteCaption, profile.Caption.Embedding, captionVector),
+	}
+
+	weights := routeRetrievalWeights(resp.Route, s.retrieval.Weights)
+	resp.Weights = &SearchDebugWeights{Image: weights.Image, Caption: weights.Caption, Keyword: weights.Keyword}
+
+	start = time.Now()
+	imageHits, imageErr := profile.Image.QdrantRepo.Search(ctx, imageVector, s.retrieval.ImageTopK, filters)
+	stage(debugRouteImage, start)
+	start = time.Now()
+	captionHits, captionErr := profile.Caption.QdrantRepo.Search(ctx, captionVector, s.retrieval.CaptionTopK, filters)
+	stage(debugRouteCaption, start)
+	start = time.Now()
+	keywordHits, keywordErr := profile.Caption.QdrantRepo.SparseSearch(ctx, req.Query, s.retrieval.CaptionTopK, filters)
+	stage(debugRouteKeyword, start)
+	resp.Routes = []SearchDebugRoute{
+		s.debugRoute(debugRouteImage, s.retrieval.ImageTopK, imageHits, imageErr, true),
+		s.debugRoute(debugRouteCaption, s.retrieval.CaptionTopK, captionHits, captionErr, true),
+		s.debugRoute(debugRouteKeyword, s.retrieval.CaptionTopK, keywordHits, keywordErr, false),
+	}
+	if imageErr != nil && captionErr != nil && keywordErr != nil {
+		return fmt.Errorf("all profile search routes failed: image=%v, caption=%v, keyword=%v", imageErr, captionErr, keywordErr)
+	}
+
+	fuseTopK := req.TopK
+	if grouping != nil {
+		fuseTopK = math.MaxInt
+	}
+	resp.Results = fuseProfileResults(imageHits, dedupeByMemeID(captionHits), dedupeByMemeID(keywordHits), weights, fuseTopK)
+	if grouping != nil {
+		resp.Results = limitPerCategory(resp.Results, grouping.Size, req.TopK)
+	}
+	s.enrichSearchResults(ctx, resp.Results)
+	return nil
+}
+
+// debugRoute reports the raw hits of a route; dense routes also report
+// whether each hit passes its score threshold.
+func (s *SearchService) debugRoute(name string, limit int, hits []repository.SearchResult, err error, dense bool) SearchDebugRoute {
+	route := SearchDebugRoute{Name: name, Limit: limit, Hits: make([]SearchDebugHit, 0, len(hits))}
+	if err != nil {
+		route.Error = err.Error()
+		return route
+	}
+	for i, hit := range hits {
+		debugHit := SearchDebugHit{Rank: i + 1, MemeID: hit.ID, Score: hit.Score}
+		if hit.Payload != nil {
+			debugHit.MemeID = hit.Payload.MemeID
+			debugHit.Category = hit.Payload.Category
+			debugHit.Description = hit.Payload.VLMDescription
+			debugHit.Chunk = hit.Payload.Chunk
+		}
+		if dense {
+			passes := s.meetsThreshold(hit.Score, debugHit.Category)
+			debugHit.Threshold = &passes
+		}
+		route.Hits = append(route.Hits, debugHit)
+	}
+	return route
+}
+
+// debugEmbedding describes a query embedding.
+func debugEmbedding(route string, provider EmbeddingProvider, vector []float32) SearchDebugEmbedding {
+	return SearchDebugEmbedding{
+		Route:      route,
+		Model:      provider.GetModel(),
+		Dimensions: len(vector),
+		Norm:       vectorNorm(vector),
+	}
+}
+
+// vectorNorm returns the L2 norm of vector.
+func vectorNorm(vector []float32) float64 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum)
+}
+
+// debugFilters copies the applied payload filters.
+func debugFilters(filters *repository.SearchFilters) SearchDebugFilters {
+	if filters == nil {
+		return SearchDebugFilters{}
+	}
+	return SearchDebugFilters{
+		Category:      stringValue(filters.Category),
+		SourceType:    stringValue(filters.SourceType),
+		Color:         stringValue(filters.Color),
+		MinSaturation: filters.MinSaturation,
+		MaxSaturation: filters.MaxSaturation,
+		TextLang:      stringValue(filters.TextLang),
+		Scene:         stringValue(filters.Scene),
+	}
+}
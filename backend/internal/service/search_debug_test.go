@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/timmy/emomo/internal/repository"
+)
+
+func TestDebugRouteReportsThresholdForDenseHits(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, &SearchConfig{
+		ScoreThreshold:     0.35,
+		CategoryThresholds: map[string]float32{"文字表情": 0.2},
+	})
+	hits := []repository.SearchResult{
+		{ID: "p1", Score: 0.5, Payload: &repository.MemePayload{MemeID: "a", Category: "猫猫"}},
+		{ID: "p2", Score: 0.3, Payload: &repository.MemePayload{MemeID: "b", Category: "文字表情"}},
+		{ID: "p3", Score: 0.3, Payload: &repository.MemePayload{MemeID: "c", Category: "猫猫"}},
+	}
+
+	dense := searchService.debugRoute(debugRouteDense, 3, hits, nil, true)
+	want := []bool{true, true, false}
+	for i, hit := range dense.Hits {
+		if hit.Rank != i+1 || hit.Threshold == nil || *hit.Threshold != want[i] {
+			t.Fatalf("dense hit %d = %+v, want rank %d passing=%v", i, hit, i+1, want[i])
+		}
+	}
+
+	sparse := searchService.debugRoute(debugRouteKeyword, 3, hits, nil, false)
+	if sparse.Hits[0].MemeID != "a" || sparse.Hits[0].Threshold != nil {
+		t.Fatalf("keyword hit = %+v, want meme a without a threshold verdict", sparse.Hits[0])
+	}
+
+	failed := searchService.debugRoute(debugRouteHybrid, 3, nil, errors.New("qdrant down"), false)
+	if failed.Error != "qdrant down" || len(failed.Hits) != 0 {
+		t.Fatalf("failed route = %+v, want the error and no hits", failed)
+	}
+}
+
+func TestVectorNorm(t *testing.T) {
+	t.Parallel()
+
+	if got := vectorNorm([]float32{3, 4}); math.Abs(got-5) > 1e-9 {
+		t.Fatalf("vectorNorm([3 4]) = %v, want 5", got)
+	}
+	if got := vectorNorm(nil); got != 0 {
+		t.Fatalf("vectorNorm(nil) = %v, want 0", got)
+	}
+}
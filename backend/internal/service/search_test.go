@@ -1,10 +1,15 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/timmy/emomo/internal/domain"
 	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
 )
 
 func TestSearchServiceGetAvailableCollectionsUsesConfiguredKeys(t *testing.T) {
@@ -68,7 +73,7 @@ func TestFuseProfileResultsCombinesRoutesByMemeID(t *testing.T) {
 
 	imageResults := []repository.SearchResult{
 		{ID: "point-image-1", Payload: &repository.MemePayload{MemeID: "meme-a", StorageURL: "a.jpg"}},
-		{ID: "point-image-2", Payload: &repository.MemePayload{MemeID: "meme-b", StorageURL: "b.jpg"}},
+		{ID: "point-image-2", Payload: &repository.MemePayload{MemeID: "meme-b", StorageURL: "b.jpg", OCRText: "好的"}},
 	}
 	captionResults := []repository.SearchResult{
 		{ID: "point-caption-1", Payload: &repository.MemePayload{MemeID: "meme-b", StorageURL: "b.jpg"}},
@@ -93,4 +98,194 @@ func TestFuseProfileResultsCombinesRoutesByMemeID(t *testing.T) {
 	if results[0].Score != 1 {
 		t.Fatalf("first result score = %v, want normalized score 1", results[0].Score)
 	}
+	if results[0].OCRText != "好的" {
+		t.Fatalf("first result OCR text = %q, want the payload OCR text", results[0].OCRText)
+	}
+}
+
+func TestRouteRetrievalWeightsBoostsKeywordsForExactQueries(t *testing.T) {
+	t.Parallel()
+
+	imageResults := []repository.SearchResult{
+		{ID: "point-image-1", Payload: &repository.MemePayload{MemeID: "meme-a", StorageURL: "a.jpg"}},
+	}
+	keywordResults := []repository.SearchResult{
+		{ID: "point-keyword-1", Payload: &repository.MemePayload{MemeID: "meme-b", StorageURL: "b.jpg"}},
+	}
+	weights := RetrievalWeights{Image: 0.6, Caption: 0.3, Keyword: 0.3}
+
+	semantic := fuseProfileResults(imageResults, nil, keywordResults, routeRetrievalWeights(QueryRouteSemantic, weights), 20)
+	if len(semantic) != 2 || semantic[0].ID != "meme-a" {
+		t.Fatalf("semantic route first result = %v, want meme-a", semantic)
+	}
+	exact := fuseProfileResults(imageResults, nil, keywordResults, routeRetrievalWeights(QueryRouteExact, weights), 20)
+	if len(exact) != 2 || exact[0].ID != "meme-b" {
+		t.Fatalf("exact route first result = %v, want meme-b", exact)
+	}
+}
+
+func TestBuildSearchGrouping(t *testing.T) {
+	t.Parallel()
+
+	grouping, err := buildSearchGrouping(&SearchRequest{TopK: 20, GroupBy: "category"})
+	if err != nil || grouping == nil || grouping.Field != "category" || grouping.Size != defaultGroupSize {
+		t.Fatalf("buildSearchGrouping(category) = %+v, %v, want category groups of %d", grouping, err, defaultGroupSize)
+	}
+	if grouping, err := buildSearchGrouping(&SearchRequest{TopK: 20}); grouping != nil || err != nil {
+		t.Fatalf("buildSearchGrouping(none) = %+v, %v, want no grouping", grouping, err)
+	}
+	if _, err := buildSearchGrouping(&SearchRequest{TopK: 20, GroupBy: "template_id"}); err == nil {
+		t.Fatal("buildSearchGrouping(template_id) error = nil, want unsupported field")
+	}
+}
+
+func TestLimitPerCategoryKeepsOrder(t *testing.T) {
+	t.Parallel()
+
+	results := []SearchResult{
+		{ID: "a", Category: "猫猫"},
+		{ID: "b", Category: "猫猫"},
+		{ID: "c", Category: "猫猫"},
+		{ID: "d", Category: "狗狗"},
+		{ID: "e", Category: "熊猫头"},
+	}
+	limited := limitPerCategory(results, 2, 3)
+	if len(limited) != 3 || limited[0].ID != "a" || limited[1].ID != "b" || limited[2].ID != "d" {
+		t.Fatalf("limitPerCategory() = %+v, want a, b, d", limited)
+	}
+}
+
+func TestMeetsThresholdUsesCategoryOverrides(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, &SearchConfig{
+		ScoreThreshold:     0.35,
+		CategoryThresholds: map[string]float32{"文字表情": 0.2, "无门槛": 0},
+	})
+	tests := []struct {
+		score    float32
+		category string
+		want     bool
+	}{
+		{0.3, "猫猫", false},
+		{0.4, "猫猫", true},
+		{0.3, "文字表情", true},
+		{0.1, "文字表情", false},
+		{0.01, "无门槛", true},
+	}
+	for _, tt := range tests {
+		if got := searchService.meetsThreshold(tt.score, tt.category); got != tt.want {
+			t.Errorf("meetsThreshold(%v, %q) = %v, want %v", tt.score, tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestSearchServiceGetStatsReportsCollectionCoverage(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	vectorRepo := repository.NewMemeVectorRepository(db)
+	for i := 0; i < 4; i++ {
+		md5Hash := fmt.Sprintf("md5-%d", i)
+		if err := memeRepo.Create(ctx, &domain.Meme{
+			ID:         fmt.Sprintf("meme-%d", i),
+			SourceType: "test",
+			SourceID:   fmt.Sprintf("source-%d", i),
+			MD5Hash:    md5Hash,
+			Status:     domain.MemeStatusActive,
+		}); err != nil {
+			t.Fatalf("Create() meme error = %v", err)
+		}
+		if i == 3 {
+			continue
+		}
+		if err := vectorRepo.Create(ctx, &domain.MemeVector{
+			ID:             fmt.Sprintf("vector-%d", i),
+			MemeID:         fmt.Sprintf("meme-%d", i),
+			MD5Hash:        md5Hash,
+			Collection:     "memes_image",
+			EmbeddingModel: "model",
+			QdrantPointID:  fmt.Sprintf("point-%d", i),
+		}); err != nil {
+			t.Fatalf("Create() vector error = %v", err)
+		}
+	}
+	// A caption vector of a covered meme, a deleted vector and a vector of a
+	// rejected meme add rows but no coverage
+	if err := memeRepo.Create(ctx, &domain.Meme{ID: "meme-gone", SourceType: "test", SourceID: "gone", MD5Hash: "md5-gone",
+		Status: domain.MemeStatusRejected}); err != nil {
+		t.Fatalf("Create() meme error = %v", err)
+	}
+	for _, vector := range []domain.MemeVector{
+		{ID: "caption-0", MemeID: "meme-0", MD5Hash: "md5-0", VectorType: domain.MemeVectorTypeCaption},
+		{ID: "deleted-3", MemeID: "meme-3", MD5Hash: "md5-3", Status: domain.MemeVectorStatusDeleted},
+		{ID: "vector-gone", MemeID: "meme-gone", MD5Hash: "md5-gone"},
+	} {
+		vector.Collection, vector.EmbeddingModel, vector.QdrantPointID = "memes_image", "model", "point-"+vector.ID
+		if err := vectorRepo.Create(ctx, &vector); err != nil {
+			t.Fatalf("Create() vector %s error = %v", vector.ID, err)
+		}
+	}
+
+	newRepo := func(collection string) *repository.QdrantRepository {
+		repo, err := repository.NewQdrantRepository(&repository.QdrantConnectionConfig{
+			Host:       "localhost",
+			Port:       6334,
+			Collection: collection,
+		})
+		if err != nil {
+			t.Fatalf("NewQdrantRepository() error = %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+		return repo
+	}
+
+	searchService := NewSearchService(memeRepo, nil, newRepo("memes_image"), nil, nil, nil, nil, nil)
+	searchService.SetVectorRepository(vectorRepo)
+	searchService.RegisterCollection("caption", newRepo("memes_caption"), nil)
+
+	stats, err := searchService.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+
+	got := stats["collections"]
+	want := []CollectionStats{
+		{Collection: "memes_caption", Vectors: 0, CoveragePct: 0},
+		{Collection: "memes_image", Vectors: 6, Memes: 3, CoveragePct: 75},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("collections = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateSearchTargetRejectsUnknownNames(t *testing.T) {
+	t.Parallel()
+
+	searchService := NewSearchService(nil, nil, nil, nil, nil, nil, nil, &SearchConfig{
+		DefaultCollection: "qwen3",
+	})
+	searchService.RegisterCollection("jina", nil, nil)
+	searchService.RegisterProfile("qwen3vl", nil, nil, nil, nil)
+
+	tests := []struct {
+		req  SearchRequest
+		want error
+	}{
+		{req: SearchRequest{}},
+		{req: SearchRequest{Collection: "qwen3"}},
+		{req: SearchRequest{Collection: "jina"}},
+		{req: SearchRequest{Collection: "qwen3vl"}},
+		{req: SearchRequest{Profile: "qwen3vl"}},
+		{req: SearchRequest{Collection: "clip"}, want: ErrUnknownCollection},
+		{req: SearchRequest{Profile: "clip"}, want: ErrUnknownProfile},
+	}
+	for _, tt := range tests {
+		if err := searchService.ValidateSearchTarget(&tt.req); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Fatalf("ValidateSearchTarget(%+v) error = %v, want %v", tt.req, err, tt.want)
+		}
+	}
 }
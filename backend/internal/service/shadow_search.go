@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+const (
+	defaultShadowTimeout     = 10 * time.Second
+	defaultShadowMaxInFlight = 4
+)
+
+// ShadowSearchConfig mirrors a share of production searches to a canary
+// collection or profile, so a new embedding model can be compared on real
+// traffic before cutover. Shadow searches run after the response is ready and
+// never change it.
+type ShadowSearchConfig struct {
+	Target      string        // Canary collection or profile (empty disables shadow traffic)
+	Percent     float64       // Share of searches mirrored, 0-100
+	Timeout     time.Duration // Deadline of each shadow search (defaults to 10s)
+	MaxInFlight int           // Shadow searches running at once; samples beyond it are dropped (defaults to 4)
+}
+
+// shadowSearch holds the shadow traffic settings and the slots limiting
+// concurrent shadow searches.
+type shadowSearch struct {
+	cfg   ShadowSearchConfig
+	slots chan struct{}
+}
+
+// shadowComparison summarizes how canary results differ from production.
+type shadowComparison struct {
+	Overlap  float64 // Share of production results the canary also returned
+	TopMatch bool    // Both returned the same first result
+}
+
+// SetShadowSearch enables mirroring of production searches to a canary.
+// Parameters:
+//   - cfg: canary target, sample rate and limits; an empty target disables shadow traffic.
+//
+// Returns:
+//   - error: non-nil if the target is not a registered collection or profile.
+func (s *SearchService) SetShadowSearch(cfg ShadowSearchConfig) error {
+	if cfg.Target == "" || cfg.Percent <= 0 {
+		s.shadow = nil
+		return nil
+	}
+	if _, ok := s.profiles[cfg.Target]; !ok {
+		if _, ok := s.collections[cfg.Target]; !ok {
+			return fmt.Errorf("unknown shadow search target: %s", cfg.Target)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultShadowTimeout
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = defaultShadowMaxInFlight
+	}
+	cfg.Percent = min(cfg.Percent, 100)
+	s.shadow = &shadowSearch{cfg: cfg, slots: make(chan struct{}, cfg.MaxInFlight)}
+	return nil
+}
+
+// mirrorSearch samples a finished production search and, when picked, repeats
+// it against the canary in the background and logs how the results compare.
+// The canary reuses the production query expansion and filters, so only the
+// target differs.
+func (s *SearchService) mirrorSearch(ctx context.Context, req *SearchRequest, resp *SearchResponse, latency time.Duration) {
+	shadow := s.shadow
+	if shadow == nil || rand.Float64()*100 >= shadow.cfg.Percent {
+		return
+	}
+	production := resp.Profile
+	if production == "" {
+		production = resp.Collection
+	}
+	if production == shadow.cfg.Target {
+		return
+	}
+	select {
+	case shadow.slots <- struct{}{}:
+	default:
+		logger.CtxDebug(ctx, "Shadow search dropped, canary busy: target=%s", shadow.cfg.Target)
+		return
+	}
+
+	shadowReq := *req
+	shadowReq.Collection = shadow.cfg.Target
+	shadowReq.Profile = ""
+	shadowReq.expansion = resp.ExpandedQuery
+	go func() {
+		defer func() { <-shadow.slots }()
+		shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shadow.cfg.Timeout)
+		defer cancel()
+
+		start := time.Now()
+		canary, err := s.textSearch(shadowCtx, &shadowReq, false)
+		canaryLatency := time.Since(start)
+		if err != nil {
+			logger.CtxWarn(ctx, "Shadow search failed: query=%q, target=%s, error=%v", logger.Query(req.Query), shadow.cfg.Target, err)
+			return
+		}
+		cmp := compareShadowResults(resp.Results, canary.Results)
+		logger.CtxInfo(ctx, "Shadow search: query=%q, production=%s, canary=%s, overlap=%.2f, top_match=%v, "+
+			"production_results=%d, canary_results=%d, production_ms=%d, canary_ms=%d",
+			logger.Query(req.Query), production, shadow.cfg.Target, cmp.Overlap, cmp.TopMatch,
+			len(resp.Results), len(canary.Results), latency.Milliseconds(), canaryLatency.Milliseconds())
+	}()
+}
+
+// compareShadowResults measures the agreement of canary results with
+// production results by meme ID.
+func compareShadowResults(production, canary []SearchResult) shadowComparison {
+	var cmp shadowComparison
+	if len(production) == 0 {
+		if len(canary) == 0 {
+			cmp.Overlap, cmp.TopMatch = 1, true
+		}
+		return cmp
+	}
+	canaryIDs := make(map[string]bool, len(canary))
+	for _, result := range canary {
+		canaryIDs[result.ID] = true
+	}
+	shared := 0
+	for _, result := range production {
+		if canaryIDs[result.ID] {
+			shared++
+		}
+	}
+	cmp.Overlap = float64(shared) / float64(len(production))
+	cmp.TopMatch = len(canary) > 0 && canary[0].ID == production[0].ID
+	return cmp
+}
@@ -0,0 +1,41 @@
+package service
+
+import "testing"
+
+func TestCompareShadowResults(t *testing.T) {
+	t.Parallel()
+
+	production := []SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	canary := []SearchResult{{ID: "a"}, {ID: "c"}, {ID: "x"}}
+	cmp := compareShadowResults(production, canary)
+	if cmp.Overlap != 0.5 || !cmp.TopMatch {
+		t.Fatalf("compareShadowResults() = %+v, want overlap 0.5 and a top match", cmp)
+	}
+
+	cmp = compareShadowResults(production, canary[1:])
+	if cmp.TopMatch {
+		t.Fatalf("compareShadowResults() = %+v, want no top match", cmp)
+	}
+	if cmp := compareShadowResults(nil, nil); cmp.Overlap != 1 || !cmp.TopMatch {
+		t.Fatalf("compareShadowResults(empty, empty) = %+v, want full agreement", cmp)
+	}
+}
+
+func TestSetShadowSearchRequiresKnownTarget(t *testing.T) {
+	t.Parallel()
+
+	search := NewSearchService(nil, nil, nil, nil, nil, nil, nil, nil)
+	search.RegisterCollection("canary", nil, nil)
+	if err := search.SetShadowSearch(ShadowSearchConfig{Target: "missing", Percent: 10}); err == nil {
+		t.Fatalf("SetShadowSearch(missing) error = nil, want an error")
+	}
+	if err := search.SetShadowSearch(ShadowSearchConfig{Target: "canary", Percent: 250}); err != nil {
+		t.Fatalf("SetShadowSearch(canary) error = %v", err)
+	}
+	if search.shadow == nil || search.shadow.cfg.Percent != 100 || cap(search.shadow.slots) != defaultShadowMaxInFlight {
+		t.Fatalf("shadow = %+v, want percent capped at 100 and default slots", search.shadow)
+	}
+	if err := search.SetShadowSearch(ShadowSearchConfig{Target: "canary"}); err != nil || search.shadow != nil {
+		t.Fatalf("SetShadowSearch() with zero percent = %v, shadow %+v, want shadow traffic disabled", err, search.shadow)
+	}
+}
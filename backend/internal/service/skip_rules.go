@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+// errSkipRule is a sentinel error for items filtered out by skip rules.
+var errSkipRule = errors.New("skipped: matched skip rule")
+
+// SkipRules filters source items before they are read, so excluded files cost
+// no download, decoding or VLM call.
+type SkipRules struct {
+	MinFileSize       int64    // Skip local files smaller than this many bytes (0 keeps all sizes)
+	SkipFormats       []string // Skip items with these formats, e.g. "gif" or "webm"
+	IncludeCategories []string // Only ingest categories matching one of these globs (empty includes all)
+	ExcludeCategories []string // Skip categories matching one of these globs
+}
+
+// merge returns the rules with every field set in override replacing the base
+// value. A negative override MinFileSize turns the size check off.
+func (r SkipRules) merge(override *SkipRules) SkipRules {
+	if override == nil {
+		return r
+	}
+	merged := r
+	switch {
+	case override.MinFileSize < 0:
+		merged.MinFileSize = 0
+	case override.MinFileSize > 0:
+		merged.MinFileSize = override.MinFileSize
+	}
+	if override.SkipFormats != nil {
+		merged.SkipFormats = override.SkipFormats
+	}
+	if override.IncludeCategories != nil {
+		merged.IncludeCategories = override.IncludeCategories
+	}
+	if override.ExcludeCategories != nil {
+		merged.ExcludeCategories = override.ExcludeCategories
+	}
+	return merged
+}
+
+// Validate reports malformed category globs.
+// Parameters: none.
+// Returns:
+//   - error: non-nil if a category pattern is not a valid glob.
+func (r SkipRules) Validate() error {
+	for _, pattern := range append(append([]string{}, r.IncludeCategories...), r.ExcludeCategories...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid category pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// check returns why item is skipped, or "" when it should be ingested. The
+// size check only stats local files; other items pass it.
+func (r SkipRules) check(item *source.MemeItem) string {
+	if len(r.IncludeCategories) > 0 && !matchesAnyGlob(r.IncludeCategories, item.Category) {
+		return fmt.Sprintf("category %q not included", item.Category)
+	}
+	if matchesAnyGlob(r.ExcludeCategories, item.Category) {
+		return fmt.Sprintf("category %q excluded", item.Category)
+	}
+
+	format := normalizeSkipFormat(item.Format)
+	for _, skipped := range r.SkipFormats {
+		if normalizeSkipFormat(skipped) == format {
+			return fmt.Sprintf("format %s skipped", format)
+		}
+	}
+
+	if r.MinFileSize > 0 && item.LocalPath != "" {
+		if info, err := os.Stat(item.LocalPath); err == nil && info.Size() < r.MinFileSize {
+			return fmt.Sprintf("file size %d below %d bytes", info.Size(), r.MinFileSize)
+		}
+	}
+	return ""
+}
+
+func matchesAnyGlob(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeSkipFormat(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	switch format {
+	case "jpg":
+		return "jpeg"
+	case "heif":
+		return "heic"
+	}
+	return format
+}
+
+// SetSourceSkipRules sets the skip rules applied to every run of a source.
+// Per-run rules in IngestOptions override them field by field.
+// Parameters:
+//   - sourceID: source identifier the rules apply to.
+//   - rules: skip rules for the source.
+//
+// Returns: none.
+func (s *IngestService) SetSourceSkipRules(sourceID string, rules SkipRules) {
+	if s.skipRules == nil {
+		s.skipRules = make(map[string]SkipRules)
+	}
+	s.skipRules[sourceID] = rules
+}
+
+// skipRulesFor returns the effective skip rules of a run.
+func (s *IngestService) skipRulesFor(sourceID string, opts *IngestOptions) SkipRules {
+	return s.skipRules[sourceID].merge(opts.SkipRules)
+}
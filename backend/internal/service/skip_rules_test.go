@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+func TestSkipRulesCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.png")
+	if err := os.WriteFile(small, testPNG1x1, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	rules := SkipRules{
+		MinFileSize:       2048,
+		SkipFormats:       []string{"JPG", ".webm"},
+		IncludeCategories: []string{"cat*", "dog"},
+		ExcludeCategories: []string{"cat_nsfw"},
+	}
+	tests := []struct {
+		name string
+		item source.MemeItem
+		skip bool
+	}{
+		{name: "included", item: source.MemeItem{Category: "cats", Format: "png"}},
+		{name: "not included", item: source.MemeItem{Category: "frog", Format: "png"}, skip: true},
+		{name: "excluded", item: source.MemeItem{Category: "cat_nsfw", Format: "png"}, skip: true},
+		{name: "jpeg alias", item: source.MemeItem{Category: "dog", Format: "jpeg"}, skip: true},
+		{name: "clip format", item: source.MemeItem{Category: "dog", Format: "webm"}, skip: true},
+		{name: "small file", item: source.MemeItem{Category: "dog", Format: "png", LocalPath: small}, skip: true},
+	}
+	for _, tt := range tests {
+		if reason := rules.check(&tt.item); (reason != "") != tt.skip {
+			t.Fatalf("%s: check() = %q, want skip %v", tt.name, reason, tt.skip)
+		}
+	}
+
+	// Run rules override the source's field by field; -1 turns the size check off.
+	merged := rules.merge(&SkipRules{MinFileSize: -1, IncludeCategories: []string{}})
+	item := source.MemeItem{Category: "frog", Format: "png", LocalPath: small}
+	if reason := merged.check(&item); reason != "" {
+		t.Fatalf("merged check() = %q, want item ingested", reason)
+	}
+	if merged.ExcludeCategories[0] != "cat_nsfw" {
+		t.Fatalf("merged ExcludeCategories = %v, want source rules kept", merged.ExcludeCategories)
+	}
+
+	if err := (SkipRules{ExcludeCategories: []string{"["}}).Validate(); err == nil {
+		t.Fatal("Validate(invalid glob) error = nil, want error")
+	}
+}
+
+func TestIngestFromSourceAppliesSkipRulesBeforeReading(t *testing.T) {
+	t.Parallel()
+
+	ingest := &IngestService{workers: 2, batchSize: 5}
+	ingest.SetSourceSkipRules("scripted", SkipRules{IncludeCategories: []string{"cats"}})
+
+	// The scripted items point at a missing file, so any item that is read fails.
+	stats, err := ingest.IngestFromSource(context.Background(), &scriptedSource{}, 10, nil)
+	if err != nil {
+		t.Fatalf("IngestFromSource() error = %v", err)
+	}
+	if stats.SkippedItems != 10 || stats.FailedItems != 0 {
+		t.Fatalf("stats = %+v, want all items skipped unread", stats)
+	}
+
+	stats, err = ingest.IngestFromSource(context.Background(), &scriptedSource{}, 10, &IngestOptions{
+		SkipRules: &SkipRules{IncludeCategories: []string{}},
+	})
+	if err != nil {
+		t.Fatalf("IngestFromSource() error = %v", err)
+	}
+	if stats.SkippedItems != 0 || stats.FailedItems != 10 {
+		t.Fatalf("stats = %+v, want run rules to include every category", stats)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+// sourceDeleteBatchSize is the number of memes removed per transaction.
+const sourceDeleteBatchSize = 200
+
+// SourceDeleteReport summarizes the removal of all memes of a source. In a dry
+// run the counts describe what would be removed.
+type SourceDeleteReport struct {
+	JobID       string           `json:"job_id,omitempty"`
+	SourceType  string           `json:"source_type"`
+	DryRun      bool             `json:"dry_run"`
+	Status      domain.JobStatus `json:"status"`
+	StartedAt   time.Time        `json:"started_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Error       string           `json:"error,omitempty"`
+
+	Memes         int64 `json:"memes"`
+	Descriptions  int64 `json:"descriptions"`
+	Vectors       int64 `json:"vectors"`        // meme_vectors rows
+	Points        int64 `json:"points"`         // Qdrant points across all vector collections
+	Objects       int64 `json:"objects"`        // Storage objects only this source referenced
+	SharedObjects int64 `json:"shared_objects"` // Storage objects kept for memes of other sources
+	FailedObjects int64 `json:"failed_objects"` // Storage objects whose delete failed
+}
+
+// DeleteSource removes every meme of a source: its Qdrant points in all vector
+// collections, its meme, description and vector rows, and the storage objects
+// no meme of another source references. The run is recorded as an ingest job
+// of kind source_delete. Deleting is idempotent, so a failed run can be retried.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - sourceType: source identifier stored as meme.source_type.
+//   - dryRun: only count what would be removed.
+//
+// Returns:
+//   - *SourceDeleteReport: counts of removed (or removable) records.
+//   - error: non-nil if a Qdrant or database step fails; the report holds
+//     the progress made so far.
+func (s *IngestService) DeleteSource(ctx context.Context, sourceType string, dryRun bool) (*SourceDeleteReport, error) {
+	if sourceType == "" {
+		return nil, errors.New("source type is required")
+	}
+
+	report := &SourceDeleteReport{
+		JobID:      uuid.New().String(),
+		SourceType: sourceType,
+		DryRun:     dryRun,
+		StartedAt:  time.Now(),
+	}
+	job := s.startSourceDeleteJob(ctx, report)
+
+	logger.CtxInfo(ctx, "Deleting source: source=%s, dry_run=%v, job_id=%s", sourceType, dryRun, report.JobID)
+	err := s.deleteSource(ctx, report)
+
+	report.CompletedAt = time.Now()
+	report.Status = domain.JobStatusCompleted
+	if err != nil {
+		report.Status = domain.JobStatusFailed
+		report.Error = err.Error()
+	}
+	s.finishSourceDeleteJob(ctx, job, report)
+	if !dryRun && report.Memes > 0 {
+		s.purgeCache(ctx, []string{SurrogateKeyMemes, SurrogateKeyCategories})
+	}
+
+	if err != nil {
+		return report, err
+	}
+	logger.CtxInfo(ctx, "Source deleted: source=%s, dry_run=%v, memes=%d, points=%d, objects=%d, shared_objects=%d, failed_objects=%d",
+		sourceType, dryRun, report.Memes, report.Points, report.Objects, report.SharedObjects, report.FailedObjects)
+	return report, nil
+}
+
+// deleteSource removes Qdrant points first so search stops returning the
+// source right away, then database rows and storage objects batch by batch.
+func (s *IngestService) deleteSource(ctx context.Context, report *SourceDeleteReport) error {
+	for _, qdrantRepo := range s.sourceCollections() {
+		count, err := qdrantRepo.CountBySourceType(ctx, report.SourceType)
+		if err != nil {
+			return fmt.Errorf("failed to count points in %s: %w", qdrantRepo.GetCollectionName(), err)
+		}
+		report.Points += int64(count)
+		if report.DryRun || count == 0 {
+			continue
+		}
+		if err := qdrantRepo.DeleteBySourceType(ctx, report.SourceType); err != nil {
+			return fmt.Errorf("failed to delete points in %s: %w", qdrantRepo.GetCollectionName(), err)
+		}
+	}
+
+	seenKeys := make(map[string]bool)
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		memes, err := s.memeRepo.ListBySourceType(ctx, report.SourceType, afterID, sourceDeleteBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list source memes: %w", err)
+		}
+		if len(memes) == 0 {
+			return nil
+		}
+		afterID = memes[len(memes)-1].ID
+
+		ids := make([]string, 0, len(memes))
+		var keys []string
+		for _, meme := range memes {
+			ids = append(ids, meme.ID)
+			for _, key := range []string{meme.StorageKey, meme.PosterKey} {
+				if key == "" || seenKeys[key] {
+					continue
+				}
+				seenKeys[key] = true
+				refs, err := s.memeRepo.CountStorageKeyRefs(ctx, key, report.SourceType)
+				if err != nil {
+					return fmt.Errorf("failed to check storage key references: %w", err)
+				}
+				if refs > 0 {
+					report.SharedObjects++
+					continue
+				}
+				keys = append(keys, key)
+			}
+		}
+
+		if s.descRepo != nil {
+			count, err := s.descRepo.CountByMemeIDs(ctx, ids)
+			if err != nil {
+				return fmt.Errorf("failed to count descriptions: %w", err)
+			}
+			report.Descriptions += count
+		}
+		if s.vectorRepo != nil {
+			count, err := s.vectorRepo.CountByMemeIDs(ctx, ids)
+			if err != nil {
+				return fmt.Errorf("failed to count vectors: %w", err)
+			}
+			report.Vectors += count
+		}
+
+		if !report.DryRun {
+			if err := s.deleteMemeRows(ctx, ids); err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err := s.storage.Delete(ctx, key); err != nil {
+					logger.CtxWarn(ctx, "Failed to delete storage object: storage_key=%s, error=%v", key, err)
+					report.FailedObjects++
+				}
+			}
+		}
+		report.Memes += int64(len(memes))
+		report.Objects += int64(len(keys))
+	}
+}
+
+// deleteMemeRows removes memes with their vector and description rows in one transaction.
+func (s *IngestService) deleteMemeRows(ctx context.Context, ids []string) error {
+	return s.withTx(ctx, func(repos *repository.TxRepositories) error {
+		if repos.Vectors != nil {
+			if err := repos.Vectors.DeleteByMemeIDs(ctx, ids); err != nil {
+				return fmt.Errorf("failed to delete vectors: %w", err)
+			}
+		}
+		if repos.Descriptions != nil {
+			if err := repos.Descriptions.DeleteByMemeIDs(ctx, ids); err != nil {
+				return fmt.Errorf("failed to delete descriptions: %w", err)
+			}
+		}
+		if err := repos.Memes.DeleteByIDs(ctx, ids); err != nil {
+			return fmt.Errorf("failed to delete memes: %w", err)
+		}
+		return nil
+	})
+}
+
+// sourceCollections returns one Qdrant repository per vector collection written by ingest.
+func (s *IngestService) sourceCollections() []*repository.QdrantRepository {
+	seen := make(map[string]bool)
+	var repos []*repository.QdrantRepository
+	add := func(repo *repository.QdrantRepository) {
+		if repo == nil || seen[repo.GetCollectionName()] {
+			return
+		}
+		seen[repo.GetCollectionName()] = true
+		repos = append(repos, repo)
+	}
+	for _, index := range s.indexes {
+		add(index.QdrantRepo)
+	}
+	add(s.qdrantRepo)
+	return repos
+}
+
+// startSourceDeleteJob records a running source deletion. Like startJob, it
+// only logs failures.
+func (s *IngestService) startSourceDeleteJob(ctx context.Context, report *SourceDeleteReport) *domain.IngestJob {
+	if s.jobRepo == nil {
+		return nil
+	}
+	job := &domain.IngestJob{
+		ID:        report.JobID,
+		SourceID:  report.SourceType,
+		Kind:      domain.JobKindSourceDelete,
+		Status:    domain.JobStatusRunning,
+		StartedAt: &report.StartedAt,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.CtxWarn(ctx, "Failed to record source delete job: job_id=%s, error=%v", job.ID, err)
+		return nil
+	}
+	return job
+}
+
+// finishSourceDeleteJob stores the final counts and report on the job.
+func (s *IngestService) finishSourceDeleteJob(ctx context.Context, job *domain.IngestJob, report *SourceDeleteReport) {
+	if job == nil {
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to encode source delete report: job_id=%s, error=%v", job.ID, err)
+		return
+	}
+
+	job.Status = report.Status
+	job.TotalItems = int(report.Memes)
+	job.ProcessedItems = int(report.Memes)
+	job.FailedItems = int(report.FailedObjects)
+	job.CompletedAt = &report.CompletedAt
+	job.ErrorLog = report.Error
+	job.Report = string(data)
+	if err := s.jobRepo.Save(context.WithoutCancel(ctx), job); err != nil {
+		logger.CtxWarn(ctx, "Failed to save source delete report: job_id=%s, error=%v", job.ID, err)
+	}
+}
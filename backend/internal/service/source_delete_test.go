@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestDeleteSourceKeepsSharedObjects(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	vectorRepo := repository.NewMemeVectorRepository(db)
+	descRepo := repository.NewMemeDescriptionRepository(db)
+	objects := newMemoryObjectStorage()
+
+	for _, meme := range []*domain.Meme{
+		{ID: "a", SourceType: "old", SourceID: "1", MD5Hash: "a", StorageKey: "a.png"},
+		{ID: "b", SourceType: "old", SourceID: "2", MD5Hash: "b", StorageKey: "shared.png"},
+		{ID: "c", SourceType: "other", SourceID: "1", MD5Hash: "c", StorageKey: "c.mp4", PosterKey: "shared.png"},
+	} {
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+		objects.objects[meme.StorageKey] = []byte("data")
+		if err := descRepo.Create(ctx, &domain.MemeDescription{ID: "d" + meme.ID, MemeID: meme.ID, MD5Hash: meme.MD5Hash, VLMModel: "vlm", Description: "desc"}); err != nil {
+			t.Fatalf("Create(description %s) error = %v", meme.ID, err)
+		}
+		if err := vectorRepo.Create(ctx, &domain.MemeVector{ID: "v" + meme.ID, MemeID: meme.ID, MD5Hash: meme.MD5Hash, Collection: "memes", EmbeddingModel: "m", QdrantPointID: "p" + meme.ID}); err != nil {
+			t.Fatalf("Create(vector %s) error = %v", meme.ID, err)
+		}
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo, vectorRepo: vectorRepo, descRepo: descRepo, storage: objects}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+
+	preview, err := ingest.DeleteSource(ctx, "old", true)
+	if err != nil {
+		t.Fatalf("DeleteSource(dry run) error = %v", err)
+	}
+	if preview.Memes != 2 || preview.Descriptions != 2 || preview.Vectors != 2 || preview.Objects != 1 || preview.SharedObjects != 1 {
+		t.Fatalf("DeleteSource(dry run) = %+v, want 2 memes, 1 object and 1 shared object", preview)
+	}
+	if _, err := memeRepo.GetByID(ctx, "a"); err != nil || len(objects.objects) != 3 {
+		t.Fatalf("dry run removed data: meme error = %v, objects = %d", err, len(objects.objects))
+	}
+
+	report, err := ingest.DeleteSource(ctx, "old", false)
+	if err != nil {
+		t.Fatalf("DeleteSource() error = %v", err)
+	}
+	if report.Memes != 2 || report.Objects != 1 || report.Status != domain.JobStatusCompleted {
+		t.Fatalf("DeleteSource() = %+v, want 2 memes and 1 object removed", report)
+	}
+	if _, ok := objects.objects["a.png"]; ok {
+		t.Fatal("a.png still stored, want it deleted")
+	}
+	if _, ok := objects.objects["shared.png"]; !ok {
+		t.Fatal("shared.png deleted, want it kept for the other source")
+	}
+	if remaining, _ := descRepo.CountByMemeIDs(ctx, []string{"a", "b", "c"}); remaining != 1 {
+		t.Fatalf("remaining descriptions = %d, want 1", remaining)
+	}
+	if remaining, _ := vectorRepo.CountByMemeIDs(ctx, []string{"a", "b", "c"}); remaining != 1 {
+		t.Fatalf("remaining vectors = %d, want 1", remaining)
+	}
+
+	jobs, total, err := ingest.ListIngestJobs(ctx, 10, 0)
+	if err != nil || total != 2 || jobs[0].Kind != domain.JobKindSourceDelete {
+		t.Fatalf("ListIngestJobs() = %+v (total %d), %v, want two source delete jobs", jobs, total, err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/source"
+	"gorm.io/gorm"
+)
+
+// SourceStats summarizes how much of a source has been ingested.
+type SourceStats struct {
+	SourceID      string     `json:"source_id"`
+	DisplayName   string     `json:"display_name"`
+	TotalItems    int64      `json:"total_items"`    // Items currently in the source (-1 if the source cannot count)
+	IngestedItems int64      `json:"ingested_items"` // Active memes from this source
+	PendingItems  int64      `json:"pending_items"`  // Source items not ingested yet (-1 if unknown)
+	LastSyncAt    *time.Time `json:"last_sync_at,omitempty"`
+}
+
+// GetSourceStats reports total, ingested and pending item counts for a source.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - src: data source to inspect.
+//
+// Returns:
+//   - *SourceStats: source statistics.
+//   - error: non-nil if counting fails.
+func (s *IngestService) GetSourceStats(ctx context.Context, src source.Source) (*SourceStats, error) {
+	stats := &SourceStats{
+		SourceID:     src.GetSourceID(),
+		DisplayName:  src.GetDisplayName(),
+		TotalItems:   -1,
+		PendingItems: -1,
+	}
+
+	ingested, err := s.memeRepo.CountBySourceType(ctx, src.GetSourceID(), domain.MemeStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ingested memes: %w", err)
+	}
+	stats.IngestedItems = ingested
+
+	if counter, ok := src.(source.Counter); ok {
+		total, err := counter.GetTotalCount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count source items: %w", err)
+		}
+		stats.TotalItems = int64(total)
+		stats.PendingItems = max(stats.TotalItems-ingested, 0)
+	}
+
+	if s.sourceRepo != nil {
+		ds, err := s.sourceRepo.GetByID(ctx, src.GetSourceID())
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load source sync state: %w", err)
+		}
+		if ds != nil {
+			stats.LastSyncAt = ds.LastSyncAt
+		}
+	}
+
+	return stats, nil
+}
+
+// sourceTypeOf maps a source adapter to the data_sources type column.
+func sourceTypeOf(src source.Source) domain.SourceType {
+	if src.SupportsIncremental() {
+		return domain.SourceTypeAPI
+	}
+	return domain.SourceTypeStatic
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+	"github.com/timmy/emomo/internal/source"
+)
+
+type countingSource struct {
+	id    string
+	total int
+}
+
+func (s countingSource) GetSourceID() string    { return s.id }
+func (s countingSource) GetDisplayName() string { return s.id }
+func (s countingSource) SupportsIncremental() bool {
+	return false
+}
+func (s countingSource) FetchBatch(context.Context, string, int) ([]source.MemeItem, string, error) {
+	return nil, "", nil
+}
+func (s countingSource) GetTotalCount(context.Context) (int, error) { return s.total, nil }
+
+func TestGetSourceStatsCountsPendingAndLastSync(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for i, sourceType := range []string{"localdir", "localdir", "other"} {
+		if err := memeRepo.Create(ctx, &domain.Meme{
+			ID:         fmt.Sprintf("meme-%d", i),
+			SourceType: sourceType,
+			SourceID:   fmt.Sprintf("source-%d", i),
+			MD5Hash:    fmt.Sprintf("md5-%d", i),
+			Status:     domain.MemeStatusActive,
+		}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	sourceRepo := repository.NewDataSourceRepository(db)
+	syncedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := sourceRepo.MarkSynced(ctx, "localdir", "localdir", domain.SourceTypeStatic, syncedAt); err != nil {
+		t.Fatalf("MarkSynced() error = %v", err)
+	}
+
+	ingest := NewIngestService(memeRepo, nil, nil, nil, nil, nil, nil, nil, &IngestConfig{})
+	ingest.SetSourceRepository(sourceRepo)
+
+	stats, err := ingest.GetSourceStats(ctx, countingSource{id: "localdir", total: 5})
+	if err != nil {
+		t.Fatalf("GetSourceStats() error = %v", err)
+	}
+	if stats.TotalItems != 5 || stats.IngestedItems != 2 || stats.PendingItems != 3 {
+		t.Fatalf("stats = total %d, ingested %d, pending %d; want 5, 2, 3",
+			stats.TotalItems, stats.IngestedItems, stats.PendingItems)
+	}
+	if stats.LastSyncAt == nil || !stats.LastSyncAt.Equal(syncedAt) {
+		t.Fatalf("LastSyncAt = %v, want %v", stats.LastSyncAt, syncedAt)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const defaultSparseEncoderTimeout = 30 * time.Second
+
+// SparseEncoder turns document text into learned sparse vectors (e.g. SPLADE)
+// stored next to the server-side BM25 vector.
+type SparseEncoder interface {
+	// EncodeSparse returns one sparse vector per text, in order.
+	EncodeSparse(ctx context.Context, texts []string) ([]repository.SparseVector, error)
+}
+
+// SpladeEncoderConfig configures a SPLADE encoder served over HTTP.
+type SpladeEncoderConfig struct {
+	Endpoint string        // URL of a text-embeddings-inference style /embed_sparse endpoint
+	APIKey   string        // Optional bearer token
+	Timeout  time.Duration // Per-request timeout (0 uses 30s)
+}
+
+// SpladeEncoder calls an /embed_sparse endpoint, which takes {"inputs": [...]}
+// and answers with one list of {"index", "value"} pairs per input.
+type SpladeEncoder struct {
+	client   *resty.Client
+	endpoint string
+}
+
+type spladeRequest struct {
+	Inputs []string `json:"inputs"`
+}
+
+type spladeWeight struct {
+	Index uint32  `json:"index"`
+	Value float32 `json:"value"`
+}
+
+// NewSpladeEncoder creates a SPLADE encoder.
+// Parameters:
+//   - cfg: endpoint, API key and timeout.
+//
+// Returns:
+//   - *SpladeEncoder: initialized encoder.
+//   - error: non-nil if no endpoint is configured.
+func NewSpladeEncoder(cfg *SpladeEncoderConfig) (*SpladeEncoder, error) {
+	if cfg == nil || strings.TrimSpace(cfg.Endpoint) == "" {
+		return nil, fmt.Errorf("sparse encoder endpoint is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSparseEncoderTimeout
+	}
+
+	client := resty.New()
+	client.SetTimeout(timeout)
+	client.SetHeader("Content-Type", "application/json")
+	if cfg.APIKey != "" {
+		client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
+	}
+	return &SpladeEncoder{client: client, endpoint: strings.TrimSpace(cfg.Endpoint)}, nil
+}
+
+// EncodeSparse encodes texts into sparse vectors.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - texts: document texts.
+//
+// Returns:
+//   - []repository.SparseVector: one vector per text.
+//   - error: non-nil if the request fails or the response does not match the input.
+func (e *SpladeEncoder) EncodeSparse(ctx context.Context, texts []string) ([]repository.SparseVector, error) {
+	if len(texts) == 0 {
+		return []repository.SparseVector{}, nil
+	}
+
+	var weights [][]spladeWeight
+	resp, err := e.client.R().
+		SetContext(ctx).
+		SetBody(spladeRequest{Inputs: texts}).
+		SetResult(&weights).
+		Post(e.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call sparse encoder: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("sparse encoder error: status=%d, body=%s", resp.StatusCode(), resp.String())
+	}
+	if len(weights) != len(texts) {
+		return nil, fmt.Errorf("sparse encoder returned %d vectors for %d texts", len(weights), len(texts))
+	}
+
+	vectors := make([]repository.SparseVector, len(weights))
+	for i, terms := range weights {
+		vector := repository.SparseVector{
+			Indices: make([]uint32, len(terms)),
+			Values:  make([]float32, len(terms)),
+		}
+		for j, term := range terms {
+			vector.Indices[j] = term.Index
+			vector.Values[j] = term.Value
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestSpladeEncoderEncodeSparse(t *testing.T) {
+	t.Parallel()
+
+	encoder, err := NewSpladeEncoder(&SpladeEncoderConfig{Endpoint: "https://splade.test/embed_sparse", APIKey: "key"})
+	if err != nil {
+		t.Fatalf("NewSpladeEncoder() error = %v", err)
+	}
+	encoder.client.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		var req spladeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Inputs) != 2 {
+			t.Errorf("request inputs = %v, %v, want two texts", req.Inputs, err)
+		}
+		body := `[[{"index":3,"value":0.5},{"index":7,"value":1.25}],[]]`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}, nil
+	}))
+
+	vectors, err := encoder.EncodeSparse(context.Background(), []string{"猫 生气", ""})
+	if err != nil {
+		t.Fatalf("EncodeSparse() error = %v", err)
+	}
+	if len(vectors) != 2 || len(vectors[0].Indices) != 2 || vectors[0].Indices[1] != 7 || vectors[0].Values[1] != 1.25 || len(vectors[1].Indices) != 0 {
+		t.Fatalf("EncodeSparse() = %+v, want one two-term vector and one empty vector", vectors)
+	}
+
+	if _, err := NewSpladeEncoder(&SpladeEncoderConfig{}); err == nil {
+		t.Fatal("NewSpladeEncoder(empty endpoint) error = nil, want error")
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	// DefaultStatsHistoryDays is the history window used when none is given.
+	DefaultStatsHistoryDays = 30
+	// MaxStatsHistoryDays bounds the history window.
+	MaxStatsHistoryDays = 365
+
+	// statsDateLayout formats the UTC day a snapshot belongs to.
+	statsDateLayout = "2006-01-02"
+)
+
+// StatsHistory is the daily stats of the last days, oldest first.
+type StatsHistory struct {
+	Days    int                    `json:"days"`
+	History []domain.StatsSnapshot `json:"history"` // Days without a snapshot or search are missing
+}
+
+// SetStatsHistoryRepository enables the stats history: searches are counted
+// per day and SnapshotStats can store the daily counts.
+// Parameters:
+//   - statsRepo: repository of daily stats rows.
+//
+// Returns: none.
+func (s *SearchService) SetStatsHistoryRepository(statsRepo *repository.StatsSnapshotRepository) {
+	s.statsRepo = statsRepo
+}
+
+// recordQuery counts a served search for today. It is best-effort: a failure
+// is logged and never fails the search.
+func (s *SearchService) recordQuery(ctx context.Context) {
+	if s.statsRepo == nil {
+		return
+	}
+	date := time.Now().UTC().Format(statsDateLayout)
+	if err := s.statsRepo.AddQueries(ctx, date, 1); err != nil {
+		logger.CtxWarn(ctx, "Failed to record search query: date=%s, error=%v", date, err)
+	}
+}
+
+// SnapshotStats stores today's total memes, memes per source and vectors per
+// collection, replacing an earlier snapshot of the day.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - *domain.StatsSnapshot: the stored counts.
+//   - error: non-nil if the history is disabled or a count or write fails.
+func (s *SearchService) SnapshotStats(ctx context.Context) (*domain.StatsSnapshot, error) {
+	if s.statsRepo == nil {
+		return nil, fmt.Errorf("stats history is not configured")
+	}
+	total, err := s.memeRepo.CountByStatus(ctx, domain.MemeStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memes: %w", err)
+	}
+	sources, err := s.memeRepo.CountBySource(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memes per source: %w", err)
+	}
+	collections, err := s.getCollectionStats(ctx, total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count vectors per collection: %w", err)
+	}
+
+	now := time.Now()
+	snapshot := &domain.StatsSnapshot{
+		Date:        now.UTC().Format(statsDateLayout),
+		TotalMemes:  total,
+		Sources:     make(domain.CountMap, len(sources)),
+		Collections: make(domain.CountMap, len(collections)),
+		SnapshotAt:  &now,
+		UpdatedAt:   now,
+	}
+	for _, source := range sources {
+		snapshot.Sources[source.SourceType] = source.Count
+	}
+	for _, collection := range collections {
+		snapshot.Collections[collection.Collection] = collection.Vectors
+	}
+	if err := s.statsRepo.SaveCounts(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to save stats snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// StartStatsSnapshots takes a stats snapshot right away and then every
+// interval until ctx is cancelled, so each day keeps the counts of its last
+// snapshot.
+// Parameters:
+//   - ctx: context that stops the snapshots when cancelled.
+//   - interval: time between snapshots (0 disables).
+//
+// Returns: none.
+func (s *SearchService) StartStatsSnapshots(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.statsRepo == nil {
+		return
+	}
+	snapshot := func() {
+		if _, err := s.SnapshotStats(ctx); err != nil && ctx.Err() == nil {
+			logger.CtxWarn(ctx, "Stats snapshot failed: error=%v", err)
+		}
+	}
+	go func() {
+		snapshot()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				snapshot()
+			}
+		}
+	}()
+}
+
+// GetStatsHistory returns the daily stats of the last days, today included.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - days: window in days, clamped to [1, MaxStatsHistoryDays].
+//
+// Returns:
+//   - *StatsHistory: daily rows, oldest first; empty when the history is disabled.
+//   - error: non-nil if the query fails.
+func (s *SearchService) GetStatsHistory(ctx context.Context, days int) (*StatsHistory, error) {
+	switch {
+	case days <= 0:
+		days = DefaultStatsHistoryDays
+	case days > MaxStatsHistoryDays:
+		days = MaxStatsHistoryDays
+	}
+	history := &StatsHistory{Days: days, History: []domain.StatsSnapshot{}}
+	if s.statsRepo == nil {
+		return history, nil
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format(statsDateLayout)
+	rows, err := s.statsRepo.ListSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stats history: %w", err)
+	}
+	history.History = append(history.History, rows...)
+	return history, nil
+}
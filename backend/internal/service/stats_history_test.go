@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestStatsHistoryKeepsQueriesAcrossSnapshots(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	for _, meme := range []*domain.Meme{
+		{ID: "a", SourceType: "chinesebqb", Status: domain.MemeStatusActive},
+		{ID: "b", SourceType: "chinesebqb", Status: domain.MemeStatusActive},
+		{ID: "c", SourceType: "upload", Status: domain.MemeStatusActive},
+		{ID: "d", SourceType: "upload", Status: domain.MemeStatusReview},
+	} {
+		meme.SourceID, meme.MD5Hash = meme.ID, "md5-"+meme.ID
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+
+	search := &SearchService{memeRepo: memeRepo}
+	if history, err := search.GetStatsHistory(ctx, 0); err != nil || history.Days != DefaultStatsHistoryDays || len(history.History) != 0 {
+		t.Fatalf("GetStatsHistory() without repository = %+v, %v, want empty default window", history, err)
+	}
+	search.SetStatsHistoryRepository(repository.NewStatsSnapshotRepository(db))
+
+	search.recordQuery(ctx)
+	if _, err := search.SnapshotStats(ctx); err != nil {
+		t.Fatalf("SnapshotStats() error = %v", err)
+	}
+	search.recordQuery(ctx)
+	if _, err := search.SnapshotStats(ctx); err != nil {
+		t.Fatalf("SnapshotStats() again error = %v", err)
+	}
+
+	history, err := search.GetStatsHistory(ctx, MaxStatsHistoryDays+1)
+	if err != nil {
+		t.Fatalf("GetStatsHistory() error = %v", err)
+	}
+	if history.Days != MaxStatsHistoryDays || len(history.History) != 1 {
+		t.Fatalf("GetStatsHistory() = %+v, want one row in a %d day window", history, MaxStatsHistoryDays)
+	}
+	today := history.History[0]
+	if today.Date != time.Now().UTC().Format(statsDateLayout) || today.Queries != 2 || today.TotalMemes != 3 {
+		t.Fatalf("today = %+v, want 2 queries and 3 active memes", today)
+	}
+	if today.Sources["chinesebqb"] != 2 || today.Sources["upload"] != 1 || today.SnapshotAt == nil {
+		t.Fatalf("today sources = %v, snapshot_at = %v, want 2 chinesebqb and 1 upload", today.Sources, today.SnapshotAt)
+	}
+}
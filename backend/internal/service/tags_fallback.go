@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/timmy/emomo/internal/logger"
+)
+
+// TagsFallbackPromptVersion is the prompt version of descriptions built from
+// tags after the VLM failed. It never matches a prompt hash, so ingest
+// --redescribe picks these descriptions up as outdated.
+const TagsFallbackPromptVersion = "tags_fallback"
+
+// DescriptionIssueVLMFailed marks a description built from tags because the
+// VLM failed.
+const DescriptionIssueVLMFailed = "vlm_failed"
+
+// fallbackSubject is what a tags-only description knows about a meme.
+type fallbackSubject struct {
+	Path     string // Local file path, whose name is split into tags
+	SourceID string // Split into tags when Path is empty
+	Category string
+	Tags     []string
+}
+
+// describeImageOrTags runs describeImage and, when the VLM fails and the tags
+// fallback is enabled, describes the meme by its file name, tags and category
+// instead so it is still indexed. The fallback description always scores 0 and
+// is flagged for review. The returned prompt version is the one to store.
+func (s *IngestService) describeImageOrTags(ctx context.Context, imageData []byte, format string, subject fallbackSubject) (string, string, *DescriptionQuality, string, error) {
+	description, ocrText, quality, err := s.describeImage(ctx, imageData, format)
+	if err == nil {
+		return description, ocrText, quality, s.vlm.PromptVersion(), nil
+	}
+	if !s.quality.TagsFallback || ctx.Err() != nil {
+		return "", "", nil, "", err
+	}
+	description = tagsDescription(subject)
+	if description == "" {
+		return "", "", nil, "", err
+	}
+	logger.CtxWarn(ctx, "VLM failed, indexing meme by its tags: path=%s, source_id=%s, error=%v",
+		subject.Path, subject.SourceID, err)
+	return description, "", &DescriptionQuality{Issues: []string{DescriptionIssueVLMFailed}}, TagsFallbackPromptVersion, nil
+}
+
+// tagsDescription builds a description from the category, the tags and the
+// words of the file name. It is empty when none of them carries a word.
+func tagsDescription(subject fallbackSubject) string {
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range subject.Tags {
+		add(tag)
+	}
+	name := subject.Path
+	if name == "" {
+		name = subject.SourceID
+	}
+	for _, tag := range fileNameTags(name) {
+		add(tag)
+	}
+
+	var parts []string
+	if subject.Category != "" && subject.Category != uncategorizedCategory {
+		parts = append(parts, "分类："+subject.Category)
+	}
+	var words []string
+	for _, tag := range tags {
+		if tag != subject.Category {
+			words = append(words, tag)
+		}
+	}
+	if len(words) > 0 {
+		parts = append(parts, "标签："+strings.Join(words, "、"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "表情包。" + strings.Join(parts, "。")
+}
+
+// fileNameTags splits the base name of a path into words, dropping numbers
+// and hash-like tokens that describe nothing.
+func fileNameTags(name string) []string {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	var tags []string
+	for _, token := range strings.FieldsFunc(base, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ':' || unicode.IsSpace(r)
+	}) {
+		if len([]rune(token)) > 1 && !meaninglessToken(token) {
+			tags = append(tags, token)
+		}
+	}
+	return tags
+}
+
+// meaninglessToken reports whether a file name token is a number or a hex
+// hash such as an MD5 or UUID part.
+func meaninglessToken(token string) bool {
+	digits, hex := true, true
+	for _, r := range strings.ToLower(token) {
+		if r < '0' || r > '9' {
+			digits = false
+			if r < 'a' || r > 'f' {
+				hex = false
+			}
+		}
+	}
+	return digits || (hex && len(token) >= 8)
+}
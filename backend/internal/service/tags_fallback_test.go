@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+)
+
+func TestDescribeImageOrTagsFallsBackWhenVLMFails(t *testing.T) {
+	t.Parallel()
+
+	vlm := NewVLMService(&VLMConfig{Model: "test-vlm", APIKey: "test-key", BaseURL: "https://vlm.test/v1"})
+	vlm.client.SetTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(t, http.StatusBadRequest, map[string]any{"error": map[string]string{"message": "image rejected"}}), nil
+	}))
+	subject := fallbackSubject{Path: "/data/memes/猫猫/无语_3f2a9c1d4e5b.jpg", Category: "猫猫", Tags: []string{"猫猫", "摆烂"}}
+	ctx := context.Background()
+
+	ingest := &IngestService{vlm: vlm}
+	if _, _, _, _, err := ingest.describeImageOrTags(ctx, testPNG1x1, "png", subject); err == nil {
+		t.Fatal("describeImageOrTags() without the fallback succeeded, want the VLM error")
+	}
+
+	ingest.quality.TagsFallback = true
+	description, ocrText, quality, promptVersion, err := ingest.describeImageOrTags(ctx, testPNG1x1, "png", subject)
+	if err != nil {
+		t.Fatalf("describeImageOrTags() error = %v", err)
+	}
+	if want := "表情包。分类：猫猫。标签：摆烂、无语"; description != want || ocrText != "" {
+		t.Fatalf("description = %q, ocr = %q, want %q", description, ocrText, want)
+	}
+	if promptVersion != TagsFallbackPromptVersion {
+		t.Fatalf("prompt version = %q, want %q", promptVersion, TagsFallbackPromptVersion)
+	}
+	desc := &domain.MemeDescription{}
+	ingest.applyDescriptionQuality(desc, *quality)
+	if !desc.NeedsReview || !reflect.DeepEqual([]string(desc.QualityIssues), []string{DescriptionIssueVLMFailed}) {
+		t.Fatalf("description = %+v, want flagged with %s", desc, DescriptionIssueVLMFailed)
+	}
+
+	// Nothing to describe the meme by: the VLM error stands
+	if _, _, _, _, err := ingest.describeImageOrTags(ctx, testPNG1x1, "png", fallbackSubject{SourceID: "8f14e45fceea167a.png", Category: "未分类"}); err == nil {
+		t.Fatal("describeImageOrTags() without tags succeeded, want the VLM error")
+	}
+}
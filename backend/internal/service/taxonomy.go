@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	// TaxonomyVersion is the format version of exported taxonomies.
+	TaxonomyVersion = 1
+
+	// taxonomyJobSource is the source ID recorded on taxonomy import jobs.
+	taxonomyJobSource = "taxonomy"
+	// taxonomyBatchSize is the number of memes read per page during an import.
+	taxonomyBatchSize = 200
+	// maxTaxonomyFailures bounds the failed meme IDs kept in a report.
+	maxTaxonomyFailures = 100
+)
+
+// ErrInvalidTaxonomy wraps the reason an imported taxonomy was rejected.
+var ErrInvalidTaxonomy = errors.New("invalid taxonomy")
+
+// Taxonomy is the category and tag vocabulary of the library. An exported
+// taxonomy can be curated and imported again: an entry whose From lists other
+// names renames (one name) or merges (several names) them into its Name.
+// Entries without From change nothing, so re-importing an untouched export is
+// a no-op.
+type Taxonomy struct {
+	Version    int             `json:"version"`
+	ExportedAt *time.Time      `json:"exported_at,omitempty"`
+	Categories []TaxonomyEntry `json:"categories"`
+	Tags       []TaxonomyEntry `json:"tags"`
+}
+
+// TaxonomyEntry is one category or tag of a taxonomy.
+type TaxonomyEntry struct {
+	Name  string   `json:"name"`
+	Count int64    `json:"count,omitempty"` // Active memes carrying the name when exported; ignored on import
+	From  []string `json:"from,omitempty"`  // Names renamed or merged into Name on import
+}
+
+// TaxonomyChange reports one rename or merge of an import.
+type TaxonomyChange struct {
+	Name  string   `json:"name"`
+	From  []string `json:"from"`
+	Memes int64    `json:"memes"` // Memes carrying one of the From names
+}
+
+// TaxonomyImportReport summarizes a taxonomy import. In a dry run the counts
+// describe what would change.
+type TaxonomyImportReport struct {
+	JobID       string           `json:"job_id,omitempty"`
+	DryRun      bool             `json:"dry_run"`
+	Status      domain.JobStatus `json:"status"`
+	StartedAt   time.Time        `json:"started_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Error       string           `json:"error,omitempty"`
+
+	Categories   []TaxonomyChange `json:"categories"`
+	Tags         []TaxonomyChange `json:"tags"`
+	Memes        int64            `json:"memes"`                  // Memes whose category or tags change
+	UpdatedMemes int64            `json:"updated_memes"`          // Memes rewritten in the database and every ingest collection
+	FailedMemes  int64            `json:"failed_memes"`           // Memes whose update failed; importing again retries them
+	Failed       []string         `json:"failed,omitempty"`       // IDs of the first failed memes
+	MovedCovers  []string         `json:"moved_covers,omitempty"` // Categories whose pinned cover moved to the new name
+	Collections  []string         `json:"collections,omitempty"`  // Collections whose payloads were rewritten
+}
+
+// ExportTaxonomy returns every category and tag of active memes with their
+// meme counts, largest first.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//
+// Returns:
+//   - *Taxonomy: the current taxonomy.
+//   - error: non-nil if a query fails.
+func (s *IngestService) ExportTaxonomy(ctx context.Context) (*Taxonomy, error) {
+	categories, err := s.memeRepo.CountByCategory(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count categories: %w", err)
+	}
+	tags, err := s.memeRepo.CountByTag(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	exportedAt := time.Now().UTC()
+	taxonomy := &Taxonomy{
+		Version:    TaxonomyVersion,
+		ExportedAt: &exportedAt,
+		Categories: make([]TaxonomyEntry, 0, len(categories)),
+		Tags:       make([]TaxonomyEntry, 0, len(tags)),
+	}
+	for _, category := range categories {
+		if category.Category == "" {
+			continue
+		}
+		taxonomy.Categories = append(taxonomy.Categories, TaxonomyEntry{Name: category.Category, Count: category.Count})
+	}
+	for _, tag := range tags {
+		taxonomy.Tags = append(taxonomy.Tags, TaxonomyEntry{Name: tag.Tag, Count: tag.Count})
+	}
+	return taxonomy, nil
+}
+
+// ImportTaxonomy applies the renames and merges of a curated taxonomy to
+// every meme, whatever its status: the category and tags in the payloads of
+// all ingest collections first, then the meme row. A meme whose update fails
+// keeps its old names in the database, so importing again retries it. Pinned
+// covers follow renamed categories unless the new name has one. The run is
+// recorded as an ingest job of kind taxonomy_import.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - taxonomy: curated taxonomy.
+//   - dryRun: only count what would change.
+//
+// Returns:
+//   - *TaxonomyImportReport: the changes and their progress.
+//   - error: ErrInvalidTaxonomy, or a database error that stopped the run;
+//     the report then holds the progress made so far.
+func (s *IngestService) ImportTaxonomy(ctx context.Context, taxonomy *Taxonomy, dryRun bool) (*TaxonomyImportReport, error) {
+	categoryRenames, err := taxonomyRenames(taxonomy.Categories)
+	if err != nil {
+		return nil, fmt.Errorf("%w: categories: %v", ErrInvalidTaxonomy, err)
+	}
+	tagRenames, err := taxonomyRenames(taxonomy.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tags: %v", ErrInvalidTaxonomy, err)
+	}
+	if taxonomy.Version != 0 && taxonomy.Version != TaxonomyVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidTaxonomy, taxonomy.Version)
+	}
+
+	report := &TaxonomyImportReport{
+		JobID:      uuid.New().String(),
+		DryRun:     dryRun,
+		StartedAt:  time.Now(),
+		Categories: taxonomyChanges(taxonomy.Categories),
+		Tags:       taxonomyChanges(taxonomy.Tags),
+	}
+	job := s.startTaxonomyJob(ctx, report)
+
+	logger.CtxInfo(ctx, "Importing taxonomy: category_renames=%d, tag_renames=%d, dry_run=%v, job_id=%s",
+		len(categoryRenames), len(tagRenames), dryRun, report.JobID)
+	err = s.importTaxonomy(ctx, categoryRenames, tagRenames, report)
+
+	report.CompletedAt = time.Now()
+	report.Status = domain.JobStatusCompleted
+	if err != nil {
+		report.Status = domain.JobStatusFailed
+		report.Error = err.Error()
+	}
+	s.finishTaxonomyJob(ctx, job, report)
+	if !dryRun && report.UpdatedMemes > 0 {
+		keys := []string{SurrogateKeyMemes, SurrogateKeyCategories}
+		for from, to := range categoryRenames {
+			keys = append(keys, CategorySurrogateKey(from), CategorySurrogateKey(to))
+		}
+		s.purgeCache(ctx, keys)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	logger.CtxInfo(ctx, "Taxonomy imported: memes=%d, updated=%d, failed=%d, moved_covers=%v, dry_run=%v",
+		report.Memes, report.UpdatedMemes, report.FailedMemes, report.MovedCovers, dryRun)
+	return report, nil
+}
+
+// importTaxonomy walks the memes carrying a renamed category or tag in ID
+// order and rewrites each one.
+func (s *IngestService) importTaxonomy(ctx context.Context, categoryRenames, tagRenames map[string]string, report *TaxonomyImportReport) error {
+	collections := s.sourceCollections()
+	if !report.DryRun {
+		for _, qdrantRepo := range collections {
+			report.Collections = append(report.Collections, qdrantRepo.GetCollectionName())
+		}
+	}
+	categoryCounts := make(map[string]int64)
+	tagCounts := make(map[string]int64)
+
+	categories, tags := renameSources(categoryRenames), renameSources(tagRenames)
+	afterID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		memes, err := s.memeRepo.ListByCategoriesOrTags(ctx, categories, tags, afterID, taxonomyBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list memes: %w", err)
+		}
+		if len(memes) == 0 {
+			break
+		}
+		afterID = memes[len(memes)-1].ID
+
+		for i := range memes {
+			meme := &memes[i]
+			category, newTags, changed := renameMemeLabels(meme, categoryRenames, tagRenames)
+			if !changed {
+				continue
+			}
+			report.Memes++
+			if to, ok := categoryRenames[meme.Category]; ok {
+				categoryCounts[to]++
+			}
+			for _, to := range renamedTagTargets(meme.Tags, tagRenames) {
+				tagCounts[to]++
+			}
+			if report.DryRun {
+				continue
+			}
+
+			if err := s.renameMeme(ctx, collections, meme, category, newTags); err != nil {
+				logger.CtxWarn(ctx, "Failed to apply taxonomy to meme: meme_id=%s, error=%v", meme.ID, err)
+				report.FailedMemes++
+				if len(report.Failed) < maxTaxonomyFailures {
+					report.Failed = append(report.Failed, meme.ID)
+				}
+				continue
+			}
+			report.UpdatedMemes++
+		}
+	}
+
+	for i := range report.Categories {
+		report.Categories[i].Memes = categoryCounts[report.Categories[i].Name]
+	}
+	for i := range report.Tags {
+		report.Tags[i].Memes = tagCounts[report.Tags[i].Name]
+	}
+	if report.DryRun {
+		return nil
+	}
+	return s.moveCategoryCovers(ctx, categoryRenames, report)
+}
+
+// renameMeme writes the new category and tags to the payloads of every
+// collection, then to the meme row.
+func (s *IngestService) renameMeme(ctx context.Context, collections []*repository.QdrantRepository, meme *domain.Meme, category string, tags []string) error {
+	update := &repository.PayloadUpdate{Category: &category, Tags: tags}
+	for _, qdrantRepo := range collections {
+		if err := qdrantRepo.SetPayloadByMemeID(ctx, meme.ID, update); err != nil {
+			return fmt.Errorf("failed to update payload in %s: %w", qdrantRepo.GetCollectionName(), err)
+		}
+	}
+	meme.Category = category
+	meme.Tags = tags
+	if err := s.memeRepo.Update(ctx, meme); err != nil {
+		return fmt.Errorf("failed to update meme: %w", err)
+	}
+	return nil
+}
+
+// moveCategoryCovers re-pins the cover of each renamed category under its new
+// name, unless the new name already has a cover.
+func (s *IngestService) moveCategoryCovers(ctx context.Context, categoryRenames map[string]string, report *TaxonomyImportReport) error {
+	if s.coverRepo == nil || len(categoryRenames) == 0 {
+		return nil
+	}
+	covers, err := s.coverRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list category covers: %w", err)
+	}
+	pinned := make(map[string]bool, len(covers))
+	for _, cover := range covers {
+		pinned[cover.Category] = true
+	}
+
+	for _, cover := range covers {
+		to, ok := categoryRenames[cover.Category]
+		if !ok {
+			continue
+		}
+		if !pinned[to] {
+			if err := s.coverRepo.Upsert(ctx, &domain.CategoryCover{Category: to, MemeID: cover.MemeID}); err != nil {
+				return fmt.Errorf("failed to move cover of %s: %w", cover.Category, err)
+			}
+			pinned[to] = true
+			report.MovedCovers = append(report.MovedCovers, cover.Category)
+		}
+		if _, err := s.coverRepo.Delete(ctx, cover.Category); err != nil {
+			return fmt.Errorf("failed to remove cover of %s: %w", cover.Category, err)
+		}
+	}
+	sort.Strings(report.MovedCovers)
+	return nil
+}
+
+// taxonomyRenames maps every From name of the entries to the entry's Name. A
+// name may be renamed only once and not be both renamed and a rename target.
+func taxonomyRenames(entries []TaxonomyEntry) (map[string]string, error) {
+	renames := make(map[string]string)
+	targets := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Name == "" {
+			if len(entry.From) > 0 {
+				return nil, fmt.Errorf("entry with from %v has no name", entry.From)
+			}
+			continue
+		}
+		for _, from := range entry.From {
+			if from == "" || from == entry.Name {
+				continue
+			}
+			if to, ok := renames[from]; ok && to != entry.Name {
+				return nil, fmt.Errorf("%q is renamed to both %q and %q", from, to, entry.Name)
+			}
+			renames[from] = entry.Name
+			targets[entry.Name] = true
+		}
+	}
+	for from := range renames {
+		if targets[from] {
+			return nil, fmt.Errorf("%q is both renamed and a rename target", from)
+		}
+	}
+	return renames, nil
+}
+
+// taxonomyChanges lists the entries that rename or merge other names.
+func taxonomyChanges(entries []TaxonomyEntry) []TaxonomyChange {
+	changes := []TaxonomyChange{}
+	for _, entry := range entries {
+		var from []string
+		for _, name := range entry.From {
+			if name != "" && name != entry.Name {
+				from = append(from, name)
+			}
+		}
+		if entry.Name != "" && len(from) > 0 {
+			changes = append(changes, TaxonomyChange{Name: entry.Name, From: from})
+		}
+	}
+	return changes
+}
+
+// renameSources returns the names renamed by renames, sorted.
+func renameSources(renames map[string]string) []string {
+	names := make([]string, 0, len(renames))
+	for from := range renames {
+		names = append(names, from)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renameMemeLabels returns the category and tags of a meme after renames,
+// dropping tags that a merge made repeated, and whether anything changed.
+func renameMemeLabels(meme *domain.Meme, categoryRenames, tagRenames map[string]string) (string, []string, bool) {
+	category, changed := meme.Category, false
+	if to, ok := categoryRenames[category]; ok {
+		category, changed = to, true
+	}
+
+	renamed := make([]string, len(meme.Tags))
+	for i, tag := range meme.Tags {
+		renamed[i] = tag
+		if to, ok := tagRenames[tag]; ok {
+			renamed[i], changed = to, true
+		}
+	}
+	tags := make([]string, 0, len(renamed))
+	for _, row := range domain.NewMemeTags(meme.ID, renamed) {
+		tags = append(tags, row.Tag)
+	}
+	return category, tags, changed
+}
+
+// renamedTagTargets returns the distinct rename targets of a meme's tags.
+func renamedTagTargets(tags []string, tagRenames map[string]string) []string {
+	seen := make(map[string]bool)
+	var targets []string
+	for _, tag := range tags {
+		if to, ok := tagRenames[tag]; ok && !seen[to] {
+			seen[to] = true
+			targets = append(targets, to)
+		}
+	}
+	return targets
+}
+
+// startTaxonomyJob records a running taxonomy import. Like startJob, it only
+// logs failures.
+func (s *IngestService) startTaxonomyJob(ctx context.Context, report *TaxonomyImportReport) *domain.IngestJob {
+	if s.jobRepo == nil {
+		return nil
+	}
+	job := &domain.IngestJob{
+		ID:        report.JobID,
+		SourceID:  taxonomyJobSource,
+		Kind:      domain.JobKindTaxonomyImport,
+		Status:    domain.JobStatusRunning,
+		StartedAt: &report.StartedAt,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.CtxWarn(ctx, "Failed to record taxonomy import job: job_id=%s, error=%v", job.ID, err)
+		return nil
+	}
+	return job
+}
+
+// finishTaxonomyJob stores the final counts and report on the job.
+func (s *IngestService) finishTaxonomyJob(ctx context.Context, job *domain.IngestJob, report *TaxonomyImportReport) {
+	if job == nil {
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to encode taxonomy import report: job_id=%s, error=%v", job.ID, err)
+		return
+	}
+
+	job.Status = report.Status
+	job.TotalItems = int(report.Memes)
+	job.ProcessedItems = int(report.UpdatedMemes)
+	job.FailedItems = int(report.FailedMemes)
+	job.CompletedAt = &report.CompletedAt
+	job.ErrorLog = report.Error
+	job.Report = string(data)
+	if err := s.jobRepo.Save(context.WithoutCancel(ctx), job); err != nil {
+		logger.CtxWarn(ctx, "Failed to save taxonomy import report: job_id=%s, error=%v", job.ID, err)
+	}
+}
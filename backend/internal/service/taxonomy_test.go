@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func TestImportTaxonomyRenamesAndMerges(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	ctx := context.Background()
+	memeRepo := repository.NewMemeRepository(db)
+	coverRepo := repository.NewCategoryCoverRepository(db)
+	for _, meme := range []*domain.Meme{
+		{ID: "a", Category: "猫猫", Tags: domain.StringArray{"猫咪", "可爱"}, Status: domain.MemeStatusActive},
+		{ID: "b", Category: "狗狗", Tags: domain.StringArray{"猫咪", "小猫"}, Status: domain.MemeStatusActive},
+		{ID: "c", Category: "职场", Tags: domain.StringArray{"加班"}, Status: domain.MemeStatusPending},
+	} {
+		meme.SourceType, meme.SourceID, meme.MD5Hash = "test", meme.ID, "md5-"+meme.ID
+		if err := memeRepo.Create(ctx, meme); err != nil {
+			t.Fatalf("Create(%s) error = %v", meme.ID, err)
+		}
+	}
+	if err := coverRepo.Upsert(ctx, &domain.CategoryCover{Category: "猫猫", MemeID: "a"}); err != nil {
+		t.Fatalf("Upsert(cover) error = %v", err)
+	}
+
+	ingest := &IngestService{memeRepo: memeRepo}
+	ingest.SetJobRepository(repository.NewIngestJobRepository(db))
+	ingest.SetCategoryCoverRepository(coverRepo)
+
+	exported, err := ingest.ExportTaxonomy(ctx)
+	if err != nil {
+		t.Fatalf("ExportTaxonomy() error = %v", err)
+	}
+	if len(exported.Categories) != 2 || exported.Tags[0].Name != "猫咪" || exported.Tags[0].Count != 2 {
+		t.Fatalf("ExportTaxonomy() = %+v, want two active categories and 猫咪 first", exported)
+	}
+	if report, err := ingest.ImportTaxonomy(ctx, exported, false); err != nil || report.Memes != 0 {
+		t.Fatalf("ImportTaxonomy(export) = %+v, %v, want no changes", report, err)
+	}
+
+	curated := &Taxonomy{
+		Version:    TaxonomyVersion,
+		Categories: []TaxonomyEntry{{Name: "动物", From: []string{"猫猫", "狗狗"}}},
+		Tags:       []TaxonomyEntry{{Name: "猫", From: []string{"猫咪", "小猫"}}},
+	}
+	report, err := ingest.ImportTaxonomy(ctx, curated, true)
+	if err != nil || report.Memes != 2 || report.UpdatedMemes != 0 {
+		t.Fatalf("ImportTaxonomy(dry run) = %+v, %v, want 2 memes and none updated", report, err)
+	}
+	if a, _ := memeRepo.GetByID(ctx, "a"); a.Category != "猫猫" {
+		t.Fatalf("category after dry run = %q, want it unchanged", a.Category)
+	}
+
+	report, err = ingest.ImportTaxonomy(ctx, curated, false)
+	if err != nil || report.UpdatedMemes != 2 || report.Status != domain.JobStatusCompleted {
+		t.Fatalf("ImportTaxonomy() = %+v, %v, want 2 memes updated", report, err)
+	}
+	if report.Categories[0].Memes != 2 || report.Tags[0].Memes != 2 {
+		t.Fatalf("ImportTaxonomy() changes = %+v, %+v, want 2 memes each", report.Categories, report.Tags)
+	}
+	b, _ := memeRepo.GetByID(ctx, "b")
+	if b.Category != "动物" || !reflect.DeepEqual([]string(b.Tags), []string{"猫"}) {
+		t.Fatalf("meme b = %q %v, want 动物 [猫]", b.Category, b.Tags)
+	}
+	if memes, _ := memeRepo.ListByTag(ctx, "猫", 10, 0); len(memes) != 2 {
+		t.Fatalf("ListByTag(猫) = %d memes, want 2", len(memes))
+	}
+	covers, _ := coverRepo.List(ctx)
+	if len(covers) != 1 || covers[0].Category != "动物" || covers[0].MemeID != "a" {
+		t.Fatalf("covers = %+v, want the 猫猫 cover moved to 动物", covers)
+	}
+}
+
+func TestImportTaxonomyRejectsConflictingRenames(t *testing.T) {
+	t.Parallel()
+
+	ingest := &IngestService{}
+	for name, taxonomy := range map[string]*Taxonomy{
+		"renamed twice": {Tags: []TaxonomyEntry{{Name: "猫", From: []string{"猫咪"}}, {Name: "喵", From: []string{"猫咪"}}}},
+		"chained":       {Tags: []TaxonomyEntry{{Name: "猫", From: []string{"猫咪"}}, {Name: "动物", From: []string{"猫"}}}},
+		"no name":       {Categories: []TaxonomyEntry{{From: []string{"职场"}}}},
+		"version":       {Version: TaxonomyVersion + 1},
+	} {
+		if _, err := ingest.ImportTaxonomy(context.Background(), taxonomy, true); !errors.Is(err, ErrInvalidTaxonomy) {
+			t.Fatalf("ImportTaxonomy(%s) error = %v, want ErrInvalidTaxonomy", name, err)
+		}
+	}
+}
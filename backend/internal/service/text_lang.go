@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Languages reported for overlaid (OCR) text.
+const (
+	TextLangChinese  = "zh"
+	TextLangEnglish  = "en"
+	TextLangJapanese = "ja"
+)
+
+// detectTextLanguage classifies OCR text by script. Any kana marks the text as
+// Japanese (kanji alone is indistinguishable from Chinese); otherwise Han
+// characters win unless Latin letters clearly dominate, since a Han character
+// carries roughly as much text as a short English word. Returns "" when the
+// text has no letters to judge by.
+func detectTextLanguage(text string) string {
+	var han, kana, latin int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana) && r != 'ー':
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0:
+		return TextLangJapanese
+	case han > 0 && han*3 >= latin:
+		return TextLangChinese
+	case latin >= 2:
+		return TextLangEnglish
+	default:
+		return ""
+	}
+}
+
+// DetectTextLanguage exposes the OCR language detector used by ingest, so
+// cmd/reembed writes the same text_lang payload field.
+func DetectTextLanguage(text string) string {
+	return detectTextLanguage(text)
+}
+
+// normalizeTextLang validates a text language filter value, accepting common aliases.
+func normalizeTextLang(lang string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "":
+		return "", nil
+	case "zh", "zh-cn", "zh-hans", "zh-tw", "zh-hant", "cn":
+		return TextLangChinese, nil
+	case "en":
+		return TextLangEnglish, nil
+	case "ja", "jp":
+		return TextLangJapanese, nil
+	default:
+		return "", fmt.Errorf("unknown text language: %s", lang)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestDetectTextLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "我太难了", want: TextLangChinese},
+		{text: "我太难了 OMG", want: TextLangChinese},
+		{text: "this is fine", want: TextLangEnglish},
+		{text: "I love you 我", want: TextLangEnglish},
+		{text: "お疲れ様です", want: TextLangJapanese},
+		{text: "無理ですね", want: TextLangJapanese},
+		{text: "666 !!!", want: ""},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := detectTextLanguage(tt.text); got != tt.want {
+			t.Fatalf("detectTextLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSearchFiltersNormalizesTextLang(t *testing.T) {
+	t.Parallel()
+
+	search := &SearchService{}
+	jp := "JP"
+	filters, err := search.buildSearchFilters(&SearchRequest{Query: "猫", TextLang: &jp})
+	if err != nil {
+		t.Fatalf("buildSearchFilters() error = %v", err)
+	}
+	if filters.TextLang == nil || *filters.TextLang != TextLangJapanese {
+		t.Fatalf("TextLang = %v, want %q", filters.TextLang, TextLangJapanese)
+	}
+
+	unknown := "fr"
+	if _, err := search.buildSearchFilters(&SearchRequest{Query: "猫", TextLang: &unknown}); err == nil {
+		t.Fatal("buildSearchFilters(fr) error = nil, want unknown text language")
+	}
+}
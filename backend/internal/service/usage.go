@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// usageMonthLayout formats the calendar month usage is counted in.
+const usageMonthLayout = "2006-01"
+
+var (
+	// ErrUnknownAPIKey is returned for a key or key ID that is not configured.
+	ErrUnknownAPIKey = errors.New("unknown api key")
+	// ErrRequestQuotaExceeded is returned when a key used up its monthly requests.
+	ErrRequestQuotaExceeded = errors.New("monthly request quota exceeded")
+	// ErrCostQuotaExceeded is returned when a key used up its monthly cost.
+	ErrCostQuotaExceeded = errors.New("monthly cost quota exceeded")
+)
+
+// APIKeyRole grants a config key access to the ingest and admin routes.
+type APIKeyRole string
+
+const (
+	// APIKeyRoleReadonly may read ingest jobs and admin listings.
+	APIKeyRoleReadonly APIKeyRole = "readonly"
+	// APIKeyRoleAdmin may also trigger ingests and change or delete data.
+	APIKeyRoleAdmin APIKeyRole = "admin"
+)
+
+// Allows reports whether the role grants the access of required.
+// Parameters:
+//   - required: role a route needs.
+//
+// Returns:
+//   - bool: true for admin, and for readonly when readonly access is enough.
+func (r APIKeyRole) Allows(required APIKeyRole) bool {
+	switch r {
+	case APIKeyRoleAdmin:
+		return true
+	case APIKeyRoleReadonly:
+		return required == APIKeyRoleReadonly
+	default:
+		return false
+	}
+}
+
+// KeyUsage is the usage of an API key against its quotas.
+type KeyUsage struct {
+	KeyID           string               `json:"key_id"`
+	MonthlyRequests int64                `json:"monthly_requests"` // Request quota (0 = unlimited)
+	MonthlyCost     float64              `json:"monthly_cost"`     // Cost quota (0 = unlimited)
+	Current         domain.APIKeyUsage   `json:"current"`          // Usage of the current month
+	History         []domain.APIKeyUsage `json:"history"`          // Earlier months, most recent first
+}
+
+// UsageService authenticates API keys, enforces their monthly quotas and
+// records the requests and model tokens spent per key. Keys come from
+// config.yaml or, with SetKeyRepository, from the admin API.
+type UsageService struct {
+	require            bool
+	adminAuth          bool
+	llmCostPer1K       float64
+	embeddingCostPer1K float64
+	keys               []config.APIKeyConfig
+	repo               *repository.APIKeyUsageRepository
+	keyRepo            *repository.APIKeyRepository
+	now                func() time.Time
+}
+
+// NewUsageService creates a new UsageService.
+// Parameters:
+//   - cfg: API keys, quotas and token prices.
+//   - repo: repository of monthly usage counters.
+//
+// Returns:
+//   - *UsageService: initialized service.
+func NewUsageService(cfg *config.APIKeysConfig, repo *repository.APIKeyUsageRepository) *UsageService {
+	return &UsageService{
+		require:            cfg.Require,
+		adminAuth:          cfg.AdminAuthEnabled(),
+		llmCostPer1K:       cfg.LLMCostPer1K,
+		embeddingCostPer1K: cfg.EmbeddingCostPer1K,
+		keys:               cfg.Keys,
+		repo:               repo,
+		now:                time.Now,
+	}
+}
+
+// RequireKey reports whether requests without an API key are rejected.
+// Parameters: none.
+// Returns:
+//   - bool: true when a key is required.
+func (s *UsageService) RequireKey() bool {
+	return s.require
+}
+
+// AdminAuthRequired reports whether ingest and admin routes need a key with a role.
+// Parameters: none.
+// Returns:
+//   - bool: true when api_keys.admin_auth is on or keys are configured.
+func (s *UsageService) AdminAuthRequired() bool {
+	return s.adminAuth
+}
+
+// Authorize checks that a key may use a route of scope. Managed keys need
+// the scope or the admin scope. Config keys may always search; ingest and
+// admin routes need their role when admin auth is required (see
+// config.APIKeysConfig.AdminAuthEnabled): readonly or admin for reads, admin
+// otherwise.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: key ID returned by Authenticate.
+//   - scope: scope of the route.
+//   - readOnly: whether the request only reads (GET or HEAD).
+//
+// Returns:
+//   - error: ErrMissingScope, ErrMissingRole, ErrUnknownAPIKey or a
+//     repository error; nil when the request may proceed.
+func (s *UsageService) Authorize(ctx context.Context, keyID string, scope APIKeyScope, readOnly bool) error {
+	key, err := s.lookup(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.managed {
+		if slices.Contains(key.scopes, string(scope)) || slices.Contains(key.scopes, string(APIKeyScopeAdmin)) {
+			return nil
+		}
+		return ErrMissingScope
+	}
+	if scope == APIKeyScopeSearch || !s.adminAuth {
+		return nil
+	}
+	required := APIKeyRoleAdmin
+	if readOnly {
+		required = APIKeyRoleReadonly
+	}
+	if !key.role.Allows(required) {
+		return ErrMissingRole
+	}
+	return nil
+}
+
+// Authenticate returns the ID of the key whose secret is key. Config keys
+// are checked first, then keys created through the admin API.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - key: secret sent by the client.
+//
+// Returns:
+//   - string: key ID.
+//   - error: ErrUnknownAPIKey if no key matches or the key is revoked,
+//     ErrAPIKeyExpired if it expired, or a repository error.
+func (s *UsageService) Authenticate(ctx context.Context, key string) (string, error) {
+	if key == "" {
+		return "", ErrUnknownAPIKey
+	}
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			return k.ID, nil
+		}
+	}
+	if s.keyRepo != nil && strings.HasPrefix(key, managedKeyPrefix) {
+		return s.authenticateManaged(ctx, key)
+	}
+	return "", ErrUnknownAPIKey
+}
+
+// CheckQuota returns an error when the key used up a quota of the current month.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: key ID returned by Authenticate.
+//
+// Returns:
+//   - error: ErrRequestQuotaExceeded, ErrCostQuotaExceeded, ErrUnknownAPIKey
+//     or a repository error; nil when the request may proceed.
+func (s *UsageService) CheckQuota(ctx context.Context, keyID string) error {
+	key, err := s.lookup(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.monthlyRequests <= 0 && key.monthlyCost <= 0 {
+		return nil
+	}
+
+	current, err := s.repo.Get(ctx, keyID, s.month())
+	if err != nil {
+		return fmt.Errorf("failed to load api key usage: %w", err)
+	}
+	if key.monthlyRequests > 0 && current.Requests >= key.monthlyRequests {
+		return ErrRequestQuotaExceeded
+	}
+	if key.monthlyCost > 0 && current.Cost >= key.monthlyCost {
+		return ErrCostQuotaExceeded
+	}
+	return nil
+}
+
+// Record counts one request of the key and the tokens it spent.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: key ID returned by Authenticate.
+//   - llmTokens: chat completion tokens spent by the request.
+//   - embeddingTokens: embedding tokens spent by the request.
+//
+// Returns:
+//   - error: non-nil if the counters cannot be updated.
+func (s *UsageService) Record(ctx context.Context, keyID string, llmTokens, embeddingTokens int64) error {
+	err := s.repo.Add(ctx, &domain.APIKeyUsage{
+		KeyID:           keyID,
+		Month:           s.month(),
+		Requests:        1,
+		LLMTokens:       llmTokens,
+		EmbeddingTokens: embeddingTokens,
+		Cost:            s.cost(llmTokens, embeddingTokens),
+		UpdatedAt:       s.now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record api key usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsage returns the usage of a key in the current and earlier months.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - keyID: key ID.
+//   - months: maximum number of earlier months to return.
+//
+// Returns:
+//   - *KeyUsage: usage and quotas of the key.
+//   - error: ErrUnknownAPIKey for an unknown ID, or a repository error.
+func (s *UsageService) GetUsage(ctx context.Context, keyID string, months int) (*KeyUsage, error) {
+	key, err := s.lookup(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	month := s.month()
+	current, err := s.repo.Get(ctx, keyID, month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key usage: %w", err)
+	}
+	rows, err := s.repo.ListByKey(ctx, keyID, months+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api key usage: %w", err)
+	}
+	history := make([]domain.APIKeyUsage, 0, len(rows))
+	for _, row := range rows {
+		if row.Month != month && len(history) < months {
+			history = append(history, row)
+		}
+	}
+
+	return &KeyUsage{
+		KeyID:           keyID,
+		MonthlyRequests: key.monthlyRequests,
+		MonthlyCost:     key.monthlyCost,
+		Current:         *current,
+		History:         history,
+	}, nil
+}
+
+// keyInfo is what access checks and quotas need of a config or managed key.
+type keyInfo struct {
+	managed         bool
+	role            APIKeyRole // Config keys
+	scopes          []string   // Managed keys
+	monthlyRequests int64
+	monthlyCost     float64
+}
+
+// lookup returns the config key with the given ID, or else the managed key.
+// Revoked managed keys are still found, so their usage stays readable.
+func (s *UsageService) lookup(ctx context.Context, keyID string) (keyInfo, error) {
+	for _, k := range s.keys {
+		if k.ID == keyID {
+			return keyInfo{
+				role:            APIKeyRole(k.Role),
+				monthlyRequests: k.MonthlyRequests,
+				monthlyCost:     k.MonthlyCost,
+			}, nil
+		}
+	}
+	if s.keyRepo == nil {
+		return keyInfo{}, ErrUnknownAPIKey
+	}
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return keyInfo{}, ErrUnknownAPIKey
+	}
+	if err != nil {
+		return keyInfo{}, fmt.Errorf("failed to load api key: %w", err)
+	}
+	return keyInfo{
+		managed:         true,
+		scopes:          key.Scopes,
+		monthlyRequests: key.MonthlyRequests,
+		monthlyCost:     key.MonthlyCost,
+	}, nil
+}
+
+// month returns the current calendar month in UTC.
+func (s *UsageService) month() string {
+	return s.now().UTC().Format(usageMonthLayout)
+}
+
+// cost prices the tokens of a request.
+func (s *UsageService) cost(llmTokens, embeddingTokens int64) float64 {
+	return float64(llmTokens)/1000*s.llmCostPer1K + float64(embeddingTokens)/1000*s.embeddingCostPer1K
+}
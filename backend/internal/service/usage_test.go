@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/timmy/emomo/internal/config"
+	"github.com/timmy/emomo/internal/repository"
+	"github.com/timmy/emomo/internal/repository/testutil"
+)
+
+func newTestUsageService(t *testing.T, cfg *config.APIKeysConfig) *UsageService {
+	t.Helper()
+
+	db := testutil.NewTestDB(t)
+	return NewUsageService(cfg, repository.NewAPIKeyUsageRepository(db))
+}
+
+func TestUsageServiceAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	s := newTestUsageService(t, &config.APIKeysConfig{
+		Keys: []config.APIKeyConfig{{ID: "a", Key: "secret-a"}, {ID: "b", Key: "secret-b"}},
+	})
+	if id, err := s.Authenticate(context.Background(), "secret-b"); err != nil || id != "b" {
+		t.Fatalf("Authenticate(secret-b) = %q, %v, want b", id, err)
+	}
+	for _, key := range []string{"", "secret", "secret-c"} {
+		if _, err := s.Authenticate(context.Background(), key); !errors.Is(err, ErrUnknownAPIKey) {
+			t.Fatalf("Authenticate(%q) error = %v, want ErrUnknownAPIKey", key, err)
+		}
+	}
+}
+
+func TestUsageServiceRequestQuota(t *testing.T) {
+	t.Parallel()
+
+	s := newTestUsageService(t, &config.APIKeysConfig{
+		Keys: []config.APIKeyConfig{{ID: "a", Key: "secret", MonthlyRequests: 2}},
+	})
+	s.now = func() time.Time { return time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := s.CheckQuota(ctx, "a"); err != nil {
+			t.Fatalf("CheckQuota() before request %d error = %v", i, err)
+		}
+		if err := s.Record(ctx, "a", 0, 0); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := s.CheckQuota(ctx, "a"); !errors.Is(err, ErrRequestQuotaExceeded) {
+		t.Fatalf("CheckQuota() error = %v, want ErrRequestQuotaExceeded", err)
+	}
+
+	// A new month starts with a fresh quota.
+	s.now = func() time.Time { return time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC) }
+	if err := s.CheckQuota(ctx, "a"); err != nil {
+		t.Fatalf("CheckQuota() in a new month error = %v", err)
+	}
+}
+
+func TestUsageServiceCostQuota(t *testing.T) {
+	t.Parallel()
+
+	s := newTestUsageService(t, &config.APIKeysConfig{
+		LLMCostPer1K:       0.5,
+		EmbeddingCostPer1K: 0.1,
+		Keys:               []config.APIKeyConfig{{ID: "a", Key: "secret", MonthlyCost: 1}},
+	})
+	s.now = func() time.Time { return time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	if err := s.Record(ctx, "a", 1000, 2000); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := s.CheckQuota(ctx, "a"); err != nil {
+		t.Fatalf("CheckQuota() at cost 0.7 error = %v", err)
+	}
+	if err := s.Record(ctx, "a", 600, 0); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := s.CheckQuota(ctx, "a"); !errors.Is(err, ErrCostQuotaExceeded) {
+		t.Fatalf("CheckQuota() error = %v, want ErrCostQuotaExceeded", err)
+	}
+
+	got, err := s.GetUsage(ctx, "a", 12)
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	current := got.Current
+	if current.Month != "2026-03" || current.Requests != 2 || current.LLMTokens != 1600 || current.EmbeddingTokens != 2000 {
+		t.Fatalf("Current = %+v, want 2 requests with 1600 LLM and 2000 embedding tokens", current)
+	}
+	if math.Abs(current.Cost-1.0) > 1e-9 {
+		t.Fatalf("Cost = %v, want 1.0", current.Cost)
+	}
+}
+
+func TestUsageServiceGetUsage(t *testing.T) {
+	t.Parallel()
+
+	s := newTestUsageService(t, &config.APIKeysConfig{
+		Keys: []config.APIKeyConfig{{ID: "a", Key: "secret", MonthlyRequests: 100}},
+	})
+	ctx := context.Background()
+	for _, month := range []time.Month{1, 2, 2, 3} {
+		s.now = func() time.Time { return time.Date(2026, month, 10, 0, 0, 0, 0, time.UTC) }
+		if err := s.Record(ctx, "a", 0, 0); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	s.now = func() time.Time { return time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC) }
+	got, err := s.GetUsage(ctx, "a", 2)
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	if got.Current.Month != "2026-04" || got.Current.Requests != 0 || got.MonthlyRequests != 100 {
+		t.Fatalf("GetUsage() = %+v, want an empty current month", got)
+	}
+	if len(got.History) != 2 || got.History[0].Month != "2026-03" || got.History[1].Requests != 2 {
+		t.Fatalf("History = %+v, want 2026-03 and 2026-02", got.History)
+	}
+
+	if _, err := s.GetUsage(ctx, "missing", 2); !errors.Is(err, ErrUnknownAPIKey) {
+		t.Fatalf("GetUsage(missing) error = %v, want ErrUnknownAPIKey", err)
+	}
+}
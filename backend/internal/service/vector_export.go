@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timmy/emomo/internal/domain"
+	"github.com/timmy/emomo/internal/logger"
+	"github.com/timmy/emomo/internal/repository"
+)
+
+const (
+	// defaultExportShardSize is the number of points per Parquet file. A shard
+	// of 2048-dimensional vectors is about 16MB in memory.
+	defaultExportShardSize = 2000
+	// exportScrollPage is the number of points read from Qdrant per request.
+	exportScrollPage = 256
+	// exportKeyPrefix is the storage prefix of export files.
+	exportKeyPrefix = "exports/vectors"
+
+	parquetContentType = "application/vnd.apache.parquet"
+)
+
+// VectorExportReport describes an export of Qdrant points to Parquet files.
+type VectorExportReport struct {
+	JobID       string             `json:"job_id"`
+	Status      domain.JobStatus   `json:"status"`
+	StartedAt   time.Time          `json:"started_at"`
+	CompletedAt time.Time          `json:"completed_at"`
+	Error       string             `json:"error,omitempty"`
+	Points      int64              `json:"points"`
+	Collections []CollectionExport `json:"collections"`
+}
+
+// CollectionExport lists the files written for one collection.
+type CollectionExport struct {
+	Collection string   `json:"collection"`
+	Points     int64    `json:"points"`
+	Files      []string `json:"files"` // Storage keys of the Parquet files
+}
+
+// ExportVectors writes every point of the vector collections to Parquet files
+// in object storage, under exports/vectors/<job_id>/<collection>/. Each row
+// holds meme_id, point_id, the dense vector and the payload as JSON. The run
+// is recorded as an ingest job of kind vector_export.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - collection: collection to export; empty exports all vector collections.
+//   - shardSize: points per file (0 uses 2000).
+//
+// Returns:
+//   - *VectorExportReport: files written per collection.
+//   - error: non-nil if the collection is unknown or reading or uploading
+//     fails; the report lists the files written so far.
+func (s *IngestService) ExportVectors(ctx context.Context, collection string, shardSize int) (*VectorExportReport, error) {
+	repos := s.sourceCollections()
+	if collection != "" {
+		var selected []*repository.QdrantRepository
+		for _, repo := range repos {
+			if repo.GetCollectionName() == collection {
+				selected = append(selected, repo)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("unknown vector collection: %s", collection)
+		}
+		repos = selected
+	}
+	if shardSize <= 0 {
+		shardSize = defaultExportShardSize
+	}
+
+	report := &VectorExportReport{
+		JobID:       uuid.New().String(),
+		StartedAt:   time.Now(),
+		Collections: []CollectionExport{},
+	}
+	job := s.startExportJob(ctx, report)
+	logger.CtxInfo(ctx, "Exporting vectors: collections=%d, shard_size=%d, job_id=%s", len(repos), shardSize, report.JobID)
+
+	var err error
+	for _, repo := range repos {
+		if err = s.exportCollection(ctx, repo, shardSize, report); err != nil {
+			break
+		}
+	}
+
+	report.CompletedAt = time.Now()
+	report.Status = domain.JobStatusCompleted
+	if err != nil {
+		report.Status = domain.JobStatusFailed
+		report.Error = err.Error()
+	}
+	s.finishExportJob(ctx, job, report)
+
+	if err != nil {
+		return report, err
+	}
+	logger.CtxInfo(ctx, "Vectors exported: points=%d, job_id=%s", report.Points, report.JobID)
+	return report, nil
+}
+
+// exportCollection scrolls one collection and uploads it shard by shard.
+func (s *IngestService) exportCollection(ctx context.Context, repo *repository.QdrantRepository, shardSize int, report *VectorExportReport) error {
+	report.Collections = append(report.Collections, CollectionExport{Collection: repo.GetCollectionName(), Files: []string{}})
+	export := &report.Collections[len(report.Collections)-1]
+
+	rows := make([]vectorExportRow, 0, shardSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		key := fmt.Sprintf("%s/%s/%s/part-%05d.parquet", exportKeyPrefix, report.JobID, export.Collection, len(export.Files))
+		var buf bytes.Buffer
+		if err := writeVectorParquet(&buf, rows); err != nil {
+			return err
+		}
+		if err := s.storage.Upload(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), parquetContentType); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", key, err)
+		}
+		export.Files = append(export.Files, key)
+		export.Points += int64(len(rows))
+		report.Points += int64(len(rows))
+		rows = rows[:0]
+		return nil
+	}
+
+	offset := ""
+	for {
+		points, next, err := repo.ScrollPoints(ctx, offset, exportScrollPage, true)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", export.Collection, err)
+		}
+		for _, point := range points {
+			row := vectorExportRow{PointID: point.ID, Vector: point.Vector, Payload: "{}"}
+			if point.Payload != nil {
+				row.MemeID = point.Payload.MemeID
+				data, err := json.Marshal(point.Payload)
+				if err != nil {
+					return fmt.Errorf("failed to encode payload of point %s: %w", point.ID, err)
+				}
+				row.Payload = string(data)
+			}
+			rows = append(rows, row)
+			if len(rows) == shardSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if next == "" || len(points) == 0 {
+			return flush()
+		}
+		offset = next
+	}
+}
+
+// startExportJob records a running vector export. Like startJob, it only logs failures.
+func (s *IngestService) startExportJob(ctx context.Context, report *VectorExportReport) *domain.IngestJob {
+	if s.jobRepo == nil {
+		return nil
+	}
+	job := &domain.IngestJob{
+		ID:        report.JobID,
+		SourceID:  exportKeyPrefix,
+		Kind:      domain.JobKindVectorExport,
+		Status:    domain.JobStatusRunning,
+		StartedAt: &report.StartedAt,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		logger.CtxWarn(ctx, "Failed to record vector export job: job_id=%s, error=%v", job.ID, err)
+		return nil
+	}
+	return job
+}
+
+// finishExportJob stores the final counts and report on the job.
+func (s *IngestService) finishExportJob(ctx context.Context, job *domain.IngestJob, report *VectorExportReport) {
+	if job == nil {
+		return
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		logger.CtxWarn(ctx, "Failed to encode vector export report: job_id=%s, error=%v", job.ID, err)
+		return
+	}
+
+	job.Status = report.Status
+	job.TotalItems = int(report.Points)
+	job.ProcessedItems = int(report.Points)
+	job.CompletedAt = &report.CompletedAt
+	job.ErrorLog = report.Error
+	job.Report = string(data)
+	if err := s.jobRepo.Save(context.WithoutCancel(ctx), job); err != nil {
+		logger.CtxWarn(ctx, "Failed to save vector export report: job_id=%s, error=%v", job.ID, err)
+	}
+}
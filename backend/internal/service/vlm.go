@@ -2,82 +2,42 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"net/http"
 	"time"
 
-	"github.com/go-resty/resty/v2"
+	"github.com/timmy/emomo/internal/llmclient"
+	"github.com/timmy/emomo/internal/prompts"
 )
 
-// EmotionWords is the shared emotion lexicon used by VLM and query expansion.
-// Keep this list in sync with query_expansion.go.
-var EmotionWords = []string{
-	"无语", "尴尬", "开心", "暴怒", "委屈", "嫌弃", "震惊", "疑惑", "得意", "摆烂",
-	"emo", "社死", "破防", "裂开", "绝望", "狂喜", "阴阳怪气", "幸灾乐祸", "无奈", "崩溃",
-	"感动", "害怕", "可爱", "呆萌", "嘲讽", "鄙视", "期待", "失望", "愤怒", "悲伤",
-}
-
-// InternetMemes is the shared meme-slang lexicon used by VLM and query expansion.
-// Keep this list in sync with query_expansion.go.
-var InternetMemes = []string{
-	"芭比Q了(完蛋了)", "绝绝子(太绝了)", "yyds(永远的神)", "真的栓Q(真的谢谢)",
-	"CPU(被PUA)", "一整个xx住", "xx子", "我不理解", "好耶", "啊这", "6",
-	"笑死", "裂开", "麻了", "蚌埠住了", "绷不住了", "DNA动了",
+// promptVersion returns a short hash of the given prompts.
+func promptVersion(texts ...string) string {
+	h := sha256.New()
+	for _, prompt := range texts {
+		h.Write([]byte(prompt))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
 }
 
-const (
-	// VLM System Prompt - 定义角色和规则
-	vlmSystemPrompt = `你是表情包语义分析专家，负责生成用于向量搜索的描述文本。你的描述将被转换为向量，用于语义搜索匹配。
-
-【分析步骤】
-1. 文字提取（最高优先级）：完整提取图片中所有文字，理解文字含义和表达意图
-2. 主体识别：识别人物/动物/卡通形象类型（如熊猫头、蘑菇头、柴犬、猫咪等）
-3. 表情动作：描述面部表情和肢体动作
-4. 情绪标签：选择最匹配的情绪词（无语/尴尬/开心/暴怒/委屈/嫌弃/震惊/疑惑/得意/摆烂/emo/社死/破防/裂开/绝望/狂喜/阴阳怪气/幸灾乐祸/无奈/崩溃/感动/害怕/可爱/呆萌）
-5. 网络梗识别：如涉及流行语需解释含义（芭比Q了/绝绝子/yyds/栓Q/一整个xx住等）
-
-【输出要求】
-- 80-150字自然段落，禁止使用序号或分点
-- 优先级：文字内容 > 情绪表达 > 画面描述
-- 必须嵌入搜索关键词（情绪词、动作词、主体类型词）
-- 无文字图片：重点描述表情、动作和情绪，不要写"图中无文字"`
-
-	// VLM User Prompt - 包含Few-shot示例
-	vlmUserPrompt = `请分析这张表情包图片。
-
-【参考示例】
-示例1：一只熊猫头表情包，文字写着"我不理解"，露出一脸疑惑、无语的表情，歪着脑袋眼神空洞，表达对某事完全不理解、懵逼的状态，适合在困惑、震惊、无法理解对方行为时使用。
-
-示例2：柴犬表情包，狗狗露出标志性的微笑，眼睛眯成一条缝，表情开心、得意、满足，像是在说"我就知道会这样"，带有幸灾乐祸、阴阳怪气的感觉，适合表达暗爽或看好戏的心情。
-
-示例3：蘑菇头表情包，小人双手叉腰，配文"就这？"，表情嫌弃、不屑、鄙视，表达对某事物的轻蔑和失望，觉得不过如此、不值一提。
-
-示例4：一只猫咪瘫倒在地，四仰八叉，表情疲惫、无力、摆烂，眼神空洞望向天花板，表达累了、不想动、彻底放弃挣扎的emo状态。
-
-现在请分析图片并生成描述：`
-
-	// OCR System Prompt - 仅识别图片中的文字
-	vlmOCRSystemPrompt = `你是OCR文字识别助手，只负责提取图片中的文字内容。`
-
-	// OCR User Prompt - 只输出识别文本
-	vlmOCRUserPrompt = `请只输出图片中的文字内容，保持原有顺序与换行，不要解释或添加任何前缀。
-如果图片中没有文字，请输出空字符串。`
-)
-
 // VLMService handles image description generation using Vision Language Models.
 type VLMService struct {
-	client   *resty.Client
-	model    string
-	apiKey   string
-	endpoint string
+	client  *llmclient.Client
+	model   string
+	prompts prompts.Provider
 }
 
 // VLMConfig holds configuration for VLM service.
 type VLMConfig struct {
-	Provider string
-	Model    string
-	APIKey   string
-	BaseURL  string
+	Provider  string
+	Model     string
+	APIKey    string
+	BaseURL   string
+	Prompts   prompts.Provider  // nil uses the built-in prompts
+	Transport http.RoundTripper // nil uses the default HTTP transport
 }
 
 // NewVLMService creates a new VLM service.
@@ -87,24 +47,17 @@ type VLMConfig struct {
 // Returns:
 //   - *VLMService: initialized VLM client wrapper.
 func NewVLMService(cfg *VLMConfig) *VLMService {
-	client := resty.New()
-	client.SetHeader("Authorization", "Bearer "+cfg.APIKey)
-	client.SetHeader("Content-Type", "application/json")
-	// Set timeout to prevent hanging requests
-	client.SetTimeout(60 * time.Second)
-
-	// Default to OpenAI compatible endpoint if not specified
-	baseURL := cfg.BaseURL
-	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
-	}
-	endpoint := baseURL + "/chat/completions"
-
 	return &VLMService{
-		client:   client,
-		model:    cfg.Model,
-		apiKey:   cfg.APIKey,
-		endpoint: endpoint,
+		client: llmclient.New(llmclient.Config{
+			APIKey:  cfg.APIKey,
+			BaseURL: cfg.BaseURL,
+			// Set timeout to prevent hanging requests
+			Timeout:    60 * time.Second,
+			MaxRetries: 2,
+			Transport:  cfg.Transport,
+		}),
+		model:   cfg.Model,
+		prompts: prompts.OrDefault(cfg.Prompts),
 	}
 }
 
@@ -116,43 +69,23 @@ func (s *VLMService) GetModel() string {
 	return s.model
 }
 
-// OpenAI-compatible Chat Completion API request/response structures
-type openAIRequest struct {
-	Model     string          `json:"model"`
-	Messages  []openAIMessage `json:"messages"`
-	MaxTokens int             `json:"max_tokens"`
-}
-
-type openAIMessage struct {
-	Role    string      `json:"role"`
-	Content interface{} `json:"content"` // string for system, []interface{} for user with images
-}
-
-type openAITextContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
-type openAIImageContent struct {
-	Type     string         `json:"type"`
-	ImageURL openAIImageURL `json:"image_url"`
-}
-
-type openAIImageURL struct {
-	URL    string `json:"url"`
-	Detail string `json:"detail,omitempty"`
+// PromptVersion returns the version of the description prompts. It changes
+// whenever the prompts are edited or overridden, so descriptions from older
+// prompts can be found and regenerated.
+// Parameters: none.
+// Returns:
+//   - string: short hash of the current description prompts.
+func (s *VLMService) PromptVersion() string {
+	p := s.prompts.Prompts()
+	return promptVersion(p.VLMSystem, p.VLMUser)
 }
 
-type openAIResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-	} `json:"error,omitempty"`
+// Usage returns the tokens used by all VLM calls of this service.
+// Parameters: none.
+// Returns:
+//   - llmclient.Usage: accumulated token usage.
+func (s *VLMService) Usage() llmclient.Usage {
+	return s.client.TotalUsage()
 }
 
 // DescribeImage generates a description for an image.
@@ -165,80 +98,31 @@ type openAIResponse struct {
 //   - string: generated description text.
 //   - error: non-nil if the API request fails.
 func (s *VLMService) DescribeImage(ctx context.Context, imageData []byte, format string) (string, error) {
-	// Determine MIME type
-	mimeType := getMIMEType(format)
-
-	// Encode image to base64
-	base64Image := base64.StdEncoding.EncodeToString(imageData)
-	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64Image)
-
-	// Build request with system/user separation
-	req := openAIRequest{
-		Model: s.model,
-		Messages: []openAIMessage{
-			{
-				Role:    "system",
-				Content: vlmSystemPrompt,
-			},
-			{
-				Role: "user",
-				Content: []interface{}{
-					openAITextContent{
-						Type: "text",
-						Text: vlmUserPrompt,
-					},
-					openAIImageContent{
-						Type: "image_url",
-						ImageURL: openAIImageURL{
-							URL:    dataURL,
-							Detail: "auto", // Use auto for better text recognition
-						},
-					},
-				},
-			},
-		},
-		MaxTokens: 300,
-	}
+	p := s.prompts.Prompts()
+	return s.describeImage(ctx, imageDataURL(imageData, format), p.VLMSystem, p.VLMUser)
+}
 
-	// Send request
-	var resp openAIResponse
-	httpResp, err := s.client.R().
-		SetContext(ctx).
-		SetBody(req).
-		SetResult(&resp).
-		Post(s.endpoint)
+// DescribeImageStrict regenerates a description with stricter instructions,
+// used when the first description fails the quality check.
+// Parameters:
+//   - ctx: context for cancellation and deadlines.
+//   - imageData: raw image bytes (must be in a VLM-supported format: jpg, png).
+//   - format: image format extension (jpg, png).
+//
+// Returns:
+//   - string: generated description text.
+//   - error: non-nil if the API request fails.
+func (s *VLMService) DescribeImageStrict(ctx context.Context, imageData []byte, format string) (string, error) {
+	p := s.prompts.Prompts()
+	return s.describeImage(ctx, imageDataURL(imageData, format), p.VLMSystem, p.VLMUser+p.VLMStrictRetry)
+}
 
+func (s *VLMService) describeImage(ctx context.Context, imageURL, systemPrompt, userPrompt string) (string, error) {
+	resp, err := s.client.Chat(ctx, imageRequest(s.model, systemPrompt, userPrompt, imageURL, 300))
 	if err != nil {
 		return "", fmt.Errorf("failed to call VLM API: %w", err)
 	}
-
-	// Check HTTP status code
-	if httpResp.StatusCode() < 200 || httpResp.StatusCode() >= 300 {
-		// Try to get error message from response body
-		errorMsg := fmt.Sprintf("HTTP %d", httpResp.StatusCode())
-		if resp.Error != nil {
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), resp.Error.Message)
-		} else {
-			// Include response body for debugging
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("VLM API returned error: %s", errorMsg)
-	}
-
-	if resp.Error != nil {
-		return "", fmt.Errorf("VLM API error: %s", resp.Error.Message)
-	}
-
-	if len(resp.Choices) == 0 {
-		// Include more context in error message
-		errorMsg := fmt.Sprintf("no choices in response (status: %d)", httpResp.StatusCode())
-		if len(httpResp.Body()) > 0 {
-			errorMsg += fmt.Sprintf(", response body: %s", string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("no response from VLM API: %s", errorMsg)
-	}
-
-	return resp.Choices[0].Message.Content, nil
+	return resp.Content, nil
 }
 
 // ExtractOCRText extracts text from an image using the VLM OCR prompt.
@@ -251,74 +135,13 @@ func (s *VLMService) DescribeImage(ctx context.Context, imageData []byte, format
 //   - string: extracted OCR text (may be empty).
 //   - error: non-nil if the API request fails.
 func (s *VLMService) ExtractOCRText(ctx context.Context, imageData []byte, format string) (string, error) {
-	// Determine MIME type
-	mimeType := getMIMEType(format)
-
-	// Encode image to base64
-	base64Image := base64.StdEncoding.EncodeToString(imageData)
-	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, base64Image)
-
-	req := openAIRequest{
-		Model: s.model,
-		Messages: []openAIMessage{
-			{
-				Role:    "system",
-				Content: vlmOCRSystemPrompt,
-			},
-			{
-				Role: "user",
-				Content: []interface{}{
-					openAITextContent{
-						Type: "text",
-						Text: vlmOCRUserPrompt,
-					},
-					openAIImageContent{
-						Type: "image_url",
-						ImageURL: openAIImageURL{
-							URL:    dataURL,
-							Detail: "auto",
-						},
-					},
-				},
-			},
-		},
-		MaxTokens: 400,
-	}
-
-	var resp openAIResponse
-	httpResp, err := s.client.R().
-		SetContext(ctx).
-		SetBody(req).
-		SetResult(&resp).
-		Post(s.endpoint)
-
+	p := s.prompts.Prompts()
+	req := imageRequest(s.model, p.OCRSystem, p.OCRUser, imageDataURL(imageData, format), 400)
+	resp, err := s.client.Chat(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("failed to call VLM OCR API: %w", err)
 	}
-
-	if httpResp.StatusCode() < 200 || httpResp.StatusCode() >= 300 {
-		errorMsg := fmt.Sprintf("HTTP %d", httpResp.StatusCode())
-		if resp.Error != nil {
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), resp.Error.Message)
-		} else {
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("VLM OCR API returned error: %s", errorMsg)
-	}
-
-	if resp.Error != nil {
-		return "", fmt.Errorf("VLM OCR API error: %s", resp.Error.Message)
-	}
-
-	if len(resp.Choices) == 0 {
-		errorMsg := fmt.Sprintf("no choices in response (status: %d)", httpResp.StatusCode())
-		if len(httpResp.Body()) > 0 {
-			errorMsg += fmt.Sprintf(", response body: %s", string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("no response from VLM OCR API: %s", errorMsg)
-	}
-
-	return resp.Choices[0].Message.Content, nil
+	return resp.Content, nil
 }
 
 // DescribeImageFromURL generates a description for an image from URL.
@@ -330,72 +153,33 @@ func (s *VLMService) ExtractOCRText(ctx context.Context, imageData []byte, forma
 //   - string: generated description text.
 //   - error: non-nil if the API request fails.
 func (s *VLMService) DescribeImageFromURL(ctx context.Context, imageURL string) (string, error) {
-	// Build request with system/user separation
-	req := openAIRequest{
-		Model: s.model,
-		Messages: []openAIMessage{
-			{
-				Role:    "system",
-				Content: vlmSystemPrompt,
-			},
+	p := s.prompts.Prompts()
+	return s.describeImage(ctx, imageURL, p.VLMSystem, p.VLMUser)
+}
+
+// imageRequest builds a system prompt plus a user message carrying the prompt
+// text and one image.
+func imageRequest(model, systemPrompt, userPrompt, imageURL string, maxTokens int) llmclient.ChatRequest {
+	return llmclient.ChatRequest{
+		Model: model,
+		Messages: []llmclient.Message{
+			{Role: "system", Content: systemPrompt},
 			{
 				Role: "user",
-				Content: []interface{}{
-					openAITextContent{
-						Type: "text",
-						Text: vlmUserPrompt,
-					},
-					openAIImageContent{
-						Type: "image_url",
-						ImageURL: openAIImageURL{
-							URL:    imageURL,
-							Detail: "auto", // Use auto for better text recognition
-						},
-					},
+				Content: []llmclient.ContentPart{
+					{Type: "text", Text: userPrompt},
+					// Use auto for better text recognition
+					{Type: "image_url", ImageURL: &llmclient.ImageURL{URL: imageURL, Detail: "auto"}},
 				},
 			},
 		},
-		MaxTokens: 300,
-	}
-
-	var resp openAIResponse
-	httpResp, err := s.client.R().
-		SetContext(ctx).
-		SetBody(req).
-		SetResult(&resp).
-		Post(s.endpoint)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to call VLM API: %w", err)
-	}
-
-	// Check HTTP status code
-	if httpResp.StatusCode() < 200 || httpResp.StatusCode() >= 300 {
-		// Try to get error message from response body
-		errorMsg := fmt.Sprintf("HTTP %d", httpResp.StatusCode())
-		if resp.Error != nil {
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), resp.Error.Message)
-		} else {
-			// Include response body for debugging
-			errorMsg = fmt.Sprintf("HTTP %d: %s", httpResp.StatusCode(), string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("VLM API returned error: %s", errorMsg)
-	}
-
-	if resp.Error != nil {
-		return "", fmt.Errorf("VLM API error: %s", resp.Error.Message)
-	}
-
-	if len(resp.Choices) == 0 {
-		// Include more context in error message
-		errorMsg := fmt.Sprintf("no choices in response (status: %d)", httpResp.StatusCode())
-		if len(httpResp.Body()) > 0 {
-			errorMsg += fmt.Sprintf(", response body: %s", string(httpResp.Body()))
-		}
-		return "", fmt.Errorf("no response from VLM API: %s", errorMsg)
+		MaxTokens: maxTokens,
 	}
+}
 
-	return resp.Choices[0].Message.Content, nil
+// imageDataURL encodes image bytes as a base64 data URL.
+func imageDataURL(imageData []byte, format string) string {
+	return fmt.Sprintf("data:%s;base64,%s", getMIMEType(format), base64.StdEncoding.EncodeToString(imageData))
 }
 
 func getMIMEType(format string) string {
@@ -0,0 +1,85 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RefererOrigin as DownloadConfig.Referer sends the scheme and host of each
+// requested URL, which satisfies most hotlink checks.
+const RefererOrigin = "origin"
+
+// DownloadConfig configures HTTP downloads of remote images. It is shared by
+// the ingest of URL items and by adapters that fetch from the web, so every
+// outbound image request leaves through the same proxy with the same headers.
+type DownloadConfig struct {
+	Proxy     string // http, https, socks5 or socks5h proxy URL (empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY)
+	UserAgent string // Replaces the User-Agent of every request (empty keeps the client's own)
+	Referer   string // Sent when a request has none; RefererOrigin sends the URL's scheme and host
+}
+
+// NewDownloadTransport returns the transport of image downloads, applying
+// the proxy and headers of cfg.
+// Parameters:
+//   - cfg: proxy and headers of downloads.
+//
+// Returns:
+//   - http.RoundTripper: transport for HTTP clients of image downloads.
+//   - error: non-nil if the proxy URL is malformed or of an unsupported scheme.
+func NewDownloadTransport(cfg DownloadConfig) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy := strings.TrimSpace(cfg.Proxy); proxy != "" {
+		// Errors name the proxy without its password, as they end up in logs.
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return nil, fmt.Errorf("invalid download proxy: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("invalid download proxy %q: scheme must be http, https, socks5 or socks5h", proxyURL.Redacted())
+		}
+		if proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid download proxy %q: missing host", proxyURL.Redacted())
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	userAgent := strings.TrimSpace(cfg.UserAgent)
+	referer := strings.TrimSpace(cfg.Referer)
+	if userAgent == "" && referer == "" {
+		return transport, nil
+	}
+	return &headerTransport{next: transport, userAgent: userAgent, referer: referer}, nil
+}
+
+// headerTransport sets the configured User-Agent and Referer on requests.
+type headerTransport struct {
+	next      http.RoundTripper
+	userAgent string
+	referer   string
+}
+
+// RoundTrip sends a copy of req with the configured headers; a RoundTripper
+// must not modify the request it was given.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	if t.referer != "" && req.Header.Get("Referer") == "" {
+		referer := t.referer
+		if referer == RefererOrigin {
+			referer = req.URL.Scheme + "://" + req.URL.Host + "/"
+		}
+		req.Header.Set("Referer", referer)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -1,6 +1,11 @@
 package source
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+)
 
 // MemeItem represents a meme item from a data source.
 type MemeItem struct {
@@ -10,6 +15,48 @@ type MemeItem struct {
 	Tags      []string
 	Format    string // File format (jpg, png, webp, etc.)
 	LocalPath string // Local file path (if available)
+	Data      []byte // Image bytes of an in-memory upload; read instead of LocalPath and URL
+	Priority  int    // Higher values are ingested first; 0 is normal backfill
+	SHA256    string // Expected hex SHA-256 of the file, e.g. from a manifest; ingest quarantines files that differ
+}
+
+// CategoryPriority assigns a priority to items whose category matches a glob.
+type CategoryPriority struct {
+	Pattern  string `json:"pattern" mapstructure:"pattern"`   // Glob matched against the item category
+	Priority int    `json:"priority" mapstructure:"priority"` // Priority for matching items
+}
+
+// PriorityFor returns the highest priority among rules matching category.
+// Parameters:
+//   - rules: category priority rules.
+//   - category: item category.
+//
+// Returns:
+//   - int: highest matching priority.
+//   - bool: true if any rule matched.
+func PriorityFor(rules []CategoryPriority, category string) (int, bool) {
+	priority, matched := 0, false
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Pattern, category); ok && (!matched || rule.Priority > priority) {
+			priority, matched = rule.Priority, true
+		}
+	}
+	return priority, matched
+}
+
+// ValidatePriorities reports malformed category globs.
+// Parameters:
+//   - rules: category priority rules.
+//
+// Returns:
+//   - error: non-nil if a pattern is not a valid glob.
+func ValidatePriorities(rules []CategoryPriority) error {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid priority pattern %q: %w", rule.Pattern, err)
+		}
+	}
+	return nil
 }
 
 // Source defines the interface for meme data sources.
@@ -43,3 +90,66 @@ type Source interface {
 	//   - bool: true when incremental updates are supported.
 	SupportsIncremental() bool
 }
+
+// Counter is implemented by sources that can report their size up front.
+type Counter interface {
+	// GetTotalCount returns the number of items the source currently holds.
+	// Parameters:
+	//   - ctx: context for cancellation and deadlines.
+	// Returns:
+	//   - int: total number of items.
+	//   - err: non-nil if counting fails.
+	GetTotalCount(ctx context.Context) (int, error)
+}
+
+// Reloader is implemented by sources that cache their item list after the
+// first fetch.
+type Reloader interface {
+	// Reload drops the cached items, so the next fetch or count scans the
+	// source again and sees items written since.
+	// Parameters: none.
+	// Returns: none.
+	Reload()
+}
+
+var (
+	// ErrStagingUnsupported is returned by a Stager that is not configured
+	// for staging uploads.
+	ErrStagingUnsupported = errors.New("source does not accept staging uploads")
+	// ErrInvalidStaging is returned for a staging upload whose manifest and
+	// files do not match.
+	ErrInvalidStaging = errors.New("invalid staging upload")
+	// ErrStagingConflict is returned when an uploaded file would replace a
+	// different staged file of the same name.
+	ErrStagingConflict = errors.New("staged file exists with different content")
+)
+
+// StagedFile is a file of a staging upload.
+type StagedFile struct {
+	Name string // Base filename, as referenced by the manifest
+	Data []byte
+}
+
+// StageResult reports what a staging upload wrote.
+type StageResult struct {
+	Written   int `json:"written"`   // Files written to the staging directory
+	Unchanged int `json:"unchanged"` // Files already staged with the same content
+	Skipped   int `json:"skipped"`   // Entries without "keep": true, which are neither written nor appended
+	Entries   int `json:"entries"`   // Manifest entries appended
+}
+
+// Stager is implemented by sources that accept staging uploads in the
+// layout of the external crawler: images plus a JSONL manifest.
+type Stager interface {
+	// Stage writes files and manifest into the staging layout and rescans
+	// the source, so the next fetch or count includes them. Callers must
+	// not run it while an ingest of the source is paging through its items.
+	// Parameters:
+	//   - manifest: JSONL manifest entries describing files.
+	//   - files: uploaded files, each referenced by a manifest entry.
+	// Returns:
+	//   - *StageResult: files written and manifest entries appended.
+	//   - err: ErrStagingUnsupported, ErrInvalidStaging, ErrStagingConflict
+	//     or a filesystem error.
+	Stage(manifest []byte, files []StagedFile) (*StageResult, error)
+}
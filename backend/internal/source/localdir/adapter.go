@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/timmy/emomo/internal/source"
 )
@@ -26,6 +27,7 @@ type Options struct {
 	SourceID     string
 	ManifestPath string
 	QueuePath    string
+	Priorities   []source.CategoryPriority // Category priorities; matching items are fetched first
 }
 
 // Adapter implements source.Source for a local static image directory.
@@ -34,7 +36,9 @@ type Adapter struct {
 	sourceID     string
 	manifestPath string
 	queuePath    string
+	priorities   []source.CategoryPriority
 
+	mu     sync.Mutex
 	items  []source.MemeItem
 	loaded bool
 }
@@ -47,6 +51,7 @@ type stage2Record struct {
 	Confidence float64 `json:"confidence"`
 	Reason     string  `json:"reason"`
 	Keep       bool    `json:"keep"`
+	SHA256     string  `json:"sha256"` // Hex SHA-256 of the downloaded file; verified during ingest
 }
 
 type queueRecord struct {
@@ -71,6 +76,7 @@ func NewAdapter(opts Options) *Adapter {
 		sourceID:     sourceID,
 		manifestPath: opts.ManifestPath,
 		queuePath:    opts.QueuePath,
+		priorities:   opts.Priorities,
 	}
 }
 
@@ -93,38 +99,70 @@ func (a *Adapter) SupportsIncremental() bool {
 	return false
 }
 
+// GetTotalCount returns the number of supported images found under the root path.
+func (a *Adapter) GetTotalCount(ctx context.Context) (int, error) {
+	items, err := a.ensureLoaded()
+	if err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
 // FetchBatch fetches a page of local image items.
 func (a *Adapter) FetchBatch(ctx context.Context, cursor string, limit int) ([]source.MemeItem, string, error) {
-	if !a.loaded {
-		if err := a.loadItems(); err != nil {
-			return nil, "", err
-		}
-		a.loaded = true
+	items, err := a.ensureLoaded()
+	if err != nil {
+		return nil, "", err
 	}
 
 	startIndex := 0
 	if cursor != "" {
-		var err error
 		startIndex, err = strconv.Atoi(cursor)
 		if err != nil {
 			return nil, "", fmt.Errorf("invalid cursor: %w", err)
 		}
 	}
-	if startIndex >= len(a.items) {
+	if startIndex >= len(items) {
 		return []source.MemeItem{}, "", nil
 	}
 
 	endIndex := startIndex + limit
-	if limit <= 0 || endIndex > len(a.items) {
-		endIndex = len(a.items)
+	if limit <= 0 || endIndex > len(items) {
+		endIndex = len(items)
 	}
 
 	nextCursor := ""
-	if endIndex < len(a.items) {
+	if endIndex < len(items) {
 		nextCursor = strconv.Itoa(endIndex)
 	}
 
-	return a.items[startIndex:endIndex], nextCursor, nil
+	return items[startIndex:endIndex], nextCursor, nil
+}
+
+// Reload drops the scanned items, so the next fetch rescans the directory,
+// the manifest and the queue, e.g. after the crawler wrote a new manifest.
+func (a *Adapter) Reload() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.items = nil
+	a.loaded = false
+}
+
+// ensureLoaded scans the directory once and returns the items read under the
+// lock; the stats endpoint may call it while an ingest is paging through the
+// same adapter, and Reload may drop a.items at any time, so callers index the
+// returned slice rather than a.items.
+func (a *Adapter) ensureLoaded() ([]source.MemeItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.loaded {
+		return a.items, nil
+	}
+	if err := a.loadItems(); err != nil {
+		return nil, err
+	}
+	a.loaded = true
+	return a.items, nil
 }
 
 func (a *Adapter) loadItems() error {
@@ -197,7 +235,9 @@ func (a *Adapter) loadItems() error {
 			Category:  category,
 			Format:    format,
 			Tags:      tagsForItem(a.sourceID, relPath, meta, queueMeta, category),
+			SHA256:    strings.ToLower(strings.TrimSpace(meta.SHA256)),
 		}
+		item.Priority, _ = source.PriorityFor(a.priorities, category)
 		items = append(items, item)
 		return nil
 	})
@@ -205,7 +245,11 @@ func (a *Adapter) loadItems() error {
 		return fmt.Errorf("failed to scan local directory: %w", err)
 	}
 
+	// High-priority items come first so they are ingested before the backfill.
 	sort.Slice(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority > items[j].Priority
+		}
 		return items[i].SourceID < items[j].SourceID
 	})
 	a.items = items
@@ -220,6 +264,14 @@ func formatFromFilename(name string) (string, bool) {
 		return "png", true
 	case ".webp":
 		return "webp", true
+	case ".heic", ".heif":
+		return "heic", true
+	case ".avif":
+		return "avif", true
+	case ".mp4":
+		return "mp4", true
+	case ".webm":
+		return "webm", true
 	default:
 		return "", false
 	}
@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+
+	"github.com/timmy/emomo/internal/source"
 )
 
 func writeFile(t *testing.T, path string, data string) {
@@ -39,6 +42,9 @@ func TestFetchBatchScansStaticImagesAndSkipsUnsupportedFiles(t *testing.T) {
 	if len(items) != 3 {
 		t.Fatalf("FetchBatch() returned %d items, want 3", len(items))
 	}
+	if total, err := adapter.GetTotalCount(context.Background()); err != nil || total != 3 {
+		t.Fatalf("GetTotalCount() = (%d, %v), want (3, nil)", total, err)
+	}
 
 	byID := map[string]string{}
 	for _, item := range items {
@@ -78,7 +84,7 @@ func TestFetchBatchUsesXiaohongshuManifestAndQueueMetadata(t *testing.T) {
 
 	manifestPath := filepath.Join(root, "stage2_results.jsonl")
 	writeFile(t, manifestPath,
-		`{"note_id":"65d4a17900000000070079da","filename":"65d4a17900000000070079da_1.jpg","keyword":"学生党表情包","title":"考试周","confidence":0.9,"reason":"熊猫头配文字","keep":true}`+"\n"+
+		`{"note_id":"65d4a17900000000070079da","filename":"65d4a17900000000070079da_1.jpg","keyword":"学生党表情包","title":"考试周","confidence":0.9,"reason":"熊猫头配文字","keep":true,"sha256":" 9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08 "}`+"\n"+
 			`{"note_id":"65d4a17900000000070079da","filename":"65d4a17900000000070079da_2.jpg","keyword":"学生党表情包","confidence":0.4,"reason":"非表情包","keep":false}`+"\n")
 
 	queuePath := filepath.Join(root, "stage1_queue.jsonl")
@@ -113,6 +119,9 @@ func TestFetchBatchUsesXiaohongshuManifestAndQueueMetadata(t *testing.T) {
 	if item.LocalPath != keepImage {
 		t.Fatalf("LocalPath = %q, want %q", item.LocalPath, keepImage)
 	}
+	if item.SHA256 != "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" {
+		t.Fatalf("SHA256 = %q, want the normalized manifest checksum", item.SHA256)
+	}
 	if item.Category != "学生党表情包" {
 		t.Fatalf("Category = %q, want 学生党表情包", item.Category)
 	}
@@ -122,3 +131,91 @@ func TestFetchBatchUsesXiaohongshuManifestAndQueueMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchBatchOrdersPriorityCategoriesFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "archive", "a.jpg"), "jpg")
+	writeFile(t, filepath.Join(root, "hot-pack", "z.jpg"), "jpg")
+	writeFile(t, filepath.Join(root, "new", "b.png"), "png")
+
+	adapter := NewAdapter(Options{RootPath: root, Priorities: []source.CategoryPriority{
+		{Pattern: "hot-*", Priority: 10},
+		{Pattern: "new", Priority: 5},
+	}})
+	items, _, err := adapter.FetchBatch(context.Background(), "", 10)
+	if err != nil {
+		t.Fatalf("FetchBatch() error = %v", err)
+	}
+
+	var order []string
+	for _, item := range items {
+		order = append(order, item.SourceID)
+	}
+	if want := []string{"hot-pack/z.jpg", "new/b.png", "archive/a.jpg"}; !slices.Equal(order, want) {
+		t.Fatalf("FetchBatch() order = %v, want %v", order, want)
+	}
+	if items[0].Priority != 10 || items[2].Priority != 0 {
+		t.Fatalf("priorities = %d, %d, want 10 and 0", items[0].Priority, items[2].Priority)
+	}
+}
+
+func TestReloadReadsRewrittenManifest(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a_1.jpg"), "jpg")
+	writeFile(t, filepath.Join(root, "b_1.jpg"), "jpg")
+	manifestPath := filepath.Join(root, "stage2_results.jsonl")
+	writeFile(t, manifestPath, `{"note_id":"a","filename":"a_1.jpg","keyword":"猫猫","keep":true}`+"\n")
+
+	adapter := NewAdapter(Options{RootPath: root, ManifestPath: manifestPath})
+	var _ source.Reloader = adapter
+	if total, err := adapter.GetTotalCount(context.Background()); err != nil || total != 1 {
+		t.Fatalf("GetTotalCount() = (%d, %v), want (1, nil)", total, err)
+	}
+
+	// The crawler appends a batch; the scan is cached until Reload
+	writeFile(t, manifestPath, `{"note_id":"a","filename":"a_1.jpg","keyword":"猫猫","keep":true}`+"\n"+
+		`{"note_id":"b","filename":"b_1.jpg","keyword":"狗狗","keep":true}`+"\n")
+	if total, _ := adapter.GetTotalCount(context.Background()); total != 1 {
+		t.Fatalf("GetTotalCount() before Reload = %d, want the cached 1", total)
+	}
+	adapter.Reload()
+	items, _, err := adapter.FetchBatch(context.Background(), "", 10)
+	if err != nil || len(items) != 2 || items[1].Category != "狗狗" {
+		t.Fatalf("FetchBatch() after Reload = %+v, %v, want both manifest entries", items, err)
+	}
+}
+
+func TestReloadDuringFetch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		writeFile(t, filepath.Join(root, name), "jpg")
+	}
+	adapter := NewAdapter(Options{RootPath: root})
+
+	// The stats endpoint and the webhook reload while an ingest pages through
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				adapter.Reload()
+				if items, _, err := adapter.FetchBatch(context.Background(), "1", 10); err != nil || len(items) != 2 {
+					t.Errorf("FetchBatch() = %d items, %v, want 2", len(items), err)
+					return
+				}
+				if total, err := adapter.GetTotalCount(context.Background()); err != nil || total != 3 {
+					t.Errorf("GetTotalCount() = (%d, %v), want (3, nil)", total, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,228 @@
+package localdir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+// stagedEntry is a validated manifest line of a staging upload with its file.
+type stagedEntry struct {
+	line []byte
+	file source.StagedFile
+	keep bool
+}
+
+// Stage writes uploaded images into the root path and appends their entries
+// to the stage2 manifest, the layout the crawler produces, then drops the
+// scanned items so the next fetch sees them. Images are written before the
+// manifest: an upload that fails halfway leaves files without entries, which
+// the manifest filter skips. Entries without "keep": true would never be
+// ingested, so they and their files are skipped; unchanged files the
+// manifest already keeps get no second entry.
+// Parameters:
+//   - manifest: stage2 JSONL entries, one per uploaded file.
+//   - files: uploaded images, named by the "filename" of their entry.
+//
+// Returns:
+//   - *source.StageResult: files written, unchanged and skipped, and
+//     manifest entries appended.
+//   - error: source.ErrStagingUnsupported without a manifest path,
+//     source.ErrInvalidStaging or source.ErrStagingConflict for a rejected
+//     upload, or a filesystem error.
+func (a *Adapter) Stage(manifest []byte, files []source.StagedFile) (*source.StageResult, error) {
+	rootPath := strings.TrimSpace(a.rootPath)
+	manifestPath := strings.TrimSpace(a.manifestPath)
+	if rootPath == "" || manifestPath == "" {
+		return nil, fmt.Errorf("%w: %s needs root_path and manifest_path", source.ErrStagingUnsupported, a.sourceID)
+	}
+
+	entries, err := parseStagingUpload(manifest, files)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(rootPath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	staged, err := loadStage2Manifest(manifestPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Check every file before writing any, so a conflict rejects the whole upload
+	result := &source.StageResult{}
+	pending := make([]source.StagedFile, 0, len(entries))
+	appended := make([]stagedEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.keep {
+			result.Skipped++
+			continue
+		}
+		existing, err := os.ReadFile(filepath.Join(rootPath, entry.file.Name))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			pending = append(pending, entry.file)
+		case err != nil:
+			return nil, fmt.Errorf("failed to read staged file: %w", err)
+		case !bytes.Equal(existing, entry.file.Data):
+			return nil, fmt.Errorf("%w: %s", source.ErrStagingConflict, entry.file.Name)
+		default:
+			result.Unchanged++
+			// A file left without an entry by a failed upload still needs one
+			if staged[entry.file.Name].Keep {
+				continue
+			}
+		}
+		appended = append(appended, entry)
+	}
+
+	for _, file := range pending {
+		if err := writeStagedFile(rootPath, file); err != nil {
+			return nil, err
+		}
+	}
+	if len(appended) > 0 {
+		if err := appendManifest(manifestPath, appended); err != nil {
+			return nil, err
+		}
+	}
+
+	a.items = nil
+	a.loaded = false
+	result.Written = len(pending)
+	result.Entries = len(appended)
+	return result, nil
+}
+
+// parseStagingUpload pairs each manifest line with its file, requiring a
+// one-to-one match and a matching sha256 where the entry has one.
+func parseStagingUpload(manifest []byte, files []source.StagedFile) ([]stagedEntry, error) {
+	byName := make(map[string]source.StagedFile, len(files))
+	for _, file := range files {
+		if _, ok := byName[file.Name]; ok {
+			return nil, fmt.Errorf("%w: file %q uploaded twice", source.ErrInvalidStaging, file.Name)
+		}
+		byName[file.Name] = file
+	}
+
+	entries := make([]stagedEntry, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, line := range bytes.Split(manifest, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record stage2Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("%w: failed to parse manifest line: %v", source.ErrInvalidStaging, err)
+		}
+		name := record.Filename
+		if !validStagedName(name) {
+			return nil, fmt.Errorf("%w: filename %q must be a visible image or video file name without directories",
+				source.ErrInvalidStaging, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: filename %q listed twice", source.ErrInvalidStaging, name)
+		}
+		seen[name] = true
+
+		file, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: no file uploaded for %q", source.ErrInvalidStaging, name)
+		}
+		if want := strings.ToLower(strings.TrimSpace(record.SHA256)); want != "" {
+			sum := sha256.Sum256(file.Data)
+			if got := hex.EncodeToString(sum[:]); got != want {
+				return nil, fmt.Errorf("%w: sha256 of %q is %s, manifest says %s", source.ErrInvalidStaging, name, got, want)
+			}
+		}
+		entries = append(entries, stagedEntry{line: line, file: file, keep: record.Keep})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: manifest has no entries", source.ErrInvalidStaging)
+	}
+	for name := range byName {
+		if !seen[name] {
+			return nil, fmt.Errorf("%w: file %q has no manifest entry", source.ErrInvalidStaging, name)
+		}
+	}
+	return entries, nil
+}
+
+// validStagedName reports whether name can be written to the root path and
+// is picked up by the scan: a plain, visible file name of a supported format.
+func validStagedName(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return false
+	}
+	_, ok := formatFromFilename(name)
+	return ok
+}
+
+// writeStagedFile writes file under a hidden temporary name and renames it,
+// so a concurrent scan never reads a partial image.
+func writeStagedFile(rootPath string, file source.StagedFile) error {
+	tmp, err := os.CreateTemp(rootPath, "."+file.Name+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create staged file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(file.Data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write staged file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write staged file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write staged file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(rootPath, file.Name)); err != nil {
+		return fmt.Errorf("failed to write staged file: %w", err)
+	}
+	return nil
+}
+
+// appendManifest appends the lines of entries to the manifest, starting on a
+// new line if the crawler left the last one unterminated.
+func appendManifest(path string, entries []stagedEntry) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open stage2 manifest: %w", err)
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read stage2 manifest: %w", err)
+		}
+		if last[0] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	for _, entry := range entries {
+		buf.Write(entry.line)
+		buf.WriteByte('\n')
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to append to stage2 manifest: %w", err)
+	}
+	return nil
+}
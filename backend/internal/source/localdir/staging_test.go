@@ -0,0 +1,98 @@
+package localdir
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/timmy/emomo/internal/source"
+)
+
+func TestStageWritesImagesAndManifestEntries(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "stage2_results.jsonl")
+	// The crawler's last line lacks a newline; staged entries must not join it
+	writeFile(t, manifestPath, `{"note_id":"n0","filename":"old.jpg","keyword":"猫猫","keep":true}`)
+	writeFile(t, filepath.Join(root, "old.jpg"), "old")
+	// Left without an entry by an upload that failed halfway
+	writeFile(t, filepath.Join(root, "orphan.jpg"), "orphan")
+
+	adapter := NewAdapter(Options{RootPath: root, SourceID: "xiaohongshu", ManifestPath: manifestPath})
+	if total, err := adapter.GetTotalCount(context.Background()); err != nil || total != 1 {
+		t.Fatalf("GetTotalCount() = (%d, %v), want (1, nil)", total, err)
+	}
+
+	manifest := `{"note_id":"n1","filename":"new.png","keyword":"狗狗","keep":true,"sha256":"11507A0E2F5E69D5DFA40A62A1BD7B6EE57E6BCD85C67C9B8431B36FFF21C437"}` + "\n" +
+		`{"note_id":"n0","filename":"old.jpg","keyword":"猫猫","keep":true}` + "\n" +
+		`{"note_id":"n2","filename":"orphan.jpg","keyword":"猫猫","keep":true}` + "\n" +
+		`{"note_id":"n3","filename":"drop.jpg","keyword":"猫猫","keep":false}` + "\n"
+	result, err := adapter.Stage([]byte(manifest), []source.StagedFile{
+		{Name: "new.png", Data: []byte("new")},
+		{Name: "old.jpg", Data: []byte("old")},
+		{Name: "orphan.jpg", Data: []byte("orphan")},
+		{Name: "drop.jpg", Data: []byte("drop")},
+	})
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if *result != (source.StageResult{Written: 1, Unchanged: 2, Skipped: 1, Entries: 2}) {
+		t.Fatalf("Stage() = %+v, want 1 written, 2 unchanged, 1 skipped, 2 entries", *result)
+	}
+	if _, err := os.Stat(filepath.Join(root, "drop.jpg")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stage() wrote drop.jpg without keep: %v", err)
+	}
+
+	items, _, err := adapter.FetchBatch(context.Background(), "", 10)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("FetchBatch() after Stage() = %d items, %v, want 3", len(items), err)
+	}
+	for _, item := range items {
+		if item.SourceID == "n1:new.png" && item.Category != "狗狗" {
+			t.Fatalf("staged item category = %q, want 狗狗", item.Category)
+		}
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	// old.jpg already had an entry; new.png and orphan.jpg get one each
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 {
+		t.Fatalf("manifest has %d lines, want 3:\n%s", len(lines), data)
+	}
+
+	// Rejected uploads leave the directory as it was
+	tests := []struct {
+		name     string
+		manifest string
+		files    []source.StagedFile
+		want     error
+	}{
+		{"missing file", `{"filename":"a.jpg","keep":true}`, nil, source.ErrInvalidStaging},
+		{"unlisted file", `{"filename":"a.jpg","keep":true}`,
+			[]source.StagedFile{{Name: "a.jpg", Data: []byte("a")}, {Name: "b.jpg", Data: []byte("b")}}, source.ErrInvalidStaging},
+		{"path", `{"filename":"../a.jpg","keep":true}`, []source.StagedFile{{Name: "../a.jpg", Data: []byte("a")}}, source.ErrInvalidStaging},
+		{"hidden", `{"filename":".a.jpg","keep":true}`, []source.StagedFile{{Name: ".a.jpg", Data: []byte("a")}}, source.ErrInvalidStaging},
+		{"unsupported format", `{"filename":"a.gif","keep":true}`, []source.StagedFile{{Name: "a.gif", Data: []byte("a")}}, source.ErrInvalidStaging},
+		{"checksum", `{"filename":"a.jpg","keep":true,"sha256":"00"}`, []source.StagedFile{{Name: "a.jpg", Data: []byte("a")}}, source.ErrInvalidStaging},
+		{"conflict", `{"filename":"a.jpg","keep":true}` + "\n" + `{"filename":"old.jpg","keep":true}`,
+			[]source.StagedFile{{Name: "a.jpg", Data: []byte("a")}, {Name: "old.jpg", Data: []byte("changed")}}, source.ErrStagingConflict},
+	}
+	for _, tt := range tests {
+		if _, err := adapter.Stage([]byte(tt.manifest), tt.files); !errors.Is(err, tt.want) {
+			t.Errorf("Stage(%s) error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.jpg")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("rejected upload wrote a.jpg: %v", err)
+	}
+
+	unconfigured := NewAdapter(Options{RootPath: root})
+	if _, err := unconfigured.Stage([]byte(manifest), nil); !errors.Is(err, source.ErrStagingUnsupported) {
+		t.Fatalf("Stage() without manifest path error = %v, want ErrStagingUnsupported", err)
+	}
+}
@@ -14,6 +14,14 @@ type ObjectStorage interface {
 	//   - error: non-nil if the bucket check/create fails.
 	EnsureBucket(ctx context.Context) error
 
+	// CheckBucket checks that the configured bucket is reachable, without
+	// creating it.
+	// Parameters:
+	//   - ctx: context for cancellation and deadlines.
+	// Returns:
+	//   - error: non-nil if the bucket is missing or unreachable.
+	CheckBucket(ctx context.Context) error
+
 	// Upload stores an object at the given key.
 	// Parameters:
 	//   - ctx: context for cancellation and deadlines.
@@ -36,6 +36,8 @@ type S3Config struct {
 	Bucket    string
 	Region    string
 	PublicURL string // Public URL prefix for R2.dev or custom CDN
+
+	OperationTimeout time.Duration // Default deadline per request when the caller sets none (0 disables)
 }
 
 // S3Storage implements ObjectStorage for S3-compatible services.
@@ -47,6 +49,7 @@ type S3Storage struct {
 	storeType StorageType
 	publicURL string
 	region    string
+	timeout   time.Duration
 }
 
 // NewS3Storage creates a new S3-compatible storage client.
@@ -106,6 +109,7 @@ func NewS3Storage(cfg *S3Config) (*S3Storage, error) {
 		storeType: cfg.Type,
 		publicURL: publicURL,
 		region:    region,
+		timeout:   cfg.OperationTimeout,
 	}, nil
 }
 
@@ -126,12 +130,50 @@ func normalizeEndpoint(endpoint string) string {
 	return endpoint
 }
 
+// CheckBucket checks that the bucket is reachable with a HeadBucket request.
+func (s *S3Storage) CheckBucket(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.bucket),
+	}); err != nil {
+		return fmt.Errorf("failed to head bucket %s: %w", s.bucket, err)
+	}
+	return nil
+}
+
+// withTimeout bounds ctx by the operation timeout unless it already has an earlier deadline.
+func (s *S3Storage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= s.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// cancelOnClose releases the operation context once the caller is done with the body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
 // EnsureBucket ensures the configured bucket exists.
 // Parameters:
 //   - ctx: context for cancellation and deadlines.
 // Returns:
 //   - error: non-nil if the bucket check/create fails.
 func (s *S3Storage) EnsureBucket(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	// Check if bucket exists
 	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(s.bucket),
@@ -166,6 +208,9 @@ func (s *S3Storage) EnsureBucket(ctx context.Context) error {
 // Returns:
 //   - error: non-nil if the upload fails.
 func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	startTime := time.Now()
 
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
@@ -201,6 +246,8 @@ func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, si
 //   - io.ReadCloser: reader for the object contents.
 //   - error: non-nil if the download fails.
 func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	ctx, cancel := s.withTimeout(ctx)
+
 	startTime := time.Now()
 
 	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
@@ -213,6 +260,7 @@ func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, er
 		logger.With(logger.Fields{
 			logger.FieldDurationMs: duration.Milliseconds(),
 		}).Error(ctx, "Failed to download: key=%s, error=%v", key, err)
+		cancel()
 		return nil, fmt.Errorf("failed to download object: %w", err)
 	}
 
@@ -220,7 +268,8 @@ func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, er
 		logger.FieldDurationMs: duration.Milliseconds(),
 	}).Debug(ctx, "Download completed: key=%s", key)
 
-	return result.Body, nil
+	// Keep the deadline alive until the body has been consumed
+	return &cancelOnClose{ReadCloser: result.Body, cancel: cancel}, nil
 }
 
 // GetURL returns a public or signed URL for accessing an object.
@@ -264,6 +313,9 @@ func (s *S3Storage) GetURL(key string) string {
 // Returns:
 //   - error: non-nil if the delete fails.
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
@@ -282,6 +334,9 @@ func (s *S3Storage) Delete(ctx context.Context, key string) error {
 //   - bool: true if the object exists.
 //   - error: non-nil if the check fails.
 func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
@@ -0,0 +1,80 @@
+// Package usage meters the LLM and embedding tokens spent while serving a
+// request. The API key middleware puts a Meter into the request context and the
+// model clients add to it; code without a meter in its context is not metered.
+package usage
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Meter accumulates the tokens spent on behalf of one request. It is safe for
+// concurrent use.
+type Meter struct {
+	llmTokens       atomic.Int64
+	embeddingTokens atomic.Int64
+}
+
+type meterKey struct{}
+
+// NewContext returns a copy of ctx carrying m.
+// Parameters:
+//   - ctx: parent context.
+//   - m: meter that collects the tokens spent under the returned context.
+//
+// Returns:
+//   - context.Context: context carrying m.
+func NewContext(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// FromContext returns the meter carried by ctx, or nil.
+// Parameters:
+//   - ctx: context to read.
+//
+// Returns:
+//   - *Meter: meter of ctx (nil when not metered).
+func FromContext(ctx context.Context) *Meter {
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	return m
+}
+
+// AddLLMTokens adds chat completion tokens to the meter of ctx, if any.
+// Parameters:
+//   - ctx: request context.
+//   - tokens: total tokens of a completion.
+//
+// Returns: none.
+func AddLLMTokens(ctx context.Context, tokens int) {
+	if m := FromContext(ctx); m != nil && tokens > 0 {
+		m.llmTokens.Add(int64(tokens))
+	}
+}
+
+// AddEmbeddingTokens adds embedding tokens to the meter of ctx, if any.
+// Parameters:
+//   - ctx: request context.
+//   - tokens: total tokens of an embedding request.
+//
+// Returns: none.
+func AddEmbeddingTokens(ctx context.Context, tokens int) {
+	if m := FromContext(ctx); m != nil && tokens > 0 {
+		m.embeddingTokens.Add(int64(tokens))
+	}
+}
+
+// LLMTokens returns the chat completion tokens metered so far.
+// Parameters: none.
+// Returns:
+//   - int64: token count.
+func (m *Meter) LLMTokens() int64 {
+	return m.llmTokens.Load()
+}
+
+// EmbeddingTokens returns the embedding tokens metered so far.
+// Parameters: none.
+// Returns:
+//   - int64: token count.
+func (m *Meter) EmbeddingTokens() int64 {
+	return m.embeddingTokens.Load()
+}
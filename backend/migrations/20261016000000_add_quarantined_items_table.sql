@@ -0,0 +1,17 @@
+-- Migration: Add quarantined_items table for source files that fail image validation
+
+CREATE TABLE IF NOT EXISTS quarantined_items (
+    id TEXT PRIMARY KEY,
+    source_type TEXT NOT NULL,
+    source_id TEXT NOT NULL,
+    local_path TEXT,
+    md5_hash TEXT,
+    format TEXT,
+    file_size BIGINT,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_items_source
+    ON quarantined_items(source_type, source_id);
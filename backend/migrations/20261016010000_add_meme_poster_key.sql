@@ -0,0 +1,6 @@
+-- Migration: Add poster_key to memes
+-- Animated memes (transcoded clips) store a static JPEG poster frame next to
+-- the clip so list views can render without loading the video.
+
+ALTER TABLE memes
+    ADD COLUMN IF NOT EXISTS poster_key TEXT;
@@ -0,0 +1,10 @@
+-- Migration: Add color palette fields to memes
+-- dominant_colors holds a JSON array of hex colors ordered by pixel share and
+-- saturation the mean HSV saturation (0-1); both are computed at ingest and
+-- copied into the Qdrant payload for color filtering.
+
+ALTER TABLE memes
+    ADD COLUMN IF NOT EXISTS dominant_colors TEXT;
+
+ALTER TABLE memes
+    ADD COLUMN IF NOT EXISTS saturation DOUBLE PRECISION DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Migration: Add scene tags to meme_descriptions
+-- scene_tags holds a JSON array of usage scenes (e.g. ["考试","熬夜"]) from the
+-- optional scene tagging pass; it is copied into the Qdrant payload for scene filtering.
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS scene_tags TEXT;
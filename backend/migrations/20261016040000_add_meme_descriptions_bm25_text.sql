@@ -0,0 +1,8 @@
+-- Migration: Add BM25 text to meme_descriptions
+-- bm25_text records the document text the BM25 sparse vectors were built from
+-- (OCR text, compacted description and tags). Existing rows stay NULL; run
+-- `go run ./cmd/reembed --stale` to rewrite sparse vectors and re-embed caption
+-- vectors built from outdated text, which also fills this column.
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS bm25_text TEXT;
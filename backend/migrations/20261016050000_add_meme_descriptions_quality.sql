@@ -0,0 +1,16 @@
+-- Migration: Add description quality fields to meme_descriptions
+-- quality_score is the share of heuristic checks (length, emotion words, OCR
+-- consistency) a new description passed; quality_issues lists the failed checks.
+-- Descriptions still below the minimum after a strict retry get needs_review
+-- and are listed at GET /api/v1/admin/descriptions/review.
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS quality_score DOUBLE PRECISION DEFAULT 0;
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS quality_issues TEXT;
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS needs_review BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_meme_descriptions_needs_review ON meme_descriptions(needs_review);
@@ -0,0 +1,9 @@
+-- Migration: Add prompt version to meme_descriptions
+-- prompt_version is a short hash of the VLM prompts that produced the
+-- description. Existing rows stay NULL and count as outdated; find them with
+-- `ingest --prompt-report` and regenerate them with `ingest --redescribe`.
+
+ALTER TABLE meme_descriptions
+    ADD COLUMN IF NOT EXISTS prompt_version TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_meme_descriptions_prompt_version ON meme_descriptions(prompt_version);
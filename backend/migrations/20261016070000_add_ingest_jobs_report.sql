@@ -0,0 +1,20 @@
+-- Migration: Record ingest runs in ingest_jobs with their run report
+
+CREATE TABLE IF NOT EXISTS ingest_jobs (
+    id TEXT PRIMARY KEY,
+    source_id TEXT NOT NULL,
+    status TEXT DEFAULT 'pending',
+    total_items INTEGER DEFAULT 0,
+    processed_items INTEGER DEFAULT 0,
+    failed_items INTEGER DEFAULT 0,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    error_log TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_jobs_source_id ON ingest_jobs(source_id);
+
+ALTER TABLE ingest_jobs ADD COLUMN IF NOT EXISTS skipped_items INTEGER DEFAULT 0;
+ALTER TABLE ingest_jobs ADD COLUMN IF NOT EXISTS report TEXT;
@@ -0,0 +1,11 @@
+-- Migration: Track remote origins of memes from URL-based sources
+-- source_url is the origin the meme was downloaded from. origin_status is set
+-- by the origin checker ('ok', 'dead' or 'blocked'); dead and blocked origins
+-- are listed for review at /api/v1/admin/origins/review.
+
+ALTER TABLE memes
+    ADD COLUMN IF NOT EXISTS source_url TEXT,
+    ADD COLUMN IF NOT EXISTS origin_status TEXT,
+    ADD COLUMN IF NOT EXISTS origin_checked_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_memes_origin_status ON memes(origin_status);
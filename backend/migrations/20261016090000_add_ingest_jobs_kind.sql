@@ -0,0 +1,5 @@
+-- Migration: Distinguish ingest runs from source deletions in ingest_jobs
+
+ALTER TABLE ingest_jobs ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'ingest';
+
+CREATE INDEX IF NOT EXISTS idx_ingest_jobs_kind ON ingest_jobs(kind);
@@ -0,0 +1,11 @@
+-- Migration: Index perceptual hashes and link near-duplicate memes
+-- perceptual_hash holds the 64-bit dHash of new memes as hex. Under the link
+-- near duplicate policy, variant_of is the ID of the stored meme a new image
+-- matched.
+
+ALTER TABLE memes
+    ADD COLUMN IF NOT EXISTS perceptual_hash TEXT,
+    ADD COLUMN IF NOT EXISTS variant_of TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_memes_perceptual_hash ON memes(perceptual_hash);
+CREATE INDEX IF NOT EXISTS idx_memes_variant_of ON memes(variant_of);
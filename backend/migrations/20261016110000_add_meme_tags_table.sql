@@ -0,0 +1,18 @@
+-- Migration: Normalize meme tags into meme_tags for listing memes by tag
+-- memes.tags stays the source of truth; the repository rewrites a meme's rows
+-- whenever it writes the meme. Existing tags are backfilled here.
+
+CREATE TABLE IF NOT EXISTS meme_tags (
+    meme_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (meme_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meme_tags_tag ON meme_tags(tag);
+
+INSERT INTO meme_tags (meme_id, tag)
+SELECT DISTINCT m.id, btrim(t.tag)
+FROM memes m
+CROSS JOIN LATERAL jsonb_array_elements_text(COALESCE(NULLIF(m.tags, ''), '[]')::jsonb) AS t(tag)
+WHERE btrim(t.tag) <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,10 @@
+-- Migration: Add memes.download_count and indexes for list sort orders
+-- download_count is incremented by single and bundle downloads and feeds the
+-- popular sort. Each sort of GET /api/v1/memes filters on status, so the
+-- indexes lead with it.
+
+ALTER TABLE memes ADD COLUMN IF NOT EXISTS download_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_memes_status_created ON memes(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_memes_status_downloads ON memes(status, download_count);
+CREATE INDEX IF NOT EXISTS idx_memes_status_file_size ON memes(status, file_size);
@@ -0,0 +1,6 @@
+-- Migration: Rename the pending_review meme status to review
+-- Uploads and crawled memes held for moderation share the review status;
+-- rejected memes keep their row with status rejected. The Qdrant payload flag
+-- keeps its name pending_review.
+
+UPDATE memes SET status = 'review' WHERE status = 'pending_review';
@@ -0,0 +1,13 @@
+-- Migration: Add stats_snapshots table for GET /api/v1/stats/history
+-- One row per UTC day: searches are added as they are served, meme and vector
+-- counts are replaced by the periodic snapshot.
+
+CREATE TABLE IF NOT EXISTS stats_snapshots (
+    date TEXT PRIMARY KEY,
+    total_memes BIGINT NOT NULL DEFAULT 0,
+    sources TEXT,
+    collections TEXT,
+    queries BIGINT NOT NULL DEFAULT 0,
+    snapshot_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
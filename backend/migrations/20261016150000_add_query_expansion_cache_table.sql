@@ -0,0 +1,13 @@
+-- Migration: Add query_expansion_cache table for search.query_expansion.persist_cache
+-- Expansions are keyed by a hash of the expansion model and prompt; the API
+-- drops rows of other versions when it loads the cache at startup.
+
+CREATE TABLE IF NOT EXISTS query_expansion_cache (
+    version TEXT NOT NULL,
+    query TEXT NOT NULL,
+    expansion TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version, query)
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_expansion_cache_updated_at ON query_expansion_cache(updated_at);
@@ -0,0 +1,19 @@
+-- Migration: Add api_keys table for keys created through /api/v1/admin/keys
+-- Only a SHA-256 hash of each secret is stored; keys from config.yaml are not
+-- recorded here. Revoked keys stay for their usage history.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT,
+    monthly_requests BIGINT NOT NULL DEFAULT 0,
+    monthly_cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_revoked_at ON api_keys(revoked_at);
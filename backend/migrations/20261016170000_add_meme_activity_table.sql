@@ -0,0 +1,16 @@
+-- Migration: Add meme_activity table for the popularity prior of search
+-- One row per meme and UTC day: impressions are added for every search
+-- result served, clicks by POST /api/v1/memes/:id/click and downloads by the
+-- download endpoints. Rows older than search.popularity.days are pruned.
+
+CREATE TABLE IF NOT EXISTS meme_activity (
+    meme_id TEXT NOT NULL,
+    date TEXT NOT NULL,
+    impressions BIGINT NOT NULL DEFAULT 0,
+    clicks BIGINT NOT NULL DEFAULT 0,
+    downloads BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (meme_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meme_activity_date ON meme_activity(date);
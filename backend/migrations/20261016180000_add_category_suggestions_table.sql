@@ -0,0 +1,22 @@
+-- Migration: Add category_suggestions table for cmd/discover
+-- Categories proposed by the LLM for clusters of memes, waiting for review
+-- under /api/v1/admin/categories/suggestions. Accepting one moves its memes
+-- to label.
+
+CREATE TABLE IF NOT EXISTS category_suggestions (
+    id TEXT PRIMARY KEY,
+    run_id TEXT NOT NULL,
+    collection TEXT NOT NULL,
+    source_category TEXT,
+    label TEXT NOT NULL,
+    rationale TEXT,
+    size BIGINT,
+    meme_ids TEXT,
+    exemplar_ids TEXT,
+    status TEXT DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_suggestions_run_id ON category_suggestions(run_id);
+CREATE INDEX IF NOT EXISTS idx_category_suggestions_status ON category_suggestions(status);
@@ -0,0 +1,15 @@
+-- Migration: Add api_key_usage table for per-key monthly usage
+-- One row per API key and calendar month (UTC); the API key middleware adds
+-- requests, tokens and cost after every request and checks the monthly quotas
+-- against it.
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id TEXT NOT NULL,
+    month TEXT NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    llm_tokens BIGINT NOT NULL DEFAULT 0,
+    embedding_tokens BIGINT NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (key_id, month)
+);
@@ -0,0 +1,10 @@
+-- Migration: Add category_covers table for pinned category covers
+-- One row per category: the meme an admin pinned with
+-- PUT /api/v1/admin/categories/:name/cover, returned by GET /api/v1/categories.
+
+CREATE TABLE IF NOT EXISTS category_covers (
+    category TEXT PRIMARY KEY,
+    meme_id TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
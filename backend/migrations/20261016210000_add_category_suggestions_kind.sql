@@ -0,0 +1,3 @@
+-- Migration: Distinguish cluster suggestions from outliers in category_suggestions
+
+ALTER TABLE category_suggestions ADD COLUMN IF NOT EXISTS kind TEXT DEFAULT 'cluster';
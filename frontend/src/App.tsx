@@ -6,6 +6,7 @@ import {
   searchMemesStream,
   getMemes,
   getStats,
+  recordMemeClick,
   type SearchStage,
   type SearchProgressEvent,
 } from './api';
@@ -272,8 +273,11 @@ function App() {
 
   // Handle meme click
   const handleMemeClick = useCallback((meme: Meme) => {
+    if (hasSearched) {
+      recordMemeClick(meme.id);
+    }
     setSelectedMeme(meme);
-  }, []);
+  }, [hasSearched]);
 
   // Handle modal close
   const handleModalClose = useCallback(() => {
@@ -111,13 +111,14 @@ export async function getCategories(): Promise<Category[]> {
     throw new Error(`Failed to fetch categories: ${response.statusText}`);
   }
 
-  // Backend returns { categories: string[], total: number }
+  // Backend returns { categories: string[], total: number, covers?: { [name]: cover } }
   const data: CategoriesResponse = await response.json();
   
   // Convert string array to Category objects
   return data.categories.map((name) => ({
     name,
     count: undefined, // Backend doesn't provide count per category in this endpoint
+    cover: data.covers?.[name],
   }));
 }
 
@@ -181,6 +182,20 @@ export async function getMeme(id: string): Promise<Meme> {
   return response.json();
 }
 
+/**
+ * Reports a meme opened from search results, feeding the popularity ranking.
+ * Failures are ignored: the click is best-effort and never blocks the UI.
+ *
+ * @param id - The unique identifier of the meme.
+ */
+export function recordMemeClick(id: string): void {
+  fetch(`${API_BASE}/memes/${encodeURIComponent(id)}/click`, {
+    method: 'POST',
+    headers: getHeaders(),
+    keepalive: true,
+  }).catch(() => undefined);
+}
+
 /**
  * Retrieves aggregate backend stats for the header and system status.
  *
@@ -206,6 +221,7 @@ export type SearchStage =
   | 'query_expansion_done'
   | 'embedding'
   | 'searching'
+  | 'reranking'
   | 'enriching'
   | 'complete'
   | 'error';
@@ -222,6 +238,7 @@ export interface SearchProgressEvent {
   query?: string;
   collection?: string;
   profile?: string;
+  cached?: boolean;
   error?: string;
 }
 
@@ -93,7 +93,7 @@ export default function MemeCard({ meme, index = 0, onClick }: MemeCardProps) {
           {/* Meme image */}
           {!imageError && (
             <motion.img
-              src={meme.url || meme.original_url}
+              src={meme.poster_url || meme.url || meme.original_url}
               alt={description || 'Meme'}
               className={styles.image}
               loading="lazy"
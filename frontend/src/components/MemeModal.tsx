@@ -259,6 +259,20 @@ export default function MemeModal({ meme, isOpen, onClose }: MemeModalProps) {
                   </svg>
                   <p>图片加载失败</p>
                 </div>
+              ) : meme.poster_url ? (
+                <motion.video
+                  src={meme.url}
+                  poster={meme.poster_url}
+                  className={styles.image}
+                  autoPlay
+                  loop
+                  muted
+                  playsInline
+                  initial={{ opacity: 0 }}
+                  animate={{ opacity: 1 }}
+                  transition={{ delay: 0.1 }}
+                  onError={handleImageError}
+                />
               ) : (
                 <motion.img
                   src={meme.url || meme.original_url}
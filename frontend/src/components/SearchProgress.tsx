@@ -21,6 +21,9 @@ function getStageIndex(stage: SearchStage): number {
   if (stage === 'thinking' || stage === 'query_expansion_done') {
     return 0; // Still in query expansion phase
   }
+  if (stage === 'reranking') {
+    return 2; // Reranking is the tail of the search step
+  }
   const index = STAGES.findIndex((s) => s.key === stage);
   return index >= 0 ? index : 0;
 }
@@ -7,6 +7,8 @@ export interface Meme {
   id: string;
   /** The URL of the meme image. */
   url: string;
+  /** Static poster frame for animated memes; render this in list views. */
+  poster_url?: string;
   /**
    * The similarity score from a search query.
    * Defaults to 0 for list results (non-search).
@@ -24,6 +26,8 @@ export interface Meme {
   width?: number;
   /** The height of the meme image in pixels. */
   height?: number;
+  /** Dominant colors as hex strings, ordered by pixel share. */
+  dominant_colors?: string[];
 
   // Legacy fields for demo data compatibility
   /**
@@ -77,10 +81,16 @@ export interface SearchResult {
   id: string;
   /** The URL of the result image. */
   url: string;
+  /** Static poster frame for animated results. */
+  poster_url?: string;
   /** The similarity score of the result. */
   score: number;
+  /** Reranker relevance, set when the rerank stage scored the result. */
+  rerank_score?: number;
   /** The description of the result. */
   description: string;
+  /** Text read from the image by OCR. */
+  ocr_text?: string;
   /** The category of the result. */
   category: string;
   /** Tags associated with the result. */
@@ -89,6 +99,8 @@ export interface SearchResult {
   width?: number;
   /** The height of the result image. */
   height?: number;
+  /** Dominant colors as hex strings, ordered by pixel share. */
+  dominant_colors?: string[];
 }
 
 /**
@@ -104,6 +116,22 @@ export interface SearchRequest {
   top_k?: number;
   /** Optional category filter. */
   category?: string;
+  /**
+   * Optional color filter: a color name, `monochrome` or `colorful`.
+   * Detected from the query when omitted.
+   */
+  color?: string;
+  /** Optional language filter for the text on the meme: `zh`, `en` or `ja`. */
+  text_lang?: string;
+  /**
+   * Optional usage scene filter, e.g. `考试` or `恋爱`.
+   * Detected from the query when omitted and scene tagging is enabled.
+   */
+  scene?: string;
+  /** Optional grouping field; only `category` is supported. */
+  group_by?: string;
+  /** Maximum results per group when `group_by` is set (default 2). */
+  group_size?: number;
   /** Optional multi-route search profile. */
   profile?: string;
   /** Legacy optional collection key; retained for backend compatibility. */
@@ -122,6 +150,12 @@ export interface SearchResponse {
   profile?: string;
   /** The backend collection used for legacy single-collection search. */
   collection?: string;
+  /** The color filter applied, whether requested or detected from the query. */
+  color?: string;
+  /** The scene filter applied, whether requested or detected from the query. */
+  scene?: string;
+  /** True when the response was served from the backend result cache. */
+  cached?: boolean;
 }
 
 /**
@@ -133,6 +167,38 @@ export interface Category {
   name: string;
   /** The count of items in this category (optional). */
   count?: number;
+  /** The cover meme pinned by an admin (optional). */
+  cover?: CategoryCover;
+}
+
+/**
+ * Represents the cover image pinned to a category.
+ */
+export interface CategoryCover {
+  /** The ID of the cover meme. */
+  meme_id: string;
+  /** The URL of the cover image. */
+  url: string;
+  /** A static frame to show when the cover is animated (optional). */
+  poster_url?: string;
+}
+
+/**
+ * Represents the content statistics of a category, returned with `?stats=true`.
+ */
+export interface CategoryStats {
+  /** The name of the category. */
+  category: string;
+  /** The number of active memes. */
+  count: number;
+  /** The number of active animated memes. */
+  animated: number;
+  /** The share of animated memes, from 0 to 1. */
+  animated_ratio: number;
+  /** The number of memes ingested within the requested window. */
+  added: number;
+  /** Memes added relative to the count before the window; absent for new categories. */
+  growth?: number;
 }
 
 /**
@@ -143,6 +209,10 @@ export interface CategoriesResponse {
   categories: string[];
   /** The total number of categories. */
   total: number;
+  /** Pinned cover images keyed by category name; categories without a cover are absent. */
+  covers?: Record<string, CategoryCover>;
+  /** Per-category statistics, present when requested with `?stats=true`. */
+  stats?: CategoryStats[];
 }
 
 /**
@@ -158,6 +228,10 @@ export interface MemesListResponse {
   limit: number;
   /** The offset used for pagination. */
   offset: number;
+  /** The number of pages of `limit` memes. */
+  total_pages: number;
+  /** Whether more memes follow this page. */
+  has_more: boolean;
 }
 
 /**
@@ -174,4 +248,20 @@ export interface StatsResponse {
   available_collections?: string[];
   /** Names of multi-route search profiles exposed by the backend. */
   available_profiles?: string[];
+  /** Vector counts and coverage of active memes per Qdrant collection. */
+  collections?: CollectionStats[];
+}
+
+/**
+ * Represents vector coverage for a single Qdrant collection.
+ */
+export interface CollectionStats {
+  /** Qdrant collection name. */
+  collection: string;
+  /** Number of meme_vectors rows recorded for the collection. */
+  vectors: number;
+  /** Active memes with an active vector in the collection. */
+  memes: number;
+  /** Covered memes as a percentage of active memes. */
+  coverage_pct: number;
 }